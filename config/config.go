@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
@@ -22,6 +24,16 @@ var (
 	isInitialized bool
 )
 
+// UnknownKeysError 配置文件中存在结构体没有对应字段的键（LoadConfigStrict）
+type UnknownKeysError struct {
+	Keys []string // 未知的键，使用点号分隔的完整路径，已排序
+}
+
+// Error 实现error接口
+func (e *UnknownKeysError) Error() string {
+	return fmt.Sprintf("配置中存在未知的键: %s", strings.Join(e.Keys, ", "))
+}
+
 // Cleanup 清理全局配置状态，释放相关资源
 //
 // 使用场景:
@@ -41,6 +53,10 @@ func Cleanup() {
 	defer globalMutex.Unlock()
 	globalViper = nil
 	isInitialized = false
+	lastReport = nil
+	activeProfile = ""
+	lastLoad = nil
+	lastLoadInfo = nil
 }
 
 // ResetGlobalState 重置全局配置状态（主要用于测试）
@@ -100,20 +116,85 @@ func ResetGlobalState() {
 //	// 使用前缀: export APP_NAME=myapp 时
 //	//   export MYAPP_APP_PORT=8080
 func LoadConfig(config interface{}, filePath ...string) error {
+	return loadConfig(config, false, "", nil, filePath...)
+}
+
+// LoadConfigStrict 与 LoadConfig 相同，但配置文件中存在结构体没有对应字段的键时返回 *UnknownKeysError
+//
+// 用于发现配置键拼写错误（例如把 database.host 写成 databse.host），
+// 这类错误在 LoadConfig 中会被静默忽略，字段保持零值。
+// 目标字段为 map 或 interface{} 时，其下的任意键都视为已知。
+//
+// 示例:
+//
+//	var cfg AppConfig
+//	if err := config.LoadConfigStrict(&cfg); err != nil {
+//	    var unknown *config.UnknownKeysError
+//	    if errors.As(err, &unknown) {
+//	        log.Fatalf("配置中存在未知的键: %v", unknown.Keys)
+//	    }
+//	    log.Fatal(err)
+//	}
+func LoadConfigStrict(config interface{}, filePath ...string) error {
+	return loadConfig(config, true, "", nil, filePath...)
+}
+
+// loadConfig 加载配置并初始化全局viper实例，strict 为true时检查未知的键，profile 非空时合并对应环境的配置，
+// defaults 非空时其非零字段作为默认值
+func loadConfig(config interface{}, strict bool, profile string, defaults interface{}, filePath ...string) error {
+	return load(config, &loadParams{
+		config:   config,
+		strict:   strict,
+		profile:  profile,
+		defaults: defaults,
+		filePath: append([]string(nil), filePath...),
+	})
+}
+
+// load 按 params 加载配置并解析到 config，成功后登记 params 供 Reload 使用
+func load(config interface{}, params *loadParams) error {
+	strict, profile, defaults, filePath := params.strict, params.profile, params.defaults, params.filePath
 	v, err := createViperInstanceWithError(filePath...)
 	if err != nil {
 		return err
 	}
+	if err := applyStructDefaults(v, config, defaults); err != nil {
+		return err
+	}
+	if err := applyProfile(v, profile); err != nil {
+		return err
+	}
+
+	// 切片和 map 字段的环境变量需要显式解析
+	if err := applyCollectionEnv(v, config); err != nil {
+		return err
+	}
 
 	// 解析配置到结构体
-	if err := v.Unmarshal(config); err != nil {
+	var metadata mapstructure.Metadata
+	if err := v.Unmarshal(config, func(dc *mapstructure.DecoderConfig) {
+		dc.Metadata = &metadata
+	}); err != nil {
 		return fmt.Errorf("解析配置到结构体失败: %w", err)
 	}
+	// 严格模式失败时也保留报告，便于启动日志输出
+	report := buildKeyReport(v, config, metadata.Unused)
+	globalMutex.Lock()
+	lastReport = report
+	globalMutex.Unlock()
+	if strict && len(report.UnknownKeys) > 0 {
+		return &UnknownKeysError{Keys: append([]string(nil), report.UnknownKeys...)}
+	}
 
 	// 同时初始化全局viper实例供其他函数使用
 	globalMutex.Lock()
 	globalViper = v
 	isInitialized = true
+	activeProfile = profile
+	lastLoad = params
+	lastLoadInfo = buildLoadInfo(v)
+	// 运行时覆盖值只保留到下一次成功加载配置文件
+	clearRuntimeOverridesLocked()
 	globalMutex.Unlock()
 
 	return nil
@@ -176,6 +257,7 @@ func GetClient(filePath ...string) (*viper.Viper, error) {
 		}
 		globalViper = v
 		isInitialized = true
+		lastLoadInfo = buildLoadInfo(v)
 	}
 
 	return globalViper, nil
@@ -222,7 +304,7 @@ func MustGetClient(filePath ...string) *viper.Viper {
 //	    log.Fatal(err)
 //	}
 func GetStringWithDefault(key, defaultValue string) (string, error) {
-	client, err := GetClient()
+	client, key, err := clientForKey(key)
 	if err != nil {
 		return defaultValue, err
 	}
@@ -247,7 +329,7 @@ func MustGetStringWithDefault(key, defaultValue string) string {
 
 // GetIntWithDefault 获取整数配置项，支持默认值
 func GetIntWithDefault(key string, defaultValue int) (int, error) {
-	client, err := GetClient()
+	client, key, err := clientForKey(key)
 	if err != nil {
 		return defaultValue, err
 	}
@@ -272,7 +354,7 @@ func MustGetIntWithDefault(key string, defaultValue int) int {
 
 // GetBoolWithDefault 获取布尔配置项，支持默认值
 func GetBoolWithDefault(key string, defaultValue bool) (bool, error) {
-	client, err := GetClient()
+	client, key, err := clientForKey(key)
 	if err != nil {
 		return defaultValue, err
 	}
@@ -321,7 +403,7 @@ func MustGetBoolWithDefault(key string, defaultValue bool) bool {
 //	    log.Warn("端口配置超出有效范围，使用默认值")
 //	}
 func GetIntWithValidation(key string, defaultValue, min, max int) (int, bool, error) {
-	client, err := GetClient()
+	client, key, err := clientForKey(key)
 	if err != nil {
 		return defaultValue, false, err
 	}
@@ -340,7 +422,7 @@ func GetIntWithValidation(key string, defaultValue, min, max int) (int, bool, er
 
 // GetStringSliceWithDefault 获取字符串切片配置项，支持默认值
 func GetStringSliceWithDefault(key string, defaultValue []string) ([]string, error) {
-	client, err := GetClient()
+	client, key, err := clientForKey(key)
 	if err != nil {
 		return defaultValue, err
 	}
@@ -365,7 +447,7 @@ func MustGetStringSliceWithDefault(key string, defaultValue []string) []string {
 
 // GetFloat64WithDefault 获取浮点数配置项，支持默认值
 func GetFloat64WithDefault(key string, defaultValue float64) (float64, error) {
-	client, err := GetClient()
+	client, key, err := clientForKey(key)
 	if err != nil {
 		return defaultValue, err
 	}
@@ -390,7 +472,7 @@ func MustGetFloat64WithDefault(key string, defaultValue float64) float64 {
 
 // GetInt64WithDefault 获取64位整数配置项，支持默认值
 func GetInt64WithDefault(key string, defaultValue int64) (int64, error) {
-	client, err := GetClient()
+	client, key, err := clientForKey(key)
 	if err != nil {
 		return defaultValue, err
 	}
@@ -415,7 +497,7 @@ func MustGetInt64WithDefault(key string, defaultValue int64) int64 {
 
 // GetUintWithDefault 获取无符号整数配置项，支持默认值
 func GetUintWithDefault(key string, defaultValue uint) (uint, error) {
-	client, err := GetClient()
+	client, key, err := clientForKey(key)
 	if err != nil {
 		return defaultValue, err
 	}
@@ -440,7 +522,7 @@ func MustGetUintWithDefault(key string, defaultValue uint) uint {
 
 // GetUint64WithDefault 获取64位无符号整数配置项，支持默认值
 func GetUint64WithDefault(key string, defaultValue uint64) (uint64, error) {
-	client, err := GetClient()
+	client, key, err := clientForKey(key)
 	if err != nil {
 		return defaultValue, err
 	}
@@ -465,7 +547,7 @@ func MustGetUint64WithDefault(key string, defaultValue uint64) uint64 {
 
 // GetDurationWithDefault 获取时间间隔配置项，支持默认值
 func GetDurationWithDefault(key string, defaultValue time.Duration) (time.Duration, error) {
-	client, err := GetClient()
+	client, key, err := clientForKey(key)
 	if err != nil {
 		return defaultValue, err
 	}
@@ -490,7 +572,7 @@ func MustGetDurationWithDefault(key string, defaultValue time.Duration) time.Dur
 
 // GetTimeWithDefault 获取时间配置项，支持默认值
 func GetTimeWithDefault(key string, defaultValue time.Time) (time.Time, error) {
-	client, err := GetClient()
+	client, key, err := clientForKey(key)
 	if err != nil {
 		return defaultValue, err
 	}
@@ -515,7 +597,7 @@ func MustGetTimeWithDefault(key string, defaultValue time.Time) time.Time {
 
 // GetStringMapWithDefault 获取字符串映射配置项，支持默认值
 func GetStringMapWithDefault(key string, defaultValue map[string]interface{}) (map[string]interface{}, error) {
-	client, err := GetClient()
+	client, key, err := clientForKey(key)
 	if err != nil {
 		return defaultValue, err
 	}
@@ -540,7 +622,7 @@ func MustGetStringMapWithDefault(key string, defaultValue map[string]interface{}
 
 // GetStringMapStringWithDefault 获取字符串到字符串的映射配置项，支持默认值
 func GetStringMapStringWithDefault(key string, defaultValue map[string]string) (map[string]string, error) {
-	client, err := GetClient()
+	client, key, err := clientForKey(key)
 	if err != nil {
 		return defaultValue, err
 	}
@@ -565,7 +647,7 @@ func MustGetStringMapStringWithDefault(key string, defaultValue map[string]strin
 
 // GetStringMapStringSliceWithDefault 获取字符串到字符串切片的映射配置项，支持默认值
 func GetStringMapStringSliceWithDefault(key string, defaultValue map[string][]string) (map[string][]string, error) {
-	client, err := GetClient()
+	client, key, err := clientForKey(key)
 	if err != nil {
 		return defaultValue, err
 	}
@@ -590,7 +672,7 @@ func MustGetStringMapStringSliceWithDefault(key string, defaultValue map[string]
 
 // GetSizeInBytesWithDefault 获取字节大小配置项，支持默认值
 func GetSizeInBytesWithDefault(key string, defaultValue int) (uint, error) {
-	client, err := GetClient()
+	client, key, err := clientForKey(key)
 	if err != nil {
 		return uint(defaultValue), err
 	}
@@ -615,7 +697,7 @@ func MustGetSizeInBytesWithDefault(key string, defaultValue int) uint {
 
 // GetFloat64WithValidation 获取浮点数配置项并进行范围验证
 func GetFloat64WithValidation(key string, defaultValue, min, max float64) (float64, bool, error) {
-	client, err := GetClient()
+	client, key, err := clientForKey(key)
 	if err != nil {
 		return defaultValue, false, err
 	}
@@ -634,7 +716,7 @@ func GetFloat64WithValidation(key string, defaultValue, min, max float64) (float
 
 // GetDurationWithValidation 获取时间间隔配置项并进行范围验证
 func GetDurationWithValidation(key string, defaultValue, min, max time.Duration) (time.Duration, bool, error) {
-	client, err := GetClient()
+	client, key, err := clientForKey(key)
 	if err != nil {
 		return defaultValue, false, err
 	}
@@ -651,6 +733,34 @@ func GetDurationWithValidation(key string, defaultValue, min, max time.Duration)
 	return value, true, nil
 }
 
+// GetStringSliceWithValidation 获取字符串切片配置项，并验证每个元素都在 allowed 中
+//
+// 返回:
+//   - value: 配置值（存在不允许的元素时返回默认值）
+//   - isValid: 所有元素是否都在 allowed 中
+//   - error: 如果获取配置客户端失败
+//
+// 示例:
+//
+//	methods, valid, err := config.GetStringSliceWithValidation("cors.methods",
+//	    []string{"GET"}, []string{"GET", "POST", "PUT", "DELETE"})
+//	if !valid {
+//	    log.Printf("cors.methods 中存在不支持的方法，使用默认值: %v", methods)
+//	}
+func GetStringSliceWithValidation(key string, defaultValue []string, allowed []string) ([]string, bool, error) {
+	value, err := GetStringSliceWithDefault(key, defaultValue)
+	if err != nil {
+		return defaultValue, false, err
+	}
+
+	for _, item := range value {
+		if !slices.Contains(allowed, item) {
+			return defaultValue, false, nil
+		}
+	}
+	return value, true, nil
+}
+
 // IsSet 检查配置项是否已设置
 //
 // 使用场景:
@@ -663,6 +773,10 @@ func GetDurationWithValidation(key string, defaultValue, min, max time.Duration)
 //	    // 启用高级功能
 //	}
 func IsSet(key string) (bool, error) {
+	if hasOverride(key) {
+		return true, nil
+	}
+
 	client, err := GetClient()
 	if err != nil {
 		return false, err
@@ -670,7 +784,7 @@ func IsSet(key string) (bool, error) {
 
 	// IsSet 是只读操作，使用读锁
 	globalMutex.RLock()
-	result := client.IsSet(key)
+	result := client.IsSet(resolveKey(client, key))
 	globalMutex.RUnlock()
 
 	return result, nil
@@ -700,7 +814,7 @@ func AllKeys() ([]string, error) {
 	result := client.AllKeys()
 	globalMutex.RUnlock()
 
-	return result, nil
+	return mergeOverrideKeys(result), nil
 }
 
 // createViperInstanceWithError 创建并配置viper实例，返回错误（用于LoadConfig和GetClient）
@@ -1,11 +1,14 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sync"
 	"testing"
+	"time"
 )
 
 // TestConfig 测试用的配置结构体
@@ -549,6 +552,137 @@ server:
 	}
 }
 
+func TestGetFloat64AndDurationWithValidation(t *testing.T) {
+	// 重置全局状态确保测试隔离
+	ResetGlobalState()
+
+	// 创建临时配置文件
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "config.yml")
+
+	configContent := `
+sampling:
+  ratio: 0.25
+  burst: 1.5  # 超出有效范围
+server:
+  timeout: 15s
+  idle: 2h    # 超出有效范围
+`
+
+	err := os.WriteFile(configFile, []byte(configContent), 0644)
+	if err != nil {
+		t.Fatalf("创建临时配置文件失败: %v", err)
+	}
+
+	// 切换到临时目录
+	oldDir, _ := os.Getwd()
+	defer os.Chdir(oldDir)
+	os.Chdir(tempDir)
+
+	// 测试带默认值的浮点数配置
+	ratio, err := GetFloat64WithDefault("sampling.ratio", 0.5)
+	if err != nil {
+		t.Fatalf("GetFloat64WithDefault 失败: %v", err)
+	}
+	if ratio != 0.25 {
+		t.Errorf("期望 sampling.ratio = 0.25, 实际 = %v", ratio)
+	}
+
+	floatTests := []struct {
+		key          string
+		defaultValue float64
+		want         float64
+		wantValid    bool
+	}{
+		{"sampling.ratio", 0.5, 0.25, true},
+		{"sampling.burst", 0.5, 0.5, false},
+		{"sampling.missing", 0.1, 0.1, true},
+	}
+	for _, tt := range floatTests {
+		value, valid, err := GetFloat64WithValidation(tt.key, tt.defaultValue, 0, 1)
+		if err != nil {
+			t.Fatalf("GetFloat64WithValidation 失败: %v", err)
+		}
+		if value != tt.want || valid != tt.wantValid {
+			t.Errorf("%s: 期望 (%v, %t), 实际 (%v, %t)", tt.key, tt.want, tt.wantValid, value, valid)
+		}
+	}
+
+	durationTests := []struct {
+		key          string
+		defaultValue time.Duration
+		want         time.Duration
+		wantValid    bool
+	}{
+		{"server.timeout", 30 * time.Second, 15 * time.Second, true},
+		{"server.idle", 5 * time.Minute, 5 * time.Minute, false},
+		{"server.missing", 10 * time.Second, 10 * time.Second, true},
+	}
+	for _, tt := range durationTests {
+		value, valid, err := GetDurationWithValidation(tt.key, tt.defaultValue, time.Second, time.Hour)
+		if err != nil {
+			t.Fatalf("GetDurationWithValidation 失败: %v", err)
+		}
+		if value != tt.want || valid != tt.wantValid {
+			t.Errorf("%s: 期望 (%v, %t), 实际 (%v, %t)", tt.key, tt.want, tt.wantValid, value, valid)
+		}
+	}
+}
+
+func TestGetStringSliceWithValidation(t *testing.T) {
+	// 重置全局状态确保测试隔离
+	ResetGlobalState()
+
+	// 创建临时配置文件
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "config.yml")
+
+	configContent := `
+cors:
+  methods: ["GET", "POST"]
+  headers: ["X-Token", "X-Unknown"]  # 包含不允许的值
+`
+
+	err := os.WriteFile(configFile, []byte(configContent), 0644)
+	if err != nil {
+		t.Fatalf("创建临时配置文件失败: %v", err)
+	}
+
+	// 切换到临时目录
+	oldDir, _ := os.Getwd()
+	defer os.Chdir(oldDir)
+	os.Chdir(tempDir)
+
+	allowedMethods := []string{"GET", "POST", "PUT", "DELETE"}
+
+	// 测试所有元素都允许的配置
+	methods, valid, err := GetStringSliceWithValidation("cors.methods", []string{"GET"}, allowedMethods)
+	if err != nil {
+		t.Fatalf("GetStringSliceWithValidation 失败: %v", err)
+	}
+	if !valid || !reflect.DeepEqual(methods, []string{"GET", "POST"}) {
+		t.Errorf("期望 cors.methods = [GET POST] 且有效, 实际 = %v, %t", methods, valid)
+	}
+
+	// 测试包含不允许元素的配置
+	headers, valid, err := GetStringSliceWithValidation("cors.headers", []string{"X-Token"}, []string{"X-Token"})
+	if err != nil {
+		t.Fatalf("GetStringSliceWithValidation 失败: %v", err)
+	}
+	if valid || !reflect.DeepEqual(headers, []string{"X-Token"}) {
+		t.Errorf("期望 cors.headers 无效并返回默认值, 实际 = %v, %t", headers, valid)
+	}
+
+	// 测试不存在的配置项
+	origins, valid, err := GetStringSliceWithValidation("cors.origins", []string{"*"}, []string{"*"})
+	if err != nil {
+		t.Fatalf("GetStringSliceWithValidation 失败: %v", err)
+	}
+	if !valid || !reflect.DeepEqual(origins, []string{"*"}) {
+		t.Errorf("期望 cors.origins = [*] (默认值), 实际 = %v, %t", origins, valid)
+	}
+}
+
 func TestGetStringSliceWithDefault(t *testing.T) {
 	// 重置全局状态确保测试隔离
 	ResetGlobalState()
@@ -1184,3 +1318,94 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+// writeConfigFile 在临时目录中写入名为 name 的配置文件，返回文件路径
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	configFile := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("创建临时配置文件失败: %v", err)
+	}
+	return configFile
+}
+
+func TestLoadConfigStrict_UnknownKeys(t *testing.T) {
+	ResetGlobalState()
+
+	configFile := writeConfigFile(t, "config.yml", `
+app:
+  name: "Test App"
+  prot: 8080
+databse:
+  host: "localhost"
+`)
+
+	var cfg TestConfig
+	err := LoadConfigStrict(&cfg, configFile)
+
+	var unknown *UnknownKeysError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("期望 *UnknownKeysError, 实际 %v", err)
+	}
+	want := []string{"app.prot", "databse"}
+	if !reflect.DeepEqual(unknown.Keys, want) {
+		t.Errorf("期望未知键 %v, 实际 %v", want, unknown.Keys)
+	}
+	if err.Error() != "配置中存在未知的键: app.prot, databse" {
+		t.Errorf("错误信息不正确: %s", err.Error())
+	}
+
+	// 宽松模式保持原有行为
+	ResetGlobalState()
+	if err := LoadConfig(&cfg, configFile); err != nil {
+		t.Fatalf("LoadConfig 不应因未知键失败: %v", err)
+	}
+	if cfg.App.Name != "Test App" {
+		t.Errorf("期望 app.name 被解析, 实际 %q", cfg.App.Name)
+	}
+}
+
+func TestLoadConfigStrict_Valid(t *testing.T) {
+	ResetGlobalState()
+
+	configFile := writeConfigFile(t, "config.yml", `
+app:
+  name: "Test App"
+  port: 8080
+database:
+  host: "localhost"
+`)
+
+	var cfg TestConfig
+	if err := LoadConfigStrict(&cfg, configFile); err != nil {
+		t.Fatalf("期望加载成功, 实际 %v", err)
+	}
+	if cfg.Database.Host != "localhost" || cfg.App.Port != 8080 {
+		t.Errorf("配置解析不正确: %+v", cfg)
+	}
+	if value, err := GetStringWithDefault("database.host", ""); err != nil || value != "localhost" {
+		t.Errorf("期望全局实例已初始化, 实际 %q %v", value, err)
+	}
+}
+
+func TestLoadConfigStrict_MapTarget(t *testing.T) {
+	ResetGlobalState()
+
+	configFile := writeConfigFile(t, "config.yml", `
+name: "svc"
+labels:
+  team: "core"
+  anything: "goes"
+`)
+
+	var cfg struct {
+		Name   string            `mapstructure:"name"`
+		Labels map[string]string `mapstructure:"labels"`
+	}
+	if err := LoadConfigStrict(&cfg, configFile); err != nil {
+		t.Fatalf("map 字段下的键不应视为未知, 实际 %v", err)
+	}
+	if cfg.Labels["anything"] != "goes" {
+		t.Errorf("期望 map 字段被解析, 实际 %v", cfg.Labels)
+	}
+}
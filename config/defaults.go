@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/viper"
+)
+
+// LoadConfigWithDefaults 与 LoadConfig 相同，但使用同类型的结构体提供默认值
+//
+// defaults 为与 config 同类型的结构体或结构体指针，其中的非零字段作为最低优先级的值，
+// 配置文件、环境变量中设置的键都会覆盖它；零值字段不提供默认值。
+// 与 default 标签相比，默认值可以在运行时计算（例如根据环境变量推导端口）。
+//
+// 配置文件中显式写出的值（包括 0、false 和空字符串）优先于默认值。
+// 有默认值的键不会出现在 KeyReport.MissingKeys 中。
+//
+// 示例:
+//
+//	defaults := AppConfig{}
+//	defaults.App.Port = basePort + 80
+//	defaults.App.Timeout = 30 * time.Second
+//
+//	var cfg AppConfig
+//	err := config.LoadConfigWithDefaults(&cfg, defaults) // 配置文件中没有 app.port 时使用 basePort+80
+func LoadConfigWithDefaults(config interface{}, defaults interface{}, filePath ...string) error {
+	return loadConfig(config, false, "", defaults, filePath...)
+}
+
+// applyStructDefaults 将 defaults 的非零叶子字段注册为 viper 默认值
+func applyStructDefaults(v *viper.Viper, config interface{}, defaults interface{}) error {
+	if defaults == nil {
+		return nil
+	}
+	dv := reflect.ValueOf(defaults)
+	for dv.Kind() == reflect.Ptr {
+		if dv.IsNil() {
+			return nil
+		}
+		dv = dv.Elem()
+	}
+
+	ct := reflect.TypeOf(config)
+	for ct != nil && ct.Kind() == reflect.Ptr {
+		ct = ct.Elem()
+	}
+	if dv.Kind() != reflect.Struct || dv.Type() != ct {
+		return fmt.Errorf("默认值类型 %s 与配置类型 %v 不一致", dv.Type(), ct)
+	}
+
+	setStructDefaults(v, dv, "")
+	return nil
+}
+
+func setStructDefaults(v *viper.Viper, rv reflect.Value, prefix string) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		key, squash, skip := configFieldKey(sf)
+		if skip {
+			continue
+		}
+		path := key
+		if squash {
+			path = prefix
+		} else if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		fv := rv.Field(i)
+		for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		if fv.IsZero() {
+			continue
+		}
+
+		if isNestedConfigStruct(fv) {
+			setStructDefaults(v, fv, path)
+			continue
+		}
+		v.SetDefault(path, configLeafValue(fv))
+	}
+}
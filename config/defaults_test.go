@@ -0,0 +1,120 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+type defaultsTestConfig struct {
+	App struct {
+		Name    string        `mapstructure:"name"`
+		Port    int           `mapstructure:"port"`
+		Debug   bool          `mapstructure:"debug"`
+		Timeout time.Duration `mapstructure:"timeout"`
+		Tags    []string      `mapstructure:"tags"`
+	} `mapstructure:"app"`
+	Database *struct {
+		Host string `mapstructure:"host"`
+	} `mapstructure:"database"`
+}
+
+func newTestDefaults() defaultsTestConfig {
+	var defaults defaultsTestConfig
+	defaults.App.Name = "default-app"
+	defaults.App.Port = 8080
+	defaults.App.Debug = true
+	defaults.App.Timeout = 30 * time.Second
+	defaults.App.Tags = []string{"a", "b"}
+	defaults.Database = &struct {
+		Host string `mapstructure:"host"`
+	}{Host: "localhost"}
+	return defaults
+}
+
+func TestLoadConfigWithDefaults_FileWins(t *testing.T) {
+	ResetGlobalState()
+	configFile := writeConfigFile(t, "config.yml", "app:\n  name: demo\n  debug: false\n")
+
+	var cfg defaultsTestConfig
+	if err := LoadConfigWithDefaults(&cfg, newTestDefaults(), configFile); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if cfg.App.Name != "demo" {
+		t.Errorf("App.Name = %q, 期望配置文件中的 demo", cfg.App.Name)
+	}
+	// 配置文件中显式写出的 false 优先于默认值
+	if cfg.App.Debug {
+		t.Error("App.Debug 应为配置文件中的 false")
+	}
+	// 配置文件中没有的键使用默认结构体的值
+	if cfg.App.Port != 8080 || cfg.App.Timeout != 30*time.Second {
+		t.Errorf("缺失的键应使用默认值, 实际 port=%d timeout=%v", cfg.App.Port, cfg.App.Timeout)
+	}
+	if len(cfg.App.Tags) != 2 || cfg.App.Tags[1] != "b" {
+		t.Errorf("App.Tags = %v, 期望 [a b]", cfg.App.Tags)
+	}
+	if cfg.Database == nil || cfg.Database.Host != "localhost" {
+		t.Errorf("Database.Host 应使用默认值, 实际 %+v", cfg.Database)
+	}
+
+	report := LastKeyReport()
+	for _, key := range report.MissingKeys {
+		if key == "app.port" {
+			t.Error("有默认值的键不应出现在 MissingKeys 中")
+		}
+	}
+	if port := MustGetClient().GetInt("app.port"); port != 8080 {
+		t.Errorf("全局实例应能读取默认值, 实际 %d", port)
+	}
+}
+
+func TestLoadConfigWithDefaults_EnvWins(t *testing.T) {
+	ResetGlobalState()
+	t.Setenv("APP_NAME", "")
+	t.Setenv("APP_PORT", "9090")
+	configFile := writeConfigFile(t, "config.yml", "app:\n  name: demo\n")
+
+	var cfg defaultsTestConfig
+	if err := LoadConfigWithDefaults(&cfg, newTestDefaults(), configFile); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if cfg.App.Port != 9090 {
+		t.Errorf("App.Port = %d, 环境变量应覆盖默认值", cfg.App.Port)
+	}
+}
+
+func TestLoadConfigWithDefaults_PointerAndZeroFields(t *testing.T) {
+	ResetGlobalState()
+	configFile := writeConfigFile(t, "config.yml", "app:\n  name: demo\n")
+
+	defaults := &defaultsTestConfig{}
+	defaults.App.Port = 7000
+
+	var cfg defaultsTestConfig
+	if err := LoadConfigWithDefaults(&cfg, defaults, configFile); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if cfg.App.Port != 7000 {
+		t.Errorf("App.Port = %d, 期望 7000", cfg.App.Port)
+	}
+	// 零值字段不提供默认值，仍报告为缺失
+	missing := false
+	for _, key := range LastKeyReport().MissingKeys {
+		if key == "app.timeout" {
+			missing = true
+		}
+	}
+	if !missing {
+		t.Errorf("零值默认字段应报告为缺失, 实际 %v", LastKeyReport().MissingKeys)
+	}
+}
+
+func TestLoadConfigWithDefaults_TypeMismatch(t *testing.T) {
+	ResetGlobalState()
+	configFile := writeConfigFile(t, "config.yml", "app:\n  name: demo\n")
+
+	var cfg defaultsTestConfig
+	if err := LoadConfigWithDefaults(&cfg, profileTestConfig{}, configFile); err == nil {
+		t.Error("默认值类型不一致时应返回错误")
+	}
+}
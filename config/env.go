@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// applyCollectionEnv 解析切片和 map 字段对应的环境变量，写入 viper
+//
+// viper 的 AutomaticEnv 只在配置文件中存在该键时才会在解码时读取环境变量，
+// 且无法把字符串转换为 map。这里按结构体字段显式读取:
+//   - 切片: 逗号分隔，例如 FEATURES_ALLOWED_IPS=10.0.0.1,10.0.0.2
+//   - map:  逗号分隔的 key=value，例如 FEATURES_LIMITS=read=100,write=10
+//
+// 元素两侧的空白会被去掉，空元素被忽略；环境变量设置为空字符串时得到空切片或空 map。
+func applyCollectionEnv(v *viper.Viper, config interface{}) error {
+	t := reflect.TypeOf(config)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return applyCollectionEnvFields(v, t, "", envPrefix())
+}
+
+func applyCollectionEnvFields(v *viper.Viper, t reflect.Type, prefix, envPrefix string) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		key, squash, skip := configFieldKey(sf)
+		if skip {
+			continue
+		}
+		path := key
+		if squash {
+			path = prefix
+		} else if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if isNestedConfigStruct(reflect.New(ft).Elem()) {
+			if err := applyCollectionEnvFields(v, ft, path, envPrefix); err != nil {
+				return err
+			}
+			continue
+		}
+		if !isEnvCollection(ft) {
+			continue
+		}
+
+		name := envName(envPrefix, path)
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if ft.Kind() == reflect.Slice {
+			v.Set(path, splitEnvList(raw))
+			continue
+		}
+		m, err := parseEnvMap(raw)
+		if err != nil {
+			return fmt.Errorf("环境变量 %s 格式无效（期望 k1=v1,k2=v2）: %w", name, err)
+		}
+		v.Set(path, m)
+	}
+	return nil
+}
+
+// isEnvCollection 判断字段是否为元素为基本类型的切片，或键为字符串、值为基本类型的 map
+func isEnvCollection(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice:
+		// []byte 按普通值处理
+		return t.Elem().Kind() != reflect.Uint8 && isEnvScalar(t.Elem())
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && isEnvScalar(t.Elem())
+	}
+	return false
+}
+
+func isEnvScalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// envPrefix 返回 APP_NAME 决定的环境变量前缀，与 createViperInstanceWithError 中的设置一致
+func envPrefix() string {
+	return strings.ToUpper(os.Getenv("APP_NAME"))
+}
+
+// envName 返回配置键对应的环境变量名，规则与 viper 的 AutomaticEnv 相同
+func envName(prefix, key string) string {
+	name := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	if prefix != "" {
+		return prefix + "_" + name
+	}
+	return name
+}
+
+// splitEnvList 解析逗号分隔的列表
+func splitEnvList(raw string) []string {
+	items := []string{}
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseEnvMap 解析逗号分隔的 key=value 列表，值中可以包含 =
+func parseEnvMap(raw string) (map[string]string, error) {
+	m := map[string]string{}
+	for _, item := range splitEnvList(raw) {
+		key, value, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("缺少键或 = : %q", item)
+		}
+		m[key] = strings.TrimSpace(value)
+	}
+	return m, nil
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type collectionEnvConfig struct {
+	App struct {
+		Name string `mapstructure:"name"`
+	} `mapstructure:"app"`
+	Features struct {
+		AllowedIPs []string          `mapstructure:"allowed_ips"`
+		Ports      []int             `mapstructure:"ports"`
+		Limits     map[string]int    `mapstructure:"limits"`
+		Labels     map[string]string `mapstructure:"labels"`
+	} `mapstructure:"features"`
+}
+
+const collectionEnvYAML = `
+app:
+  name: demo
+features:
+  allowed_ips:
+    - 127.0.0.1
+  limits:
+    read: 1
+`
+
+func TestLoadConfig_SliceEnvOverride(t *testing.T) {
+	ResetGlobalState()
+	configFile := writeConfigFile(t, "config.yml", collectionEnvYAML)
+
+	// allowed_ips 在配置文件中，ports 不在
+	t.Setenv("FEATURES_ALLOWED_IPS", "10.0.0.1, 10.0.0.2,,")
+	t.Setenv("FEATURES_PORTS", "80,443")
+
+	var cfg collectionEnvConfig
+	if err := LoadConfig(&cfg, configFile); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+
+	if want := []string{"10.0.0.1", "10.0.0.2"}; !reflect.DeepEqual(cfg.Features.AllowedIPs, want) {
+		t.Errorf("AllowedIPs = %v, 期望 %v", cfg.Features.AllowedIPs, want)
+	}
+	if want := []int{80, 443}; !reflect.DeepEqual(cfg.Features.Ports, want) {
+		t.Errorf("Ports = %v, 期望 %v", cfg.Features.Ports, want)
+	}
+
+	// 全局实例同样返回环境变量中的值
+	ips, err := GetStringSliceWithDefault("features.allowed_ips", nil)
+	if err != nil || !reflect.DeepEqual(ips, []string{"10.0.0.1", "10.0.0.2"}) {
+		t.Errorf("GetStringSliceWithDefault = %v, %v", ips, err)
+	}
+}
+
+func TestLoadConfig_MapEnvOverride(t *testing.T) {
+	ResetGlobalState()
+	configFile := writeConfigFile(t, "config.yml", collectionEnvYAML)
+
+	t.Setenv("FEATURES_LIMITS", "read=100, write=10")
+	t.Setenv("FEATURES_LABELS", "team=core,query=a=b")
+
+	var cfg collectionEnvConfig
+	if err := LoadConfig(&cfg, configFile); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+
+	if want := map[string]int{"read": 100, "write": 10}; !reflect.DeepEqual(cfg.Features.Limits, want) {
+		t.Errorf("Limits = %v, 期望 %v", cfg.Features.Limits, want)
+	}
+	if want := map[string]string{"team": "core", "query": "a=b"}; !reflect.DeepEqual(cfg.Features.Labels, want) {
+		t.Errorf("Labels = %v, 期望 %v", cfg.Features.Labels, want)
+	}
+}
+
+func TestLoadConfig_CollectionEnvWithPrefix(t *testing.T) {
+	ResetGlobalState()
+	configFile := writeConfigFile(t, "config.yml", collectionEnvYAML)
+
+	t.Setenv("APP_NAME", "myapp")
+	t.Setenv("MYAPP_FEATURES_ALLOWED_IPS", "192.168.0.1")
+	// 前缀模式下不读取无前缀的变量，与其他配置项一致
+	t.Setenv("FEATURES_PORTS", "80")
+
+	var cfg collectionEnvConfig
+	if err := LoadConfig(&cfg, configFile); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if want := []string{"192.168.0.1"}; !reflect.DeepEqual(cfg.Features.AllowedIPs, want) {
+		t.Errorf("AllowedIPs = %v, 期望 %v", cfg.Features.AllowedIPs, want)
+	}
+	if cfg.Features.Ports != nil {
+		t.Errorf("Ports 应保持为空, 实际 %v", cfg.Features.Ports)
+	}
+}
+
+func TestLoadConfig_EmptyAndInvalidCollectionEnv(t *testing.T) {
+	ResetGlobalState()
+	configFile := writeConfigFile(t, "config.yml", collectionEnvYAML)
+
+	t.Setenv("FEATURES_ALLOWED_IPS", "")
+	var cfg collectionEnvConfig
+	if err := LoadConfig(&cfg, configFile); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if len(cfg.Features.AllowedIPs) != 0 {
+		t.Errorf("空环境变量应清空列表, 实际 %v", cfg.Features.AllowedIPs)
+	}
+
+	t.Setenv("FEATURES_LIMITS", "read")
+	err := LoadConfig(&cfg, configFile)
+	if err == nil || !strings.Contains(err.Error(), "FEATURES_LIMITS") {
+		t.Errorf("格式无效时应返回包含变量名的错误, 实际 %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/spf13/viper"
+)
+
+// NormalizeKey 将配置键转换为规范形式：小写 snake_case，层级之间使用 "."
+//
+// 驼峰、短横线和下划线三种写法会被统一，例如
+// "Database.maxConnections"、"database.max-connections" 均转换为 "database.max_connections"。
+// 规范形式与YAML常用写法一致，对应的环境变量为 DATABASE_MAX_CONNECTIONS。
+func NormalizeKey(key string) string {
+	segments := strings.Split(key, ".")
+	for i, segment := range segments {
+		segments[i] = toSnakeCase(segment)
+	}
+	return strings.Join(segments, ".")
+}
+
+// toSnakeCase 将单个键段转换为 snake_case
+func toSnakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	b.Grow(len(s) + 4)
+
+	for i, r := range runes {
+		switch {
+		case r == '-' || r == '_':
+			if b.Len() > 0 && !strings.HasSuffix(b.String(), "_") {
+				b.WriteByte('_')
+			}
+		case unicode.IsUpper(r):
+			if i > 0 && b.Len() > 0 && !strings.HasSuffix(b.String(), "_") {
+				prev := runes[i-1]
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				// fooBar -> foo_bar，HTTPServer -> http_server
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+					b.WriteByte('_')
+				}
+			}
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// compactKey 返回忽略大小写、短横线和下划线后的键，用于宽松匹配
+// viper 会将文件中的 "maxConnections" 存为 "maxconnections"，无法还原单词边界，
+// 因此匹配时去掉所有分隔符再比较
+func compactKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, key)
+}
+
+// resolveKey 在viper实例中查找与 key 等价的实际键（调用方需持有 globalMutex）
+//
+// 查找顺序：原始键 -> 规范形式（兼容环境变量）-> 已加载键的宽松匹配。
+// 找不到时返回原始键，保持默认值行为不变。
+func resolveKey(client *viper.Viper, key string) string {
+	if client.IsSet(key) {
+		return key
+	}
+	if normalized := NormalizeKey(key); normalized != key && client.IsSet(normalized) {
+		return normalized
+	}
+
+	target := compactKey(key)
+	depth := strings.Count(key, ".") + 1
+	for _, candidate := range client.AllKeys() {
+		segments := strings.Split(candidate, ".")
+		if len(segments) < depth {
+			continue
+		}
+		// 允许匹配父级键，例如 GetStringMap("database.connPool") 对应 database.conn_pool.*
+		prefix := strings.Join(segments[:depth], ".")
+		if compactKey(prefix) == target {
+			return prefix
+		}
+	}
+	return key
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeKey(t *testing.T) {
+	tests := map[string]string{
+		"database.max_connections": "database.max_connections",
+		"database.maxConnections":  "database.max_connections",
+		"database.max-connections": "database.max_connections",
+		"Database.MaxConnections":  "database.max_connections",
+		"server.HTTPServerPort":    "server.http_server_port",
+		"app.v2Enabled":            "app.v2_enabled",
+		"app.name":                 "app.name",
+	}
+	for input, want := range tests {
+		if got := NormalizeKey(input); got != want {
+			t.Errorf("NormalizeKey(%q) = %q, 期望 %q", input, got, want)
+		}
+	}
+}
+
+func loadKeysTestConfig(t *testing.T, content string) {
+	t.Helper()
+	ResetGlobalState()
+	ClearOverrides()
+	t.Cleanup(ResetGlobalState)
+
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("创建临时配置文件失败: %v", err)
+	}
+	if _, err := GetClient(configFile); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+}
+
+func TestKeyLookupAcrossSpellings(t *testing.T) {
+	spellings := []string{
+		"database.max_connections",
+		"database.maxConnections",
+		"database.max-connections",
+	}
+
+	// 配置文件中分别使用三种写法
+	fileKeys := []string{"max_connections", "maxConnections", "max-connections"}
+	for _, fileKey := range fileKeys {
+		t.Run("file:"+fileKey, func(t *testing.T) {
+			loadKeysTestConfig(t, "database:\n  "+fileKey+": 42\n")
+
+			for _, key := range spellings {
+				value, err := GetIntWithDefault(key, 1)
+				if err != nil {
+					t.Fatalf("获取配置失败: %v", err)
+				}
+				if value != 42 {
+					t.Errorf("GetIntWithDefault(%q) = %d, 期望 42", key, value)
+				}
+				if ok, _ := IsSet(key); !ok {
+					t.Errorf("IsSet(%q) 期望为 true", key)
+				}
+			}
+		})
+	}
+}
+
+func TestKeyLookupFromEnv(t *testing.T) {
+	loadKeysTestConfig(t, "app:\n  name: test\n")
+
+	t.Setenv("DATABASE_MAX_CONNECTIONS", "64")
+
+	for _, key := range []string{"database.max_connections", "database.maxConnections", "database.max-connections"} {
+		value, _ := GetIntWithDefault(key, 1)
+		if value != 64 {
+			t.Errorf("GetIntWithDefault(%q) = %d, 期望从环境变量读取 64", key, value)
+		}
+	}
+}
+
+func TestKeyLookupNestedMap(t *testing.T) {
+	loadKeysTestConfig(t, "database:\n  conn_pool:\n    size: 10\n")
+
+	pool, err := GetStringMapWithDefault("database.connPool", nil)
+	if err != nil {
+		t.Fatalf("获取配置失败: %v", err)
+	}
+	if len(pool) != 1 {
+		t.Errorf("期望读取到 conn_pool 子配置, 实际 = %v", pool)
+	}
+}
+
+func TestKeyLookupMissingUsesDefault(t *testing.T) {
+	loadKeysTestConfig(t, "database:\n  max_connections: 42\n")
+
+	value, _ := GetIntWithDefault("database.minConnections", 5)
+	if value != 5 {
+		t.Errorf("期望不存在的键返回默认值 5, 实际 = %d", value)
+	}
+}
+
+func TestOverrideLookupAcrossSpellings(t *testing.T) {
+	loadKeysTestConfig(t, "database:\n  max_connections: 42\n")
+	defer ClearOverrides()
+
+	SetOverride("database.maxConnections", 100)
+
+	value, _ := GetIntWithDefault("database.max_connections", 1)
+	if value != 100 {
+		t.Errorf("期望覆盖值对不同写法生效, 实际 = %d", value)
+	}
+}
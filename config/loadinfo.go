@@ -0,0 +1,117 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/tsopia/go-kit/logger"
+)
+
+// lastLoadInfo 当前全局viper实例的加载信息，由 globalMutex 保护
+var lastLoadInfo *LoadMetadata
+
+// LoadMetadata 配置的加载信息，用于排查"实际加载了哪个配置文件"
+type LoadMetadata struct {
+	// Path 加载的配置文件的绝对路径
+	Path string `json:"path"`
+	// ModTime 加载时配置文件的修改时间
+	ModTime time.Time `json:"mod_time"`
+	// Checksum 加载时配置文件内容的 SHA-256（十六进制），可与版本库中的文件对比
+	Checksum string `json:"checksum"`
+	// EnvPrefix 环境变量前缀（APP_NAME 转为大写），未设置 APP_NAME 时为空
+	EnvPrefix string `json:"env_prefix"`
+	// EnvOverrides 被环境变量覆盖的配置键数量
+	EnvOverrides int `json:"env_overrides"`
+	// EnvOverrideKeys 被环境变量覆盖的配置键，已排序；只包含键名，不包含值
+	EnvOverrideKeys []string `json:"env_override_keys"`
+	// LoadedAt 加载时间
+	LoadedAt time.Time `json:"loaded_at"`
+}
+
+// LoadInfo 返回当前配置的加载信息，尚未加载配置时返回 nil
+//
+// LoadConfig、GetClient 首次初始化和 Reload 都会更新加载信息；
+// 已初始化后重复调用 GetClient 不会改变它。
+//
+// 示例:
+//
+//	if info := config.LoadInfo(); info != nil {
+//	    fmt.Printf("配置文件 %s sha256=%s\n", info.Path, info.Checksum)
+//	}
+func LoadInfo() *LoadMetadata {
+	globalMutex.RLock()
+	defer globalMutex.RUnlock()
+
+	if lastLoadInfo == nil {
+		return nil
+	}
+	info := *lastLoadInfo
+	info.EnvOverrideKeys = append([]string(nil), lastLoadInfo.EnvOverrideKeys...)
+	return &info
+}
+
+// LogLoadInfo 以结构化字段输出当前配置的加载信息，尚未加载配置时输出警告
+//
+// 示例:
+//
+//	if err := config.LoadConfig(&cfg); err != nil {
+//	    log.Fatal(err)
+//	}
+//	config.LogLoadInfo(logger.GetDefaultLogger())
+//	// {"msg": "配置已加载", "config_path": "/app/config.yml", "config_checksum": "9f86d0...", ...}
+func LogLoadInfo(l *logger.Logger) {
+	info := LoadInfo()
+	if info == nil {
+		l.Warn("配置尚未加载")
+		return
+	}
+	l.Info("配置已加载",
+		"config_path", info.Path,
+		"config_mod_time", info.ModTime,
+		"config_checksum", info.Checksum,
+		"config_env_prefix", info.EnvPrefix,
+		"config_env_overrides", info.EnvOverrides,
+		"config_env_override_keys", info.EnvOverrideKeys,
+		"config_loaded_at", info.LoadedAt,
+	)
+}
+
+// buildLoadInfo 在 v 读取配置文件并合并环境变量后生成加载信息
+//
+// 校验和重新读取配置文件计算，与 viper 读取之间的修改不会被发现，这在排查场景中可以接受。
+func buildLoadInfo(v *viper.Viper) *LoadMetadata {
+	info := &LoadMetadata{
+		EnvPrefix:       envPrefix(),
+		EnvOverrideKeys: []string{},
+		LoadedAt:        time.Now(),
+	}
+
+	if path := v.ConfigFileUsed(); path != "" {
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		info.Path = path
+		if stat, err := os.Stat(path); err == nil {
+			info.ModTime = stat.ModTime()
+		}
+		if data, err := os.ReadFile(path); err == nil {
+			sum := sha256.Sum256(data)
+			info.Checksum = hex.EncodeToString(sum[:])
+		}
+	}
+
+	// AllowEmptyEnv 开启时设置为空字符串的环境变量同样覆盖配置文件
+	for _, key := range v.AllKeys() {
+		if _, ok := os.LookupEnv(envName(info.EnvPrefix, key)); ok {
+			info.EnvOverrideKeys = append(info.EnvOverrideKeys, key)
+		}
+	}
+	sort.Strings(info.EnvOverrideKeys)
+	info.EnvOverrides = len(info.EnvOverrideKeys)
+	return info
+}
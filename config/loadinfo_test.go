@@ -0,0 +1,176 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/tsopia/go-kit/logger"
+	"github.com/tsopia/go-kit/logger/loggertest"
+)
+
+// setAppName 设置 APP_NAME，为空时删除（空值的 APP_NAME 同样会覆盖 app.name），测试结束后恢复
+func setAppName(t *testing.T, appName string) {
+	t.Helper()
+	t.Setenv("APP_NAME", appName)
+	if appName == "" {
+		os.Unsetenv("APP_NAME")
+	}
+}
+
+func TestLoadInfoPathAndChecksum(t *testing.T) {
+	ResetGlobalState()
+	clearProfileEnv(t)
+	setAppName(t, "")
+	if LoadInfo() != nil {
+		t.Fatal("尚未加载配置时 LoadInfo 应返回 nil")
+	}
+
+	configFile := writeProfileFiles(t, map[string]string{"config.yml": profileBaseYAML})
+	var cfg profileTestConfig
+	if err := LoadConfig(&cfg, configFile); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+
+	info := LoadInfo()
+	if info == nil {
+		t.Fatal("加载配置后 LoadInfo 不应为 nil")
+	}
+	abs, _ := filepath.Abs(configFile)
+	if info.Path != abs {
+		t.Errorf("Path = %q, 期望 %q", info.Path, abs)
+	}
+	sum := sha256.Sum256([]byte(profileBaseYAML))
+	if info.Checksum != hex.EncodeToString(sum[:]) {
+		t.Errorf("Checksum = %q, 期望 %x", info.Checksum, sum)
+	}
+	stat, _ := os.Stat(configFile)
+	if !info.ModTime.Equal(stat.ModTime()) {
+		t.Errorf("ModTime = %v, 期望 %v", info.ModTime, stat.ModTime())
+	}
+	if info.LoadedAt.IsZero() || info.EnvPrefix != "" || info.EnvOverrides != 0 {
+		t.Errorf("意外的加载信息 %+v", info)
+	}
+
+	// Reload 后按新内容更新
+	updated := "database:\n  host: new-db\n"
+	if err := os.WriteFile(configFile, []byte(updated), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Reload(); err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+	sum = sha256.Sum256([]byte(updated))
+	if got := LoadInfo(); got.Checksum != hex.EncodeToString(sum[:]) || got.LoadedAt.Before(info.LoadedAt) {
+		t.Errorf("Reload 后加载信息未更新: %+v", got)
+	}
+}
+
+func TestLoadInfoEnvOverrides(t *testing.T) {
+	tests := []struct {
+		name    string
+		appName string
+		env     map[string]string
+		prefix  string
+	}{
+		{
+			name:   "无前缀",
+			env:    map[string]string{"DATABASE_HOST": "env-db", "DATABASE_PORT": "6543", "MYAPP_APP_NAME": "ignored"},
+			prefix: "",
+		},
+		{
+			name:    "APP_NAME前缀",
+			appName: "myapp",
+			env:     map[string]string{"MYAPP_DATABASE_HOST": "env-db", "MYAPP_DATABASE_PORT": "6543", "DATABASE_USER": "ignored"},
+			prefix:  "MYAPP",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetGlobalState()
+			clearProfileEnv(t)
+			setAppName(t, tt.appName)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			configFile := writeProfileFiles(t, map[string]string{"config.yml": profileBaseYAML})
+			var cfg profileTestConfig
+			if err := LoadConfig(&cfg, configFile); err != nil {
+				t.Fatalf("加载配置失败: %v", err)
+			}
+			if cfg.Database.Host != "env-db" {
+				t.Fatalf("环境变量未生效: %+v", cfg)
+			}
+
+			info := LoadInfo()
+			want := []string{"database.host", "database.port"}
+			if info.EnvPrefix != tt.prefix || info.EnvOverrides != 2 || !reflect.DeepEqual(info.EnvOverrideKeys, want) {
+				t.Errorf("期望前缀 %q 覆盖 %v, 实际 %+v", tt.prefix, want, info)
+			}
+		})
+	}
+}
+
+func TestLoadInfoStableAcrossGetClient(t *testing.T) {
+	ResetGlobalState()
+	clearProfileEnv(t)
+	setAppName(t, "")
+	configFile := writeProfileFiles(t, map[string]string{"config.yml": profileBaseYAML})
+
+	// GetClient 首次初始化时生成加载信息
+	if _, err := GetClient(configFile); err != nil {
+		t.Fatalf("获取配置客户端失败: %v", err)
+	}
+	first := LoadInfo()
+	if first == nil || first.Checksum == "" {
+		t.Fatalf("GetClient 初始化后应有加载信息, 实际 %+v", first)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := GetClient(configFile); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if again := LoadInfo(); !reflect.DeepEqual(first, again) {
+		t.Errorf("重复调用 GetClient 不应改变加载信息: %+v != %+v", first, again)
+	}
+
+	// 返回的是副本
+	first.EnvOverrideKeys = append(first.EnvOverrideKeys, "mutated")
+	if len(LoadInfo().EnvOverrideKeys) != 0 {
+		t.Error("修改返回值不应影响内部状态")
+	}
+
+	Cleanup()
+	if LoadInfo() != nil {
+		t.Error("Cleanup 后 LoadInfo 应返回 nil")
+	}
+}
+
+func TestLogLoadInfo(t *testing.T) {
+	ResetGlobalState()
+	clearProfileEnv(t)
+	setAppName(t, "")
+	l, rec := loggertest.NewTestLogger()
+
+	LogLoadInfo(l)
+	rec.AssertContains(t, logger.WarnLevel, "配置尚未加载")
+
+	configFile := writeProfileFiles(t, map[string]string{"config.yml": profileBaseYAML})
+	var cfg profileTestConfig
+	if err := LoadConfig(&cfg, configFile); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	info := LoadInfo()
+	LogLoadInfo(l)
+	rec.AssertContains(t, logger.InfoLevel, "配置已加载",
+		"config_path", info.Path,
+		"config_checksum", info.Checksum,
+		"config_env_overrides", 0,
+	)
+}
@@ -0,0 +1,272 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	// overrides 覆盖层，优先于环境变量和配置文件，不修改共享的viper实例
+	overrides     = make(map[string]interface{})
+	overrideMutex sync.RWMutex
+
+	// runtimeOverrideKeys 通过 UntilReload 设置的键，下次加载配置文件时清除
+	runtimeOverrideKeys = make(map[string]bool)
+)
+
+// OverrideOption 配置 SetOverride 的行为
+type OverrideOption func(*overrideOptions)
+
+type overrideOptions struct {
+	untilReload bool
+}
+
+// UntilReload 覆盖值只保留到下一次加载配置文件（LoadConfig 等）成功为止
+//
+// 适合运维接口临时开关功能、调整日志级别等场景，重新加载配置文件后恢复为文件中的值。
+func UntilReload() OverrideOption {
+	return func(o *overrideOptions) {
+		o.untilReload = true
+	}
+}
+
+// SetOverride 设置配置覆盖值（线程安全）
+//
+// 覆盖值优先于环境变量和配置文件（覆盖值 > 环境变量 > 配置文件 > 默认值），
+// 对所有 GetXxxWithDefault 函数、IsSet、AllKeys 以及 UnmarshalWithOverrides 立即可见，
+// 但不会修改 GetClient 返回的viper实例。
+//
+// 默认情况下覆盖值在重新加载配置后仍然保留；传入 UntilReload 时只保留到下一次加载配置文件。
+//
+// 使用场景:
+//   - ✅ 测试中临时修改配置
+//   - ✅ 命令行参数覆盖配置文件
+//   - ✅ 运行时开关功能（配合 UntilReload）
+//
+// 示例:
+//
+//	config.SetOverride("app.debug", true)
+//	defer config.ClearOverrides()
+//
+//	config.SetOverride("features.new_ui", true, config.UntilReload())
+//	config.DeleteOverride("features.new_ui") // 恢复为环境变量或配置文件中的值
+func SetOverride(key string, value interface{}, opts ...OverrideOption) {
+	var options overrideOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.untilReload {
+		// 持有 globalMutex，避免与并发的配置加载交错
+		globalMutex.Lock()
+		defer globalMutex.Unlock()
+	}
+	overrideMutex.Lock()
+	defer overrideMutex.Unlock()
+
+	key = strings.ToLower(key)
+	overrides[key] = value
+	if options.untilReload {
+		runtimeOverrideKeys[key] = true
+	} else {
+		delete(runtimeOverrideKeys, key)
+	}
+}
+
+// DeleteOverride 删除指定键的覆盖值，之后读取环境变量、配置文件或默认值
+func DeleteOverride(key string) {
+	overrideMutex.Lock()
+	defer overrideMutex.Unlock()
+	key = strings.ToLower(key)
+	delete(overrides, key)
+	delete(runtimeOverrideKeys, key)
+}
+
+// ClearOverrides 清除所有覆盖值
+func ClearOverrides() {
+	overrideMutex.Lock()
+	defer overrideMutex.Unlock()
+	overrides = make(map[string]interface{})
+	runtimeOverrideKeys = make(map[string]bool)
+}
+
+// clearRuntimeOverridesLocked 清除 UntilReload 设置的覆盖值，调用方需持有 globalMutex
+func clearRuntimeOverridesLocked() {
+	overrideMutex.Lock()
+	defer overrideMutex.Unlock()
+	for key := range runtimeOverrideKeys {
+		delete(overrides, key)
+	}
+	runtimeOverrideKeys = make(map[string]bool)
+}
+
+// CleanupRegistrar 支持注册清理函数的测试对象，*testing.T 和 *testing.B 均满足该接口
+type CleanupRegistrar interface {
+	Cleanup(func())
+}
+
+// WithOverrides 在测试期间设置覆盖值，并通过 t.Cleanup 自动恢复
+//
+// 只恢复本次设置的键（恢复为之前的覆盖值及其 UntilReload 属性，或删除），
+// 因此并行测试使用不同的键时互不影响。
+//
+// 示例:
+//
+//	func TestFeature(t *testing.T) {
+//	    config.WithOverrides(t, map[string]interface{}{
+//	        "features.new_ui": true,
+//	        "app.port":        9090,
+//	    })
+//	    // ...
+//	}
+func WithOverrides(t CleanupRegistrar, kv map[string]interface{}) {
+	overrideMutex.Lock()
+	previous := make(map[string]interface{}, len(kv))
+	existed := make(map[string]bool, len(kv))
+	untilReload := make(map[string]bool, len(kv))
+	for key, value := range kv {
+		key = strings.ToLower(key)
+		previous[key], existed[key] = overrides[key]
+		untilReload[key] = runtimeOverrideKeys[key]
+		overrides[key] = value
+		delete(runtimeOverrideKeys, key)
+	}
+	overrideMutex.Unlock()
+
+	t.Cleanup(func() {
+		overrideMutex.Lock()
+		defer overrideMutex.Unlock()
+		for key := range previous {
+			if existed[key] {
+				overrides[key] = previous[key]
+			} else {
+				delete(overrides, key)
+			}
+			if untilReload[key] {
+				runtimeOverrideKeys[key] = true
+			} else {
+				delete(runtimeOverrideKeys, key)
+			}
+		}
+	})
+}
+
+// UnmarshalWithOverrides 将当前配置（含覆盖值）解析到结构体中
+//
+// 与 LoadConfig 不同，它使用已初始化的全局配置，并在一个临时viper实例上应用覆盖值，
+// 共享的viper实例不会被修改。
+//
+// 示例:
+//
+//	config.WithOverrides(t, map[string]interface{}{"app.port": 9090})
+//	var cfg AppConfig
+//	err := config.UnmarshalWithOverrides(&cfg)
+func UnmarshalWithOverrides(config interface{}) error {
+	client, err := GetClient()
+	if err != nil {
+		return err
+	}
+
+	globalMutex.RLock()
+	settings := client.AllSettings()
+	globalMutex.RUnlock()
+
+	merged := viper.New()
+	if err := merged.MergeConfigMap(settings); err != nil {
+		return fmt.Errorf("合并配置失败: %w", err)
+	}
+
+	overrideMutex.RLock()
+	for key, value := range overrides {
+		merged.Set(key, value)
+	}
+	overrideMutex.RUnlock()
+
+	if err := merged.Unmarshal(config); err != nil {
+		return fmt.Errorf("解析配置到结构体失败: %w", err)
+	}
+	return nil
+}
+
+// clientForKey 返回用于读取指定键的viper实例，以及该实例中实际使用的键
+// 存在覆盖值时返回只包含该值的临时实例，保证类型转换规则与viper一致；
+// 否则按 resolveKey 的规则解析键的不同写法
+func clientForKey(key string) (*viper.Viper, string, error) {
+	if value, ok := lookupOverride(key); ok {
+		v := viper.New()
+		v.Set(key, value)
+		return v, key, nil
+	}
+
+	client, err := GetClient()
+	if err != nil {
+		return nil, key, err
+	}
+
+	globalMutex.RLock()
+	resolved := resolveKey(client, key)
+	globalMutex.RUnlock()
+
+	return client, resolved, nil
+}
+
+// lookupOverride 查找覆盖值，键的大小写、短横线和下划线写法视为等价
+func lookupOverride(key string) (interface{}, bool) {
+	overrideMutex.RLock()
+	defer overrideMutex.RUnlock()
+
+	if value, ok := overrides[strings.ToLower(key)]; ok {
+		return value, true
+	}
+	target := compactKey(key)
+	for k, value := range overrides {
+		if compactKey(k) == target {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// hasOverride 判断键（或其子键）是否存在覆盖值
+func hasOverride(key string) bool {
+	key = compactKey(key)
+	prefix := key + "."
+
+	overrideMutex.RLock()
+	defer overrideMutex.RUnlock()
+
+	for k := range overrides {
+		k = compactKey(k)
+		if k == key || strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeOverrideKeys 合并覆盖值的键
+func mergeOverrideKeys(keys []string) []string {
+	overrideMutex.RLock()
+	defer overrideMutex.RUnlock()
+
+	if len(overrides) == 0 {
+		return keys
+	}
+
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		seen[key] = true
+	}
+	var extra []string
+	for key := range overrides {
+		if !seen[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	return append(keys, extra...)
+}
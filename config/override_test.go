@@ -0,0 +1,307 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func loadOverrideTestConfig(t *testing.T) {
+	t.Helper()
+	ResetGlobalState()
+	ClearOverrides()
+
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	configContent := `
+app:
+  name: "File App"
+  port: 8080
+  debug: false
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("创建临时配置文件失败: %v", err)
+	}
+
+	var cfg TestConfig
+	if err := LoadConfig(&cfg, configFile); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+}
+
+func TestSetOverride(t *testing.T) {
+	loadOverrideTestConfig(t)
+	defer ClearOverrides()
+
+	os.Setenv("APP_PORT", "7070")
+	defer os.Unsetenv("APP_PORT")
+
+	SetOverride("app.port", 9090)
+	SetOverride("App.Timeout", "5s")
+
+	port, err := GetIntWithDefault("app.port", 1)
+	if err != nil {
+		t.Fatalf("获取配置失败: %v", err)
+	}
+	if port != 9090 {
+		t.Errorf("期望覆盖值优先于环境变量和文件, 实际 = %d", port)
+	}
+
+	timeout, _ := GetDurationWithDefault("app.timeout", time.Second)
+	if timeout != 5*time.Second {
+		t.Errorf("期望 app.timeout = 5s, 实际 = %v", timeout)
+	}
+
+	// 未覆盖的键仍然读取配置文件
+	name, _ := GetStringWithDefault("app.name", "")
+	if name != "File App" {
+		t.Errorf("期望 app.name = 'File App', 实际 = '%s'", name)
+	}
+
+	// 共享的viper实例不应被修改
+	client := MustGetClient()
+	if client.GetInt("app.port") == 9090 {
+		t.Error("覆盖值不应写入共享的viper实例")
+	}
+
+	ClearOverrides()
+	port, _ = GetIntWithDefault("app.port", 1)
+	if port != 7070 {
+		t.Errorf("清除覆盖后期望读取环境变量 7070, 实际 = %d", port)
+	}
+}
+
+func TestOverrideIsSetAndAllKeys(t *testing.T) {
+	loadOverrideTestConfig(t)
+	defer ClearOverrides()
+
+	SetOverride("feature.flags.new_ui", true)
+
+	for _, key := range []string{"feature.flags.new_ui", "feature.flags", "feature"} {
+		if exists, err := IsSet(key); err != nil || !exists {
+			t.Errorf("期望 IsSet(%q) = true, 实际 = %v, err = %v", key, exists, err)
+		}
+	}
+
+	keys, err := AllKeys()
+	if err != nil {
+		t.Fatalf("获取所有键失败: %v", err)
+	}
+	found := false
+	for _, key := range keys {
+		if key == "feature.flags.new_ui" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("期望 AllKeys 包含覆盖键, 实际 = %v", keys)
+	}
+}
+
+func TestUnmarshalWithOverrides(t *testing.T) {
+	loadOverrideTestConfig(t)
+
+	WithOverrides(t, map[string]interface{}{
+		"app.port":  9191,
+		"app.debug": true,
+	})
+
+	var cfg TestConfig
+	if err := UnmarshalWithOverrides(&cfg); err != nil {
+		t.Fatalf("解析配置失败: %v", err)
+	}
+	if cfg.App.Port != 9191 {
+		t.Errorf("期望 App.Port = 9191, 实际 = %d", cfg.App.Port)
+	}
+	if !cfg.App.Debug {
+		t.Error("期望 App.Debug = true")
+	}
+	if cfg.App.Name != "File App" {
+		t.Errorf("期望 App.Name = 'File App', 实际 = '%s'", cfg.App.Name)
+	}
+}
+
+func TestWithOverridesCleanup(t *testing.T) {
+	loadOverrideTestConfig(t)
+	defer ClearOverrides()
+
+	SetOverride("app.name", "outer")
+
+	t.Run("inner", func(t *testing.T) {
+		WithOverrides(t, map[string]interface{}{
+			"app.name": "inner",
+			"app.port": 1234,
+		})
+		name, _ := GetStringWithDefault("app.name", "")
+		if name != "inner" {
+			t.Errorf("期望 app.name = 'inner', 实际 = '%s'", name)
+		}
+	})
+
+	// 子测试结束后恢复之前的覆盖值，并删除新增的键
+	name, _ := GetStringWithDefault("app.name", "")
+	if name != "outer" {
+		t.Errorf("期望恢复为 'outer', 实际 = '%s'", name)
+	}
+	port, _ := GetIntWithDefault("app.port", 0)
+	if port != 8080 {
+		t.Errorf("期望 app.port 恢复为文件值 8080, 实际 = %d", port)
+	}
+}
+
+func TestWithOverridesParallelIsolation(t *testing.T) {
+	loadOverrideTestConfig(t)
+	defer ClearOverrides()
+
+	t.Run("group", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			i := i
+			t.Run(fmt.Sprintf("subtest-%d", i), func(t *testing.T) {
+				t.Parallel()
+
+				key := fmt.Sprintf("parallel.worker_%d.value", i)
+				WithOverrides(t, map[string]interface{}{key: i})
+
+				for j := 0; j < 50; j++ {
+					value, err := GetIntWithDefault(key, -1)
+					if err != nil {
+						t.Fatalf("获取配置失败: %v", err)
+					}
+					if value != i {
+						t.Fatalf("期望 %s = %d, 实际 = %d", key, i, value)
+					}
+					if exists, _ := IsSet(key); !exists {
+						t.Fatalf("期望 IsSet(%s) = true", key)
+					}
+				}
+			})
+		}
+	})
+
+	// 所有并行子测试结束后不应残留覆盖值
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("parallel.worker_%d.value", i)
+		if hasOverride(key) {
+			t.Errorf("期望 %s 的覆盖值已被清理", key)
+		}
+	}
+}
+
+func TestSetOverrideUntilReloadAndDelete(t *testing.T) {
+	loadOverrideTestConfig(t)
+	defer ClearOverrides()
+
+	os.Setenv("APP_PORT", "7070")
+	defer os.Unsetenv("APP_PORT")
+
+	SetOverride("App.Debug", true, UntilReload())
+	SetOverride("app.port", 9090, UntilReload())
+
+	debug, err := GetBoolWithDefault("app.debug", false)
+	if err != nil {
+		t.Fatalf("获取配置失败: %v", err)
+	}
+	if !debug {
+		t.Error("期望覆盖值立即生效")
+	}
+	port, _ := GetIntWithDefault("app.port", 1)
+	if port != 9090 {
+		t.Errorf("期望覆盖值优先于环境变量, 实际 = %d", port)
+	}
+
+	DeleteOverride("app.debug")
+	debug, _ = GetBoolWithDefault("app.debug", true)
+	if debug {
+		t.Error("清除覆盖后期望读取配置文件中的 false")
+	}
+	port, _ = GetIntWithDefault("app.port", 1)
+	if port != 9090 {
+		t.Errorf("DeleteOverride 不应影响其他键, 实际 = %d", port)
+	}
+
+	DeleteOverride("app.port")
+	port, _ = GetIntWithDefault("app.port", 1)
+	if port != 7070 {
+		t.Errorf("清除覆盖后期望读取环境变量 7070, 实际 = %d", port)
+	}
+}
+
+func TestSetOverrideUntilReloadClearedOnReload(t *testing.T) {
+	loadOverrideTestConfig(t)
+	defer ClearOverrides()
+
+	SetOverride("app.name", "Runtime App", UntilReload())
+	SetOverride("app.port", 9090)
+
+	name, _ := GetStringWithDefault("app.name", "")
+	if name != "Runtime App" {
+		t.Fatalf("期望 app.name = 'Runtime App', 实际 = '%s'", name)
+	}
+
+	// 重新加载配置文件后 UntilReload 设置的覆盖值失效，其他覆盖值保留
+	var cfg TestConfig
+	if err := LoadConfig(&cfg, MustGetClient().ConfigFileUsed()); err != nil {
+		t.Fatalf("重新加载配置失败: %v", err)
+	}
+
+	name, _ = GetStringWithDefault("app.name", "")
+	if name != "File App" {
+		t.Errorf("重新加载后期望 app.name = 'File App', 实际 = '%s'", name)
+	}
+	port, _ := GetIntWithDefault("app.port", 1)
+	if port != 9090 {
+		t.Errorf("重新加载后期望保留 SetOverride 的值 9090, 实际 = %d", port)
+	}
+}
+
+func TestWithOverridesRestoresUntilReload(t *testing.T) {
+	loadOverrideTestConfig(t)
+	defer ClearOverrides()
+
+	SetOverride("app.name", "Runtime App", UntilReload())
+	t.Run("scoped", func(t *testing.T) {
+		WithOverrides(t, map[string]interface{}{"app.name": "Test App"})
+
+		name, _ := GetStringWithDefault("app.name", "")
+		if name != "Test App" {
+			t.Errorf("期望 app.name = 'Test App', 实际 = '%s'", name)
+		}
+	})
+
+	name, _ := GetStringWithDefault("app.name", "")
+	if name != "Runtime App" {
+		t.Fatalf("期望恢复 app.name = 'Runtime App', 实际 = '%s'", name)
+	}
+
+	// 恢复后的覆盖值仍然只保留到下一次加载
+	var cfg TestConfig
+	if err := LoadConfig(&cfg, MustGetClient().ConfigFileUsed()); err != nil {
+		t.Fatalf("重新加载配置失败: %v", err)
+	}
+	name, _ = GetStringWithDefault("app.name", "")
+	if name != "File App" {
+		t.Errorf("重新加载后期望 app.name = 'File App', 实际 = '%s'", name)
+	}
+}
+
+func TestSetOverrideUntilReloadConcurrent(t *testing.T) {
+	loadOverrideTestConfig(t)
+	defer ClearOverrides()
+
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		go func(i int) {
+			defer func() { done <- struct{}{} }()
+			key := fmt.Sprintf("feature.flag%d", i)
+			for j := 0; j < 100; j++ {
+				SetOverride(key, j%2 == 0, UntilReload())
+				_, _ = GetBoolWithDefault(key, false)
+				DeleteOverride(key)
+			}
+		}(i)
+	}
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// ProfileKey 当前环境（profile）在配置中的键，LoadConfigWithProfile 会写入该键
+	ProfileKey = "app.profile"
+
+	// profilesKey 配置文件中按环境划分的配置段
+	profilesKey = "profiles"
+)
+
+// activeProfile 最近一次成功加载配置时使用的环境，由 globalMutex 保护
+var activeProfile string
+
+// LoadConfigWithProfile 按环境（dev/staging/prod 等）加载配置
+//
+// profile 为空时依次读取 APP_ENV、GO_ENV 环境变量，都为空时与 LoadConfig 相同。
+// 加载顺序（后者覆盖前者，嵌套的键逐层合并）:
+//  1. 基础配置文件，例如 config.yml
+//  2. 基础配置文件中的 profiles.{profile} 段，适合单文件配置
+//  3. 同目录下的 config.{profile}.yml，不存在时跳过
+//  4. 环境变量，优先级最高，与 LoadConfig 相同
+//
+// 当前环境写入 app.profile 键（结构体中可以声明对应字段），也可以通过 ActiveProfile 获取。
+// profiles 段不会被 LoadConfigStrict 视为未知的键。
+//
+// 示例:
+//
+//	# config.yml
+//	database:
+//	  host: localhost
+//	  port: 5432
+//	profiles:
+//	  prod:
+//	    database:
+//	      host: db.internal
+//
+//	// APP_ENV=prod 时 database.host 为 db.internal，database.port 仍为 5432
+//	err := config.LoadConfigWithProfile(&cfg, "")
+func LoadConfigWithProfile(config interface{}, profile string, filePath ...string) error {
+	return loadConfig(config, false, resolveProfile(profile), nil, filePath...)
+}
+
+// ActiveProfile 返回最近一次成功加载配置时使用的环境，未使用环境时返回空字符串
+func ActiveProfile() string {
+	globalMutex.RLock()
+	defer globalMutex.RUnlock()
+	return activeProfile
+}
+
+// resolveProfile 未指定环境时从 APP_ENV、GO_ENV 读取
+func resolveProfile(profile string) string {
+	if profile = strings.TrimSpace(profile); profile != "" {
+		return profile
+	}
+	for _, name := range []string{"APP_ENV", "GO_ENV"} {
+		if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// applyProfile 将 profiles.{profile} 段和环境配置文件合并到已读取基础配置的 viper 实例
+func applyProfile(v *viper.Viper, profile string) error {
+	if profile == "" {
+		return nil
+	}
+	if profile != filepath.Base(profile) || strings.ContainsAny(profile, `/\`) {
+		return fmt.Errorf("环境名称无效: %q", profile)
+	}
+
+	if section := v.GetStringMap(profilesKey + "." + profile); len(section) > 0 {
+		if err := v.MergeConfigMap(section); err != nil {
+			return fmt.Errorf("合并 %s.%s 配置段失败: %w", profilesKey, profile, err)
+		}
+	}
+
+	if overlay := profileFilePath(v.ConfigFileUsed(), profile); overlay != "" {
+		data, err := os.ReadFile(overlay)
+		switch {
+		case os.IsNotExist(err):
+		case err != nil:
+			return fmt.Errorf("读取环境配置文件失败: %w", err)
+		default:
+			if err := v.MergeConfig(bytes.NewReader(data)); err != nil {
+				return fmt.Errorf("合并环境配置文件 %s 失败: %w", overlay, err)
+			}
+		}
+	}
+
+	v.Set(ProfileKey, profile)
+	return nil
+}
+
+// profileFilePath 返回基础配置文件对应的环境配置文件路径，例如 config.yml -> config.prod.yml
+func profileFilePath(base, profile string) string {
+	if base == "" {
+		return ""
+	}
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "." + profile + ext
+}
+
+// isProfilesKey 判断键是否位于 profiles 段中
+func isProfilesKey(key string) bool {
+	return key == profilesKey || strings.HasPrefix(key, profilesKey+".")
+}
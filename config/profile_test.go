@@ -0,0 +1,143 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type profileTestConfig struct {
+	App struct {
+		Name    string `mapstructure:"name"`
+		Profile string `mapstructure:"profile"`
+	} `mapstructure:"app"`
+	Database struct {
+		Host string `mapstructure:"host"`
+		Port int    `mapstructure:"port"`
+	} `mapstructure:"database"`
+}
+
+const profileBaseYAML = `
+app:
+  name: demo
+database:
+  host: localhost
+  port: 5432
+profiles:
+  staging:
+    database:
+      host: staging-db
+`
+
+func writeProfileFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("创建临时配置文件失败: %v", err)
+		}
+	}
+	return filepath.Join(dir, "config.yml")
+}
+
+func clearProfileEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("APP_ENV", "")
+	t.Setenv("GO_ENV", "")
+}
+
+func TestLoadConfigWithProfile_OverlayFile(t *testing.T) {
+	ResetGlobalState()
+	clearProfileEnv(t)
+	configFile := writeProfileFiles(t, map[string]string{
+		"config.yml":      profileBaseYAML,
+		"config.prod.yml": "database:\n  host: prod-db\n",
+	})
+
+	var cfg profileTestConfig
+	if err := LoadConfigWithProfile(&cfg, "prod", configFile); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if cfg.Database.Host != "prod-db" {
+		t.Errorf("Database.Host = %q, 期望 prod-db", cfg.Database.Host)
+	}
+	// 覆盖文件中没有的键保留基础配置的值
+	if cfg.Database.Port != 5432 || cfg.App.Name != "demo" {
+		t.Errorf("基础配置应被保留, 实际 %+v", cfg)
+	}
+	if cfg.App.Profile != "prod" || ActiveProfile() != "prod" {
+		t.Errorf("当前环境应为 prod, 实际 app.profile=%q ActiveProfile=%q", cfg.App.Profile, ActiveProfile())
+	}
+	if host, _ := GetStringWithDefault("database.host", ""); host != "prod-db" {
+		t.Errorf("全局实例 database.host = %q, 期望 prod-db", host)
+	}
+}
+
+func TestLoadConfigWithProfile_InFileSection(t *testing.T) {
+	ResetGlobalState()
+	clearProfileEnv(t)
+	t.Setenv("APP_ENV", "staging")
+	configFile := writeProfileFiles(t, map[string]string{"config.yml": profileBaseYAML})
+
+	var cfg profileTestConfig
+	if err := LoadConfigWithProfile(&cfg, "", configFile); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if cfg.Database.Host != "staging-db" || cfg.Database.Port != 5432 {
+		t.Errorf("期望合并 profiles.staging 段, 实际 %+v", cfg.Database)
+	}
+	if ActiveProfile() != "staging" {
+		t.Errorf("ActiveProfile = %q, 期望 staging", ActiveProfile())
+	}
+
+	// profiles 段不算未知的键
+	if report := LastKeyReport(); len(report.UnknownKeys) != 0 {
+		t.Errorf("profiles 段不应报告为未知的键: %v", report.UnknownKeys)
+	}
+}
+
+func TestLoadConfigWithProfile_MissingProfile(t *testing.T) {
+	ResetGlobalState()
+	clearProfileEnv(t)
+	configFile := writeProfileFiles(t, map[string]string{"config.yml": profileBaseYAML})
+
+	var cfg profileTestConfig
+	if err := LoadConfigWithProfile(&cfg, "dev", configFile); err != nil {
+		t.Fatalf("没有对应环境的配置时应正常加载: %v", err)
+	}
+	if cfg.Database.Host != "localhost" || cfg.App.Profile != "dev" {
+		t.Errorf("期望使用基础配置, 实际 %+v", cfg)
+	}
+
+	// 未指定环境且没有环境变量时与 LoadConfig 相同
+	ResetGlobalState()
+	cfg = profileTestConfig{}
+	if err := LoadConfigWithProfile(&cfg, "", configFile); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if cfg.App.Profile != "" || ActiveProfile() != "" {
+		t.Errorf("未指定环境时不应设置 profile, 实际 %q", cfg.App.Profile)
+	}
+
+	if err := LoadConfigWithProfile(&cfg, "../prod", configFile); err == nil {
+		t.Error("包含路径分隔符的环境名称应返回错误")
+	}
+}
+
+func TestLoadConfigWithProfile_EnvOverridesProfile(t *testing.T) {
+	ResetGlobalState()
+	clearProfileEnv(t)
+	configFile := writeProfileFiles(t, map[string]string{
+		"config.yml":      profileBaseYAML,
+		"config.prod.yml": "database:\n  host: prod-db\n",
+	})
+	t.Setenv("DATABASE_HOST", "env-db")
+
+	var cfg profileTestConfig
+	if err := LoadConfigWithProfile(&cfg, "prod", configFile); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if cfg.Database.Host != "env-db" {
+		t.Errorf("环境变量应优先于环境配置, 实际 %q", cfg.Database.Host)
+	}
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrNotLoaded 调用 Reload 之前没有成功加载过配置
+var ErrNotLoaded = errors.New("config: 尚未通过 LoadConfig 加载配置")
+
+// loadParams 一次加载配置使用的参数
+type loadParams struct {
+	config   interface{} // 调用方传入的结构体指针
+	strict   bool
+	profile  string
+	defaults interface{}
+	filePath []string
+}
+
+// lastLoad 最近一次成功加载配置的参数，由 globalMutex 保护
+var lastLoad *loadParams
+
+// Reload 使用最近一次成功加载时的参数（文件路径、环境、默认值、严格模式）重新加载配置，
+// 并重新解析到当时传入的结构体
+//
+// LoadConfig 之后结构体和全局viper实例是两份独立的数据：修改结构体不会影响 GetXxxWithDefault，
+// 配置文件变化后两者都不会更新。Reload 重新读取配置文件和环境变量，同时替换两者，使它们再次一致。
+//
+// 行为说明:
+//   - 先解析到新的结构体，成功后整体写入登记的结构体；失败时结构体和全局实例都保持不变
+//   - 新配置中没有的键，对应字段恢复为零值（或默认值），不会保留旧值或对结构体的修改
+//   - 与 LoadConfig 一样会清除 SetOverride(key, v, UntilReload()) 设置的覆盖值；不带 UntilReload 的 SetOverride 值
+//     在重新加载后保留，仍需要通过 UnmarshalWithOverrides 解析到结构体
+//   - 写入结构体时不加锁，调用方需要保证此时没有其他协程读取该结构体
+//
+// 示例:
+//
+//	var cfg AppConfig
+//	if err := config.LoadConfig(&cfg); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	// 收到 SIGHUP 时重新加载
+//	if err := config.Reload(); err != nil {
+//	    log.Printf("重新加载配置失败，继续使用旧配置: %v", err)
+//	}
+func Reload() error {
+	globalMutex.RLock()
+	params := lastLoad
+	globalMutex.RUnlock()
+	if params == nil {
+		return ErrNotLoaded
+	}
+
+	target := reflect.ValueOf(params.config).Elem()
+	fresh := reflect.New(target.Type())
+	if err := load(fresh.Interface(), params); err != nil {
+		return err
+	}
+	target.Set(fresh.Elem())
+	return nil
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestReloadRefreshesStructAndGlobal(t *testing.T) {
+	ResetGlobalState()
+	clearProfileEnv(t)
+	configFile := writeProfileFiles(t, map[string]string{"config.yml": profileBaseYAML})
+
+	var cfg profileTestConfig
+	if err := LoadConfig(&cfg, configFile); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	// 修改结构体不会影响全局实例
+	cfg.Database.Host = "mutated"
+	if host, _ := GetStringWithDefault("database.host", ""); host != "localhost" {
+		t.Fatalf("全局实例 database.host = %q, 期望 localhost", host)
+	}
+
+	if err := os.WriteFile(configFile, []byte("database:\n  host: new-db\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Reload(); err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+	if cfg.Database.Host != "new-db" {
+		t.Errorf("Database.Host = %q, 期望 new-db", cfg.Database.Host)
+	}
+	// 新配置中没有的键恢复为零值
+	if cfg.Database.Port != 0 || cfg.App.Name != "" {
+		t.Errorf("删除的键应恢复为零值, 实际 %+v", cfg)
+	}
+	if host, _ := GetStringWithDefault("database.host", ""); host != "new-db" {
+		t.Errorf("全局实例 database.host = %q, 期望 new-db", host)
+	}
+}
+
+func TestReloadKeepsLoadParameters(t *testing.T) {
+	ResetGlobalState()
+	clearProfileEnv(t)
+	configFile := writeProfileFiles(t, map[string]string{"config.yml": profileBaseYAML})
+
+	var cfg profileTestConfig
+	if err := LoadConfigWithProfile(&cfg, "staging", configFile); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	cfg.Database.Host = "mutated"
+	if err := Reload(); err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+	if cfg.Database.Host != "staging-db" || ActiveProfile() != "staging" {
+		t.Errorf("重新加载应使用原来的环境, 实际 host=%q profile=%q", cfg.Database.Host, ActiveProfile())
+	}
+}
+
+func TestReloadFailureKeepsState(t *testing.T) {
+	ResetGlobalState()
+	clearProfileEnv(t)
+	configFile := writeProfileFiles(t, map[string]string{"config.yml": profileBaseYAML})
+
+	var cfg profileTestConfig
+	if err := LoadConfigStrict(&cfg, configFile); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if err := os.WriteFile(configFile, []byte("database:\n  hots: typo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var unknown *UnknownKeysError
+	if err := Reload(); !errors.As(err, &unknown) {
+		t.Fatalf("严格模式下应返回 UnknownKeysError, 实际 %v", err)
+	}
+	if cfg.Database.Host != "localhost" || cfg.Database.Port != 5432 {
+		t.Errorf("失败时结构体应保持不变, 实际 %+v", cfg)
+	}
+	if host, _ := GetStringWithDefault("database.host", ""); host != "localhost" {
+		t.Errorf("失败时全局实例应保持不变, database.host = %q", host)
+	}
+}
+
+func TestReloadBeforeLoad(t *testing.T) {
+	ResetGlobalState()
+	if err := Reload(); !errors.Is(err, ErrNotLoaded) {
+		t.Errorf("未加载配置时应返回 ErrNotLoaded, 实际 %v", err)
+	}
+}
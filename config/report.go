@@ -0,0 +1,133 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/spf13/viper"
+)
+
+// lastReport 最近一次加载配置生成的键检查报告，由 globalMutex 保护
+var lastReport *KeyReport
+
+// KeyReport 配置键检查报告，用于在启动时记录可能的键名拼写错误
+type KeyReport struct {
+	// UnknownKeys 配置文件中存在但结构体没有对应字段的键，使用点号分隔的完整路径，已排序
+	UnknownKeys []string `json:"unknown_keys"`
+	// MissingKeys 结构体中为零值、且配置文件和环境变量都没有提供的字段键，已排序
+	MissingKeys []string `json:"missing_keys"`
+}
+
+// HasIssues 报告中是否存在未知或缺失的键
+func (r *KeyReport) HasIssues() bool {
+	return r != nil && (len(r.UnknownKeys) > 0 || len(r.MissingKeys) > 0)
+}
+
+// LoadConfigWithReport 与 LoadConfig 相同，同时返回配置键检查报告
+//
+// 未知的键不会导致失败，由调用方决定如何处理报告。
+// MissingKeys 从另一侧发现拼写错误：文件中写成 databse.host 时，
+// database.host 字段既没有对应的键也没有环境变量，会出现在 MissingKeys 中。
+// 有意留空的可选字段也会出现在其中，因此只建议记录为警告。
+//
+// 示例:
+//
+//	report, err := config.LoadConfigWithReport(&cfg)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if report.HasIssues() {
+//	    log.Printf("配置检查: 未知的键 %v, 缺失的键 %v", report.UnknownKeys, report.MissingKeys)
+//	}
+func LoadConfigWithReport(config interface{}, filePath ...string) (*KeyReport, error) {
+	if err := loadConfig(config, false, "", nil, filePath...); err != nil {
+		return nil, err
+	}
+	return LastKeyReport(), nil
+}
+
+// LastKeyReport 返回最近一次 LoadConfig/LoadConfigStrict/LoadConfigWithReport 生成的键检查报告
+// 尚未加载配置时返回 nil
+func LastKeyReport() *KeyReport {
+	globalMutex.RLock()
+	defer globalMutex.RUnlock()
+
+	if lastReport == nil {
+		return nil
+	}
+	return &KeyReport{
+		UnknownKeys: append([]string(nil), lastReport.UnknownKeys...),
+		MissingKeys: append([]string(nil), lastReport.MissingKeys...),
+	}
+}
+
+// buildKeyReport 根据解码元数据和解码后的结构体生成报告
+func buildKeyReport(v *viper.Viper, config interface{}, unused []string) *KeyReport {
+	report := &KeyReport{
+		UnknownKeys: []string{},
+		MissingKeys: []string{},
+	}
+
+	// 只统计配置文件中的键，通过 BindEnv 等方式引入的键不算未知；profiles 段由 LoadConfigWithProfile 使用
+	for _, key := range unused {
+		if v.InConfig(key) && !isProfilesKey(key) {
+			report.UnknownKeys = append(report.UnknownKeys, key)
+		}
+	}
+	sort.Strings(report.UnknownKeys)
+
+	rv := reflect.ValueOf(config)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Struct {
+		collectMissingKeys(v, rv, "", &report.MissingKeys)
+	}
+	sort.Strings(report.MissingKeys)
+
+	return report
+}
+
+// collectMissingKeys 收集为零值且没有任何来源（配置文件或环境变量）的叶子字段
+func collectMissingKeys(v *viper.Viper, rv reflect.Value, prefix string, missing *[]string) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		key, squash, skip := configFieldKey(sf)
+		if skip {
+			continue
+		}
+		path := key
+		if squash {
+			path = prefix
+		} else if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		fv := rv.Field(i)
+		// nil 结构体指针按零值展开，报告其下的每个字段
+		for fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				if fv.Type().Elem().Kind() != reflect.Struct {
+					break
+				}
+				fv = reflect.New(fv.Type().Elem())
+			}
+			fv = fv.Elem()
+		}
+
+		if isNestedConfigStruct(fv) {
+			collectMissingKeys(v, fv, path, missing)
+			continue
+		}
+
+		// IsSet 同时检查配置文件和环境变量（含 APP_NAME 前缀）
+		if fv.IsZero() && !v.IsSet(path) {
+			*missing = append(*missing, path)
+		}
+	}
+}
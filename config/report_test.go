@@ -0,0 +1,144 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfigWithReport_Typos(t *testing.T) {
+	fixtures := map[string]string{
+		"config.yml": `
+app:
+  name: "Test App"
+  version: "1.0.0"
+  port: 8080
+  debug: true
+databse:
+  host: "localhost"
+  port: 5432
+  username: "root"
+  password: "secret"
+`,
+		"config.json": `{
+  "app": {"name": "Test App", "version": "1.0.0", "port": 8080, "debug": true},
+  "databse": {"host": "localhost", "port": 5432, "username": "root", "password": "secret"}
+}`,
+	}
+
+	for name, content := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			ResetGlobalState()
+			configFile := writeConfigFile(t, name, content)
+
+			var cfg TestConfig
+			report, err := LoadConfigWithReport(&cfg, configFile)
+			if err != nil {
+				t.Fatalf("LoadConfigWithReport 失败: %v", err)
+			}
+
+			if want := []string{"databse"}; !reflect.DeepEqual(report.UnknownKeys, want) {
+				t.Errorf("期望未知键 %v, 实际 %v", want, report.UnknownKeys)
+			}
+			wantMissing := []string{"database.host", "database.password", "database.port", "database.username"}
+			if !reflect.DeepEqual(report.MissingKeys, wantMissing) {
+				t.Errorf("期望缺失键 %v, 实际 %v", wantMissing, report.MissingKeys)
+			}
+			if !report.HasIssues() {
+				t.Error("期望报告存在问题")
+			}
+			if last := LastKeyReport(); !reflect.DeepEqual(last, report) {
+				t.Errorf("LastKeyReport 应与返回的报告一致: %+v", last)
+			}
+
+			// 严格模式同样报告完整路径
+			ResetGlobalState()
+			err = LoadConfigStrict(&cfg, configFile)
+			var unknown *UnknownKeysError
+			if !errors.As(err, &unknown) || !reflect.DeepEqual(unknown.Keys, []string{"databse"}) {
+				t.Errorf("期望 *UnknownKeysError{databse}, 实际 %v", err)
+			}
+			if last := LastKeyReport(); last == nil || len(last.MissingKeys) != 4 {
+				t.Errorf("严格模式失败时也应保留报告, 实际 %+v", last)
+			}
+		})
+	}
+}
+
+func TestLoadConfigWithReport_NestedTypoJSON(t *testing.T) {
+	ResetGlobalState()
+	configFile := writeConfigFile(t, "config.json", `{
+  "app": {"name": "Test App", "prot": 8080},
+  "database": {"host": "localhost"}
+}`)
+
+	var cfg TestConfig
+	report, err := LoadConfigWithReport(&cfg, configFile)
+	if err != nil {
+		t.Fatalf("LoadConfigWithReport 失败: %v", err)
+	}
+	if want := []string{"app.prot"}; !reflect.DeepEqual(report.UnknownKeys, want) {
+		t.Errorf("期望未知键 %v, 实际 %v", want, report.UnknownKeys)
+	}
+	if !containsString(report.MissingKeys, "app.port") {
+		t.Errorf("期望 app.port 出现在缺失键中, 实际 %v", report.MissingKeys)
+	}
+	if containsString(report.MissingKeys, "app.name") || containsString(report.MissingKeys, "database.host") {
+		t.Errorf("已提供的键不应视为缺失, 实际 %v", report.MissingKeys)
+	}
+}
+
+func TestLoadConfigWithReport_EnvKeys(t *testing.T) {
+	ResetGlobalState()
+	configFile := writeConfigFile(t, "config.yml", `
+app:
+  name: "Test App"
+database:
+  host: "localhost"
+`)
+
+	// 环境变量提供的键（含 APP_NAME 前缀）不算缺失，也不算未知
+	t.Setenv("APP_NAME", "myapp")
+	t.Setenv("MYAPP_DATABASE_HOST", "db.internal")
+	t.Setenv("MYAPP_DATABASE_USERNAME", "svc")
+
+	var cfg TestConfig
+	report, err := LoadConfigWithReport(&cfg, configFile)
+	if err != nil {
+		t.Fatalf("LoadConfigWithReport 失败: %v", err)
+	}
+	if cfg.Database.Host != "db.internal" {
+		t.Fatalf("期望环境变量覆盖生效, 实际 %q", cfg.Database.Host)
+	}
+	if len(report.UnknownKeys) != 0 {
+		t.Errorf("环境变量不应产生未知键, 实际 %v", report.UnknownKeys)
+	}
+	if containsString(report.MissingKeys, "database.username") {
+		t.Errorf("环境变量提供的键不应视为缺失, 实际 %v", report.MissingKeys)
+	}
+
+	ResetGlobalState()
+	if err := LoadConfigStrict(&cfg, configFile); err != nil {
+		t.Errorf("严格模式不应因环境变量失败: %v", err)
+	}
+}
+
+func TestLastKeyReport_Reset(t *testing.T) {
+	ResetGlobalState()
+	if LastKeyReport() != nil {
+		t.Error("未加载配置时应返回 nil")
+	}
+	var report *KeyReport
+	if report.HasIssues() {
+		t.Error("nil 报告不应存在问题")
+	}
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
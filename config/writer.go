@@ -0,0 +1,368 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configField 配置结构体中的一个字段，叶子字段持有值，嵌套结构体持有子字段
+type configField struct {
+	key      string
+	comment  string
+	value    interface{}
+	children []configField
+	isGroup  bool
+}
+
+// WriteDefault 将配置结构体序列化为默认配置文件，是 LoadConfig 的逆操作
+//
+// 参数:
+//   - cfg: 配置结构体或其指针，字段的当前值作为默认值写出
+//   - path: 输出文件路径，根据扩展名选择格式（.yml/.yaml 或 .json）
+//
+// 字段规则:
+//   - 键名优先使用 mapstructure 标签，其次是 yaml 标签，否则使用小写字段名
+//   - 字段为零值且设置了 default 标签时，使用 default 标签的值
+//   - YAML 格式会把 comment 标签写成字段上方的注释
+//
+// 使用场景:
+//   - ✅ 为新用户生成配置模板（如 --write-config 参数）
+//   - ✅ 导出当前生效的配置作为参考
+//
+// 示例:
+//
+//	type AppConfig struct {
+//	    App struct {
+//	        Name string        `mapstructure:"name" default:"my-app" comment:"应用名称"`
+//	        Port int           `mapstructure:"port" default:"8080"`
+//	        Timeout time.Duration `mapstructure:"timeout" default:"30s"`
+//	    } `mapstructure:"app"`
+//	}
+//
+//	if *writeConfig {
+//	    if err := config.WriteDefault(AppConfig{}, "config.yml"); err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    return
+//	}
+func WriteDefault(cfg interface{}, path string) error {
+	v := reflect.ValueOf(cfg)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v = reflect.New(v.Type().Elem())
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("配置必须是结构体或结构体指针，实际类型: %s", v.Kind())
+	}
+
+	fields, err := collectConfigFields(v)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		data, err = encodeFieldsYAML(fields)
+	case ".json":
+		data, err = encodeFieldsJSON(fields)
+	default:
+		return fmt.Errorf("不支持的配置文件格式: %s（仅支持 .yml、.yaml、.json）", filepath.Ext(path))
+	}
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("创建配置目录失败: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("写入配置文件失败: %w", err)
+	}
+	return nil
+}
+
+// collectConfigFields 遍历结构体字段，生成配置字段树
+func collectConfigFields(v reflect.Value) ([]configField, error) {
+	t := v.Type()
+	fields := make([]configField, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		key, squash, skip := configFieldKey(sf)
+		if skip {
+			continue
+		}
+
+		fv := v.Field(i)
+		if fv.IsZero() {
+			if def, ok := sf.Tag.Lookup("default"); ok {
+				withDefault := reflect.New(fv.Type()).Elem()
+				if err := setDefaultValue(withDefault, def); err != nil {
+					return nil, fmt.Errorf("字段 %s 的 default 标签无效: %w", sf.Name, err)
+				}
+				fv = withDefault
+			}
+		}
+
+		// 解引用指针，nil 结构体指针按零值展开，便于生成完整模板
+		for fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				if fv.Type().Elem().Kind() != reflect.Struct {
+					break
+				}
+				fv = reflect.New(fv.Type().Elem())
+			}
+			fv = fv.Elem()
+		}
+
+		if isNestedConfigStruct(fv) {
+			children, err := collectConfigFields(fv)
+			if err != nil {
+				return nil, err
+			}
+			if squash {
+				fields = append(fields, children...)
+				continue
+			}
+			fields = append(fields, configField{
+				key:      key,
+				comment:  sf.Tag.Get("comment"),
+				children: children,
+				isGroup:  true,
+			})
+			continue
+		}
+
+		fields = append(fields, configField{
+			key:     key,
+			comment: sf.Tag.Get("comment"),
+			value:   configLeafValue(fv),
+		})
+	}
+
+	return fields, nil
+}
+
+// configFieldKey 解析字段对应的配置键
+func configFieldKey(sf reflect.StructField) (key string, squash bool, skip bool) {
+	for _, tagName := range []string{"mapstructure", "yaml"} {
+		tag, ok := sf.Tag.Lookup(tagName)
+		if !ok {
+			continue
+		}
+		if tag == "-" {
+			return "", false, true
+		}
+		parts := strings.Split(tag, ",")
+		for _, opt := range parts[1:] {
+			if opt == "squash" || opt == "inline" {
+				squash = true
+			}
+		}
+		if parts[0] != "" {
+			return parts[0], squash, false
+		}
+		break
+	}
+
+	// 未声明标签的嵌入结构体按 mapstructure 的 squash 规则展开
+	if sf.Anonymous && !squash {
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct {
+			squash = true
+		}
+	}
+
+	return strings.ToLower(sf.Name), squash, false
+}
+
+// isNestedConfigStruct 判断是否需要展开为嵌套配置段
+func isNestedConfigStruct(v reflect.Value) bool {
+	return v.Kind() == reflect.Struct && v.Type() != reflect.TypeOf(time.Time{})
+}
+
+// configLeafValue 将叶子字段转换为便于阅读和回读的值
+func configLeafValue(v reflect.Value) interface{} {
+	if !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return nil
+	}
+
+	switch val := v.Interface().(type) {
+	case time.Duration:
+		return val.String()
+	case time.Time:
+		return val.Format(time.RFC3339)
+	}
+	return v.Interface()
+}
+
+// setDefaultValue 按字段类型解析 default 标签
+func setDefaultValue(v reflect.Value, raw string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var parts []string
+		if raw != "" {
+			parts = strings.Split(raw, ",")
+		}
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setDefaultValue(slice.Index(i), strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := setDefaultValue(elem.Elem(), raw); err != nil {
+			return err
+		}
+		v.Set(elem)
+	default:
+		return fmt.Errorf("不支持的类型: %s", v.Type())
+	}
+	return nil
+}
+
+// encodeFieldsYAML 生成带注释的YAML
+func encodeFieldsYAML(fields []configField) ([]byte, error) {
+	root, err := yamlMappingNode(fields)
+	if err != nil {
+		return nil, err
+	}
+	root.HeadComment = "由 config.WriteDefault 生成的默认配置"
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}); err != nil {
+		return nil, fmt.Errorf("序列化YAML失败: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("序列化YAML失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlMappingNode 将字段列表转换为YAML映射节点
+func yamlMappingNode(fields []configField) (*yaml.Node, error) {
+	mapping := &yaml.Node{Kind: yaml.MappingNode}
+	for _, field := range fields {
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: field.key, HeadComment: field.comment}
+
+		var valueNode *yaml.Node
+		if field.isGroup {
+			node, err := yamlMappingNode(field.children)
+			if err != nil {
+				return nil, err
+			}
+			valueNode = node
+		} else {
+			valueNode = &yaml.Node{}
+			if err := valueNode.Encode(field.value); err != nil {
+				return nil, fmt.Errorf("序列化字段 %s 失败: %w", field.key, err)
+			}
+		}
+
+		mapping.Content = append(mapping.Content, keyNode, valueNode)
+	}
+	return mapping, nil
+}
+
+// orderedObject 保持字段声明顺序的JSON对象
+type orderedObject []configField
+
+// MarshalJSON 按字段声明顺序序列化
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+
+		var value []byte
+		if field.isGroup {
+			value, err = orderedObject(field.children).MarshalJSON()
+		} else {
+			value, err = json.Marshal(field.value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("序列化字段 %s 失败: %w", field.key, err)
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// encodeFieldsJSON 生成格式化的JSON（JSON不支持注释，comment 标签会被忽略）
+func encodeFieldsJSON(fields []configField) ([]byte, error) {
+	data, err := json.MarshalIndent(orderedObject(fields), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化JSON失败: %w", err)
+	}
+	return append(data, '\n'), nil
+}
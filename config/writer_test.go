@@ -0,0 +1,119 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// DefaultTestConfig 带 default 和 comment 标签的测试配置
+type DefaultTestConfig struct {
+	App struct {
+		Name    string        `mapstructure:"name" default:"demo-app" comment:"应用名称"`
+		Port    int           `mapstructure:"port" default:"8080"`
+		Debug   bool          `mapstructure:"debug"`
+		Timeout time.Duration `mapstructure:"timeout" default:"30s"`
+		Tags    []string      `mapstructure:"tags" default:"a,b"`
+	} `mapstructure:"app" comment:"应用配置"`
+
+	Database *struct {
+		Host string `mapstructure:"host" default:"localhost"`
+	} `mapstructure:"database"`
+
+	Internal string `mapstructure:"-"`
+}
+
+func TestWriteDefault_YAMLRoundTrip(t *testing.T) {
+	ResetGlobalState()
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+
+	cfg := DefaultTestConfig{}
+	cfg.App.Debug = true
+	if err := WriteDefault(&cfg, path); err != nil {
+		t.Fatalf("生成默认配置失败: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取生成的配置失败: %v", err)
+	}
+	for _, want := range []string{"# 应用配置", "# 应用名称", "timeout: 30s"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("期望生成的配置包含 %q, 实际:\n%s", want, content)
+		}
+	}
+	if strings.Contains(string(content), "internal") {
+		t.Errorf("期望忽略 mapstructure:\"-\" 字段, 实际:\n%s", content)
+	}
+
+	var loaded DefaultTestConfig
+	if err := LoadConfig(&loaded, path); err != nil {
+		t.Fatalf("加载生成的配置失败: %v", err)
+	}
+	if loaded.App.Name != "demo-app" {
+		t.Errorf("期望 App.Name = 'demo-app', 实际 = '%s'", loaded.App.Name)
+	}
+	if loaded.App.Port != 8080 {
+		t.Errorf("期望 App.Port = 8080, 实际 = %d", loaded.App.Port)
+	}
+	if !loaded.App.Debug {
+		t.Error("期望 App.Debug = true")
+	}
+	if loaded.App.Timeout != 30*time.Second {
+		t.Errorf("期望 App.Timeout = 30s, 实际 = %v", loaded.App.Timeout)
+	}
+	if len(loaded.App.Tags) != 2 || loaded.App.Tags[1] != "b" {
+		t.Errorf("期望 App.Tags = [a b], 实际 = %v", loaded.App.Tags)
+	}
+	if loaded.Database == nil || loaded.Database.Host != "localhost" {
+		t.Errorf("期望 Database.Host = 'localhost', 实际 = %+v", loaded.Database)
+	}
+}
+
+func TestWriteDefault_JSON(t *testing.T) {
+	ResetGlobalState()
+
+	path := filepath.Join(t.TempDir(), "nested", "config.json")
+	if err := WriteDefault(DefaultTestConfig{}, path); err != nil {
+		t.Fatalf("生成默认配置失败: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取生成的配置失败: %v", err)
+	}
+	// 字段保持声明顺序
+	if strings.Index(string(content), `"app"`) > strings.Index(string(content), `"database"`) {
+		t.Errorf("期望字段保持声明顺序, 实际:\n%s", content)
+	}
+
+	var loaded DefaultTestConfig
+	if err := LoadConfig(&loaded, path); err != nil {
+		t.Fatalf("加载生成的配置失败: %v", err)
+	}
+	if loaded.App.Port != 8080 {
+		t.Errorf("期望 App.Port = 8080, 实际 = %d", loaded.App.Port)
+	}
+}
+
+func TestWriteDefault_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := WriteDefault(DefaultTestConfig{}, filepath.Join(dir, "config.toml")); err == nil {
+		t.Error("期望不支持的扩展名返回错误")
+	}
+
+	if err := WriteDefault("not a struct", filepath.Join(dir, "config.yml")); err == nil {
+		t.Error("期望非结构体返回错误")
+	}
+
+	type badDefault struct {
+		Port int `mapstructure:"port" default:"abc"`
+	}
+	if err := WriteDefault(badDefault{}, filepath.Join(dir, "config.yml")); err == nil {
+		t.Error("期望无效的 default 标签返回错误")
+	}
+}
@@ -0,0 +1,14 @@
+package constants
+
+// DefaultSensitiveHeaders 返回默认的敏感请求头列表，调试输出和抓包时这些头的值会被脱敏
+// 每次调用返回新的切片，调用方可以自由追加
+func DefaultSensitiveHeaders() []string {
+	return []string{
+		"Authorization",
+		"Cookie",
+		"Set-Cookie",
+		"X-Api-Key",
+		"X-Auth-Token",
+		"Bearer",
+	}
+}
@@ -0,0 +1,210 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ErrMissingModel 批量迭代的查询未指定模型
+var ErrMissingModel = errors.New("查询必须通过 Model 指定模型")
+
+// BatchCheckpoint 批量迭代的检查点存储
+// 每处理完一个批次保存该批次最后一条记录的主键，任务重启后从该主键之后继续
+type BatchCheckpoint interface {
+	// Load 读取上次处理到的主键，ok 为 false 表示从头开始
+	Load(ctx context.Context) (lastKey interface{}, ok bool, err error)
+	// Save 保存已处理的最后一个主键
+	Save(ctx context.Context, lastKey interface{}) error
+}
+
+// BatchProgress 批量迭代进度
+type BatchProgress struct {
+	Batches int           // 已处理批次数
+	Rows    int64         // 已处理行数
+	LastKey interface{}   // 最后处理的主键
+	Elapsed time.Duration // 已耗时
+}
+
+// BatchHandler 批次处理函数，batch 为模型切片的指针（如 *[]User）
+type BatchHandler func(ctx context.Context, batch interface{}) error
+
+// IterOption 批量迭代选项
+type IterOption func(*iterOptions)
+
+type iterOptions struct {
+	checkpoint   BatchCheckpoint
+	batchTimeout time.Duration
+	throttle     time.Duration
+	progress     func(BatchProgress)
+}
+
+// WithCheckpoint 设置检查点存储，用于中断后恢复
+func WithCheckpoint(store BatchCheckpoint) IterOption {
+	return func(o *iterOptions) {
+		o.checkpoint = store
+	}
+}
+
+// WithBatchTimeout 设置单个批次处理函数的超时时间
+func WithBatchTimeout(timeout time.Duration) IterOption {
+	return func(o *iterOptions) {
+		o.batchTimeout = timeout
+	}
+}
+
+// WithThrottle 设置批次之间的等待时间，降低对数据库的压力
+func WithThrottle(delay time.Duration) IterOption {
+	return func(o *iterOptions) {
+		o.throttle = delay
+	}
+}
+
+// WithProgress 设置进度回调，每个批次处理完成后调用
+func WithProgress(fn func(BatchProgress)) IterOption {
+	return func(o *iterOptions) {
+		o.progress = fn
+	}
+}
+
+// IterateBatches 按主键顺序分批遍历查询结果，避免一次性加载全部数据
+//
+// 在 GORM FindInBatches 的基础上增加:
+//   - 每个批次之间检查 ctx 是否已取消
+//   - 检查点存储，任务重启后从上次处理的主键之后继续
+//   - 单批次超时、批次间节流和进度回调
+//
+// 处理函数返回错误时停止迭代，返回的错误包含失败批次的主键范围。
+//
+// 示例:
+//
+//	query := db.GetDB().Model(&User{}).Where("status = ?", "active")
+//	err := db.IterateBatches(ctx, query, 1000, func(ctx context.Context, batch interface{}) error {
+//	    users := batch.(*[]User)
+//	    return process(ctx, *users)
+//	}, database.WithCheckpoint(store), database.WithThrottle(100*time.Millisecond))
+func (d *Database) IterateBatches(ctx context.Context, query *gorm.DB, batchSize int, handler BatchHandler, opts ...IterOption) error {
+	if batchSize <= 0 {
+		return fmt.Errorf("批次大小必须大于0，当前值: %d", batchSize)
+	}
+	if query == nil {
+		query = d.GetDB()
+	}
+
+	options := &iterOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	// 根据查询模型创建批次切片并解析主键
+	model := query.Statement.Model
+	if model == nil {
+		return ErrMissingModel
+	}
+	modelType := reflect.Indirect(reflect.ValueOf(model)).Type()
+	if modelType.Kind() == reflect.Slice {
+		modelType = modelType.Elem()
+	}
+
+	stmt := &gorm.Statement{DB: query}
+	if err := stmt.Parse(model); err != nil {
+		return fmt.Errorf("解析模型失败: %w", err)
+	}
+	pkField := stmt.Schema.PrioritizedPrimaryField
+	if pkField == nil {
+		return fmt.Errorf("模型 %s 没有主键，无法分批遍历", stmt.Schema.Name)
+	}
+
+	tx := query.WithContext(ctx)
+
+	// 从检查点恢复
+	if options.checkpoint != nil {
+		lastKey, ok, err := options.checkpoint.Load(ctx)
+		if err != nil {
+			return fmt.Errorf("读取检查点失败: %w", err)
+		}
+		if ok {
+			tx = tx.Where(fmt.Sprintf("%s > ?", stmt.Quote(pkField.DBName)), lastKey)
+		}
+	}
+
+	start := time.Now()
+	progress := BatchProgress{}
+	dest := reflect.New(reflect.SliceOf(modelType))
+
+	result := tx.FindInBatches(dest.Interface(), batchSize, func(batchTx *gorm.DB, batch int) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rows := dest.Elem()
+		if rows.Len() == 0 {
+			return nil
+		}
+		firstKey := primaryKeyAt(ctx, pkField, rows, 0)
+		lastKey := primaryKeyAt(ctx, pkField, rows, rows.Len()-1)
+
+		if err := runBatchHandler(ctx, options.batchTimeout, handler, dest.Interface()); err != nil {
+			return NewDatabaseError(ErrorTypeQuery, "IterateBatches", err).
+				WithContext("batch", batch).
+				WithContext("first_key", firstKey).
+				WithContext("last_key", lastKey)
+		}
+
+		if options.checkpoint != nil {
+			if err := options.checkpoint.Save(ctx, lastKey); err != nil {
+				return fmt.Errorf("保存检查点失败: %w", err)
+			}
+		}
+
+		progress.Batches++
+		progress.Rows += int64(rows.Len())
+		progress.LastKey = lastKey
+		progress.Elapsed = time.Since(start)
+		if options.progress != nil {
+			options.progress(progress)
+		}
+
+		// 批次间节流（最后一个不满的批次之后无需等待）
+		if options.throttle > 0 && rows.Len() == batchSize {
+			timer := time.NewTimer(options.throttle)
+			defer timer.Stop()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+		return nil
+	})
+
+	if result.Error != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(result.Error, ctxErr) {
+			return fmt.Errorf("批量迭代已取消: %w", result.Error)
+		}
+		return result.Error
+	}
+	return nil
+}
+
+// runBatchHandler 执行批次处理函数，设置了超时时间时使用带超时的 context
+func runBatchHandler(ctx context.Context, timeout time.Duration, handler BatchHandler, batch interface{}) error {
+	if timeout <= 0 {
+		return handler(ctx, batch)
+	}
+
+	batchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return handler(batchCtx, batch)
+}
+
+// primaryKeyAt 获取切片中指定位置记录的主键值
+func primaryKeyAt(ctx context.Context, field *schema.Field, rows reflect.Value, index int) interface{} {
+	value, _ := field.ValueOf(ctx, reflect.Indirect(rows.Index(index)))
+	return value
+}
@@ -0,0 +1,172 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryCheckpoint 内存检查点存储
+type memoryCheckpoint struct {
+	mu      sync.Mutex
+	lastKey interface{}
+	saved   bool
+}
+
+func (m *memoryCheckpoint) Load(ctx context.Context) (interface{}, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastKey, m.saved, nil
+}
+
+func (m *memoryCheckpoint) Save(ctx context.Context, lastKey interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastKey = lastKey
+	m.saved = true
+	return nil
+}
+
+// seededBatchDatabase 创建包含 n 个用户的文件型SQLite数据库
+func seededBatchDatabase(t *testing.T, n int) *Database {
+	t.Helper()
+
+	db := newFileTestDatabase(t)
+	if err := db.AutoMigrate(&TestUser{}); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+
+	users := make([]TestUser, 0, n)
+	for i := 1; i <= n; i++ {
+		users = append(users, TestUser{
+			Name:  fmt.Sprintf("user-%d", i),
+			Email: fmt.Sprintf("user-%d@example.com", i),
+			Age:   i,
+		})
+	}
+	if err := db.GetDB().CreateInBatches(users, 50).Error; err != nil {
+		t.Fatalf("写入测试数据失败: %v", err)
+	}
+	return db
+}
+
+func TestIterateBatches(t *testing.T) {
+	db := seededBatchDatabase(t, 95)
+
+	var seen int
+	var progress []BatchProgress
+	err := db.IterateBatches(context.Background(), db.GetDB().Model(&TestUser{}), 10,
+		func(ctx context.Context, batch interface{}) error {
+			users := batch.(*[]TestUser)
+			seen += len(*users)
+			return nil
+		},
+		WithProgress(func(p BatchProgress) { progress = append(progress, p) }),
+	)
+	if err != nil {
+		t.Fatalf("批量迭代失败: %v", err)
+	}
+
+	if seen != 95 {
+		t.Errorf("期望处理 95 行，实际 %d", seen)
+	}
+	if len(progress) != 10 {
+		t.Fatalf("期望 10 次进度回调，实际 %d", len(progress))
+	}
+	last := progress[len(progress)-1]
+	if last.Rows != 95 || last.Batches != 10 {
+		t.Errorf("期望最终进度为 95 行/10 批，实际 %+v", last)
+	}
+}
+
+func TestIterateBatches_ResumeFromCheckpoint(t *testing.T) {
+	db := seededBatchDatabase(t, 50)
+	checkpoint := &memoryCheckpoint{}
+	query := db.GetDB().Model(&TestUser{})
+
+	// 第一次运行在第3个批次失败
+	batches := 0
+	err := db.IterateBatches(context.Background(), query, 10, func(ctx context.Context, batch interface{}) error {
+		batches++
+		if batches == 3 {
+			return errors.New("模拟失败")
+		}
+		return nil
+	}, WithCheckpoint(checkpoint))
+	if err == nil {
+		t.Fatal("期望返回处理函数的错误")
+	}
+	if !strings.Contains(err.Error(), "first_key:21") || !strings.Contains(err.Error(), "last_key:30") {
+		t.Errorf("期望错误包含失败批次的主键范围, 实际: %v", err)
+	}
+	if checkpoint.lastKey != uint(20) {
+		t.Fatalf("期望检查点为 20，实际 %v", checkpoint.lastKey)
+	}
+
+	// 第二次运行从检查点之后继续
+	var ids []uint
+	err = db.IterateBatches(context.Background(), query, 10, func(ctx context.Context, batch interface{}) error {
+		for _, u := range *batch.(*[]TestUser) {
+			ids = append(ids, u.ID)
+		}
+		return nil
+	}, WithCheckpoint(checkpoint))
+	if err != nil {
+		t.Fatalf("恢复迭代失败: %v", err)
+	}
+	if len(ids) != 30 || ids[0] != 21 || ids[len(ids)-1] != 50 {
+		t.Errorf("期望处理主键 21-50，实际 %d 行: 首 %v 尾 %v", len(ids), ids[0], ids[len(ids)-1])
+	}
+}
+
+func TestIterateBatches_Cancellation(t *testing.T) {
+	db := seededBatchDatabase(t, 100)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	batches := 0
+	err := db.IterateBatches(ctx, db.GetDB().Model(&TestUser{}), 10, func(ctx context.Context, batch interface{}) error {
+		batches++
+		if batches == 2 {
+			cancel()
+		}
+		return nil
+	}, WithThrottle(time.Millisecond))
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("期望返回 context.Canceled，实际 %v", err)
+	}
+	if batches != 2 {
+		t.Errorf("期望取消后停止迭代，实际处理 %d 个批次", batches)
+	}
+}
+
+func TestIterateBatches_BatchTimeout(t *testing.T) {
+	db := seededBatchDatabase(t, 5)
+
+	err := db.IterateBatches(context.Background(), db.GetDB().Model(&TestUser{}), 10, func(ctx context.Context, batch interface{}) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithBatchTimeout(10*time.Millisecond))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("期望返回 context.DeadlineExceeded，实际 %v", err)
+	}
+}
+
+func TestIterateBatches_InvalidArguments(t *testing.T) {
+	db := seededBatchDatabase(t, 1)
+	noop := func(ctx context.Context, batch interface{}) error { return nil }
+
+	if err := db.IterateBatches(context.Background(), db.GetDB(), 10, noop); !errors.Is(err, ErrMissingModel) {
+		t.Errorf("期望返回 ErrMissingModel，实际 %v", err)
+	}
+	if err := db.IterateBatches(context.Background(), db.GetDB().Model(&TestUser{}), 0, noop); err == nil {
+		t.Error("期望批次大小为0时返回错误")
+	}
+}
@@ -0,0 +1,237 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// PostgresMaintenanceDatabase 检查和创建 PostgreSQL 数据库时连接的维护库
+const PostgresMaintenanceDatabase = "postgres"
+
+// ErrAutoCreateDisabled 设置了 Config.DisableAutoCreate 时 EnsureDatabase 返回该错误
+var ErrAutoCreateDisabled = errors.New("已禁止自动创建数据库")
+
+// sqlIdentifierPattern 排序规则和字符编码名称的格式
+var sqlIdentifierPattern = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// openServerDB 连接服务端级别的地址（不指定目标数据库），测试中可替换
+var openServerDB = func(cfg *Config) (*sql.DB, error) {
+	dialector, err := newDialector(serverConfig(cfg))
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:               logger.Discard,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return db.DB()
+}
+
+// EnsureDatabase 检查配置的数据库是否存在，不存在时创建，用于新环境的首次部署
+//
+//   - MySQL 连接不指定库的服务端地址，使用 Charset 和 Collation 建库
+//   - PostgreSQL 连接维护库 postgres，使用 Owner 和 Encoding 建库
+//   - SQLite 只创建数据库文件所在的目录，文件由首次连接创建
+//
+// 设置 Config.DisableAutoCreate 时返回 ErrAutoCreateDisabled，不连接数据库。
+// 配置无效时返回 ErrorTypeValidation 类型的 DatabaseError，连接或建库失败时返回 ErrorTypeConnection。
+//
+// 示例:
+//
+//	if err := database.EnsureDatabase(ctx, cfg); err != nil {
+//	    log.Fatalf("初始化数据库失败: %v", err)
+//	}
+func EnsureDatabase(ctx context.Context, cfg *Config) error {
+	_, err := ensureDatabase(ctx, cfg, false)
+	return err
+}
+
+// EnsureDatabaseDryRun 与 EnsureDatabase 相同地检查数据库，但不执行任何修改
+//
+// 返回将要执行的建库语句，数据库已存在时返回空字符串；
+// SQLite 目录不存在时返回描述创建目录的说明。
+func EnsureDatabaseDryRun(ctx context.Context, cfg *Config) (string, error) {
+	return ensureDatabase(ctx, cfg, true)
+}
+
+// CreateDatabaseDDL 返回配置对应的建库语句，不连接数据库，SQLite 返回空字符串
+func CreateDatabaseDDL(cfg *Config) (string, error) {
+	c := *cfg
+	c.SetDefaults()
+	if err := validateAutoCreate(&c); err != nil {
+		return "", NewDatabaseError(ErrorTypeValidation, "create_database_ddl", err)
+	}
+	return createDatabaseDDL(&c), nil
+}
+
+func ensureDatabase(ctx context.Context, cfg *Config, dryRun bool) (string, error) {
+	if cfg.DisableAutoCreate {
+		return "", NewDatabaseError(ErrorTypeValidation, "ensure_database", ErrAutoCreateDisabled)
+	}
+
+	c := *cfg
+	c.SetDefaults()
+	if err := validateAutoCreate(&c); err != nil {
+		return "", NewDatabaseError(ErrorTypeValidation, "ensure_database", err)
+	}
+
+	if c.Driver == "sqlite" {
+		return ensureSQLiteDir(&c, dryRun)
+	}
+
+	db, err := openServerDB(&c)
+	if err != nil {
+		return "", NewDatabaseError(ErrorTypeConnection, "ensure_database", err)
+	}
+	defer db.Close()
+
+	exists, err := databaseExists(ctx, db, &c)
+	if err != nil {
+		return "", NewDatabaseError(ErrorTypeConnection, "ensure_database", err)
+	}
+	if exists {
+		return "", nil
+	}
+
+	ddl := createDatabaseDDL(&c)
+	if dryRun {
+		return ddl, nil
+	}
+	if _, err := db.ExecContext(ctx, ddl); err != nil && !isDuplicateDatabaseError(err) {
+		return "", NewDatabaseError(ErrorTypeConnection, "ensure_database", fmt.Errorf("执行 %s 失败: %w", ddl, err))
+	}
+	return ddl, nil
+}
+
+// validateAutoCreate 校验建库所需的配置，数据库名、排序规则等会拼接进语句，必须严格校验
+func validateAutoCreate(c *Config) error {
+	if c.Driver == "sqlite" {
+		if c.Database == "" {
+			return ErrMissingDBPath
+		}
+		return nil
+	}
+
+	// SQLite 的目录检查由 ensureSQLiteDir 负责，其余驱动复用完整的配置校验
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	if c.Driver == "mysql" && c.Collation != "" && !sqlIdentifierPattern.MatchString(c.Collation) {
+		return fmt.Errorf("%w: 排序规则包含非法字符: %s", ErrInvalidCharset, c.Collation)
+	}
+	if c.Driver == "postgres" {
+		if c.Encoding != "" && !sqlIdentifierPattern.MatchString(c.Encoding) {
+			return fmt.Errorf("%w: 字符编码包含非法字符: %s", ErrInvalidCharset, c.Encoding)
+		}
+		if c.Owner != "" && !isValidDatabaseName(c.Owner) {
+			return fmt.Errorf("数据库所有者包含非法字符: %s", c.Owner)
+		}
+	}
+	return nil
+}
+
+// serverConfig 返回连接服务端级别地址的配置副本
+// MySQL 不指定数据库，PostgreSQL 连接维护库
+func serverConfig(cfg *Config) *Config {
+	c := *cfg
+	switch c.Driver {
+	case "mysql":
+		c.Database = ""
+	case "postgres":
+		c.Database = PostgresMaintenanceDatabase
+	}
+	return &c
+}
+
+// databaseExists 在服务端查询目标数据库是否存在
+func databaseExists(ctx context.Context, db *sql.DB, c *Config) (bool, error) {
+	var query string
+	switch c.Driver {
+	case "mysql":
+		query = "SELECT COUNT(*) FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME = ?"
+	case "postgres":
+		query = "SELECT COUNT(*) FROM pg_database WHERE datname = $1"
+	default:
+		return false, fmt.Errorf("不支持的数据库驱动: %s", c.Driver)
+	}
+
+	var count int
+	if err := db.QueryRowContext(ctx, query, c.Database).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// createDatabaseDDL 构建建库语句，调用前必须经过 validateAutoCreate 校验
+func createDatabaseDDL(c *Config) string {
+	switch c.Driver {
+	case "mysql":
+		ddl := fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`", c.Database)
+		if c.Charset != "" {
+			ddl += " CHARACTER SET " + c.Charset
+		}
+		if c.Collation != "" {
+			ddl += " COLLATE " + c.Collation
+		}
+		return ddl
+	case "postgres":
+		// PostgreSQL 不支持 IF NOT EXISTS，并发创建时由 isDuplicateDatabaseError 兜底
+		ddl := fmt.Sprintf(`CREATE DATABASE "%s"`, c.Database)
+		if c.Owner != "" {
+			ddl += fmt.Sprintf(` OWNER "%s"`, c.Owner)
+		}
+		if c.Encoding != "" {
+			ddl += fmt.Sprintf(" ENCODING '%s'", c.Encoding)
+		}
+		return ddl
+	default:
+		return ""
+	}
+}
+
+// ensureSQLiteDir 创建 SQLite 数据库文件所在的目录，内存数据库不需要处理
+func ensureSQLiteDir(c *Config, dryRun bool) (string, error) {
+	dir := sqliteDir(c.Database)
+	if dir == "" {
+		return "", nil
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return "", nil
+	}
+	if dryRun {
+		return fmt.Sprintf("mkdir -p %s", dir), nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", NewDatabaseError(ErrorTypeConnection, "ensure_database", err)
+	}
+	return "", nil
+}
+
+// sqliteDir 返回 SQLite 数据库文件所在的目录，内存数据库或当前目录返回空字符串
+func sqliteDir(path string) string {
+	if path == ":memory:" {
+		return ""
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		return dir
+	}
+	return ""
+}
+
+// isDuplicateDatabaseError 判断是否为数据库已存在（PostgreSQL 42P04 duplicate_database）
+func isDuplicateDatabaseError(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "42P04"
+}
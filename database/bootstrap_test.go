@@ -0,0 +1,240 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stubServerDB 替换 openServerDB，记录调用次数并返回 err
+func stubServerDB(t *testing.T, err error) *int {
+	t.Helper()
+
+	calls := 0
+	original := openServerDB
+	openServerDB = func(cfg *Config) (*sql.DB, error) {
+		calls++
+		return nil, err
+	}
+	t.Cleanup(func() { openServerDB = original })
+	return &calls
+}
+
+func serverTestConfig(driver string) *Config {
+	config := &Config{
+		Driver:   driver,
+		Host:     "db.internal",
+		Port:     3306,
+		Username: "app",
+		Password: "secret",
+		Database: "orders",
+	}
+	if driver == "postgres" {
+		config.Port = 5432
+	}
+	config.SetDefaults()
+	return config
+}
+
+func TestServerConfigDSN(t *testing.T) {
+	mysqlConfig := serverTestConfig("mysql")
+	dsn := buildMySQLDSN(serverConfig(mysqlConfig))
+	if want := "app:secret@tcp(db.internal:3306)/?charset=utf8mb4&parseTime=True&loc=Local"; dsn != want {
+		t.Errorf("MySQL DSN = %q, 期望 %q", dsn, want)
+	}
+
+	pgConfig := serverTestConfig("postgres")
+	dsn = buildPostgresDSN(serverConfig(pgConfig))
+	if !strings.Contains(dsn, "dbname=postgres ") {
+		t.Errorf("PostgreSQL DSN 应连接维护库: %q", dsn)
+	}
+
+	// 不修改传入的配置
+	if mysqlConfig.Database != "orders" || pgConfig.Database != "orders" {
+		t.Errorf("serverConfig 不应修改原配置: %q, %q", mysqlConfig.Database, pgConfig.Database)
+	}
+
+	sqliteConfig := &Config{Driver: "sqlite", Database: "data/app.db"}
+	if got := serverConfig(sqliteConfig).Database; got != "data/app.db" {
+		t.Errorf("SQLite 配置不应改变，得到 %q", got)
+	}
+}
+
+func TestCreateDatabaseDDL(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		driver string
+		want   string
+	}{
+		{
+			name:   "MySQL默认字符集",
+			driver: "mysql",
+			want:   "CREATE DATABASE IF NOT EXISTS `orders` CHARACTER SET utf8mb4",
+		},
+		{
+			name:   "MySQL排序规则",
+			driver: "mysql",
+			modify: func(c *Config) { c.Collation = "utf8mb4_unicode_ci" },
+			want:   "CREATE DATABASE IF NOT EXISTS `orders` CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci",
+		},
+		{
+			name:   "PostgreSQL",
+			driver: "postgres",
+			want:   `CREATE DATABASE "orders"`,
+		},
+		{
+			name:   "PostgreSQL所有者和编码",
+			driver: "postgres",
+			modify: func(c *Config) { c.Owner = "app_owner"; c.Encoding = "UTF8" },
+			want:   `CREATE DATABASE "orders" OWNER "app_owner" ENCODING 'UTF8'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := serverTestConfig(tt.driver)
+			if tt.modify != nil {
+				tt.modify(config)
+			}
+			got, err := CreateDatabaseDDL(config)
+			if err != nil {
+				t.Fatalf("CreateDatabaseDDL 失败: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DDL = %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateDatabaseDDLRejectsInvalidNames(t *testing.T) {
+	tests := []struct {
+		name   string
+		driver string
+		modify func(c *Config)
+	}{
+		{"数据库名包含分号", "mysql", func(c *Config) { c.Database = "orders; DROP DATABASE x" }},
+		{"数据库名包含反引号", "mysql", func(c *Config) { c.Database = "ord`ers" }},
+		{"数据库名包含引号", "postgres", func(c *Config) { c.Database = `ord"ers` }},
+		{"数据库名过长", "postgres", func(c *Config) { c.Database = strings.Repeat("a", 65) }},
+		{"排序规则非法", "mysql", func(c *Config) { c.Collation = "utf8mb4_bin; DROP" }},
+		{"编码非法", "postgres", func(c *Config) { c.Encoding = "UTF8'" }},
+		{"所有者非法", "postgres", func(c *Config) { c.Owner = `app" SUPERUSER` }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := serverTestConfig(tt.driver)
+			tt.modify(config)
+			if _, err := CreateDatabaseDDL(config); !IsValidationError(err) {
+				t.Errorf("期望验证错误，得到 %v", err)
+			}
+		})
+	}
+}
+
+func TestEnsureDatabaseDisabled(t *testing.T) {
+	calls := stubServerDB(t, errors.New("不应连接"))
+
+	config := serverTestConfig("mysql")
+	config.DisableAutoCreate = true
+
+	if err := EnsureDatabase(context.Background(), config); !errors.Is(err, ErrAutoCreateDisabled) {
+		t.Errorf("期望 ErrAutoCreateDisabled，得到 %v", err)
+	}
+	if _, err := EnsureDatabaseDryRun(context.Background(), config); !errors.Is(err, ErrAutoCreateDisabled) {
+		t.Errorf("dry-run 期望 ErrAutoCreateDisabled，得到 %v", err)
+	}
+	if *calls != 0 {
+		t.Errorf("禁止自动建库时不应连接数据库，连接次数 %d", *calls)
+	}
+}
+
+func TestEnsureDatabaseValidatesBeforeConnect(t *testing.T) {
+	calls := stubServerDB(t, errors.New("connection refused"))
+
+	config := serverTestConfig("postgres")
+	config.Database = "orders;"
+	if err := EnsureDatabase(context.Background(), config); !IsValidationError(err) {
+		t.Errorf("期望验证错误，得到 %v", err)
+	}
+	if *calls != 0 {
+		t.Errorf("数据库名非法时不应连接数据库，连接次数 %d", *calls)
+	}
+
+	config.Database = "orders"
+	if err := EnsureDatabase(context.Background(), config); !IsConnectionError(err) {
+		t.Errorf("期望连接错误，得到 %v", err)
+	}
+	if *calls != 1 {
+		t.Errorf("连接次数 = %d, 期望 1", *calls)
+	}
+}
+
+func TestEnsureDatabaseSQLiteDir(t *testing.T) {
+	stubServerDB(t, errors.New("SQLite 不应连接服务端"))
+
+	dir := filepath.Join(t.TempDir(), "nested", "data")
+	config := &Config{Driver: "sqlite", Database: filepath.Join(dir, "app.db")}
+
+	ddl, err := EnsureDatabaseDryRun(context.Background(), config)
+	if err != nil {
+		t.Fatalf("dry-run 失败: %v", err)
+	}
+	if !strings.Contains(ddl, dir) {
+		t.Errorf("dry-run 应说明将创建的目录，得到 %q", ddl)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("dry-run 不应创建目录: %v", err)
+	}
+
+	if err := EnsureDatabase(context.Background(), config); err != nil {
+		t.Fatalf("EnsureDatabase 失败: %v", err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Fatalf("目录未创建: %v", err)
+	}
+
+	// 目录已存在时 dry-run 没有需要执行的操作
+	if ddl, err := EnsureDatabaseDryRun(context.Background(), config); err != nil || ddl != "" {
+		t.Errorf("目录已存在时 dry-run = %q, %v", ddl, err)
+	}
+
+	memory := &Config{Driver: "sqlite", Database: ":memory:"}
+	if err := EnsureDatabase(context.Background(), memory); err != nil {
+		t.Errorf("内存数据库不需要处理: %v", err)
+	}
+}
+
+func TestNewWithAutoCreate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "fresh")
+	config := testConfig()
+	config.Database = filepath.Join(dir, "app.db")
+	config.LogLevel = "silent"
+
+	if _, err := New(config); err == nil {
+		t.Fatal("目录不存在且未开启 AutoCreate 时应失败")
+	}
+
+	config.AutoCreate = true
+	config.DisableAutoCreate = true
+	if _, err := New(config); err == nil {
+		t.Fatal("DisableAutoCreate 应优先于 AutoCreate")
+	}
+
+	config.DisableAutoCreate = false
+	db, err := New(config)
+	if err != nil {
+		t.Fatalf("AutoCreate 时应创建目录并连接: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := os.Stat(config.Database); err != nil {
+		t.Errorf("数据库文件未创建: %v", err)
+	}
+}
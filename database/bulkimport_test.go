@@ -0,0 +1,329 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// generatedUsers 即时生成 n 个用户行的数据源，每次返回同一个切片以检查导入不依赖切片内容保持不变
+func generatedUsers(n int) RowSource {
+	i := 0
+	row := make([]interface{}, 3)
+	return RowSourceFunc(func() ([]interface{}, error) {
+		if i >= n {
+			return nil, io.EOF
+		}
+		i++
+		row[0], row[1], row[2] = fmt.Sprintf("user-%d", i), fmt.Sprintf("user-%d@example.com", i), i
+		return row, nil
+	})
+}
+
+var userColumns = []string{"name", "email", "age"}
+
+func TestBulkImport_Inserts(t *testing.T) {
+	db := seededBatchDatabase(t, 0)
+
+	var report ImportResult
+	err := db.BulkImport(context.Background(), "test_users", userColumns, generatedUsers(1234),
+		WithImportBatchSize(100), WithImportReport(func(r ImportResult) { report = r }))
+	if err != nil {
+		t.Fatalf("批量导入失败: %v", err)
+	}
+
+	if n := countUsers(t, db); n != 1234 {
+		t.Errorf("期望导入 1234 行，实际 %d", n)
+	}
+	if report.Rows != 1234 || report.Method != "insert" || report.Table != "test_users" || report.Duration <= 0 || report.Err != nil {
+		t.Errorf("导入报告不正确: %+v", report)
+	}
+
+	var last TestUser
+	db.GetDB().Order("id DESC").First(&last)
+	if last.Name != "user-1234" || last.Age != 1234 {
+		t.Errorf("最后一行数据不正确: %+v", last)
+	}
+}
+
+func TestBulkImport_RowErrors(t *testing.T) {
+	db := seededBatchDatabase(t, 0)
+	ctx := context.Background()
+
+	assertRow := func(t *testing.T, err error, want int64) {
+		t.Helper()
+		var rowErr *ImportRowError
+		if !errors.As(err, &rowErr) || rowErr.Row != want {
+			t.Fatalf("期望第 %d 行的错误，实际: %v", want, err)
+		}
+		if !strings.Contains(err.Error(), fmt.Sprintf("第 %d 行", want)) {
+			t.Errorf("错误消息应包含行号: %v", err)
+		}
+		if n := countUsers(t, db); n != 0 {
+			t.Errorf("导入失败后应回滚，实际有 %d 行", n)
+		}
+	}
+
+	t.Run("validator", func(t *testing.T) {
+		var report ImportResult
+		err := db.BulkImport(ctx, "test_users", userColumns, generatedUsers(20),
+			WithRowValidator(func(row int64, values []interface{}) error {
+				if values[2].(int) == 7 {
+					return errors.New("年龄不合法")
+				}
+				return nil
+			}),
+			WithImportReport(func(r ImportResult) { report = r }))
+		assertRow(t, err, 7)
+		if !strings.Contains(err.Error(), "年龄不合法") || report.Rows != 0 || report.Err != err {
+			t.Errorf("期望报告中包含校验错误, err=%v report=%+v", err, report)
+		}
+	})
+
+	t.Run("column count", func(t *testing.T) {
+		rows := SliceRows([][]interface{}{{"a", "a@example.com", 1}, {"b", "b@example.com"}})
+		assertRow(t, db.BulkImport(ctx, "test_users", userColumns, rows), 2)
+	})
+
+	t.Run("source error", func(t *testing.T) {
+		src := generatedUsers(3)
+		rows := RowSourceFunc(func() ([]interface{}, error) {
+			row, err := src.Next()
+			if err == io.EOF {
+				return nil, errors.New("CSV 格式错误")
+			}
+			return row, err
+		})
+		assertRow(t, db.BulkImport(ctx, "test_users", userColumns, rows), 4)
+	})
+
+	t.Run("database constraint", func(t *testing.T) {
+		// 第5行与第2行的邮箱重复，位于第二个批次中间
+		rows := make([][]interface{}, 0, 8)
+		for i := 1; i <= 8; i++ {
+			email := fmt.Sprintf("user-%d@example.com", i)
+			if i == 5 {
+				email = "user-2@example.com"
+			}
+			rows = append(rows, []interface{}{fmt.Sprintf("user-%d", i), email, i})
+		}
+		assertRow(t, db.BulkImport(ctx, "test_users", userColumns, SliceRows(rows), WithImportBatchSize(3)), 5)
+	})
+}
+
+func TestBulkImport_Truncate(t *testing.T) {
+	db := seededBatchDatabase(t, 30)
+	ctx := context.Background()
+
+	rows := SliceRows([][]interface{}{{"new-1", "new-1@example.com", 1}, {"new-2", "new-2@example.com", 2}})
+	if err := db.BulkImport(ctx, "test_users", userColumns, rows, WithTruncate()); err != nil {
+		t.Fatalf("批量导入失败: %v", err)
+	}
+	var names []string
+	db.GetDB().Model(&TestUser{}).Order("name").Pluck("name", &names)
+	if strings.Join(names, ",") != "new-1,new-2" {
+		t.Errorf("期望只剩新导入的行，实际 %v", names)
+	}
+
+	// 导入失败时清空一并回滚
+	err := db.BulkImport(ctx, "test_users", userColumns, SliceRows([][]interface{}{{"x"}}), WithTruncate())
+	if err == nil {
+		t.Fatal("期望导入失败")
+	}
+	if n := countUsers(t, db); n != 2 {
+		t.Errorf("期望清空被回滚，实际有 %d 行", n)
+	}
+
+	if err := db.BulkImport(ctx, "", userColumns, rows); !IsValidationError(err) {
+		t.Errorf("期望缺少表名时返回验证错误，实际: %v", err)
+	}
+}
+
+// fakeCopier 记录语句并消费 COPY 数据源的 pgCopier
+type fakeCopier struct {
+	execs  []string
+	copied [][]interface{}
+	err    error // CopyFrom 读完数据后返回的错误
+}
+
+func (f *fakeCopier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	f.execs = append(f.execs, sql)
+	return pgconn.CommandTag{}, nil
+}
+
+func (f *fakeCopier) CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, src pgx.CopyFromSource) (int64, error) {
+	for src.Next() {
+		values, _ := src.Values()
+		f.copied = append(f.copied, append([]interface{}(nil), values...))
+	}
+	if err := src.Err(); err != nil {
+		return 0, err
+	}
+	if f.err != nil {
+		return 0, f.err
+	}
+	return int64(len(f.copied)), nil
+}
+
+func TestCopyRows(t *testing.T) {
+	ctx := context.Background()
+	copier := &fakeCopier{}
+	src := &importSource{rows: generatedUsers(5), columns: 3}
+
+	n, err := copyRows(ctx, copier, "public.users", userColumns, src, &importOptions{truncate: true})
+	if err != nil || n != 5 || len(copier.copied) != 5 || copier.copied[4][0] != "user-5" {
+		t.Fatalf("COPY 结果不正确: n=%d err=%v rows=%v", n, err, copier.copied)
+	}
+	if len(copier.execs) != 1 || copier.execs[0] != `TRUNCATE TABLE "public"."users"` {
+		t.Errorf("期望先清空表，实际 %v", copier.execs)
+	}
+
+	// 数据库报告的错误按 COPY 的行号转换
+	copier = &fakeCopier{err: &pgconn.PgError{Code: "22P02", Message: `invalid input syntax for type integer: "x"`, Where: `COPY users, line 3, column age: "x"`}}
+	_, err = copyRows(ctx, copier, "users", userColumns, &importSource{rows: generatedUsers(5), columns: 3}, &importOptions{})
+	var rowErr *ImportRowError
+	if !errors.As(err, &rowErr) || rowErr.Row != 3 {
+		t.Errorf("期望第 3 行的错误，实际: %v", err)
+	}
+
+	// 数据源的错误优先于 COPY 的错误
+	copier = &fakeCopier{}
+	src = &importSource{rows: generatedUsers(5), columns: 3, validate: func(row int64, values []interface{}) error {
+		if row == 4 {
+			return errors.New("invalid")
+		}
+		return nil
+	}}
+	_, err = copyRows(ctx, copier, "users", userColumns, src, &importOptions{})
+	if !errors.As(err, &rowErr) || rowErr.Row != 4 || len(copier.copied) != 3 {
+		t.Errorf("期望在第 4 行停止，实际: %v（已发送 %d 行）", err, len(copier.copied))
+	}
+}
+
+// fakeLoadConn 记录语句，执行 LOAD DATA 时读取注册的数据读取器
+type fakeLoadConn struct {
+	readers map[string]func() io.Reader
+	execs   []string
+	data    string
+	err     error
+}
+
+func (f *fakeLoadConn) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	f.execs = append(f.execs, query)
+	if !strings.HasPrefix(query, "LOAD DATA") {
+		return driverResult(0), nil
+	}
+	start := strings.Index(query, "Reader::") + len("Reader::")
+	name := query[start : start+strings.IndexByte(query[start:], '\'')]
+	data, err := io.ReadAll(f.readers[name]())
+	if err != nil {
+		return nil, err
+	}
+	f.data = string(data)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return driverResult(strings.Count(f.data, "\n")), nil
+}
+
+type driverResult int64
+
+func (r driverResult) LastInsertId() (int64, error) { return 0, nil }
+func (r driverResult) RowsAffected() (int64, error) { return int64(r), nil }
+
+// withFakeReaders 替换 MySQL 数据读取器的注册函数
+func withFakeReaders(t *testing.T, conn *fakeLoadConn) {
+	conn.readers = make(map[string]func() io.Reader)
+	origRegister, origDeregister := registerReader, deregisterReader
+	registerReader = func(name string, handler func() io.Reader) { conn.readers[name] = handler }
+	deregisterReader = func(name string) { delete(conn.readers, name) }
+	t.Cleanup(func() { registerReader, deregisterReader = origRegister, origDeregister })
+}
+
+func backquote(name string) string { return "`" + name + "`" }
+
+func TestLoadDataRows(t *testing.T) {
+	ctx := context.Background()
+	conn := &fakeLoadConn{}
+	withFakeReaders(t, conn)
+
+	at := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+	rows := SliceRows([][]interface{}{
+		{"tab\there", "line\nbreak", 1},
+		{`back\slash`, nil, true},
+		{[]byte("raw"), at, sql.NullInt64{}},
+	})
+	src := &importSource{rows: rows, columns: 3}
+	n, err := loadDataRows(ctx, conn, backquote, "users", userColumns, src, &importOptions{truncate: true, disableIndexes: true})
+	if err != nil || n != 3 {
+		t.Fatalf("LOAD DATA 结果不正确: n=%d err=%v", n, err)
+	}
+
+	want := "tab\\there\tline\\nbreak\t1\n" +
+		"back\\\\slash\t\\N\t1\n" +
+		"raw\t2024-03-01 08:30:00\t\\N\n"
+	if conn.data != want {
+		t.Errorf("编码的数据不正确:\n%q\nwant:\n%q", conn.data, want)
+	}
+
+	wantExecs := []string{
+		"TRUNCATE TABLE `users`",
+		"SET unique_checks = 0",
+		"SET foreign_key_checks = 0",
+		"ALTER TABLE `users` DISABLE KEYS",
+		"LOAD DATA",
+		"ALTER TABLE `users` ENABLE KEYS",
+		"SET unique_checks = 1",
+		"SET foreign_key_checks = 1",
+	}
+	if len(conn.execs) != len(wantExecs) {
+		t.Fatalf("期望执行 %d 条语句，实际 %v", len(wantExecs), conn.execs)
+	}
+	for i, prefix := range wantExecs {
+		if !strings.HasPrefix(conn.execs[i], prefix) {
+			t.Errorf("第 %d 条语句期望以 %q 开头，实际 %q", i+1, prefix, conn.execs[i])
+		}
+	}
+	if !strings.HasSuffix(conn.execs[4], "INTO TABLE `users` CHARACTER SET utf8mb4 FIELDS TERMINATED BY '\\t' ESCAPED BY '\\\\' LINES TERMINATED BY '\\n' (`name`, `email`, `age`)") {
+		t.Errorf("LOAD DATA 语句不正确: %s", conn.execs[4])
+	}
+	if len(conn.readers) != 0 {
+		t.Error("期望导入结束后注销数据读取器")
+	}
+}
+
+func TestLoadDataRowsErrors(t *testing.T) {
+	ctx := context.Background()
+
+	// 数据库报告的行号
+	conn := &fakeLoadConn{err: &mysql.MySQLError{Number: 1366, Message: "Incorrect integer value: 'x' for column 'age' at row 2"}}
+	withFakeReaders(t, conn)
+	_, err := loadDataRows(ctx, conn, backquote, "users", userColumns, &importSource{rows: generatedUsers(3), columns: 3}, &importOptions{})
+	var rowErr *ImportRowError
+	if !errors.As(err, &rowErr) || rowErr.Row != 2 {
+		t.Errorf("期望第 2 行的错误，实际: %v", err)
+	}
+
+	// 数据源的错误中断数据流
+	conn = &fakeLoadConn{}
+	withFakeReaders(t, conn)
+	src := &importSource{rows: generatedUsers(5), columns: 3, validate: func(row int64, values []interface{}) error {
+		if row == 3 {
+			return errors.New("invalid")
+		}
+		return nil
+	}}
+	_, err = loadDataRows(ctx, conn, backquote, "users", userColumns, src, &importOptions{})
+	if !errors.As(err, &rowErr) || rowErr.Row != 3 {
+		t.Errorf("期望第 3 行的错误，实际: %v", err)
+	}
+}
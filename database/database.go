@@ -12,6 +12,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"gorm.io/driver/mysql"
@@ -51,6 +52,7 @@ const (
 	ErrorTypeQuery
 	ErrorTypeTransaction
 	ErrorTypeMigration
+	ErrorTypePlugin
 )
 
 // DatabaseError 数据库错误结构
@@ -157,6 +159,9 @@ const (
 	DefaultRetryMaxDelay      = 30 * time.Second
 	DefaultRetryBackoffFactor = 2.0
 	DefaultRetryJitterEnabled = true
+
+	// DefaultWarmUpTimeout 预热时建立单个连接的超时时间
+	DefaultWarmUpTimeout = 5 * time.Second
 )
 
 // Config 数据库配置
@@ -178,6 +183,18 @@ type Config struct {
 	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime" json:"conn_max_lifetime" yaml:"conn_max_lifetime"`
 	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time" json:"conn_max_idle_time" yaml:"conn_max_idle_time"`
 
+	// 连接预热配置（WarmUp）
+	WarmConnections int           `mapstructure:"warm_connections" json:"warm_connections" yaml:"warm_connections"` // New 时预先建立的连接数，0 表示不预热
+	WarmUpTimeout   time.Duration `mapstructure:"warm_up_timeout" json:"warm_up_timeout" yaml:"warm_up_timeout"`    // 建立单个连接的超时时间
+	WarmUpStrict    bool          `mapstructure:"warm_up_strict" json:"warm_up_strict" yaml:"warm_up_strict"`       // 预热未完全成功时 New 返回错误，默认只记录警告
+
+	// 自动建库配置（EnsureDatabase）
+	AutoCreate        bool   `mapstructure:"auto_create" json:"auto_create" yaml:"auto_create"`                         // New 连接前调用 EnsureDatabase 创建缺失的数据库
+	DisableAutoCreate bool   `mapstructure:"disable_auto_create" json:"disable_auto_create" yaml:"disable_auto_create"` // 禁止自动建库（生产环境保护），优先于 AutoCreate
+	Collation         string `mapstructure:"collation" json:"collation" yaml:"collation"`                               // MySQL 建库时的排序规则，为空时使用服务端默认值
+	Owner             string `mapstructure:"owner" json:"owner" yaml:"owner"`                                           // PostgreSQL 建库时的所有者，为空时为当前用户
+	Encoding          string `mapstructure:"encoding" json:"encoding" yaml:"encoding"`                                  // PostgreSQL 建库时的字符编码，例如 UTF8
+
 	// GORM日志配置
 	CustomLogger              logger.Interface `mapstructure:"-" json:"-" yaml:"-"`
 	LogLevel                  string           `mapstructure:"log_level" json:"log_level" yaml:"log_level"`
@@ -185,6 +202,14 @@ type Config struct {
 	IgnoreRecordNotFoundError bool             `mapstructure:"ignore_record_not_found_error" json:"ignore_record_not_found_error" yaml:"ignore_record_not_found_error"`
 	ParameterizedQueries      bool             `mapstructure:"parameterized_queries" json:"parameterized_queries" yaml:"parameterized_queries"`
 	Colorful                  bool             `mapstructure:"colorful" json:"colorful" yaml:"colorful"`
+	OnSlowQuery               SlowQueryFunc    `mapstructure:"-" json:"-" yaml:"-"` // RawCtx/ExecCtx 超过 SlowThreshold 时调用
+
+	// Plugins 在 gorm.Open 之后、配置连接池之前注册的GORM插件
+	Plugins []gorm.Plugin `mapstructure:"-" json:"-" yaml:"-"`
+
+	// 请求会话配置（SessionFromContext）
+	DefaultQueryTimeout time.Duration `mapstructure:"default_query_timeout" json:"default_query_timeout" yaml:"default_query_timeout"`
+	Session             SessionConfig `mapstructure:"session" json:"session" yaml:"session"`
 
 	// 连接重试配置
 	RetryMaxAttempts   int           `mapstructure:"retry_max_attempts" json:"retry_max_attempts" yaml:"retry_max_attempts"`
@@ -200,6 +225,20 @@ type Config struct {
 	DisableForeignKey bool   `mapstructure:"disable_foreign_key" json:"disable_foreign_key" yaml:"disable_foreign_key"`
 	PrepareStmt       bool   `mapstructure:"prepare_stmt" json:"prepare_stmt" yaml:"prepare_stmt"`
 	DryRun            bool   `mapstructure:"dry_run" json:"dry_run" yaml:"dry_run"`
+
+	// EnableSoftDelete AutoMigrate 时校验所有模型包含 gorm.DeletedAt 字段
+	EnableSoftDelete bool `mapstructure:"enable_soft_delete" json:"enable_soft_delete" yaml:"enable_soft_delete"`
+
+	// Guardrails 查询护栏（禁止全表 UPDATE/DELETE 等），默认全部关闭
+	Guardrails GuardrailsConfig `mapstructure:"guardrails" json:"guardrails" yaml:"guardrails"`
+
+	// Environment 运行环境（dev/staging/prod），prod 或 production 时启用生产环境安全联锁
+	Environment string `mapstructure:"environment" json:"environment" yaml:"environment"`
+	// ProductionSafety 生产环境安全联锁的放行开关
+	ProductionSafety ProductionSafetyConfig `mapstructure:"production_safety" json:"production_safety" yaml:"production_safety"`
+
+	// LeakDetection 长事务与连接泄漏检测，默认关闭
+	LeakDetection LeakDetectionConfig `mapstructure:"leak_detection" json:"leak_detection" yaml:"leak_detection"`
 }
 
 // SetDefaults 设置默认值
@@ -222,6 +261,9 @@ func (c *Config) SetDefaults() {
 	if c.ConnMaxIdleTime == 0 {
 		c.ConnMaxIdleTime = DefaultConnMaxIdleTime
 	}
+	if c.WarmUpTimeout == 0 {
+		c.WarmUpTimeout = DefaultWarmUpTimeout
+	}
 
 	// 重试配置默认值
 	if c.RetryMaxAttempts == 0 {
@@ -404,6 +446,9 @@ func (c *Config) validateConnectionPool() error {
 	if c.MaxOpenConns > 0 && c.MaxIdleConns > c.MaxOpenConns {
 		return fmt.Errorf("%w: 最大空闲连接数(%d)不能大于最大打开连接数(%d)", ErrInvalidConnPool, c.MaxIdleConns, c.MaxOpenConns)
 	}
+	if c.WarmConnections < 0 {
+		return fmt.Errorf("%w: 预热连接数不能为负数", ErrInvalidConnPool)
+	}
 
 	return nil
 }
@@ -419,6 +464,9 @@ func (c *Config) validateTimeouts() error {
 	if c.ConnMaxIdleTime < 0 {
 		return fmt.Errorf("%w: 连接最大空闲时间不能为负数", ErrInvalidTimeout)
 	}
+	if c.WarmUpTimeout < 0 {
+		return fmt.Errorf("%w: 预热超时时间不能为负数", ErrInvalidTimeout)
+	}
 
 	return nil
 }
@@ -482,6 +530,7 @@ func isValidDatabaseName(name string) bool {
 // PoolStats 连接池统计信息
 type PoolStats struct {
 	OpenConnections   int
+	InUseConnections  int
 	IdleConnections   int
 	WaitCount         int64
 	WaitDuration      time.Duration
@@ -491,9 +540,11 @@ type PoolStats struct {
 
 // Database 数据库管理器
 type Database struct {
-	config *Config
-	db     *gorm.DB
-	mu     sync.RWMutex
+	config  *Config
+	db      *gorm.DB
+	mu      sync.RWMutex
+	closing atomic.Bool  // 已调用 Shutdown
+	leaks   *leakTracker // 泄漏检测，未启用时为nil
 }
 
 // New 创建新的数据库管理器
@@ -501,6 +552,13 @@ func New(config *Config) (*Database, error) {
 	// 设置默认值
 	config.SetDefaults()
 
+	// 自动建库需要在验证前执行，SQLite 的目录由 EnsureDatabase 创建
+	if config.AutoCreate && !config.DisableAutoCreate {
+		if err := EnsureDatabase(context.Background(), config); err != nil {
+			return nil, fmt.Errorf("创建数据库失败: %w", err)
+		}
+	}
+
 	// 验证配置
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("配置验证失败: %w", err)
@@ -516,18 +574,51 @@ func New(config *Config) (*Database, error) {
 		db:     db,
 	}
 
+	if err := database.registerShutdownGuard(); err != nil {
+		return nil, database.closeAfterError("注册回调失败", err)
+	}
+	if err := database.registerGuardrails(config.Guardrails); err != nil {
+		return nil, database.closeAfterError("注册查询护栏失败", err)
+	}
+	if err := database.registerProductionSafety(*config); err != nil {
+		return nil, database.closeAfterError("注册生产环境安全联锁失败", err)
+	}
+
+	// 注册插件
+	if err := database.applyPlugins(config.Plugins); err != nil {
+		return nil, database.closeAfterError("注册插件失败", err)
+	}
+
+	// 泄漏检测在 Close 时停止
+	database.leaks = newLeakTracker(config.LeakDetection, database.db.Logger)
+
 	// 配置连接池
 	if err := database.configurePool(); err != nil {
-		// 如果连接池配置失败，关闭已建立的连接
-		if closeErr := database.Close(); closeErr != nil {
-			return nil, fmt.Errorf("配置连接池失败: %w (关闭连接时发生额外错误: %v)", err, closeErr)
+		return nil, database.closeAfterError("配置连接池失败", err)
+	}
+
+	// 预热连接，默认不因部分失败而中止启动
+	if config.WarmConnections > 0 {
+		ctx := context.Background()
+		if err := database.WarmUp(ctx, config.WarmConnections); err != nil {
+			if config.WarmUpStrict {
+				return nil, database.closeAfterError("预热连接失败", err)
+			}
+			database.db.Logger.Warn(ctx, "预热连接未完全成功: %v", err)
 		}
-		return nil, fmt.Errorf("配置连接池失败: %w", err)
 	}
 
 	return database, nil
 }
 
+// closeAfterError 初始化失败时关闭已建立的连接，并合并关闭时的错误
+func (d *Database) closeAfterError(operation string, err error) error {
+	if closeErr := d.Close(); closeErr != nil {
+		return fmt.Errorf("%s: %w (关闭连接时发生额外错误: %v)", operation, err, closeErr)
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
+
 // connect 连接数据库
 func connect(config *Config) (*gorm.DB, error) {
 	if config.RetryEnabled && config.RetryMaxAttempts > 1 {
@@ -536,25 +627,26 @@ func connect(config *Config) (*gorm.DB, error) {
 	return connectOnce(config)
 }
 
-// connectOnce 单次连接数据库
-func connectOnce(config *Config) (*gorm.DB, error) {
-	var dialector gorm.Dialector
-
+// newDialector 根据驱动创建GORM方言
+func newDialector(config *Config) (gorm.Dialector, error) {
 	switch config.Driver {
 	case "mysql":
-		dsn := buildMySQLDSN(config)
-		dialector = mysql.Open(dsn)
-
+		return mysql.Open(buildMySQLDSN(config)), nil
 	case "postgres":
-		dsn := buildPostgresDSN(config)
-		dialector = postgres.Open(dsn)
-
+		return postgres.Open(buildPostgresDSN(config)), nil
 	case "sqlite":
-		dialector = sqlite.Open(config.Database)
-
+		return sqlite.Open(config.Database), nil
 	default:
 		return nil, fmt.Errorf("不支持的数据库驱动: %s", config.Driver)
 	}
+}
+
+// connectOnce 单次连接数据库
+func connectOnce(config *Config) (*gorm.DB, error) {
+	dialector, err := newDialector(config)
+	if err != nil {
+		return nil, err
+	}
 
 	// 配置GORM
 	gormConfig := &gorm.Config{
@@ -710,6 +802,10 @@ func (d *Config) SetCustomLogger(l SimpleLogger, level string) {
 
 // Close 关闭数据库连接
 func (d *Database) Close() error {
+	if d.leaks != nil {
+		d.leaks.close()
+	}
+
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -739,6 +835,7 @@ func (d *Database) Stats() PoolStats {
 	stats := sqlDB.Stats()
 	return PoolStats{
 		OpenConnections:   stats.OpenConnections,
+		InUseConnections:  stats.InUse,
 		IdleConnections:   stats.Idle,
 		WaitCount:         stats.WaitCount,
 		WaitDuration:      stats.WaitDuration,
@@ -854,8 +951,32 @@ func (d *Database) performQueryTest(ctx context.Context) error {
 }
 
 // AutoMigrate 自动迁移数据库表
+// 启用 EnableSoftDelete 时先校验所有模型支持软删除，任一模型不支持则不执行迁移
+// 生产环境中需要确认，见 AutoMigrateCtx
 func (d *Database) AutoMigrate(dst ...interface{}) error {
-	return d.db.AutoMigrate(dst...)
+	return d.AutoMigrateCtx(context.Background(), dst...)
+}
+
+// AutoMigrateCtx 与 AutoMigrate 相同，迁移通过 WithContext(ctx) 执行
+//
+// Environment 为生产环境时，ctx 必须通过 ConfirmDestructive 确认
+// （或开启 ProductionSafety.AllowAutoMigrate），否则返回 ErrDestructiveOperation。
+//
+// 示例:
+//
+//	ctx := database.ConfirmDestructive(ctx, "v2.3 发布迁移")
+//	err := db.AutoMigrateCtx(ctx, &User{}, &Order{})
+func (d *Database) AutoMigrateCtx(ctx context.Context, dst ...interface{}) error {
+	cfg := d.GetConfig()
+	if cfg.EnableSoftDelete {
+		if err := d.ValidateSoftDelete(dst...); err != nil {
+			return err
+		}
+	}
+	if err := checkDestructive(ctx, cfg, "AutoMigrate", "", cfg.ProductionSafety.AllowAutoMigrate); err != nil {
+		return err
+	}
+	return d.WithContext(ctx).AutoMigrate(dst...)
 }
 
 // IsConnected 检查数据库连接状态
@@ -864,7 +985,12 @@ func (d *Database) IsConnected() bool {
 }
 
 // WithContext 返回带有Context的GORM实例
+// ctx 中有 TransactionCtx 开启的事务时，返回的实例加入该事务
 func (d *Database) WithContext(ctx context.Context) *gorm.DB {
+	if tx, ok := d.txFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 	return d.db.WithContext(ctx)
@@ -893,11 +1019,23 @@ func (d *Database) IsReadOnly() bool {
 
 // Transaction 事务便利方法，自动处理提交和回滚
 func (d *Database) Transaction(fn func(*gorm.DB) error) error {
+	if d.closing.Load() {
+		return ErrDatabaseClosing
+	}
+	if d.leaks != nil {
+		return d.leaks.transaction(d.db, d.leaks.callers(1), fn)
+	}
 	return d.db.Transaction(fn)
 }
 
 // TransactionWithContext 带Context的事务便利方法
 func (d *Database) TransactionWithContext(ctx context.Context, fn func(*gorm.DB) error) error {
+	if d.closing.Load() {
+		return ErrDatabaseClosing
+	}
+	if d.leaks != nil {
+		return d.leaks.transaction(d.db.WithContext(ctx), d.leaks.callers(1), fn)
+	}
 	return d.db.WithContext(ctx).Transaction(fn)
 }
 
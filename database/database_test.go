@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
@@ -49,6 +50,25 @@ func testDatabase(t *testing.T) *Database {
 	return db
 }
 
+// newFileTestDatabase 创建基于临时文件的 sqlite 测试数据库，测试结束时自动关闭
+// opts 在 testConfig 的基础上调整配置
+func newFileTestDatabase(t *testing.T, opts ...func(*Config)) *Database {
+	t.Helper()
+
+	config := testConfig()
+	config.Database = filepath.Join(t.TempDir(), "test.db")
+	config.LogLevel = "silent"
+	for _, opt := range opts {
+		opt(config)
+	}
+	db, err := New(config)
+	if err != nil {
+		t.Fatalf("创建测试数据库失败: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
 // TestConfig_Validate 测试配置验证
 func TestConfig_Validate(t *testing.T) {
 	t.Run("有效配置", func(t *testing.T) {
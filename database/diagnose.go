@@ -0,0 +1,331 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DefaultDiagnoseStepTimeout 诊断时每个网络步骤的超时时间
+const DefaultDiagnoseStepTimeout = 5 * time.Second
+
+// 诊断步骤名称，按执行顺序排列
+const (
+	DiagnoseStepConfig   = "config"   // 配置校验
+	DiagnoseStepDNS      = "dns"      // 解析 Host
+	DiagnoseStepTCP      = "tcp"      // 连接 Host:Port
+	DiagnoseStepAuth     = "auth"     // 用户名和密码认证
+	DiagnoseStepDatabase = "database" // 数据库是否存在并可访问
+)
+
+// DiagnosisStatus 诊断步骤的结果
+type DiagnosisStatus string
+
+const (
+	DiagnosisOK      DiagnosisStatus = "ok"
+	DiagnosisFailed  DiagnosisStatus = "failed"
+	DiagnosisSkipped DiagnosisStatus = "skipped" // 前面的步骤失败或该驱动不需要
+)
+
+// DiagnosisStep 单个诊断步骤
+type DiagnosisStep struct {
+	Name    string          `json:"name"`
+	Status  DiagnosisStatus `json:"status"`
+	Elapsed time.Duration   `json:"elapsed"`
+	Detail  string          `json:"detail,omitempty"` // 例如解析到的地址
+	Error   string          `json:"error,omitempty"`
+	Hint    string          `json:"hint,omitempty"` // 失败时的排查建议
+	Err     error           `json:"-"`
+}
+
+// DiagnosisReport 连接诊断报告
+type DiagnosisReport struct {
+	Driver    string          `json:"driver"`
+	Target    string          `json:"target"` // host:port，SQLite 为文件路径
+	Timestamp time.Time       `json:"timestamp"`
+	Steps     []DiagnosisStep `json:"steps"`
+}
+
+// OK 所有步骤都没有失败
+func (r *DiagnosisReport) OK() bool {
+	return r.FailedStep() == nil
+}
+
+// FailedStep 返回第一个失败的步骤，全部成功时返回nil
+func (r *DiagnosisReport) FailedStep() *DiagnosisStep {
+	for i := range r.Steps {
+		if r.Steps[i].Status == DiagnosisFailed {
+			return &r.Steps[i]
+		}
+	}
+	return nil
+}
+
+// Err 返回第一个失败步骤对应的 ErrorTypeConnection 错误（配置校验失败时为 ErrorTypeValidation），全部成功时返回nil
+func (r *DiagnosisReport) Err() error {
+	step := r.FailedStep()
+	if step == nil {
+		return nil
+	}
+	errorType := ErrorTypeConnection
+	if step.Name == DiagnoseStepConfig {
+		errorType = ErrorTypeValidation
+	}
+	return NewDatabaseError(errorType, "diagnose", step.Err).
+		WithContext("step", step.Name).
+		WithContext("target", r.Target)
+}
+
+// String 返回多行的可读报告
+func (r *DiagnosisReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "数据库连接诊断 [%s %s]\n", r.Driver, r.Target)
+	for _, step := range r.Steps {
+		fmt.Fprintf(&b, "  %-8s %-7s", step.Name, step.Status)
+		if step.Status != DiagnosisSkipped {
+			fmt.Fprintf(&b, " %v", step.Elapsed.Round(time.Millisecond))
+		}
+		if step.Detail != "" {
+			fmt.Fprintf(&b, " %s", step.Detail)
+		}
+		if step.Error != "" {
+			fmt.Fprintf(&b, " 错误: %s", step.Error)
+		}
+		if step.Hint != "" {
+			fmt.Fprintf(&b, " 建议: %s", step.Hint)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Diagnose 逐步检查数据库连接，返回每一步的结果
+//
+// 依次执行：配置校验（SetDefaults + Validate，不修改传入的配置）、DNS 解析、TCP 连接、
+// 认证和数据库是否存在，某一步失败后后续步骤标记为 skipped。
+// 与 New 不同，Diagnose 不重试，用于在启动失败时把笼统的"连接失败"转换为可操作的信息。
+// SQLite 只执行配置校验和数据库检查。
+//
+// 示例:
+//
+//	db, err := database.New(cfg)
+//	if err != nil {
+//	    report := database.Diagnose(cfg)
+//	    log.Fatalf("数据库不可用:\n%s", report)
+//	}
+func Diagnose(config *Config) *DiagnosisReport {
+	return DiagnoseContext(context.Background(), config)
+}
+
+// DiagnoseContext 与 Diagnose 相同，ctx 取消时停止诊断
+// 每个网络步骤另有 DefaultDiagnoseStepTimeout 的超时
+func DiagnoseContext(ctx context.Context, config *Config) *DiagnosisReport {
+	report := &DiagnosisReport{Timestamp: time.Now()}
+	if config == nil {
+		report.Steps = append(report.Steps, DiagnosisStep{
+			Name:   DiagnoseStepConfig,
+			Status: DiagnosisFailed,
+			Error:  "配置不能为空",
+			Err:    errors.New("配置不能为空"),
+		})
+		return report
+	}
+
+	cfg := *config
+	cfg.SetDefaults()
+	report.Driver = cfg.Driver
+	report.Target = diagnoseTarget(&cfg)
+
+	d := &diagnosis{report: report}
+	d.run(DiagnoseStepConfig, func() (string, string, error) {
+		return "", "修正配置后重试", cfg.Validate()
+	})
+
+	if cfg.Driver != "sqlite" {
+		d.run(DiagnoseStepDNS, func() (string, string, error) {
+			addrs, err := resolveHost(ctx, cfg.Host)
+			return strings.Join(addrs, ","), "检查 Host 拼写、DNS 配置或容器网络", err
+		})
+		d.run(DiagnoseStepTCP, func() (string, string, error) {
+			return "", "检查端口是否正确、数据库是否已启动，以及防火墙或安全组规则", dialTCP(ctx, cfg.Host, cfg.Port)
+		})
+	}
+
+	d.runSession(ctx, &cfg)
+	return report
+}
+
+// diagnosis 按顺序执行诊断步骤，失败后跳过剩余步骤
+type diagnosis struct {
+	report *DiagnosisReport
+	failed bool
+}
+
+// run 执行一个步骤，fn 返回详情、失败时的建议和错误
+func (d *diagnosis) run(name string, fn func() (detail, hint string, err error)) {
+	if d.failed {
+		d.skip(name)
+		return
+	}
+	start := time.Now()
+	detail, hint, err := fn()
+	step := DiagnosisStep{Name: name, Status: DiagnosisOK, Elapsed: time.Since(start), Detail: detail}
+	if err != nil {
+		step.Status = DiagnosisFailed
+		step.Error = err.Error()
+		step.Err = err
+		step.Hint = hint
+		d.failed = true
+	}
+	d.report.Steps = append(d.report.Steps, step)
+}
+
+func (d *diagnosis) skip(name string) {
+	d.report.Steps = append(d.report.Steps, DiagnosisStep{Name: name, Status: DiagnosisSkipped})
+}
+
+// runSession 建立数据库会话并按驱动返回的错误码区分认证失败和数据库不存在
+// 两者通过同一次连接检查，数据库不存在意味着认证已经通过
+func (d *diagnosis) runSession(ctx context.Context, cfg *Config) {
+	if cfg.Driver == "sqlite" {
+		d.run(DiagnoseStepDatabase, func() (string, string, error) {
+			hint := "检查文件路径和读写权限"
+			if cfg.Database != ":memory:" {
+				// 打开不存在的文件会创建它，诊断不应产生副作用
+				if _, err := os.Stat(cfg.Database); os.IsNotExist(err) {
+					return "文件不存在，首次连接时创建", hint, nil
+				}
+			}
+			return "", hint, pingDatabase(ctx, cfg)
+		})
+		return
+	}
+	if d.failed {
+		d.skip(DiagnoseStepAuth)
+		d.skip(DiagnoseStepDatabase)
+		return
+	}
+
+	start := time.Now()
+	err := pingDatabase(ctx, cfg)
+	elapsed := time.Since(start)
+
+	auth := DiagnosisStep{Name: DiagnoseStepAuth, Status: DiagnosisOK, Elapsed: elapsed}
+	database := DiagnosisStep{Name: DiagnoseStepDatabase, Status: DiagnosisOK, Elapsed: elapsed, Detail: cfg.Database}
+	switch {
+	case err == nil:
+	case isUnknownDatabaseError(err):
+		database.Status = DiagnosisFailed
+		database.Error, database.Err = err.Error(), err
+		database.Hint = "数据库不存在或当前用户无权访问，先创建数据库或检查 Database 名称和授权"
+	case isAuthError(err):
+		auth.Status = DiagnosisFailed
+		auth.Error, auth.Err = err.Error(), err
+		auth.Hint = "检查 Username、Password 以及用户允许的来源主机"
+		database = DiagnosisStep{Name: DiagnoseStepDatabase, Status: DiagnosisSkipped}
+	default:
+		// 无法区分的错误（TLS、协议等）归入认证步骤
+		auth.Status = DiagnosisFailed
+		auth.Error, auth.Err = err.Error(), err
+		auth.Hint = "TCP 连接成功但无法建立会话，检查 SSLMode、服务端协议版本和数据库日志"
+		database = DiagnosisStep{Name: DiagnoseStepDatabase, Status: DiagnosisSkipped}
+	}
+	d.report.Steps = append(d.report.Steps, auth, database)
+	d.failed = auth.Status == DiagnosisFailed || database.Status == DiagnosisFailed
+}
+
+// diagnoseTarget 返回报告中显示的连接目标
+func diagnoseTarget(cfg *Config) string {
+	if cfg.Driver == "sqlite" {
+		return cfg.Database
+	}
+	return net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+}
+
+// resolveHost 解析主机名，IP 地址直接返回
+func resolveHost(ctx context.Context, host string) ([]string, error) {
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, DefaultDiagnoseStepTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("解析主机 %s 失败: %w", host, err)
+	}
+	return addrs, nil
+}
+
+// dialTCP 检查端口是否可以建立 TCP 连接
+func dialTCP(ctx context.Context, host string, port int) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultDiagnoseStepTimeout)
+	defer cancel()
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("连接 %s 失败: %w", addr, err)
+	}
+	return conn.Close()
+}
+
+// pingDatabase 使用与 New 相同的方言建立单个连接并执行 Ping
+func pingDatabase(ctx context.Context, cfg *Config) error {
+	dialector, err := newDialector(cfg)
+	if err != nil {
+		return err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:               logger.Discard,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultDiagnoseStepTimeout)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
+
+// isAuthError 判断是否为认证失败
+// MySQL: 1045 ER_ACCESS_DENIED_ERROR；PostgreSQL: 28P01 invalid_password、28000 invalid_authorization_specification
+func isAuthError(err error) bool {
+	var mysqlErr *mysqldriver.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1045
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "28P01" || pgErr.Code == "28000"
+	}
+	return false
+}
+
+// isUnknownDatabaseError 判断是否为数据库不存在或无权访问
+// MySQL: 1049 ER_BAD_DB_ERROR、1044 ER_DBACCESS_DENIED_ERROR；PostgreSQL: 3D000 invalid_catalog_name
+func isUnknownDatabaseError(err error) bool {
+	var mysqlErr *mysqldriver.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1049 || mysqlErr.Number == 1044
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "3D000"
+	}
+	return false
+}
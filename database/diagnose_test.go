@@ -0,0 +1,184 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func stepStatuses(report *DiagnosisReport) map[string]DiagnosisStatus {
+	statuses := make(map[string]DiagnosisStatus, len(report.Steps))
+	for _, step := range report.Steps {
+		statuses[step.Name] = step.Status
+	}
+	return statuses
+}
+
+func mysqlDiagnoseConfig(host string, port int) *Config {
+	return &Config{
+		Driver:   "mysql",
+		Host:     host,
+		Port:     port,
+		Username: "app",
+		Password: "secret",
+		Database: "app",
+	}
+}
+
+func TestDiagnose_SQLite(t *testing.T) {
+	report := Diagnose(testConfig())
+	if !report.OK() {
+		t.Fatalf("期望诊断通过:\n%s", report)
+	}
+	statuses := stepStatuses(report)
+	if statuses[DiagnoseStepConfig] != DiagnosisOK || statuses[DiagnoseStepDatabase] != DiagnosisOK {
+		t.Errorf("期望 config 和 database 通过, 实际 %v", statuses)
+	}
+	if _, ok := statuses[DiagnoseStepDNS]; ok {
+		t.Error("SQLite 不应执行 DNS 检查")
+	}
+	if report.Err() != nil {
+		t.Errorf("诊断通过时 Err 应为nil, 实际 %v", report.Err())
+	}
+}
+
+func TestDiagnose_SQLiteMissingFileNotCreated(t *testing.T) {
+	config := testConfig()
+	config.Database = filepath.Join(t.TempDir(), "missing.db")
+
+	report := Diagnose(config)
+	if !report.OK() {
+		t.Fatalf("期望诊断通过:\n%s", report)
+	}
+	if _, err := os.Stat(config.Database); !os.IsNotExist(err) {
+		t.Error("诊断不应创建数据库文件")
+	}
+}
+
+func TestDiagnose_InvalidConfig(t *testing.T) {
+	config := mysqlDiagnoseConfig("", 3306)
+	report := Diagnose(config)
+
+	step := report.FailedStep()
+	if step == nil || step.Name != DiagnoseStepConfig {
+		t.Fatalf("期望 config 步骤失败:\n%s", report)
+	}
+	if !errors.Is(report.Err(), ErrMissingHost) || !IsValidationError(report.Err()) {
+		t.Errorf("期望包装 ErrMissingHost 的验证错误, 实际 %v", report.Err())
+	}
+	for _, name := range []string{DiagnoseStepDNS, DiagnoseStepTCP, DiagnoseStepAuth, DiagnoseStepDatabase} {
+		if stepStatuses(report)[name] != DiagnosisSkipped {
+			t.Errorf("期望 %s 被跳过:\n%s", name, report)
+		}
+	}
+	if config.Charset != "" {
+		t.Error("Diagnose 不应修改传入的配置")
+	}
+}
+
+func TestDiagnose_DNSFailure(t *testing.T) {
+	report := Diagnose(mysqlDiagnoseConfig("db.invalid", 3306))
+
+	step := report.FailedStep()
+	if step == nil || step.Name != DiagnoseStepDNS {
+		t.Fatalf("期望 dns 步骤失败:\n%s", report)
+	}
+	if step.Hint == "" {
+		t.Error("失败步骤应包含排查建议")
+	}
+	if !IsConnectionError(report.Err()) {
+		t.Errorf("期望连接错误, 实际 %v", report.Err())
+	}
+	if stepStatuses(report)[DiagnoseStepTCP] != DiagnosisSkipped {
+		t.Errorf("dns 失败后应跳过 tcp:\n%s", report)
+	}
+}
+
+func TestDiagnose_TCPFailure(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("无法监听端口: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	report := Diagnose(mysqlDiagnoseConfig("127.0.0.1", port))
+
+	step := report.FailedStep()
+	if step == nil || step.Name != DiagnoseStepTCP {
+		t.Fatalf("期望 tcp 步骤失败:\n%s", report)
+	}
+	if stepStatuses(report)[DiagnoseStepDNS] != DiagnosisOK {
+		t.Errorf("IP 地址的 dns 步骤应通过:\n%s", report)
+	}
+	if !strings.Contains(report.String(), "tcp      failed") {
+		t.Errorf("报告中应包含失败的 tcp 步骤:\n%s", report)
+	}
+}
+
+func TestDiagnose_SessionFailure(t *testing.T) {
+	// 接受连接后立即关闭，模拟端口可达但不是数据库服务
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("无法监听端口: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	report := Diagnose(mysqlDiagnoseConfig("127.0.0.1", listener.Addr().(*net.TCPAddr).Port))
+
+	statuses := stepStatuses(report)
+	if statuses[DiagnoseStepTCP] != DiagnosisOK || statuses[DiagnoseStepAuth] != DiagnosisFailed {
+		t.Fatalf("期望 tcp 通过、auth 失败:\n%s", report)
+	}
+	if statuses[DiagnoseStepDatabase] != DiagnosisSkipped {
+		t.Errorf("auth 失败后应跳过 database:\n%s", report)
+	}
+}
+
+func TestDiagnose_ClassifyDriverErrors(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		auth, unknownDB bool
+	}{
+		{"mysql access denied", &mysqldriver.MySQLError{Number: 1045}, true, false},
+		{"mysql unknown database", &mysqldriver.MySQLError{Number: 1049}, false, true},
+		{"mysql db access denied", &mysqldriver.MySQLError{Number: 1044}, false, true},
+		{"postgres invalid password", &pgconn.PgError{Code: "28P01"}, true, false},
+		{"postgres unknown database", fmt.Errorf("connect: %w", &pgconn.PgError{Code: "3D000"}), false, true},
+		{"other", errors.New("tls: handshake failure"), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isAuthError(tt.err); got != tt.auth {
+				t.Errorf("isAuthError = %v, 期望 %v", got, tt.auth)
+			}
+			if got := isUnknownDatabaseError(tt.err); got != tt.unknownDB {
+				t.Errorf("isUnknownDatabaseError = %v, 期望 %v", got, tt.unknownDB)
+			}
+		})
+	}
+}
+
+func TestDiagnose_NilConfig(t *testing.T) {
+	report := Diagnose(nil)
+	if report.OK() || report.FailedStep().Name != DiagnoseStepConfig {
+		t.Errorf("nil 配置期望 config 步骤失败:\n%s", report)
+	}
+}
@@ -0,0 +1,293 @@
+package database
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AllowGlobalKey 单次调用绕过查询护栏的设置键，用于有意为之的维护操作
+//
+//	db.GetDB().Set(database.AllowGlobalKey, true).Exec("DELETE FROM sessions")
+//
+// 通过查询构建器的全表操作还需要GORM自身的 AllowGlobalUpdate：
+//
+//	db.GetDB().Set(database.AllowGlobalKey, true).
+//	    Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&Session{})
+const AllowGlobalKey = "kit:allow_global"
+
+// 护栏名称，违反时记录在 DatabaseError.Context["guardrail"] 或警告日志中
+const (
+	GuardrailGlobalUpdateDelete = "forbid_global_update_delete"
+	GuardrailUnindexedLike      = "require_limit_on_unindexed_like"
+	GuardrailMaxRows            = "max_rows_warning"
+)
+
+// ErrGlobalUpdateDelete UPDATE/DELETE 没有 WHERE 条件
+var ErrGlobalUpdateDelete = errors.New("禁止没有 WHERE 条件的 UPDATE/DELETE")
+
+// GuardrailsConfig 查询护栏配置，默认全部关闭
+type GuardrailsConfig struct {
+	// ForbidGlobalUpdateDelete 拒绝没有 WHERE 条件（也没有主键值）的 UPDATE/DELETE，包括 Exec 执行的原生SQL
+	ForbidGlobalUpdateDelete bool `mapstructure:"forbid_global_update_delete" json:"forbid_global_update_delete" yaml:"forbid_global_update_delete"`
+	// RequireLimitOnUnindexedLikeQueries 前导通配符的 LIKE 查询（无法使用索引）没有 LIMIT 时记录警告
+	RequireLimitOnUnindexedLikeQueries bool `mapstructure:"require_limit_on_unindexed_like_queries" json:"require_limit_on_unindexed_like_queries" yaml:"require_limit_on_unindexed_like_queries"`
+	// MaxRowsWarning 单次查询返回的行数超过该值时记录警告，0表示不检查
+	MaxRowsWarning int64 `mapstructure:"max_rows_warning" json:"max_rows_warning" yaml:"max_rows_warning"`
+}
+
+// enabled 是否启用了任一护栏
+func (g GuardrailsConfig) enabled() bool {
+	return g.ForbidGlobalUpdateDelete || g.RequireLimitOnUnindexedLikeQueries || g.MaxRowsWarning > 0
+}
+
+// IsGuardrailViolation 判断错误是否由查询护栏产生，返回护栏名称
+func IsGuardrailViolation(err error) (string, bool) {
+	var dbErr *DatabaseError
+	if !errors.As(err, &dbErr) || dbErr.Type != ErrorTypeValidation {
+		return "", false
+	}
+	name, ok := dbErr.Context["guardrail"].(string)
+	return name, ok
+}
+
+const guardrailsCallbackName = "go-kit:guardrails"
+
+// registerGuardrails 按配置注册查询护栏回调
+func (d *Database) registerGuardrails(cfg GuardrailsConfig) error {
+	if !cfg.enabled() {
+		return nil
+	}
+
+	callbacks := d.db.Callback()
+	var registrations []error
+	if cfg.ForbidGlobalUpdateDelete {
+		registrations = append(registrations,
+			callbacks.Update().Before("gorm:update").Register(guardrailsCallbackName, forbidGlobalBuilder),
+			callbacks.Delete().Before("gorm:delete").Register(guardrailsCallbackName, forbidGlobalBuilder),
+			callbacks.Raw().Before("gorm:raw").Register(guardrailsCallbackName, forbidGlobalRaw),
+			callbacks.Row().Before("gorm:row").Register(guardrailsCallbackName, forbidGlobalRaw),
+		)
+	}
+	if cfg.RequireLimitOnUnindexedLikeQueries || cfg.MaxRowsWarning > 0 {
+		warn := func(db *gorm.DB) { warnQuery(db, cfg) }
+		registrations = append(registrations,
+			callbacks.Query().After("gorm:query").Register(guardrailsCallbackName, warn),
+		)
+	}
+	for _, err := range registrations {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// guardrailAllowed 当前调用是否通过 AllowGlobalKey 绕过护栏
+func guardrailAllowed(db *gorm.DB) bool {
+	allowed, _ := db.Get(AllowGlobalKey)
+	ok, _ := allowed.(bool)
+	return ok
+}
+
+// rejectGlobal 记录全表操作的护栏错误
+func rejectGlobal(db *gorm.DB, sql string) {
+	err := NewDatabaseError(ErrorTypeValidation, "查询护栏", ErrGlobalUpdateDelete).
+		WithContext("guardrail", GuardrailGlobalUpdateDelete)
+	if sql != "" {
+		err = err.WithContext("sql", sql)
+	}
+	db.AddError(err)
+}
+
+// forbidGlobalBuilder 检查查询构建器生成的 UPDATE/DELETE
+func forbidGlobalBuilder(db *gorm.DB) {
+	if db.Error != nil || guardrailAllowed(db) {
+		return
+	}
+	// Exec 等原生SQL由 forbidGlobalRaw 检查
+	if db.Statement.SQL.Len() > 0 {
+		return
+	}
+	if hasWhereClause(db.Statement) || hasPrimaryKeyValue(db.Statement) {
+		return
+	}
+	table := db.Statement.Table
+	if table == "" && db.Statement.Schema != nil {
+		table = db.Statement.Schema.Table
+	}
+	err := NewDatabaseError(ErrorTypeValidation, "查询护栏", ErrGlobalUpdateDelete).
+		WithContext("guardrail", GuardrailGlobalUpdateDelete).
+		WithContext("table", table)
+	db.AddError(err)
+}
+
+// forbidGlobalRaw 检查原生SQL中的 UPDATE/DELETE
+func forbidGlobalRaw(db *gorm.DB) {
+	if db.Error != nil || guardrailAllowed(db) || db.Statement.SQL.Len() == 0 {
+		return
+	}
+	sql := db.Statement.SQL.String()
+	if isGlobalUpdateDelete(sql) {
+		rejectGlobal(db, sql)
+	}
+}
+
+// hasWhereClause 语句是否已有 WHERE 条件
+func hasWhereClause(stmt *gorm.Statement) bool {
+	c, ok := stmt.Clauses["WHERE"]
+	if !ok {
+		return false
+	}
+	where, ok := c.Expression.(clause.Where)
+	return ok && len(where.Exprs) > 0
+}
+
+// hasPrimaryKeyValue 模型的主键是否有值，GORM会据此生成 WHERE 条件（例如 db.Delete(&user)）
+func hasPrimaryKeyValue(stmt *gorm.Statement) bool {
+	if stmt.Schema == nil || len(stmt.Schema.PrimaryFields) == 0 || !stmt.ReflectValue.IsValid() {
+		return false
+	}
+	hasKey := func(rv reflect.Value) bool {
+		rv = reflect.Indirect(rv)
+		if rv.Kind() != reflect.Struct {
+			return false
+		}
+		for _, field := range stmt.Schema.PrimaryFields {
+			if _, zero := field.ValueOf(stmt.Context, rv); !zero {
+				return true
+			}
+		}
+		return false
+	}
+
+	rv := reflect.Indirect(stmt.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Struct:
+		return hasKey(rv)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if hasKey(rv.Index(i)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var (
+	sqlWhereRe     = regexp.MustCompile(`(?i)\bWHERE\b`)
+	sqlTrivialRe   = regexp.MustCompile(`(?is)\bWHERE\s+(1|TRUE|1\s*=\s*1)\s*$`)
+	sqlModifyRe    = regexp.MustCompile(`(?i)\b(UPDATE|DELETE)\b`)
+	sqlLimitRe     = regexp.MustCompile(`(?i)\b(LIMIT|FETCH\s+FIRST|TOP)\b`)
+	sqlLikeLitRe   = regexp.MustCompile(`(?i)\bLIKE\s+'%`)
+	sqlLikeParamRe = regexp.MustCompile(`(?i)\bLIKE\s+(\?|\$\d+|@p\d+)`)
+)
+
+// isGlobalUpdateDelete 保守地判断原生SQL中是否有没有 WHERE 条件的 UPDATE/DELETE
+//
+// 去掉字符串字面量和注释后按分号拆分语句，以 UPDATE/DELETE 开头
+// （或 WITH 开头且包含 UPDATE/DELETE）的语句必须包含 WHERE，且条件不能是 1、TRUE、1=1。
+func isGlobalUpdateDelete(sql string) bool {
+	for _, stmt := range strings.Split(stripSQLLiterals(sql), ";") {
+		fields := strings.Fields(stmt)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "UPDATE", "DELETE":
+		case "WITH":
+			if !sqlModifyRe.MatchString(stmt) {
+				continue
+			}
+		default:
+			continue
+		}
+		if !sqlWhereRe.MatchString(stmt) || sqlTrivialRe.MatchString(stmt) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripSQLLiterals 将字符串字面量、引号标识符和注释替换为空格，避免其中的关键字和分号干扰判断
+func stripSQLLiterals(sql string) string {
+	var b strings.Builder
+	b.Grow(len(sql))
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			// 跳到匹配的引号，支持反斜杠转义和重复引号
+			for i++; i < len(sql); i++ {
+				if sql[i] == '\\' {
+					i++
+					continue
+				}
+				if sql[i] == c {
+					if i+1 < len(sql) && sql[i+1] == c {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			b.WriteByte(' ')
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+			b.WriteByte(' ')
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 3
+			}
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// warnQuery 查询执行后检查前导通配符的 LIKE 和返回行数，只记录警告
+func warnQuery(db *gorm.DB, cfg GuardrailsConfig) {
+	if db.Error != nil || guardrailAllowed(db) {
+		return
+	}
+	stmt := db.Statement
+	sql := stmt.SQL.String()
+
+	if cfg.RequireLimitOnUnindexedLikeQueries && hasLeadingWildcardLike(sql, stmt.Vars) &&
+		!sqlLimitRe.MatchString(stripSQLLiterals(sql)) {
+		db.Logger.Warn(stmt.Context, "查询护栏 [%s]: 前导通配符的 LIKE 无法使用索引，查询应带 LIMIT: %s",
+			GuardrailUnindexedLike, sql)
+	}
+	if cfg.MaxRowsWarning > 0 && db.RowsAffected > cfg.MaxRowsWarning {
+		db.Logger.Warn(stmt.Context, "查询护栏 [%s]: 单次查询返回 %d 行，超过 %d: %s",
+			GuardrailMaxRows, db.RowsAffected, cfg.MaxRowsWarning, sql)
+	}
+}
+
+// hasLeadingWildcardLike 是否有以 % 开头的 LIKE 模式（字面量或绑定参数）
+func hasLeadingWildcardLike(sql string, vars []interface{}) bool {
+	if sqlLikeLitRe.MatchString(sql) {
+		return true
+	}
+	if !sqlLikeParamRe.MatchString(sql) {
+		return false
+	}
+	for _, v := range vars {
+		if s, ok := v.(string); ok && strings.HasPrefix(s, "%") {
+			return true
+		}
+	}
+	return false
+}
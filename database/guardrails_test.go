@@ -0,0 +1,175 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func guardrailsDatabase(t *testing.T, guardrails GuardrailsConfig, logs *messageLogger) *Database {
+	t.Helper()
+	db := newFileTestDatabase(t, func(config *Config) {
+		config.Guardrails = guardrails
+		if logs != nil {
+			config.SetCustomLogger(logs, "warn")
+		}
+	})
+
+	if err := db.AutoMigrate(&TestUser{}); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	users := []TestUser{
+		{Name: "alice", Email: "alice@example.com"},
+		{Name: "bob", Email: "bob@example.com"},
+		{Name: "carol", Email: "carol@example.com"},
+	}
+	if err := db.GetDB().Create(&users).Error; err != nil {
+		t.Fatalf("插入数据失败: %v", err)
+	}
+	return db
+}
+
+func assertGuardrailViolation(t *testing.T, err error) {
+	t.Helper()
+	if !errors.Is(err, ErrGlobalUpdateDelete) {
+		t.Fatalf("期望 ErrGlobalUpdateDelete, 实际 %v", err)
+	}
+	if !IsValidationError(err) {
+		t.Errorf("护栏错误应为 ErrorTypeValidation: %v", err)
+	}
+	if name, ok := IsGuardrailViolation(err); !ok || name != GuardrailGlobalUpdateDelete {
+		t.Errorf("期望护栏名称 %s, 实际 %q", GuardrailGlobalUpdateDelete, name)
+	}
+}
+
+func countUsers(t *testing.T, db *Database) int64 {
+	t.Helper()
+	var n int64
+	if err := db.GetDB().Model(&TestUser{}).Count(&n).Error; err != nil {
+		t.Fatalf("统计失败: %v", err)
+	}
+	return n
+}
+
+func TestGuardrails_ForbidGlobalUpdateDelete(t *testing.T) {
+	db := guardrailsDatabase(t, GuardrailsConfig{ForbidGlobalUpdateDelete: true}, nil)
+	gdb := db.GetDB()
+
+	t.Run("raw exec", func(t *testing.T) {
+		for _, sql := range []string{
+			"DELETE FROM test_users",
+			"  delete from test_users -- WHERE id = 1",
+			"UPDATE test_users SET name = 'where'",
+			"DELETE FROM test_users WHERE 1=1",
+			"SELECT 1; DELETE FROM test_users",
+		} {
+			assertGuardrailViolation(t, gdb.Exec(sql).Error)
+		}
+		if n := countUsers(t, db); n != 3 {
+			t.Errorf("被拒绝的语句不应执行, 剩余 %d 行", n)
+		}
+	})
+
+	t.Run("session bypass", func(t *testing.T) {
+		// GORM 的 AllowGlobalUpdate 不能绕过护栏
+		err := gdb.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&TestUser{}).Error
+		assertGuardrailViolation(t, err)
+		err = gdb.Session(&gorm.Session{AllowGlobalUpdate: true}).Model(&TestUser{}).Update("age", 1).Error
+		assertGuardrailViolation(t, err)
+	})
+
+	t.Run("scoped operations", func(t *testing.T) {
+		if err := gdb.Exec("UPDATE test_users SET age = ? WHERE name = ?", 30, "alice").Error; err != nil {
+			t.Errorf("带 WHERE 的原生SQL应允许: %v", err)
+		}
+		if err := gdb.Model(&TestUser{}).Where("name = ?", "bob").Update("age", 20).Error; err != nil {
+			t.Errorf("带 WHERE 的更新应允许: %v", err)
+		}
+		var carol TestUser
+		gdb.Where("name = ?", "carol").First(&carol)
+		if err := gdb.Delete(&carol).Error; err != nil {
+			t.Errorf("按主键删除应允许: %v", err)
+		}
+		if n := countUsers(t, db); n != 2 {
+			t.Errorf("期望剩余 2 行, 实际 %d", n)
+		}
+	})
+
+	t.Run("escape hatch", func(t *testing.T) {
+		if err := gdb.Set(AllowGlobalKey, true).Exec("UPDATE test_users SET age = 0").Error; err != nil {
+			t.Errorf("AllowGlobalKey 应允许全表更新: %v", err)
+		}
+		err := gdb.Set(AllowGlobalKey, true).Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&TestUser{}).Error
+		if err != nil {
+			t.Errorf("AllowGlobalKey 应允许全表删除: %v", err)
+		}
+		if n := countUsers(t, db); n != 0 {
+			t.Errorf("期望全部删除, 剩余 %d 行", n)
+		}
+	})
+}
+
+func TestGuardrails_Warnings(t *testing.T) {
+	logs := &messageLogger{}
+	db := guardrailsDatabase(t, GuardrailsConfig{RequireLimitOnUnindexedLikeQueries: true, MaxRowsWarning: 2}, logs)
+	gdb := db.GetDB()
+
+	warnings := func() []string {
+		logs.mu.Lock()
+		defer logs.mu.Unlock()
+		result := logs.warnings
+		logs.warnings = nil
+		return result
+	}
+
+	var users []TestUser
+	gdb.Where("name = ?", "alice").Find(&users)
+	if got := warnings(); len(got) != 0 {
+		t.Errorf("普通查询不应产生警告: %v", got)
+	}
+
+	gdb.Find(&users)
+	if got := warnings(); len(got) != 1 || !strings.Contains(got[0], GuardrailMaxRows) {
+		t.Errorf("期望返回行数警告, 实际 %v", got)
+	}
+
+	gdb.Where("email LIKE ?", "%@example.com").Limit(1).Find(&users)
+	if got := warnings(); len(got) != 0 {
+		t.Errorf("带 LIMIT 的 LIKE 查询不应产生警告: %v", got)
+	}
+	gdb.Where("email LIKE ?", "%bob%").Find(&users)
+	if got := warnings(); len(got) != 1 || !strings.Contains(got[0], GuardrailUnindexedLike) {
+		t.Errorf("期望 LIKE 警告, 实际 %v", got)
+	}
+	gdb.Where("email LIKE ?", "bob%").Find(&users)
+	if got := warnings(); len(got) != 0 {
+		t.Errorf("前缀匹配可以使用索引，不应产生警告: %v", got)
+	}
+
+	// 护栏只警告，不影响查询结果
+	if len(users) != 1 {
+		t.Errorf("期望查询到 1 行, 实际 %d", len(users))
+	}
+}
+
+func TestIsGlobalUpdateDelete(t *testing.T) {
+	tests := map[string]bool{
+		"DELETE FROM t":                          true,
+		"update t set a = 1":                     true,
+		"UPDATE t SET a = 1 WHERE TRUE":          true,
+		"DELETE FROM t WHERE id = 1":             false,
+		"DELETE FROM t /* no where */ WHERE a=1": false,
+		"SELECT * FROM t":                        false,
+		"INSERT INTO t (a) VALUES ('delete')":    false,
+		"WITH x AS (SELECT 1) DELETE FROM t":     true,
+		"UPDATE t SET a = 'x;DELETE FROM t'":     true,
+		"UPDATE t SET a = 'x;' WHERE id = 2":     false,
+	}
+	for sql, want := range tests {
+		if got := isGlobalUpdateDelete(sql); got != want {
+			t.Errorf("isGlobalUpdateDelete(%q) = %v, 期望 %v", sql, got, want)
+		}
+	}
+}
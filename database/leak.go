@@ -0,0 +1,318 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	// DefaultLeakStackDepth 泄漏检测记录的默认调用栈深度
+	DefaultLeakStackDepth = 16
+	// maxLeakStackDepth 调用栈深度上限，避免在每个事务上做过多的栈展开
+	maxLeakStackDepth = 64
+
+	// LeakKindTransaction Transaction/TransactionWithContext/TransactionCtx 开启的事务
+	LeakKindTransaction = "transaction"
+	// LeakKindSession BeginTracked 开启的会话
+	LeakKindSession = "session"
+)
+
+// LeakDetectionConfig 长事务与连接泄漏检测配置，Threshold 为0时关闭，不产生任何开销
+type LeakDetectionConfig struct {
+	// Threshold 事务或会话打开超过该时长时记录警告（每个事务只报告一次）
+	Threshold time.Duration `mapstructure:"threshold" json:"threshold" yaml:"threshold"`
+	// RollbackAfter 打开超过该时长时强制回滚，0表示不回滚；应大于 Threshold
+	RollbackAfter time.Duration `mapstructure:"rollback_after" json:"rollback_after" yaml:"rollback_after"`
+	// SweepInterval 后台检查的间隔，默认 Threshold 的一半
+	SweepInterval time.Duration `mapstructure:"sweep_interval" json:"sweep_interval" yaml:"sweep_interval"`
+	// StackDepth 记录的调用栈深度，默认 DefaultLeakStackDepth，最大64
+	StackDepth int `mapstructure:"stack_depth" json:"stack_depth" yaml:"stack_depth"`
+	// OnLeak 报告泄漏时调用（超过 Threshold 和强制回滚时各一次），可用于指标和告警
+	OnLeak func(LeakReport) `mapstructure:"-" json:"-" yaml:"-"`
+}
+
+// enabled 是否启用了泄漏检测
+func (c LeakDetectionConfig) enabled() bool {
+	return c.Threshold > 0
+}
+
+// withDefaults 填充默认值
+func (c LeakDetectionConfig) withDefaults() LeakDetectionConfig {
+	if c.SweepInterval <= 0 {
+		c.SweepInterval = c.Threshold / 2
+		if c.SweepInterval <= 0 {
+			c.SweepInterval = c.Threshold
+		}
+	}
+	if c.StackDepth <= 0 {
+		c.StackDepth = DefaultLeakStackDepth
+	}
+	if c.StackDepth > maxLeakStackDepth {
+		c.StackDepth = maxLeakStackDepth
+	}
+	return c
+}
+
+// LeakReport 一次泄漏报告
+type LeakReport struct {
+	Kind       string        // LeakKindTransaction 或 LeakKindSession
+	StartedAt  time.Time     // 开启时间
+	Elapsed    time.Duration // 报告时已打开的时长
+	Stack      string        // 开启位置的调用栈，每帧一行 "函数\n\t文件:行号"
+	RolledBack bool          // 本次报告是否为强制回滚
+	Err        error         // 强制回滚失败时的错误
+}
+
+// leakEntry 一个打开中的事务或会话
+type leakEntry struct {
+	kind       string
+	startedAt  time.Time
+	pcs        []uintptr
+	rollback   func() error
+	warned     bool
+	rolledBack bool
+}
+
+// leakTracker 记录打开中的事务，由后台协程定期检查
+type leakTracker struct {
+	config LeakDetectionConfig
+	logger logger.Interface
+
+	mu   sync.Mutex
+	open map[*leakEntry]struct{}
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// newLeakTracker 创建并启动泄漏检测，未启用时返回nil
+func newLeakTracker(config LeakDetectionConfig, log logger.Interface) *leakTracker {
+	if !config.enabled() {
+		return nil
+	}
+	t := &leakTracker{
+		config: config.withDefaults(),
+		logger: log,
+		open:   make(map[*leakEntry]struct{}),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// callers 记录调用方的栈，skip 为0时第一帧是 callers 的调用方
+func (t *leakTracker) callers(skip int) []uintptr {
+	var buf [maxLeakStackDepth]uintptr
+	n := runtime.Callers(skip+2, buf[:t.config.StackDepth])
+	pcs := make([]uintptr, n)
+	copy(pcs, buf[:n])
+	return pcs
+}
+
+// add 登记一个打开的事务，rollback 为nil时不会被强制回滚
+func (t *leakTracker) add(kind string, pcs []uintptr, rollback func() error) *leakEntry {
+	entry := &leakEntry{kind: kind, startedAt: time.Now(), pcs: pcs, rollback: rollback}
+	t.mu.Lock()
+	t.open[entry] = struct{}{}
+	t.mu.Unlock()
+	return entry
+}
+
+// remove 事务结束时注销
+func (t *leakTracker) remove(entry *leakEntry) {
+	t.mu.Lock()
+	delete(t.open, entry)
+	t.mu.Unlock()
+}
+
+// transaction 在 db 上执行 db.Transaction(fn)，并登记事务直到 fn 返回
+func (t *leakTracker) transaction(db *gorm.DB, pcs []uintptr, fn func(*gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		entry := t.add(LeakKindTransaction, pcs, txRollback(tx))
+		defer t.remove(entry)
+		return fn(tx)
+	})
+}
+
+// txRollback 直接回滚底层的 sql.Tx，用于强制回滚（事务仍被其他协程持有）
+func txRollback(tx *gorm.DB) func() error {
+	committer, ok := tx.Statement.ConnPool.(gorm.TxCommitter)
+	if !ok {
+		return nil
+	}
+	return committer.Rollback
+}
+
+func (t *leakTracker) run() {
+	defer close(t.done)
+	ticker := time.NewTicker(t.config.SweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.stop:
+			return
+		case now := <-ticker.C:
+			t.sweep(now)
+		}
+	}
+}
+
+// sweep 报告超过 Threshold 的事务，并回滚超过 RollbackAfter 的事务
+func (t *leakTracker) sweep(now time.Time) {
+	type pending struct {
+		entry    *leakEntry
+		rollback bool
+	}
+	var found []pending
+
+	t.mu.Lock()
+	for entry := range t.open {
+		elapsed := now.Sub(entry.startedAt)
+		if !entry.warned && elapsed >= t.config.Threshold {
+			entry.warned = true
+			found = append(found, pending{entry: entry})
+		}
+		if t.config.RollbackAfter > 0 && !entry.rolledBack && entry.rollback != nil && elapsed >= t.config.RollbackAfter {
+			entry.rolledBack = true
+			found = append(found, pending{entry: entry, rollback: true})
+		}
+	}
+	t.mu.Unlock()
+
+	for _, p := range found {
+		report := LeakReport{
+			Kind:      p.entry.kind,
+			StartedAt: p.entry.startedAt,
+			Elapsed:   now.Sub(p.entry.startedAt),
+			Stack:     formatStack(p.entry.pcs),
+		}
+		if p.rollback {
+			report.RolledBack = true
+			report.Err = p.entry.rollback()
+		}
+		t.report(report)
+	}
+}
+
+func (t *leakTracker) report(r LeakReport) {
+	ctx := context.Background()
+	switch {
+	case r.RolledBack && r.Err != nil:
+		t.logger.Warn(ctx, "泄漏检测: %s 已打开 %v，强制回滚失败: %v\n%s", r.Kind, r.Elapsed.Round(time.Millisecond), r.Err, r.Stack)
+	case r.RolledBack:
+		t.logger.Warn(ctx, "泄漏检测: %s 已打开 %v，已强制回滚\n%s", r.Kind, r.Elapsed.Round(time.Millisecond), r.Stack)
+	default:
+		t.logger.Warn(ctx, "泄漏检测: %s 已打开 %v，可能未提交或回滚\n%s", r.Kind, r.Elapsed.Round(time.Millisecond), r.Stack)
+	}
+	if t.config.OnLeak != nil {
+		t.config.OnLeak(r)
+	}
+}
+
+// close 停止后台检查，可重复调用
+func (t *leakTracker) close() {
+	t.stopOnce.Do(func() {
+		close(t.stop)
+		<-t.done
+	})
+}
+
+// formatStack 只在报告时解析调用栈，登记时仅保存PC
+func formatStack(pcs []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// TrackedSession BeginTracked 开启的事务，必须调用 Commit 或 Rollback 结束
+//
+// 嵌入的 *gorm.DB 用于执行查询；结束事务请调用 TrackedSession 自身的 Commit/Rollback，
+// 它们会同时注销泄漏检测的登记。
+type TrackedSession struct {
+	*gorm.DB
+	tracker *leakTracker
+	entry   *leakEntry
+	once    sync.Once
+}
+
+// BeginTracked 开启事务，启用泄漏检测时登记开启位置的调用栈
+//
+// 适用于无法使用 Transaction 回调的场景（事务跨越多个函数）。开启失败时错误保存在 DB.Error 中。
+//
+// 示例:
+//
+//	session := db.BeginTracked(ctx)
+//	if session.Error != nil {
+//	    return session.Error
+//	}
+//	defer session.Rollback() // 已提交时为空操作
+//	if err := session.Create(&order).Error; err != nil {
+//	    return err
+//	}
+//	return session.Commit()
+func (d *Database) BeginTracked(ctx context.Context, opts ...*sql.TxOptions) *TrackedSession {
+	d.mu.RLock()
+	base := d.db
+	d.mu.RUnlock()
+
+	if d.closing.Load() {
+		db := base.WithContext(ctx)
+		db.AddError(ErrDatabaseClosing)
+		return &TrackedSession{DB: db}
+	}
+
+	tx := base.WithContext(ctx).Begin(opts...)
+	session := &TrackedSession{DB: tx, tracker: d.leaks}
+	if d.leaks != nil && tx.Error == nil {
+		session.entry = d.leaks.add(LeakKindSession, d.leaks.callers(1), txRollback(tx))
+	}
+	return session
+}
+
+// Commit 提交事务并注销登记
+func (s *TrackedSession) Commit() error {
+	s.untrack()
+	if s.DB.Error != nil {
+		return s.DB.Error
+	}
+	return s.DB.Commit().Error
+}
+
+// Rollback 回滚事务并注销登记，事务已结束时为空操作
+func (s *TrackedSession) Rollback() error {
+	s.untrack()
+	if s.DB.Error != nil {
+		return nil
+	}
+	err := s.DB.Rollback().Error
+	if errors.Is(err, sql.ErrTxDone) {
+		return nil
+	}
+	return err
+}
+
+func (s *TrackedSession) untrack() {
+	s.once.Do(func() {
+		if s.entry != nil {
+			s.tracker.remove(s.entry)
+		}
+	})
+}
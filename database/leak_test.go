@@ -0,0 +1,191 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// leakRecorder 收集 OnLeak 报告
+type leakRecorder struct {
+	mu      sync.Mutex
+	reports []LeakReport
+	fired   chan LeakReport
+}
+
+func newLeakRecorder() *leakRecorder {
+	return &leakRecorder{fired: make(chan LeakReport, 16)}
+}
+
+func (r *leakRecorder) record(report LeakReport) {
+	r.mu.Lock()
+	r.reports = append(r.reports, report)
+	r.mu.Unlock()
+	r.fired <- report
+}
+
+func (r *leakRecorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.reports)
+}
+
+func (r *leakRecorder) wait(t *testing.T) LeakReport {
+	t.Helper()
+	select {
+	case report := <-r.fired:
+		return report
+	case <-time.After(2 * time.Second):
+		t.Fatal("等待泄漏报告超时")
+		return LeakReport{}
+	}
+}
+
+func leakDatabase(t *testing.T, leak LeakDetectionConfig, logs *messageLogger) *Database {
+	t.Helper()
+	db := newFileTestDatabase(t, func(config *Config) {
+		config.LeakDetection = leak
+		if logs != nil {
+			config.SetCustomLogger(logs, "warn")
+		}
+	})
+
+	if err := db.AutoMigrate(&TestUser{}); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	return db
+}
+
+func TestLeakDetection_DisabledByDefault(t *testing.T) {
+	db := leakDatabase(t, LeakDetectionConfig{}, nil)
+	if db.leaks != nil {
+		t.Fatal("未配置 Threshold 时不应启用泄漏检测")
+	}
+	if err := db.Transaction(func(tx *gorm.DB) error { return nil }); err != nil {
+		t.Fatalf("事务失败: %v", err)
+	}
+	session := db.BeginTracked(context.Background())
+	if session.entry != nil {
+		t.Error("未启用时不应登记会话")
+	}
+	if err := session.Commit(); err != nil {
+		t.Fatalf("提交失败: %v", err)
+	}
+}
+
+func TestLeakDetection_HeldTransaction(t *testing.T) {
+	logs := &messageLogger{}
+	recorder := newLeakRecorder()
+	db := leakDatabase(t, LeakDetectionConfig{
+		Threshold:     50 * time.Millisecond,
+		SweepInterval: 10 * time.Millisecond,
+		OnLeak:        recorder.record,
+	}, logs)
+
+	var report LeakReport
+	err := db.Transaction(func(tx *gorm.DB) error {
+		report = recorder.wait(t)
+		return tx.Create(&TestUser{Name: "held", Email: "held@example.com"}).Error
+	})
+	if err != nil {
+		t.Fatalf("事务失败: %v", err)
+	}
+
+	if report.Kind != LeakKindTransaction || report.RolledBack || report.Elapsed < 50*time.Millisecond {
+		t.Errorf("报告不符合预期: %+v", report)
+	}
+	// 调用栈的第一帧是调用 Transaction 的测试函数
+	firstFrame := strings.SplitN(report.Stack, "\n", 3)
+	if !strings.HasSuffix(firstFrame[0], "TestLeakDetection_HeldTransaction") || !strings.Contains(firstFrame[1], "leak_test.go:") {
+		t.Errorf("调用栈应指向测试函数，实际为:\n%s", report.Stack)
+	}
+
+	logs.mu.Lock()
+	defer logs.mu.Unlock()
+	if len(logs.warnings) != 1 || !strings.Contains(logs.warnings[0], "泄漏检测: transaction") ||
+		!strings.Contains(logs.warnings[0], "leak_test.go:") {
+		t.Errorf("应记录一条包含调用栈的警告，实际为 %q", logs.warnings)
+	}
+}
+
+func TestLeakDetection_CommittedNeverReported(t *testing.T) {
+	recorder := newLeakRecorder()
+	db := leakDatabase(t, LeakDetectionConfig{
+		Threshold:     50 * time.Millisecond,
+		SweepInterval: 5 * time.Millisecond,
+		RollbackAfter: 100 * time.Millisecond,
+		OnLeak:        recorder.record,
+	}, nil)
+	ctx := context.Background()
+
+	for i := 0; i < 20; i++ {
+		if err := db.TransactionWithContext(ctx, func(tx *gorm.DB) error {
+			return tx.Model(&TestUser{}).Where("id = ?", i).Update("age", i).Error
+		}); err != nil {
+			t.Fatalf("事务失败: %v", err)
+		}
+		if err := db.TransactionCtx(ctx, func(ctx context.Context) error { return nil }); err != nil {
+			t.Fatalf("事务失败: %v", err)
+		}
+		session := db.BeginTracked(ctx)
+		if err := session.Commit(); err != nil {
+			t.Fatalf("提交失败: %v", err)
+		}
+		if err := db.BeginTracked(ctx).Rollback(); err != nil {
+			t.Fatalf("回滚失败: %v", err)
+		}
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if n := recorder.count(); n != 0 {
+		t.Errorf("已结束的事务不应被报告，实际报告 %d 次", n)
+	}
+	db.leaks.mu.Lock()
+	defer db.leaks.mu.Unlock()
+	if len(db.leaks.open) != 0 {
+		t.Errorf("登记应全部注销，剩余 %d 个", len(db.leaks.open))
+	}
+}
+
+func TestLeakDetection_ForceRollback(t *testing.T) {
+	recorder := newLeakRecorder()
+	db := leakDatabase(t, LeakDetectionConfig{
+		Threshold:     20 * time.Millisecond,
+		RollbackAfter: 60 * time.Millisecond,
+		SweepInterval: 5 * time.Millisecond,
+		OnLeak:        recorder.record,
+	}, nil)
+
+	session := db.BeginTracked(context.Background())
+	if session.Error != nil {
+		t.Fatalf("开启事务失败: %v", session.Error)
+	}
+	if err := session.Create(&TestUser{Name: "abandoned", Email: "abandoned@example.com"}).Error; err != nil {
+		t.Fatalf("插入失败: %v", err)
+	}
+
+	warned := recorder.wait(t)
+	if warned.Kind != LeakKindSession || warned.RolledBack {
+		t.Errorf("第一次报告应为警告，实际为 %+v", warned)
+	}
+	if !strings.Contains(warned.Stack, "TestLeakDetection_ForceRollback") {
+		t.Errorf("调用栈应指向测试函数，实际为:\n%s", warned.Stack)
+	}
+	rolledBack := recorder.wait(t)
+	if !rolledBack.RolledBack || rolledBack.Err != nil {
+		t.Errorf("第二次报告应为强制回滚，实际为 %+v", rolledBack)
+	}
+
+	if err := session.Commit(); err == nil {
+		t.Error("强制回滚后提交应失败")
+	}
+	var count int64
+	db.GetDB().Model(&TestUser{}).Count(&count)
+	if count != 0 {
+		t.Errorf("强制回滚后不应有数据，实际 %d 条", count)
+	}
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ErrPluginRegistered 插件已注册
+var ErrPluginRegistered = errors.New("插件已注册")
+
+// Use 在数据库实例上注册GORM插件
+//
+// 持有写锁执行，与 GetDB 等读操作互斥。同名插件已注册时返回 ErrPluginRegistered，
+// 插件初始化失败时返回 ErrorTypePlugin 类型的 *DatabaseError。
+// 需要在连接池配置前生效的插件（如 dbresolver）应通过 Config.Plugins 注册。
+func (d *Database) Use(plugin gorm.Plugin) error {
+	if plugin == nil {
+		return NewDatabaseError(ErrorTypeValidation, "注册插件", errors.New("插件不能为空"))
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return usePlugin(d.db, plugin)
+}
+
+// Callback 持有写锁执行 fn，用于直接调整GORM回调链
+//
+// 注意: 这是一个锋利的接口，回调的注册、替换和删除会影响所有使用该实例的查询，
+// 错误的回调顺序可能破坏软删除、关联保存等内置行为。仅在启动阶段使用，优先考虑 Use 注册插件。
+//
+// 示例:
+//
+//	err := db.Callback(func(gdb *gorm.DB) error {
+//	    return gdb.Callback().Create().Before("gorm:create").Register("app:set_tenant", setTenant)
+//	})
+func (d *Database) Callback(fn func(db *gorm.DB) error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := fn(d.db); err != nil {
+		return NewDatabaseError(ErrorTypePlugin, "注册回调", err)
+	}
+	return nil
+}
+
+// applyPlugins 注册 Config.Plugins 中的插件
+func (d *Database) applyPlugins(plugins []gorm.Plugin) error {
+	for _, plugin := range plugins {
+		if plugin == nil {
+			continue
+		}
+		if err := usePlugin(d.db, plugin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// usePlugin 校验插件未重复注册后调用 gorm.DB.Use（调用方需持有写锁或独占 db）
+func usePlugin(db *gorm.DB, plugin gorm.Plugin) error {
+	name := plugin.Name()
+	if _, ok := db.Config.Plugins[name]; ok {
+		return NewDatabaseError(ErrorTypePlugin, "注册插件", fmt.Errorf("%w: %s", ErrPluginRegistered, name)).
+			WithContext("plugin", name)
+	}
+	if err := db.Use(plugin); err != nil {
+		return NewDatabaseError(ErrorTypePlugin, "注册插件", err).WithContext("plugin", name)
+	}
+	return nil
+}
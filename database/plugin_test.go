@@ -0,0 +1,209 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tsopia/go-kit/constants"
+	"gorm.io/gorm"
+)
+
+// recordingPlugin 记录 Initialize 调用的测试插件
+type recordingPlugin struct {
+	name    string
+	err     error
+	calls   int
+	db      *gorm.DB
+	created int
+}
+
+func (p *recordingPlugin) Name() string { return p.name }
+
+func (p *recordingPlugin) Initialize(db *gorm.DB) error {
+	p.calls++
+	p.db = db
+	if p.err != nil {
+		return p.err
+	}
+	return db.Callback().Create().Before("gorm:create").Register(p.name+":count", func(*gorm.DB) {
+		p.created++
+	})
+}
+
+type pluginModel struct {
+	ID   uint
+	Name string
+}
+
+func newPluginTestConfig(plugins ...gorm.Plugin) *Config {
+	return &Config{
+		Driver:   "sqlite",
+		Database: ":memory:",
+		LogLevel: "silent",
+		Plugins:  plugins,
+	}
+}
+
+func TestConfigPlugins(t *testing.T) {
+	plugin := &recordingPlugin{name: "recorder"}
+	db, err := New(newPluginTestConfig(plugin))
+	if err != nil {
+		t.Fatalf("创建数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	if plugin.calls != 1 {
+		t.Fatalf("期望 Initialize 调用 1 次, 实际 %d", plugin.calls)
+	}
+	if err := db.AutoMigrate(&pluginModel{}); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	if err := db.GetDB().Create(&pluginModel{Name: "a"}).Error; err != nil {
+		t.Fatalf("创建记录失败: %v", err)
+	}
+	if plugin.created != 1 {
+		t.Errorf("期望插件回调执行 1 次, 实际 %d", plugin.created)
+	}
+}
+
+func TestUseDuplicatePlugin(t *testing.T) {
+	db, err := New(newPluginTestConfig(&recordingPlugin{name: "recorder"}))
+	if err != nil {
+		t.Fatalf("创建数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	second := &recordingPlugin{name: "recorder"}
+	err = db.Use(second)
+	if !errors.Is(err, ErrPluginRegistered) {
+		t.Fatalf("期望 ErrPluginRegistered, 实际 %v", err)
+	}
+	var dbErr *DatabaseError
+	if !errors.As(err, &dbErr) || dbErr.Type != ErrorTypePlugin {
+		t.Errorf("期望 ErrorTypePlugin 类型的 DatabaseError, 实际 %v", err)
+	}
+	if second.calls != 0 {
+		t.Errorf("重复注册不应调用 Initialize, 实际 %d", second.calls)
+	}
+
+	other := &recordingPlugin{name: "other"}
+	if err := db.Use(other); err != nil || other.calls != 1 {
+		t.Errorf("注册新插件失败: %v (calls=%d)", err, other.calls)
+	}
+	if err := db.Use(nil); err == nil {
+		t.Error("期望 nil 插件返回错误")
+	}
+}
+
+func TestUsePluginInitializeError(t *testing.T) {
+	db, err := New(newPluginTestConfig())
+	if err != nil {
+		t.Fatalf("创建数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	initErr := errors.New("init failed")
+	err = db.Use(&recordingPlugin{name: "broken", err: initErr})
+	var dbErr *DatabaseError
+	if !errors.As(err, &dbErr) || dbErr.Type != ErrorTypePlugin || !errors.Is(err, initErr) {
+		t.Errorf("期望包装初始化错误, 实际 %v", err)
+	}
+}
+
+func TestConfigPluginFailureClosesConnection(t *testing.T) {
+	initErr := errors.New("init failed")
+	plugin := &recordingPlugin{name: "broken", err: initErr}
+
+	db, err := New(newPluginTestConfig(plugin))
+	if err == nil {
+		db.Close()
+		t.Fatal("期望插件初始化失败时 New 返回错误")
+	}
+	if !errors.Is(err, initErr) {
+		t.Errorf("期望错误链包含初始化错误, 实际 %v", err)
+	}
+
+	sqlDB, err := plugin.db.DB()
+	if err != nil {
+		t.Fatalf("获取连接失败: %v", err)
+	}
+	if err := sqlDB.Ping(); err == nil {
+		t.Error("期望插件初始化失败后连接已关闭")
+	}
+}
+
+func TestCallback(t *testing.T) {
+	db, err := New(newPluginTestConfig())
+	if err != nil {
+		t.Fatalf("创建数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	queries := 0
+	err = db.Callback(func(gdb *gorm.DB) error {
+		return gdb.Callback().Query().After("gorm:query").Register("test:count", func(*gorm.DB) {
+			queries++
+		})
+	})
+	if err != nil {
+		t.Fatalf("注册回调失败: %v", err)
+	}
+	if err := db.AutoMigrate(&pluginModel{}); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	var models []pluginModel
+	db.GetDB().Find(&models)
+	if queries != 1 {
+		t.Errorf("期望查询回调执行 1 次, 实际 %d", queries)
+	}
+
+	callbackErr := errors.New("bad callback")
+	err = db.Callback(func(*gorm.DB) error { return callbackErr })
+	if !errors.Is(err, callbackErr) {
+		t.Errorf("期望返回回调错误, 实际 %v", err)
+	}
+}
+
+// tracingPlugin 记录查询回调中的 trace_id，模拟 OpenTelemetry 等追踪插件
+type tracingPlugin struct {
+	traceIDs []string
+}
+
+func (p *tracingPlugin) Name() string { return "tracing" }
+
+func (p *tracingPlugin) Initialize(db *gorm.DB) error {
+	return db.Callback().Query().After("gorm:query").Register("tracing:after_query", func(tx *gorm.DB) {
+		p.traceIDs = append(p.traceIDs, constants.TraceIDFromContext(tx.Statement.Context))
+	})
+}
+
+func TestPluginCallbackSeesRequestContext(t *testing.T) {
+	plugin := &tracingPlugin{}
+	db, err := New(newPluginTestConfig())
+	if err != nil {
+		t.Fatalf("创建数据库失败: %v", err)
+	}
+	defer db.Close()
+	if err := db.Use(plugin); err != nil {
+		t.Fatalf("注册插件失败: %v", err)
+	}
+	if err := db.AutoMigrate(&pluginModel{}); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+
+	ctx := constants.WithTraceID(context.Background(), "trace-123")
+	var models []pluginModel
+	if err := db.WithContext(ctx).Find(&models).Error; err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.WithContext(ctx).Find(&models).Error
+	}); err != nil {
+		t.Fatalf("事务查询失败: %v", err)
+	}
+
+	if len(plugin.traceIDs) != 2 || plugin.traceIDs[0] != "trace-123" || plugin.traceIDs[1] != "trace-123" {
+		t.Errorf("期望插件回调拿到请求的 trace_id，实际 %v", plugin.traceIDs)
+	}
+}
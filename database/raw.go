@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// QueryInfo 原生SQL的执行信息
+type QueryInfo struct {
+	SQL          string
+	Args         []interface{}
+	Elapsed      time.Duration
+	RowsAffected int64 // 仅 ExecCtx 有效
+	Err          error
+}
+
+// SlowQueryFunc 慢查询回调，ctx 为执行SQL时传入的上下文（可从中获取追踪信息）
+type SlowQueryFunc func(ctx context.Context, info QueryInfo)
+
+// RawCtx 执行原生查询并返回结果集
+//
+// 与 GetDB().Raw(...) 不同，查询通过 WithContext(ctx) 执行，会记录耗时，
+// 超过 SlowThreshold 时调用 Config.OnSlowQuery，错误经过 WrapError 转换。
+// 耗时统计到数据库返回结果集为止，不包含调用方读取行的时间。调用方负责关闭 rows。
+//
+// 示例:
+//
+//	rows, err := db.RawCtx(ctx, "SELECT id, name FROM users WHERE age > ?", 18)
+//	if err != nil {
+//	    return err
+//	}
+//	defer rows.Close()
+func (d *Database) RawCtx(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := d.WithContext(ctx).Raw(query, args...).Rows()
+	err = WrapError("raw", err)
+	d.observe(ctx, QueryInfo{SQL: query, Args: args, Elapsed: time.Since(start), Err: err})
+	return rows, err
+}
+
+// ExecCtx 执行原生语句，返回受影响的行数
+// 计时、慢查询回调和错误转换与 RawCtx 相同
+func (d *Database) ExecCtx(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	start := time.Now()
+	result := d.WithContext(ctx).Exec(query, args...)
+	err := WrapError("exec", result.Error)
+	d.observe(ctx, QueryInfo{
+		SQL:          query,
+		Args:         args,
+		Elapsed:      time.Since(start),
+		RowsAffected: result.RowsAffected,
+		Err:          err,
+	})
+	return result.RowsAffected, err
+}
+
+// observe 执行时间达到慢查询阈值时调用慢查询回调
+func (d *Database) observe(ctx context.Context, info QueryInfo) {
+	d.mu.RLock()
+	threshold, onSlow := d.config.SlowThreshold, d.config.OnSlowQuery
+	d.mu.RUnlock()
+
+	if onSlow != nil && threshold > 0 && info.Elapsed >= threshold {
+		onSlow(ctx, info)
+	}
+}
+
+// WrapError 将GORM和database/sql返回的错误转换为 *DatabaseError
+//
+// 连接类错误（连接已关闭、驱动报告连接失效）归为 ErrorTypeConnection，
+// 事务状态错误归为 ErrorTypeTransaction，其余归为 ErrorTypeQuery。
+// 原始错误保留在错误链中，errors.Is(err, gorm.ErrRecordNotFound) 等判断仍然有效。
+// err 为 nil 或已经是 *DatabaseError 时原样返回。
+func WrapError(operation string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var dbErr *DatabaseError
+	if errors.As(err, &dbErr) {
+		return err
+	}
+
+	errorType := ErrorTypeQuery
+	switch {
+	case errors.Is(err, sql.ErrConnDone), errors.Is(err, driver.ErrBadConn):
+		errorType = ErrorTypeConnection
+	case errors.Is(err, sql.ErrTxDone), errors.Is(err, gorm.ErrInvalidTransaction):
+		errorType = ErrorTypeTransaction
+	}
+	return NewDatabaseError(errorType, operation, err)
+}
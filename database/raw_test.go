@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type traceKey struct{}
+
+func TestRawCtxSlowQuery(t *testing.T) {
+	db := seededBatchDatabase(t, 10)
+
+	var calls []QueryInfo
+	var traceID interface{}
+	db.config.SlowThreshold = time.Nanosecond
+	db.config.OnSlowQuery = func(ctx context.Context, info QueryInfo) {
+		traceID = ctx.Value(traceKey{})
+		calls = append(calls, info)
+	}
+
+	ctx := context.WithValue(context.Background(), traceKey{}, "trace-1")
+	// 递归CTE使查询耗时可测量
+	query := "WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < ?) SELECT count(*) FROM n"
+	rows, err := db.RawCtx(ctx, query, 100000)
+	if err != nil {
+		t.Fatalf("RawCtx 失败: %v", err)
+	}
+	var count int
+	for rows.Next() {
+		if err := rows.Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows.Close()
+
+	if count != 100000 {
+		t.Errorf("期望计数 100000, 实际 %d", count)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("期望慢查询回调1次, 实际 %d", len(calls))
+	}
+	if calls[0].SQL != query || len(calls[0].Args) != 1 {
+		t.Errorf("回调中的SQL或参数不正确: %+v", calls[0])
+	}
+	if calls[0].Elapsed <= 0 {
+		t.Errorf("期望记录耗时, 实际 %v", calls[0].Elapsed)
+	}
+	if traceID != "trace-1" {
+		t.Errorf("期望回调收到调用方的上下文, 实际 %v", traceID)
+	}
+}
+
+func TestExecCtx(t *testing.T) {
+	db := seededBatchDatabase(t, 10)
+
+	var calls []QueryInfo
+	db.config.SlowThreshold = time.Hour
+	db.config.OnSlowQuery = func(ctx context.Context, info QueryInfo) {
+		calls = append(calls, info)
+	}
+
+	affected, err := db.ExecCtx(context.Background(), "UPDATE test_users SET age = age + 1 WHERE age <= ?", 3)
+	if err != nil {
+		t.Fatalf("ExecCtx 失败: %v", err)
+	}
+	if affected != 3 {
+		t.Errorf("期望影响3行, 实际 %d", affected)
+	}
+	if len(calls) != 0 {
+		t.Errorf("未超过阈值时不应调用慢查询回调, 实际 %d 次", len(calls))
+	}
+
+	db.config.SlowThreshold = time.Nanosecond
+	_, err = db.ExecCtx(context.Background(), "UPDATE missing_table SET x = 1")
+	var dbErr *DatabaseError
+	if !errors.As(err, &dbErr) || dbErr.Type != ErrorTypeQuery || dbErr.Operation != "exec" {
+		t.Fatalf("期望查询类型的 DatabaseError, 实际 %v", err)
+	}
+	if len(calls) != 1 || calls[0].Err == nil {
+		t.Errorf("期望失败的语句也触发回调并带有错误, 实际 %+v", calls)
+	}
+}
+
+func TestRawCtxCanceled(t *testing.T) {
+	db := seededBatchDatabase(t, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := db.RawCtx(ctx, "SELECT * FROM test_users")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("期望 context.Canceled, 实际 %v", err)
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	existing := NewDatabaseError(ErrorTypeMigration, "migrate", errors.New("boom"))
+
+	tests := []struct {
+		name string
+		err  error
+		want ErrorType
+	}{
+		{"conn done", sql.ErrConnDone, ErrorTypeConnection},
+		{"bad conn", fmt.Errorf("driver: %w", driver.ErrBadConn), ErrorTypeConnection},
+		{"tx done", sql.ErrTxDone, ErrorTypeTransaction},
+		{"invalid transaction", gorm.ErrInvalidTransaction, ErrorTypeTransaction},
+		{"not found", gorm.ErrRecordNotFound, ErrorTypeQuery},
+		{"existing", existing, ErrorTypeMigration},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := WrapError("query", tt.err)
+			var dbErr *DatabaseError
+			if !errors.As(err, &dbErr) {
+				t.Fatalf("期望 *DatabaseError, 实际 %T", err)
+			}
+			if dbErr.Type != tt.want {
+				t.Errorf("期望类型 %v, 实际 %v", tt.want, dbErr.Type)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Error("原始错误应保留在错误链中")
+			}
+		})
+	}
+
+	if WrapError("query", nil) != nil {
+		t.Error("nil 错误应返回 nil")
+	}
+}
@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	kiterrors "github.com/tsopia/go-kit/errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrMissingPrimaryKey 模型没有主键，无法按ID查询或删除
+var ErrMissingPrimaryKey = errors.New("模型没有主键")
+
+// Repo 模型 T 的泛型仓储，提供带 context 的常用增删改查
+//
+// 所有方法通过 SessionFromContext 执行：随 ctx 取消、应用 DefaultQueryTimeout、
+// 加入 TransactionCtx 开启的事务。错误统一转换：
+//   - 记录不存在时返回错误码为 errors.CodeRecordNotFound 的 *errors.Error，
+//     errors.Is(err, gorm.ErrRecordNotFound) 仍然成立
+//   - 其他错误经过 WrapError 转换为 *DatabaseError
+//
+// 模型包含 gorm.DeletedAt 字段时，Get、List、Count 自动排除已删除记录，Delete 执行软删除。
+type Repo[T any] struct {
+	db *Database
+}
+
+// NewRepo 创建模型 T 的仓储
+//
+// 示例:
+//
+//	users := database.NewRepo[User](db)
+//
+//	user, err := users.Get(ctx, 42)
+//	if kiterrors.Is(err, kiterrors.CodeRecordNotFound) { ... }
+//
+//	active, err := users.List(ctx, func(tx *gorm.DB) *gorm.DB {
+//	    return tx.Where("status = ?", "active").Order("id")
+//	})
+func NewRepo[T any](db *Database) *Repo[T] {
+	return &Repo[T]{db: db}
+}
+
+// Get 按主键查询记录
+func (r *Repo[T]) Get(ctx context.Context, id any) (*T, error) {
+	query, err := r.byID(ctx, "Repo.Get", id)
+	if err != nil {
+		return nil, err
+	}
+
+	var record T
+	if err := query.Take(&record).Error; err != nil {
+		return nil, r.wrap("Repo.Get", id, err)
+	}
+	return &record, nil
+}
+
+// List 查询满足 scopes 条件的所有记录，没有记录时返回空切片
+func (r *Repo[T]) List(ctx context.Context, scopes ...func(*gorm.DB) *gorm.DB) ([]T, error) {
+	records := make([]T, 0)
+	if err := r.session(ctx).Scopes(scopes...).Find(&records).Error; err != nil {
+		return nil, r.wrap("Repo.List", nil, err)
+	}
+	return records, nil
+}
+
+// Create 插入记录，自增主键和默认值回填到 record
+func (r *Repo[T]) Create(ctx context.Context, record *T) error {
+	if err := r.session(ctx).Create(record).Error; err != nil {
+		return r.wrap("Repo.Create", nil, err)
+	}
+	return nil
+}
+
+// Update 按 record 的主键更新记录
+//
+// 指定 fields 时只更新这些字段（零值同样写入），否则更新全部字段。
+// 不检查记录是否存在：MySQL 在值没有变化时受影响行数同样为0。
+func (r *Repo[T]) Update(ctx context.Context, record *T, fields ...string) error {
+	query := r.session(ctx).Model(record)
+	if len(fields) > 0 {
+		query = query.Select(fields)
+	} else {
+		query = query.Select("*")
+	}
+	if err := query.Updates(record).Error; err != nil {
+		return r.wrap("Repo.Update", nil, err)
+	}
+	return nil
+}
+
+// Delete 按主键删除记录，模型包含 gorm.DeletedAt 字段时执行软删除
+// 记录不存在（或已被软删除）时返回 errors.CodeRecordNotFound
+func (r *Repo[T]) Delete(ctx context.Context, id any) error {
+	query, err := r.byID(ctx, "Repo.Delete", id)
+	if err != nil {
+		return err
+	}
+
+	result := query.Delete(new(T))
+	if result.Error != nil {
+		return r.wrap("Repo.Delete", id, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return r.wrap("Repo.Delete", id, gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// Count 统计满足 scopes 条件的记录数
+func (r *Repo[T]) Count(ctx context.Context, scopes ...func(*gorm.DB) *gorm.DB) (int64, error) {
+	var count int64
+	if err := r.session(ctx).Model(new(T)).Scopes(scopes...).Count(&count).Error; err != nil {
+		return 0, r.wrap("Repo.Count", nil, err)
+	}
+	return count, nil
+}
+
+// IncludeDeleted 查询条件中包含已软删除的记录，可作为 Repo.List、Repo.Count 的 scope
+//
+// 示例:
+//
+//	all, err := users.List(ctx, database.IncludeDeleted)
+func IncludeDeleted(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+func (r *Repo[T]) session(ctx context.Context) *gorm.DB {
+	return SessionFromContext(ctx, r.db)
+}
+
+// byID 返回按主键过滤的查询
+// 主键条件使用参数绑定，字符串ID不会被 GORM 当作SQL片段
+func (r *Repo[T]) byID(ctx context.Context, operation string, id any) (*gorm.DB, error) {
+	db := r.session(ctx)
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, NewDatabaseError(ErrorTypeValidation, operation, err).WithContext("model", modelName[T]())
+	}
+	pk := stmt.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return nil, NewDatabaseError(ErrorTypeValidation, operation, ErrMissingPrimaryKey).WithContext("model", stmt.Schema.Name)
+	}
+	return db.Model(new(T)).Where(clause.Eq{
+		Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName},
+		Value:  id,
+	}), nil
+}
+
+// wrap 转换仓储错误并记录模型和ID，记录不存在时附加 errors.CodeRecordNotFound
+func (r *Repo[T]) wrap(operation string, id any, err error) error {
+	wrapped := WrapError(operation, err)
+	var dbErr *DatabaseError
+	if errors.As(wrapped, &dbErr) {
+		dbErr.WithContext("model", modelName[T]())
+		if id != nil {
+			dbErr.WithContext("id", id)
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return kiterrors.Wrap(wrapped, kiterrors.CodeRecordNotFound)
+	}
+	return wrapped
+}
+
+// modelName 返回模型的类型名，用于错误上下文
+func modelName[T any]() string {
+	return reflect.TypeOf((*T)(nil)).Elem().Name()
+}
@@ -0,0 +1,237 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	kiterrors "github.com/tsopia/go-kit/errors"
+
+	"gorm.io/gorm"
+)
+
+type repoUser struct {
+	ID     uint
+	Name   string
+	Email  string
+	Active bool
+}
+
+type repoDocument struct {
+	Key   string `gorm:"primaryKey"`
+	Title string
+}
+
+func repoDatabase(t *testing.T, models ...interface{}) *Database {
+	t.Helper()
+
+	db := newFileTestDatabase(t)
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	return db
+}
+
+func TestRepo_CreateGetList(t *testing.T) {
+	db := repoDatabase(t, &repoUser{})
+	users := NewRepo[repoUser](db)
+	ctx := context.Background()
+
+	alice := &repoUser{Name: "alice", Active: true}
+	if err := users.Create(ctx, alice); err != nil {
+		t.Fatalf("Create 失败: %v", err)
+	}
+	if alice.ID == 0 {
+		t.Fatal("Create 应回填自增主键")
+	}
+	if err := users.Create(ctx, &repoUser{Name: "bob"}); err != nil {
+		t.Fatalf("Create 失败: %v", err)
+	}
+
+	got, err := users.Get(ctx, alice.ID)
+	if err != nil {
+		t.Fatalf("Get 失败: %v", err)
+	}
+	if got.Name != "alice" {
+		t.Errorf("期望 alice, 实际 %s", got.Name)
+	}
+
+	all, err := users.List(ctx)
+	if err != nil || len(all) != 2 {
+		t.Fatalf("期望 2 条记录, 实际 %d (%v)", len(all), err)
+	}
+
+	active := func(tx *gorm.DB) *gorm.DB { return tx.Where("active = ?", true) }
+	list, err := users.List(ctx, active)
+	if err != nil || len(list) != 1 || list[0].Name != "alice" {
+		t.Errorf("期望只返回 alice, 实际 %+v (%v)", list, err)
+	}
+
+	count, err := users.Count(ctx, active)
+	if err != nil || count != 1 {
+		t.Errorf("期望 Count = 1, 实际 %d (%v)", count, err)
+	}
+	count, err = users.Count(ctx)
+	if err != nil || count != 2 {
+		t.Errorf("期望 Count = 2, 实际 %d (%v)", count, err)
+	}
+}
+
+func TestRepo_NotFound(t *testing.T) {
+	db := repoDatabase(t, &repoUser{})
+	users := NewRepo[repoUser](db)
+	ctx := context.Background()
+
+	_, err := users.Get(ctx, 404)
+	if !kiterrors.Is(err, kiterrors.CodeRecordNotFound) {
+		t.Errorf("期望 CodeRecordNotFound, 实际 %v", err)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("期望错误链中保留 gorm.ErrRecordNotFound, 实际 %v", err)
+	}
+	var dbErr *DatabaseError
+	if !errors.As(err, &dbErr) || dbErr.Context["id"] != 404 || dbErr.Context["model"] != "repoUser" {
+		t.Errorf("期望错误上下文包含模型和ID, 实际 %v", err)
+	}
+
+	if err := users.Delete(ctx, 404); !kiterrors.Is(err, kiterrors.CodeRecordNotFound) {
+		t.Errorf("删除不存在的记录期望 CodeRecordNotFound, 实际 %v", err)
+	}
+
+	list, err := users.List(ctx)
+	if err != nil || list == nil || len(list) != 0 {
+		t.Errorf("没有记录时期望空切片, 实际 %#v (%v)", list, err)
+	}
+}
+
+func TestRepo_UpdateFields(t *testing.T) {
+	db := repoDatabase(t, &repoUser{})
+	users := NewRepo[repoUser](db)
+	ctx := context.Background()
+
+	user := &repoUser{Name: "alice", Email: "alice@example.com", Active: true}
+	if err := users.Create(ctx, user); err != nil {
+		t.Fatalf("Create 失败: %v", err)
+	}
+
+	// 只更新指定字段，零值同样写入
+	if err := users.Update(ctx, &repoUser{ID: user.ID, Name: "ignored", Active: false}, "Active"); err != nil {
+		t.Fatalf("Update 失败: %v", err)
+	}
+	got, _ := users.Get(ctx, user.ID)
+	if got.Active || got.Name != "alice" || got.Email != "alice@example.com" {
+		t.Errorf("期望只更新 Active, 实际 %+v", got)
+	}
+
+	// 不指定字段时更新全部字段
+	if err := users.Update(ctx, &repoUser{ID: user.ID, Name: "alice2"}); err != nil {
+		t.Fatalf("Update 失败: %v", err)
+	}
+	got, _ = users.Get(ctx, user.ID)
+	if got.Name != "alice2" || got.Email != "" {
+		t.Errorf("期望更新全部字段, 实际 %+v", got)
+	}
+
+	// 缺少主键时不应更新全表
+	if err := users.Update(ctx, &repoUser{Name: "all"}, "Name"); err == nil {
+		t.Error("缺少主键时期望返回错误")
+	}
+}
+
+func TestRepo_Delete(t *testing.T) {
+	db := repoDatabase(t, &repoUser{})
+	users := NewRepo[repoUser](db)
+	ctx := context.Background()
+
+	user := &repoUser{Name: "alice"}
+	users.Create(ctx, user)
+
+	if err := users.Delete(ctx, user.ID); err != nil {
+		t.Fatalf("Delete 失败: %v", err)
+	}
+	if _, err := users.Get(ctx, user.ID); !kiterrors.Is(err, kiterrors.CodeRecordNotFound) {
+		t.Errorf("删除后期望 CodeRecordNotFound, 实际 %v", err)
+	}
+}
+
+func TestRepo_SoftDelete(t *testing.T) {
+	db := repoDatabase(t, &softUser{})
+	users := NewRepo[softUser](db)
+	ctx := context.Background()
+
+	user := &softUser{Name: "alice"}
+	users.Create(ctx, user)
+	users.Create(ctx, &softUser{Name: "bob"})
+
+	if err := users.Delete(ctx, user.ID); err != nil {
+		t.Fatalf("Delete 失败: %v", err)
+	}
+	if err := users.Delete(ctx, user.ID); !kiterrors.Is(err, kiterrors.CodeRecordNotFound) {
+		t.Errorf("重复软删除期望 CodeRecordNotFound, 实际 %v", err)
+	}
+
+	count, _ := users.Count(ctx)
+	if count != 1 {
+		t.Errorf("期望排除已删除记录, 实际 Count = %d", count)
+	}
+	count, _ = users.Count(ctx, IncludeDeleted)
+	if count != 2 {
+		t.Errorf("IncludeDeleted 期望 Count = 2, 实际 %d", count)
+	}
+	all, _ := users.List(ctx, IncludeDeleted)
+	if len(all) != 2 {
+		t.Errorf("IncludeDeleted 期望 2 条记录, 实际 %d", len(all))
+	}
+}
+
+func TestRepo_StringPrimaryKey(t *testing.T) {
+	db := repoDatabase(t, &repoDocument{})
+	docs := NewRepo[repoDocument](db)
+	ctx := context.Background()
+
+	docs.Create(ctx, &repoDocument{Key: "readme", Title: "README"})
+
+	got, err := docs.Get(ctx, "readme")
+	if err != nil || got.Title != "README" {
+		t.Fatalf("Get 失败: %+v (%v)", got, err)
+	}
+
+	// 字符串ID作为参数绑定，不会被当作SQL条件
+	if _, err := docs.Get(ctx, "1 = 1"); !kiterrors.Is(err, kiterrors.CodeRecordNotFound) {
+		t.Errorf("期望 CodeRecordNotFound, 实际 %v", err)
+	}
+}
+
+func TestRepo_JoinsTransaction(t *testing.T) {
+	db := repoDatabase(t, &repoUser{})
+	users := NewRepo[repoUser](db)
+
+	rollback := errors.New("rollback")
+	err := db.TransactionCtx(context.Background(), func(ctx context.Context) error {
+		if err := users.Create(ctx, &repoUser{Name: "alice"}); err != nil {
+			return err
+		}
+		if count, _ := users.Count(ctx); count != 1 {
+			t.Errorf("事务内期望 Count = 1, 实际 %d", count)
+		}
+		return rollback
+	})
+	if !errors.Is(err, rollback) {
+		t.Fatalf("期望返回 rollback, 实际 %v", err)
+	}
+
+	if count, _ := users.Count(context.Background()); count != 0 {
+		t.Errorf("回滚后期望 Count = 0, 实际 %d", count)
+	}
+}
+
+func TestRepo_CanceledContext(t *testing.T) {
+	db := repoDatabase(t, &repoUser{})
+	users := NewRepo[repoUser](db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := users.List(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("期望 context.Canceled, 实际 %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// GuardrailProductionSafety 生产环境安全联锁，拒绝时记录在 DatabaseError.Context["guardrail"] 中
+const GuardrailProductionSafety = "production_safety"
+
+// ErrDestructiveOperation 生产环境中未经确认的破坏性操作
+var ErrDestructiveOperation = errors.New("生产环境禁止未经确认的破坏性操作")
+
+// ProductionSafetyConfig 生产环境安全联锁配置
+//
+// Config.Environment 为 prod 或 production 时，破坏性操作（AutoMigrate、DROP/TRUNCATE 等DDL）
+// 必须通过 ConfirmDestructive 在 ctx 中确认，否则返回 ErrDestructiveOperation。
+// 以下开关用于在配置层面放行某类操作（例如专门执行迁移的部署任务），使用时同样记录审计日志。
+type ProductionSafetyConfig struct {
+	// AllowAutoMigrate 生产环境允许不经确认执行 AutoMigrate
+	AllowAutoMigrate bool `mapstructure:"allow_auto_migrate" json:"allow_auto_migrate" yaml:"allow_auto_migrate"`
+	// AllowDestructiveSQL 生产环境允许不经确认执行 DROP/TRUNCATE/ALTER ... DROP
+	AllowDestructiveSQL bool `mapstructure:"allow_destructive_sql" json:"allow_destructive_sql" yaml:"allow_destructive_sql"`
+}
+
+// IsProduction 是否为生产环境（Environment 为 prod 或 production，不区分大小写）
+func (c *Config) IsProduction() bool {
+	env := strings.TrimSpace(c.Environment)
+	return strings.EqualFold(env, "prod") || strings.EqualFold(env, "production")
+}
+
+type destructiveConfirmKey struct{}
+
+// ConfirmDestructive 返回确认执行破坏性操作的 ctx，reason 记录在审计日志中，不能为空
+//
+// 示例:
+//
+//	ctx = database.ConfirmDestructive(ctx, "JIRA-1234 删除废弃的 legacy_orders 表")
+//	_, err := db.ExecCtx(ctx, "DROP TABLE legacy_orders")
+func ConfirmDestructive(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, destructiveConfirmKey{}, strings.TrimSpace(reason))
+}
+
+// destructiveConfirmation 返回 ctx 中的确认原因
+func destructiveConfirmation(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	reason, _ := ctx.Value(destructiveConfirmKey{}).(string)
+	return reason, reason != ""
+}
+
+// auditLogf 输出破坏性操作的审计日志（测试时替换）
+var auditLogf = log.Printf
+
+// GuardDestructive 检查破坏性操作是否允许执行，非生产环境总是允许
+//
+// 生产环境中 ctx 没有通过 ConfirmDestructive 确认时返回包装 ErrDestructiveOperation 的
+// ErrorTypeValidation 错误；确认时记录审计日志。自定义的清理、重置数据等操作可以调用它接入联锁。
+//
+// 示例:
+//
+//	func (s *Seeder) Reset(ctx context.Context) error {
+//	    if err := s.db.GuardDestructive(ctx, "Seeder.Reset"); err != nil {
+//	        return err
+//	    }
+//	    ...
+//	}
+func (d *Database) GuardDestructive(ctx context.Context, operation string) error {
+	return checkDestructive(ctx, d.GetConfig(), operation, "", false)
+}
+
+// checkDestructive 生产环境中检查确认，allowed 为配置层面的放行开关
+func checkDestructive(ctx context.Context, cfg Config, operation, sql string, allowed bool) error {
+	if !cfg.IsProduction() {
+		return nil
+	}
+	target := operation
+	if sql != "" {
+		target = fmt.Sprintf("%s (%s)", operation, sql)
+	}
+	if reason, ok := destructiveConfirmation(ctx); ok {
+		auditLogf("[审计] 生产环境(%s)执行已确认的破坏性操作: %s，原因: %s", cfg.Environment, target, reason)
+		return nil
+	}
+	if allowed {
+		auditLogf("[审计] 生产环境(%s)按配置放行破坏性操作: %s", cfg.Environment, target)
+		return nil
+	}
+
+	err := NewDatabaseError(ErrorTypeValidation, operation, fmt.Errorf(
+		"%w: 已拦截 %s。确认后重试: ctx = database.ConfirmDestructive(ctx, \"原因\")，或在配置中开启 production_safety 对应的放行开关",
+		ErrDestructiveOperation, target)).
+		WithContext("guardrail", GuardrailProductionSafety).
+		WithContext("environment", cfg.Environment)
+	if sql != "" {
+		err = err.WithContext("sql", sql)
+	}
+	return err
+}
+
+const productionSafetyCallbackName = "go-kit:production_safety"
+
+// registerProductionSafety 生产环境中注册原生SQL的破坏性语句检查
+func (d *Database) registerProductionSafety(cfg Config) error {
+	if !cfg.IsProduction() {
+		return nil
+	}
+	check := func(db *gorm.DB) {
+		if db.Error != nil || db.Statement.SQL.Len() == 0 {
+			return
+		}
+		sql := db.Statement.SQL.String()
+		if !isDestructiveSQL(sql) {
+			return
+		}
+		if err := checkDestructive(db.Statement.Context, cfg, "exec", sql, cfg.ProductionSafety.AllowDestructiveSQL); err != nil {
+			db.AddError(err)
+		}
+	}
+
+	callbacks := d.db.Callback()
+	if err := callbacks.Raw().Before("gorm:raw").Register(productionSafetyCallbackName, check); err != nil {
+		return err
+	}
+	return callbacks.Row().Before("gorm:row").Register(productionSafetyCallbackName, check)
+}
+
+var sqlDropRe = regexp.MustCompile(`(?i)\bDROP\b`)
+
+// isDestructiveSQL 判断原生SQL中是否有 DROP、TRUNCATE 或 ALTER ... DROP 语句
+//
+// 去掉字符串字面量和注释后按分号拆分语句，只检查语句开头的关键字（不区分大小写和空白），
+// 因此 SELECT/INSERT/UPDATE/DELETE 等DML永远不会被拦截。
+func isDestructiveSQL(sql string) bool {
+	for _, stmt := range strings.Split(stripSQLLiterals(sql), ";") {
+		fields := strings.Fields(stmt)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "DROP", "TRUNCATE":
+			return true
+		case "ALTER":
+			if sqlDropRe.MatchString(stmt) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// captureAuditLog 替换审计日志输出，返回已记录的日志
+func captureAuditLog(t *testing.T) func() []string {
+	t.Helper()
+	var mu sync.Mutex
+	var lines []string
+	original := auditLogf
+	auditLogf = func(format string, args ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+	t.Cleanup(func() { auditLogf = original })
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), lines...)
+	}
+}
+
+func safetyDatabase(t *testing.T, environment string, safety ProductionSafetyConfig) *Database {
+	t.Helper()
+	db := newFileTestDatabase(t, func(config *Config) {
+		config.Environment = environment
+		config.ProductionSafety = safety
+	})
+
+	if err := db.AutoMigrateCtx(ConfirmDestructive(context.Background(), "测试初始化"), &TestUser{}); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	if err := db.GetDB().Create(&TestUser{Name: "alice", Email: "alice@example.com"}).Error; err != nil {
+		t.Fatalf("插入数据失败: %v", err)
+	}
+	return db
+}
+
+func assertDestructiveBlocked(t *testing.T, err error, blocked string) {
+	t.Helper()
+	if !errors.Is(err, ErrDestructiveOperation) {
+		t.Fatalf("期望 ErrDestructiveOperation, 实际 %v", err)
+	}
+	if !IsValidationError(err) {
+		t.Errorf("联锁错误应为 ErrorTypeValidation: %v", err)
+	}
+	if name, ok := IsGuardrailViolation(err); !ok || name != GuardrailProductionSafety {
+		t.Errorf("期望护栏名称 %s, 实际 %q", GuardrailProductionSafety, name)
+	}
+	// 错误信息说明拦截了什么以及如何确认
+	if msg := err.Error(); !strings.Contains(msg, blocked) || !strings.Contains(msg, "ConfirmDestructive") {
+		t.Errorf("错误信息应包含 %q 和确认方式: %s", blocked, msg)
+	}
+}
+
+func TestIsDestructiveSQL(t *testing.T) {
+	destructive := []string{
+		"DROP TABLE users",
+		"  drop\n\ttable users",
+		"Truncate users",
+		"/* cleanup */ DROP TABLE users",
+		"-- cleanup\nTRUNCATE TABLE users",
+		"SELECT 1; DROP TABLE users",
+		"ALTER TABLE users DROP COLUMN email",
+		"alter table users\n drop constraint fk_orders",
+		"DROP INDEX idx_users_email",
+	}
+	for _, sql := range destructive {
+		if !isDestructiveSQL(sql) {
+			t.Errorf("应识别为破坏性语句: %q", sql)
+		}
+	}
+
+	safe := []string{
+		"SELECT * FROM drop_log",
+		"SELECT 'DROP TABLE users'",
+		"INSERT INTO audit (action) VALUES ('truncate')",
+		"UPDATE users SET status = 'drop' WHERE id = 1",
+		"DELETE FROM users WHERE note = 'DROP TABLE x; TRUNCATE y'",
+		"UPDATE `drop` SET truncate = 1 WHERE id = 2",
+		"ALTER TABLE users ADD COLUMN dropped_at DATETIME",
+		"CREATE TABLE drops (id INT)",
+	}
+	for _, sql := range safe {
+		if isDestructiveSQL(sql) {
+			t.Errorf("不应识别为破坏性语句: %q", sql)
+		}
+	}
+}
+
+func TestProductionSafety_BlocksUnconfirmed(t *testing.T) {
+	captureAuditLog(t)
+	db := safetyDatabase(t, "prod", ProductionSafetyConfig{})
+	ctx := context.Background()
+
+	t.Run("auto migrate", func(t *testing.T) {
+		assertDestructiveBlocked(t, db.AutoMigrate(&TestUser{}), "AutoMigrate")
+		assertDestructiveBlocked(t, db.AutoMigrateCtx(ctx, &TestUser{}), "AutoMigrate")
+	})
+
+	t.Run("raw exec", func(t *testing.T) {
+		for _, sql := range []string{"DROP TABLE test_users", "  truncate   test_users", "DELETE FROM test_users WHERE id = 0; drop table test_users"} {
+			_, err := db.ExecCtx(ctx, sql)
+			assertDestructiveBlocked(t, err, sql)
+			assertDestructiveBlocked(t, db.GetDB().Exec(sql).Error, sql)
+		}
+		if !db.GetDB().Migrator().HasTable(&TestUser{}) {
+			t.Fatal("被拒绝的语句不应执行")
+		}
+	})
+
+	t.Run("migrator drop table", func(t *testing.T) {
+		assertDestructiveBlocked(t, db.GetDB().Migrator().DropTable(&TestUser{}), "DROP TABLE")
+	})
+
+	t.Run("custom operation", func(t *testing.T) {
+		assertDestructiveBlocked(t, db.GuardDestructive(ctx, "Seeder.Reset"), "Seeder.Reset")
+	})
+
+	t.Run("empty reason", func(t *testing.T) {
+		_, err := db.ExecCtx(ConfirmDestructive(ctx, "  "), "DROP TABLE test_users")
+		assertDestructiveBlocked(t, err, "DROP TABLE")
+	})
+}
+
+func TestProductionSafety_AllowsDML(t *testing.T) {
+	captureAuditLog(t)
+	db := safetyDatabase(t, "production", ProductionSafetyConfig{})
+	ctx := context.Background()
+
+	if _, err := db.ExecCtx(ctx, "UPDATE test_users SET name = ? WHERE name = ?", "DROP TABLE", "alice"); err != nil {
+		t.Fatalf("普通 UPDATE 不应被拦截: %v", err)
+	}
+	if _, err := db.ExecCtx(ctx, "INSERT INTO test_users (name, email, age) VALUES ('truncate', 'b@example.com', 1)"); err != nil {
+		t.Fatalf("普通 INSERT 不应被拦截: %v", err)
+	}
+	if _, err := db.ExecCtx(ctx, "DELETE FROM test_users WHERE name = 'truncate'"); err != nil {
+		t.Fatalf("普通 DELETE 不应被拦截: %v", err)
+	}
+	var names []string
+	if err := db.GetDB().Raw("SELECT name FROM test_users").Scan(&names).Error; err != nil {
+		t.Fatalf("普通查询不应被拦截: %v", err)
+	}
+	if len(names) != 1 || names[0] != "DROP TABLE" {
+		t.Errorf("期望更新后的记录, 实际 %v", names)
+	}
+}
+
+func TestProductionSafety_Confirmed(t *testing.T) {
+	logs := captureAuditLog(t)
+	db := safetyDatabase(t, "prod", ProductionSafetyConfig{})
+	ctx := ConfirmDestructive(context.Background(), "JIRA-42 清理测试表")
+
+	if err := db.AutoMigrateCtx(ctx, &TestUser{}); err != nil {
+		t.Fatalf("确认后应允许 AutoMigrate: %v", err)
+	}
+	if err := db.GuardDestructive(ctx, "Seeder.Reset"); err != nil {
+		t.Fatalf("确认后应允许自定义操作: %v", err)
+	}
+	if _, err := db.ExecCtx(ctx, "DROP TABLE test_users"); err != nil {
+		t.Fatalf("确认后应允许 DROP: %v", err)
+	}
+	if db.GetDB().Migrator().HasTable(&TestUser{}) {
+		t.Error("确认后 DROP 应执行")
+	}
+
+	lines := logs()
+	// 初始化迁移 + 3 次确认操作
+	if len(lines) != 4 {
+		t.Fatalf("期望 4 条审计日志, 实际 %d: %v", len(lines), lines)
+	}
+	for _, want := range []string{"AutoMigrate", "Seeder.Reset", "DROP TABLE test_users"} {
+		found := false
+		for _, line := range lines {
+			if strings.Contains(line, want) && strings.Contains(line, "JIRA-42 清理测试表") {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("审计日志应记录 %q 和确认原因: %v", want, lines)
+		}
+	}
+}
+
+func TestProductionSafety_ConfigOverride(t *testing.T) {
+	logs := captureAuditLog(t)
+	db := safetyDatabase(t, "prod", ProductionSafetyConfig{AllowAutoMigrate: true})
+	ctx := context.Background()
+
+	if err := db.AutoMigrateCtx(ctx, &TestUser{}); err != nil {
+		t.Fatalf("AllowAutoMigrate 应放行迁移: %v", err)
+	}
+	_, err := db.ExecCtx(ctx, "DROP TABLE test_users")
+	assertDestructiveBlocked(t, err, "DROP TABLE")
+
+	lines := logs()
+	if len(lines) != 2 || !strings.Contains(lines[1], "按配置放行") {
+		t.Errorf("配置放行应记录审计日志, 实际 %v", lines)
+	}
+}
+
+func TestProductionSafety_NonProduction(t *testing.T) {
+	for _, env := range []string{"", "dev", "staging"} {
+		t.Run(env, func(t *testing.T) {
+			logs := captureAuditLog(t)
+			db := safetyDatabase(t, env, ProductionSafetyConfig{})
+			ctx := context.Background()
+
+			if err := db.AutoMigrate(&TestUser{}); err != nil {
+				t.Fatalf("非生产环境不需要确认: %v", err)
+			}
+			if err := db.GuardDestructive(ctx, "Seeder.Reset"); err != nil {
+				t.Fatalf("非生产环境不需要确认: %v", err)
+			}
+			if _, err := db.ExecCtx(ctx, "DROP TABLE test_users"); err != nil {
+				t.Fatalf("非生产环境不需要确认: %v", err)
+			}
+			if lines := logs(); len(lines) != 0 {
+				t.Errorf("非生产环境不应记录审计日志: %v", lines)
+			}
+		})
+	}
+}
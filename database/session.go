@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+
+	"github.com/tsopia/go-kit/constants"
+
+	"gorm.io/gorm"
+)
+
+// SessionConfig SessionFromContext 创建的会话设置
+type SessionConfig struct {
+	PrepareStmt bool `mapstructure:"prepare_stmt" json:"prepare_stmt" yaml:"prepare_stmt"` // 会话内缓存预编译语句
+	QueryFields bool `mapstructure:"query_fields" json:"query_fields" yaml:"query_fields"` // 查询时列出全部字段而不是 SELECT *
+}
+
+type databaseContextKey struct{}
+
+// WithDatabase 将数据库管理器存入 context，配合 FromContext 使用
+func WithDatabase(ctx context.Context, db *Database) context.Context {
+	return context.WithValue(ctx, databaseContextKey{}, db)
+}
+
+// FromContext 从 context 中获取 WithDatabase 存入的数据库管理器
+func FromContext(ctx context.Context) (*Database, bool) {
+	db, ok := ctx.Value(databaseContextKey{}).(*Database)
+	return db, ok && db != nil
+}
+
+// SessionFromContext 返回绑定请求上下文的GORM会话
+//
+//   - 查询随 ctx 取消（例如客户端断开）
+//   - ctx 没有截止时间且配置了 DefaultQueryTimeout 时，使用该超时
+//   - 使用 GORM 日志桥接器（NewGormLogger）时，会话日志带上 trace_id 和 request_id
+//   - 应用 Config.Session 中的 PrepareStmt、QueryFields 设置
+//   - ctx 中有 TransactionCtx 开启的事务时加入该事务
+//
+// 示例:
+//
+//	var user User
+//	err := database.SessionFromContext(r.Context(), db).First(&user, id).Error
+func SessionFromContext(ctx context.Context, db *Database) *gorm.DB {
+	db.mu.RLock()
+	base := db.db
+	timeout := db.config.DefaultQueryTimeout
+	settings := db.config.Session
+	db.mu.RUnlock()
+
+	// 加入 TransactionCtx 开启的事务
+	if tx, ok := db.txFromContext(ctx); ok {
+		base = tx
+	}
+
+	if _, ok := ctx.Deadline(); !ok && timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		// 会话没有明确的结束点，cancel 无法交给调用方；截止时间到达后计时器即被释放
+		_ = cancel
+	}
+
+	session := &gorm.Session{
+		Context:     ctx,
+		PrepareStmt: settings.PrepareStmt,
+		QueryFields: settings.QueryFields,
+	}
+	if bridge, ok := base.Logger.(*GORMLogger); ok {
+		session.Logger = bridge.withFields(traceFields(ctx))
+	}
+
+	return base.Session(session)
+}
+
+// traceFields 提取 context 中的 trace_id 和 request_id
+func traceFields(ctx context.Context) []interface{} {
+	var fields []interface{}
+	if traceID := constants.TraceIDFromContext(ctx); traceID != "" {
+		fields = append(fields, constants.TraceIDKey, traceID)
+	}
+	if requestID := constants.RequestIDFromContext(ctx); requestID != "" {
+		fields = append(fields, constants.RequestIDKey, requestID)
+	}
+	return fields
+}
+
+// withFields 返回为每条日志附加固定字段的桥接器副本
+func (g *GORMLogger) withFields(fields []interface{}) *GORMLogger {
+	if len(fields) == 0 {
+		return g
+	}
+	return &GORMLogger{
+		l:            &fieldsLogger{l: g.l, fields: fields},
+		level:        g.level,
+		traceEnabled: g.traceEnabled,
+	}
+}
+
+// fieldsLogger 为每条日志附加固定字段
+// 同时实现 ContextualLogger，底层日志器支持 Context 时继续使用 Context 方法
+type fieldsLogger struct {
+	l      SimpleLogger
+	fields []interface{}
+}
+
+func (f *fieldsLogger) with(fields []interface{}) []interface{} {
+	merged := make([]interface{}, 0, len(fields)+len(f.fields))
+	merged = append(merged, fields...)
+	return append(merged, f.fields...)
+}
+
+func (f *fieldsLogger) Info(msg string, fields ...interface{}) {
+	f.l.Info(msg, f.with(fields)...)
+}
+
+func (f *fieldsLogger) Warn(msg string, fields ...interface{}) {
+	f.l.Warn(msg, f.with(fields)...)
+}
+
+func (f *fieldsLogger) Error(msg string, fields ...interface{}) {
+	f.l.Error(msg, f.with(fields)...)
+}
+
+func (f *fieldsLogger) InfoWithContext(ctx context.Context, msg string, fields ...interface{}) {
+	if cl, ok := f.l.(ContextualLogger); ok {
+		cl.InfoWithContext(ctx, msg, f.with(fields)...)
+		return
+	}
+	f.Info(msg, fields...)
+}
+
+func (f *fieldsLogger) WarnWithContext(ctx context.Context, msg string, fields ...interface{}) {
+	if cl, ok := f.l.(ContextualLogger); ok {
+		cl.WarnWithContext(ctx, msg, f.with(fields)...)
+		return
+	}
+	f.Warn(msg, fields...)
+}
+
+func (f *fieldsLogger) ErrorWithContext(ctx context.Context, msg string, fields ...interface{}) {
+	if cl, ok := f.l.(ContextualLogger); ok {
+		cl.ErrorWithContext(ctx, msg, f.with(fields)...)
+		return
+	}
+	f.Error(msg, fields...)
+}
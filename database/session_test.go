@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tsopia/go-kit/constants"
+)
+
+// longQuery 在SQLite中执行足够久的查询
+const longQuery = "WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 1000000000) SELECT count(*) FROM n"
+
+// recordingLogger 记录日志字段的 SimpleLogger
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries [][]interface{}
+}
+
+func (r *recordingLogger) record(fields []interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, fields)
+}
+
+func (r *recordingLogger) Info(msg string, fields ...interface{})  { r.record(fields) }
+func (r *recordingLogger) Warn(msg string, fields ...interface{})  { r.record(fields) }
+func (r *recordingLogger) Error(msg string, fields ...interface{}) { r.record(fields) }
+
+func (r *recordingLogger) field(key string) []interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var values []interface{}
+	for _, fields := range r.entries {
+		for i := 0; i+1 < len(fields); i += 2 {
+			if fields[i] == key {
+				values = append(values, fields[i+1])
+			}
+		}
+	}
+	return values
+}
+
+func sessionDatabase(t *testing.T, configure func(*Config)) *Database {
+	t.Helper()
+
+	if configure == nil {
+		return newFileTestDatabase(t)
+	}
+	return newFileTestDatabase(t, configure)
+}
+
+func TestSessionFromContextCancellation(t *testing.T) {
+	db := sessionDatabase(t, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	var count int64
+	err := SessionFromContext(ctx, db).Raw(longQuery).Scan(&count).Error
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("期望 context.Canceled, 实际 %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("取消后查询应尽快返回, 实际耗时 %v", elapsed)
+	}
+}
+
+func TestSessionFromContextDefaultTimeout(t *testing.T) {
+	db := sessionDatabase(t, func(c *Config) {
+		c.DefaultQueryTimeout = 50 * time.Millisecond
+	})
+
+	var count int64
+	err := SessionFromContext(context.Background(), db).Raw(longQuery).Scan(&count).Error
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("期望 context.DeadlineExceeded, 实际 %v", err)
+	}
+
+	// 调用方设置的截止时间优先
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+	got, ok := SessionFromContext(ctx, db).Statement.Context.Deadline()
+	if !ok || !got.Equal(deadline) {
+		t.Errorf("期望保留调用方的截止时间 %v, 实际 %v", deadline, got)
+	}
+}
+
+func TestSessionFromContextLoggerFields(t *testing.T) {
+	recorder := &recordingLogger{}
+	db := sessionDatabase(t, func(c *Config) {
+		c.CustomLogger = NewGormLogger(recorder, "info")
+		c.Session = SessionConfig{QueryFields: true}
+	})
+	if err := db.AutoMigrate(&TestUser{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := constants.WithTraceAndRequestID(context.Background(), "trace-123", "req-456")
+	session := SessionFromContext(ctx, db)
+	if !session.Config.QueryFields {
+		t.Error("期望会话应用 QueryFields 设置")
+	}
+
+	var users []TestUser
+	if err := session.Find(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	traceIDs := recorder.field(constants.TraceIDKey)
+	if len(traceIDs) == 0 || traceIDs[len(traceIDs)-1] != "trace-123" {
+		t.Errorf("期望日志桥接器收到 trace_id, 实际 %v", traceIDs)
+	}
+	requestIDs := recorder.field(constants.RequestIDKey)
+	if len(requestIDs) == 0 || requestIDs[len(requestIDs)-1] != "req-456" {
+		t.Errorf("期望日志桥接器收到 request_id, 实际 %v", requestIDs)
+	}
+
+	// 全局实例的日志不受会话影响
+	before := len(recorder.field(constants.TraceIDKey))
+	db.GetDB().Find(&users)
+	if after := len(recorder.field(constants.TraceIDKey)); after != before {
+		t.Errorf("全局实例不应带有会话字段")
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if _, ok := FromContext(context.Background()); ok {
+		t.Error("空 context 不应包含数据库实例")
+	}
+
+	db := sessionDatabase(t, nil)
+	got, ok := FromContext(WithDatabase(context.Background(), db))
+	if !ok || got != db {
+		t.Error("期望取回存入的数据库实例")
+	}
+}
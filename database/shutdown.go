@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrDatabaseClosing 数据库正在关闭，不再接受事务外的新查询
+var ErrDatabaseClosing = errors.New("数据库正在关闭")
+
+// shutdownPollInterval Shutdown 检查进行中查询的间隔
+const shutdownPollInterval = 10 * time.Millisecond
+
+// shutdownGuardName 拒绝新查询的回调名称
+const shutdownGuardName = "go-kit:shutdown_guard"
+
+// Shutdown 优雅关闭数据库：拒绝新查询，等待进行中的查询和事务完成后关闭连接池
+//
+// 调用后事务外的新查询和新事务返回 ErrDatabaseClosing，已开始的事务可以继续执行直到提交或回滚。
+// 每 10ms 检查一次使用中的连接数，归零后关闭；ctx 结束时不再等待，关闭连接池并返回 ctx 的错误。
+// 与 httpserver 的优雅关闭配合使用时，应在HTTP连接排空之后调用:
+//
+//	server.OnShutdown(db.Shutdown)
+func (d *Database) Shutdown(ctx context.Context) error {
+	d.closing.Store(true)
+
+	sqlDB, err := d.GetDB().DB()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+	for sqlDB.Stats().InUse > 0 {
+		select {
+		case <-ctx.Done():
+			inUse := sqlDB.Stats().InUse
+			if closeErr := d.Close(); closeErr != nil {
+				return fmt.Errorf("关闭数据库失败: %w", closeErr)
+			}
+			return fmt.Errorf("等待进行中的查询超时，%d 个连接仍在使用: %w", inUse, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	return d.Close()
+}
+
+// IsClosing 是否已调用 Shutdown
+func (d *Database) IsClosing() bool {
+	return d.closing.Load()
+}
+
+// registerShutdownGuard 注册在 Shutdown 之后拒绝事务外新查询的回调
+func (d *Database) registerShutdownGuard() error {
+	guard := func(db *gorm.DB) {
+		if !d.closing.Load() {
+			return
+		}
+		// 已开始的事务需要完成，否则会留下被截断的事务
+		if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); inTx {
+			return
+		}
+		db.AddError(ErrDatabaseClosing)
+	}
+
+	callbacks := d.db.Callback()
+	registrations := []error{
+		callbacks.Create().Before("gorm:create").Register(shutdownGuardName, guard),
+		callbacks.Query().Before("gorm:query").Register(shutdownGuardName, guard),
+		callbacks.Update().Before("gorm:update").Register(shutdownGuardName, guard),
+		callbacks.Delete().Before("gorm:delete").Register(shutdownGuardName, guard),
+		callbacks.Row().Before("gorm:row").Register(shutdownGuardName, guard),
+		callbacks.Raw().Before("gorm:raw").Register(shutdownGuardName, guard),
+	}
+	for _, err := range registrations {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
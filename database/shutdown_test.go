@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestDatabase_ShutdownWaitsForInFlight(t *testing.T) {
+	db := newFileTestDatabase(t)
+
+	// 进行中的事务占用一个连接，模拟慢查询
+	tx := db.GetDB().Begin()
+	if tx.Error != nil {
+		t.Fatalf("开启事务失败: %v", tx.Error)
+	}
+
+	const hold = 150 * time.Millisecond
+	txDone := make(chan error, 1)
+	go func() {
+		time.Sleep(hold)
+		// 关闭期间已开始的事务可以继续执行
+		if err := tx.Exec("SELECT 1").Error; err != nil {
+			tx.Rollback()
+			txDone <- err
+			return
+		}
+		txDone <- tx.Commit().Error
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	shutdownDone := make(chan error, 1)
+	start := time.Now()
+	go func() { shutdownDone <- db.Shutdown(ctx) }()
+
+	// 等待 Shutdown 开始后，事务外的新查询和新事务被拒绝
+	for !db.IsClosing() {
+		time.Sleep(time.Millisecond)
+	}
+	var n int
+	if err := db.GetDB().Raw("SELECT 1").Scan(&n).Error; !errors.Is(err, ErrDatabaseClosing) {
+		t.Errorf("期望新查询返回 ErrDatabaseClosing, 实际 %v", err)
+	}
+	if err := db.Transaction(func(*gorm.DB) error { return nil }); !errors.Is(err, ErrDatabaseClosing) {
+		t.Errorf("期望新事务返回 ErrDatabaseClosing, 实际 %v", err)
+	}
+
+	if err := <-shutdownDone; err != nil {
+		t.Fatalf("Shutdown 失败: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < hold {
+		t.Errorf("Shutdown 应等待进行中的事务完成, 实际只等待了 %v", elapsed)
+	}
+	if err := <-txDone; err != nil {
+		t.Errorf("进行中的事务应正常提交, 实际 %v", err)
+	}
+	if db.Ping() == nil {
+		t.Error("Shutdown 后连接池应已关闭")
+	}
+}
+
+func TestDatabase_ShutdownTimeout(t *testing.T) {
+	db := newFileTestDatabase(t)
+
+	tx := db.GetDB().Begin()
+	if tx.Error != nil {
+		t.Fatalf("开启事务失败: %v", tx.Error)
+	}
+	defer tx.Rollback()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := db.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("期望超时错误, 实际 %v", err)
+	}
+	if db.Ping() == nil {
+		t.Error("超时后连接池也应关闭")
+	}
+}
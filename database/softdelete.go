@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// ErrSoftDeleteUnsupported 模型缺少 gorm.DeletedAt 字段，不支持软删除
+var ErrSoftDeleteUnsupported = errors.New("模型不支持软删除，缺少 gorm.DeletedAt 字段")
+
+var deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+
+// ValidateSoftDelete 校验模型包含 gorm.DeletedAt 字段
+// 不支持的模型返回包装 ErrSoftDeleteUnsupported 的 ErrorTypeValidation 错误，上下文中记录模型名
+func (d *Database) ValidateSoftDelete(models ...interface{}) error {
+	db := d.GetDB()
+	for _, model := range models {
+		if err := validateSoftDelete(db, model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateSoftDelete 解析模型的 schema 并查找 gorm.DeletedAt 字段
+func validateSoftDelete(db *gorm.DB, model interface{}) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return NewDatabaseError(ErrorTypeValidation, "ValidateSoftDelete", err).
+			WithContext("model", fmt.Sprintf("%T", model))
+	}
+	for _, field := range stmt.Schema.Fields {
+		if field.FieldType == deletedAtType {
+			return nil
+		}
+	}
+	return NewDatabaseError(ErrorTypeValidation, "ValidateSoftDelete", ErrSoftDeleteUnsupported).
+		WithContext("model", stmt.Schema.Name)
+}
+
+// Unscoped 返回不附加软删除条件的GORM实例，用于查询已删除记录或永久删除
+//
+// 示例:
+//
+//	// 永久删除
+//	err := db.Unscoped().Delete(&User{}, id).Error
+func (d *Database) Unscoped() *gorm.DB {
+	return d.GetDB().Unscoped()
+}
+
+// SoftDeleteByID 按主键软删除记录
+//
+// 模型必须包含 gorm.DeletedAt 字段，否则返回 ErrSoftDeleteUnsupported，避免误执行物理删除；
+// 记录不存在（或已被删除）时返回包装 gorm.ErrRecordNotFound 的错误。
+// ctx 中有 TransactionCtx 开启的事务时在该事务中执行。
+//
+// 示例:
+//
+//	err := db.SoftDeleteByID(ctx, &User{}, 42)
+func (d *Database) SoftDeleteByID(ctx context.Context, model interface{}, id interface{}) error {
+	db := d.WithContext(ctx)
+	if err := validateSoftDelete(db, model); err != nil {
+		return err
+	}
+
+	result := db.Delete(model, id)
+	if result.Error != nil {
+		return NewDatabaseError(ErrorTypeQuery, "SoftDeleteByID", result.Error).WithContext("id", id)
+	}
+	if result.RowsAffected == 0 {
+		return NewDatabaseError(ErrorTypeQuery, "SoftDeleteByID", gorm.ErrRecordNotFound).WithContext("id", id)
+	}
+	return nil
+}
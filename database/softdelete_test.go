@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type softUser struct {
+	gorm.Model
+	Name string
+}
+
+type hardUser struct {
+	ID   uint
+	Name string
+}
+
+func softDeleteDatabase(t *testing.T, enable bool) *Database {
+	t.Helper()
+
+	return newFileTestDatabase(t, func(config *Config) {
+		config.EnableSoftDelete = enable
+	})
+}
+
+func TestAutoMigrate_EnableSoftDelete(t *testing.T) {
+	db := softDeleteDatabase(t, true)
+
+	if err := db.AutoMigrate(&softUser{}); err != nil {
+		t.Fatalf("包含 DeletedAt 的模型应迁移成功: %v", err)
+	}
+
+	err := db.AutoMigrate(&softUser{}, &hardUser{})
+	if !errors.Is(err, ErrSoftDeleteUnsupported) {
+		t.Fatalf("期望 ErrSoftDeleteUnsupported, 实际 %v", err)
+	}
+	if !IsValidationError(err) {
+		t.Errorf("期望验证错误, 实际 %v", err)
+	}
+	if db.GetDB().Migrator().HasTable(&hardUser{}) {
+		t.Error("校验失败时不应执行迁移")
+	}
+
+	// 未启用时不校验
+	plain := softDeleteDatabase(t, false)
+	if err := plain.AutoMigrate(&hardUser{}); err != nil {
+		t.Errorf("未启用软删除时应迁移成功: %v", err)
+	}
+}
+
+func TestSoftDeleteByID(t *testing.T) {
+	db := softDeleteDatabase(t, true)
+	ctx := context.Background()
+	if err := db.AutoMigrate(&softUser{}); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+
+	user := softUser{Name: "alice"}
+	if err := db.WithContext(ctx).Create(&user).Error; err != nil {
+		t.Fatalf("创建记录失败: %v", err)
+	}
+	if user.CreatedAt.IsZero() || user.UpdatedAt.IsZero() {
+		t.Error("期望自动填充 created_at / updated_at")
+	}
+
+	if err := db.SoftDeleteByID(ctx, &softUser{}, user.ID); err != nil {
+		t.Fatalf("软删除失败: %v", err)
+	}
+
+	var count int64
+	db.WithContext(ctx).Model(&softUser{}).Count(&count)
+	if count != 0 {
+		t.Errorf("软删除后默认查询应不可见, 实际 %d 条", count)
+	}
+
+	var deleted softUser
+	if err := db.Unscoped().First(&deleted, user.ID).Error; err != nil {
+		t.Fatalf("Unscoped 应能查询到已删除记录: %v", err)
+	}
+	if !deleted.DeletedAt.Valid {
+		t.Error("期望 deleted_at 已设置")
+	}
+
+	// 重复删除视为记录不存在
+	if err := db.SoftDeleteByID(ctx, &softUser{}, user.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("期望 gorm.ErrRecordNotFound, 实际 %v", err)
+	}
+
+	// 永久删除
+	if err := db.Unscoped().Delete(&softUser{}, user.ID).Error; err != nil {
+		t.Fatalf("永久删除失败: %v", err)
+	}
+	db.Unscoped().Model(&softUser{}).Count(&count)
+	if count != 0 {
+		t.Errorf("永久删除后应无记录, 实际 %d 条", count)
+	}
+}
+
+func TestSoftDeleteByID_RejectsHardDelete(t *testing.T) {
+	db := softDeleteDatabase(t, false)
+	ctx := context.Background()
+	if err := db.AutoMigrate(&hardUser{}); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	user := hardUser{Name: "bob"}
+	db.WithContext(ctx).Create(&user)
+
+	if err := db.SoftDeleteByID(ctx, &hardUser{}, user.ID); !errors.Is(err, ErrSoftDeleteUnsupported) {
+		t.Fatalf("期望 ErrSoftDeleteUnsupported, 实际 %v", err)
+	}
+
+	var count int64
+	db.WithContext(ctx).Model(&hardUser{}).Count(&count)
+	if count != 1 {
+		t.Errorf("不支持软删除的模型不应被删除, 实际 %d 条", count)
+	}
+}
+
+func TestSoftDeleteByID_InTransaction(t *testing.T) {
+	db := softDeleteDatabase(t, true)
+	if err := db.AutoMigrate(&softUser{}); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	user := softUser{Name: "carol"}
+	db.WithContext(context.Background()).Create(&user)
+
+	rollback := errors.New("rollback")
+	err := db.TransactionCtx(context.Background(), func(ctx context.Context) error {
+		if err := db.SoftDeleteByID(ctx, &softUser{}, user.ID); err != nil {
+			return err
+		}
+		return rollback
+	})
+	if !errors.Is(err, rollback) {
+		t.Fatalf("期望事务回滚错误, 实际 %v", err)
+	}
+
+	var count int64
+	db.WithContext(context.Background()).Model(&softUser{}).Count(&count)
+	if count != 1 {
+		t.Errorf("事务回滚后记录应仍可见, 实际 %d 条", count)
+	}
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+
+	"gorm.io/gorm"
+)
+
+// 多租户 schema 切换相关错误
+var (
+	ErrInvalidSchema     = errors.New("无效的schema名称")
+	ErrSchemaUnsupported = errors.New("只有PostgreSQL支持按schema切换")
+	ErrSchemaContext     = errors.New("WithSchema 需要可以取消的 ctx，连接在 ctx 结束时归还连接池")
+)
+
+// schemaNamePattern PostgreSQL 未加引号的标识符：字母或下划线开头，最长63字节
+var schemaNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]{0,62}$`)
+
+// ValidateSchemaName 检查 schema 名称，只允许字母、数字和下划线，防止拼接进 search_path 时被注入
+func ValidateSchemaName(schema string) error {
+	if !schemaNamePattern.MatchString(schema) {
+		return fmt.Errorf("%w: %q（只允许字母、数字和下划线，以字母或下划线开头，最长63个字符）", ErrInvalidSchema, schema)
+	}
+	return nil
+}
+
+// WithSchema 返回查询 schema 中的表的GORM实例，用于 schema-per-tenant 的多租户部署（仅 PostgreSQL）
+//
+// search_path 只作用于本次请求，不会影响连接池中的其他连接：
+//   - ctx 中有 TransactionCtx 开启的事务时，在事务内执行 SET LOCAL，事务结束后自动恢复
+//   - 否则从连接池取出一个连接专用于本次请求，设置 search_path 后返回绑定该连接的实例；
+//     ctx 结束（请求完成或取消）时重置 search_path 并归还连接，因此 ctx 必须可以取消
+//
+// 名称无效、驱动不支持或设置失败时，错误记录在返回实例的 Error 中，后续查询直接返回该错误。
+//
+// 示例:
+//
+//	tenantDB := db.WithSchema(r.Context(), "tenant_"+tenantID)
+//	err := tenantDB.Where("status = ?", "paid").Find(&orders).Error
+func (d *Database) WithSchema(ctx context.Context, schema string) *gorm.DB {
+	session := d.WithContext(ctx)
+	if err := ValidateSchemaName(schema); err != nil {
+		return schemaError(session, ErrorTypeValidation, err)
+	}
+	if driver := d.GetDriver(); driver != "postgres" {
+		return schemaError(session, ErrorTypeValidation, fmt.Errorf("%w: %s", ErrSchemaUnsupported, driver))
+	}
+
+	// 事务内使用 SET LOCAL，作用范围就是事务本身
+	if tx, ok := d.txFromContext(ctx); ok {
+		if err := tx.WithContext(ctx).Exec(fmt.Sprintf(`SET LOCAL search_path TO "%s"`, schema)).Error; err != nil {
+			return schemaError(session, ErrorTypeQuery, err)
+		}
+		return session
+	}
+
+	if ctx.Done() == nil {
+		return schemaError(session, ErrorTypeValidation, ErrSchemaContext)
+	}
+	sqlDB, err := session.DB()
+	if err != nil {
+		return schemaError(session, ErrorTypeConnection, err)
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return schemaError(session, ErrorTypeConnection, err)
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf(`SET search_path TO "%s"`, schema)); err != nil {
+		conn.Close()
+		return schemaError(session, ErrorTypeQuery, err)
+	}
+	context.AfterFunc(ctx, func() { releaseSchemaConn(conn) })
+
+	// 指定 Context 使会话复制 Statement，修改 ConnPool 不影响其他实例
+	pinned := session.Session(&gorm.Session{Context: ctx, NewDB: true})
+	pinned.Statement.ConnPool = conn
+	return pinned
+}
+
+// releaseSchemaConn 重置 search_path 后归还连接，重置失败时丢弃该连接，避免其他请求使用错误的 schema
+func releaseSchemaConn(conn *sql.Conn) {
+	if _, err := conn.ExecContext(context.Background(), "RESET search_path"); err != nil {
+		log.Printf("重置 search_path 失败，丢弃连接: %v", err)
+		conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	}
+	conn.Close()
+}
+
+// schemaError 返回记录了错误的GORM实例
+func schemaError(db *gorm.DB, errType ErrorType, err error) *gorm.DB {
+	db.AddError(NewDatabaseError(errType, "with_schema", err))
+	return db
+}
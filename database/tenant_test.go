@@ -0,0 +1,158 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestValidateSchemaName(t *testing.T) {
+	for _, name := range []string{"public", "tenant_42", "_archive", "T1"} {
+		if err := ValidateSchemaName(name); err != nil {
+			t.Errorf("%q 应为合法名称: %v", name, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"1tenant",
+		"tenant-a",
+		`tenant"; DROP TABLE users; --`,
+		"public, pg_catalog",
+		"tenant a",
+		"租户",
+		strings.Repeat("a", 64),
+	}
+	for _, name := range invalid {
+		if err := ValidateSchemaName(name); !errors.Is(err, ErrInvalidSchema) {
+			t.Errorf("%q 应为非法名称, 实际 %v", name, err)
+		}
+	}
+}
+
+func TestWithSchema_Errors(t *testing.T) {
+	db, err := New(testConfig())
+	if err != nil {
+		t.Fatalf("创建测试数据库失败: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	var count int64
+	err = db.WithSchema(ctx, `x"; DROP TABLE users`).Raw("SELECT 1").Scan(&count).Error
+	if !errors.Is(err, ErrInvalidSchema) || !IsValidationError(err) {
+		t.Errorf("期望 ErrInvalidSchema 校验错误, 实际 %v", err)
+	}
+
+	err = db.WithSchema(ctx, "tenant_a").Raw("SELECT 1").Scan(&count).Error
+	if !errors.Is(err, ErrSchemaUnsupported) {
+		t.Errorf("SQLite 应返回 ErrSchemaUnsupported, 实际 %v", err)
+	}
+
+	// 错误不影响其他实例
+	if err := db.WithContext(ctx).Raw("SELECT 1").Scan(&count).Error; err != nil {
+		t.Errorf("其他查询不应受影响: %v", err)
+	}
+}
+
+// postgresTestDatabase 连接 GOKIT_TEST_POSTGRES_HOST 指定的 PostgreSQL，未设置时跳过
+func postgresTestDatabase(t *testing.T) *Database {
+	t.Helper()
+	host := os.Getenv("GOKIT_TEST_POSTGRES_HOST")
+	if host == "" {
+		t.Skip("未设置 GOKIT_TEST_POSTGRES_HOST，跳过 PostgreSQL 测试")
+	}
+	env := func(key, fallback string) string {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+		return fallback
+	}
+	port, _ := strconv.Atoi(env("GOKIT_TEST_POSTGRES_PORT", "5432"))
+	db, err := New(&Config{
+		Driver:       "postgres",
+		Host:         host,
+		Port:         port,
+		Username:     env("GOKIT_TEST_POSTGRES_USER", "postgres"),
+		Password:     env("GOKIT_TEST_POSTGRES_PASSWORD", "postgres"),
+		Database:     env("GOKIT_TEST_POSTGRES_DB", "postgres"),
+		SSLMode:      "disable",
+		LogLevel:     "silent",
+		MaxOpenConns: 2,
+	})
+	if err != nil {
+		t.Fatalf("连接 PostgreSQL 失败: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestWithSchema_Postgres(t *testing.T) {
+	db := postgresTestDatabase(t)
+	setup := context.Background()
+
+	for _, schema := range []string{"gokit_tenant_a", "gokit_tenant_b"} {
+		stmts := []string{
+			`DROP SCHEMA IF EXISTS ` + schema + ` CASCADE`,
+			`CREATE SCHEMA ` + schema,
+			`CREATE TABLE ` + schema + `.orders (name TEXT)`,
+			`INSERT INTO ` + schema + `.orders VALUES ('` + schema + `')`,
+		}
+		for _, stmt := range stmts {
+			if err := db.GetDB().WithContext(setup).Exec(stmt).Error; err != nil {
+				t.Fatalf("初始化 %s 失败: %v", schema, err)
+			}
+		}
+		t.Cleanup(func() { db.GetDB().Exec(`DROP SCHEMA IF EXISTS ` + schema + ` CASCADE`) })
+	}
+
+	queryTenant := func(ctx context.Context, schema string) string {
+		t.Helper()
+		var name string
+		if err := db.WithSchema(ctx, schema).Raw("SELECT name FROM orders").Scan(&name).Error; err != nil {
+			t.Fatalf("查询 %s 失败: %v", schema, err)
+		}
+		return name
+	}
+
+	ctxA, cancelA := context.WithCancel(setup)
+	ctxB, cancelB := context.WithCancel(setup)
+	if got := queryTenant(ctxA, "gokit_tenant_a"); got != "gokit_tenant_a" {
+		t.Errorf("期望查询 gokit_tenant_a, 实际 %s", got)
+	}
+	if got := queryTenant(ctxB, "gokit_tenant_b"); got != "gokit_tenant_b" {
+		t.Errorf("期望查询 gokit_tenant_b, 实际 %s", got)
+	}
+	cancelA()
+	cancelB()
+
+	// 请求结束后连接归还连接池，search_path 已重置
+	for i := 0; i < 4; i++ {
+		var path string
+		if err := db.GetDB().Raw("SHOW search_path").Scan(&path).Error; err != nil {
+			t.Fatal(err)
+		}
+		if path != `"$user", public` {
+			t.Errorf("连接池中的连接不应保留租户的 search_path: %s", path)
+		}
+	}
+
+	// 事务内使用 SET LOCAL
+	err := db.TransactionCtx(setup, func(ctx context.Context) error {
+		if got := queryTenant(ctx, "gokit_tenant_b"); got != "gokit_tenant_b" {
+			t.Errorf("事务内期望查询 gokit_tenant_b, 实际 %s", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("事务失败: %v", err)
+	}
+
+	err = db.WithSchema(setup, "gokit_tenant_a").Exec("SELECT 1").Error
+	if !errors.Is(err, ErrSchemaContext) {
+		t.Errorf("不可取消的 ctx 应返回 ErrSchemaContext, 实际 %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txContextKey struct{}
+
+// txEntry context 中保存的事务及其所属的数据库管理器
+type txEntry struct {
+	owner *Database
+	tx    *gorm.DB
+}
+
+// TxFromContext 返回 TransactionCtx 存入 context 的事务
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	entry, ok := ctx.Value(txContextKey{}).(txEntry)
+	return entry.tx, ok && entry.tx != nil
+}
+
+// TransactionCtx 在事务中执行 fn，事务保存在传给 fn 的 ctx 中
+//
+// fn 内部通过 WithContext(ctx) 或 SessionFromContext(ctx, db) 获取的GORM实例会自动加入该事务，
+// 没有事务时回退到连接池，仓储层代码无需区分两种情况（工作单元模式）。
+// fn 返回错误或 panic 时回滚，否则提交。
+// ctx 中已有本实例的事务时，嵌套调用使用保存点，内层失败只回滚内层的写入。
+//
+// 示例:
+//
+//	err := db.TransactionCtx(ctx, func(ctx context.Context) error {
+//	    if err := orderRepo.Create(ctx, order); err != nil { // 内部使用 db.WithContext(ctx)
+//	        return err
+//	    }
+//	    return stockRepo.Decrease(ctx, order.Items)
+//	})
+func (d *Database) TransactionCtx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if tx, ok := d.txFromContext(ctx); ok {
+		return tx.Transaction(func(nested *gorm.DB) error {
+			return fn(d.withTx(ctx, nested))
+		})
+	}
+
+	if d.closing.Load() {
+		return ErrDatabaseClosing
+	}
+
+	d.mu.RLock()
+	base := d.db
+	d.mu.RUnlock()
+
+	run := func(tx *gorm.DB) error {
+		return fn(d.withTx(ctx, tx))
+	}
+	if d.leaks != nil {
+		return d.leaks.transaction(base.WithContext(ctx), d.leaks.callers(1), run)
+	}
+	return base.WithContext(ctx).Transaction(run)
+}
+
+// withTx 将事务存入 context
+func (d *Database) withTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txContextKey{}, txEntry{owner: d, tx: tx})
+}
+
+// txFromContext 返回 ctx 中属于本实例的事务，其他实例的事务不会被加入
+func (d *Database) txFromContext(ctx context.Context) (*gorm.DB, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	entry, ok := ctx.Value(txContextKey{}).(txEntry)
+	if !ok || entry.owner != d || entry.tx == nil {
+		return nil, false
+	}
+	return entry.tx, true
+}
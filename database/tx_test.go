@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type txAccount struct {
+	ID   uint
+	Name string
+}
+
+func newTxTestDatabase(t *testing.T) *Database {
+	t.Helper()
+	db := newFileTestDatabase(t)
+	if err := db.AutoMigrate(&txAccount{}); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	return db
+}
+
+// createAccount 模拟仓储层函数，只依赖 ctx
+func createAccount(ctx context.Context, db *Database, name string) error {
+	return db.WithContext(ctx).Create(&txAccount{Name: name}).Error
+}
+
+func countAccounts(t *testing.T, db *Database) int64 {
+	t.Helper()
+	var count int64
+	if err := db.GetDB().Model(&txAccount{}).Count(&count).Error; err != nil {
+		t.Fatalf("统计失败: %v", err)
+	}
+	return count
+}
+
+func TestTransactionCtxRollbackNestedWrites(t *testing.T) {
+	db := newTxTestDatabase(t)
+	errAbort := errors.New("abort")
+
+	err := db.TransactionCtx(context.Background(), func(ctx context.Context) error {
+		if _, ok := TxFromContext(ctx); !ok {
+			t.Error("期望 ctx 中存在事务")
+		}
+		if err := createAccount(ctx, db, "outer"); err != nil {
+			return err
+		}
+		if err := SessionFromContext(ctx, db).Create(&txAccount{Name: "session"}).Error; err != nil {
+			return err
+		}
+		// 嵌套的服务函数加入同一事务
+		if err := db.TransactionCtx(ctx, func(ctx context.Context) error {
+			return createAccount(ctx, db, "nested")
+		}); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("期望返回 fn 的错误, 实际 %v", err)
+	}
+	if count := countAccounts(t, db); count != 0 {
+		t.Errorf("期望回滚全部写入, 实际剩余 %d 条", count)
+	}
+}
+
+func TestTransactionCtxCommit(t *testing.T) {
+	db := newTxTestDatabase(t)
+
+	err := db.TransactionCtx(context.Background(), func(ctx context.Context) error {
+		if err := createAccount(ctx, db, "a"); err != nil {
+			return err
+		}
+		return createAccount(ctx, db, "b")
+	})
+	if err != nil {
+		t.Fatalf("事务失败: %v", err)
+	}
+	if count := countAccounts(t, db); count != 2 {
+		t.Errorf("期望提交 2 条, 实际 %d", count)
+	}
+}
+
+func TestTransactionCtxNestedSavepoint(t *testing.T) {
+	db := newTxTestDatabase(t)
+	errInner := errors.New("inner")
+
+	err := db.TransactionCtx(context.Background(), func(ctx context.Context) error {
+		if err := createAccount(ctx, db, "outer"); err != nil {
+			return err
+		}
+		innerErr := db.TransactionCtx(ctx, func(ctx context.Context) error {
+			if err := createAccount(ctx, db, "inner"); err != nil {
+				return err
+			}
+			return errInner
+		})
+		if !errors.Is(innerErr, errInner) {
+			t.Errorf("期望内层返回错误, 实际 %v", innerErr)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("外层事务失败: %v", err)
+	}
+
+	var names []string
+	db.GetDB().Model(&txAccount{}).Pluck("name", &names)
+	if len(names) != 1 || names[0] != "outer" {
+		t.Errorf("期望只保留外层写入, 实际 %v", names)
+	}
+}
+
+func TestWithContextWithoutTransaction(t *testing.T) {
+	db := newTxTestDatabase(t)
+
+	if _, ok := TxFromContext(context.Background()); ok {
+		t.Error("普通 ctx 不应包含事务")
+	}
+	if err := createAccount(context.Background(), db, "pool"); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	if count := countAccounts(t, db); count != 1 {
+		t.Errorf("期望直接写入连接池, 实际 %d", count)
+	}
+
+	// 其他实例的事务不会被加入
+	other := newTxTestDatabase(t)
+	err := other.TransactionCtx(context.Background(), func(ctx context.Context) error {
+		if err := createAccount(ctx, db, "outside"); err != nil {
+			return err
+		}
+		return errors.New("rollback other")
+	})
+	if err == nil {
+		t.Fatal("期望返回错误")
+	}
+	if count := countAccounts(t, db); count != 2 {
+		t.Errorf("其他实例回滚不应影响本实例, 实际 %d", count)
+	}
+}
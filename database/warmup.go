@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// warmUpPing 预热时检查单个连接，测试中可替换
+var warmUpPing = func(ctx context.Context, conn *sql.Conn) error {
+	return conn.PingContext(ctx)
+}
+
+// WarmUp 并发预先建立最多 n 个连接（不超过 MaxOpenConns），避免部署后首批请求集中建连
+//
+// 每个连接通过 Ping 确认可用，并在全部建立完成后才归还连接池，保证得到的是不同的连接；
+// 归还后超出 MaxIdleConns 的连接会被连接池关闭。单个连接的超时时间为 Config.WarmUpTimeout。
+// 有连接建立失败时返回 ErrorTypeConnection 类型的 DatabaseError，
+// 上下文中的 succeeded / requested 记录成功数与请求数。
+//
+// 示例:
+//
+//	if err := db.WarmUp(ctx, 20); err != nil {
+//	    log.Printf("预热连接未完全成功: %v", err)
+//	}
+func (d *Database) WarmUp(ctx context.Context, n int) error {
+	if n <= 0 {
+		return nil
+	}
+
+	d.mu.RLock()
+	base := d.db
+	timeout := d.config.WarmUpTimeout
+	d.mu.RUnlock()
+	if timeout <= 0 {
+		timeout = DefaultWarmUpTimeout
+	}
+
+	sqlDB, err := base.DB()
+	if err != nil {
+		return NewDatabaseError(ErrorTypeConnection, "warm_up", err)
+	}
+	if maxOpen := sqlDB.Stats().MaxOpenConnections; maxOpen > 0 && n > maxOpen {
+		n = maxOpen
+	}
+
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		conns = make([]*sql.Conn, 0, n)
+		errs  []error
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, err := warmUpConn(ctx, sqlDB, timeout)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			conns = append(conns, conn)
+		}()
+	}
+	wg.Wait()
+
+	// 全部建立后再归还，连接池保留为空闲连接
+	for _, conn := range conns {
+		conn.Close()
+	}
+
+	if len(errs) > 0 {
+		return NewDatabaseError(ErrorTypeConnection, "warm_up",
+			fmt.Errorf("成功预热 %d/%d 个连接: %w", len(conns), n, errors.Join(errs...))).
+			WithContext("succeeded", len(conns)).
+			WithContext("requested", n)
+	}
+	return nil
+}
+
+// warmUpConn 在超时时间内从连接池取得一个连接并确认可用
+func warmUpConn(ctx context.Context, sqlDB *sql.DB, timeout time.Duration) (*sql.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := warmUpPing(ctx, conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// messageLogger 记录日志消息的 SimpleLogger
+type messageLogger struct {
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (m *messageLogger) Info(msg string, fields ...interface{})  {}
+func (m *messageLogger) Error(msg string, fields ...interface{}) {}
+func (m *messageLogger) Warn(msg string, fields ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.warnings = append(m.warnings, msg)
+}
+
+// failEveryOtherPing 让每隔一次的预热 Ping 失败
+func failEveryOtherPing(t *testing.T) {
+	t.Helper()
+
+	var calls atomic.Int64
+	original := warmUpPing
+	warmUpPing = func(ctx context.Context, conn *sql.Conn) error {
+		if calls.Add(1)%2 == 0 {
+			return errors.New("ping refused")
+		}
+		return conn.PingContext(ctx)
+	}
+	t.Cleanup(func() { warmUpPing = original })
+}
+
+func warmUpConfig(t *testing.T, maxIdle, maxOpen int) *Config {
+	t.Helper()
+
+	config := testConfig()
+	config.Database = filepath.Join(t.TempDir(), "warmup.db")
+	config.LogLevel = "silent"
+	config.MaxIdleConns = maxIdle
+	config.MaxOpenConns = maxOpen
+	return config
+}
+
+func TestWarmUp(t *testing.T) {
+	db, err := New(warmUpConfig(t, 5, 10))
+	if err != nil {
+		t.Fatalf("创建数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.WarmUp(context.Background(), 5); err != nil {
+		t.Fatalf("预热失败: %v", err)
+	}
+	stats := db.Stats()
+	if stats.IdleConnections != 5 || stats.OpenConnections != 5 {
+		t.Errorf("期望 5 个空闲连接, 实际 idle=%d open=%d", stats.IdleConnections, stats.OpenConnections)
+	}
+}
+
+func TestWarmUp_CappedByMaxOpenConns(t *testing.T) {
+	db, err := New(warmUpConfig(t, 3, 3))
+	if err != nil {
+		t.Fatalf("创建数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.WarmUp(context.Background(), 50); err != nil {
+		t.Fatalf("预热失败: %v", err)
+	}
+	if idle := db.Stats().IdleConnections; idle != 3 {
+		t.Errorf("期望 3 个空闲连接, 实际 %d", idle)
+	}
+}
+
+func TestWarmUp_PartialFailure(t *testing.T) {
+	failEveryOtherPing(t)
+
+	db, err := New(warmUpConfig(t, 4, 4))
+	if err != nil {
+		t.Fatalf("创建数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	err = db.WarmUp(context.Background(), 4)
+	if err == nil {
+		t.Fatal("期望预热返回错误")
+	}
+	if !IsConnectionError(err) {
+		t.Errorf("期望连接错误, 实际 %v", err)
+	}
+	var dbErr *DatabaseError
+	if !errors.As(err, &dbErr) {
+		t.Fatalf("期望 DatabaseError, 实际 %T", err)
+	}
+	if dbErr.Context["succeeded"] != 2 || dbErr.Context["requested"] != 4 {
+		t.Errorf("上下文不正确: %v", dbErr.Context)
+	}
+	if !strings.Contains(err.Error(), "成功预热 2/4 个连接") || !strings.Contains(err.Error(), "ping refused") {
+		t.Errorf("错误信息不正确: %v", err)
+	}
+}
+
+func TestNew_WarmConnections(t *testing.T) {
+	config := warmUpConfig(t, 6, 10)
+	config.WarmConnections = 6
+
+	db, err := New(config)
+	if err != nil {
+		t.Fatalf("创建数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	if idle := db.Stats().IdleConnections; idle != 6 {
+		t.Errorf("期望 6 个空闲连接, 实际 %d", idle)
+	}
+}
+
+func TestNew_WarmConnectionsPartialFailureLogsWarning(t *testing.T) {
+	failEveryOtherPing(t)
+
+	logs := &messageLogger{}
+	config := warmUpConfig(t, 4, 4)
+	config.WarmConnections = 4
+	config.SetCustomLogger(logs, "warn")
+
+	db, err := New(config)
+	if err != nil {
+		t.Fatalf("部分预热失败不应导致启动失败: %v", err)
+	}
+	defer db.Close()
+
+	logs.mu.Lock()
+	defer logs.mu.Unlock()
+	if len(logs.warnings) != 1 || !strings.Contains(logs.warnings[0], "预热连接未完全成功") {
+		t.Errorf("期望记录一条预热警告, 实际 %v", logs.warnings)
+	}
+}
+
+func TestNew_WarmUpStrict(t *testing.T) {
+	failEveryOtherPing(t)
+
+	config := warmUpConfig(t, 4, 4)
+	config.WarmConnections = 4
+	config.WarmUpStrict = true
+
+	db, err := New(config)
+	if err == nil {
+		db.Close()
+		t.Fatal("严格模式下预热失败应返回错误")
+	}
+	if !strings.Contains(err.Error(), "预热连接失败") {
+		t.Errorf("错误信息不正确: %v", err)
+	}
+}
+
+func TestConfig_ValidateWarmUp(t *testing.T) {
+	for name, configure := range map[string]func(*Config){
+		"负数预热连接数": func(c *Config) { c.WarmConnections = -1 },
+		"负数预热超时":  func(c *Config) { c.WarmUpTimeout = -1 },
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := testConfig()
+			configure(config)
+			if err := config.Validate(); err == nil {
+				t.Errorf("%s: 期望验证失败", name)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Package analyzer 检查传给 errors 包构造函数的消息参数是否为常量字符串
+//
+// 动态数据应通过 errors.NewKV 的键值对参数传递，而不是插入消息，
+// 避免日志聚合失效和敏感信息泄露。Newf、Wrapf 传入格式化参数同样会被报告。
+//
+// 与 go vet 一起使用:
+//
+//	go install github.com/tsopia/go-kit/errors/analyzer/cmd/errmsgcheck@latest
+//	go vet -vettool=$(which errmsgcheck) ./...
+package analyzer
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// ErrorsPkgPath errors 包的导入路径
+const ErrorsPkgPath = "github.com/tsopia/go-kit/errors"
+
+// messageArgIndex 需要检查的函数及其消息参数的位置
+var messageArgIndex = map[string]int{
+	"New":             1,
+	"Newf":            1,
+	"NewKV":           1,
+	"NewWithDetails":  1,
+	"Wrap":            2,
+	"Wrapf":           2,
+	"WrapKV":          2,
+	"WrapWithDetails": 2,
+	"InvalidParam":    0,
+	"NotFound":        0,
+	"Internal":        0,
+}
+
+// kvAlternative 格式化函数对应的键值对版本，传入格式化参数时建议改用
+var kvAlternative = map[string]string{
+	"Newf":  "NewKV",
+	"Wrapf": "WrapKV",
+}
+
+// Analyzer 报告传给 errors 构造函数的非常量消息，以及 Newf、Wrapf 的格式化参数
+var Analyzer = &analysis.Analyzer{
+	Name:     "errmsgcheck",
+	Doc:      "检查传给 go-kit errors 构造函数的消息是否为常量，动态数据应使用 NewKV/WrapKV 的键值对参数",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	inspect.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+
+		name, ok := errorsFunc(pass.TypesInfo, call)
+		if !ok {
+			return
+		}
+		index, ok := messageArgIndex[name]
+		// 展开的切片（message...）无法判断，不报告
+		if !ok || index >= len(call.Args) || call.Ellipsis.IsValid() {
+			return
+		}
+
+		arg := call.Args[index]
+		if tv, ok := pass.TypesInfo.Types[arg]; !ok || tv.Value == nil {
+			pass.Reportf(arg.Pos(), "errors.%s 的消息参数必须是常量字符串，动态数据请使用 errors.NewKV 的键值对参数", name)
+			return
+		}
+		if kv, ok := kvAlternative[name]; ok && len(call.Args) > index+1 {
+			pass.Reportf(call.Args[index+1].Pos(), "errors.%s 不应通过格式化参数插入动态数据，请使用 errors.%s 的键值对参数", name, kv)
+		}
+	})
+
+	return nil, nil
+}
+
+// errorsFunc 判断调用是否为 errors 包的包级函数，返回函数名
+func errorsFunc(info *types.Info, call *ast.CallExpr) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	fn, ok := info.Uses[sel.Sel].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != ErrorsPkgPath {
+		return "", false
+	}
+	if sig, ok := fn.Type().(*types.Signature); !ok || sig.Recv() != nil {
+		return "", false
+	}
+	return fn.Name(), true
+}
@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// TestAnalyzer 对比 testdata/src/a 中 // want 注释标注的诊断，
+// 导入的 errors 包使用 testdata 中的替身
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "a")
+}
+
+// TestVettool 通过 go vet -vettool 运行完整的检查流程
+func TestVettool(t *testing.T) {
+	if testing.Short() {
+		t.Skip("跳过需要编译的集成测试")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("找不到 go 命令")
+	}
+
+	tool := filepath.Join(t.TempDir(), "errmsgcheck")
+	build := exec.Command(gobin, "build", "-o", tool, "./cmd/errmsgcheck")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("编译 errmsgcheck 失败: %v\n%s", err, out)
+	}
+
+	// testdata 位于模块内，其中的包导入的是真实的 errors 包
+	vet := exec.Command(gobin, "vet", "-vettool="+tool, "./testdata/src/a")
+	out, err := vet.CombinedOutput()
+	if err == nil {
+		t.Fatalf("期望 go vet 报告问题, 输出:\n%s", out)
+	}
+
+	source, err := os.ReadFile(filepath.Join("testdata", "src", "a", "a.go"))
+	if err != nil {
+		t.Fatalf("读取 testdata 失败: %v", err)
+	}
+	for i, line := range strings.Split(string(source), "\n") {
+		if strings.Contains(line, "// want") && !strings.Contains(string(out), "a.go:"+strconv.Itoa(i+1)+":") {
+			t.Errorf("期望 go vet 报告第 %d 行, 输出:\n%s", i+1, out)
+		}
+	}
+}
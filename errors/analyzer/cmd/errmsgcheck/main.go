@@ -0,0 +1,16 @@
+// errmsgcheck 检查传给 go-kit errors 构造函数的消息是否为常量
+//
+// 用法:
+//
+//	go vet -vettool=$(which errmsgcheck) ./...
+package main
+
+import (
+	"github.com/tsopia/go-kit/errors/analyzer"
+
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+func main() {
+	unitchecker.Main(analyzer.Analyzer)
+}
@@ -0,0 +1,39 @@
+package a
+
+import (
+	"fmt"
+
+	"github.com/tsopia/go-kit/errors"
+)
+
+const notFoundMessage = "user not found"
+
+func constant(err error, email string) {
+	_ = errors.New(errors.CodeUserNotFound)
+	_ = errors.New(errors.CodeUserNotFound, "user not found")
+	_ = errors.New(errors.CodeUserNotFound, notFoundMessage)
+	_ = errors.New(errors.CodeUserNotFound, "user "+"not found")
+	_ = errors.Newf(errors.CodeUserNotFound, "user not found")
+	_ = errors.NewKV(errors.CodeUserNotFound, "user not found", "user_email", email)
+	_ = errors.WrapKV(err, errors.CodeUserNotFound, "user not found", "user_email", email)
+	_ = errors.NotFound("user not found")
+}
+
+func dynamic(err error, email string) {
+	_ = errors.New(errors.CodeUserNotFound, "user not found: "+email)          // want `errors\.New 的消息参数必须是常量字符串`
+	_ = errors.New(errors.CodeUserNotFound, fmt.Sprintf("user %s", email))     // want `errors\.New 的消息参数必须是常量字符串`
+	_ = errors.Newf(errors.CodeUserNotFound, email)                            // want `errors\.Newf 的消息参数`
+	_ = errors.Newf(errors.CodeUserNotFound, "user %s not found", email)       // want `errors\.Newf 不应通过格式化参数插入动态数据，请使用 errors\.NewKV`
+	_ = errors.Wrapf(err, errors.CodeUserNotFound, "lookup %s failed", email)  // want `errors\.Wrapf 不应通过格式化参数插入动态数据，请使用 errors\.WrapKV`
+	_ = errors.NewKV(errors.CodeUserNotFound, email)                           // want `errors\.NewKV 的消息参数`
+	_ = errors.Wrap(err, errors.CodeUserNotFound, err.Error())                 // want `errors\.Wrap 的消息参数`
+	_ = errors.WrapWithDetails(err, errors.CodeUserNotFound, email, "details") // want `errors\.WrapWithDetails 的消息参数`
+	_ = errors.NotFound(email)                                                 // want `errors\.NotFound 的消息参数`
+}
+
+func ignored(messages []string, email string) {
+	// 展开的切片无法静态判断
+	_ = errors.New(errors.CodeUserNotFound, messages...)
+	// 只检查包级函数
+	_ = errors.New(errors.CodeUserNotFound).WithMessage(email)
+}
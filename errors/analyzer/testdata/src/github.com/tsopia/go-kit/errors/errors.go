@@ -0,0 +1,29 @@
+// Package errors 测试用的 go-kit errors 包替身，只保留被检查函数的签名
+package errors
+
+type ErrorCode struct{ Code int }
+
+var CodeUserNotFound = ErrorCode{Code: 404}
+
+type Error struct{ Message string }
+
+func (e *Error) Error() string                     { return e.Message }
+func (e *Error) WithMessage(message string) *Error { e.Message = message; return e }
+
+func New(code ErrorCode, message ...string) *Error                         { return &Error{} }
+func NewWithDetails(code ErrorCode, message string, details string) *Error { return &Error{} }
+func Newf(code ErrorCode, format string, args ...interface{}) *Error       { return &Error{} }
+func NewKV(code ErrorCode, message string, keyvals ...interface{}) *Error  { return &Error{} }
+func Wrap(err error, code ErrorCode, message ...string) *Error             { return &Error{} }
+func Wrapf(err error, code ErrorCode, format string, args ...interface{}) *Error {
+	return &Error{}
+}
+func WrapKV(err error, code ErrorCode, message string, keyvals ...interface{}) *Error {
+	return &Error{}
+}
+func WrapWithDetails(err error, code ErrorCode, message string, details string) *Error {
+	return &Error{}
+}
+func InvalidParam(message ...string) *Error { return &Error{} }
+func NotFound(message ...string) *Error     { return &Error{} }
+func Internal(message ...string) *Error     { return &Error{} }
@@ -0,0 +1,72 @@
+package errors
+
+// 错误分类，用于指标和日志按类别聚合错误，无需枚举每个错误码
+const (
+	// CategoryClient 调用方的问题（参数、权限、业务规则），重试同样的请求不会成功
+	CategoryClient = "client"
+	// CategoryServer 本服务的问题（内部错误、数据库错误）
+	CategoryServer = "server"
+	// CategoryExternal 依赖的外部服务或网络的问题
+	CategoryExternal = "external"
+)
+
+// codeRange 错误码区间及其分类，闭区间
+type codeRange struct {
+	min, max int
+	category string
+}
+
+// codeRanges 错误码区间是分类的唯一依据，自定义错误码应落在对应区间内:
+//
+//	1000        系统内部错误          server
+//	1001-1999   请求错误（参数、权限）  client
+//	2000-2999   业务错误              client
+//	3000-3999   数据库错误            server
+//	4000-4999   外部服务错误          external
+//
+// 不在任何区间内的错误码（例如 StringToCode 未找到时的 9999）按 server 处理。
+var codeRanges = []codeRange{
+	{1000, 1000, CategoryServer},
+	{1001, 1999, CategoryClient},
+	{2000, 2999, CategoryClient},
+	{3000, 3999, CategoryServer},
+	{4000, 4999, CategoryExternal},
+}
+
+// Category 按错误码的数值区间返回分类：client、server 或 external
+func (ec ErrorCode) Category() string {
+	for _, r := range codeRanges {
+		if ec.Code >= r.min && ec.Code <= r.max {
+			return r.category
+		}
+	}
+	return CategoryServer
+}
+
+// Category 返回错误的分类，err 为 nil 时返回空字符串
+// 不是本包错误的 err 按 GetCode 的规则视为 CodeInternalServer，即 server
+//
+// 示例:
+//
+//	requestErrors.WithLabelValues(errors.Category(err)).Inc()
+func Category(err error) string {
+	if err == nil {
+		return ""
+	}
+	return GetCode(err).Category()
+}
+
+// IsClientError 检查错误是否由调用方引起（对应 HTTP 4xx）
+func IsClientError(err error) bool {
+	return Category(err) == CategoryClient
+}
+
+// IsServerError 检查错误是否为本服务的问题（对应 HTTP 5xx），外部服务错误使用 IsExternalError
+func IsServerError(err error) bool {
+	return Category(err) == CategoryServer
+}
+
+// IsExternalError 检查错误是否来自外部服务或网络
+func IsExternalError(err error) bool {
+	return Category(err) == CategoryExternal
+}
@@ -0,0 +1,83 @@
+package errors
+
+import (
+	stderrors "errors"
+	"testing"
+)
+
+func TestErrorCodeCategory(t *testing.T) {
+	want := map[string]string{
+		"INTERNAL_SERVER_ERROR":  CategoryServer,
+		"INVALID_PARAM":          CategoryClient,
+		"NOT_FOUND":              CategoryClient,
+		"UNAUTHORIZED":           CategoryClient,
+		"FORBIDDEN":              CategoryClient,
+		"CONFLICT":               CategoryClient,
+		"TOO_MANY_REQUESTS":      CategoryClient,
+		"USER_NOT_FOUND":         CategoryClient,
+		"USER_EXISTS":            CategoryClient,
+		"INVALID_PASSWORD":       CategoryClient,
+		"TOKEN_EXPIRED":          CategoryClient,
+		"TOKEN_INVALID":          CategoryClient,
+		"DATABASE_ERROR":         CategoryServer,
+		"RECORD_NOT_FOUND":       CategoryServer,
+		"DUPLICATE_KEY":          CategoryServer,
+		"FOREIGN_KEY_VIOLATION":  CategoryServer,
+		"EXTERNAL_SERVICE_ERROR": CategoryExternal,
+		"NETWORK_ERROR":          CategoryExternal,
+		"TIMEOUT_ERROR":          CategoryExternal,
+	}
+	// 所有预定义错误码都有明确的分类
+	for name, code := range codeMap {
+		expected, ok := want[name]
+		if !ok {
+			t.Errorf("缺少错误码 %s 的期望分类", name)
+			continue
+		}
+		if got := code.Category(); got != expected {
+			t.Errorf("%s(%d).Category() = %q, 期望 %q", name, code.Code, got, expected)
+		}
+	}
+
+	custom := map[int]string{
+		1500: CategoryClient,
+		2999: CategoryClient,
+		3500: CategoryServer,
+		4999: CategoryExternal,
+		9999: CategoryServer,
+		0:    CategoryServer,
+	}
+	for code, expected := range custom {
+		if got := NewErrorCode(code, "CUSTOM").Category(); got != expected {
+			t.Errorf("自定义错误码 %d 的分类 = %q, 期望 %q", code, got, expected)
+		}
+	}
+}
+
+func TestCategoryHelpers(t *testing.T) {
+	tests := []struct {
+		name                     string
+		err                      error
+		category                 string
+		client, server, external bool
+	}{
+		{"nil", nil, "", false, false, false},
+		{"invalid param", InvalidParam("bad id"), CategoryClient, true, false, false},
+		{"wrapped business", Wrap(stderrors.New("duplicate"), CodeUserExists), CategoryClient, true, false, false},
+		{"database", New(CodeDuplicateKey), CategoryServer, false, true, false},
+		{"timeout", Timeout(), CategoryExternal, false, false, true},
+		{"plain error", stderrors.New("boom"), CategoryServer, false, true, false},
+		{"wrap keeps outer code", Wrap(New(CodeNetworkError), CodeInvalidParam), CategoryClient, true, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Category(tt.err); got != tt.category {
+				t.Errorf("Category() = %q, 期望 %q", got, tt.category)
+			}
+			if IsClientError(tt.err) != tt.client || IsServerError(tt.err) != tt.server || IsExternalError(tt.err) != tt.external {
+				t.Errorf("client/server/external = %v/%v/%v, 期望 %v/%v/%v",
+					IsClientError(tt.err), IsServerError(tt.err), IsExternalError(tt.err), tt.client, tt.server, tt.external)
+			}
+		})
+	}
+}
@@ -160,6 +160,10 @@ type Error struct {
 	Context map[string]interface{} `json:"context,omitempty"`
 	Stack   string                 `json:"stack,omitempty"`
 	Cause   error                  `json:"-"`
+
+	// timeout、temporary 由 Timeout、Temporary 构造函数预设，见 timeout.go
+	timeout   bool
+	temporary bool
 }
 
 // Error 实现error接口
@@ -184,25 +188,41 @@ func (e *Error) WithStack() *Error {
 	return e
 }
 
-// WithContext 添加上下文信息
+// WithContext 返回添加了上下文信息的副本，原错误不受影响
+//
+// 错误可能被缓存或在多个协程间共享，因此不修改原错误；链式调用的写法不变:
+//
+//	err := errors.New(errors.CodeNotFound).WithContext("user_id", id).WithContext("tenant", tenant)
+//
+// 单独调用时需要使用返回值: err = err.WithContext("key", value)
 func (e *Error) WithContext(key string, value interface{}) *Error {
-	if e.Context == nil {
-		e.Context = make(map[string]interface{})
+	derived := e.clone()
+	derived.Context = make(map[string]interface{}, len(e.Context)+1)
+	for k, v := range e.Context {
+		derived.Context[k] = v
 	}
-	e.Context[key] = value
-	return e
+	derived.Context[key] = value
+	return derived
 }
 
-// WithDetails 添加详细信息
+// WithDetails 返回设置了详细信息的副本，原错误不受影响
 func (e *Error) WithDetails(details string) *Error {
-	e.Details = details
-	return e
+	derived := e.clone()
+	derived.Details = details
+	return derived
 }
 
-// WithMessage 设置自定义消息
+// WithMessage 返回设置了自定义消息的副本，原错误不受影响
 func (e *Error) WithMessage(message string) *Error {
-	e.Message = message
-	return e
+	derived := e.clone()
+	derived.Message = message
+	return derived
+}
+
+// clone 浅拷贝错误，Context 与原错误共享，修改前需要复制
+func (e *Error) clone() *Error {
+	derived := *e
+	return &derived
 }
 
 // New 创建新的错误
@@ -446,6 +466,25 @@ func GetContext(err error) map[string]interface{} {
 	return nil
 }
 
+// ContextValue 沿包装链查找上下文值，返回最外层包含该键的错误中的值
+//
+// 示例:
+//
+//	if id, ok := errors.ContextValue(err, "user_id"); ok {
+//	    log.Warn("用户请求失败", "user_id", id)
+//	}
+func ContextValue(err error, key string) (interface{}, bool) {
+	for err != nil {
+		if e, ok := err.(*Error); ok {
+			if value, ok := e.Context[key]; ok {
+				return value, true
+			}
+		}
+		err = stderrors.Unwrap(err)
+	}
+	return nil, false
+}
+
 // GetStack 获取堆栈信息
 func GetStack(err error) string {
 	if err == nil {
@@ -2,6 +2,8 @@ package errors
 
 import (
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
 )
 
@@ -755,8 +757,8 @@ func TestFluentInterface(t *testing.T) {
 
 	t.Run("重复调用WithContext", func(t *testing.T) {
 		err := New(CodeInvalidParam, "test")
-		err.WithContext("key", "value1")
-		err.WithContext("key", "value2")
+		err = err.WithContext("key", "value1")
+		err = err.WithContext("key", "value2")
 
 		if err.Context["key"] != "value2" {
 			t.Errorf("Expected context key=value2, got %v", err.Context["key"])
@@ -951,7 +953,7 @@ func TestEdgeCases(t *testing.T) {
 
 	t.Run("空上下文", func(t *testing.T) {
 		err := New(CodeInvalidParam, "test")
-		err.WithContext("", "value")
+		err = err.WithContext("", "value")
 		if err.Context[""] != "value" {
 			t.Error("Expected empty key to work in context")
 		}
@@ -959,7 +961,7 @@ func TestEdgeCases(t *testing.T) {
 
 	t.Run("nil上下文值", func(t *testing.T) {
 		err := New(CodeInvalidParam, "test")
-		err.WithContext("key", nil)
+		err = err.WithContext("key", nil)
 		if err.Context["key"] != nil {
 			t.Error("Expected nil value to work in context")
 		}
@@ -1007,6 +1009,21 @@ func BenchmarkWithStack(b *testing.B) {
 	}
 }
 
+func BenchmarkWithContext(b *testing.B) {
+	for _, n := range []int{3, 5} {
+		base := New(CodeInvalidParam, "test")
+		for i := 0; i < n-1; i++ {
+			base = base.WithContext(fmt.Sprintf("key%d", i), i)
+		}
+		b.Run(fmt.Sprintf("%d_keys", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				base.WithContext("last", i)
+			}
+		})
+	}
+}
+
 // === 并发安全测试 ===
 
 func TestConcurrency(t *testing.T) {
@@ -1037,7 +1054,7 @@ func TestConcurrency(t *testing.T) {
 			go func(id int) {
 				// 每个goroutine创建独立的错误实例
 				err := New(CodeInvalidParam, "test")
-				err.WithContext("key", id)
+				err = err.WithContext("key", id)
 
 				// 验证上下文设置成功
 				context := GetContext(err)
@@ -1053,3 +1070,68 @@ func TestConcurrency(t *testing.T) {
 		}
 	})
 }
+
+func TestDerivationIsImmutable(t *testing.T) {
+	base := New(CodeNotFound, "base").WithContext("user_id", 1)
+
+	derived := base.WithContext("tenant", "acme").WithDetails("details").WithMessage("derived")
+
+	if _, ok := base.Context["tenant"]; ok {
+		t.Error("WithContext should not modify the original error")
+	}
+	if base.Details != "" || base.Message != "base" {
+		t.Errorf("WithDetails/WithMessage should not modify the original error, got %q %q", base.Details, base.Message)
+	}
+	if derived.Context["user_id"] != 1 || derived.Context["tenant"] != "acme" {
+		t.Errorf("Expected derived context to include both keys, got %v", derived.Context)
+	}
+	if derived.Details != "details" || derived.Message != "derived" {
+		t.Errorf("Unexpected derived error: %+v", derived)
+	}
+}
+
+func TestConcurrentWithContextOnSharedError(t *testing.T) {
+	shared := New(CodeInvalidParam, "shared").WithContext("request_id", "r1")
+
+	const numGoroutines = 50
+	var wg sync.WaitGroup
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(2)
+		go func(id int) {
+			defer wg.Done()
+			derived := shared.WithContext("worker", id)
+			if GetContext(derived)["worker"] != id {
+				t.Errorf("Expected worker=%d in derived context", id)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for key, value := range GetContext(shared) {
+				_, _ = key, value
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(shared.Context) != 1 {
+		t.Errorf("Expected shared context unchanged, got %v", shared.Context)
+	}
+}
+
+func TestContextValue(t *testing.T) {
+	inner := New(CodeDatabaseError).WithContext("table", "users").WithContext("id", 1)
+	outer := Wrap(fmt.Errorf("query: %w", inner), CodeInternalServer).WithContext("id", 2)
+
+	if value, ok := ContextValue(outer, "id"); !ok || value != 2 {
+		t.Errorf("Expected outermost id=2, got %v %v", value, ok)
+	}
+	if value, ok := ContextValue(outer, "table"); !ok || value != "users" {
+		t.Errorf("Expected table=users from wrapped error, got %v %v", value, ok)
+	}
+	if _, ok := ContextValue(outer, "missing"); ok {
+		t.Error("Expected missing key not to be found")
+	}
+	if _, ok := ContextValue(nil, "id"); ok {
+		t.Error("Expected nil error not to have context")
+	}
+}
@@ -0,0 +1,206 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Format 实现 fmt.Formatter
+//
+//   - %s、%v: 与 Error() 相同，只包含错误码、消息和详情
+//   - %q: Error() 的带引号形式
+//   - %+v: 完整的错误链，依次输出错误码、消息、详情、上下文、堆栈，
+//     然后以 "caused by:" 递归输出原因的 %+v，与 pkg/errors 的约定一致
+//
+// 示例:
+//
+//	err := errors.Wrap(dbErr, errors.CodeDatabaseError, "查询订单失败").WithContext("order_id", id)
+//	log.Printf("%+v", err)
+//	// [DATABASE_ERROR] 查询订单失败
+//	//     context: order_id=42
+//	// caused by: sql: no rows in result set
+func (e *Error) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if f.Flag('+') {
+			e.writeVerbose(f)
+			return
+		}
+		io.WriteString(f, e.Error())
+	case 's':
+		io.WriteString(f, e.Error())
+	case 'q':
+		fmt.Fprintf(f, "%q", e.Error())
+	default:
+		fmt.Fprintf(f, "%%!%c(*errors.Error=%s)", verb, e.Error())
+	}
+}
+
+// writeVerbose 输出 %+v 格式的完整错误链
+func (e *Error) writeVerbose(w io.Writer) {
+	io.WriteString(w, e.Error())
+
+	if pairs := contextPairs(e.Context); len(pairs) > 0 {
+		fmt.Fprintf(w, "\n    context: %s", strings.Join(pairs, ", "))
+	}
+
+	if e.Stack != "" {
+		io.WriteString(w, "\n    stack:")
+		for _, line := range strings.Split(strings.TrimRight(e.Stack, "\n"), "\n") {
+			io.WriteString(w, "\n    "+line)
+		}
+	}
+
+	if e.Cause != nil {
+		// 原因实现 fmt.Formatter 时（例如本库的 Error 或 pkg/errors）同样输出完整信息
+		fmt.Fprintf(w, "\ncaused by: %+v", e.Cause)
+	}
+}
+
+// FormatOption 控制 Format 的输出
+type FormatOption func(*formatOptions)
+
+type formatOptions struct {
+	stack    bool
+	maxDepth int
+	collapse bool
+}
+
+// FormatWithoutStack 不输出堆栈
+func FormatWithoutStack() FormatOption {
+	return func(o *formatOptions) {
+		o.stack = false
+	}
+}
+
+// FormatMaxDepth 最多输出 depth 层错误，其余层以 "... N more" 表示；depth <= 0 时不限制
+func FormatMaxDepth(depth int) FormatOption {
+	return func(o *formatOptions) {
+		o.maxDepth = depth
+	}
+}
+
+// FormatCollapseRepeated 合并相邻的相同消息（例如同一错误码逐层 Wrap），合并后的行以 "(xN)" 结尾，
+// 各层的上下文仍列在合并后的行下面
+func FormatCollapseRepeated() FormatOption {
+	return func(o *formatOptions) {
+		o.collapse = true
+	}
+}
+
+// chainEntry Format 输出的一层错误
+type chainEntry struct {
+	message string
+	context []string
+	repeat  int
+}
+
+// Format 把错误链渲染为多行文本，便于在终端和日志查看器中阅读
+//
+// 每层错误一行，包括错误码、消息和详情，逐层缩进；该层的上下文按键排序列在下面，
+// 最后输出最内层捕获的堆栈。非本库的错误只输出消息，并去掉末尾与原因相同的部分
+// （例如 fmt.Errorf("query: %w", err) 只输出 "query"）。
+//
+// 示例:
+//
+//	fmt.Println(errors.Format(err))
+//	// [INTERNAL_SERVER_ERROR] 处理请求失败: GET /orders/42
+//	//     request_id=req-1
+//	//   caused by: [DATABASE_ERROR] 查询订单失败
+//	//       order_id=42
+//	//     caused by: connection refused
+//	// stack:
+//	//     goroutine 1 [running]:
+//	//     ...
+func Format(err error, opts ...FormatOption) string {
+	if err == nil {
+		return ""
+	}
+	o := formatOptions{stack: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var entries []chainEntry
+	var stack string
+	for err != nil {
+		cause := stderrors.Unwrap(err)
+		entry := chainEntry{message: chainMessage(err, cause), repeat: 1}
+		if e, ok := err.(*Error); ok {
+			entry.context = contextPairs(e.Context)
+			if e.Stack != "" {
+				stack = e.Stack
+			}
+		}
+
+		if last := len(entries) - 1; o.collapse && last >= 0 && entries[last].message == entry.message {
+			entries[last].repeat++
+			entries[last].context = append(entries[last].context, entry.context...)
+		} else {
+			entries = append(entries, entry)
+		}
+		err = cause
+	}
+
+	var b strings.Builder
+	for depth, entry := range entries {
+		indent := strings.Repeat("  ", depth)
+		if o.maxDepth > 0 && depth >= o.maxDepth {
+			fmt.Fprintf(&b, "\n%s... %d more", indent, len(entries)-depth)
+			break
+		}
+		if depth > 0 {
+			b.WriteString("\n" + indent + "caused by: ")
+		}
+		b.WriteString(entry.message)
+		if entry.repeat > 1 {
+			fmt.Fprintf(&b, " (x%d)", entry.repeat)
+		}
+		for _, pair := range entry.context {
+			b.WriteString("\n" + indent + "    " + pair)
+		}
+	}
+
+	if o.stack && stack != "" {
+		b.WriteString("\nstack:")
+		for _, line := range strings.Split(strings.TrimRight(stack, "\n"), "\n") {
+			b.WriteString("\n    " + line)
+		}
+	}
+	return b.String()
+}
+
+// chainMessage 返回错误链中一层的消息，非本库错误的消息通常以原因的消息结尾，去掉重复的部分
+func chainMessage(err, cause error) string {
+	if e, ok := err.(*Error); ok {
+		return e.Error()
+	}
+	message := err.Error()
+	if cause != nil {
+		if trimmed := strings.TrimSuffix(message, ": "+cause.Error()); trimmed != message {
+			return trimmed
+		}
+	}
+	return message
+}
+
+// contextPairs 按键排序返回 key=value 形式的上下文
+func contextPairs(context map[string]interface{}) []string {
+	if len(context) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(context))
+	for key := range context {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", key, context[key]))
+	}
+	return pairs
+}
@@ -0,0 +1,135 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFormatConcise(t *testing.T) {
+	root := stderrors.New("connection refused")
+	err := WrapWithDetails(root, CodeDatabaseError, "查询失败", "orders").WithContext("order_id", 42)
+
+	want := "[DATABASE_ERROR] 查询失败: orders"
+	for _, verb := range []string{"%v", "%s"} {
+		if got := fmt.Sprintf(verb, err); got != want {
+			t.Errorf("%s: expected %q, got %q", verb, want, got)
+		}
+	}
+	if got := fmt.Sprintf("%q", err); got != fmt.Sprintf("%q", want) {
+		t.Errorf("%%q: got %s", got)
+	}
+	// 嵌入 fmt.Errorf 时 %w 仍使用简洁形式
+	if got := fmt.Errorf("handler: %w", err).Error(); got != "handler: "+want {
+		t.Errorf("unexpected wrapped message %q", got)
+	}
+}
+
+func TestFormatVerboseChain(t *testing.T) {
+	root := stderrors.New("connection refused")
+	inner := Wrap(root, CodeDatabaseError, "查询订单失败").
+		WithContext("order_id", 42).
+		WithContext("attempt", 3)
+	outer := WrapWithDetails(inner, CodeInternalServer, "处理请求失败", "GET /orders/42")
+
+	got := fmt.Sprintf("%+v", outer)
+	want := "[INTERNAL_SERVER_ERROR] 处理请求失败: GET /orders/42\n" +
+		"caused by: [DATABASE_ERROR] 查询订单失败\n" +
+		"    context: attempt=3, order_id=42\n" +
+		"caused by: connection refused"
+	if got != want {
+		t.Errorf("unexpected %%+v output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatVerboseStack(t *testing.T) {
+	err := New(CodeInternalServer, "内部错误").WithStack()
+
+	got := fmt.Sprintf("%+v", err)
+	if !strings.HasPrefix(got, "[INTERNAL_SERVER_ERROR] 内部错误\n    stack:\n    goroutine ") {
+		t.Errorf("expected stack section, got:\n%s", got)
+	}
+	if !strings.Contains(got, "TestFormatVerboseStack") {
+		t.Errorf("expected test function in stack, got:\n%s", got)
+	}
+	if fmt.Sprintf("%v", err) != "[INTERNAL_SERVER_ERROR] 内部错误" {
+		t.Errorf("%%v should not include stack")
+	}
+}
+
+// threeLevelChain 每层都带上下文的三层错误链，最内层带固定的堆栈
+func threeLevelChain() *Error {
+	root := WrapWithDetails(stderrors.New("connection refused"), CodeDatabaseError, "查询订单失败", "orders").
+		WithContext("order_id", 42).
+		WithContext("attempt", 3)
+	root.Stack = "goroutine 1 [running]:\nmain.query()\n\t/app/db.go:10 +0x1d\n"
+
+	service := Wrap(root, CodeExternalServiceError, "加载订单失败").WithContext("tenant", "acme")
+	return Wrap(fmt.Errorf("handler: %w", service), CodeInternalServer, "处理请求失败").WithContext("request_id", "req-1")
+}
+
+func TestFormatChain(t *testing.T) {
+	err := threeLevelChain()
+
+	want := "[INTERNAL_SERVER_ERROR] 处理请求失败\n" +
+		"    request_id=req-1\n" +
+		"  caused by: handler\n" +
+		"    caused by: [EXTERNAL_SERVICE_ERROR] 加载订单失败\n" +
+		"        tenant=acme\n" +
+		"      caused by: [DATABASE_ERROR] 查询订单失败: orders\n" +
+		"          attempt=3\n" +
+		"          order_id=42\n" +
+		"        caused by: connection refused\n" +
+		"stack:\n" +
+		"    goroutine 1 [running]:\n" +
+		"    main.query()\n" +
+		"    \t/app/db.go:10 +0x1d"
+	if got := Format(err); got != want {
+		t.Errorf("unexpected Format output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatOptions(t *testing.T) {
+	err := threeLevelChain()
+
+	got := Format(err, FormatWithoutStack(), FormatMaxDepth(2))
+	want := "[INTERNAL_SERVER_ERROR] 处理请求失败\n" +
+		"    request_id=req-1\n" +
+		"  caused by: handler\n" +
+		"    ... 3 more"
+	if got != want {
+		t.Errorf("unexpected output with options:\n%s\nwant:\n%s", got, want)
+	}
+
+	repeated := Wrap(Wrap(New(CodeNotFound, "订单不存在").WithContext("id", 1), CodeNotFound, "订单不存在"), CodeNotFound, "订单不存在").
+		WithContext("tenant", "acme")
+	got = Format(repeated, FormatCollapseRepeated())
+	want = "[NOT_FOUND] 订单不存在 (x3)\n" +
+		"    tenant=acme\n" +
+		"    id=1"
+	if got != want {
+		t.Errorf("unexpected collapsed output:\n%s\nwant:\n%s", got, want)
+	}
+
+	if Format(nil) != "" {
+		t.Error("expected empty output for nil error")
+	}
+	if got := Format(stderrors.New("plain")); got != "plain" {
+		t.Errorf("expected plain message, got %q", got)
+	}
+}
+
+func TestFormatKeepsConciseVerbs(t *testing.T) {
+	err := threeLevelChain()
+
+	// %v、%s 与 Error() 一致，只包含最外层
+	for _, verb := range []string{"%v", "%s"} {
+		if got := fmt.Sprintf(verb, err); got != "[INTERNAL_SERVER_ERROR] 处理请求失败" {
+			t.Errorf("%s: unexpected output %q", verb, got)
+		}
+	}
+	if got := fmt.Sprintf("%+v", err); !strings.HasPrefix(got, "[INTERNAL_SERVER_ERROR] 处理请求失败\n    context: request_id=req-1\ncaused by: handler") {
+		t.Errorf("%%+v should stay verbose, got:\n%s", got)
+	}
+}
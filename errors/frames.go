@@ -0,0 +1,81 @@
+package errors
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Frame 解析后的堆栈帧
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// ParseStack 解析 runtime.Stack / debug.Stack 格式的堆栈文本
+//
+// 输入形如:
+//
+//	goroutine 1 [running]:
+//	main.handler(0x1)
+//		/app/main.go:42 +0x1d
+//
+// 只解析第一个 goroutine，无法识别的行会被跳过。Error.Stack 可以直接传入。
+func ParseStack(stack string) []Frame {
+	lines := strings.Split(stack, "\n")
+	frames := make([]Frame, 0, len(lines)/2)
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		if line == "" {
+			// 空行之后是其他 goroutine
+			if len(frames) > 0 {
+				break
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "goroutine ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+
+		frame := Frame{Function: frameFunction(line)}
+		if i+1 < len(lines) && strings.HasPrefix(lines[i+1], "\t") {
+			frame.File, frame.Line = frameLocation(lines[i+1])
+			i++
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+// frameFunction 去掉函数行末尾的参数列表，例如 "main.handler(0x1, 0x2)" -> "main.handler"
+func frameFunction(line string) string {
+	if strings.HasPrefix(line, "created by ") {
+		line = strings.TrimPrefix(line, "created by ")
+		if idx := strings.Index(line, " in goroutine "); idx >= 0 {
+			line = line[:idx]
+		}
+		return line
+	}
+	if idx := strings.LastIndexByte(line, '('); idx > 0 && strings.HasSuffix(line, ")") {
+		return line[:idx]
+	}
+	return line
+}
+
+// frameLocation 解析 "\t/path/file.go:42 +0x1d" 形式的位置行
+func frameLocation(line string) (string, int) {
+	line = strings.TrimSpace(line)
+	if idx := strings.LastIndex(line, " +0x"); idx >= 0 {
+		line = line[:idx]
+	}
+	colon := strings.LastIndexByte(line, ':')
+	if colon < 0 {
+		return line, 0
+	}
+	n, err := strconv.Atoi(line[colon+1:])
+	if err != nil {
+		return line, 0
+	}
+	return line[:colon], n
+}
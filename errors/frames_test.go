@@ -0,0 +1,56 @@
+package errors
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseStack(t *testing.T) {
+	stack := `goroutine 7 [running]:
+runtime/debug.Stack()
+	/usr/local/go/src/runtime/debug/stack.go:26 +0x5e
+main.(*Handler).ServeHTTP(0xc000010000, {0x1, 0x2})
+	/app/handler.go:42 +0x1d
+panic({0x6b2f40?, 0x7a1b20?})
+	/usr/local/go/src/runtime/panic.go:770 +0x132
+main.main()
+	/app/main.go:10 +0x25
+created by net/http.(*Server).Serve in goroutine 1
+	/usr/local/go/src/net/http/server.go:3285 +0x4b4
+
+goroutine 1 [chan receive]:
+main.other()
+	/app/other.go:5 +0x1
+`
+	want := []Frame{
+		{Function: "runtime/debug.Stack", File: "/usr/local/go/src/runtime/debug/stack.go", Line: 26},
+		{Function: "main.(*Handler).ServeHTTP", File: "/app/handler.go", Line: 42},
+		{Function: "panic", File: "/usr/local/go/src/runtime/panic.go", Line: 770},
+		{Function: "main.main", File: "/app/main.go", Line: 10},
+		{Function: "net/http.(*Server).Serve", File: "/usr/local/go/src/net/http/server.go", Line: 3285},
+	}
+	if got := ParseStack(stack); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseStack() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParseStackFromError(t *testing.T) {
+	err := New(CodeInternalServer).WithStack()
+	frames := ParseStack(err.Stack)
+	if len(frames) == 0 {
+		t.Fatal("expected frames from error stack")
+	}
+	found := false
+	for _, f := range frames {
+		if strings.HasSuffix(f.Function, "TestParseStackFromError") && f.Line > 0 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected test function in frames: %+v", frames)
+	}
+	if len(ParseStack("")) != 0 {
+		t.Error("expected no frames for empty stack")
+	}
+}
@@ -0,0 +1,226 @@
+// Package grpcstatus 在 errors.Error 与 gRPC 状态之间转换
+//
+// 独立为子包，只使用HTTP的服务不会引入gRPC依赖。
+package grpcstatus
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tsopia/go-kit/errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// Domain ErrorInfo 中标识本库错误的域
+	Domain = "github.com/tsopia/go-kit"
+
+	// ErrorInfo.Metadata 中使用的键
+	metadataCode    = "code"
+	metadataDetails = "details"
+	metadataContext = "context."
+)
+
+// registration 错误码对应的gRPC状态码
+type registration struct {
+	code     errors.ErrorCode
+	grpcCode codes.Code
+}
+
+var (
+	registryMu sync.RWMutex
+	// registry 数字错误码 -> 映射
+	registry = map[int]registration{}
+)
+
+func init() {
+	for code, grpcCode := range map[errors.ErrorCode]codes.Code{
+		errors.CodeInternalServer:       codes.Internal,
+		errors.CodeInvalidParam:         codes.InvalidArgument,
+		errors.CodeNotFound:             codes.NotFound,
+		errors.CodeUnauthorized:         codes.Unauthenticated,
+		errors.CodeForbidden:            codes.PermissionDenied,
+		errors.CodeConflict:             codes.Aborted,
+		errors.CodeTooManyRequests:      codes.ResourceExhausted,
+		errors.CodeUserNotFound:         codes.NotFound,
+		errors.CodeUserExists:           codes.AlreadyExists,
+		errors.CodeInvalidPassword:      codes.Unauthenticated,
+		errors.CodeTokenExpired:         codes.Unauthenticated,
+		errors.CodeTokenInvalid:         codes.Unauthenticated,
+		errors.CodeDatabaseError:        codes.Internal,
+		errors.CodeRecordNotFound:       codes.NotFound,
+		errors.CodeDuplicateKey:         codes.AlreadyExists,
+		errors.CodeForeignKeyViolation:  codes.FailedPrecondition,
+		errors.CodeExternalServiceError: codes.Unavailable,
+		errors.CodeNetworkError:         codes.Unavailable,
+		errors.CodeTimeoutError:         codes.DeadlineExceeded,
+	} {
+		Register(code, grpcCode)
+	}
+}
+
+// fallbackCodes 没有 ErrorInfo 的状态（例如其他服务或框架返回的）反向映射到的错误码
+var fallbackCodes = map[codes.Code]errors.ErrorCode{
+	codes.InvalidArgument:   errors.CodeInvalidParam,
+	codes.NotFound:          errors.CodeNotFound,
+	codes.Unauthenticated:   errors.CodeUnauthorized,
+	codes.PermissionDenied:  errors.CodeForbidden,
+	codes.AlreadyExists:     errors.CodeConflict,
+	codes.Aborted:           errors.CodeConflict,
+	codes.ResourceExhausted: errors.CodeTooManyRequests,
+	codes.DeadlineExceeded:  errors.CodeTimeoutError,
+	codes.Unavailable:       errors.CodeExternalServiceError,
+}
+
+// Register 注册自定义错误码对应的gRPC状态码，已注册的错误码会被覆盖
+//
+// 示例:
+//
+//	var CodeQuotaExceeded = errors.NewErrorCode(5001, "QUOTA_EXCEEDED", "配额不足")
+//
+//	func init() {
+//	    grpcstatus.Register(CodeQuotaExceeded, codes.ResourceExhausted)
+//	}
+func Register(code errors.ErrorCode, grpcCode codes.Code) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[code.Code] = registration{code: code, grpcCode: grpcCode}
+}
+
+// GRPCCode 返回错误码对应的gRPC状态码，未注册的错误码返回 codes.Unknown
+func GRPCCode(code errors.ErrorCode) codes.Code {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if reg, ok := registry[code.Code]; ok {
+		return reg.grpcCode
+	}
+	return codes.Unknown
+}
+
+// ToGRPCStatus 将错误转换为gRPC状态
+//
+//   - nil 返回 OK 状态
+//   - 错误链中有 *errors.Error 时，按 GRPCCode 映射状态码，消息为 GetMessage()，
+//     错误码名称、数字码、详情和上下文作为 errdetails.ErrorInfo 附加在状态详情中
+//   - 已经携带gRPC状态的错误原样返回其状态
+//   - context.DeadlineExceeded / context.Canceled 映射为对应状态码，其余为 codes.Unknown
+func ToGRPCStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+
+	var kitErr *errors.Error
+	if !stderrors.As(err, &kitErr) || kitErr == nil {
+		if st, ok := status.FromError(err); ok {
+			return st
+		}
+		switch {
+		case stderrors.Is(err, context.DeadlineExceeded):
+			return status.New(codes.DeadlineExceeded, err.Error())
+		case stderrors.Is(err, context.Canceled):
+			return status.New(codes.Canceled, err.Error())
+		}
+		return status.New(codes.Unknown, err.Error())
+	}
+
+	st := status.New(GRPCCode(kitErr.Code), kitErr.GetMessage())
+	withInfo, detailErr := st.WithDetails(errorInfo(kitErr))
+	if detailErr != nil {
+		return st
+	}
+	return withInfo
+}
+
+// errorInfo 构建携带错误码、详情和上下文的 ErrorInfo
+func errorInfo(e *errors.Error) *errdetails.ErrorInfo {
+	metadata := map[string]string{
+		metadataCode: strconv.Itoa(e.Code.Code),
+	}
+	if e.Details != "" {
+		metadata[metadataDetails] = e.Details
+	}
+	for key, value := range e.Context {
+		metadata[metadataContext+key] = fmt.Sprint(value)
+	}
+	return &errdetails.ErrorInfo{
+		Reason:   e.Code.Name,
+		Domain:   Domain,
+		Metadata: metadata,
+	}
+}
+
+// FromGRPCStatus 在客户端将gRPC状态还原为 *errors.Error
+//
+// 状态携带本库的 ErrorInfo 时还原错误码、详情和上下文（上下文值还原为字符串），
+// 未在本进程注册的自定义错误码按名称和数字码重建；
+// 否则按gRPC状态码映射到通用错误码。OK 状态返回 nil。
+func FromGRPCStatus(st *status.Status) *errors.Error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.GetDomain() != Domain {
+			continue
+		}
+		e := errors.New(lookupCode(info), st.Message())
+		if details := info.GetMetadata()[metadataDetails]; details != "" {
+			e = e.WithDetails(details)
+		}
+		keys := make([]string, 0, len(info.GetMetadata()))
+		for key := range info.GetMetadata() {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if name, ok := strings.CutPrefix(key, metadataContext); ok {
+				e = e.WithContext(name, info.GetMetadata()[key])
+			}
+		}
+		return e
+	}
+
+	code, ok := fallbackCodes[st.Code()]
+	if !ok {
+		code = errors.CodeInternalServer
+	}
+	return errors.New(code, st.Message()).WithContext("grpc_code", st.Code().String())
+}
+
+// FromError 从gRPC调用返回的错误还原 *errors.Error，err 为 nil 时返回 nil
+func FromError(err error) *errors.Error {
+	if err == nil {
+		return nil
+	}
+	return FromGRPCStatus(status.Convert(err))
+}
+
+// lookupCode 根据 ErrorInfo 还原错误码：已注册 > 预定义 > 按名称和数字码重建
+func lookupCode(info *errdetails.ErrorInfo) errors.ErrorCode {
+	number, numErr := strconv.Atoi(info.GetMetadata()[metadataCode])
+
+	if numErr == nil {
+		registryMu.RLock()
+		reg, ok := registry[number]
+		registryMu.RUnlock()
+		if ok && reg.code.Name == info.GetReason() {
+			return reg.code
+		}
+	}
+	if code, ok := errors.StringToCodeWithFound(info.GetReason()); ok {
+		return code
+	}
+	if numErr != nil {
+		number = errors.StringToCode(info.GetReason()).Code
+	}
+	return errors.NewErrorCode(number, info.GetReason())
+}
@@ -0,0 +1,153 @@
+package grpcstatus
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/tsopia/go-kit/errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCCodeDefaults(t *testing.T) {
+	tests := []struct {
+		code errors.ErrorCode
+		want codes.Code
+	}{
+		{errors.CodeNotFound, codes.NotFound},
+		{errors.CodeInvalidParam, codes.InvalidArgument},
+		{errors.CodeUnauthorized, codes.Unauthenticated},
+		{errors.CodeTimeoutError, codes.DeadlineExceeded},
+		{errors.CodeDatabaseError, codes.Internal},
+		{errors.CodeInternalServer, codes.Internal},
+		{errors.NewErrorCode(9876, "UNREGISTERED"), codes.Unknown},
+	}
+	for _, tt := range tests {
+		if got := GRPCCode(tt.code); got != tt.want {
+			t.Errorf("GRPCCode(%s) = %v, want %v", tt.code.Name, got, tt.want)
+		}
+	}
+}
+
+func TestToGRPCStatus(t *testing.T) {
+	err := errors.New(errors.CodeNotFound, "用户不存在").
+		WithDetails("id=42").
+		WithContext("user_id", 42)
+
+	st := ToGRPCStatus(fmt.Errorf("handler: %w", err))
+	if st.Code() != codes.NotFound {
+		t.Fatalf("code = %v, want NotFound", st.Code())
+	}
+	if st.Message() != "用户不存在" {
+		t.Errorf("message = %q", st.Message())
+	}
+
+	details := st.Details()
+	if len(details) != 1 {
+		t.Fatalf("details = %v, want one ErrorInfo", details)
+	}
+	info, ok := details[0].(*errdetails.ErrorInfo)
+	if !ok {
+		t.Fatalf("detail type = %T", details[0])
+	}
+	if info.GetReason() != "NOT_FOUND" || info.GetDomain() != Domain {
+		t.Errorf("reason/domain = %q/%q", info.GetReason(), info.GetDomain())
+	}
+	if info.GetMetadata()["context.user_id"] != "42" || info.GetMetadata()["details"] != "id=42" {
+		t.Errorf("metadata = %v", info.GetMetadata())
+	}
+}
+
+func TestToGRPCStatusNonKitErrors(t *testing.T) {
+	if st := ToGRPCStatus(nil); st.Code() != codes.OK {
+		t.Errorf("nil: code = %v", st.Code())
+	}
+	if st := ToGRPCStatus(context.DeadlineExceeded); st.Code() != codes.DeadlineExceeded {
+		t.Errorf("deadline: code = %v", st.Code())
+	}
+	if st := ToGRPCStatus(context.Canceled); st.Code() != codes.Canceled {
+		t.Errorf("canceled: code = %v", st.Code())
+	}
+	if st := ToGRPCStatus(status.Error(codes.Unavailable, "down")); st.Code() != codes.Unavailable {
+		t.Errorf("status error: code = %v", st.Code())
+	}
+	if st := ToGRPCStatus(stderrors.New("boom")); st.Code() != codes.Unknown || st.Message() != "boom" {
+		t.Errorf("plain: %v %q", st.Code(), st.Message())
+	}
+}
+
+func TestRoundTripPredefinedCode(t *testing.T) {
+	original := errors.New(errors.CodeInvalidParam, "参数错误").
+		WithDetails("name 不能为空").
+		WithContext("field", "name")
+
+	got := FromError(ToGRPCStatus(original).Err())
+	if got == nil {
+		t.Fatal("FromError returned nil")
+	}
+	if !got.Code.Equal(errors.CodeInvalidParam) || got.Code.Name != "INVALID_PARAM" {
+		t.Errorf("code = %+v", got.Code)
+	}
+	if got.GetMessage() != "参数错误" || got.Details != "name 不能为空" {
+		t.Errorf("message/details = %q/%q", got.GetMessage(), got.Details)
+	}
+	if got.Context["field"] != "name" {
+		t.Errorf("context = %v", got.Context)
+	}
+}
+
+func TestRoundTripRegisteredCustomCode(t *testing.T) {
+	quota := errors.NewErrorCode(5101, "QUOTA_EXCEEDED", "配额不足")
+	Register(quota, codes.ResourceExhausted)
+
+	st := ToGRPCStatus(errors.New(quota))
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("code = %v, want ResourceExhausted", st.Code())
+	}
+	got := FromGRPCStatus(st)
+	if !got.Code.Equal(quota) || got.Code.DefaultMessage != "配额不足" {
+		t.Errorf("code = %+v", got.Code)
+	}
+}
+
+func TestRoundTripUnregisteredCustomCode(t *testing.T) {
+	custom := errors.NewErrorCode(5202, "LEGACY_CONFLICT", "旧系统冲突")
+
+	st := ToGRPCStatus(errors.New(custom, "冲突了"))
+	if st.Code() != codes.Unknown {
+		t.Fatalf("code = %v, want Unknown", st.Code())
+	}
+	got := FromGRPCStatus(st)
+	if got.Code.Code != 5202 || got.Code.Name != "LEGACY_CONFLICT" {
+		t.Errorf("code = %+v", got.Code)
+	}
+	if got.GetMessage() != "冲突了" {
+		t.Errorf("message = %q", got.GetMessage())
+	}
+}
+
+func TestFromGRPCStatusWithoutErrorInfo(t *testing.T) {
+	if FromGRPCStatus(status.New(codes.OK, "")) != nil {
+		t.Error("OK status should map to nil")
+	}
+	if FromError(nil) != nil {
+		t.Error("nil error should map to nil")
+	}
+
+	got := FromGRPCStatus(status.New(codes.NotFound, "missing"))
+	if !got.Code.Equal(errors.CodeNotFound) || got.GetMessage() != "missing" {
+		t.Errorf("got %+v", got)
+	}
+	if got.Context["grpc_code"] != "NotFound" {
+		t.Errorf("context = %v", got.Context)
+	}
+
+	got = FromGRPCStatus(status.New(codes.DataLoss, "lost"))
+	if !got.Code.Equal(errors.CodeInternalServer) {
+		t.Errorf("code = %+v, want INTERNAL_SERVER_ERROR", got.Code)
+	}
+}
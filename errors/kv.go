@@ -0,0 +1,84 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// badKey 键值对参数中键不是字符串或缺少值时使用的键
+const badKey = "!BADKEY"
+
+// NewKV 创建错误，消息保持为静态字符串，数据以键值对形式存入 Context
+//
+// 与 Newf 不同，消息中不插入用户输入，便于日志聚合并避免泄露敏感信息。
+// 消息中可以使用 {key} 占位符，由 RenderedMessage 在展示时替换。
+//
+// 示例:
+//
+//	err := errors.NewKV(errors.CodeUserNotFound, "user not found", "user_email", email)
+//	err.GetMessage()      // "user not found"
+//	err.RenderedMessage() // "user not found (user_email=a@example.com)"
+func NewKV(code ErrorCode, message string, keyvals ...interface{}) *Error {
+	return withKeyvals(New(code, message), keyvals)
+}
+
+// WrapKV 包装现有错误，消息保持为静态字符串，数据以键值对形式存入 Context
+func WrapKV(err error, code ErrorCode, message string, keyvals ...interface{}) *Error {
+	return withKeyvals(Wrap(err, code, message), keyvals)
+}
+
+// withKeyvals 将键值对写入新建错误的上下文，e 尚未共享，直接写入而不逐个复制
+// 键不是字符串时使用 !BADKEY，落单的最后一个参数同样记为 !BADKEY
+func withKeyvals(e *Error, keyvals []interface{}) *Error {
+	if len(keyvals) == 0 {
+		return e
+	}
+	e.Context = make(map[string]interface{}, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		if i+1 >= len(keyvals) {
+			e.Context[badKey] = keyvals[i]
+			break
+		}
+		key, ok := keyvals[i].(string)
+		if !ok {
+			e.Context[badKey] = keyvals[i+1]
+			continue
+		}
+		e.Context[key] = keyvals[i+1]
+	}
+	return e
+}
+
+// RenderedMessage 返回插入上下文数据后的消息，用于面向人的展示
+//
+// 消息中的 {key} 占位符替换为对应的上下文值，其余上下文按键排序追加在括号中。
+// 日志聚合和API响应应继续使用 GetMessage 返回的静态消息。
+func (e *Error) RenderedMessage() string {
+	message := e.GetMessage()
+	if len(e.Context) == 0 {
+		return message
+	}
+
+	keys := make([]string, 0, len(e.Context))
+	for key := range e.Context {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var rest []string
+	for _, key := range keys {
+		placeholder := "{" + key + "}"
+		value := fmt.Sprint(e.Context[key])
+		if strings.Contains(message, placeholder) {
+			message = strings.ReplaceAll(message, placeholder, value)
+			continue
+		}
+		rest = append(rest, key+"="+value)
+	}
+
+	if len(rest) == 0 {
+		return message
+	}
+	return message + " (" + strings.Join(rest, ", ") + ")"
+}
@@ -0,0 +1,87 @@
+package errors
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewKV(t *testing.T) {
+	err := NewKV(CodeUserNotFound, "user not found", "user_email", "a@example.com", "attempt", 3)
+
+	if err.GetMessage() != "user not found" {
+		t.Errorf("期望静态消息 'user not found', 实际 '%s'", err.GetMessage())
+	}
+	if err.Error() != "[USER_NOT_FOUND] user not found" {
+		t.Errorf("Error() 不应包含上下文数据, 实际 '%s'", err.Error())
+	}
+	if err.Context["user_email"] != "a@example.com" || err.Context["attempt"] != 3 {
+		t.Errorf("期望键值对写入上下文, 实际 %v", err.Context)
+	}
+	if !Is(err, CodeUserNotFound) {
+		t.Error("期望错误码为 CodeUserNotFound")
+	}
+}
+
+func TestNewKVBadKeys(t *testing.T) {
+	err := NewKV(CodeInvalidParam, "invalid", 42, "value", "dangling")
+
+	if err.Context[badKey] != "dangling" {
+		t.Errorf("期望落单参数记为 %s, 实际 %v", badKey, err.Context)
+	}
+	if len(err.Context) != 1 {
+		t.Errorf("期望只有一个上下文项, 实际 %v", err.Context)
+	}
+
+	if err := NewKV(CodeInvalidParam, "invalid"); err.Context != nil {
+		t.Errorf("没有键值对时不应创建上下文, 实际 %v", err.Context)
+	}
+}
+
+func TestWrapKV(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := WrapKV(cause, CodeDatabaseError, "query failed", "table", "users")
+
+	if !errors.Is(err, cause) {
+		t.Error("期望保留原始错误")
+	}
+	if err.GetMessage() != "query failed" || err.Context["table"] != "users" {
+		t.Errorf("意外的错误: %v %v", err.GetMessage(), err.Context)
+	}
+}
+
+func TestRenderedMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *Error
+		expected string
+	}{
+		{
+			name:     "无上下文",
+			err:      New(CodeNotFound, "not found"),
+			expected: "not found",
+		},
+		{
+			name:     "追加上下文",
+			err:      NewKV(CodeNotFound, "user not found", "user_id", 7, "email", "a@example.com"),
+			expected: "user not found (email=a@example.com, user_id=7)",
+		},
+		{
+			name:     "替换占位符",
+			err:      NewKV(CodeNotFound, "user {user_id} not found", "user_id", 7, "tenant", "t1"),
+			expected: "user 7 not found (tenant=t1)",
+		},
+		{
+			name:     "默认消息",
+			err:      NewKV(CodeNotFound, "", "id", 1),
+			expected: "资源不存在 (id=1)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.RenderedMessage(); got != tt.expected {
+				t.Errorf("期望 '%s', 实际 '%s'", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package errors
+
+import stderrors "errors"
+
+// temporaryCodes 默认视为临时错误（可重试）的错误码
+var temporaryCodes = []ErrorCode{CodeTimeoutError, CodeNetworkError, CodeTooManyRequests}
+
+// Timeout 创建超时错误，Timeout() 与 Temporary() 都返回 true
+//
+// 示例:
+//
+//	return errors.Timeout("查询库存超时")
+func Timeout(message ...string) *Error {
+	err := New(CodeTimeoutError, message...)
+	err.timeout = true
+	err.temporary = true
+	return err
+}
+
+// Temporary 创建临时错误，Temporary() 返回 true，表示稍后重试可能成功
+//
+// 示例:
+//
+//	return errors.Temporary(errors.CodeExternalServiceError, "库存服务维护中")
+func Temporary(code ErrorCode, message ...string) *Error {
+	err := New(code, message...)
+	err.temporary = true
+	return err
+}
+
+// Timeout 实现 net.Error 的 Timeout 方法，供 os.IsTimeout 和第三方重试库识别
+//
+// 以下情况返回 true:
+//   - 通过 Timeout 构造函数创建
+//   - 错误码为 CodeTimeoutError
+//   - 错误链中的原始错误报告超时（例如包装了 net.Error 超时），与自身错误码无关
+func (e *Error) Timeout() bool {
+	if e.timeout || e.Code.Equal(CodeTimeoutError) {
+		return true
+	}
+	var t interface{ Timeout() bool }
+	return e.Cause != nil && stderrors.As(e.Cause, &t) && t.Timeout()
+}
+
+// Temporary 实现 net.Error 的 Temporary 方法，表示稍后重试可能成功
+//
+// 以下情况返回 true:
+//   - 通过 Timeout 或 Temporary 构造函数创建
+//   - 错误码为 CodeTimeoutError、CodeNetworkError 或 CodeTooManyRequests
+//   - 错误链中的原始错误报告 Temporary() 或 Timeout()
+func (e *Error) Temporary() bool {
+	if e.temporary || e.Timeout() {
+		return true
+	}
+	for _, code := range temporaryCodes {
+		if e.Code.Equal(code) {
+			return true
+		}
+	}
+	var t interface{ Temporary() bool }
+	return e.Cause != nil && stderrors.As(e.Cause, &t) && t.Temporary()
+}
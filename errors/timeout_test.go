@@ -0,0 +1,113 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+)
+
+// timeoutErr 模拟只报告超时的 net.Error
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string   { return "i/o timeout" }
+func (timeoutErr) Timeout() bool   { return true }
+func (timeoutErr) Temporary() bool { return false }
+
+func TestTimeoutConstructor(t *testing.T) {
+	err := Timeout("查询超时")
+
+	var netErr net.Error = err
+	if !netErr.Timeout() || !netErr.Temporary() {
+		t.Errorf("期望 Timeout() 和 Temporary() 都为 true")
+	}
+	if !Is(err, CodeTimeoutError) || err.GetMessage() != "查询超时" {
+		t.Errorf("期望 CodeTimeoutError 和自定义消息, 实际 %v", err)
+	}
+	if !os.IsTimeout(err) {
+		t.Error("os.IsTimeout 应识别超时错误")
+	}
+}
+
+func TestTemporaryConstructor(t *testing.T) {
+	err := Temporary(CodeExternalServiceError, "服务维护中")
+	if !err.Temporary() || err.Timeout() {
+		t.Errorf("期望 Temporary()=true Timeout()=false, 实际 %v %v", err.Temporary(), err.Timeout())
+	}
+	if !Is(err, CodeExternalServiceError) {
+		t.Error("期望保留错误码")
+	}
+	// 派生副本保留标志
+	if !err.WithContext("k", "v").Temporary() {
+		t.Error("WithContext 副本应保留 Temporary 标志")
+	}
+}
+
+func TestTimeoutFromCode(t *testing.T) {
+	tests := []struct {
+		code      ErrorCode
+		timeout   bool
+		temporary bool
+	}{
+		{CodeTimeoutError, true, true},
+		{CodeNetworkError, false, true},
+		{CodeTooManyRequests, false, true},
+		{CodeInternalServer, false, false},
+		{CodeNotFound, false, false},
+	}
+	for _, tt := range tests {
+		err := New(tt.code)
+		if err.Timeout() != tt.timeout || err.Temporary() != tt.temporary {
+			t.Errorf("%s: 期望 Timeout=%v Temporary=%v, 实际 %v %v", tt.code, tt.timeout, tt.temporary, err.Timeout(), err.Temporary())
+		}
+		if os.IsTimeout(err) != tt.timeout {
+			t.Errorf("%s: os.IsTimeout 期望 %v", tt.code, tt.timeout)
+		}
+	}
+}
+
+func TestTimeoutThroughWrapChain(t *testing.T) {
+	// 只有最内层是超时错误，外层都是通用错误码
+	inner := &net.OpError{Op: "read", Net: "tcp", Err: timeoutErr{}}
+	middle := Wrap(fmt.Errorf("读取响应: %w", inner), CodeExternalServiceError, "调用库存服务失败")
+	outer := Wrap(middle, CodeInternalServer, "下单失败")
+
+	var netErr net.Error
+	if !errors.As(outer, &netErr) || !netErr.Timeout() {
+		t.Fatal("errors.As 得到的 net.Error 应报告超时")
+	}
+	if !outer.Timeout() || !os.IsTimeout(outer) {
+		t.Error("外层错误应通过错误链报告超时")
+	}
+	if !outer.Temporary() {
+		t.Error("超时错误应视为临时错误")
+	}
+	if !errors.Is(outer, inner) {
+		t.Error("错误链应保留原始错误")
+	}
+}
+
+func TestTimeoutFromContextDeadline(t *testing.T) {
+	err := Wrap(context.DeadlineExceeded, CodeDatabaseError, "查询失败")
+	if !err.Timeout() || !os.IsTimeout(err) {
+		t.Error("包装 context.DeadlineExceeded 应报告超时")
+	}
+
+	err = Wrap(context.Canceled, CodeDatabaseError, "查询失败")
+	if err.Timeout() || err.Temporary() {
+		t.Error("包装 context.Canceled 不应报告超时或临时错误")
+	}
+}
+
+func TestTemporaryFromCause(t *testing.T) {
+	cause := Temporary(CodeExternalServiceError, "维护中")
+	err := Wrap(cause, CodeInternalServer)
+	if !err.Temporary() || err.Timeout() {
+		t.Errorf("期望从原始错误继承 Temporary, 实际 Temporary=%v Timeout=%v", err.Temporary(), err.Timeout())
+	}
+	if Wrap(errors.New("boom"), CodeInternalServer).Temporary() {
+		t.Error("普通错误不应视为临时错误")
+	}
+}
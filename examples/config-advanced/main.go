@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/tsopia/go-kit/config"
 )
@@ -157,6 +158,31 @@ func demonstrateConvenienceFunctions() {
 		return
 	}
 	fmt.Printf("✅ 允许的IP: %v\n", allowedIPs)
+
+	// 超时验证
+	timeout, valid, err := config.GetDurationWithValidation("app.timeout", 30*time.Second, time.Second, 5*time.Minute)
+	if err != nil {
+		log.Printf("❌ 超时验证失败: %v", err)
+		return
+	}
+	if valid {
+		fmt.Printf("✅ 超时配置有效: %v\n", timeout)
+	} else {
+		fmt.Printf("⚠️  超时配置无效，使用默认值: %v\n", timeout)
+	}
+
+	// 枚举值验证
+	envs, valid, err := config.GetStringSliceWithValidation("features.environments",
+		[]string{"dev"}, []string{"dev", "staging", "prod"})
+	if err != nil {
+		log.Printf("❌ 环境列表验证失败: %v", err)
+		return
+	}
+	if valid {
+		fmt.Printf("✅ 启用的环境: %v\n", envs)
+	} else {
+		fmt.Printf("⚠️  环境列表包含未知的值，使用默认值: %v\n", envs)
+	}
 }
 
 func demonstrateErrorHandling() {
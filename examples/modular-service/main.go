@@ -4,6 +4,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/tsopia/go-kit/errors"
 	"github.com/tsopia/go-kit/httpserver"
 	"github.com/tsopia/go-kit/logger"
 
@@ -87,12 +88,8 @@ func (s *userServiceImpl) ListUsers(c *gin.Context) {
 		{"id": 3, "name": "王五", "email": "wangwu@example.com", "role": "user"},
 	}
 
-	c.JSON(200, gin.H{
-		"users":     users,
-		"count":     len(users),
-		"trace_id":  httpserver.GetTraceID(c),
-		"timestamp": time.Now().Unix(),
-	})
+	// {"data": [...], "meta": {"number": 1, "size": 20, "total_items": 3, "total_pages": 1}, "trace_id": ..., "request_id": ...}
+	httpserver.Paginated(c, users, httpserver.Page{Number: 1, Size: 20, TotalItems: int64(len(users))})
 }
 
 func (s *userServiceImpl) CreateUser(c *gin.Context) {
@@ -103,7 +100,8 @@ func (s *userServiceImpl) CreateUser(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": "参数验证失败", "details": err.Error()})
+		// 400 {"data": null, "error": {"code": 1001, "name": "INVALID_PARAM", ...}, ...}
+		httpserver.Error(c, errors.NewWithDetails(errors.CodeInvalidParam, "参数验证失败", err.Error()))
 		return
 	}
 
@@ -111,47 +109,35 @@ func (s *userServiceImpl) CreateUser(c *gin.Context) {
 		req.Role = "user"
 	}
 
-	c.JSON(201, gin.H{
-		"message": "用户创建成功",
-		"user": gin.H{
-			"id":    999,
-			"name":  req.Name,
-			"email": req.Email,
-			"role":  req.Role,
-		},
-		"trace_id": httpserver.GetTraceID(c),
+	httpserver.Created(c, gin.H{
+		"id":    999,
+		"name":  req.Name,
+		"email": req.Email,
+		"role":  req.Role,
 	})
 }
 
 func (s *userServiceImpl) GetUser(c *gin.Context) {
 	userID := c.Param("id")
-	c.JSON(200, gin.H{
-		"user": gin.H{
-			"id":    userID,
-			"name":  "用户" + userID,
-			"email": "user" + userID + "@example.com",
-			"role":  "user",
-		},
-		"trace_id": httpserver.GetTraceID(c),
+	if userID == "0" {
+		httpserver.Error(c, errors.New(errors.CodeUserNotFound, "用户不存在"))
+		return
+	}
+	httpserver.OK(c, gin.H{
+		"id":    userID,
+		"name":  "用户" + userID,
+		"email": "user" + userID + "@example.com",
+		"role":  "user",
 	})
 }
 
 func (s *userServiceImpl) UpdateUser(c *gin.Context) {
 	userID := c.Param("id")
-	c.JSON(200, gin.H{
-		"message":  "用户更新成功",
-		"user_id":  userID,
-		"trace_id": httpserver.GetTraceID(c),
-	})
+	httpserver.OK(c, gin.H{"id": userID})
 }
 
 func (s *userServiceImpl) DeleteUser(c *gin.Context) {
-	userID := c.Param("id")
-	c.JSON(200, gin.H{
-		"message":  "用户删除成功",
-		"user_id":  userID,
-		"trace_id": httpserver.GetTraceID(c),
-	})
+	httpserver.NoContent(c)
 }
 
 // productServiceImpl 产品服务实现
@@ -419,7 +405,6 @@ func main() {
 	log.Println("=== 模块化服务架构演示 ===")
 	log.Println("每个服务实现自己的接口，统一注册到路由组")
 
-
 	// 3. 创建HTTP服务器
 	server := httpserver.NewServer(&httpserver.Config{
 		Host: "0.0.0.0",
@@ -517,4 +502,4 @@ func registerRoutes(r *gin.Engine) {
 			})
 		})
 	}
-}
\ No newline at end of file
+}
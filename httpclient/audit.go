@@ -0,0 +1,125 @@
+package httpclient
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tsopia/go-kit/constants"
+)
+
+// 审计日志的错误分类
+const (
+	AuditErrorTimeout     = "timeout"      // 超时（上下文截止时间或网络超时）
+	AuditErrorCanceled    = "canceled"     // 上下文被取消
+	AuditErrorDNS         = "dns"          // 域名解析失败
+	AuditErrorTLS         = "tls"          // TLS握手或证书校验失败
+	AuditErrorConnection  = "connection"   // 建立或使用连接失败
+	AuditErrorRetryBudget = "retry_budget" // 重试预算耗尽
+	AuditErrorOther       = "other"        // 其他错误
+)
+
+// auditMessage 审计日志的消息，便于按消息过滤
+const auditMessage = "HTTP请求审计"
+
+// requestAudit 单个逻辑请求（包含全部重试）的审计信息
+type requestAudit struct {
+	start    time.Time
+	attempts int
+}
+
+// writeAudit 输出一条审计日志，不包含请求头和请求体:
+//
+//	timestamp, method, url, status, duration, bytes_out, bytes_in, attempts, trace_id[, error, error_category]
+//
+// duration 为整个逻辑请求的耗时，包含重试之间的退避等待。
+func (c *Client) writeAudit(audit *requestAudit, req *Request, httpReq *http.Request, resp *Response, err error) {
+	method, target := requestTarget(req, httpReq)
+	var bytesOut int64
+	traceID := constants.TraceIDFromContext(req.ctx)
+	if httpReq != nil {
+		bytesOut = httpReq.ContentLength
+		if traceID == "" {
+			traceID = httpReq.Header.Get(constants.TraceIDHeader)
+		}
+	}
+
+	status := 0
+	var bytesIn int64
+	if resp != nil {
+		status = resp.StatusCode
+		bytesIn = int64(len(resp.Body))
+	}
+
+	fields := []interface{}{
+		"timestamp", audit.start.UTC().Format(time.RFC3339Nano),
+		"method", method,
+		"url", target,
+		"status", status,
+		"duration", time.Since(audit.start),
+		"bytes_out", bytesOut,
+		"bytes_in", bytesIn,
+		"attempts", audit.attempts,
+		"trace_id", traceID,
+	}
+	if err != nil {
+		fields = append(fields, "error", err.Error(), "error_category", auditErrorCategory(err))
+	}
+
+	c.auditLogger.Info(auditMessage, fields...)
+}
+
+// requestTarget 返回请求方法和规范化的地址，请求未构建时使用原始值
+func requestTarget(req *Request, httpReq *http.Request) (method, target string) {
+	if httpReq == nil {
+		return req.method, req.url
+	}
+	if socketPath := socketPathFromRequest(httpReq); socketPath != "" {
+		return httpReq.Method, socketURL(socketPath, httpReq.URL)
+	}
+	return httpReq.Method, normalizeAuditURL(httpReq.URL)
+}
+
+// normalizeAuditURL 去掉用户信息、查询参数和片段，避免凭据和业务数据进入审计日志
+func normalizeAuditURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	normalized := url.URL{
+		Scheme: strings.ToLower(u.Scheme),
+		Host:   strings.ToLower(u.Host),
+		Path:   u.Path,
+	}
+	if normalized.Path == "" {
+		normalized.Path = "/"
+	}
+	return normalized.String()
+}
+
+// auditErrorCategory 将请求错误分类映射为审计日志的错误类别
+func auditErrorCategory(err error) string {
+	if errors.Is(err, ErrRetryBudgetExhausted) {
+		return AuditErrorRetryBudget
+	}
+	var opErr *net.OpError
+	switch category, _ := classifyError(err); category {
+	case CategoryCanceled:
+		return AuditErrorCanceled
+	case CategoryTimeout:
+		return AuditErrorTimeout
+	case CategoryDNS:
+		return AuditErrorDNS
+	case CategoryTLS:
+		return AuditErrorTLS
+	case CategoryConnectionRefused, CategoryConnectionReset:
+		return AuditErrorConnection
+	case CategoryOther:
+		if errors.As(err, &opErr) {
+			return AuditErrorConnection
+		}
+	}
+	return AuditErrorOther
+}
@@ -0,0 +1,178 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tsopia/go-kit/constants"
+)
+
+// auditEntries 返回审计日志的字段（键值对转为map）
+func auditEntries(m *MockLogger) []map[string]interface{} {
+	var entries []map[string]interface{}
+	for i, msg := range m.infoLogs {
+		if msg != auditMessage {
+			continue
+		}
+		fields := m.infoFields[i]
+		entry := make(map[string]interface{}, len(fields)/2)
+		for j := 0; j+1 < len(fields); j += 2 {
+			entry[fields[j].(string)] = fields[j+1]
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAuditLoggerRetriedRequest(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	audit := &MockLogger{}
+	client := NewClientWithOptions(ClientOptions{
+		Logger:      &MockLogger{},
+		AuditLogger: audit,
+		Retry: &RetryConfig{
+			MaxRetries:   3,
+			InitialDelay: 20 * time.Millisecond,
+			MaxDelay:     time.Second,
+		},
+	})
+
+	ctx := constants.WithTraceID(context.Background(), "trace-abc")
+	resp, err := client.NewRequest("POST", server.URL+"/orders?token=secret").
+		Body(strings.NewReader("payload")).
+		Context(ctx).
+		Do()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+
+	entries := auditEntries(audit)
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 audit entry per logical request, got %d", len(entries))
+	}
+	entry := entries[0]
+
+	if entry["attempts"] != 3 {
+		t.Errorf("Expected 3 attempts, got %v", entry["attempts"])
+	}
+	if entry["status"] != http.StatusOK {
+		t.Errorf("Expected status 200, got %v", entry["status"])
+	}
+	if entry["method"] != "POST" {
+		t.Errorf("Expected method POST, got %v", entry["method"])
+	}
+	if entry["url"] != server.URL+"/orders" {
+		t.Errorf("Expected normalized URL without query, got %v", entry["url"])
+	}
+	if entry["bytes_out"] != int64(len("payload")) || entry["bytes_in"] != int64(2) {
+		t.Errorf("Unexpected byte counts: out=%v in=%v", entry["bytes_out"], entry["bytes_in"])
+	}
+	if entry["trace_id"] != "trace-abc" {
+		t.Errorf("Expected trace_id trace-abc, got %v", entry["trace_id"])
+	}
+	// 两次退避（20ms + 20ms）必须计入总耗时
+	if d, _ := entry["duration"].(time.Duration); d < 40*time.Millisecond {
+		t.Errorf("Expected duration to include backoff sleeps, got %v", entry["duration"])
+	}
+	if _, ok := entry["error"]; ok {
+		t.Errorf("Expected no error field on success, got %v", entry["error"])
+	}
+	if _, err := time.Parse(time.RFC3339Nano, entry["timestamp"].(string)); err != nil {
+		t.Errorf("Expected RFC3339 timestamp, got %v", entry["timestamp"])
+	}
+}
+
+func TestAuditLoggerFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	target := server.URL
+	server.Close()
+
+	audit := &MockLogger{}
+	client := NewClientWithOptions(ClientOptions{
+		Logger:      &MockLogger{},
+		AuditLogger: audit,
+		Retry: &RetryConfig{
+			MaxRetries:   2,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+		},
+	})
+
+	if _, err := client.Get(target + "/down"); err == nil {
+		t.Fatal("Expected connection error")
+	}
+
+	entries := auditEntries(audit)
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 audit entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry["attempts"] != 3 {
+		t.Errorf("Expected 3 attempts, got %v", entry["attempts"])
+	}
+	if entry["status"] != 0 {
+		t.Errorf("Expected status 0 for failed request, got %v", entry["status"])
+	}
+	if entry["error_category"] != AuditErrorConnection {
+		t.Errorf("Expected connection error category, got %v", entry["error_category"])
+	}
+}
+
+func TestAuditLoggerWithoutRetry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	audit := &MockLogger{}
+	client := NewClientWithOptions(ClientOptions{Logger: &MockLogger{}, AuditLogger: audit})
+	client.DisableDebug()
+
+	client.Get(server.URL + "/missing")
+	client.Get(server.URL + "/missing")
+
+	entries := auditEntries(audit)
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 audit entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if entry["attempts"] != 1 || entry["status"] != http.StatusNotFound {
+			t.Errorf("Unexpected audit entry: %v", entry)
+		}
+	}
+}
+
+func TestAuditErrorCategory(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{context.DeadlineExceeded, AuditErrorTimeout},
+		{context.Canceled, AuditErrorCanceled},
+		{ErrRetryBudgetExhausted, AuditErrorRetryBudget},
+		{errors.New("boom"), AuditErrorOther},
+	}
+	for _, tt := range tests {
+		if got := auditErrorCategory(tt.err); got != tt.want {
+			t.Errorf("auditErrorCategory(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
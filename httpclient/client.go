@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
@@ -12,9 +13,13 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/tsopia/go-kit/constants"
+	"golang.org/x/sync/singleflight"
 )
 
 // RetryConfig 重试配置
@@ -25,6 +30,15 @@ type RetryConfig struct {
 	BackoffFactor   float64       // 退避因子
 	RetryableStatus []int         // 可重试的状态码
 	RetryableErrors []error       // 可重试的错误类型
+	OnRetry         RetryHook     // 每次尝试后的钩子，可覆盖默认的重试决定和延迟
+	Budget          *RetryBudget  // 重试预算，限制单位时间内的重试总数
+	// NoRetryOnExpectationFailure 不满足 Expect 设置的期望时不重试，默认按可重试的错误响应处理
+	NoRetryOnExpectationFailure bool
+	// TotalTimeout 所有尝试（包括重试之间的等待）共享的总超时，0表示不限制；
+	// 剩余时间不足以等待下一次重试时直接返回最后一次的结果
+	TotalTimeout time.Duration
+	// PerAttemptTimeout 单次尝试的超时，超时的尝试按超时错误重试，0表示只受 ClientOptions.Timeout 限制
+	PerAttemptTimeout time.Duration
 }
 
 // DebugConfig Debug配置
@@ -47,14 +61,7 @@ func DefaultDebugConfig() *DebugConfig {
 		LogResponseHeaders: true,
 		LogResponseBody:    true,
 		MaxBodySize:        1024 * 10, // 10KB
-		SensitiveHeaders: []string{
-			"Authorization",
-			"Cookie",
-			"Set-Cookie",
-			"X-Api-Key",
-			"X-Auth-Token",
-			"Bearer",
-		},
+		SensitiveHeaders:   constants.DefaultSensitiveHeaders(),
 	}
 }
 
@@ -79,22 +86,59 @@ type PoolConfig struct {
 
 // ClientOptions HTTP客户端选项
 type ClientOptions struct {
-	Timeout        time.Duration                         // 超时时间
+	Timeout        time.Duration                         // 超时时间，0 使用默认的 30 秒
 	BaseURL        string                                // 基础URL
 	Headers        map[string]string                     // 默认请求头
-	UserAgent      string                                // 用户代理
+	UserAgent      string                                // 用户代理，为空时使用 DefaultUserAgent()
 	Cookies        []*http.Cookie                        // 默认Cookie
 	Retry          *RetryConfig                          // 重试配置
 	CircuitBreaker *CircuitBreakerConfig                 // 熔断器配置
-	Pool           *PoolConfig                           // 连接池配置
+	Pool           *PoolConfig                           // 连接池配置，nil 使用与 NewClient 相同的默认值
 	TLS            *tls.Config                           // TLS配置
+	MTLS           *MTLSConfig                           // 双向TLS证书文件，自动重新加载；与 TLS 同时设置时以 TLS 为基础配置
 	Proxy          func(*http.Request) (*url.URL, error) // 代理函数
 	Interceptors   []Interceptor                         // 拦截器
 	Middlewares    []Middleware                          // 中间件
 	Logger         Logger                                // 日志记录器
+	AuditLogger    Logger                                // 审计日志记录器，每个逻辑请求输出一条摘要
 	Metrics        Metrics                               // 指标收集器
 	RateLimiter    RateLimiter                           // 限流器
 	Debug          *DebugConfig                          // Debug配置
+
+	// UnixSocket 通过UNIX套接字连接，请求URL使用占位主机 http://unix
+	// 也可以把 BaseURL 设为 unix:///var/run/docker.sock
+	UnixSocket string
+	// DialContext 自定义建立连接的函数，优先于 UnixSocket，例如在测试中使用 net.Pipe
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// NoTimeout 不设置总超时，仅用于确实需要无限期等待的请求（例如长轮询、大文件下载）
+	NoTimeout bool
+
+	// ForceHTTP2 使用自定义连接或TLS配置时是否仍尝试HTTP/2，nil 表示 true
+	ForceHTTP2 *bool
+	// DisableHTTP2 只使用HTTP/1.1，优先于 ForceHTTP2，用于不能正确处理HTTP/2的上游
+	DisableHTTP2 bool
+
+	// MaxResponseBytes 响应体大小上限，超出时返回 ErrResponseTooLarge，0表示不限制
+	MaxResponseBytes int64
+	// ReadIdleTimeout 读取响应体时连续没有收到数据的最长时间，超出时返回 ErrReadIdleTimeout，
+	// 与总超时 Timeout 相互独立，0表示不限制
+	ReadIdleTimeout time.Duration
+
+	// EnableTiming 采集DNS、连接、TLS、首字节等耗时分解并填充 Response.Timing，启用Debug时自动采集
+	EnableTiming bool
+	// TTFBMetrics 采集耗时时额外通过 Metrics 导出 http_request_ttfb_seconds 直方图
+	TTFBMetrics bool
+
+	// Shadow 按比例把请求异步镜像到影子服务，nil 表示不镜像
+	Shadow *ShadowConfig
+
+	// Expectations 所有请求都检查的期望（例如始终期望JSON响应），与 Request.Expect 组合
+	Expectations []Expectation
+
+	// SingleFlight 合并相同的并发GET请求：方法、URL、请求级请求头和Cookie都相同的请求
+	// 在前一个请求完成前共享同一次网络调用，每个调用方得到独立的响应副本
+	SingleFlight bool
 }
 
 // Interceptor HTTP拦截器
@@ -161,10 +205,24 @@ type Client struct {
 	retry          *RetryConfig
 	circuitBreaker CircuitBreaker
 	logger         Logger
+	auditLogger    Logger
 	metrics        Metrics
 	rateLimiter    RateLimiter
 	mu             sync.RWMutex
 	debugConfig    *DebugConfig
+	unixSocket     string // UNIX套接字路径，为空时使用TCP
+
+	maxResponseBytes int64         // 响应体大小上限
+	readIdleTimeout  time.Duration // 读取响应体的空闲超时
+
+	enableTiming bool // 采集耗时分解
+	ttfbMetrics  bool // 导出首字节耗时直方图
+
+	expectations []Expectation // 客户端级的期望
+
+	mtls    *mtlsSource         // MTLS证书，未配置时为nil
+	shadow  *shadowMirror       // 流量镜像，未配置时为nil
+	flights *singleflight.Group // 合并相同的并发GET请求，未开启 SingleFlight 时为nil
 }
 
 // Response HTTP响应
@@ -173,9 +231,19 @@ type Response struct {
 	Status     string
 	Headers    http.Header
 	Body       []byte
+	Proto      string // 协议版本，例如 "HTTP/1.1"、"HTTP/2.0"
 	Response   *http.Response
 	Request    *http.Request
 	Duration   time.Duration
+
+	// Timing 最终返回的这次尝试的耗时分解，未开启 EnableTiming 或Debug时为nil
+	Timing *Timing
+	// AttemptTimings 每次尝试的耗时分解（包含最后一次），用于排查重试
+	AttemptTimings []Timing
+
+	tls *tls.ConnectionState // TLS连接信息，读取响应体后依然可用
+
+	sensitiveHeaders []string // Dump 脱敏的响应头，来自发送请求的客户端
 }
 
 // Request HTTP请求构建器
@@ -189,6 +257,12 @@ type Request struct {
 	timeout time.Duration
 	ctx     context.Context
 	retries int
+	err     error // 构建阶段的错误（如路径模板无效），在 Do 时返回
+
+	maxResponseBytes int64         // 覆盖客户端的响应体大小上限，负数表示不限制
+	readIdleTimeout  time.Duration // 覆盖客户端的空闲超时，负数表示不限制
+
+	expectations []Expectation // 请求级的期望
 }
 
 // httpDebugInfo 调试信息结构体
@@ -210,30 +284,108 @@ type httpDebugInfo struct {
 	// 时间信息
 	StartTime time.Time
 	Duration  time.Duration
+	Timings   []Timing // 每次尝试的耗时分解
 }
 
 // NewClient 创建新的HTTP客户端
 func NewClient() *Client {
 	return NewClientWithOptions(ClientOptions{
-		Timeout: 30 * time.Second,
-		Pool: &PoolConfig{
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 10,
-			MaxConnsPerHost:     100,
-			IdleConnTimeout:     90 * time.Second,
-		},
+		Timeout: defaultTimeout,
+		Pool:    defaultPoolConfig(),
 	})
 }
 
+// defaultTimeout NewClient 使用的默认超时时间
+const defaultTimeout = 30 * time.Second
+
+// defaultPoolConfig NewClient 使用的默认连接池配置
+func defaultPoolConfig() *PoolConfig {
+	return &PoolConfig{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		MaxConnsPerHost:     100,
+		IdleConnTimeout:     90 * time.Second,
+	}
+}
+
 // NewClientWithOptions 根据选项创建HTTP客户端
+//
+// 零值选项是安全的：Timeout 为 0 时使用 30 秒（NoTimeout 为 true 时除外），Pool 为 nil 时使用默认连接池，
+// 未设置 UserAgent 时使用 DefaultUserAgent()。
+// 选项无效时（见 ClientOptions.Validate）记录警告并自动修正；需要在构造时得到错误请使用 NewClientWithOptionsE。
 func NewClientWithOptions(opts ClientOptions) *Client {
+	if err := opts.Validate(); err != nil {
+		warnInvalidOptions(opts.Logger, err)
+		opts = opts.fixup()
+	}
+	client, err := newClient(opts.withDefaults())
+	if err != nil {
+		// 证书文件加载失败时不使用MTLS，与其他无效选项一样记录警告
+		warnInvalidOptions(opts.Logger, err)
+		opts.MTLS = nil
+		client, _ = newClient(opts.withDefaults())
+	}
+	return client
+}
+
+// NewClientWithOptionsE 与 NewClientWithOptions 相同，但选项无效时返回包装 ErrInvalidOptions 的错误
+//
+// 示例:
+//
+//	client, err := httpclient.NewClientWithOptionsE(httpclient.ClientOptions{
+//	    BaseURL: "https://api.example.com",
+//	    Retry:   &httpclient.RetryConfig{MaxRetries: 3, InitialDelay: time.Second, MaxDelay: 100 * time.Millisecond},
+//	})
+//	// errors.Is(err, httpclient.ErrInvalidOptions) == true: MaxDelay 小于 InitialDelay
+//
+// MTLS 的证书文件加载失败时同样返回包装 ErrInvalidOptions 的错误。
+func NewClientWithOptionsE(opts ClientOptions) (*Client, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return newClient(opts.withDefaults())
+}
+
+// newClient 根据已校验并填充默认值的选项创建客户端，只有加载MTLS证书失败时返回错误
+func newClient(opts ClientOptions) (*Client, error) {
+	var mtls *mtlsSource
+	if opts.MTLS != nil {
+		source, err := newMTLSSource(*opts.MTLS, opts.Logger)
+		if err != nil {
+			return nil, fmt.Errorf("%w: MTLS: %w", ErrInvalidOptions, err)
+		}
+		mtls = source
+	}
+
+	// UNIX套接字: unix:// 形式的 BaseURL 转换为占位主机
+	baseURL := opts.BaseURL
+	unixSocket := opts.UnixSocket
+	if socketPath, ok := parseUnixBaseURL(baseURL); ok {
+		unixSocket = socketPath
+		baseURL = ""
+	}
+	if unixSocket != "" && baseURL == "" {
+		baseURL = "http://" + UnixSocketHost
+	}
+
 	// 构建传输层
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	dialContext := dialer.DialContext
+	switch {
+	case opts.DialContext != nil:
+		dialContext = opts.DialContext
+	case unixSocket != "":
+		dialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", unixSocket)
+		}
+	}
+
 	transport := &http.Transport{
-		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
-		ForceAttemptHTTP2:     true,
+		DialContext:           dialContext,
+		ForceAttemptHTTP2:     opts.ForceHTTP2 == nil || *opts.ForceHTTP2,
 		MaxIdleConns:          100,
 		MaxIdleConnsPerHost:   10,
 		IdleConnTimeout:       90 * time.Second,
@@ -261,8 +413,15 @@ func NewClientWithOptions(opts ClientOptions) *Client {
 		transport.Proxy = opts.Proxy
 	}
 
+	if opts.DisableHTTP2 {
+		disableHTTP2(transport)
+	}
+
 	// 应用中间件
 	var roundTripper http.RoundTripper = transport
+	if mtls != nil {
+		roundTripper = mtls.install(transport, transport.TLSClientConfig)
+	}
 	for i := len(opts.Middlewares) - 1; i >= 0; i-- {
 		roundTripper = opts.Middlewares[i](roundTripper)
 	}
@@ -275,16 +434,34 @@ func NewClientWithOptions(opts ClientOptions) *Client {
 
 	client := &Client{
 		httpClient:   httpClient,
-		baseURL:      strings.TrimSuffix(opts.BaseURL, "/"),
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
 		headers:      make(map[string]string),
 		cookies:      opts.Cookies,
 		interceptors: opts.Interceptors,
 		middlewares:  opts.Middlewares,
 		retry:        opts.Retry,
 		logger:       opts.Logger,
+		auditLogger:  opts.AuditLogger,
 		metrics:      opts.Metrics,
 		rateLimiter:  opts.RateLimiter,
 		debugConfig:  opts.Debug,
+		unixSocket:   unixSocket,
+
+		maxResponseBytes: opts.MaxResponseBytes,
+		readIdleTimeout:  opts.ReadIdleTimeout,
+
+		enableTiming: opts.EnableTiming,
+		ttfbMetrics:  opts.TTFBMetrics,
+
+		expectations: append([]Expectation(nil), opts.Expectations...),
+
+		mtls: mtls,
+	}
+	if opts.Shadow != nil {
+		client.shadow = newShadowMirror(*opts.Shadow, opts.Logger, opts.Metrics)
+	}
+	if opts.SingleFlight {
+		client.flights = &singleflight.Group{}
 	}
 
 	// 设置默认请求头
@@ -304,7 +481,7 @@ func NewClientWithOptions(opts ClientOptions) *Client {
 		client.circuitBreaker = newCircuitBreaker(*opts.CircuitBreaker)
 	}
 
-	return client
+	return client, nil
 }
 
 // NewRequest 创建新的请求构建器
@@ -399,6 +576,86 @@ func (c *Client) DisableDebug() {
 	}
 }
 
+// Clone 复制客户端，用于派生只有少量配置不同的子客户端
+//
+// 请求头、Cookie、拦截器、中间件、重试和Debug配置被复制，修改副本不会影响原客户端；
+// 传输层（连接池）、熔断器、限流器、日志、指标和重试预算与原客户端共享，复制的开销很小。
+//
+// 示例:
+//
+//	admin := base.Clone()
+//	admin.SetBaseURL("https://admin.example.com")
+//	admin.SetHeader("X-Admin", "1")
+func (c *Client) Clone() *Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	httpClient := *c.httpClient
+	clone := &Client{
+		httpClient:       &httpClient,
+		baseURL:          c.baseURL,
+		headers:          make(map[string]string, len(c.headers)),
+		cookies:          make([]*http.Cookie, 0, len(c.cookies)),
+		interceptors:     append([]Interceptor(nil), c.interceptors...),
+		middlewares:      append([]Middleware(nil), c.middlewares...),
+		circuitBreaker:   c.circuitBreaker,
+		logger:           c.logger,
+		auditLogger:      c.auditLogger,
+		metrics:          c.metrics,
+		rateLimiter:      c.rateLimiter,
+		unixSocket:       c.unixSocket,
+		maxResponseBytes: c.maxResponseBytes,
+		readIdleTimeout:  c.readIdleTimeout,
+		enableTiming:     c.enableTiming,
+		ttfbMetrics:      c.ttfbMetrics,
+		expectations:     append([]Expectation(nil), c.expectations...),
+		mtls:             c.mtls,
+		shadow:           c.shadow,
+	}
+	// 克隆后的请求头可能不同，不能与原客户端共享合并的请求
+	if c.flights != nil {
+		clone.flights = &singleflight.Group{}
+	}
+	for key, value := range c.headers {
+		clone.headers[key] = value
+	}
+	for _, cookie := range c.cookies {
+		copied := *cookie
+		clone.cookies = append(clone.cookies, &copied)
+	}
+	if c.retry != nil {
+		retry := *c.retry
+		retry.RetryableStatus = append([]int(nil), c.retry.RetryableStatus...)
+		retry.RetryableErrors = append([]error(nil), c.retry.RetryableErrors...)
+		clone.retry = &retry
+	}
+	if c.debugConfig != nil {
+		debug := *c.debugConfig
+		debug.SensitiveHeaders = append([]string(nil), c.debugConfig.SensitiveHeaders...)
+		clone.debugConfig = &debug
+	}
+	return clone
+}
+
+// disableHTTP2 限制传输层只使用HTTP/1.1
+// 非nil的空 TLSNextProto 阻止自动启用HTTP/2，同时从ALPN中移除 h2，避免服务端协商出HTTP/2
+func disableHTTP2(transport *http.Transport) {
+	transport.ForceAttemptHTTP2 = false
+	transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	if transport.TLSClientConfig == nil {
+		return
+	}
+	tlsConfig := transport.TLSClientConfig.Clone()
+	protos := tlsConfig.NextProtos[:0:0]
+	for _, proto := range tlsConfig.NextProtos {
+		if proto != "h2" {
+			protos = append(protos, proto)
+		}
+	}
+	tlsConfig.NextProtos = protos
+	transport.TLSClientConfig = tlsConfig
+}
+
 // rebuildTransport 重新构建传输层
 func (c *Client) rebuildTransport() {
 	transport := c.httpClient.Transport
@@ -420,16 +677,34 @@ func (c *Client) rebuildTransport() {
 	c.httpClient.Transport = transport
 }
 
+// resolveURL 构建完整URL，相对路径拼接在 BaseURL 之后
+func (c *Client) resolveURL(target string) string {
+	if strings.HasPrefix(target, "http") {
+		return target
+	}
+	c.mu.RLock()
+	baseURL := c.baseURL
+	c.mu.RUnlock()
+	return baseURL + "/" + strings.TrimPrefix(target, "/")
+}
+
 // buildRequest 构建HTTP请求
 func (c *Client) buildRequest(req *Request) (*http.Request, error) {
-	// 构建完整URL
-	fullURL := req.url
-	if !strings.HasPrefix(req.url, "http") {
-		fullURL = c.baseURL + "/" + strings.TrimPrefix(req.url, "/")
+	if req.err != nil {
+		return nil, req.err
+	}
+
+	fullURL := c.resolveURL(req.url)
+
+	ctx := req.ctx
+	if c.unixSocket != "" {
+		ctx = withSocketPath(ctx, c.unixSocket)
 	}
+	ctx = withResponseLimits(ctx, c.resolveResponseLimits(req))
+	ctx = withExpectations(ctx, c.expectations, req.expectations)
 
 	// 创建HTTP请求
-	httpReq, err := http.NewRequestWithContext(req.ctx, req.method, fullURL, req.body)
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, fullURL, req.body)
 	if err != nil {
 		return nil, fmt.Errorf("创建请求失败: %w", err)
 	}
@@ -461,39 +736,74 @@ func (c *Client) buildRequest(req *Request) (*http.Request, error) {
 }
 
 // do 执行HTTP请求
-func (c *Client) do(req *Request) (*Response, error) {
+func (c *Client) do(req *Request) (response *Response, err error) {
 	start := time.Now()
 
+	// 审计: 每个逻辑请求（包含重试）输出一条摘要，与Debug配置无关
+	var httpReq *http.Request
+	var audit *requestAudit
+	if c.auditLogger != nil {
+		audit = &requestAudit{start: start}
+		defer func() {
+			c.writeAudit(audit, req, httpReq, response, err)
+		}()
+	}
+
 	// 应用限流
 	if c.rateLimiter != nil {
 		if !c.rateLimiter.Allow() {
 			if err := c.rateLimiter.Wait(req.ctx); err != nil {
-				return nil, fmt.Errorf("限流等待失败: %w", err)
+				return nil, newRequestError(req, nil, 0, fmt.Errorf("限流等待失败: %w", err))
 			}
 		}
 	}
 
 	// 构建HTTP请求
-	httpReq, err := c.buildRequest(req)
+	httpReq, err = c.buildRequest(req)
 	if err != nil {
-		return nil, err
+		return nil, newRequestError(req, nil, 0, err)
+	}
+
+	// 流量镜像: 采样在发送前决定，以便保留请求体
+	shadowed := c.shadow != nil && c.shadow.sample(httpReq.Method)
+	if shadowed {
+		if err := c.shadow.prepare(httpReq); err != nil {
+			return nil, newRequestError(req, httpReq, 0, fmt.Errorf("读取请求体失败: %w", err))
+		}
+	}
+
+	debugEnabled := c.debugConfig != nil && c.debugConfig.Enabled
+
+	// 耗时分解: 只有开启时才放入记录器，关闭时不安装 httptrace
+	var timing *timingRecorder
+	if c.enableTiming || debugEnabled {
+		timing = &timingRecorder{}
+		httpReq = httpReq.WithContext(withTimingRecorder(httpReq.Context(), timing))
 	}
 
 	// Debug: 初始化调试信息收集
 	var debugInfo *httpDebugInfo
-	if c.debugConfig != nil && c.debugConfig.Enabled {
+	if debugEnabled {
 		debugInfo = &httpDebugInfo{
 			RequestMethod: req.method,
 			RequestURL:    req.url,
 			StartTime:     start,
 		}
 
+		// 套接字请求展示套接字路径而不是占位主机
+		if socketPath := socketPathFromRequest(httpReq); socketPath != "" {
+			debugInfo.RequestURL = socketURL(socketPath, httpReq.URL)
+		}
+
 		// 收集请求信息
 		c.collectRequestDebugInfo(debugInfo, httpReq, req)
 
 		// 使用defer确保在函数返回时输出完整的调试信息
 		defer func() {
 			debugInfo.Duration = time.Since(debugInfo.StartTime)
+			if debugInfo.Timings == nil {
+				_, debugInfo.Timings = timing.finish(time.Now())
+			}
 			c.logCombinedDebugInfo(debugInfo)
 		}()
 	}
@@ -508,13 +818,24 @@ func (c *Client) do(req *Request) (*Response, error) {
 
 	// 执行请求
 	var resp *http.Response
+	var attempts int
 	if c.circuitBreaker != nil {
-		err = c.circuitBreaker.Execute(func() error {
-			resp, err = c.executeRequest(httpReq)
-			return err
+		// 熔断器只统计上游故障，调用方取消等错误不计入
+		breakerErr := c.circuitBreaker.Execute(func() error {
+			resp, err = c.executeRequest(httpReq, &attempts)
+			if category, _ := classifyError(err); err != nil && category.IsUpstreamFailure() {
+				return err
+			}
+			return nil
 		})
+		if err == nil && breakerErr != nil {
+			resp, err = nil, breakerErr
+		}
 	} else {
-		resp, err = c.executeRequest(httpReq)
+		resp, err = c.executeRequest(httpReq, &attempts)
+	}
+	if audit != nil {
+		audit.attempts = attempts
 	}
 
 	duration := time.Since(start)
@@ -532,6 +853,8 @@ func (c *Client) do(req *Request) (*Response, error) {
 	}
 
 	if err != nil {
+		err = newRequestError(req, httpReq, attempts, err)
+
 		// Debug: 记录错误信息到debugInfo
 		if debugInfo != nil {
 			debugInfo.Error = err.Error()
@@ -548,22 +871,48 @@ func (c *Client) do(req *Request) (*Response, error) {
 		return nil, err
 	}
 
-	// 读取响应体
+	// 读取响应体（已受 MaxResponseBytes 和 ReadIdleTimeout 限制）
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		resp.Body.Close()
-		return nil, fmt.Errorf("读取响应体失败: %w", err)
+		reqErr := newRequestError(req, httpReq, attempts, fmt.Errorf("读取响应体失败: %w", err))
+		reqErr.Category = CategoryBodyReadFailed
+		err = reqErr
+		if debugInfo != nil {
+			debugInfo.Error = err.Error()
+		}
+		return nil, err
 	}
 	resp.Body.Close()
 
-	response := &Response{
+	var lastTiming *Timing
+	var attemptTimings []Timing
+	if timing != nil {
+		lastTiming, attemptTimings = timing.finish(time.Now())
+		if c.metrics != nil && c.ttfbMetrics && lastTiming != nil {
+			c.metrics.AddHistogram("http_request_ttfb_seconds", lastTiming.TimeToFirstByte.Seconds(), map[string]string{
+				"method": req.method,
+				"url":    req.url,
+				"status": fmt.Sprintf("%d", resp.StatusCode),
+			})
+		}
+	}
+
+	response = &Response{
 		StatusCode: resp.StatusCode,
 		Status:     resp.Status,
 		Headers:    resp.Header,
 		Body:       body,
+		Proto:      resp.Proto,
 		Response:   resp,
 		Request:    httpReq,
 		Duration:   duration,
+		tls:        resp.TLS,
+
+		sensitiveHeaders: c.sensitiveHeaders(),
+
+		Timing:         lastTiming,
+		AttemptTimings: attemptTimings,
 	}
 
 	// Debug: 收集响应信息到debugInfo
@@ -585,19 +934,33 @@ func (c *Client) do(req *Request) (*Response, error) {
 			req.method, req.url, resp.StatusCode, duration)
 	}
 
+	if shadowed {
+		c.shadow.mirror(httpReq, response)
+	}
+
 	return response, nil
 }
 
-// executeRequest 执行HTTP请求（带重试）
-func (c *Client) executeRequest(req *http.Request) (*http.Response, error) {
+// executeRequest 执行HTTP请求（带重试），attempts 记录实际尝试次数
+func (c *Client) executeRequest(req *http.Request, attempts *int) (*http.Response, error) {
 	if c.retry == nil {
-		return c.executeWithInterceptors(req)
+		*attempts = 1
+		return c.executeAttempt(req)
 	}
 
+	// 总超时覆盖所有尝试和重试等待，最终返回的响应体关闭后才释放
+	ctx, cancelTotal := c.retry.totalContext(req.Context())
+
 	var lastErr error
 	for attempt := 0; attempt <= c.retry.MaxRetries; attempt++ {
+		attemptCtx, cancelAttempt := c.retry.attemptContext(ctx)
+		release := func() {
+			cancelAttempt()
+			cancelTotal()
+		}
+
 		// 克隆请求（因为body可能被消费）
-		clonedReq := req.Clone(req.Context())
+		clonedReq := req.Clone(attemptCtx)
 		if req.Body != nil {
 			// 如果有body，需要重新设置
 			if seeker, ok := req.Body.(io.Seeker); ok {
@@ -606,14 +969,31 @@ func (c *Client) executeRequest(req *http.Request) (*http.Response, error) {
 			}
 		}
 
-		resp, err := c.executeWithInterceptors(clonedReq)
-		if err == nil && !c.shouldRetry(resp, err) {
-			return resp, nil
+		*attempts = attempt + 1
+		resp, err := c.executeAttempt(clonedReq)
+		retry, delay := decideRetry(c.retry, attempt+1, resp, err,
+			c.shouldRetry(resp, err), c.calculateDelay(attempt))
+		// 总超时或请求上下文的剩余时间不足以等待下一次重试
+		if retry && attempt < c.retry.MaxRetries && !canRetryWithin(ctx, delay) {
+			retry = false
+		}
+		if !retry {
+			return releaseOnClose(resp, release), err
 		}
 
 		lastErr = err
 		if attempt < c.retry.MaxRetries {
-			delay := c.calculateDelay(attempt)
+			// 检查重试预算
+			if c.retry.Budget != nil && !c.retry.Budget.Allow() {
+				if err != nil {
+					release()
+					return nil, fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, err)
+				}
+				return releaseOnClose(resp, release), nil
+			}
+			drainAndClose(resp)
+			cancelAttempt()
+
 			if c.logger != nil {
 				c.logger.Warn("HTTP请求失败，准备重试",
 					"attempt", attempt+1,
@@ -626,22 +1006,32 @@ func (c *Client) executeRequest(req *http.Request) (*http.Response, error) {
 				fmt.Printf("[WARN] HTTP请求失败，准备重试 - Attempt: %d/%d, Delay: %v, Error: %v\n",
 					attempt+1, c.retry.MaxRetries, delay, err)
 			}
-			time.Sleep(delay)
+			if waitErr := waitRetry(ctx, delay); waitErr != nil {
+				cancelTotal()
+				return nil, fmt.Errorf("等待重试时中断: %w（上次尝试: %v）", waitErr, lastErr)
+			}
+		} else {
+			drainAndClose(resp)
+			cancelAttempt()
 		}
 	}
 
+	cancelTotal()
 	return nil, fmt.Errorf("重试%d次后仍然失败: %w", c.retry.MaxRetries, lastErr)
 }
 
 // executeWithInterceptors 使用拦截器执行请求
+// 响应体限制在拦截器之内生效，拦截器读取响应体时同样受限
 func (c *Client) executeWithInterceptors(req *http.Request) (*http.Response, error) {
-	if len(c.interceptors) == 0 {
-		return c.httpClient.Do(req)
+	execute := func(req *http.Request) (*http.Response, error) {
+		resp, err := c.httpClient.Do(traceAttempt(req))
+		if err != nil {
+			return nil, err
+		}
+		return limitResponse(resp, responseLimitsFromRequest(req))
 	}
-
-	var execute func(*http.Request) (*http.Response, error)
-	execute = func(req *http.Request) (*http.Response, error) {
-		return c.httpClient.Do(req)
+	if len(c.interceptors) == 0 {
+		return execute(req)
 	}
 
 	// 从后往前应用拦截器
@@ -662,17 +1052,13 @@ func (c *Client) shouldRetry(resp *http.Response, err error) bool {
 		return false
 	}
 
-	// 检查错误类型
+	// 检查错误类型: RetryableErrors 优先，其余按错误分类判断
+	// 响应体超限和空闲超时默认不重试，可以通过 RetryableErrors 开启
 	if err != nil {
-		for _, retryableErr := range c.retry.RetryableErrors {
-			if errors.Is(err, retryableErr) {
-				return true
-			}
-		}
-		// 默认网络错误可重试
-		if isNetworkError(err) {
-			return true
+		if errors.Is(err, ErrExpectationFailed) {
+			return !c.retry.NoRetryOnExpectationFailure
 		}
+		return isRetryableError(err, c.retry.RetryableErrors)
 	}
 
 	// 检查状态码
@@ -709,31 +1095,6 @@ func (c *Client) calculateDelay(attempt int) time.Duration {
 	return delay
 }
 
-// isNetworkError 判断是否为网络错误
-func isNetworkError(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	// 检查常见的网络错误类型
-	var netErr net.Error
-	if errors.As(err, &netErr) {
-		return netErr.Timeout() || netErr.Temporary()
-	}
-
-	// 检查URL错误
-	var urlErr *url.Error
-	if errors.As(err, &urlErr) {
-		return isNetworkError(urlErr.Err)
-	}
-
-	// 检查其他网络相关错误
-	return strings.Contains(err.Error(), "connection refused") ||
-		strings.Contains(err.Error(), "connection reset") ||
-		strings.Contains(err.Error(), "no such host") ||
-		strings.Contains(err.Error(), "network is unreachable")
-}
-
 // Get 发送GET请求
 func (c *Client) Get(url string) (*Response, error) {
 	return c.NewRequest("GET", url).Do()
@@ -821,6 +1182,31 @@ func (r *Request) Form(data url.Values) *Request {
 	return r
 }
 
+// XML 设置XML请求体，编码结果带 XML 声明
+// SOAP 1.1 等要求 text/xml 的接口可在其后调用 ContentType 覆盖
+func (r *Request) XML(data interface{}) *Request {
+	xmlData, err := xml.Marshal(data)
+	if err != nil {
+		r.err = fmt.Errorf("编码XML请求体失败: %w", err)
+		return r
+	}
+	r.body = bytes.NewBufferString(xml.Header + string(xmlData))
+	r.headers["Content-Type"] = "application/xml"
+	return r
+}
+
+// Accept 设置 Accept 请求头
+func (r *Request) Accept(mime string) *Request {
+	r.headers["Accept"] = mime
+	return r
+}
+
+// ContentType 设置 Content-Type 请求头，覆盖 JSON、XML、Form 设置的默认值
+func (r *Request) ContentType(mime string) *Request {
+	r.headers["Content-Type"] = mime
+	return r
+}
+
 // Timeout 设置超时时间
 func (r *Request) Timeout(timeout time.Duration) *Request {
 	r.timeout = timeout
@@ -854,6 +1240,9 @@ func (r *Request) Do() (*Response, error) {
 		r.ctx = ctx
 	}
 
+	if key, ok := r.client.singleFlightKey(r); ok {
+		return r.client.doShared(r, key)
+	}
 	return r.client.do(r)
 }
 
@@ -864,6 +1253,11 @@ func (r *Response) JSON(v interface{}) error {
 	return json.Unmarshal(r.Body, v)
 }
 
+// XML 解析响应为XML
+func (r *Response) XML(v interface{}) error {
+	return xml.Unmarshal(r.Body, v)
+}
+
 // String 获取响应字符串
 func (r *Response) String() string {
 	return string(r.Body)
@@ -874,6 +1268,25 @@ func (r *Response) Bytes() []byte {
 	return r.Body
 }
 
+// StatusText 获取状态描述（不含状态码），例如 "Not Found"
+func (r *Response) StatusText() string {
+	if text := strings.TrimSpace(strings.TrimPrefix(r.Status, strconv.Itoa(r.StatusCode))); text != "" {
+		return text
+	}
+	return http.StatusText(r.StatusCode)
+}
+
+// TLS 获取TLS连接信息（协商的版本、加密套件、对端证书等），非HTTPS连接返回nil
+func (r *Response) TLS() *tls.ConnectionState {
+	if r.tls != nil {
+		return r.tls
+	}
+	if r.Response != nil {
+		return r.Response.TLS
+	}
+	return nil
+}
+
 // IsSuccess 检查是否为成功响应 (仅2xx)
 func (r *Response) IsSuccess() bool {
 	return r.StatusCode >= 200 && r.StatusCode < 300
@@ -944,24 +1357,52 @@ type retryTransport struct {
 }
 
 func (rt *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancelTotal := rt.config.totalContext(req.Context())
+
 	var lastErr error
 	for attempt := 0; attempt <= rt.config.MaxRetries; attempt++ {
-		resp, err := rt.next.RoundTrip(req)
-		if err == nil && !rt.shouldRetry(resp, err) {
-			return resp, nil
+		attemptCtx, cancelAttempt := rt.config.attemptContext(ctx)
+		release := func() {
+			cancelAttempt()
+			cancelTotal()
+		}
+
+		resp, err := rt.next.RoundTrip(req.WithContext(attemptCtx))
+		retry, delay := decideRetry(&rt.config, attempt+1, resp, err,
+			rt.shouldRetry(resp, err), rt.calculateDelay(attempt))
+		if retry && attempt < rt.config.MaxRetries && !canRetryWithin(ctx, delay) {
+			retry = false
+		}
+		if !retry {
+			return releaseOnClose(resp, release), err
 		}
 		lastErr = err
 		if attempt < rt.config.MaxRetries {
-			delay := rt.calculateDelay(attempt)
-			time.Sleep(delay)
+			if rt.config.Budget != nil && !rt.config.Budget.Allow() {
+				if err != nil {
+					release()
+					return nil, fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, err)
+				}
+				return releaseOnClose(resp, release), nil
+			}
+			drainAndClose(resp)
+			cancelAttempt()
+			if waitErr := waitRetry(ctx, delay); waitErr != nil {
+				cancelTotal()
+				return nil, fmt.Errorf("等待重试时中断: %w（上次尝试: %v）", waitErr, lastErr)
+			}
+		} else {
+			drainAndClose(resp)
+			cancelAttempt()
 		}
 	}
+	cancelTotal()
 	return nil, lastErr
 }
 
 func (rt *retryTransport) shouldRetry(resp *http.Response, err error) bool {
 	if err != nil {
-		return true
+		return isRetryableError(err, rt.config.RetryableErrors)
 	}
 	if resp != nil {
 		for _, status := range rt.config.RetryableStatus {
@@ -1046,7 +1487,7 @@ func (mt *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error)
 
 	labels := map[string]string{
 		"method": req.Method,
-		"host":   req.URL.Host,
+		"host":   requestHostLabel(req),
 	}
 
 	if resp != nil {
@@ -1120,6 +1561,20 @@ func SetDefaultClient(client *Client) {
 	defaultClient = client
 }
 
+// ConfigureDefault 使用选项重建全局客户端，Get、PostJSON 等全局函数随之生效
+// 未设置的 Timeout、Pool 和 UserAgent 使用与 NewClientWithOptions 相同的默认值。
+// 应在程序启动时调用，调用前通过 SetTimeout、SetHeader 等做的设置会被替换。
+//
+// 示例:
+//
+//	httpclient.ConfigureDefault(httpclient.ClientOptions{
+//	    Retry:          &httpclient.RetryConfig{MaxRetries: 3, InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second},
+//	    CircuitBreaker: &httpclient.CircuitBreakerConfig{MaxRequests: 10, Timeout: 30 * time.Second},
+//	})
+func ConfigureDefault(opts ClientOptions) {
+	SetDefaultClient(NewClientWithOptions(opts))
+}
+
 func GetDefaultClient() *Client {
 	return defaultClient
 }
@@ -1143,6 +1598,7 @@ func (c *Client) collectRequestDebugInfo(debugInfo *httpDebugInfo, httpReq *http
 func (c *Client) collectResponseDebugInfo(debugInfo *httpDebugInfo, response *Response) {
 	// 收集响应状态信息
 	debugInfo.ResponseStatus = fmt.Sprintf("✅ %s", response.Status)
+	debugInfo.Timings = response.AttemptTimings
 
 	// 收集响应头信息
 	if c.debugConfig.LogResponseHeaders {
@@ -1192,6 +1648,7 @@ func (c *Client) logCombinedDebugInfo(debugInfo *httpDebugInfo) {
 │ 📥 RESPONSE:
 │ Status: %s
 │ Duration: %v
+│ Timing: %s
 │ Headers: %s
 │ Body: %s
 └─────────────────────────────────────────────────────────────────────────────────`,
@@ -1203,6 +1660,7 @@ func (c *Client) logCombinedDebugInfo(debugInfo *httpDebugInfo) {
 		debugInfo.RequestBody,
 		statusInfo,
 		debugInfo.Duration,
+		formatTimings(debugInfo.Timings),
 		responseHeaders,
 		responseBody,
 	)
@@ -1235,8 +1693,8 @@ func (c *Client) formatHeaders(headers http.Header, isRequest bool) string {
 		value := strings.Join(values, ", ")
 
 		// 脱敏处理
-		if c.isSensitiveHeader(key) {
-			value = c.maskSensitiveValue(value)
+		if isSensitiveHeader(c.debugConfig.SensitiveHeaders, key) {
+			value = maskSensitiveValue(value)
 		}
 
 		formatted = append(formatted, fmt.Sprintf("%s: %s", key, value))
@@ -1292,19 +1750,8 @@ func (c *Client) formatBodyContent(body []byte) string {
 	return fmt.Sprintf("\n│         %s", strings.Join(lines, "\n│         "))
 }
 
-// isSensitiveHeader 检查是否为敏感请求头
-func (c *Client) isSensitiveHeader(key string) bool {
-	lowerKey := strings.ToLower(key)
-	for _, sensitive := range c.debugConfig.SensitiveHeaders {
-		if strings.ToLower(sensitive) == lowerKey {
-			return true
-		}
-	}
-	return false
-}
-
 // maskSensitiveValue 脱敏处理敏感值
-func (c *Client) maskSensitiveValue(value string) string {
+func maskSensitiveValue(value string) string {
 	if len(value) <= 8 {
 		return "****"
 	}
@@ -2,11 +2,17 @@ package httpclient
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -88,6 +94,93 @@ func TestAddMiddleware(t *testing.T) {
 	}
 }
 
+func TestClone(t *testing.T) {
+	base := NewClient()
+	base.SetBaseURL("https://api.example.com")
+	base.SetHeader("Authorization", "Bearer token123")
+	base.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+	base.SetDebug(&DebugConfig{Enabled: false, SensitiveHeaders: []string{"Authorization"}})
+
+	clone := base.Clone()
+	clone.SetBaseURL("https://admin.example.com")
+	clone.SetHeader("X-Admin", "1")
+	clone.SetHeader("Authorization", "Bearer admin")
+	clone.cookies[0].Value = "changed"
+	clone.EnableDebug()
+
+	if base.baseURL != "https://api.example.com" {
+		t.Errorf("Expected base URL to be unchanged, got %s", base.baseURL)
+	}
+	if base.headers["Authorization"] != "Bearer token123" || base.headers["X-Admin"] != "" {
+		t.Errorf("Expected base headers to be unchanged, got %v", base.headers)
+	}
+	if base.cookies[0].Value != "abc" {
+		t.Errorf("Expected base cookie to be unchanged, got %s", base.cookies[0].Value)
+	}
+	if base.debugConfig.Enabled {
+		t.Error("Expected base debug config to be unchanged")
+	}
+
+	// 传输层（连接池）共享
+	if clone.httpClient == base.httpClient || clone.httpClient.Transport != base.httpClient.Transport {
+		t.Error("Expected clone to have its own http.Client sharing the transport")
+	}
+
+	// 副本添加中间件不影响原客户端
+	clone.AddMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return &testRoundTripper{next: next}
+	})
+	if len(base.middlewares) != 0 {
+		t.Errorf("Expected base middlewares to be unchanged, got %d", len(base.middlewares))
+	}
+}
+
+func TestCloneConcurrent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("X-Variant")))
+	}))
+	defer server.Close()
+
+	base := NewClient()
+	base.SetBaseURL(server.URL)
+	base.SetHeader("X-Variant", "base")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			variant := fmt.Sprintf("clone-%d", i)
+			clone := base.Clone()
+			clone.SetHeader("X-Variant", variant)
+			clone.SetBaseURL(server.URL)
+
+			resp, err := clone.Get("/")
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+				return
+			}
+			if resp.String() != variant {
+				t.Errorf("Expected %s, got %s", variant, resp.String())
+			}
+		}(i)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := base.Get("/")
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+				return
+			}
+			if resp.String() != "base" {
+				t.Errorf("Expected base, got %s", resp.String())
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 // 测试用的RoundTripper
 type testRoundTripper struct {
 	next http.RoundTripper
@@ -474,6 +567,57 @@ func TestResponseError(t *testing.T) {
 	}
 }
 
+func TestResponseStatusTextAndProto(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if resp.StatusText() != "Not Found" {
+		t.Errorf("Expected status text 'Not Found', got %q", resp.StatusText())
+	}
+	if resp.Proto != "HTTP/1.1" {
+		t.Errorf("Expected proto HTTP/1.1, got %q", resp.Proto)
+	}
+	if resp.TLS() != nil {
+		t.Error("Expected nil TLS state for plain HTTP")
+	}
+}
+
+func TestResponseTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secure"))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(ClientOptions{
+		Timeout: 5 * time.Second,
+		TLS:     server.Client().Transport.(*http.Transport).TLSClientConfig,
+	})
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 响应体已被读取并关闭，TLS信息依然可用
+	state := resp.TLS()
+	if state == nil {
+		t.Fatal("Expected TLS connection state")
+	}
+	if !state.HandshakeComplete {
+		t.Error("Expected completed TLS handshake")
+	}
+	if len(state.PeerCertificates) == 0 {
+		t.Error("Expected peer certificates")
+	}
+}
+
 func TestRetryMiddleware(t *testing.T) {
 	attempts := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -512,6 +656,336 @@ func TestRetryMiddleware(t *testing.T) {
 	}
 }
 
+func TestRetryOnRetryCustomDelay(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var hookAttempts []int
+	client := NewClientWithOptions(ClientOptions{
+		Timeout: 5 * time.Second,
+		Retry: &RetryConfig{
+			MaxRetries:   3,
+			InitialDelay: 10 * time.Second, // 默认延迟很长，必须被钩子覆盖
+			MaxDelay:     10 * time.Second,
+			OnRetry: func(attempt int, resp *http.Response, err error) (bool, time.Duration) {
+				hookAttempts = append(hookAttempts, attempt)
+				return resp == nil || resp.StatusCode != http.StatusOK, 10 * time.Millisecond
+			},
+		},
+		Logger: &MockLogger{},
+	})
+
+	start := time.Now()
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected custom delay to be used, took %v", elapsed)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+	if len(hookAttempts) != 3 || hookAttempts[0] != 1 || hookAttempts[2] != 3 {
+		t.Errorf("Expected hook attempts [1 2 3], got %v", hookAttempts)
+	}
+}
+
+func TestRetryOnRetryStopsRetrying(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(ClientOptions{
+		Timeout: 5 * time.Second,
+		Retry: &RetryConfig{
+			MaxRetries:   3,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+			OnRetry: func(attempt int, resp *http.Response, err error) (bool, time.Duration) {
+				return false, 0
+			},
+		},
+		Logger: &MockLogger{},
+	})
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestRetryBudgetExhaustion(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	budget := NewRetryBudget(2, time.Minute)
+	client := NewClientWithOptions(ClientOptions{
+		Timeout: 5 * time.Second,
+		Retry: &RetryConfig{
+			MaxRetries:   5,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+			Budget:       budget,
+		},
+		Logger: &MockLogger{},
+	})
+
+	// 第一次请求：消耗全部2次预算后停止重试
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+	if budget.Remaining() != 0 {
+		t.Errorf("Expected budget to be exhausted, remaining %d", budget.Remaining())
+	}
+
+	// 第二次请求：预算已耗尽，不再重试
+	attempts = 0
+	if _, err := client.Get(server.URL); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt after budget exhaustion, got %d", attempts)
+	}
+}
+
+func TestRetryBudgetExhaustionWithError(t *testing.T) {
+	// 使用已关闭的服务器制造网络错误
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	client := NewClientWithOptions(ClientOptions{
+		Timeout: time.Second,
+		Retry: &RetryConfig{
+			MaxRetries:   3,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+			Budget:       NewRetryBudget(0, time.Minute),
+		},
+		Logger: &MockLogger{},
+	})
+
+	_, err := client.Get(url)
+	if !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Errorf("Expected ErrRetryBudgetExhausted, got %v", err)
+	}
+}
+
+func TestRetryBudgetWindowReset(t *testing.T) {
+	budget := NewRetryBudget(1, 50*time.Millisecond)
+	if !budget.Allow() {
+		t.Fatal("Expected first retry to be allowed")
+	}
+	if budget.Allow() {
+		t.Fatal("Expected second retry to be rejected")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if !budget.Allow() {
+		t.Error("Expected retry to be allowed after window reset")
+	}
+}
+
+func TestRetryTotalTimeout(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	const budget = 300 * time.Millisecond
+	client := NewClientWithOptions(ClientOptions{
+		Timeout: 5 * time.Second,
+		Retry: &RetryConfig{
+			MaxRetries:   10,
+			InitialDelay: 60 * time.Millisecond,
+			MaxDelay:     60 * time.Millisecond,
+			TotalTimeout: budget,
+		},
+		Logger: &MockLogger{},
+	})
+
+	start := time.Now()
+	resp, err := client.Get(server.URL)
+	elapsed := time.Since(start)
+
+	// 剩余时间不足以等待下一次重试时返回最后一次的响应
+	if err != nil {
+		t.Fatalf("Expected last response instead of error, got %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+	if elapsed > budget+50*time.Millisecond {
+		t.Errorf("Expected total wall time within %v, got %v", budget, elapsed)
+	}
+	// 100ms + 60ms + 100ms 之后只剩约40ms，不足以等待下一次重试
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("Expected 2 attempts within budget, got %d", n)
+	}
+}
+
+func TestRetryTotalTimeoutDuringAttempt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(ClientOptions{
+		Timeout: 5 * time.Second,
+		Retry: &RetryConfig{
+			MaxRetries:   3,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+			TotalTimeout: 150 * time.Millisecond,
+		},
+		Logger: &MockLogger{},
+	})
+
+	start := time.Now()
+	_, err := client.Get(server.URL)
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("Expected request to stop at the total timeout, took %v", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestRetryPerAttemptTimeout(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// 第一次尝试卡住，直到被单次超时取消
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(ClientOptions{
+		Timeout: 5 * time.Second,
+		Retry: &RetryConfig{
+			MaxRetries:        2,
+			InitialDelay:      time.Millisecond,
+			MaxDelay:          time.Millisecond,
+			PerAttemptTimeout: 100 * time.Millisecond,
+		},
+		Logger: &MockLogger{},
+	})
+
+	start := time.Now()
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected retry after attempt timeout to succeed, got %v", err)
+	}
+	if resp.String() != "ok" {
+		t.Errorf("Expected body to be readable after attempt context, got %q", resp.String())
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected first attempt to be cut at PerAttemptTimeout, took %v", elapsed)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 attempts, got %d", calls)
+	}
+}
+
+func TestRetryBackoffRespectsRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(ClientOptions{
+		Retry: &RetryConfig{
+			MaxRetries:   3,
+			InitialDelay: time.Second,
+			MaxDelay:     time.Second,
+		},
+		Logger: &MockLogger{},
+	})
+
+	// 请求级超时同样覆盖重试等待，不会等待超过截止时间的退避
+	start := time.Now()
+	resp, err := client.NewRequest("GET", server.URL).Timeout(200 * time.Millisecond).Do()
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("Expected request timeout to bound retries, took %v", elapsed)
+	}
+	if err != nil || resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected last 502 response, got %v, %v", resp, err)
+	}
+}
+
+func TestRetryMiddlewareTotalTimeout(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(ClientOptions{Logger: &MockLogger{}})
+	client.AddMiddleware(RetryMiddleware(RetryConfig{
+		MaxRetries:   10,
+		InitialDelay: 60 * time.Millisecond,
+		MaxDelay:     60 * time.Millisecond,
+		TotalTimeout: 200 * time.Millisecond,
+	}))
+
+	start := time.Now()
+	resp, err := client.Get(server.URL)
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("Expected middleware retries within total timeout, took %v", elapsed)
+	}
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected last 503 response, got %v, %v", resp, err)
+	}
+	if n := atomic.LoadInt32(&calls); n < 3 || n > 4 {
+		t.Errorf("Expected 3-4 attempts within budget, got %d", n)
+	}
+}
+
 func TestBuildRequest(t *testing.T) {
 	client := NewClient()
 	client.SetBaseURL("https://api.example.com")
@@ -1076,10 +1550,11 @@ func TestDebugPerformanceImpact(t *testing.T) {
 
 // MockLogger 用于测试的mock logger
 type MockLogger struct {
-	debugLogs []string
-	infoLogs  []string
-	warnLogs  []string
-	errorLogs []string
+	debugLogs  []string
+	infoLogs   []string
+	infoFields [][]interface{}
+	warnLogs   []string
+	errorLogs  []string
 }
 
 func (m *MockLogger) Debug(msg string, fields ...interface{}) {
@@ -1088,6 +1563,7 @@ func (m *MockLogger) Debug(msg string, fields ...interface{}) {
 
 func (m *MockLogger) Info(msg string, fields ...interface{}) {
 	m.infoLogs = append(m.infoLogs, msg)
+	m.infoFields = append(m.infoFields, fields)
 }
 
 func (m *MockLogger) Warn(msg string, fields ...interface{}) {
@@ -1387,3 +1863,163 @@ func TestRequestWithCtxChaining(t *testing.T) {
 		t.Error("Timeout should be set correctly after WithCtx")
 	}
 }
+
+// TestConfigureDefault 测试全局客户端配置
+func TestConfigureDefault(t *testing.T) {
+	original := GetDefaultClient()
+	defer SetDefaultClient(original)
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	ConfigureDefault(ClientOptions{
+		BaseURL: server.URL,
+		Logger:  &MockLogger{},
+		Retry: &RetryConfig{
+			MaxRetries:   3,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+		},
+	})
+
+	resp, err := Get("/resource")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || calls != 3 {
+		t.Errorf("Expected global Get to retry until success, got status %d after %d calls", resp.StatusCode, calls)
+	}
+
+	client := GetDefaultClient()
+	if client.httpClient.Timeout != defaultTimeout {
+		t.Errorf("Expected default timeout %v, got %v", defaultTimeout, client.httpClient.Timeout)
+	}
+}
+
+type xmlOrder struct {
+	XMLName xml.Name `xml:"order"`
+	ID      int      `xml:"id,attr"`
+	Item    string   `xml:"item"`
+	Qty     int      `xml:"qty"`
+}
+
+func TestRequestXMLBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/xml" {
+			t.Errorf("Expected Content-Type application/xml, got %s", ct)
+		}
+		if accept := r.Header.Get("Accept"); accept != "application/xml" {
+			t.Errorf("Expected Accept application/xml, got %s", accept)
+		}
+
+		var order xmlOrder
+		if err := xml.NewDecoder(r.Body).Decode(&order); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		order.Qty *= 2
+
+		w.Header().Set("Content-Type", "application/xml")
+		xml.NewEncoder(w).Encode(order)
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	resp, err := client.NewRequest("POST", server.URL).
+		Accept("application/xml").
+		XML(xmlOrder{ID: 7, Item: "book", Qty: 2}).
+		Do()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var order xmlOrder
+	if err := resp.XML(&order); err != nil {
+		t.Fatalf("Expected no error parsing XML, got %v", err)
+	}
+	if order.ID != 7 || order.Item != "book" || order.Qty != 4 {
+		t.Errorf("Unexpected order: %+v", order)
+	}
+}
+
+func TestRequestContentTypeOverride(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "text/xml; charset=utf-8" {
+			t.Errorf("Expected overridden Content-Type, got %s", ct)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !strings.HasPrefix(string(body), "<?xml") {
+			t.Errorf("Expected XML declaration, got %s", body)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	_, err := client.NewRequest("POST", server.URL).
+		XML(xmlOrder{ID: 1}).
+		ContentType("text/xml; charset=utf-8").
+		Do()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestRequestXMLEncodeError(t *testing.T) {
+	client := NewClient()
+	_, err := client.NewRequest("POST", "http://127.0.0.1:1").XML(make(chan int)).Do()
+	if err == nil || !strings.Contains(err.Error(), "编码XML请求体失败") {
+		t.Errorf("Expected XML encode error, got %v", err)
+	}
+}
+
+func TestHTTP2Options(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	forceFalse := false
+	tests := []struct {
+		name  string
+		opts  ClientOptions
+		proto string
+	}{
+		{"default", ClientOptions{}, "HTTP/2.0"},
+		{"force http2 false", ClientOptions{ForceHTTP2: &forceFalse}, "HTTP/1.1"},
+		{"disable http2", ClientOptions{DisableHTTP2: true}, "HTTP/1.1"},
+		{
+			name: "disable http2 with h2 in NextProtos",
+			opts: ClientOptions{DisableHTTP2: true, TLS: &tls.Config{
+				InsecureSkipVerify: true,
+				NextProtos:         []string{"h2", "http/1.1"},
+			}},
+			proto: "HTTP/1.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.BaseURL = server.URL
+			if opts.TLS == nil {
+				opts.TLS = &tls.Config{InsecureSkipVerify: true}
+			}
+			resp, err := NewClientWithOptions(opts).Get("/")
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.Proto != tt.proto || resp.String() != tt.proto {
+				t.Errorf("expected %s, got response proto %s, server saw %s", tt.proto, resp.Proto, resp.String())
+			}
+		})
+	}
+}
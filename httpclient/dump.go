@@ -0,0 +1,166 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tsopia/go-kit/constants"
+)
+
+// AsCurl 把构建完成的请求（BaseURL、客户端默认请求头、Cookie、请求体）渲染为等价的 curl 命令，
+// 用于问题反馈时复现请求；敏感请求头按 DebugConfig.SensitiveHeaders（未配置时使用默认列表）脱敏
+//
+// 请求体需要可以重复读取（JSON、Form、XML 以及 *bytes.Buffer、*strings.Reader 等可 Seek 的读取器），
+// 否则返回错误，以免读取后请求无法再发送。请求签名等由中间件在发送时添加的请求头不包含在内。
+//
+// 示例:
+//
+//	req := client.Post("/orders").JSON(order)
+//	cmd, err := req.AsCurl()
+//	// curl -X POST 'https://api.example.com/orders' \
+//	//   -H 'Authorization: Bear****oken' \
+//	//   -H 'Content-Type: application/json' \
+//	//   --data-raw '{"id":1}'
+func (r *Request) AsCurl() (string, error) {
+	c := r.client
+	httpReq, err := c.buildRequest(r)
+	if err != nil {
+		return "", err
+	}
+	body, err := c.readBodySafely(r.body)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("curl")
+	if httpReq.Method != http.MethodGet {
+		b.WriteString(" -X " + httpReq.Method)
+	}
+	if c.unixSocket != "" {
+		b.WriteString(" --unix-socket " + shellQuote(c.unixSocket))
+	}
+	b.WriteString(" " + shellQuote(httpReq.URL.String()))
+
+	sensitive := c.sensitiveHeaders()
+	for _, key := range sortedHeaderKeys(httpReq.Header) {
+		for _, value := range httpReq.Header[key] {
+			if isSensitiveHeader(sensitive, key) {
+				value = maskSensitiveValue(value)
+			}
+			b.WriteString(" \\\n  -H " + shellQuote(key+": "+value))
+		}
+	}
+	if len(body) > 0 {
+		b.WriteString(" \\\n  --data-raw " + shellQuote(string(body)))
+	}
+	return b.String(), nil
+}
+
+// Dump 以 HTTP/1.x 报文格式输出响应（状态行、响应头、响应体），用于问题反馈；
+// 敏感响应头（例如 Set-Cookie）按发送请求的客户端的配置脱敏
+//
+// 示例:
+//
+//	resp, err := client.Get("/orders/1").Do()
+//	if err == nil && resp.IsError() {
+//	    log.Printf("上游返回错误:\n%s", resp.Dump())
+//	}
+func (r *Response) Dump() string {
+	var b strings.Builder
+	proto := r.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+	status := r.Status
+	if status == "" {
+		status = fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode))
+	}
+	b.WriteString(proto + " " + status + "\r\n")
+
+	sensitive := r.sensitiveHeaders
+	if sensitive == nil {
+		sensitive = constants.DefaultSensitiveHeaders()
+	}
+	for _, key := range sortedHeaderKeys(r.Headers) {
+		for _, value := range r.Headers[key] {
+			if isSensitiveHeader(sensitive, key) {
+				value = maskSensitiveValue(value)
+			}
+			b.WriteString(key + ": " + value + "\r\n")
+		}
+	}
+	b.WriteString("\r\n")
+	b.Write(r.Body)
+	return b.String()
+}
+
+// sensitiveHeaders 返回需要脱敏的请求头，未配置Debug时使用默认列表
+func (c *Client) sensitiveHeaders() []string {
+	if c.debugConfig != nil && c.debugConfig.SensitiveHeaders != nil {
+		return c.debugConfig.SensitiveHeaders
+	}
+	return constants.DefaultSensitiveHeaders()
+}
+
+// isSensitiveHeader 判断 key 是否在 sensitive 中，不区分大小写
+func isSensitiveHeader(sensitive []string, key string) bool {
+	for _, name := range sensitive {
+		if strings.EqualFold(name, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedHeaderKeys 返回排序后的请求头名称，使输出稳定
+func sortedHeaderKeys(headers http.Header) []string {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// shellQuote 把 s 转义为 shell 参数：可打印文本使用单引号，包含控制字符或非UTF-8字节时使用 $'...'
+func shellQuote(s string) string {
+	if !needsANSIQuote(s) {
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	}
+	var b strings.Builder
+	b.WriteString("$'")
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case ch == '\\' || ch == '\'':
+			b.WriteByte('\\')
+			b.WriteByte(ch)
+		case ch == '\n':
+			b.WriteString(`\n`)
+		case ch == '\t':
+			b.WriteString(`\t`)
+		case ch < 0x20 || ch >= 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, ch)
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	b.WriteString("'")
+	return b.String()
+}
+
+// needsANSIQuote 判断 s 是否包含单引号无法表达的字节
+func needsANSIQuote(s string) bool {
+	if !utf8.ValidString(s) {
+		return true
+	}
+	for _, r := range s {
+		if r < 0x20 && r != '\n' && r != '\t' || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package httpclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// parseCurl 按 shell 规则拆分 AsCurl 的输出（只支持 AsCurl 使用的单引号、$'...' 和续行），
+// 还原出方法、URL、请求头和请求体
+func parseCurl(t *testing.T, cmd string) (method, url string, headers http.Header, body string) {
+	t.Helper()
+	var args []string
+	var cur strings.Builder
+	inArg := false
+	for i := 0; i < len(cmd); i++ {
+		switch ch := cmd[i]; {
+		case ch == '\\' && i+1 < len(cmd) && cmd[i+1] == '\n':
+			i++
+		case ch == '\\':
+			inArg = true
+			i++
+			cur.WriteByte(cmd[i])
+		case ch == ' ' || ch == '\n':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		case ch == '\'':
+			inArg = true
+			end := strings.IndexByte(cmd[i+1:], '\'')
+			cur.WriteString(cmd[i+1 : i+1+end])
+			i += end + 1
+		case ch == '$' && i+1 < len(cmd) && cmd[i+1] == '\'':
+			inArg = true
+			for i += 2; cmd[i] != '\''; i++ {
+				if cmd[i] != '\\' {
+					cur.WriteByte(cmd[i])
+					continue
+				}
+				i++
+				switch cmd[i] {
+				case 'n':
+					cur.WriteByte('\n')
+				case 't':
+					cur.WriteByte('\t')
+				case 'x':
+					var b byte
+					for _, h := range cmd[i+1 : i+3] {
+						b = b<<4 | byte(strings.IndexRune("0123456789abcdef", h))
+					}
+					cur.WriteByte(b)
+					i += 2
+				default:
+					cur.WriteByte(cmd[i])
+				}
+			}
+		default:
+			inArg = true
+			cur.WriteByte(ch)
+		}
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+
+	if len(args) == 0 || args[0] != "curl" {
+		t.Fatalf("Expected a curl command, got %q", cmd)
+	}
+	method, headers = http.MethodGet, http.Header{}
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case "-X":
+			i++
+			method = args[i]
+		case "-H":
+			i++
+			key, value, _ := strings.Cut(args[i], ": ")
+			headers.Add(key, value)
+		case "--data-raw":
+			i++
+			body = args[i]
+		default:
+			url = args[i]
+		}
+	}
+	return method, url, headers, body
+}
+
+func TestRequestAsCurlRoundTrip(t *testing.T) {
+	var received *http.Request
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.SetBaseURL(server.URL)
+	client.SetHeader("X-Tenant", "acme")
+	client.SetHeader("Authorization", "Bearer secret-token-value")
+
+	body := `{"name":"it's","note":"line1\nline2"}`
+	req := client.NewRequest(http.MethodPost, "/orders?dry_run=1").
+		Header("X-Request-Id", "req-1").
+		Body(bytes.NewBufferString(body)).
+		ContentType("application/json")
+
+	cmd, err := req.AsCurl()
+	if err != nil {
+		t.Fatalf("AsCurl failed: %v", err)
+	}
+	if strings.Contains(cmd, "secret-token") {
+		t.Errorf("Expected Authorization to be masked, got:\n%s", cmd)
+	}
+
+	method, url, headers, gotBody := parseCurl(t, cmd)
+	if method != http.MethodPost || url != server.URL+"/orders?dry_run=1" {
+		t.Errorf("Unexpected method/URL %s %s in:\n%s", method, url, cmd)
+	}
+	for key, want := range map[string]string{
+		"X-Tenant":      "acme",
+		"X-Request-Id":  "req-1",
+		"Content-Type":  "application/json",
+		"Authorization": "Bear****alue",
+	} {
+		if got := headers.Get(key); got != want {
+			t.Errorf("Header %s = %q, expected %q", key, got, want)
+		}
+	}
+	if gotBody != body {
+		t.Errorf("Body = %q, expected %q", gotBody, body)
+	}
+
+	// 渲染不消耗请求体，请求仍然可以发送
+	if _, err := req.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if string(receivedBody) != body || received.Header.Get("X-Tenant") != "acme" {
+		t.Errorf("Expected the request to be sent unchanged, got body %q headers %v", receivedBody, received.Header)
+	}
+}
+
+func TestRequestAsCurlGetAndErrors(t *testing.T) {
+	client := NewClient()
+	client.SetBaseURL("https://api.example.com")
+	client.SetDebug(&DebugConfig{SensitiveHeaders: []string{"X-Secret"}})
+	client.SetHeader("User-Agent", "kit-test")
+
+	cmd, err := client.NewRequest(http.MethodGet, "/users/1").Header("X-Secret", "short").AsCurl()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "curl 'https://api.example.com/users/1' \\\n  -H 'User-Agent: kit-test' \\\n  -H 'X-Secret: ****'"
+	if cmd != want {
+		t.Errorf("AsCurl() =\n%s\nexpected\n%s", cmd, want)
+	}
+
+	// 控制字符使用 $'...'
+	cmd, _ = client.NewRequest(http.MethodPut, "/blob").Body(strings.NewReader("a\x00b")).AsCurl()
+	if !strings.HasSuffix(cmd, `--data-raw $'a\x00b'`) {
+		t.Errorf("Expected ANSI-C quoted body, got:\n%s", cmd)
+	}
+
+	// 无法重复读取的请求体
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	if _, err := client.NewRequest(http.MethodPost, "/stream").Body(pr).AsCurl(); err == nil {
+		t.Error("Expected an error for a non-rewindable body")
+	}
+}
+
+func TestResponseDump(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abcdefghijklmnop")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"error":"exists"}`))
+	}))
+	defer server.Close()
+
+	resp, err := NewClient().Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump := resp.Dump()
+	for _, want := range []string{
+		"HTTP/1.1 409 Conflict\r\n",
+		"Content-Type: application/json\r\n",
+		"Set-Cookie: sess****mnop\r\n",
+		"\r\n\r\n{\"error\":\"exists\"}",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("Expected dump to contain %q, got:\n%s", want, dump)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+)
+
+// ErrorCategory 请求失败的分类
+type ErrorCategory int
+
+const (
+	// CategoryOther 其他错误（构建请求失败、拦截器返回的错误等）
+	CategoryOther ErrorCategory = iota
+	// CategoryDNS 域名解析失败
+	CategoryDNS
+	// CategoryConnectionRefused 连接被拒绝
+	CategoryConnectionRefused
+	// CategoryConnectionReset 连接被重置或意外关闭
+	CategoryConnectionReset
+	// CategoryTLS TLS握手或证书校验失败
+	CategoryTLS
+	// CategoryTimeout 超时（上下文截止时间或网络超时）
+	CategoryTimeout
+	// CategoryCanceled 上下文被取消
+	CategoryCanceled
+	// CategoryTooManyRedirects 重定向次数超出上限
+	CategoryTooManyRedirects
+	// CategoryBodyReadFailed 读取响应体失败（包括超出 MaxResponseBytes 和空闲超时）
+	CategoryBodyReadFailed
+	// CategoryExpectationFailed 响应不符合 Request.Expect 或 ClientOptions.Expectations 设置的期望
+	CategoryExpectationFailed
+)
+
+// String 返回分类名称
+func (c ErrorCategory) String() string {
+	switch c {
+	case CategoryDNS:
+		return "dns"
+	case CategoryConnectionRefused:
+		return "connection_refused"
+	case CategoryConnectionReset:
+		return "connection_reset"
+	case CategoryTLS:
+		return "tls"
+	case CategoryTimeout:
+		return "timeout"
+	case CategoryCanceled:
+		return "canceled"
+	case CategoryTooManyRedirects:
+		return "too_many_redirects"
+	case CategoryBodyReadFailed:
+		return "body_read_failed"
+	case CategoryExpectationFailed:
+		return "expectation_failed"
+	default:
+		return "other"
+	}
+}
+
+// IsUpstreamFailure 是否表示上游服务或网络故障，熔断器只统计这类错误
+// 调用方取消、重定向策略和本地错误不算
+func (c ErrorCategory) IsUpstreamFailure() bool {
+	switch c {
+	case CategoryDNS, CategoryConnectionRefused, CategoryConnectionReset, CategoryTLS, CategoryTimeout, CategoryBodyReadFailed:
+		return true
+	}
+	return false
+}
+
+// RequestError Do 返回的请求错误，保留原始错误供 errors.Is/As 使用
+//
+// 示例:
+//
+//	resp, err := client.Get("/users")
+//	var reqErr *httpclient.RequestError
+//	if errors.As(err, &reqErr) && reqErr.Category == httpclient.CategoryConnectionRefused {
+//	    // 切换到备用地址
+//	}
+type RequestError struct {
+	Method    string        // 请求方法
+	URL       string        // 去掉用户信息、查询参数和片段的请求地址
+	Attempts  int           // 实际尝试次数，请求未发出时为0
+	Category  ErrorCategory // 错误分类
+	Retryable bool          // 按分类判断是否可以重试（不考虑 RetryConfig.RetryableErrors）
+	Err       error         // 原始错误
+}
+
+// Error 实现error接口
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%s %s 失败 [%s, 尝试%d次]: %v", e.Method, e.URL, e.Category, e.Attempts, e.Err)
+}
+
+// Unwrap 返回原始错误
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// newRequestError 包装 Do 返回的错误
+func newRequestError(req *Request, httpReq *http.Request, attempts int, err error) *RequestError {
+	method, target := requestTarget(req, httpReq)
+	category, retryable := classifyError(err)
+	return &RequestError{
+		Method:    method,
+		URL:       target,
+		Attempts:  attempts,
+		Category:  category,
+		Retryable: retryable,
+		Err:       err,
+	}
+}
+
+// classifyError 对请求错误分类，并判断按分类是否可以重试
+func classifyError(err error) (category ErrorCategory, retryable bool) {
+	category = classify(err)
+	switch category {
+	case CategoryConnectionRefused, CategoryConnectionReset, CategoryTimeout, CategoryExpectationFailed:
+		retryable = true
+	case CategoryDNS:
+		// 域名不存在时重试没有意义，只重试临时故障
+		var dnsErr *net.DNSError
+		retryable = errors.As(err, &dnsErr) && (dnsErr.IsTimeout || dnsErr.IsTemporary)
+	case CategoryOther:
+		// 其他网络层错误（例如网络不可达）可以重试
+		var opErr *net.OpError
+		retryable = errors.As(err, &opErr)
+	}
+	// 重试预算耗尽后不应再重试
+	if errors.Is(err, ErrRetryBudgetExhausted) {
+		retryable = false
+	}
+	return category, retryable
+}
+
+// classify 按错误类型分类，无法识别类型时回退到错误信息匹配
+func classify(err error) ErrorCategory {
+	if err == nil {
+		return CategoryOther
+	}
+
+	var (
+		dnsErr      *net.DNSError
+		netErr      net.Error
+		certErr     *tls.CertificateVerificationError
+		unknownAuth x509.UnknownAuthorityError
+		hostErr     x509.HostnameError
+		invalidErr  x509.CertificateInvalidError
+		headerErr   tls.RecordHeaderError
+		alertErr    tls.AlertError
+	)
+
+	switch {
+	case errors.Is(err, ErrExpectationFailed):
+		return CategoryExpectationFailed
+	case errors.Is(err, context.Canceled):
+		return CategoryCanceled
+	case isResponseLimitError(err):
+		return CategoryBodyReadFailed
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return CategoryTimeout
+	case errors.As(err, &dnsErr):
+		return CategoryDNS
+	case errors.As(err, &certErr), errors.As(err, &unknownAuth), errors.As(err, &hostErr),
+		errors.As(err, &invalidErr), errors.As(err, &headerErr), errors.As(err, &alertErr):
+		return CategoryTLS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return CategoryConnectionRefused
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE), errors.Is(err, syscall.ECONNABORTED),
+		errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return CategoryConnectionReset
+	}
+
+	// 部分错误（例如 http.Client 的重定向上限、代理返回的错误）只有文本
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "stopped after") && strings.Contains(msg, "redirects"):
+		return CategoryTooManyRedirects
+	case strings.Contains(msg, "connection refused"):
+		return CategoryConnectionRefused
+	case strings.Contains(msg, "connection reset"), strings.Contains(msg, "broken pipe"):
+		return CategoryConnectionReset
+	case strings.Contains(msg, "no such host"):
+		return CategoryDNS
+	case strings.Contains(msg, "tls: "), strings.Contains(msg, "x509: "):
+		return CategoryTLS
+	}
+	return CategoryOther
+}
+
+// isRetryableError 判断错误是否应当重试：匹配 RetryableErrors 或按分类可以重试
+func isRetryableError(err error, retryableErrors []error) bool {
+	for _, retryableErr := range retryableErrors {
+		if errors.Is(err, retryableErr) {
+			return true
+		}
+	}
+	_, retryable := classifyError(err)
+	return retryable
+}
@@ -0,0 +1,181 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// dialError 模拟 http.Client 返回的拨号错误
+func dialError(errno syscall.Errno) error {
+	return &url.Error{
+		Op:  "Get",
+		URL: "http://example.com",
+		Err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", errno)},
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		category  ErrorCategory
+		retryable bool
+	}{
+		{"dns not found", &url.Error{Op: "Get", URL: "http://nope.invalid", Err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "nope.invalid", IsNotFound: true}}}, CategoryDNS, false},
+		{"dns temporary", &net.DNSError{Err: "server misbehaving", Name: "example.com", IsTemporary: true}, CategoryDNS, true},
+		{"connection refused", dialError(syscall.ECONNREFUSED), CategoryConnectionRefused, true},
+		{"connection reset", dialError(syscall.ECONNRESET), CategoryConnectionReset, true},
+		{"unexpected eof", &url.Error{Op: "Post", URL: "http://example.com", Err: io.EOF}, CategoryConnectionReset, true},
+		{"tls record header", &url.Error{Op: "Get", URL: "https://example.com", Err: tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}}, CategoryTLS, false},
+		{"unknown authority", &url.Error{Op: "Get", URL: "https://example.com", Err: &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}}, CategoryTLS, false},
+		{"canceled", &url.Error{Op: "Get", URL: "http://example.com", Err: context.Canceled}, CategoryCanceled, false},
+		{"deadline", &url.Error{Op: "Get", URL: "http://example.com", Err: context.DeadlineExceeded}, CategoryTimeout, true},
+		{"net timeout", &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ETIMEDOUT)}, CategoryTimeout, true},
+		{"redirects", &url.Error{Op: "Get", URL: "http://example.com", Err: errors.New("stopped after 10 redirects")}, CategoryTooManyRedirects, false},
+		{"response too large", fmt.Errorf("%w: 超过 1024 字节", ErrResponseTooLarge), CategoryBodyReadFailed, false},
+		{"read idle timeout", ErrReadIdleTimeout, CategoryBodyReadFailed, false},
+		{"network unreachable", dialError(syscall.ENETUNREACH), CategoryOther, true},
+		{"retry budget", fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, dialError(syscall.ECONNREFUSED)), CategoryConnectionRefused, false},
+		{"generic", errors.New("boom"), CategoryOther, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			category, retryable := classifyError(tt.err)
+			if category != tt.category || retryable != tt.retryable {
+				t.Errorf("classifyError(%v) = (%s, %v), want (%s, %v)", tt.err, category, retryable, tt.category, tt.retryable)
+			}
+		})
+	}
+}
+
+func TestErrorCategoryIsUpstreamFailure(t *testing.T) {
+	for _, category := range []ErrorCategory{CategoryCanceled, CategoryTooManyRedirects, CategoryOther} {
+		if category.IsUpstreamFailure() {
+			t.Errorf("%s should not count as upstream failure", category)
+		}
+	}
+	for _, category := range []ErrorCategory{CategoryDNS, CategoryConnectionRefused, CategoryConnectionReset, CategoryTLS, CategoryTimeout, CategoryBodyReadFailed} {
+		if !category.IsUpstreamFailure() {
+			t.Errorf("%s should count as upstream failure", category)
+		}
+	}
+}
+
+func TestRequestErrorConnectionRefused(t *testing.T) {
+	// 监听后立即关闭，得到一个没有服务的端口
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	client := NewClientWithOptions(ClientOptions{
+		Logger: &MockLogger{},
+		Retry: &RetryConfig{
+			MaxRetries:   2,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+		},
+	})
+	_, err = client.Get("http://" + addr + "/users?token=secret")
+
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("Expected *RequestError, got %T: %v", err, err)
+	}
+	if reqErr.Category != CategoryConnectionRefused || !reqErr.Retryable {
+		t.Errorf("Expected retryable connection_refused, got %s retryable=%v", reqErr.Category, reqErr.Retryable)
+	}
+	if reqErr.Method != http.MethodGet || reqErr.URL != "http://"+addr+"/users" {
+		t.Errorf("Unexpected method/url: %s %s", reqErr.Method, reqErr.URL)
+	}
+	if reqErr.Attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", reqErr.Attempts)
+	}
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Errorf("errors.Is should find ECONNREFUSED through the wrapper: %v", err)
+	}
+	if !strings.Contains(err.Error(), "connection_refused") || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("Error message should include category and cause: %v", err)
+	}
+}
+
+func TestRequestErrorTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(ClientOptions{Logger: &MockLogger{}})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.NewRequest("GET", server.URL).Context(ctx).Do()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("errors.Is should find context.DeadlineExceeded through the wrapper: %v", err)
+	}
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) || reqErr.Category != CategoryTimeout || reqErr.Attempts != 1 {
+		t.Errorf("Expected timeout RequestError after 1 attempt, got %+v", reqErr)
+	}
+}
+
+func TestRequestErrorNotRetried(t *testing.T) {
+	var calls int32
+	errCustom := errors.New("interceptor rejected")
+	client := NewClientWithOptions(ClientOptions{
+		Logger: &MockLogger{},
+		Retry: &RetryConfig{
+			MaxRetries:   3,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+		},
+	})
+	client.AddInterceptor(func(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, errCustom
+	})
+
+	_, err := client.Get("http://example.com")
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) || reqErr.Category != CategoryOther || reqErr.Retryable {
+		t.Fatalf("Expected non-retryable other RequestError, got %v", err)
+	}
+	if !errors.Is(err, errCustom) {
+		t.Errorf("errors.Is should find the original error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Non-retryable error should not be retried, got %d attempts", calls)
+	}
+}
+
+func TestRequestErrorBuildFailure(t *testing.T) {
+	client := NewClientWithOptions(ClientOptions{Logger: &MockLogger{}})
+	_, err := client.Get("http://[::1]:namedport")
+
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("Expected *RequestError, got %T: %v", err, err)
+	}
+	if reqErr.Attempts != 0 || reqErr.Category != CategoryOther {
+		t.Errorf("Build failure should report 0 attempts and other category, got %+v", reqErr)
+	}
+}
@@ -0,0 +1,321 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrExpectationFailed 响应不符合 Expect 设置的期望，Do 返回的错误可以通过 errors.Is 判断，
+// 通过 errors.As 获取 *ExpectationError 查看详情
+var ErrExpectationFailed = errors.New("响应不符合期望")
+
+// expectationSnippetBytes ExpectationError.Snippet 保留的响应体字节数
+const expectationSnippetBytes = 256
+
+// Expectation 对响应的期望，在每次尝试收到响应后检查，不满足时该次尝试按错误处理
+//
+// 内置的期望见 ExpectStatus、ExpectContentType、ExpectJSONSchema、ExpectBodyContains，
+// 也可以直接构造自定义期望:
+//
+//	notEmpty := httpclient.Expectation{
+//	    Name: "non-empty body",
+//	    Check: func(resp *http.Response, body []byte) error {
+//	        if len(body) == 0 {
+//	            return errors.New("响应体为空")
+//	        }
+//	        return nil
+//	    },
+//	}
+type Expectation struct {
+	Name string // 期望的名称，出现在 ExpectationError 中
+	// Check 检查响应，body 为已读取的完整响应体；返回非nil表示不满足期望
+	Check func(resp *http.Response, body []byte) error
+}
+
+// ExpectationError 响应不满足期望
+type ExpectationError struct {
+	Expectation string // 不满足的期望名称
+	StatusCode  int    // 响应状态码
+	ContentType string // 响应的 Content-Type
+	Snippet     string // 响应体开头的片段，用于判断上游实际返回了什么（例如HTML维护页面）
+	Err         error  // 期望检查返回的错误
+}
+
+// Error 实现error接口
+func (e *ExpectationError) Error() string {
+	return fmt.Sprintf("响应不符合期望 %s: %v (status=%d, content-type=%q, body=%q)",
+		e.Expectation, e.Err, e.StatusCode, e.ContentType, e.Snippet)
+}
+
+// Unwrap 返回期望检查返回的错误
+func (e *ExpectationError) Unwrap() error {
+	return e.Err
+}
+
+// Is 使 errors.Is(err, ErrExpectationFailed) 成立
+func (e *ExpectationError) Is(target error) bool {
+	return target == ErrExpectationFailed
+}
+
+// Expect 为本次请求添加期望，与 ClientOptions.Expectations 组合（先检查客户端级的期望）
+//
+// 不满足期望的尝试按错误响应处理：配置了重试时默认会重试（见 RetryConfig.NoRetryOnExpectationFailure），
+// 最终不满足时 Do 返回包含 *ExpectationError 的错误，不返回响应。
+//
+// 示例:
+//
+//	resp, err := client.NewRequest("GET", "/v1/rates").
+//	    Expect(httpclient.ExpectStatus(200), httpclient.ExpectContentType("application/json")).
+//	    Do()
+//	if errors.Is(err, httpclient.ErrExpectationFailed) {
+//	    var expErr *httpclient.ExpectationError
+//	    errors.As(err, &expErr)
+//	    log.Printf("上游返回了意外的响应: %s", expErr.Snippet)
+//	}
+func (r *Request) Expect(expectations ...Expectation) *Request {
+	r.expectations = append(r.expectations, expectations...)
+	return r
+}
+
+// ExpectStatus 期望响应状态码为 codes 之一
+func ExpectStatus(codes ...int) Expectation {
+	return Expectation{
+		Name: fmt.Sprintf("status %v", codes),
+		Check: func(resp *http.Response, body []byte) error {
+			for _, code := range codes {
+				if resp.StatusCode == code {
+					return nil
+				}
+			}
+			return fmt.Errorf("状态码 %d 不在 %v 中", resp.StatusCode, codes)
+		},
+	}
+}
+
+// ExpectContentType 期望响应的媒体类型为 mediaType，忽略大小写和 charset 等参数
+func ExpectContentType(mediaType string) Expectation {
+	want := strings.ToLower(mediaType)
+	return Expectation{
+		Name: "content-type " + mediaType,
+		Check: func(resp *http.Response, body []byte) error {
+			header := resp.Header.Get("Content-Type")
+			got, _, err := mime.ParseMediaType(header)
+			if err != nil || got != want {
+				return fmt.Errorf("Content-Type 为 %q", header)
+			}
+			return nil
+		},
+	}
+}
+
+// ExpectBodyContains 期望响应体包含 substr
+func ExpectBodyContains(substr string) Expectation {
+	return Expectation{
+		Name: fmt.Sprintf("body contains %q", substr),
+		Check: func(resp *http.Response, body []byte) error {
+			if !bytes.Contains(body, []byte(substr)) {
+				return fmt.Errorf("响应体不包含 %q", substr)
+			}
+			return nil
+		},
+	}
+}
+
+// ExpectJSONSchema 期望响应体是符合 schema 的JSON
+//
+// 只支持 JSON Schema 的常用子集，用于尽早发现上游返回的结构明显不对:
+//   - type: object、array、string、number、integer、boolean、null，或它们的数组
+//   - required: 对象必须包含的字段
+//   - properties: 对象字段的子 schema（未列出的字段不检查）
+//   - items: 数组元素的子 schema
+//
+// 其他关键字被忽略。schema 本身无效时每次检查都失败。
+//
+// 示例:
+//
+//	httpclient.ExpectJSONSchema([]byte(`{
+//	    "type": "object",
+//	    "required": ["id", "items"],
+//	    "properties": {
+//	        "id":    {"type": "integer"},
+//	        "items": {"type": "array", "items": {"type": "object", "required": ["sku"]}}
+//	    }
+//	}`))
+func ExpectJSONSchema(schema []byte) Expectation {
+	var root jsonSchema
+	schemaErr := json.Unmarshal(schema, &root)
+	return Expectation{
+		Name: "json schema",
+		Check: func(resp *http.Response, body []byte) error {
+			if schemaErr != nil {
+				return fmt.Errorf("无效的 schema: %w", schemaErr)
+			}
+			decoder := json.NewDecoder(bytes.NewReader(body))
+			decoder.UseNumber()
+			var value interface{}
+			if err := decoder.Decode(&value); err != nil {
+				return fmt.Errorf("响应体不是有效的JSON: %w", err)
+			}
+			return root.validate(value, "$")
+		},
+	}
+}
+
+// jsonSchema ExpectJSONSchema 支持的 schema 子集
+type jsonSchema struct {
+	Type       jsonSchemaTypes        `json:"type"`
+	Required   []string               `json:"required"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	Items      *jsonSchema            `json:"items"`
+}
+
+// jsonSchemaTypes type 关键字，可以是字符串或字符串数组
+type jsonSchemaTypes []string
+
+func (t *jsonSchemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = jsonSchemaTypes{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("type 必须是字符串或字符串数组: %s", data)
+	}
+	*t = multiple
+	return nil
+}
+
+// validate 校验 value，path 为出错时报告的位置，例如 $.items[0].sku
+func (s *jsonSchema) validate(value interface{}, path string) error {
+	if len(s.Type) > 0 && !s.matchesType(value) {
+		return fmt.Errorf("%s 期望类型 %s，实际为 %s", path, strings.Join(s.Type, "|"), jsonTypeOf(value))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s 缺少必需字段 %q", path, name)
+			}
+		}
+		for name, child := range s.Properties {
+			if field, ok := v[name]; ok && child != nil {
+				if err := child.validate(field, path+"."+name); err != nil {
+					return err
+				}
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(item, path+"["+strconv.Itoa(i)+"]"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) matchesType(value interface{}) bool {
+	actual := jsonTypeOf(value)
+	for _, want := range s.Type {
+		if want == actual || want == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeOf 返回 UseNumber 解码后的值对应的 JSON Schema 类型，整数值为 integer
+func jsonTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if f, err := v.Float64(); err == nil && f == math.Trunc(f) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+type expectationsContextKey struct{}
+
+// withExpectations 将客户端级和请求级的期望存入 context，供 executeAttempt 读取
+func withExpectations(ctx context.Context, client, request []Expectation) context.Context {
+	if len(client) == 0 && len(request) == 0 {
+		return ctx
+	}
+	expectations := make([]Expectation, 0, len(client)+len(request))
+	expectations = append(append(expectations, client...), request...)
+	return context.WithValue(ctx, expectationsContextKey{}, expectations)
+}
+
+// expectationsFromRequest 返回请求需要检查的期望
+func expectationsFromRequest(req *http.Request) []Expectation {
+	expectations, _ := req.Context().Value(expectationsContextKey{}).([]Expectation)
+	return expectations
+}
+
+// executeAttempt 执行一次尝试并检查期望
+// 有期望时读取完整的响应体（已受响应体限制约束）并替换为内存中的副本，不满足时关闭响应并返回 *ExpectationError
+func (c *Client) executeAttempt(req *http.Request) (*http.Response, error) {
+	resp, err := c.executeWithInterceptors(req)
+	expectations := expectationsFromRequest(req)
+	if err != nil || len(expectations) == 0 {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("读取响应体失败: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	for _, expectation := range expectations {
+		if checkErr := expectation.Check(resp, body); checkErr != nil {
+			return nil, &ExpectationError{
+				Expectation: expectation.Name,
+				StatusCode:  resp.StatusCode,
+				ContentType: resp.Header.Get("Content-Type"),
+				Snippet:     bodySnippet(body),
+				Err:         checkErr,
+			}
+		}
+	}
+	return resp, nil
+}
+
+// bodySnippet 返回响应体开头不超过 expectationSnippetBytes 字节的片段，不截断多字节字符
+func bodySnippet(body []byte) string {
+	if len(body) <= expectationSnippetBytes {
+		return string(body)
+	}
+	cut := expectationSnippetBytes
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	return string(body[:cut]) + "..."
+}
@@ -0,0 +1,194 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const maintenancePage = "<html><body><h1>Scheduled maintenance</h1></body></html>"
+
+func newExpectTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"id": 7, "name": "widget", "tags": ["a", "b"], "price": 9.5}`))
+		case "/html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(maintenancePage))
+		case "/created":
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestBuiltinExpectations(t *testing.T) {
+	server := newExpectTestServer(t)
+	client := NewClientWithOptions(ClientOptions{BaseURL: server.URL, Logger: &MockLogger{}})
+
+	schema := []byte(`{
+		"type": "object",
+		"required": ["id", "name"],
+		"properties": {
+			"id":    {"type": "integer"},
+			"name":  {"type": ["string", "null"]},
+			"tags":  {"type": "array", "items": {"type": "string"}},
+			"price": {"type": "number"}
+		}
+	}`)
+
+	tests := []struct {
+		name        string
+		path        string
+		expectation Expectation
+		wantErr     string // 为空表示期望满足
+	}{
+		{"status ok", "/created", ExpectStatus(200, 201), ""},
+		{"status mismatch", "/created", ExpectStatus(200), "状态码 201 不在 [200] 中"},
+		{"content type with charset", "/json", ExpectContentType("Application/JSON"), ""},
+		{"content type mismatch", "/html", ExpectContentType("application/json"), `Content-Type 为 "text/html"`},
+		{"body contains", "/json", ExpectBodyContains(`"widget"`), ""},
+		{"body missing", "/html", ExpectBodyContains(`"id"`), `响应体不包含 "\"id\""`},
+		{"schema ok", "/json", ExpectJSONSchema(schema), ""},
+		{"schema not json", "/html", ExpectJSONSchema(schema), "响应体不是有效的JSON"},
+		{"schema missing field", "/json", ExpectJSONSchema([]byte(`{"required": ["sku"]}`)), `$ 缺少必需字段 "sku"`},
+		{"schema wrong type", "/json", ExpectJSONSchema([]byte(`{"properties": {"price": {"type": "integer"}}}`)),
+			"$.price 期望类型 integer，实际为 number"},
+		{"schema item type", "/json", ExpectJSONSchema([]byte(`{"properties": {"tags": {"items": {"type": "boolean"}}}}`)),
+			"$.tags[0] 期望类型 boolean，实际为 string"},
+		{"schema invalid", "/json", ExpectJSONSchema([]byte(`{"type": 1}`)), "无效的 schema"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.NewRequest(http.MethodGet, tt.path).Expect(tt.expectation).Do()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Expected expectation to pass, got %v", err)
+				}
+				if tt.path == "/json" && !strings.Contains(resp.String(), "widget") {
+					t.Errorf("Expected the checked body to be returned, got %q", resp.String())
+				}
+				return
+			}
+
+			var expErr *ExpectationError
+			if !errors.As(err, &expErr) || !errors.Is(err, ErrExpectationFailed) {
+				t.Fatalf("Expected ExpectationError, got %v", err)
+			}
+			if resp != nil {
+				t.Error("Expected no response when an expectation fails")
+			}
+			if expErr.Expectation != tt.expectation.Name || !strings.Contains(expErr.Err.Error(), tt.wantErr) {
+				t.Errorf("Expected %s failing with %q, got %s: %v", tt.expectation.Name, tt.wantErr, expErr.Expectation, expErr.Err)
+			}
+		})
+	}
+}
+
+func TestExpectationErrorDetails(t *testing.T) {
+	server := newExpectTestServer(t)
+	client := NewClientWithOptions(ClientOptions{BaseURL: server.URL, Logger: &MockLogger{}})
+
+	_, err := client.NewRequest(http.MethodGet, "/html").Expect(ExpectContentType("application/json")).Do()
+
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) || reqErr.Category != CategoryExpectationFailed || !reqErr.Retryable {
+		t.Fatalf("Expected a retryable expectation_failed RequestError, got %#v", err)
+	}
+	var expErr *ExpectationError
+	errors.As(err, &expErr)
+	if expErr.StatusCode != http.StatusOK || expErr.ContentType != "text/html" || expErr.Snippet != maintenancePage {
+		t.Errorf("Unexpected error details %+v", expErr)
+	}
+	if !strings.Contains(err.Error(), "Scheduled maintenance") {
+		t.Errorf("Expected the snippet in the error message, got %q", err.Error())
+	}
+
+	long := strings.Repeat("数", expectationSnippetBytes)
+	if snippet := bodySnippet([]byte(long)); len(snippet) > expectationSnippetBytes+3 || !strings.HasSuffix(snippet, "...") ||
+		!strings.HasPrefix(long, strings.TrimSuffix(snippet, "...")) {
+		t.Errorf("Expected snippet to be cut on a rune boundary, got %q", snippet)
+	}
+}
+
+func TestClientExpectationsCompose(t *testing.T) {
+	server := newExpectTestServer(t)
+	client := NewClientWithOptions(ClientOptions{
+		BaseURL:      server.URL,
+		Logger:       &MockLogger{},
+		Expectations: []Expectation{ExpectContentType("application/json")},
+	})
+
+	// 客户端级期望对所有请求生效
+	if _, err := client.Get("/html"); !errors.Is(err, ErrExpectationFailed) {
+		t.Fatalf("Expected client expectation to fail for HTML, got %v", err)
+	}
+
+	// 请求级期望在客户端级期望之后检查
+	_, err := client.NewRequest(http.MethodGet, "/json").Expect(ExpectBodyContains("gadget")).Do()
+	var expErr *ExpectationError
+	if !errors.As(err, &expErr) || !strings.HasPrefix(expErr.Expectation, "body contains") {
+		t.Fatalf("Expected request expectation to fail after client expectation passed, got %v", err)
+	}
+	if _, err := client.NewRequest(http.MethodGet, "/json").Expect(ExpectStatus(200)).Do(); err != nil {
+		t.Fatalf("Expected both expectations to pass, got %v", err)
+	}
+
+	// 克隆的客户端保留客户端级期望
+	if _, err := client.Clone().Get("/html"); !errors.Is(err, ErrExpectationFailed) {
+		t.Errorf("Expected clone to keep client expectations, got %v", err)
+	}
+}
+
+func TestExpectationRetry(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 第一次返回维护页面，之后恢复正常
+		if calls.Add(1) == 1 {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(maintenancePage))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer server.Close()
+
+	newClient := func(noRetry bool) *Client {
+		return NewClientWithOptions(ClientOptions{
+			BaseURL:      server.URL,
+			Logger:       &MockLogger{},
+			Expectations: []Expectation{ExpectContentType("application/json")},
+			Retry: &RetryConfig{
+				MaxRetries:                  2,
+				InitialDelay:                time.Millisecond,
+				MaxDelay:                    time.Millisecond,
+				NoRetryOnExpectationFailure: noRetry,
+			},
+		})
+	}
+
+	resp, err := newClient(false).Get("/")
+	if err != nil {
+		t.Fatalf("Expected retry to recover from the transient content type, got %v", err)
+	}
+	if resp.String() != `{"ok": true}` || calls.Load() != 2 {
+		t.Errorf("Expected JSON after 2 attempts, got %q after %d", resp.String(), calls.Load())
+	}
+
+	calls.Store(0)
+	_, err = newClient(true).Get("/")
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) || !errors.Is(err, ErrExpectationFailed) || reqErr.Attempts != 1 || calls.Load() != 1 {
+		t.Errorf("Expected a single failed attempt with NoRetryOnExpectationFailure, got %v after %d calls", err, calls.Load())
+	}
+}
@@ -0,0 +1,153 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrResponseTooLarge 响应体超过 MaxResponseBytes
+	ErrResponseTooLarge = errors.New("响应体超过大小限制")
+	// ErrReadIdleTimeout 读取响应体时超过 ReadIdleTimeout 没有收到数据
+	ErrReadIdleTimeout = errors.New("读取响应体空闲超时")
+)
+
+// responseLimits 单个请求生效的响应体限制，0表示不限制
+type responseLimits struct {
+	maxBytes    int64
+	idleTimeout time.Duration
+}
+
+func (l responseLimits) enabled() bool {
+	return l.maxBytes > 0 || l.idleTimeout > 0
+}
+
+type responseLimitsContextKey struct{}
+
+// withResponseLimits 将请求的响应体限制存入 context，供 executeWithInterceptors 读取
+func withResponseLimits(ctx context.Context, limits responseLimits) context.Context {
+	if !limits.enabled() {
+		return ctx
+	}
+	return context.WithValue(ctx, responseLimitsContextKey{}, limits)
+}
+
+// responseLimitsFromRequest 返回请求的响应体限制
+func responseLimitsFromRequest(req *http.Request) responseLimits {
+	limits, _ := req.Context().Value(responseLimitsContextKey{}).(responseLimits)
+	return limits
+}
+
+// resolveResponseLimits 合并客户端默认值与请求级设置
+// 请求级设置为正数时覆盖默认值，为负数时取消该项限制
+func (c *Client) resolveResponseLimits(req *Request) responseLimits {
+	limits := responseLimits{maxBytes: c.maxResponseBytes, idleTimeout: c.readIdleTimeout}
+	switch {
+	case req.maxResponseBytes > 0:
+		limits.maxBytes = req.maxResponseBytes
+	case req.maxResponseBytes < 0:
+		limits.maxBytes = 0
+	}
+	switch {
+	case req.readIdleTimeout > 0:
+		limits.idleTimeout = req.readIdleTimeout
+	case req.readIdleTimeout < 0:
+		limits.idleTimeout = 0
+	}
+	return limits
+}
+
+// MaxResponseBytes 设置本次请求的响应体大小上限，覆盖 ClientOptions.MaxResponseBytes，负数表示不限制
+func (r *Request) MaxResponseBytes(n int64) *Request {
+	r.maxResponseBytes = n
+	return r
+}
+
+// ReadIdleTimeout 设置本次请求读取响应体的空闲超时，覆盖 ClientOptions.ReadIdleTimeout，负数表示不限制
+func (r *Request) ReadIdleTimeout(d time.Duration) *Request {
+	r.readIdleTimeout = d
+	return r
+}
+
+// limitResponse 对响应体应用大小限制和空闲超时
+// Content-Length 已超过上限时直接关闭响应体并返回 ErrResponseTooLarge，不读取任何数据
+func limitResponse(resp *http.Response, limits responseLimits) (*http.Response, error) {
+	if !limits.enabled() || resp.Body == nil || resp.Body == http.NoBody {
+		return resp, nil
+	}
+	if limits.maxBytes > 0 && resp.ContentLength > limits.maxBytes {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: Content-Length %d 超过 %d 字节", ErrResponseTooLarge, resp.ContentLength, limits.maxBytes)
+	}
+	resp.Body = newLimitedBody(resp.Body, limits)
+	return resp, nil
+}
+
+// isResponseLimitError 判断是否为响应体限制产生的错误，默认不重试
+func isResponseLimitError(err error) bool {
+	return errors.Is(err, ErrResponseTooLarge) || errors.Is(err, ErrReadIdleTimeout)
+}
+
+// limitedBody 限制读取字节数的响应体，并在长时间没有数据时关闭底层连接
+type limitedBody struct {
+	rc     io.ReadCloser
+	limits responseLimits
+	read   int64
+	timer  *time.Timer // 空闲超时计时器，未设置 idleTimeout 时为nil
+	idle   atomic.Bool // 计时器已触发
+}
+
+func newLimitedBody(rc io.ReadCloser, limits responseLimits) *limitedBody {
+	b := &limitedBody{rc: rc, limits: limits}
+	if limits.idleTimeout > 0 {
+		// 关闭响应体会使阻塞中的 Read 立即返回
+		b.timer = time.AfterFunc(limits.idleTimeout, func() {
+			b.idle.Store(true)
+			rc.Close()
+		})
+	}
+	return b
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	maxBytes := b.limits.maxBytes
+	if maxBytes > 0 {
+		if b.read > maxBytes {
+			return 0, b.tooLarge()
+		}
+		// 最多多读1字节，用于判断是否超出上限
+		if remaining := maxBytes - b.read + 1; int64(len(p)) > remaining {
+			p = p[:remaining]
+		}
+	}
+
+	n, err := b.rc.Read(p)
+	if b.idle.Load() {
+		return 0, fmt.Errorf("%w: %v 内未收到数据", ErrReadIdleTimeout, b.limits.idleTimeout)
+	}
+	if n > 0 && b.timer != nil {
+		b.timer.Reset(b.limits.idleTimeout)
+	}
+
+	b.read += int64(n)
+	if maxBytes > 0 && b.read > maxBytes {
+		return n - int(b.read-maxBytes), b.tooLarge()
+	}
+	return n, err
+}
+
+func (b *limitedBody) tooLarge() error {
+	return fmt.Errorf("%w: 超过 %d 字节", ErrResponseTooLarge, b.limits.maxBytes)
+}
+
+func (b *limitedBody) Close() error {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	return b.rc.Close()
+}
@@ -0,0 +1,247 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// endlessServer 持续输出数据直到客户端断开
+func endlessServer(t *testing.T, calls *atomic.Int64) *httptest.Server {
+	t.Helper()
+
+	chunk := []byte(strings.Repeat("x", 32*1024))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls != nil {
+			calls.Add(1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		for {
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+			select {
+			case <-r.Context().Done():
+				return
+			default:
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// slowServer 先输出一部分数据，然后每隔 interval 输出一个字节，共 count 次
+func slowServer(t *testing.T, interval time.Duration, count int) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+		w.Write([]byte("start"))
+		flusher.Flush()
+		for i := 0; i < count; i++ {
+			select {
+			case <-time.After(interval):
+			case <-r.Context().Done():
+				return
+			}
+			w.Write([]byte("."))
+			flusher.Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMaxResponseBytes_EndlessStream(t *testing.T) {
+	var calls atomic.Int64
+	server := endlessServer(t, &calls)
+
+	const limit = 256 * 1024
+	client := NewClientWithOptions(ClientOptions{
+		Timeout:          10 * time.Second,
+		Logger:           &MockLogger{},
+		MaxResponseBytes: limit,
+		Retry: &RetryConfig{
+			MaxRetries:   3,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+		},
+	})
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	_, err := client.Get(server.URL)
+
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("Expected ErrResponseTooLarge, got %v", err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 16*limit {
+		t.Errorf("Expected bounded memory, allocated %d bytes", allocated)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected too-large response not to be retried, got %d calls", calls.Load())
+	}
+}
+
+func TestMaxResponseBytes_ContentLength(t *testing.T) {
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Length", "10485760")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("partial"))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(ClientOptions{
+		Logger:           &MockLogger{},
+		MaxResponseBytes: 1024,
+		Retry: &RetryConfig{
+			MaxRetries:   2,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+		},
+	})
+
+	_, err := client.Get(server.URL)
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("Expected ErrResponseTooLarge, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Content-Length 10485760") {
+		t.Errorf("Expected error to mention Content-Length, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected no retry, got %d calls", calls.Load())
+	}
+}
+
+func TestMaxResponseBytes_RetryableWhenConfigured(t *testing.T) {
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Length", "2048")
+		w.Write([]byte(strings.Repeat("a", 2048)))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(ClientOptions{
+		Logger:           &MockLogger{},
+		MaxResponseBytes: 1024,
+		Retry: &RetryConfig{
+			MaxRetries:      2,
+			InitialDelay:    time.Millisecond,
+			MaxDelay:        time.Millisecond,
+			RetryableErrors: []error{ErrResponseTooLarge},
+		},
+	})
+
+	_, err := client.Get(server.URL)
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("Expected ErrResponseTooLarge, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("Expected RetryableErrors to enable retries, got %d calls", calls.Load())
+	}
+}
+
+func TestMaxResponseBytes_WithinLimitAndOverride(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", 100)))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(ClientOptions{Logger: &MockLogger{}, MaxResponseBytes: 100})
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected body at the limit to succeed, got %v", err)
+	}
+	if len(resp.Body) != 100 {
+		t.Errorf("Expected 100 bytes, got %d", len(resp.Body))
+	}
+
+	if _, err := client.NewRequest("GET", server.URL).MaxResponseBytes(10).Do(); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("Expected per-request limit to apply, got %v", err)
+	}
+
+	strict := NewClientWithOptions(ClientOptions{Logger: &MockLogger{}, MaxResponseBytes: 10})
+	if _, err := strict.NewRequest("GET", server.URL).MaxResponseBytes(-1).Do(); err != nil {
+		t.Errorf("Expected negative override to disable the limit, got %v", err)
+	}
+}
+
+func TestReadIdleTimeout_SlowStream(t *testing.T) {
+	server := slowServer(t, 500*time.Millisecond, 10)
+
+	client := NewClientWithOptions(ClientOptions{
+		Timeout:         10 * time.Second,
+		Logger:          &MockLogger{},
+		ReadIdleTimeout: 100 * time.Millisecond,
+	})
+
+	start := time.Now()
+	_, err := client.Get(server.URL)
+	if !errors.Is(err, ErrReadIdleTimeout) {
+		t.Fatalf("Expected ErrReadIdleTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected watchdog to abort early, took %v", elapsed)
+	}
+}
+
+func TestReadIdleTimeout_SteadyStream(t *testing.T) {
+	// 总耗时超过空闲超时，但数据持续到达
+	server := slowServer(t, 20*time.Millisecond, 15)
+
+	client := NewClientWithOptions(ClientOptions{
+		Logger:          &MockLogger{},
+		ReadIdleTimeout: 150 * time.Millisecond,
+	})
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected steady stream to succeed, got %v", err)
+	}
+	if resp.String() != "start"+strings.Repeat(".", 15) {
+		t.Errorf("Unexpected body %q", resp.String())
+	}
+
+	// 请求级覆盖
+	if _, err := client.NewRequest("GET", server.URL).ReadIdleTimeout(5 * time.Millisecond).Do(); !errors.Is(err, ErrReadIdleTimeout) {
+		t.Errorf("Expected per-request idle timeout to apply, got %v", err)
+	}
+}
+
+func TestMaxResponseBytes_DebugCapture(t *testing.T) {
+	server := endlessServer(t, nil)
+
+	logger := &MockLogger{}
+	client := NewClientWithOptions(ClientOptions{
+		Logger:           logger,
+		MaxResponseBytes: 64 * 1024,
+		Debug:            &DebugConfig{Enabled: true, LogRequestBody: true, LogResponseBody: true, MaxBodySize: 1024},
+	})
+
+	_, err := client.Get(server.URL)
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("Expected ErrResponseTooLarge, got %v", err)
+	}
+	found := false
+	for _, log := range logger.errorLogs {
+		if strings.Contains(log, "响应体超过大小限制") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected debug output to record the limit error, got %v", logger.errorLogs)
+	}
+}
@@ -0,0 +1,288 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMTLSReloadInterval MTLSConfig.ReloadInterval 为 0 时检查证书文件变化的间隔
+const DefaultMTLSReloadInterval = time.Minute
+
+// ErrMTLSNotConfigured 客户端没有配置 MTLS
+var ErrMTLSNotConfigured = errors.New("客户端没有配置MTLS")
+
+// MTLSConfig 双向TLS（mTLS）配置，从PEM文件加载客户端证书和CA，文件更新后自动重新加载
+//
+// 证书轮换（例如 cert-manager 每60天续期）不需要重启进程：发送请求时按 ReloadInterval
+// 检查文件的修改时间，有变化时重新加载。重新加载失败（例如证书和私钥只更新了一个）时
+// 继续使用旧证书并记录日志，下次检查时重试。新证书只用于之后建立的连接。
+//
+// 示例:
+//
+//	client, err := httpclient.NewClientWithOptionsE(httpclient.ClientOptions{
+//	    BaseURL: "https://payments.internal",
+//	    MTLS: &httpclient.MTLSConfig{
+//	        CertFile: "/etc/tls/tls.crt",
+//	        KeyFile:  "/etc/tls/tls.key",
+//	        CAFile:   "/etc/tls/ca.crt",
+//	    },
+//	})
+type MTLSConfig struct {
+	CertFile string // 客户端证书（PEM），可以包含中间证书
+	KeyFile  string // 客户端私钥（PEM）
+	CAFile   string // 验证服务端证书的CA（PEM），为空时使用系统根证书
+
+	ServerName         string // 验证服务端证书时使用的名称，为空时使用请求的主机名
+	InsecureSkipVerify bool   // 不验证服务端证书，仅用于测试
+
+	// ReloadInterval 检查证书文件变化的最小间隔，0 使用 DefaultMTLSReloadInterval，负数不自动检查
+	ReloadInterval time.Duration
+}
+
+// validate 检查文件配置是否完整
+func (m *MTLSConfig) validate() error {
+	if (m.CertFile == "") != (m.KeyFile == "") {
+		return errors.New("MTLS.CertFile 和 MTLS.KeyFile 必须同时设置")
+	}
+	if m.CertFile == "" && m.CAFile == "" {
+		return errors.New("MTLS 至少需要设置 CertFile/KeyFile 或 CAFile")
+	}
+	return nil
+}
+
+// fileStamp 文件的修改时间和大小，用于判断文件是否变化
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) (fileStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// mtlsSource 持有当前的客户端证书和使用当前CA的传输层
+//
+// 客户端证书通过 GetClientCertificate 在每次握手时读取；CA 变化时复制出使用新 RootCAs 的传输层并替换，
+// 旧传输层的空闲连接随即关闭。
+type mtlsSource struct {
+	cfg    MTLSConfig
+	logger Logger
+
+	cert      atomic.Pointer[tls.Certificate]
+	transport atomic.Pointer[http.Transport]
+	nextCheck atomic.Int64 // 下次检查文件的时间（UnixNano）
+
+	mu                           sync.Mutex // 串行化重新加载，保护以下字段
+	certStamp, keyStamp, caStamp fileStamp
+	rootCAs                      *x509.CertPool // 当前的CA，未设置 CAFile 时为nil
+}
+
+// newMTLSSource 加载初始的证书和CA，任何文件加载失败都返回错误
+func newMTLSSource(cfg MTLSConfig, logger Logger) (*mtlsSource, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if cfg.ReloadInterval == 0 {
+		cfg.ReloadInterval = DefaultMTLSReloadInterval
+	}
+	s := &mtlsSource{cfg: cfg, logger: logger}
+	if _, err := s.reload(true); err != nil {
+		return nil, err
+	}
+	s.nextCheck.Store(time.Now().Add(cfg.ReloadInterval).UnixNano())
+	return s, nil
+}
+
+// install 在传输层上应用MTLS配置，base 为用户提供的 TLS 配置（可为nil），返回发送请求时检查文件变化的传输层
+func (s *mtlsSource) install(transport *http.Transport, base *tls.Config) http.RoundTripper {
+	var tlsConfig *tls.Config
+	if base != nil {
+		tlsConfig = base.Clone()
+	} else {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if s.cfg.CertFile != "" {
+		tlsConfig.Certificates = nil
+		tlsConfig.GetClientCertificate = s.clientCertificate
+	}
+	if s.cfg.ServerName != "" {
+		tlsConfig.ServerName = s.cfg.ServerName
+	}
+	if s.cfg.InsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rootCAs != nil {
+		tlsConfig.RootCAs = s.rootCAs
+	}
+	transport.TLSClientConfig = tlsConfig
+	s.transport.Store(transport)
+	return &mtlsTransport{source: s}
+}
+
+// clientCertificate 握手时返回当前的客户端证书
+func (s *mtlsSource) clientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return s.cert.Load(), nil
+}
+
+// maybeReload 距离上次检查超过 ReloadInterval 时检查文件变化，失败时记录日志并继续使用旧证书
+func (s *mtlsSource) maybeReload() {
+	if s.cfg.ReloadInterval < 0 {
+		return
+	}
+	// 只有更新了检查时间的协程执行检查，其他协程直接使用当前证书
+	now := time.Now().UnixNano()
+	next := s.nextCheck.Load()
+	if now < next || !s.nextCheck.CompareAndSwap(next, now+int64(s.cfg.ReloadInterval)) {
+		return
+	}
+
+	changed, err := s.reload(false)
+	switch {
+	case err != nil:
+		s.log(true, "MTLS证书重新加载失败，继续使用旧证书", err)
+	case changed:
+		s.log(false, "MTLS证书已重新加载", nil)
+	}
+}
+
+// reloadAll 立即重新加载全部文件，失败时保留旧证书并返回错误
+func (s *mtlsSource) reloadAll() error {
+	_, err := s.reload(true)
+	return err
+}
+
+// reload 重新加载变化的文件（force 时全部加载），返回是否有文件被更新
+// 证书和CA分别加载，其中一个失败不影响另一个
+func (s *mtlsSource) reload(force bool) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	changed := false
+	if s.cfg.CertFile != "" {
+		updated, err := s.reloadCertLocked(force)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		changed = changed || updated
+	}
+	if s.cfg.CAFile != "" {
+		updated, err := s.reloadCALocked(force)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		changed = changed || updated
+	}
+	return changed, errors.Join(errs...)
+}
+
+func (s *mtlsSource) reloadCertLocked(force bool) (bool, error) {
+	certStamp, err := statFile(s.cfg.CertFile)
+	if err != nil {
+		return false, fmt.Errorf("读取客户端证书失败: %w", err)
+	}
+	keyStamp, err := statFile(s.cfg.KeyFile)
+	if err != nil {
+		return false, fmt.Errorf("读取客户端私钥失败: %w", err)
+	}
+	if !force && certStamp == s.certStamp && keyStamp == s.keyStamp {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(s.cfg.CertFile, s.cfg.KeyFile)
+	if err != nil {
+		return false, fmt.Errorf("加载客户端证书失败: %w", err)
+	}
+	s.cert.Store(&cert)
+	s.certStamp, s.keyStamp = certStamp, keyStamp
+	return true, nil
+}
+
+func (s *mtlsSource) reloadCALocked(force bool) (bool, error) {
+	caStamp, err := statFile(s.cfg.CAFile)
+	if err != nil {
+		return false, fmt.Errorf("读取CA证书失败: %w", err)
+	}
+	if !force && caStamp == s.caStamp {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(s.cfg.CAFile)
+	if err != nil {
+		return false, fmt.Errorf("读取CA证书失败: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return false, fmt.Errorf("CA证书文件 %s 中没有有效的PEM证书", s.cfg.CAFile)
+	}
+	s.caStamp = caStamp
+	s.rootCAs = pool
+
+	// 尚未创建传输层时由 install 使用
+	current := s.transport.Load()
+	if current == nil {
+		return true, nil
+	}
+	next := current.Clone()
+	next.TLSClientConfig.RootCAs = pool
+	s.transport.Store(next)
+	current.CloseIdleConnections()
+	return true, nil
+}
+
+// log 输出重新加载的结果，未设置 Logger 时使用标准库 log
+func (s *mtlsSource) log(failed bool, msg string, err error) {
+	if s.logger == nil {
+		if err != nil {
+			log.Printf("%s: %v", msg, err)
+		} else {
+			log.Print(msg)
+		}
+		return
+	}
+	if failed {
+		s.logger.Warn(msg, "error", err.Error())
+		return
+	}
+	s.logger.Info(msg, "cert_file", s.cfg.CertFile, "ca_file", s.cfg.CAFile)
+}
+
+// mtlsTransport 发送请求前检查证书文件变化，请求交给当前的传输层
+type mtlsTransport struct {
+	source *mtlsSource
+}
+
+func (t *mtlsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.source.maybeReload()
+	return t.source.transport.Load().RoundTrip(req)
+}
+
+// CloseIdleConnections 关闭当前传输层的空闲连接
+func (t *mtlsTransport) CloseIdleConnections() {
+	t.source.transport.Load().CloseIdleConnections()
+}
+
+// ReloadMTLS 立即重新加载 MTLS 的证书和CA文件，不等待 ReloadInterval，用于测试和运维操作
+//
+// 加载失败时继续使用旧证书并返回错误；没有配置 MTLS 时返回 ErrMTLSNotConfigured。
+func (c *Client) ReloadMTLS() error {
+	if c.mtls == nil {
+		return ErrMTLSNotConfigured
+	}
+	return c.mtls.reloadAll()
+}
@@ -0,0 +1,302 @@
+package httpclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testCA 测试用的证书颁发机构
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+var testSerial atomic.Int64
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(testSerial.Add(1)),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue 签发叶子证书，返回证书和私钥的PEM
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(testSerial.Add(1)),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func (ca *testCA) serverCert(t *testing.T) *tls.Certificate {
+	t.Helper()
+	certPEM, keyPEM := ca.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &cert
+}
+
+// writeFile 写入文件并推进修改时间，避免文件系统时间精度导致变化检测不到
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	var modTime time.Time
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime().Add(time.Second)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if !modTime.IsZero() {
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// mtlsFixture 要求客户端证书的测试服务端和客户端证书文件
+type mtlsFixture struct {
+	server     *httptest.Server
+	serverCert atomic.Pointer[tls.Certificate]
+	clientCA   *testCA
+	dir        string
+	certFile   string
+	keyFile    string
+	caFile     string
+}
+
+func newMTLSFixture(t *testing.T) *mtlsFixture {
+	t.Helper()
+	serverCA := newTestCA(t, "server-ca")
+	f := &mtlsFixture{
+		clientCA: newTestCA(t, "client-ca"),
+		dir:      t.TempDir(),
+	}
+	f.certFile = filepath.Join(f.dir, "tls.crt")
+	f.keyFile = filepath.Join(f.dir, "tls.key")
+	f.caFile = filepath.Join(f.dir, "ca.crt")
+	f.serverCert.Store(serverCA.serverCert(t))
+	writeFile(t, f.caFile, serverCA.pem)
+	f.writeClientCert(t, "client-1")
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(f.clientCA.cert)
+	f.server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	// httptest 会填充默认证书，通过 GetConfigForClient 使用可替换的服务端证书
+	f.server.TLS = &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return &tls.Config{
+				Certificates: []tls.Certificate{*f.serverCert.Load()},
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAs:    clientCAs,
+			}, nil
+		},
+	}
+	f.server.StartTLS()
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *mtlsFixture) writeClientCert(t *testing.T, name string) {
+	t.Helper()
+	certPEM, keyPEM := f.clientCA.issue(t, name, x509.ExtKeyUsageClientAuth)
+	writeFile(t, f.certFile, certPEM)
+	writeFile(t, f.keyFile, keyPEM)
+}
+
+func (f *mtlsFixture) client(t *testing.T, logger Logger, reloadInterval time.Duration) *Client {
+	t.Helper()
+	client, err := NewClientWithOptionsE(ClientOptions{
+		Logger: logger,
+		// 每个请求建立新连接，使新证书立即生效
+		Pool: &PoolConfig{DisableKeepAlives: true},
+		MTLS: &MTLSConfig{
+			CertFile:       f.certFile,
+			KeyFile:        f.keyFile,
+			CAFile:         f.caFile,
+			ReloadInterval: reloadInterval,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClientWithOptionsE: %v", err)
+	}
+	return client
+}
+
+func expectPeer(t *testing.T, client *Client, url, want string) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if got := resp.String(); got != want {
+		t.Errorf("expected server to see client cert %q, got %q", want, got)
+	}
+}
+
+func TestMTLS_Request(t *testing.T) {
+	f := newMTLSFixture(t)
+	client := f.client(t, nil, -1)
+	expectPeer(t, client, f.server.URL, "client-1")
+
+	// 没有客户端证书的请求被服务端拒绝
+	plain, err := NewClientWithOptionsE(ClientOptions{MTLS: &MTLSConfig{CAFile: f.caFile}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plain.Get(f.server.URL); err == nil {
+		t.Error("expected request without client certificate to fail")
+	}
+}
+
+func TestMTLS_ReloadMTLS(t *testing.T) {
+	f := newMTLSFixture(t)
+	client := f.client(t, nil, -1)
+	expectPeer(t, client, f.server.URL, "client-1")
+
+	f.writeClientCert(t, "client-2")
+	// 关闭自动检查时继续使用旧证书
+	expectPeer(t, client, f.server.URL, "client-1")
+
+	if err := client.ReloadMTLS(); err != nil {
+		t.Fatalf("ReloadMTLS: %v", err)
+	}
+	expectPeer(t, client, f.server.URL, "client-2")
+	// Clone 共享证书
+	expectPeer(t, client.Clone(), f.server.URL, "client-2")
+}
+
+func TestMTLS_ReloadOnInterval(t *testing.T) {
+	f := newMTLSFixture(t)
+	logger := &MockLogger{}
+	client := f.client(t, logger, 10*time.Millisecond)
+	expectPeer(t, client, f.server.URL, "client-1")
+
+	f.writeClientCert(t, "client-2")
+	time.Sleep(20 * time.Millisecond)
+	expectPeer(t, client, f.server.URL, "client-2")
+	if len(logger.infoLogs) == 0 {
+		t.Error("expected reload to be logged")
+	}
+}
+
+func TestMTLS_ReloadFailureKeepsOldCert(t *testing.T) {
+	f := newMTLSFixture(t)
+	logger := &MockLogger{}
+	client := f.client(t, logger, 10*time.Millisecond)
+
+	// 证书已更新但私钥还没有写入
+	certPEM, _ := f.clientCA.issue(t, "client-2", x509.ExtKeyUsageClientAuth)
+	writeFile(t, f.certFile, certPEM)
+
+	if err := client.ReloadMTLS(); err == nil {
+		t.Error("expected ReloadMTLS to fail with mismatched key")
+	}
+	time.Sleep(20 * time.Millisecond)
+	expectPeer(t, client, f.server.URL, "client-1")
+	if len(logger.warnLogs) == 0 {
+		t.Error("expected failed reload to be logged as warning")
+	}
+}
+
+func TestMTLS_CARotation(t *testing.T) {
+	f := newMTLSFixture(t)
+	client := f.client(t, nil, -1)
+	expectPeer(t, client, f.server.URL, "client-1")
+
+	newCA := newTestCA(t, "server-ca-2")
+	f.serverCert.Store(newCA.serverCert(t))
+	if _, err := client.Get(f.server.URL); err == nil {
+		t.Fatal("expected server certificate from unknown CA to be rejected")
+	}
+
+	writeFile(t, f.caFile, newCA.pem)
+	if err := client.ReloadMTLS(); err != nil {
+		t.Fatalf("ReloadMTLS: %v", err)
+	}
+	expectPeer(t, client, f.server.URL, "client-1")
+}
+
+func TestMTLS_InvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	_, err := NewClientWithOptionsE(ClientOptions{MTLS: &MTLSConfig{
+		CertFile: filepath.Join(dir, "missing.crt"),
+		KeyFile:  filepath.Join(dir, "missing.key"),
+	}})
+	if !errors.Is(err, ErrInvalidOptions) || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected ErrInvalidOptions wrapping os.ErrNotExist, got %v", err)
+	}
+
+	_, err = NewClientWithOptionsE(ClientOptions{MTLS: &MTLSConfig{CertFile: "tls.crt"}})
+	if !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("expected ErrInvalidOptions for missing KeyFile, got %v", err)
+	}
+
+	// NewClientWithOptions 记录警告并创建不使用 MTLS 的客户端
+	logger := &MockLogger{}
+	client := NewClientWithOptions(ClientOptions{Logger: logger, MTLS: &MTLSConfig{CAFile: filepath.Join(dir, "missing.crt")}})
+	if client.mtls != nil || len(logger.warnLogs) == 0 {
+		t.Errorf("expected MTLS to be dropped with a warning, warnings: %v", logger.warnLogs)
+	}
+	if err := client.ReloadMTLS(); !errors.Is(err, ErrMTLSNotConfigured) {
+		t.Errorf("expected ErrMTLSNotConfigured, got %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"sync"
+)
+
+// ErrInvalidOptions ClientOptions 中存在无效或相互矛盾的设置
+var ErrInvalidOptions = errors.New("无效的客户端选项")
+
+// modulePath go-kit 的模块路径，用于从构建信息中读取版本
+const modulePath = "github.com/tsopia/go-kit"
+
+var (
+	defaultUserAgentOnce  sync.Once
+	defaultUserAgentValue string
+)
+
+// DefaultUserAgent 未设置 UserAgent 时使用的默认值，形如 go-kit-httpclient/v1.2.3
+// 版本来自构建信息，无法获取时为 (devel)
+func DefaultUserAgent() string {
+	defaultUserAgentOnce.Do(func() {
+		version := "(devel)"
+		if info, ok := debug.ReadBuildInfo(); ok {
+			if info.Main.Path == modulePath && info.Main.Version != "" {
+				version = info.Main.Version
+			}
+			for _, dep := range info.Deps {
+				if dep.Path == modulePath && dep.Version != "" {
+					version = dep.Version
+				}
+			}
+		}
+		defaultUserAgentValue = "go-kit-httpclient/" + version
+	})
+	return defaultUserAgentValue
+}
+
+// Validate 检查无效或相互矛盾的选项，返回包装 ErrInvalidOptions 的错误，多个问题合并返回
+func (o ClientOptions) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrInvalidOptions, fmt.Sprintf(format, args...)))
+	}
+
+	if o.Timeout < 0 {
+		invalid("Timeout 不能为负数: %v", o.Timeout)
+	}
+	if o.NoTimeout && o.Timeout > 0 {
+		invalid("NoTimeout 与 Timeout=%v 不能同时设置", o.Timeout)
+	}
+	if p := o.Pool; p != nil {
+		if p.MaxIdleConns > 0 && p.MaxIdleConnsPerHost > p.MaxIdleConns {
+			invalid("Pool.MaxIdleConnsPerHost (%d) 大于 Pool.MaxIdleConns (%d)", p.MaxIdleConnsPerHost, p.MaxIdleConns)
+		}
+	}
+	if r := o.Retry; r != nil {
+		if r.MaxDelay > 0 && r.MaxDelay < r.InitialDelay {
+			invalid("Retry.MaxDelay (%v) 小于 Retry.InitialDelay (%v)", r.MaxDelay, r.InitialDelay)
+		}
+	}
+	if o.MTLS != nil {
+		if err := o.MTLS.validate(); err != nil {
+			invalid("%v", err)
+		}
+	}
+	if o.Shadow != nil {
+		if err := o.Shadow.validate(); err != nil {
+			invalid("%v", err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// withDefaults 为零值选项填充与 NewClient 相同的默认值
+func (o ClientOptions) withDefaults() ClientOptions {
+	if o.Timeout == 0 && !o.NoTimeout {
+		o.Timeout = defaultTimeout
+	}
+	if o.Pool == nil {
+		o.Pool = defaultPoolConfig()
+	}
+	if o.UserAgent == "" && !hasHeader(o.Headers, "User-Agent") {
+		o.UserAgent = DefaultUserAgent()
+	}
+	return o
+}
+
+// fixup 修正 Validate 报告的问题，保持 NewClientWithOptions 的向后兼容
+// Pool 和 Retry 复制后再修改，不影响调用方的配置
+func (o ClientOptions) fixup() ClientOptions {
+	if o.Timeout < 0 {
+		o.Timeout = defaultTimeout
+	}
+	if o.NoTimeout && o.Timeout > 0 {
+		o.NoTimeout = false
+	}
+	if p := o.Pool; p != nil && p.MaxIdleConns > 0 && p.MaxIdleConnsPerHost > p.MaxIdleConns {
+		pool := *p
+		pool.MaxIdleConnsPerHost = pool.MaxIdleConns
+		o.Pool = &pool
+	}
+	if r := o.Retry; r != nil && r.MaxDelay > 0 && r.MaxDelay < r.InitialDelay {
+		retry := *r
+		retry.MaxDelay = retry.InitialDelay
+		o.Retry = &retry
+	}
+	if o.MTLS != nil && o.MTLS.validate() != nil {
+		o.MTLS = nil
+	}
+	if o.Shadow != nil && o.Shadow.validate() != nil {
+		o.Shadow = nil
+	}
+	return o
+}
+
+// warnInvalidOptions 记录被自动修正的选项，未设置 Logger 时使用标准库 log
+func warnInvalidOptions(logger Logger, err error) {
+	const msg = "HTTP客户端选项无效，已自动修正"
+	if logger != nil {
+		logger.Warn(msg, "error", err.Error())
+		return
+	}
+	log.Printf("%s: %v", msg, err)
+}
+
+// hasHeader 不区分大小写地判断请求头是否存在
+func hasHeader(headers map[string]string, name string) bool {
+	for key := range headers {
+		if http.CanonicalHeaderKey(key) == name {
+			return true
+		}
+	}
+	return false
+}
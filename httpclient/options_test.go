@@ -0,0 +1,144 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func transportOf(t *testing.T, c *Client) *http.Transport {
+	t.Helper()
+	transport, ok := c.httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected *http.Transport, got %T", c.httpClient.Transport)
+	}
+	return transport
+}
+
+func TestZeroValueOptionsDefaults(t *testing.T) {
+	var gotUA string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUA = r.Header.Get("User-Agent")
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(ClientOptions{})
+
+	if client.httpClient.Timeout != defaultTimeout {
+		t.Errorf("expected default timeout %v, got %v", defaultTimeout, client.httpClient.Timeout)
+	}
+	pool := defaultPoolConfig()
+	transport := transportOf(t, client)
+	if transport.MaxIdleConns != pool.MaxIdleConns ||
+		transport.MaxIdleConnsPerHost != pool.MaxIdleConnsPerHost ||
+		transport.MaxConnsPerHost != pool.MaxConnsPerHost {
+		t.Errorf("expected default pool settings, got %d/%d/%d",
+			transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.MaxConnsPerHost)
+	}
+
+	if _, err := client.Get(server.URL); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if gotUA != DefaultUserAgent() || !strings.HasPrefix(gotUA, "go-kit-httpclient/") {
+		t.Errorf("expected default User-Agent, got %q", gotUA)
+	}
+}
+
+func TestExplicitUserAgentKept(t *testing.T) {
+	client := NewClientWithOptions(ClientOptions{Headers: map[string]string{"user-agent": "custom/1.0"}})
+	if _, ok := client.headers["User-Agent"]; ok {
+		t.Error("default User-Agent should not override one set in Headers")
+	}
+
+	client = NewClientWithOptions(ClientOptions{UserAgent: "svc/2.0"})
+	if client.headers["User-Agent"] != "svc/2.0" {
+		t.Errorf("expected svc/2.0, got %q", client.headers["User-Agent"])
+	}
+}
+
+func TestNoTimeout(t *testing.T) {
+	client := NewClientWithOptions(ClientOptions{NoTimeout: true})
+	if client.httpClient.Timeout != 0 {
+		t.Errorf("expected no timeout, got %v", client.httpClient.Timeout)
+	}
+}
+
+func TestNewClientWithOptionsE_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ClientOptions
+		want string
+	}{
+		{"negative timeout", ClientOptions{Timeout: -time.Second}, "Timeout"},
+		{"no timeout with timeout", ClientOptions{Timeout: time.Second, NoTimeout: true}, "NoTimeout"},
+		{
+			"per-host idle above total",
+			ClientOptions{Pool: &PoolConfig{MaxIdleConns: 5, MaxIdleConnsPerHost: 10}},
+			"MaxIdleConnsPerHost",
+		},
+		{
+			"retry max delay below initial",
+			ClientOptions{Retry: &RetryConfig{MaxRetries: 3, InitialDelay: time.Second, MaxDelay: 100 * time.Millisecond}},
+			"MaxDelay",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClientWithOptionsE(tt.opts)
+			if client != nil {
+				t.Error("expected nil client for invalid options")
+			}
+			if !errors.Is(err, ErrInvalidOptions) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected ErrInvalidOptions mentioning %s, got %v", tt.want, err)
+			}
+		})
+	}
+
+	client, err := NewClientWithOptionsE(ClientOptions{})
+	if err != nil || client == nil {
+		t.Errorf("zero-value options should be valid, got %v", err)
+	}
+}
+
+func TestNewClientWithOptionsE_JoinsErrors(t *testing.T) {
+	_, err := NewClientWithOptionsE(ClientOptions{
+		Timeout: -time.Second,
+		Pool:    &PoolConfig{MaxIdleConns: 1, MaxIdleConnsPerHost: 2},
+	})
+	if err == nil || !strings.Contains(err.Error(), "Timeout") || !strings.Contains(err.Error(), "MaxIdleConnsPerHost") {
+		t.Errorf("expected both problems reported, got %v", err)
+	}
+}
+
+func TestNewClientWithOptions_FixesUpInvalidOptions(t *testing.T) {
+	logger := &MockLogger{}
+	pool := &PoolConfig{MaxIdleConns: 5, MaxIdleConnsPerHost: 10}
+	retry := &RetryConfig{MaxRetries: 1, InitialDelay: time.Second, MaxDelay: time.Millisecond}
+
+	client := NewClientWithOptions(ClientOptions{
+		Timeout: -time.Second,
+		Pool:    pool,
+		Retry:   retry,
+		Logger:  logger,
+	})
+
+	if client.httpClient.Timeout != defaultTimeout {
+		t.Errorf("expected negative timeout replaced by %v, got %v", defaultTimeout, client.httpClient.Timeout)
+	}
+	if got := transportOf(t, client).MaxIdleConnsPerHost; got != 5 {
+		t.Errorf("expected MaxIdleConnsPerHost capped to 5, got %d", got)
+	}
+	if client.retry.MaxDelay != time.Second {
+		t.Errorf("expected MaxDelay raised to InitialDelay, got %v", client.retry.MaxDelay)
+	}
+	if pool.MaxIdleConnsPerHost != 10 || retry.MaxDelay != time.Millisecond {
+		t.Error("caller's Pool and Retry should not be modified")
+	}
+	if len(logger.warnLogs) != 1 {
+		t.Errorf("expected one warning, got %d", len(logger.warnLogs))
+	}
+}
@@ -0,0 +1,220 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// ErrStopPagination ForEach 的回调返回该错误时停止分页，ForEach 返回nil
+var ErrStopPagination = errors.New("httpclient: 停止分页")
+
+// NextFunc 从响应中提取下一页的URL，没有下一页时返回false
+// 返回的URL可以是相对路径，按当前页的请求URL解析
+type NextFunc func(resp *Response) (string, bool)
+
+// PageResult PageIterator.Chan 返回的通道中的元素
+type PageResult struct {
+	Response *Response
+	Err      error
+}
+
+// PageIterator 逐页请求的迭代器，由 Client.Paginate 创建，非线程安全
+//
+// 示例:
+//
+//	it := client.Paginate(client.NewRequest("GET", "/orders").WithCtx(ctx), nil)
+//	for it.Next() {
+//	    var page []Order
+//	    if err := it.Response().JSON(&page); err != nil {
+//	        return err
+//	    }
+//	}
+//	if err := it.Err(); err != nil {
+//	    return err
+//	}
+type PageIterator struct {
+	req         *Request
+	extractNext NextFunc
+	nextURL     string
+	resp        *Response
+	err         error
+	pages       int
+	done        bool
+}
+
+// Paginate 从 req 开始逐页请求，extractNext 为nil时使用 NextLink（解析 Link 响应头）
+//
+// 后续页面沿用 req 的方法、请求头、超时和上下文，但不发送请求体。
+// 遇到请求失败、非2xx响应（*StatusError）、上下文取消或下一页URL与当前页相同时停止。
+func (c *Client) Paginate(req *Request, extractNext NextFunc) *PageIterator {
+	if extractNext == nil {
+		extractNext = NextLink
+	}
+	if req.client == nil {
+		req.client = c
+	}
+	return &PageIterator{req: req, extractNext: extractNext, nextURL: req.url}
+}
+
+// Next 请求下一页，成功时返回true，通过 Response 获取该页的响应
+func (it *PageIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	if ctx := it.req.ctx; ctx != nil && ctx.Err() != nil {
+		return it.stop(ctx.Err())
+	}
+
+	page := it.req.forPage(it.nextURL, it.pages > 0)
+	resp, err := page.Do()
+	if err != nil {
+		return it.stop(err)
+	}
+	if !resp.IsSuccess() {
+		return it.stop(newStatusError(resp))
+	}
+
+	it.resp = resp
+	it.pages++
+	it.nextURL, it.done = it.resolveNext(resp)
+	return true
+}
+
+// resolveNext 返回下一页的绝对URL，没有下一页时 done 为true
+func (it *PageIterator) resolveNext(resp *Response) (next string, done bool) {
+	raw, ok := it.extractNext(resp)
+	if !ok || raw == "" {
+		return "", true
+	}
+	if resp.Request == nil || resp.Request.URL == nil {
+		return raw, false
+	}
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return raw, false
+	}
+	current := resp.Request.URL
+	resolved := current.ResolveReference(ref)
+	// 下一页指向当前页时停止，避免服务端错误导致无限循环
+	if resolved.String() == current.String() {
+		return "", true
+	}
+	return resolved.String(), false
+}
+
+func (it *PageIterator) stop(err error) bool {
+	it.err = err
+	it.resp = nil
+	it.done = true
+	return false
+}
+
+// Response 返回当前页的响应
+func (it *PageIterator) Response() *Response {
+	return it.resp
+}
+
+// Err 返回导致迭代停止的错误，正常结束时为nil
+func (it *PageIterator) Err() error {
+	return it.err
+}
+
+// Pages 返回已成功获取的页数
+func (it *PageIterator) Pages() int {
+	return it.pages
+}
+
+// ForEach 逐页调用 fn，fn 返回错误时停止并返回该错误（ErrStopPagination 除外）
+func (it *PageIterator) ForEach(fn func(resp *Response) error) error {
+	for it.Next() {
+		if err := fn(it.resp); err != nil {
+			it.done = true
+			if errors.Is(err, ErrStopPagination) {
+				return nil
+			}
+			return err
+		}
+	}
+	return it.err
+}
+
+// Chan 在后台协程中逐页请求，通过通道返回每一页
+//
+// 出错时最后一个元素的 Err 不为nil，随后通道关闭。后续请求使用 ctx 作为上下文，
+// ctx 取消时中止进行中的请求并关闭通道；调用方不再读取时应取消 ctx，否则后台协程会阻塞在发送上。
+func (it *PageIterator) Chan(ctx context.Context) <-chan PageResult {
+	req := *it.req
+	req.ctx = ctx
+	it.req = &req
+
+	ch := make(chan PageResult)
+	go func() {
+		defer close(ch)
+		for it.Next() {
+			select {
+			case ch <- PageResult{Response: it.resp}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if it.err != nil {
+			select {
+			case ch <- PageResult{Err: it.err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return ch
+}
+
+// forPage 复制请求用于获取指定页，后续页面不发送请求体
+func (r *Request) forPage(pageURL string, subsequent bool) *Request {
+	page := *r
+	page.url = pageURL
+	page.headers = make(map[string]string, len(r.headers))
+	for key, value := range r.headers {
+		page.headers[key] = value
+	}
+	if subsequent {
+		page.body = nil
+	}
+	return &page
+}
+
+// NextLink 从 Link 响应头（RFC 8288）中提取 rel="next" 的URL
+func NextLink(resp *Response) (string, bool) {
+	for _, header := range resp.Headers.Values("Link") {
+		for _, link := range strings.Split(header, ",") {
+			target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+			if !ok {
+				continue
+			}
+			target = strings.TrimSpace(target)
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			if hasRelNext(params) {
+				return target[1 : len(target)-1], true
+			}
+		}
+	}
+	return "", false
+}
+
+// hasRelNext 判断 Link 参数中的 rel 是否包含 next（rel 可以是空格分隔的多个值）
+func hasRelNext(params string) bool {
+	for _, param := range strings.Split(params, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "rel") {
+			continue
+		}
+		for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+			if strings.EqualFold(rel, "next") {
+				return true
+			}
+		}
+	}
+	return false
+}
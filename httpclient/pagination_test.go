@@ -0,0 +1,203 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// newPagedServer 返回共 total 页的服务端，通过 Link 响应头给出下一页
+func newPagedServer(total int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		if page == 99 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if page < total {
+			w.Header().Add("Link", fmt.Sprintf(`</items?page=%d>; rel="next", </items?page=%d>; rel="last"`, page+1, total))
+		}
+		w.Header().Set("X-Auth", r.Header.Get("Authorization"))
+		fmt.Fprintf(w, "page-%d", page)
+	}))
+}
+
+func TestPaginateLinkHeader(t *testing.T) {
+	server := newPagedServer(3)
+	defer server.Close()
+
+	client := NewClientWithOptions(ClientOptions{BaseURL: server.URL})
+	it := client.Paginate(client.NewRequest("GET", "/items").Header("Authorization", "token"), nil)
+
+	var pages []string
+	for it.Next() {
+		pages = append(pages, it.Response().String())
+		if got := it.Response().Headers.Get("X-Auth"); got != "token" {
+			t.Errorf("Expected request headers on every page, got %q", got)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fmt.Sprint(pages) != "[page-1 page-2 page-3]" || it.Pages() != 3 {
+		t.Errorf("Unexpected pages: %v", pages)
+	}
+}
+
+func TestPaginateCursor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			w.Write([]byte(`{"items":[1,2],"next":"abc"}`))
+		case "abc":
+			w.Write([]byte(`{"items":[3],"next":""}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(ClientOptions{BaseURL: server.URL})
+	nextCursor := func(resp *Response) (string, bool) {
+		var body struct {
+			Next string `json:"next"`
+		}
+		if err := resp.JSON(&body); err != nil || body.Next == "" {
+			return "", false
+		}
+		return "/list?cursor=" + body.Next, true
+	}
+
+	var items []int
+	err := client.Paginate(client.NewRequest("GET", "/list"), nextCursor).ForEach(func(resp *Response) error {
+		var body struct {
+			Items []int `json:"items"`
+		}
+		if err := resp.JSON(&body); err != nil {
+			return err
+		}
+		items = append(items, body.Items...)
+		return nil
+	})
+	if err != nil || fmt.Sprint(items) != "[1 2 3]" {
+		t.Errorf("Expected [1 2 3], got %v (%v)", items, err)
+	}
+}
+
+func TestPaginateStops(t *testing.T) {
+	server := newPagedServer(5)
+	defer server.Close()
+	client := NewClientWithOptions(ClientOptions{BaseURL: server.URL})
+
+	// 回调停止
+	count := 0
+	err := client.Paginate(client.NewRequest("GET", "/items"), nil).ForEach(func(resp *Response) error {
+		count++
+		if count == 2 {
+			return ErrStopPagination
+		}
+		return nil
+	})
+	if err != nil || count != 2 {
+		t.Errorf("Expected stop after 2 pages, got %d (%v)", count, err)
+	}
+
+	// 非2xx响应
+	it := client.Paginate(client.NewRequest("GET", "/items?page=99"), nil)
+	var statusErr *StatusError
+	if it.Next() || !errors.As(it.Err(), &statusErr) || statusErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected StatusError, got %v", it.Err())
+	}
+
+	// 下一页指向自身
+	loop := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", `</same>; rel="next"`)
+	}))
+	defer loop.Close()
+	it = client.Paginate(client.NewRequest("GET", loop.URL+"/same"), nil)
+	for it.Next() {
+	}
+	if it.Pages() != 1 || it.Err() != nil {
+		t.Errorf("Expected self-referencing next link to stop after 1 page, got %d (%v)", it.Pages(), it.Err())
+	}
+}
+
+func TestPaginateContextCancel(t *testing.T) {
+	server := newPagedServer(5)
+	defer server.Close()
+	client := NewClientWithOptions(ClientOptions{BaseURL: server.URL})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it := client.Paginate(client.NewRequest("GET", "/items").WithCtx(ctx), nil)
+	if !it.Next() {
+		t.Fatalf("Expected first page, got %v", it.Err())
+	}
+	cancel()
+	if it.Next() || !errors.Is(it.Err(), context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", it.Err())
+	}
+}
+
+func TestPaginateChan(t *testing.T) {
+	server := newPagedServer(3)
+	defer server.Close()
+	client := NewClientWithOptions(ClientOptions{BaseURL: server.URL})
+
+	var pages []string
+	for result := range client.Paginate(client.NewRequest("GET", "/items"), nil).Chan(context.Background()) {
+		if result.Err != nil {
+			t.Fatalf("Unexpected error: %v", result.Err)
+		}
+		pages = append(pages, result.Response.String())
+	}
+	if fmt.Sprint(pages) != "[page-1 page-2 page-3]" {
+		t.Errorf("Unexpected pages: %v", pages)
+	}
+
+	// 出错时最后一个元素带错误
+	var last PageResult
+	for result := range client.Paginate(client.NewRequest("GET", "/items?page=99"), nil).Chan(context.Background()) {
+		last = result
+	}
+	if last.Err == nil {
+		t.Error("Expected error as last result")
+	}
+
+	// 取消后通道关闭
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := client.Paginate(client.NewRequest("GET", "/items"), nil).Chan(ctx)
+	<-ch
+	cancel()
+	for range ch {
+	}
+}
+
+func TestNextLink(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+		ok     bool
+	}{
+		{`<https://api.example.com/items?page=2>; rel="next"`, "https://api.example.com/items?page=2", true},
+		{`</items?page=1>; rel="prev", </items?page=3>; rel="next"`, "/items?page=3", true},
+		{`</items?page=3>; rel="next last"`, "/items?page=3", true},
+		{`</items?page=3>; REL=next`, "/items?page=3", true},
+		{`</items?page=5>; rel="last"`, "", false},
+		{``, "", false},
+	}
+	for _, tt := range tests {
+		resp := &Response{Headers: http.Header{}}
+		if tt.header != "" {
+			resp.Headers.Set("Link", tt.header)
+		}
+		got, ok := NextLink(resp)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NextLink(%q) = %q, %v; want %q, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ErrPathTemplate 路径模板与参数不匹配
+var ErrPathTemplate = errors.New("httpclient: 路径模板无效")
+
+// NewRequestf 使用路径模板创建请求构建器，{name} 占位符替换为转义后的参数值
+//
+// 示例:
+//
+//	resp, err := client.NewRequestf("GET", "/users/{id}/orders/{orderID}", map[string]string{
+//	    "id":      userID,
+//	    "orderID": orderID,
+//	}).Do()
+func (c *Client) NewRequestf(method, template string, params map[string]string) *Request {
+	return c.NewRequest(method, "").Path(template, params)
+}
+
+// Path 使用路径模板设置请求URL
+//
+// 参数值使用 url.PathEscape 转义，"/"、"?"、"#" 等字符不会改变路径结构，
+// 已经转义过的值会被再次转义，因此应传入原始值。
+// 模板中的占位符缺少参数、参数值为空或参数未被使用时，Do 返回 ErrPathTemplate。
+func (r *Request) Path(template string, params map[string]string) *Request {
+	path, err := expandPath(template, params)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	r.url = path
+	return r
+}
+
+// expandPath 展开路径模板
+func expandPath(template string, params map[string]string) (string, error) {
+	var b strings.Builder
+	b.Grow(len(template))
+	used := make(map[string]bool, len(params))
+
+	rest := template
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			if strings.IndexByte(rest, '}') >= 0 {
+				return "", fmt.Errorf("%w: %q 中存在多余的 '}'", ErrPathTemplate, template)
+			}
+			b.WriteString(rest)
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("%w: %q 中的 '{' 未闭合", ErrPathTemplate, template)
+		}
+		end += start
+
+		if strings.IndexByte(rest[:start], '}') >= 0 {
+			return "", fmt.Errorf("%w: %q 中存在多余的 '}'", ErrPathTemplate, template)
+		}
+		b.WriteString(rest[:start])
+
+		name := rest[start+1 : end]
+		if name == "" {
+			return "", fmt.Errorf("%w: %q 中存在空的占位符", ErrPathTemplate, template)
+		}
+		value, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("%w: 缺少路径参数 %q", ErrPathTemplate, name)
+		}
+		if value == "" {
+			return "", fmt.Errorf("%w: 路径参数 %q 为空", ErrPathTemplate, name)
+		}
+		b.WriteString(url.PathEscape(value))
+		used[name] = true
+
+		rest = rest[end+1:]
+	}
+
+	if len(used) < len(params) {
+		var unused []string
+		for name := range params {
+			if !used[name] {
+				unused = append(unused, name)
+			}
+		}
+		sort.Strings(unused)
+		return "", fmt.Errorf("%w: 路径参数未使用 %v", ErrPathTemplate, unused)
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,93 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExpandPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		params   map[string]string
+		want     string
+	}{
+		{"simple", "/users/{id}", map[string]string{"id": "42"}, "/users/42"},
+		{"multiple", "/users/{id}/orders/{orderID}", map[string]string{"id": "7", "orderID": "a-1"}, "/users/7/orders/a-1"},
+		{"slash", "/files/{name}", map[string]string{"name": "../etc/passwd"}, "/files/..%2Fetc%2Fpasswd"},
+		{"query chars", "/search/{q}", map[string]string{"q": "a?b#c"}, "/search/a%3Fb%23c"},
+		{"space", "/users/{name}", map[string]string{"name": "John Doe"}, "/users/John%20Doe"},
+		{"percent", "/tags/{tag}", map[string]string{"tag": "100%"}, "/tags/100%25"},
+		{"already escaped", "/tags/{tag}", map[string]string{"tag": "a%20b"}, "/tags/a%2520b"},
+		{"unicode", "/users/{name}", map[string]string{"name": "张三"}, "/users/%E5%BC%A0%E4%B8%89"},
+		{"no params", "/health", nil, "/health"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandPath(tt.template, tt.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExpandPathErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		params   map[string]string
+	}{
+		{"missing param", "/users/{id}", map[string]string{}},
+		{"empty value", "/users/{id}", map[string]string{"id": ""}},
+		{"unused param", "/users/{id}", map[string]string{"id": "1", "idd": "2"}},
+		{"unclosed", "/users/{id", map[string]string{"id": "1"}},
+		{"stray close", "/users/id}", nil},
+		{"empty placeholder", "/users/{}", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := expandPath(tt.template, tt.params); !errors.Is(err, ErrPathTemplate) {
+				t.Errorf("expected ErrPathTemplate, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewRequestf(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.SetBaseURL(server.URL + "/api")
+
+	resp, err := client.NewRequestf("GET", "/users/{id}/files/{name}", map[string]string{
+		"id":   "42",
+		"name": "a/b c",
+	}).Do()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.IsOK() {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if gotPath != "/api/users/42/files/a%2Fb%20c" {
+		t.Errorf("unexpected path %q", gotPath)
+	}
+
+	_, err = client.NewRequest("GET", "").Path("/users/{id}", nil).Do()
+	if !errors.Is(err, ErrPathTemplate) {
+		t.Errorf("expected ErrPathTemplate from Do, got %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrRetryBudgetExhausted 重试预算已耗尽
+var ErrRetryBudgetExhausted = errors.New("重试预算已耗尽")
+
+// RetryHook 单次尝试后的重试钩子
+// attempt 为已完成的尝试次数（从1开始），resp 和 err 为本次尝试的结果
+// 返回是否重试以及重试前的等待时间，delay <= 0 时使用默认退避延迟
+type RetryHook func(attempt int, resp *http.Response, err error) (retry bool, delay time.Duration)
+
+// RetryBudget 重试预算，限制单位时间内的重试总数，防止重试风暴
+// 同一个预算可以在多个客户端之间共享
+type RetryBudget struct {
+	maxRetries  int
+	window      time.Duration
+	mu          sync.Mutex
+	windowStart time.Time
+	used        int
+}
+
+// NewRetryBudget 创建重试预算，window 时间窗口内最多允许 maxRetries 次重试
+func NewRetryBudget(maxRetries int, window time.Duration) *RetryBudget {
+	if window <= 0 {
+		window = time.Second
+	}
+	return &RetryBudget{
+		maxRetries:  maxRetries,
+		window:      window,
+		windowStart: time.Now(),
+	}
+}
+
+// Allow 消耗一次重试额度，额度不足时返回false
+func (b *RetryBudget) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.resetIfExpired()
+	if b.used >= b.maxRetries {
+		return false
+	}
+	b.used++
+	return true
+}
+
+// Remaining 返回当前时间窗口内剩余的重试额度
+func (b *RetryBudget) Remaining() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.resetIfExpired()
+	if b.used >= b.maxRetries {
+		return 0
+	}
+	return b.maxRetries - b.used
+}
+
+// resetIfExpired 时间窗口过期时重置计数
+func (b *RetryBudget) resetIfExpired() {
+	now := time.Now()
+	if now.Sub(b.windowStart) >= b.window {
+		b.windowStart = now
+		b.used = 0
+	}
+}
+
+// decideRetry 结合默认策略与 OnRetry 钩子得出最终的重试决定和延迟
+func decideRetry(config *RetryConfig, attempt int, resp *http.Response, err error, retry bool, delay time.Duration) (bool, time.Duration) {
+	if config.OnRetry == nil {
+		return retry, delay
+	}
+
+	hookRetry, hookDelay := config.OnRetry(attempt, resp, err)
+	if hookDelay > 0 {
+		delay = hookDelay
+	}
+	return hookRetry, delay
+}
+
+// drainAndClose 关闭即将被丢弃的响应体，避免连接泄漏
+func drainAndClose(resp *http.Response) {
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+}
+
+// totalContext TotalTimeout 大于0时返回所有尝试共享截止时间的上下文
+func (rc *RetryConfig) totalContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if rc.TotalTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, rc.TotalTimeout)
+}
+
+// attemptContext PerAttemptTimeout 大于0时返回单次尝试的上下文，截止时间不晚于 ctx
+func (rc *RetryConfig) attemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if rc.PerAttemptTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, rc.PerAttemptTimeout)
+}
+
+// canRetryWithin 上下文未结束且剩余时间足够等待 delay 时返回true
+func canRetryWithin(ctx context.Context, delay time.Duration) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > delay
+}
+
+// waitRetry 等待重试延迟，上下文结束时提前返回其错误
+func waitRetry(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// releaseOnClose 响应体关闭时释放上下文，没有响应体时立即释放
+// 返回给调用方的响应体在上下文取消后无法继续读取，因此不能在尝试结束时取消
+func releaseOnClose(resp *http.Response, release context.CancelFunc) *http.Response {
+	if resp == nil || resp.Body == nil {
+		release()
+		return resp
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp
+}
+
+// releaseBody 关闭时调用 release 的响应体
+type releaseBody struct {
+	io.ReadCloser
+	release context.CancelFunc
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,271 @@
+package httpclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// ShadowHeader 镜像请求携带的请求头，影子服务可据此跳过副作用
+	ShadowHeader = "X-Shadow-Request"
+
+	// DefaultShadowTimeout 镜像请求的默认超时
+	DefaultShadowTimeout = 5 * time.Second
+	// DefaultShadowConcurrency 同时进行的镜像请求的默认上限
+	DefaultShadowConcurrency = 4
+	// DefaultShadowQueueSize 等待发送的镜像请求的默认上限
+	DefaultShadowQueueSize = 100
+)
+
+// defaultShadowExcludeHeaders 默认不转发给影子服务的请求头
+var defaultShadowExcludeHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// ShadowConfig 流量镜像配置：按比例把请求复制一份发送到影子服务，用于上线前对比新旧实现
+//
+// 镜像在主请求得到响应之后异步发送，不阻塞、不影响主请求的结果，镜像失败不会返回给调用方。
+type ShadowConfig struct {
+	// BaseURL 影子服务的基础URL，请求的路径和查询参数保持不变
+	BaseURL string
+	// Percentage 镜像的请求比例，0-100
+	Percentage float64
+	// Methods 只镜像这些方法，为空时只镜像 GET 和 HEAD（避免重复写入）
+	Methods []string
+	// Timeout 单个镜像请求的超时，默认 DefaultShadowTimeout
+	Timeout time.Duration
+	// ExcludeHeaders 不转发的请求头，为空时排除 Authorization、Proxy-Authorization、Cookie
+	ExcludeHeaders []string
+	// Concurrency 同时进行的镜像请求上限，默认 DefaultShadowConcurrency
+	Concurrency int
+	// QueueSize 等待发送的镜像请求上限，队列已满时丢弃并计入 ShadowStats.Dropped，默认 DefaultShadowQueueSize
+	QueueSize int
+	// Transport 镜像请求使用的传输层，默认复制 http.DefaultTransport
+	Transport http.RoundTripper
+	// CompareFunc 镜像请求成功时在后台协程中调用，用于对比响应、记录差异指标
+	CompareFunc func(primary, shadow *Response)
+}
+
+// validate 检查 BaseURL 和 Percentage
+func (s *ShadowConfig) validate() error {
+	u, err := url.Parse(s.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("Shadow.BaseURL 必须是 http(s) 绝对地址: %q", s.BaseURL)
+	}
+	if s.Percentage < 0 || s.Percentage > 100 {
+		return fmt.Errorf("Shadow.Percentage 必须在 0-100 之间: %v", s.Percentage)
+	}
+	return nil
+}
+
+// ShadowStats 流量镜像的累计统计
+type ShadowStats struct {
+	Sent    int64 // 已发送并收到响应的镜像请求
+	Failed  int64 // 发送失败的镜像请求
+	Dropped int64 // 队列已满被丢弃的镜像请求
+}
+
+// shadowMirror 按配置复制并异步发送镜像请求
+type shadowMirror struct {
+	base       *url.URL
+	percentage float64
+	methods    map[string]bool
+	exclude    []string
+	compare    func(primary, shadow *Response)
+	httpClient *http.Client
+	logger     Logger
+	metrics    Metrics
+
+	slots   chan struct{} // 排队和进行中的镜像请求，容量为 Concurrency+QueueSize
+	workers chan struct{} // 进行中的镜像请求，容量为 Concurrency
+
+	sent    atomic.Int64
+	failed  atomic.Int64
+	dropped atomic.Int64
+}
+
+// shadowSample 返回 [0, 100) 的随机数，用于采样（测试时替换）
+var shadowSample = func() float64 {
+	return rand.Float64() * 100
+}
+
+// newShadowMirror 根据已校验的配置创建镜像器
+func newShadowMirror(cfg ShadowConfig, logger Logger, metrics Metrics) *shadowMirror {
+	base, _ := url.Parse(cfg.BaseURL)
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultShadowTimeout
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = DefaultShadowConcurrency
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultShadowQueueSize
+	}
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = []string{http.MethodGet, http.MethodHead}
+	}
+	if len(cfg.ExcludeHeaders) == 0 {
+		cfg.ExcludeHeaders = defaultShadowExcludeHeaders
+	}
+	transport := cfg.Transport
+	if transport == nil {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+
+	methods := make(map[string]bool, len(cfg.Methods))
+	for _, method := range cfg.Methods {
+		methods[strings.ToUpper(method)] = true
+	}
+	return &shadowMirror{
+		base:       base,
+		percentage: cfg.Percentage,
+		methods:    methods,
+		exclude:    append([]string(nil), cfg.ExcludeHeaders...),
+		compare:    cfg.CompareFunc,
+		httpClient: &http.Client{Transport: transport, Timeout: cfg.Timeout},
+		logger:     logger,
+		metrics:    metrics,
+		slots:      make(chan struct{}, cfg.Concurrency+cfg.QueueSize),
+		workers:    make(chan struct{}, cfg.Concurrency),
+	}
+}
+
+// sample 判断是否镜像这个请求
+func (m *shadowMirror) sample(method string) bool {
+	return m.percentage > 0 && m.methods[method] && shadowSample() < m.percentage
+}
+
+// prepare 在发送主请求前调用：不可重复读取的请求体先读入内存，使主请求发送后仍能复制
+func (m *shadowMirror) prepare(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
+}
+
+// mirror 复制主请求并放入队列，队列已满时丢弃；不会阻塞调用方
+func (m *shadowMirror) mirror(primaryReq *http.Request, primary *Response) {
+	shadowReq, err := m.clone(primaryReq)
+	if err != nil {
+		m.fail(primaryReq, err)
+		return
+	}
+
+	select {
+	case m.slots <- struct{}{}:
+	default:
+		m.dropped.Add(1)
+		if m.metrics != nil {
+			m.metrics.IncCounter("http_shadow_dropped_total", map[string]string{"method": primaryReq.Method})
+		}
+		return
+	}
+	go func() {
+		defer func() { <-m.slots }()
+		m.workers <- struct{}{}
+		defer func() { <-m.workers }()
+		m.send(shadowReq, primary)
+	}()
+}
+
+// clone 构建发往影子服务的请求，不继承主请求的 context（主请求结束后镜像仍需完成），超时由 httpClient 控制
+func (m *shadowMirror) clone(primaryReq *http.Request) (*http.Request, error) {
+	target := *primaryReq.URL
+	target.Scheme = m.base.Scheme
+	target.Host = m.base.Host
+	target.User = m.base.User
+	target.Path = strings.TrimSuffix(m.base.Path, "/") + primaryReq.URL.Path
+	target.RawPath = ""
+
+	var body io.Reader
+	if primaryReq.GetBody != nil {
+		rc, err := primaryReq.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		body = rc
+	}
+	req, err := http.NewRequest(primaryReq.Method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = primaryReq.Header.Clone()
+	for _, name := range m.exclude {
+		req.Header.Del(name)
+	}
+	req.Header.Set(ShadowHeader, "1")
+	return req, nil
+}
+
+// send 发送镜像请求并调用 CompareFunc，任何错误和 panic 都只记录不传播
+func (m *shadowMirror) send(req *http.Request, primary *Response) {
+	defer func() {
+		if r := recover(); r != nil {
+			m.fail(req, fmt.Errorf("panic: %v", r))
+		}
+	}()
+
+	start := time.Now()
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		m.fail(req, err)
+		return
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		m.fail(req, fmt.Errorf("读取响应体失败: %w", err))
+		return
+	}
+	m.sent.Add(1)
+
+	if m.compare != nil {
+		m.compare(primary, &Response{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Headers:    resp.Header,
+			Body:       body,
+			Proto:      resp.Proto,
+			Response:   resp,
+			Request:    req,
+			Duration:   time.Since(start),
+			tls:        resp.TLS,
+		})
+	}
+}
+
+func (m *shadowMirror) fail(req *http.Request, err error) {
+	m.failed.Add(1)
+	if m.metrics != nil {
+		m.metrics.IncCounter("http_shadow_errors_total", map[string]string{"method": req.Method})
+	}
+	if m.logger != nil {
+		m.logger.Warn("流量镜像请求失败", "method", req.Method, "url", req.URL.String(), "error", err.Error())
+	}
+}
+
+// ShadowStats 返回流量镜像的累计统计，未配置 Shadow 时返回零值
+func (c *Client) ShadowStats() ShadowStats {
+	if c.shadow == nil {
+		return ShadowStats{}
+	}
+	return ShadowStats{
+		Sent:    c.shadow.sent.Load(),
+		Failed:  c.shadow.failed.Load(),
+		Dropped: c.shadow.dropped.Load(),
+	}
+}
@@ -0,0 +1,253 @@
+package httpclient
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// discardLogger 并发安全的空日志记录器，避免未设置 Logger 时逐条打印请求
+type discardLogger struct{}
+
+func (discardLogger) Debug(msg string, fields ...interface{}) {}
+func (discardLogger) Info(msg string, fields ...interface{})  {}
+func (discardLogger) Warn(msg string, fields ...interface{})  {}
+func (discardLogger) Error(msg string, fields ...interface{}) {}
+
+// waitShadow 等待镜像请求全部结束（发送、失败或丢弃）
+func waitShadow(t *testing.T, client *Client, total int64) ShadowStats {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stats := client.ShadowStats()
+		if stats.Sent+stats.Failed+stats.Dropped >= total {
+			return stats
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d shadow requests, got %+v", total, stats)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func okServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestShadowSamplingPercentage(t *testing.T) {
+	primary := okServer(t, "primary")
+	var hits atomic.Int64
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer shadow.Close()
+
+	// 记录采样结果，以便等待全部镜像请求结束
+	var sampled atomic.Int64
+	original := shadowSample
+	shadowSample = func() float64 {
+		v := original()
+		if v < 25 {
+			sampled.Add(1)
+		}
+		return v
+	}
+	defer func() { shadowSample = original }()
+
+	const n = 2000
+	client := NewClientWithOptions(ClientOptions{
+		BaseURL: primary.URL,
+		Logger:  discardLogger{},
+		Shadow:  &ShadowConfig{BaseURL: shadow.URL, Percentage: 25, QueueSize: n},
+	})
+	for i := 0; i < n; i++ {
+		if _, err := client.Get("/items"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// POST 默认不镜像
+	if _, err := client.Post("/items", strings.NewReader("x")); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := waitShadow(t, client, sampled.Load())
+	// 期望 500，标准差约 19.4，取 ±5 个标准差
+	if stats.Sent < 400 || stats.Sent > 600 || stats.Failed != 0 || stats.Dropped != 0 {
+		t.Errorf("Expected about 25%% of %d requests mirrored, got %+v", n, stats)
+	}
+	if hits.Load() != stats.Sent {
+		t.Errorf("Shadow server saw %d requests, stats report %d", hits.Load(), stats.Sent)
+	}
+}
+
+func TestShadowHeadersAndBody(t *testing.T) {
+	primary := okServer(t, "primary")
+
+	type captured struct {
+		method, path, query, body string
+		header                    http.Header
+	}
+	got := make(chan captured, 1)
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- captured{r.Method, r.URL.Path, r.URL.RawQuery, string(body), r.Header.Clone()}
+		io.WriteString(w, "shadow")
+	}))
+	defer shadow.Close()
+
+	compared := make(chan [2]string, 1)
+	client := NewClientWithOptions(ClientOptions{
+		BaseURL: primary.URL,
+		Logger:  discardLogger{},
+		Shadow: &ShadowConfig{
+			BaseURL:    shadow.URL + "/v2/",
+			Percentage: 100,
+			Methods:    []string{"post"},
+			CompareFunc: func(p, s *Response) {
+				compared <- [2]string{p.String(), s.String()}
+			},
+		},
+	})
+
+	resp, err := client.NewRequest(http.MethodPost, "/orders?dry=1").
+		Header("Authorization", "Bearer secret").
+		Header("X-Tenant", "acme").
+		Cookie(&http.Cookie{Name: "session", Value: "s"}).
+		Body(io.NopCloser(strings.NewReader(`{"id":1}`))). // 不可重复读取的请求体
+		Do()
+	if err != nil || resp.String() != "primary" {
+		t.Fatalf("Unexpected primary result: %v %v", resp, err)
+	}
+
+	select {
+	case c := <-got:
+		if c.method != http.MethodPost || c.path != "/v2/orders" || c.query != "dry=1" || c.body != `{"id":1}` {
+			t.Errorf("Unexpected shadow request: %+v", c)
+		}
+		if c.header.Get("Authorization") != "" || c.header.Get("Cookie") != "" {
+			t.Errorf("Expected credentials to be excluded, got %v", c.header)
+		}
+		if c.header.Get("X-Tenant") != "acme" || c.header.Get(ShadowHeader) != "1" {
+			t.Errorf("Expected other headers and shadow marker, got %v", c.header)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shadow request not received")
+	}
+	select {
+	case pair := <-compared:
+		if pair != [2]string{"primary", "shadow"} {
+			t.Errorf("Unexpected compared responses: %v", pair)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("CompareFunc not called")
+	}
+}
+
+func TestShadowDoesNotBlockOrFail(t *testing.T) {
+	primary := okServer(t, "primary")
+	release := make(chan struct{})
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer shadow.Close()
+	defer close(release)
+
+	client := NewClientWithOptions(ClientOptions{
+		BaseURL: primary.URL,
+		Logger:  discardLogger{},
+		Shadow:  &ShadowConfig{BaseURL: shadow.URL, Percentage: 100, Timeout: 100 * time.Millisecond},
+	})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if resp, err := client.Get("/slow"); err != nil || resp.String() != "primary" {
+			t.Fatalf("Unexpected primary result: %v %v", resp, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 80*time.Millisecond {
+		t.Errorf("Primary requests waited for shadow target: %v", elapsed)
+	}
+
+	// 镜像超时只计入统计，不影响调用方
+	if stats := waitShadow(t, client, 3); stats.Failed != 3 {
+		t.Errorf("Expected 3 timed out shadow requests, got %+v", stats)
+	}
+}
+
+func TestShadowQueueFullDrops(t *testing.T) {
+	primary := okServer(t, "primary")
+	release := make(chan struct{})
+	var started sync.WaitGroup
+	started.Add(1)
+	var once sync.Once
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		once.Do(started.Done)
+		<-release
+	}))
+	defer shadow.Close()
+
+	metrics := &labelMetrics{}
+	client := NewClientWithOptions(ClientOptions{
+		BaseURL: primary.URL,
+		Logger:  discardLogger{},
+		Metrics: metrics,
+		Shadow:  &ShadowConfig{BaseURL: shadow.URL, Percentage: 100, Concurrency: 1, QueueSize: 1},
+	})
+
+	if _, err := client.Get("/a"); err != nil {
+		t.Fatal(err)
+	}
+	started.Wait() // 第一个镜像请求占用唯一的并发名额
+	for i := 0; i < 4; i++ {
+		if _, err := client.Get("/b"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 1 个进行中，1 个排队，其余 3 个丢弃
+	if stats := client.ShadowStats(); stats.Dropped != 3 {
+		t.Errorf("Expected 3 dropped shadow requests, got %+v", stats)
+	}
+
+	close(release)
+	if stats := waitShadow(t, client, 5); stats.Sent != 2 || stats.Dropped != 3 {
+		t.Errorf("Expected 2 sent and 3 dropped, got %+v", stats)
+	}
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	dropped := 0
+	for _, labels := range metrics.labels {
+		if len(labels) == 1 && labels["method"] == http.MethodGet {
+			dropped++
+		}
+	}
+	if dropped != 3 {
+		t.Errorf("Expected 3 drop counter increments, got %d", dropped)
+	}
+}
+
+func TestShadowOptionsValidation(t *testing.T) {
+	for _, cfg := range []ShadowConfig{
+		{BaseURL: "shadow.internal", Percentage: 10},
+		{BaseURL: "http://shadow.internal", Percentage: 120},
+	} {
+		cfg := cfg
+		if _, err := NewClientWithOptionsE(ClientOptions{Shadow: &cfg, Logger: discardLogger{}}); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("Expected ErrInvalidOptions for %+v, got %v", cfg, err)
+		}
+		if client := NewClientWithOptions(ClientOptions{Shadow: &cfg, Logger: discardLogger{}}); client.shadow != nil {
+			t.Errorf("Expected invalid shadow config to be dropped: %+v", cfg)
+		}
+	}
+}
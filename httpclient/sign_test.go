@@ -0,0 +1,144 @@
+package httpclient
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// verifySignature 服务端验证签名
+func verifySignature(r *http.Request, secret string, signedHeaders ...string) bool {
+	body, _ := io.ReadAll(r.Body)
+	canonical := DefaultCanonicalRequest(r, body, r.Header.Get(DefaultTimestampHeader), signedHeaders...)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(canonical))
+	expected := hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(r.Header.Get(DefaultSignatureHeader)))
+}
+
+func TestHMACSignMiddleware(t *testing.T) {
+	signedHeaders := []string{"Host", "Content-Type"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(DefaultKeyIDHeader) != "key-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if !verifySignature(r, "secret", signedHeaders...) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.AddMiddleware(HMACSignMiddleware("key-1", "secret", SignOptions{SignedHeaders: signedHeaders}))
+
+	resp, err := client.PostJSON(server.URL+"/orders?b=2&a=1", map[string]string{"id": "42"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected signature to verify, got status %d", resp.StatusCode)
+	}
+
+	resp, err = client.Get(server.URL + "/orders")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected signature without body to verify, got status %d", resp.StatusCode)
+	}
+}
+
+func TestHMACSignMiddlewareWrongSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !verifySignature(r, "secret") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.AddMiddleware(HMACSignMiddleware("key-1", "wrong", SignOptions{}))
+
+	resp, err := client.Post(server.URL, strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected signature mismatch, got status %d", resp.StatusCode)
+	}
+}
+
+func TestHMACSignMiddlewareCustomCanonicalization(t *testing.T) {
+	fixed := time.Unix(1700000000, 0)
+	var gotSignature, gotTimestamp, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get("Authorization")
+		gotTimestamp = r.Header.Get("X-Date")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.AddMiddleware(HMACSignMiddleware("key-1", "secret", SignOptions{
+		SignatureHeader: "Authorization",
+		TimestampHeader: "X-Date",
+		Encode:          base64.StdEncoding.EncodeToString,
+		Now:             func() time.Time { return fixed },
+		Timestamp:       func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+		Canonicalize: func(req *http.Request, body []byte, timestamp string) string {
+			return req.Method + "|" + req.URL.Path + "|" + timestamp + "|" + string(body)
+		},
+	}))
+
+	if _, err := client.Post(server.URL+"/v1/pay", strings.NewReader("amount=10")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("POST|/v1/pay|2023-11-14T22:13:20Z|amount=10"))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	if gotSignature != expected {
+		t.Errorf("Expected signature %s, got %s", expected, gotSignature)
+	}
+	if gotTimestamp != "2023-11-14T22:13:20Z" {
+		t.Errorf("Unexpected timestamp %s", gotTimestamp)
+	}
+	if gotBody != "amount=10" {
+		t.Errorf("Expected body to be forwarded after signing, got %q", gotBody)
+	}
+}
+
+func TestBufferRequestBodyWithoutGetBody(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "http://example.com", io.NopCloser(strings.NewReader("data")))
+	req.GetBody = nil
+
+	body, err := bufferRequestBody(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if string(body) != "data" {
+		t.Errorf("Expected body 'data', got %q", body)
+	}
+
+	again, _ := io.ReadAll(req.Body)
+	if string(again) != "data" {
+		t.Errorf("Expected body to be readable again, got %q", again)
+	}
+	if req.GetBody == nil {
+		t.Error("Expected GetBody to be set")
+	}
+}
@@ -0,0 +1,95 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// singleFlightKey 返回合并请求使用的键，只合并没有请求体的GET请求
+//
+// 客户端级的请求头和Cookie对同一客户端的所有请求相同，不计入键；
+// 请求级的请求头（例如每个用户不同的 Authorization）和Cookie计入键，避免把一个用户的响应返回给另一个用户。
+// 设置了请求级期望的请求不合并，共享的调用只会检查第一个调用方的期望。
+func (c *Client) singleFlightKey(req *Request) (string, bool) {
+	if c.flights == nil || req.err != nil || req.method != http.MethodGet || req.body != nil || len(req.expectations) > 0 {
+		return "", false
+	}
+
+	var b strings.Builder
+	b.WriteString(req.method)
+	b.WriteByte(' ')
+	b.WriteString(c.resolveURL(req.url))
+
+	names := make([]string, 0, len(req.headers))
+	for name := range req.headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString("\n")
+		b.WriteString(http.CanonicalHeaderKey(name))
+		b.WriteString(": ")
+		b.WriteString(req.headers[name])
+	}
+	for _, cookie := range req.cookies {
+		b.WriteString("\nCookie: ")
+		b.WriteString(cookie.String())
+	}
+	return b.String(), true
+}
+
+// doShared 通过 singleflight 执行请求，相同的并发请求共享第一个请求的网络调用
+//
+// 共享的调用使用第一个调用方的 context：其他调用方的 context 结束时各自提前返回；
+// 第一个调用方被取消导致共享的调用失败时，context 仍然有效的调用方会各自重新发送。
+func (c *Client) doShared(req *Request, key string) (*Response, error) {
+	ch := c.flights.DoChan(key, func() (interface{}, error) {
+		return c.do(req)
+	})
+
+	select {
+	case result := <-ch:
+		if result.Err != nil {
+			if result.Shared && isContextError(result.Err) && req.ctx.Err() == nil {
+				return c.do(req)
+			}
+			return nil, result.Err
+		}
+		response := result.Val.(*Response)
+		if !result.Shared {
+			return response, nil
+		}
+		if c.metrics != nil {
+			c.metrics.IncCounter("http_singleflight_shared_total", map[string]string{
+				"method": req.method,
+				"url":    req.url,
+			})
+		}
+		return response.clone(), nil
+	case <-req.ctx.Done():
+		return nil, newRequestError(req, nil, 0, req.ctx.Err())
+	}
+}
+
+// isContextError 判断错误是否由 context 取消或超时引起
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// clone 复制响应，调用方修改 Body 和 Headers 不会影响其他共享同一次请求的调用方
+// 底层的 Response.Response 和 Response.Request 仍然共享，只应读取
+func (r *Response) clone() *Response {
+	copied := *r
+	copied.Body = bytes.Clone(r.Body)
+	copied.Headers = r.Headers.Clone()
+	copied.AttemptTimings = append([]Timing(nil), r.AttemptTimings...)
+	if r.Timing != nil {
+		timing := *r.Timing
+		copied.Timing = &timing
+	}
+	return &copied
+}
@@ -0,0 +1,200 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// counterMetrics 按名称统计计数器
+type counterMetrics struct {
+	mu       sync.Mutex
+	counters map[string]int
+}
+
+func (m *counterMetrics) IncCounter(name string, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.counters == nil {
+		m.counters = make(map[string]int)
+	}
+	m.counters[name]++
+}
+
+func (m *counterMetrics) AddHistogram(name string, value float64, labels map[string]string) {}
+func (m *counterMetrics) SetGauge(name string, value float64, labels map[string]string)     {}
+
+func (m *counterMetrics) count(name string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counters[name]
+}
+
+// blockingServer 统计请求次数，每个请求阻塞到 release 关闭，响应体为 Authorization 请求头
+func blockingServer(t *testing.T) (*httptest.Server, *atomic.Int64, chan struct{}) {
+	t.Helper()
+	var hits atomic.Int64
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		w.Header().Set("X-Upstream", "1")
+		io.WriteString(w, "user:"+r.Header.Get("Authorization"))
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits, release
+}
+
+// waitHits 等待服务端收到 n 个请求，再留出时间让其他调用方加入合并的请求
+func waitHits(t *testing.T, hits *atomic.Int64, n int64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for hits.Load() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d server hits, got %d", n, hits.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+}
+
+func TestSingleFlightSharesConcurrentGets(t *testing.T) {
+	server, hits, release := blockingServer(t)
+	metrics := &counterMetrics{}
+	client := NewClientWithOptions(ClientOptions{
+		BaseURL:      server.URL,
+		Logger:       discardLogger{},
+		Metrics:      metrics,
+		SingleFlight: true,
+	})
+
+	const n = 20
+	responses := make([]*Response, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], errs[i] = client.Get("/items")
+		}(i)
+	}
+	waitHits(t, hits, 1)
+	close(release)
+	wg.Wait()
+
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("Expected 1 server hit, got %d", got)
+	}
+	for i := 0; i < n; i++ {
+		if errs[i] != nil {
+			t.Fatalf("Request %d failed: %v", i, errs[i])
+		}
+		if responses[i].String() != "user:" || responses[i].Headers.Get("X-Upstream") != "1" {
+			t.Fatalf("Unexpected response %d: %q", i, responses[i].String())
+		}
+	}
+
+	// 每个调用方得到独立的副本
+	responses[0].Body[0] = 'X'
+	responses[0].Headers.Set("X-Upstream", "changed")
+	for i := 1; i < n; i++ {
+		if responses[i].String() != "user:" || responses[i].Headers.Get("X-Upstream") != "1" {
+			t.Fatalf("Response %d was affected by another caller: %q", i, responses[i].String())
+		}
+	}
+	if got := metrics.count("http_singleflight_shared_total"); got != n {
+		t.Errorf("Expected %d shared responses, got %d", n, got)
+	}
+
+	// 前一批完成后的请求重新发送
+	if _, err := client.Get("/items"); err != nil {
+		t.Fatal(err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("Expected a new request after the flight completed, got %d hits", got)
+	}
+}
+
+func TestSingleFlightKey(t *testing.T) {
+	server, hits, release := blockingServer(t)
+	client := NewClientWithOptions(ClientOptions{BaseURL: server.URL, Logger: discardLogger{}, SingleFlight: true})
+
+	// 请求级的 Authorization 不同的请求不合并，POST 不合并
+	var wg sync.WaitGroup
+	bodies := make([]string, 4)
+	requests := []*Request{
+		client.NewRequest(http.MethodGet, "/me").Header("Authorization", "alice"),
+		client.NewRequest(http.MethodGet, "/me").Header("Authorization", "bob"),
+		client.NewRequest(http.MethodPost, "/me"),
+		client.NewRequest(http.MethodPost, "/me"),
+	}
+	for i, req := range requests {
+		wg.Add(1)
+		go func(i int, req *Request) {
+			defer wg.Done()
+			if resp, err := req.Do(); err == nil {
+				bodies[i] = resp.String()
+			}
+		}(i, req)
+	}
+	waitHits(t, hits, 4)
+	close(release)
+	wg.Wait()
+
+	if bodies[0] != "user:alice" || bodies[1] != "user:bob" {
+		t.Errorf("Expected per-user responses, got %q", bodies[:2])
+	}
+
+	plain := NewClientWithOptions(ClientOptions{BaseURL: server.URL, Logger: discardLogger{}})
+	if _, ok := plain.singleFlightKey(plain.NewRequest(http.MethodGet, "/me")); ok {
+		t.Error("Expected single-flight to be disabled by default")
+	}
+	if client.Clone().flights == client.flights {
+		t.Error("Expected clones to use their own single-flight group")
+	}
+}
+
+func TestSingleFlightCallerContext(t *testing.T) {
+	server, hits, release := blockingServer(t)
+	client := NewClientWithOptions(ClientOptions{BaseURL: server.URL, Logger: discardLogger{}, SingleFlight: true})
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := client.NewRequest(http.MethodGet, "/items").Context(leaderCtx).Do()
+		leaderErr <- err
+	}()
+	waitHits(t, hits, 1)
+
+	followerResp := make(chan *Response, 1)
+	go func() {
+		resp, err := client.Get("/items")
+		if err != nil {
+			t.Errorf("Follower failed: %v", err)
+		}
+		followerResp <- resp
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// 第一个调用方取消后，跟随的调用方重新发送而不是返回对方的取消错误
+	cancelLeader()
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected leader to be canceled, got %v", err)
+	}
+	waitHits(t, hits, 2)
+	close(release)
+	if resp := <-followerResp; resp == nil || resp.String() != "user:" {
+		t.Fatalf("Unexpected follower response %v", resp)
+	}
+}
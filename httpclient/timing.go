@@ -0,0 +1,192 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Timing 单次尝试的耗时分解，由 net/http/httptrace 采集
+//
+// 复用连接时 DNSLookup、Connect、TLSHandshake 为0。
+// TimeToFirstByte 从本次尝试开始计算，包含建立连接的时间。
+type Timing struct {
+	DNSLookup       time.Duration // DNS解析
+	Connect         time.Duration // 建立TCP连接
+	TLSHandshake    time.Duration // TLS握手
+	TimeToFirstByte time.Duration // 从开始到收到响应的第一个字节
+	ContentTransfer time.Duration // 读取响应体，只有最终返回的尝试才有
+	Total           time.Duration // 本次尝试的总耗时
+	ConnReused      bool          // 是否复用了连接池中的连接
+	RemoteAddr      string        // 对端地址
+}
+
+// String 返回单行的耗时分解，用于日志
+func (t Timing) String() string {
+	conn := "new"
+	if t.ConnReused {
+		conn = "reused"
+	}
+	return fmt.Sprintf("dns=%v connect=%v tls=%v ttfb=%v transfer=%v total=%v conn=%s remote=%s",
+		t.DNSLookup, t.Connect, t.TLSHandshake, t.TimeToFirstByte, t.ContentTransfer, t.Total, conn, t.RemoteAddr)
+}
+
+type timingRecorderContextKey struct{}
+
+// timingRecorder 记录一个逻辑请求中每次尝试的耗时
+type timingRecorder struct {
+	mu       sync.Mutex
+	attempts []*attemptTrace
+}
+
+// withTimingRecorder 在上下文中放入记录器，只有开启计时时才调用，关闭时不安装任何追踪
+func withTimingRecorder(ctx context.Context, rec *timingRecorder) context.Context {
+	return context.WithValue(ctx, timingRecorderContextKey{}, rec)
+}
+
+// traceAttempt 请求上下文中有记录器时开始记录新的一次尝试，返回安装了追踪的请求
+func traceAttempt(req *http.Request) *http.Request {
+	rec, ok := req.Context().Value(timingRecorderContextKey{}).(*timingRecorder)
+	if !ok {
+		return req
+	}
+
+	attempt := &attemptTrace{start: time.Now()}
+	rec.mu.Lock()
+	rec.attempts = append(rec.attempts, attempt)
+	rec.mu.Unlock()
+
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), attempt.clientTrace()))
+}
+
+// finish 以读取完响应体的时间结束最后一次尝试，返回最后一次和全部尝试的耗时
+func (r *timingRecorder) finish(end time.Time) (*Timing, []Timing) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.attempts) == 0 {
+		return nil, nil
+	}
+	timings := make([]Timing, len(r.attempts))
+	for i, attempt := range r.attempts {
+		if i == len(r.attempts)-1 {
+			timings[i] = attempt.snapshot(end)
+		} else {
+			timings[i] = attempt.snapshot(time.Time{})
+		}
+	}
+	last := timings[len(timings)-1]
+	return &last, timings
+}
+
+// attemptTrace 单次尝试的追踪时间点，回调可能在拨号协程中调用，需要加锁
+type attemptTrace struct {
+	mu sync.Mutex
+
+	start                   time.Time
+	dnsStart, dnsDone       time.Time
+	connectStart, connected time.Time
+	tlsStart, tlsDone       time.Time
+	firstByte               time.Time
+	done                    time.Time // 未收到响应（出错）时的结束时间
+	reused                  bool
+	remoteAddr              string
+}
+
+func (a *attemptTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { a.mark(&a.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { a.mark(&a.dnsDone) },
+		ConnectStart: func(string, string) {
+			// 同时尝试多个地址时只记录第一次开始
+			a.mu.Lock()
+			if a.connectStart.IsZero() {
+				a.connectStart = time.Now()
+			}
+			a.mu.Unlock()
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				a.mark(&a.connected)
+			}
+		},
+		TLSHandshakeStart: func() { a.mark(&a.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { a.mark(&a.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			a.mu.Lock()
+			a.reused = info.Reused
+			if info.Conn != nil && info.Conn.RemoteAddr() != nil {
+				a.remoteAddr = info.Conn.RemoteAddr().String()
+			}
+			a.mu.Unlock()
+		},
+		GotFirstResponseByte: func() { a.mark(&a.firstByte) },
+	}
+}
+
+func (a *attemptTrace) mark(t *time.Time) {
+	now := time.Now()
+	a.mu.Lock()
+	*t = now
+	a.mu.Unlock()
+}
+
+// snapshot 计算耗时，end 为零值时以收到第一个字节（没有响应时为当前时间）作为结束
+func (a *attemptTrace) snapshot(end time.Time) Timing {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	timing := Timing{
+		DNSLookup:    between(a.dnsStart, a.dnsDone),
+		Connect:      between(a.connectStart, a.connected),
+		TLSHandshake: between(a.tlsStart, a.tlsDone),
+		ConnReused:   a.reused,
+		RemoteAddr:   a.remoteAddr,
+	}
+	if !a.firstByte.IsZero() {
+		timing.TimeToFirstByte = a.firstByte.Sub(a.start)
+	}
+
+	if end.IsZero() {
+		// 前面的尝试在决定重试时已经结束，固定结束时间，避免每次取值不同
+		if a.done.IsZero() {
+			a.done = a.firstByte
+			if a.done.IsZero() {
+				a.done = time.Now()
+			}
+		}
+		end = a.done
+	} else if !a.firstByte.IsZero() {
+		timing.ContentTransfer = end.Sub(a.firstByte)
+	}
+	timing.Total = end.Sub(a.start)
+	return timing
+}
+
+// between 返回两个时间点的间隔，任一时间点缺失时返回0
+func between(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// formatTimings 格式化调试输出中的耗时分解，多次尝试时逐行列出
+func formatTimings(timings []Timing) string {
+	switch len(timings) {
+	case 0:
+		return "N/A"
+	case 1:
+		return timings[0].String()
+	}
+	lines := make([]string, len(timings))
+	for i, timing := range timings {
+		lines[i] = fmt.Sprintf("\n│   #%d %s", i+1, timing)
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,179 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// histogramMetrics 记录直方图名称和值
+type histogramMetrics struct {
+	mu     sync.Mutex
+	values map[string][]float64
+}
+
+func (m *histogramMetrics) IncCounter(name string, labels map[string]string) {}
+func (m *histogramMetrics) SetGauge(name string, value float64, labels map[string]string) {}
+func (m *histogramMetrics) AddHistogram(name string, value float64, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.values == nil {
+		m.values = make(map[string][]float64)
+	}
+	m.values[name] = append(m.values[name], value)
+}
+
+func TestTimingBreakdown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(ClientOptions{
+		BaseURL:      server.URL,
+		EnableTiming: true,
+		Logger:       &MockLogger{},
+	})
+
+	resp, err := client.Get("/slow")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	timing := resp.Timing
+	if timing == nil {
+		t.Fatal("Timing should be populated when EnableTiming is set")
+	}
+	if timing.ConnReused {
+		t.Error("first request should use a new connection")
+	}
+	if timing.Connect <= 0 {
+		t.Errorf("Connect = %v, want > 0 for a new connection", timing.Connect)
+	}
+	if timing.TimeToFirstByte < 50*time.Millisecond || timing.TimeToFirstByte <= timing.Connect {
+		t.Errorf("TimeToFirstByte = %v, want >= 50ms and > Connect (%v)", timing.TimeToFirstByte, timing.Connect)
+	}
+	if timing.Total < timing.TimeToFirstByte {
+		t.Errorf("Total = %v, want >= TimeToFirstByte (%v)", timing.Total, timing.TimeToFirstByte)
+	}
+	if timing.RemoteAddr != strings.TrimPrefix(server.URL, "http://") {
+		t.Errorf("RemoteAddr = %q, want %q", timing.RemoteAddr, server.URL)
+	}
+	if len(resp.AttemptTimings) != 1 {
+		t.Errorf("AttemptTimings = %d, want 1", len(resp.AttemptTimings))
+	}
+
+	resp, err = client.Get("/slow")
+	if err != nil {
+		t.Fatalf("second request failed: %v", err)
+	}
+	if !resp.Timing.ConnReused {
+		t.Error("second request should reuse the keep-alive connection")
+	}
+	if resp.Timing.Connect != 0 || resp.Timing.DNSLookup != 0 {
+		t.Errorf("reused connection should not report dial phases: %+v", resp.Timing)
+	}
+}
+
+func TestTimingDisabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	var traced bool
+	client := NewClientWithOptions(ClientOptions{
+		BaseURL: server.URL,
+		Logger:  &MockLogger{},
+		Interceptors: []Interceptor{func(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+			traced = req.Context().Value(timingRecorderContextKey{}) != nil
+			return next(req)
+		}},
+	})
+
+	resp, err := client.Get("/")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.Timing != nil || resp.AttemptTimings != nil {
+		t.Errorf("Timing should be nil when disabled, got %+v", resp.Timing)
+	}
+	if traced {
+		t.Error("no timing recorder should be installed when disabled")
+	}
+}
+
+func TestTimingPerAttemptUnderRetry(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	metrics := &histogramMetrics{}
+	client := NewClientWithOptions(ClientOptions{
+		BaseURL:      server.URL,
+		EnableTiming: true,
+		TTFBMetrics:  true,
+		Metrics:      metrics,
+		Logger:       &MockLogger{},
+		Retry: &RetryConfig{
+			MaxRetries:   3,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Millisecond,
+		},
+	})
+
+	resp, err := client.Get("/")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if len(resp.AttemptTimings) != 3 {
+		t.Fatalf("AttemptTimings = %d, want 3", len(resp.AttemptTimings))
+	}
+	for i, timing := range resp.AttemptTimings {
+		if timing.TimeToFirstByte <= 0 || timing.Total <= 0 {
+			t.Errorf("attempt %d missing durations: %+v", i+1, timing)
+		}
+	}
+	if *resp.Timing != resp.AttemptTimings[2] {
+		t.Errorf("Timing should be the last attempt: %+v vs %+v", resp.Timing, resp.AttemptTimings[2])
+	}
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if got := len(metrics.values["http_request_ttfb_seconds"]); got != 1 {
+		t.Errorf("ttfb histogram observations = %d, want 1", got)
+	}
+}
+
+func TestTimingInDebugOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	logger := &MockLogger{}
+	client := NewClientWithOptions(ClientOptions{
+		BaseURL: server.URL,
+		Logger:  logger,
+		Debug:   DefaultDebugConfig(),
+	})
+
+	resp, err := client.Get("/")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.Timing == nil {
+		t.Fatal("Debug should enable timing")
+	}
+	if len(logger.debugLogs) != 1 || !strings.Contains(logger.debugLogs[0], "Timing: dns=") {
+		t.Errorf("debug output should contain the timing breakdown: %v", logger.debugLogs)
+	}
+}
@@ -0,0 +1,180 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodySnippet 错误信息中保留的响应体最大长度
+const maxErrorBodySnippet = 512
+
+// ErrNilClient 未提供客户端
+var ErrNilClient = errors.New("httpclient: 客户端不能为空")
+
+// StatusError 非2xx响应对应的错误，携带状态码和响应体片段
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Body       string // 响应体片段，最多 512 字节
+	Response   *Response
+}
+
+// Error 实现 error 接口
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("HTTP %s", e.Status)
+	}
+	return fmt.Sprintf("HTTP %s: %s", e.Status, e.Body)
+}
+
+// APIError 非2xx响应，响应体已解析为API定义的错误结构 E
+//
+// 示例:
+//
+//	var apiErr *httpclient.APIError[VendorError]
+//	if errors.As(err, &apiErr) {
+//	    log.Printf("错误码: %s", apiErr.Payload.Code)
+//	}
+type APIError[E any] struct {
+	*StatusError
+	Payload E
+}
+
+// Unwrap 返回底层的 StatusError
+func (e *APIError[E]) Unwrap() error {
+	return e.StatusError
+}
+
+// GetAs 发送GET请求，并将2xx响应体解析为 T
+//
+// 示例:
+//
+//	user, resp, err := httpclient.GetAs[User](ctx, client, "/users/1")
+func GetAs[T any](ctx context.Context, client *Client, url string) (T, *Response, error) {
+	if client == nil {
+		var zero T
+		return zero, nil, ErrNilClient
+	}
+	return DoAs[T](client.NewRequest(http.MethodGet, url).WithCtx(ctx))
+}
+
+// PostAs 以JSON格式发送POST请求，并将2xx响应体解析为 T
+func PostAs[T any](ctx context.Context, client *Client, url string, data interface{}) (T, *Response, error) {
+	var zero T
+	if client == nil {
+		return zero, nil, ErrNilClient
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return zero, nil, fmt.Errorf("序列化请求体失败: %w", err)
+	}
+
+	req := client.NewRequest(http.MethodPost, url).
+		WithCtx(ctx).
+		Header("Content-Type", "application/json").
+		Body(bytes.NewReader(payload))
+	return DoAs[T](req)
+}
+
+// DoAs 执行请求，并将2xx响应体解析为 T
+//
+// 非2xx响应返回 *StatusError；任何失败都返回 T 的零值。
+// 请求失败以外的情况下，*Response 总会返回，便于读取响应头等信息。
+func DoAs[T any](req *Request) (T, *Response, error) {
+	var zero T
+
+	resp, err := req.Do()
+	if err != nil {
+		return zero, resp, err
+	}
+	if !resp.IsSuccess() {
+		return zero, resp, newStatusError(resp)
+	}
+
+	var out T
+	if err := decodeResponse(resp, &out); err != nil {
+		return zero, resp, err
+	}
+	return out, resp, nil
+}
+
+// DoAsWithError 与 DoAs 相同，但非2xx响应体会被解析为 E，并以 *APIError[E] 返回
+// 错误响应体无法解析时退化为 *StatusError
+func DoAsWithError[T, E any](req *Request) (T, *Response, error) {
+	var zero T
+
+	resp, err := req.Do()
+	if err != nil {
+		return zero, resp, err
+	}
+	if !resp.IsSuccess() {
+		statusErr := newStatusError(resp)
+		var payload E
+		if len(resp.Body) == 0 || decodeResponse(resp, &payload) != nil {
+			return zero, resp, statusErr
+		}
+		return zero, resp, &APIError[E]{StatusError: statusErr, Payload: payload}
+	}
+
+	var out T
+	if err := decodeResponse(resp, &out); err != nil {
+		return zero, resp, err
+	}
+	return out, resp, nil
+}
+
+// newStatusError 根据响应创建 StatusError
+func newStatusError(resp *Response) *StatusError {
+	body := resp.Body
+	if len(body) > maxErrorBodySnippet {
+		body = body[:maxErrorBodySnippet]
+	}
+	status := resp.Status
+	if status == "" {
+		status = fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		Status:     status,
+		Body:       strings.TrimSpace(string(body)),
+		Response:   resp,
+	}
+}
+
+// decodeResponse 根据 Content-Type 解析响应体
+// 支持 JSON（默认）、XML，以及解析为 string / []byte 的原始响应体
+func decodeResponse(resp *Response, v interface{}) error {
+	switch out := v.(type) {
+	case *string:
+		*out = string(resp.Body)
+		return nil
+	case *[]byte:
+		*out = append([]byte(nil), resp.Body...)
+		return nil
+	}
+
+	if len(resp.Body) == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Headers.Get("Content-Type"))
+	var err error
+	switch {
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		err = xml.Unmarshal(resp.Body, v)
+	default:
+		err = json.Unmarshal(resp.Body, v)
+	}
+	if err != nil {
+		return fmt.Errorf("解析响应体失败 (Content-Type: %s): %w", resp.Headers.Get("Content-Type"), err)
+	}
+	return nil
+}
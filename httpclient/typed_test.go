@@ -0,0 +1,146 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type typedUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type typedAPIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func newTypedTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/1":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":1,"name":"alice"}`))
+		case "/users":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":2,"name":"bob"}`))
+		case "/broken":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":`))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"not_found","message":"user not found"}`))
+		}
+	}))
+}
+
+func TestGetAsSuccess(t *testing.T) {
+	server := newTypedTestServer()
+	defer server.Close()
+
+	user, resp, err := GetAs[typedUser](context.Background(), NewClient(), server.URL+"/users/1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp == nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 response, got %v", resp)
+	}
+	if user.ID != 1 || user.Name != "alice" {
+		t.Errorf("Unexpected user: %+v", user)
+	}
+}
+
+func TestPostAsSuccess(t *testing.T) {
+	server := newTypedTestServer()
+	defer server.Close()
+
+	user, _, err := PostAs[typedUser](context.Background(), NewClient(), server.URL+"/users", typedUser{Name: "bob"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if user.ID != 2 {
+		t.Errorf("Unexpected user: %+v", user)
+	}
+}
+
+func TestGetAsNotFound(t *testing.T) {
+	server := newTypedTestServer()
+	defer server.Close()
+
+	user, resp, err := GetAs[typedUser](context.Background(), NewClient(), server.URL+"/users/404")
+	if err == nil {
+		t.Fatal("Expected error for 404 response")
+	}
+	if user != (typedUser{}) {
+		t.Errorf("Expected zero value, got %+v", user)
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected response to be returned with 404")
+	}
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("Expected *StatusError, got %T", err)
+	}
+	if statusErr.StatusCode != http.StatusNotFound || !strings.Contains(statusErr.Body, "not_found") {
+		t.Errorf("Unexpected status error: %v", statusErr)
+	}
+}
+
+func TestDoAsWithErrorDecodesErrorBody(t *testing.T) {
+	server := newTypedTestServer()
+	defer server.Close()
+
+	client := NewClient()
+	_, _, err := DoAsWithError[typedUser, typedAPIError](client.NewRequest(http.MethodGet, server.URL+"/users/404"))
+	if err == nil {
+		t.Fatal("Expected error for 404 response")
+	}
+
+	var apiErr *APIError[typedAPIError]
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *APIError, got %T", err)
+	}
+	if apiErr.Payload.Code != "not_found" || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Unexpected API error: %+v", apiErr.Payload)
+	}
+
+	// APIError 同样可以作为 StatusError 处理
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Error("Expected APIError to unwrap to *StatusError")
+	}
+}
+
+func TestDoAsDecodeFailure(t *testing.T) {
+	server := newTypedTestServer()
+	defer server.Close()
+
+	client := NewClient()
+	user, resp, err := DoAs[typedUser](client.NewRequest(http.MethodGet, server.URL+"/broken"))
+	if err == nil {
+		t.Fatal("Expected decode error")
+	}
+	if user != (typedUser{}) {
+		t.Errorf("Expected zero value, got %+v", user)
+	}
+	if resp == nil {
+		t.Error("Expected response to be returned on decode failure")
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		t.Error("Decode failure should not be a StatusError")
+	}
+}
+
+func TestGetAsNilClient(t *testing.T) {
+	if _, _, err := GetAs[typedUser](context.Background(), nil, "/"); !errors.Is(err, ErrNilClient) {
+		t.Errorf("Expected ErrNilClient, got %v", err)
+	}
+}
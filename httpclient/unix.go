@@ -0,0 +1,59 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// UnixSocketHost 通过UNIX套接字发送请求时URL中使用的占位主机名
+const UnixSocketHost = "unix"
+
+// unixSocketScheme BaseURL 中表示UNIX套接字的协议前缀
+const unixSocketScheme = "unix://"
+
+type socketContextKey struct{}
+
+// parseUnixBaseURL 解析 unix:///var/run/docker.sock 形式的 BaseURL，返回套接字路径
+func parseUnixBaseURL(baseURL string) (string, bool) {
+	if !strings.HasPrefix(baseURL, unixSocketScheme) {
+		return "", false
+	}
+	path := strings.TrimPrefix(baseURL, unixSocketScheme)
+	if path == "" {
+		return "", false
+	}
+	return path, true
+}
+
+// withSocketPath 在请求上下文中记录套接字路径，供指标、调试和审计使用
+func withSocketPath(ctx context.Context, socketPath string) context.Context {
+	return context.WithValue(ctx, socketContextKey{}, socketPath)
+}
+
+// socketPathFromRequest 返回请求使用的UNIX套接字路径，不是套接字请求时返回空字符串
+func socketPathFromRequest(req *http.Request) string {
+	if req == nil {
+		return ""
+	}
+	path, _ := req.Context().Value(socketContextKey{}).(string)
+	return path
+}
+
+// requestHostLabel 返回用于指标标签的主机，套接字请求使用套接字路径而不是占位主机
+func requestHostLabel(req *http.Request) string {
+	if path := socketPathFromRequest(req); path != "" {
+		return path
+	}
+	return req.URL.Host
+}
+
+// socketURL 以 http+unix://<转义的套接字路径>/path 形式展示套接字请求
+func socketURL(socketPath string, u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	return "http+unix://" + url.PathEscape(socketPath) + path
+}
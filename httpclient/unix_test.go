@@ -0,0 +1,174 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// labelMetrics 记录指标标签
+type labelMetrics struct {
+	mu     sync.Mutex
+	labels []map[string]string
+}
+
+func (m *labelMetrics) IncCounter(name string, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.labels = append(m.labels, labels)
+}
+
+func (m *labelMetrics) AddHistogram(name string, value float64, labels map[string]string) {}
+func (m *labelMetrics) SetGauge(name string, value float64, labels map[string]string)     {}
+
+func startUnixServer(t *testing.T, handler http.Handler) string {
+	t.Helper()
+	// 套接字路径长度有限制，使用较短的临时目录
+	dir, err := os.MkdirTemp("", "hc")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	socketPath := filepath.Join(dir, "s.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("unix sockets not supported: %v", err)
+	}
+	server := &http.Server{Handler: handler}
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+	return socketPath
+}
+
+func TestUnixSocket(t *testing.T) {
+	socketPath := startUnixServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Host + " " + r.URL.Path))
+	}))
+
+	t.Run("UnixSocket option", func(t *testing.T) {
+		client := NewClientWithOptions(ClientOptions{UnixSocket: socketPath})
+		resp, err := client.Get("/containers/json")
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		if got := resp.String(); got != "unix /containers/json" {
+			t.Errorf("unexpected response %q", got)
+		}
+	})
+
+	t.Run("unix scheme BaseURL", func(t *testing.T) {
+		metrics := &labelMetrics{}
+		client := NewClientWithOptions(ClientOptions{
+			BaseURL:     "unix://" + socketPath,
+			Middlewares: []Middleware{MetricsMiddleware(metrics)},
+		})
+		resp, err := client.Get("/_ping")
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		if got := resp.String(); got != "unix /_ping" {
+			t.Errorf("unexpected response %q", got)
+		}
+		if len(metrics.labels) == 0 || metrics.labels[0]["host"] != socketPath {
+			t.Errorf("expected host label to be socket path, got %v", metrics.labels)
+		}
+	})
+
+	t.Run("audit url", func(t *testing.T) {
+		logger := &MockLogger{}
+		client := NewClientWithOptions(ClientOptions{UnixSocket: socketPath, AuditLogger: logger})
+		if _, err := client.Get("/info?x=1"); err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		entries := auditEntries(logger)
+		want := socketURL(socketPath, &url.URL{Path: "/info"})
+		if len(entries) != 1 || entries[0]["url"] != want {
+			t.Errorf("expected audit url %q, got %v", want, entries)
+		}
+	})
+}
+
+func TestSocketURL(t *testing.T) {
+	u, _ := url.Parse("http://unix/containers/json?all=1")
+	got := socketURL("/var/run/docker.sock", u)
+	if got != "http+unix://%2Fvar%2Frun%2Fdocker.sock/containers/json" {
+		t.Errorf("unexpected socket url %q", got)
+	}
+}
+
+// pipeListener 通过 net.Pipe 建立连接的监听器，不使用真实网络
+type pipeListener struct {
+	conns  chan net.Conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newPipeListener() *pipeListener {
+	return &pipeListener{conns: make(chan net.Conn), closed: make(chan struct{})}
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *pipeListener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr {
+	return &net.UnixAddr{Name: "pipe", Net: "pipe"}
+}
+
+func (l *pipeListener) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	client, server := net.Pipe()
+	select {
+	case l.conns <- server:
+		return client, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestDialContextPipe(t *testing.T) {
+	listener := newPipeListener()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("pong"))
+	}))
+	server.Listener.Close()
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	client := NewClientWithOptions(ClientOptions{
+		BaseURL:     "http://in-memory",
+		DialContext: listener.DialContext,
+	})
+	resp, err := client.Get("/ping")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.String() != "pong" {
+		t.Errorf("unexpected response %q", resp.String())
+	}
+
+	server.Close()
+	if _, err := client.Get("/ping"); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("expected dial error after close, got %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultAuditMaxBodyBytes 审计日志中每个请求体/响应体保留的默认最大字节数
+	DefaultAuditMaxBodyBytes = 16 << 10
+	// auditLogMessage 审计日志消息
+	auditLogMessage = "HTTP审计"
+	// redactedValue 脱敏后的字段值
+	redactedValue = "***"
+	// omittedBody 需要脱敏但无法解析为JSON时记录的内容，避免敏感字段随截断的JSON泄露
+	omittedBody = "[无法解析的JSON，已省略]"
+)
+
+// AuditConfig 审计日志中间件配置
+type AuditConfig struct {
+	// Logger 日志记录器，为空时输出到标准输出
+	Logger Logger
+	// MaxBodyBytes 每个请求体/响应体记录的最大字节数，<=0 时使用 DefaultAuditMaxBodyBytes
+	MaxBodyBytes int
+	// RedactFields 需要脱敏的JSON字段名（不区分大小写，匹配任意层级），值替换为 ***
+	RedactFields []string
+}
+
+// AuditMiddleware 记录完整请求体和响应体的审计日志，用于合规要求，建议只用于特定路由
+//
+// 请求体在处理函数之前读取（最多 MaxBodyBytes），随后重新设置，处理函数仍可完整读取；
+// 响应体在写入时同步记录。每个请求以 Info 级别输出一条日志，字段包括 method、path、route、
+// status、latency、client_ip、trace_id、request_id、request_body、response_body，
+// 超出大小上限时附带 request_truncated / response_truncated。
+//
+// 配置 RedactFields 后，JSON 请求体/响应体中的同名字段被脱敏；
+// 内容类型为JSON但无法解析（例如被截断）时不记录内容，避免敏感字段泄露。
+//
+// 示例:
+//
+//	server.Handle(http.MethodPost, "/payments", []httpserver.RouteOption{
+//	    httpserver.WithMiddleware(httpserver.AuditMiddleware(httpserver.AuditConfig{
+//	        Logger:       log,
+//	        RedactFields: []string{"card_number", "cvv"},
+//	    })),
+//	}, createPayment)
+func AuditMiddleware(cfg AuditConfig) gin.HandlerFunc {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = stdoutLogger{}
+	}
+	maxBodyBytes := cfg.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultAuditMaxBodyBytes
+	}
+	redact := make(map[string]bool, len(cfg.RedactFields))
+	for _, field := range cfg.RedactFields {
+		redact[strings.ToLower(field)] = true
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		reqBody, reqTruncated := captureRequestBody(c.Request, maxBodyBytes)
+		respBody := &cappedBuffer{limit: maxBodyBytes}
+		c.Writer = &tapWriter{ResponseWriter: c.Writer, body: respBody}
+
+		c.Next()
+
+		fields := []interface{}{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"status", c.Writer.Status(),
+			"latency", time.Since(start),
+			"client_ip", c.ClientIP(),
+			"trace_id", GetTraceID(c),
+			"request_id", GetRequestID(c),
+			"request_body", redactBody(reqBody, c.Request.Header.Get("Content-Type"), reqTruncated, redact),
+			"response_body", redactBody(respBody.Bytes(), c.Writer.Header().Get("Content-Type"), respBody.truncated, redact),
+		}
+		if reqTruncated {
+			fields = append(fields, "request_truncated", true)
+		}
+		if respBody.truncated {
+			fields = append(fields, "response_truncated", true)
+		}
+		logger.Info(auditLogMessage, fields...)
+	}
+}
+
+// captureRequestBody 读取最多 limit 字节的请求体，并把已读部分与剩余部分重新拼接为请求体
+func captureRequestBody(req *http.Request, limit int) ([]byte, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, false
+	}
+
+	// 多读一个字节用于判断是否超出上限
+	captured, err := io.ReadAll(io.LimitReader(req.Body, int64(limit)+1))
+	req.Body = &teeReadCloser{
+		Reader: io.MultiReader(bytes.NewReader(captured), &errReader{err: err, next: req.Body}),
+		Closer: req.Body,
+	}
+	if len(captured) > limit {
+		return captured[:limit], true
+	}
+	return captured, false
+}
+
+// errReader 读取请求体出错时把错误留给处理函数，否则继续读取剩余部分
+type errReader struct {
+	err  error
+	next io.Reader
+}
+
+func (r *errReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	return r.next.Read(p)
+}
+
+// redactBody 返回记录到日志中的内容，配置了脱敏字段时处理JSON内容
+func redactBody(body []byte, contentType string, truncated bool, redact map[string]bool) string {
+	if len(body) == 0 || len(redact) == 0 {
+		return string(body)
+	}
+	if !isJSONContentType(contentType) && !json.Valid(body) {
+		if truncated && looksLikeJSON(body) {
+			return omittedBody
+		}
+		return string(body)
+	}
+
+	// 保留数字的原始文本，避免大整数在重新编码时丢失精度
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return omittedBody
+	}
+	redacted, err := json.Marshal(redactValue(value, redact))
+	if err != nil {
+		return omittedBody
+	}
+	return string(redacted)
+}
+
+// redactValue 递归替换对象中需要脱敏的字段
+func redactValue(value interface{}, redact map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if redact[strings.ToLower(key)] {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactValue(item, redact)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item, redact)
+		}
+	}
+	return value
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// looksLikeJSON 判断截断的内容是否像JSON对象或数组
+func looksLikeJSON(body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
@@ -0,0 +1,116 @@
+package httpserver
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tsopia/go-kit/constants"
+)
+
+func newAuditTestServer(cfg AuditConfig) (*Server, *recordingLogger, *string) {
+	log := &recordingLogger{}
+	cfg.Logger = log
+	var received string
+
+	server := NewServer(nil)
+	server.Use(TraceIDMiddleware())
+	server.Handle(http.MethodPost, "/payments", []RouteOption{WithMiddleware(AuditMiddleware(cfg))},
+		func(c *gin.Context) {
+			body, _ := io.ReadAll(c.Request.Body)
+			received = string(body)
+			c.JSON(http.StatusCreated, gin.H{"id": 9007199254740993, "token": "tok_secret", "status": "ok"})
+		})
+	server.POST("/plain", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return server, log, &received
+}
+
+func postJSON(server *Server, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(constants.TraceIDHeader, "trace-audit-1")
+	server.Engine().ServeHTTP(w, req)
+	return w
+}
+
+func TestAuditMiddlewareCapturesBodies(t *testing.T) {
+	server, log, received := newAuditTestServer(AuditConfig{
+		RedactFields: []string{"card_number", "TOKEN"},
+	})
+
+	body := `{"amount":100,"card":{"card_number":"4111111111111111","holder":"Alice"}}`
+	w := postJSON(server, "/payments", body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", w.Code)
+	}
+	if *received != body {
+		t.Errorf("Handler should still read the full body, got %q", *received)
+	}
+	if !strings.Contains(w.Body.String(), "tok_secret") {
+		t.Errorf("Redaction must not change the actual response, got %s", w.Body.String())
+	}
+
+	if len(log.entries) != 1 {
+		t.Fatalf("Expected 1 audit entry, got %d", len(log.entries))
+	}
+	fields := log.entries[0].fields
+	if fields["trace_id"] != "trace-audit-1" || fields["route"] != "/payments" || fields["status"] != http.StatusCreated {
+		t.Errorf("Unexpected audit fields: %+v", fields)
+	}
+
+	reqBody := fields["request_body"].(string)
+	if strings.Contains(reqBody, "4111") || !strings.Contains(reqBody, `"card_number":"***"`) || !strings.Contains(reqBody, `"holder":"Alice"`) {
+		t.Errorf("Nested field should be redacted, got %s", reqBody)
+	}
+	respBody := fields["response_body"].(string)
+	if strings.Contains(respBody, "tok_secret") || !strings.Contains(respBody, `"token":"***"`) {
+		t.Errorf("Response field should be redacted case-insensitively, got %s", respBody)
+	}
+	if !strings.Contains(respBody, "9007199254740993") {
+		t.Errorf("Large integers should keep their precision, got %s", respBody)
+	}
+	if _, ok := fields["request_truncated"]; ok {
+		t.Errorf("Small body should not be marked truncated: %+v", fields)
+	}
+}
+
+func TestAuditMiddlewareTruncatesCapture(t *testing.T) {
+	server, log, received := newAuditTestServer(AuditConfig{
+		MaxBodyBytes: 16,
+		RedactFields: []string{"password"},
+	})
+
+	body := `{"user":"alice","password":"hunter2hunter2"}`
+	postJSON(server, "/payments", body)
+
+	if *received != body {
+		t.Errorf("Handler should read the full body beyond the capture limit, got %q", *received)
+	}
+	fields := log.entries[0].fields
+	if fields["request_truncated"] != true || fields["response_truncated"] != true {
+		t.Errorf("Expected truncation flags, got %+v", fields)
+	}
+	// 截断的JSON无法可靠脱敏，不记录内容
+	if fields["request_body"] != omittedBody {
+		t.Errorf("Truncated JSON with redaction should be omitted, got %v", fields["request_body"])
+	}
+}
+
+func TestAuditMiddlewareWithoutRedaction(t *testing.T) {
+	server, log, _ := newAuditTestServer(AuditConfig{MaxBodyBytes: 8})
+
+	postJSON(server, "/payments", `{"amount":100}`)
+	if got := log.entries[0].fields["request_body"]; got != `{"amount` {
+		t.Errorf("Without redaction the capped body is logged as-is, got %v", got)
+	}
+
+	postJSON(server, "/plain", `{}`)
+	if len(log.entries) != 1 {
+		t.Errorf("Routes without the middleware should not be audited, got %d entries", len(log.entries))
+	}
+}
@@ -0,0 +1,362 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tsopia/go-kit/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultSurrogateControlHeader 默认的CDN缓存请求头，Fastly、Akamai 等使用该名称
+	DefaultSurrogateControlHeader = "Surrogate-Control"
+
+	cacheControlConfigKey = "cache_control_config"
+)
+
+// cacheSeconds 可选的秒数，区分未设置和 0
+type cacheSeconds struct {
+	set  bool
+	secs int64
+}
+
+func newCacheSeconds(d time.Duration) cacheSeconds {
+	return cacheSeconds{set: true, secs: int64(d / time.Second)}
+}
+
+// CacheDirective Cache-Control 指令构建器，方法返回修改后的副本
+//
+// 示例:
+//
+//	// 公共缓存60秒，过期后30秒内先返回旧内容再后台刷新
+//	httpserver.NewCacheDirective().Public().MaxAge(time.Minute).StaleWhileRevalidate(30 * time.Second)
+//	// 不缓存
+//	httpserver.NewCacheDirective().Private().NoStore()
+type CacheDirective struct {
+	public         bool
+	private        bool
+	noStore        bool
+	noCache        bool
+	mustRevalidate bool
+	immutable      bool
+	maxAge         cacheSeconds
+	sMaxAge        cacheSeconds
+	swr            cacheSeconds
+	sie            cacheSeconds
+}
+
+// NewCacheDirective 创建空的缓存指令
+func NewCacheDirective() CacheDirective {
+	return CacheDirective{}
+}
+
+// Public 允许共享缓存（CDN、代理）缓存响应
+func (d CacheDirective) Public() CacheDirective {
+	d.public = true
+	return d
+}
+
+// Private 只允许浏览器缓存
+func (d CacheDirective) Private() CacheDirective {
+	d.private = true
+	return d
+}
+
+// NoStore 禁止任何缓存保存响应
+func (d CacheDirective) NoStore() CacheDirective {
+	d.noStore = true
+	return d
+}
+
+// NoCache 可以缓存，但每次使用前必须向服务端验证
+func (d CacheDirective) NoCache() CacheDirective {
+	d.noCache = true
+	return d
+}
+
+// MustRevalidate 过期后必须验证，不允许返回过期内容
+func (d CacheDirective) MustRevalidate() CacheDirective {
+	d.mustRevalidate = true
+	return d
+}
+
+// Immutable 有效期内内容不会变化，浏览器刷新时也不需要验证
+func (d CacheDirective) Immutable() CacheDirective {
+	d.immutable = true
+	return d
+}
+
+// MaxAge 缓存有效期，按秒取整
+func (d CacheDirective) MaxAge(age time.Duration) CacheDirective {
+	d.maxAge = newCacheSeconds(age)
+	return d
+}
+
+// SMaxAge 共享缓存的有效期，覆盖 MaxAge
+func (d CacheDirective) SMaxAge(age time.Duration) CacheDirective {
+	d.sMaxAge = newCacheSeconds(age)
+	return d
+}
+
+// StaleWhileRevalidate 过期后在该时间内可以先返回旧内容，同时在后台重新验证
+func (d CacheDirective) StaleWhileRevalidate(window time.Duration) CacheDirective {
+	d.swr = newCacheSeconds(window)
+	return d
+}
+
+// StaleIfError 重新验证失败（5xx或网络错误）时在该时间内可以返回旧内容
+func (d CacheDirective) StaleIfError(window time.Duration) CacheDirective {
+	d.sie = newCacheSeconds(window)
+	return d
+}
+
+// Validate 检查指令组合是否有意义
+func (d CacheDirective) Validate() error {
+	if d == (CacheDirective{}) {
+		return errors.Newf(errors.CodeInvalidParam, "缓存指令为空")
+	}
+	if d.public && d.private {
+		return errors.Newf(errors.CodeInvalidParam, "public 和 private 不能同时使用")
+	}
+	if d.noStore && (d.public || d.immutable || d.maxAge.set || d.sMaxAge.set || d.swr.set || d.sie.set) {
+		return errors.Newf(errors.CodeInvalidParam, "no-store 不能与 public、immutable 或缓存时间同时使用")
+	}
+	durations := []struct {
+		name  string
+		value cacheSeconds
+	}{
+		{"max-age", d.maxAge}, {"s-maxage", d.sMaxAge}, {"stale-while-revalidate", d.swr}, {"stale-if-error", d.sie},
+	}
+	for _, v := range durations {
+		if v.value.set && v.value.secs < 0 {
+			return errors.Newf(errors.CodeInvalidParam, "%s 不能为负数", v.name)
+		}
+	}
+	return nil
+}
+
+// String 序列化为 Cache-Control 请求头的值，指令顺序固定
+func (d CacheDirective) String() string {
+	var parts []string
+	flag := func(on bool, name string) {
+		if on {
+			parts = append(parts, name)
+		}
+	}
+	seconds := func(v cacheSeconds, name string) {
+		if v.set {
+			parts = append(parts, name+"="+strconv.FormatInt(v.secs, 10))
+		}
+	}
+
+	flag(d.public, "public")
+	flag(d.private, "private")
+	flag(d.noStore, "no-store")
+	flag(d.noCache, "no-cache")
+	seconds(d.maxAge, "max-age")
+	seconds(d.sMaxAge, "s-maxage")
+	seconds(d.swr, "stale-while-revalidate")
+	seconds(d.sie, "stale-if-error")
+	flag(d.mustRevalidate, "must-revalidate")
+	flag(d.immutable, "immutable")
+	return strings.Join(parts, ", ")
+}
+
+// CacheControl 设置响应的 Cache-Control 请求头，指令无效时不修改并返回错误
+// 应在写入响应体之前调用
+func CacheControl(c *gin.Context, d CacheDirective) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	c.Header("Cache-Control", d.String())
+	return nil
+}
+
+// SurrogateControl 设置CDN缓存请求头（默认 Surrogate-Control，名称由 CacheControlConfig.SurrogateHeader 决定），
+// 与浏览器看到的 Cache-Control 相互独立，通常只使用 MaxAge、NoStore、StaleWhileRevalidate 和 StaleIfError
+func SurrogateControl(c *gin.Context, d CacheDirective) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	c.Header(surrogateHeader(c), d.String())
+	return nil
+}
+
+// surrogateHeader 返回中间件配置的CDN缓存请求头名称
+func surrogateHeader(c *gin.Context) string {
+	if value, exists := c.Get(cacheControlConfigKey); exists {
+		if cfg, ok := value.(CacheControlConfig); ok {
+			return cfg.SurrogateHeader
+		}
+	}
+	return DefaultSurrogateControlHeader
+}
+
+// Vary 在 Vary 响应头中追加字段，已存在的字段（不区分大小写）不会重复添加
+func Vary(c *gin.Context, fields ...string) {
+	header := c.Writer.Header()
+	var current []string
+	seen := make(map[string]bool)
+	for _, value := range header.Values("Vary") {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" || seen[strings.ToLower(field)] {
+				continue
+			}
+			seen[strings.ToLower(field)] = true
+			current = append(current, field)
+		}
+	}
+	// Vary: * 已经表示响应随任意请求变化
+	if seen["*"] {
+		return
+	}
+
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" || seen[strings.ToLower(field)] {
+			continue
+		}
+		seen[strings.ToLower(field)] = true
+		current = append(current, field)
+	}
+	if len(current) > 0 {
+		header.Set("Vary", strings.Join(current, ", "))
+	}
+}
+
+// CacheControlConfig 默认缓存策略配置
+type CacheControlConfig struct {
+	// Default 处理函数没有设置 Cache-Control 时使用的指令
+	Default CacheDirective
+	// Surrogate 处理函数没有设置CDN缓存请求头时使用的指令，nil 表示不设置
+	Surrogate *CacheDirective
+	// SurrogateHeader CDN缓存请求头名称，默认 Surrogate-Control（Cloudflare 等使用 CDN-Cache-Control）
+	SurrogateHeader string
+}
+
+// withDefaults 填充默认值
+func (cfg CacheControlConfig) withDefaults() CacheControlConfig {
+	if cfg.SurrogateHeader == "" {
+		cfg.SurrogateHeader = DefaultSurrogateControlHeader
+	}
+	return cfg
+}
+
+// DefaultCacheControl 处理函数没有设置 Cache-Control 时使用默认指令的中间件
+//
+// 默认值在响应头写出之前补充，处理函数通过 CacheControl 或 c.Header 设置的值优先。
+// 指令无效时panic，便于在启动时发现配置错误。
+//
+// 示例:
+//
+//	server.Use(httpserver.DefaultCacheControl(httpserver.NewCacheDirective().Private().NoStore()))
+//	public := server.Group("/public", httpserver.DefaultCacheControl(
+//	    httpserver.NewCacheDirective().Public().MaxAge(time.Minute)))
+func DefaultCacheControl(d CacheDirective) gin.HandlerFunc {
+	return CacheControlMiddleware(CacheControlConfig{Default: d})
+}
+
+// CacheControlMiddleware 按配置补充默认的 Cache-Control 和CDN缓存请求头
+func CacheControlMiddleware(cfg CacheControlConfig) gin.HandlerFunc {
+	cfg = cfg.withDefaults()
+	headers := map[string]string{}
+	if err := cfg.Default.Validate(); err != nil {
+		panic("httpserver: 无效的默认缓存指令: " + err.Error())
+	}
+	headers["Cache-Control"] = cfg.Default.String()
+	if cfg.Surrogate != nil {
+		if err := cfg.Surrogate.Validate(); err != nil {
+			panic("httpserver: 无效的CDN缓存指令: " + err.Error())
+		}
+		headers[cfg.SurrogateHeader] = cfg.Surrogate.String()
+	}
+
+	return func(c *gin.Context) {
+		c.Set(cacheControlConfigKey, cfg)
+		w := &cacheDefaultWriter{ResponseWriter: c.Writer, headers: headers}
+		c.Writer = w
+		c.Next()
+		// 没有响应体的响应由 gin 在处理结束后写出响应头
+		w.applyDefaults()
+	}
+}
+
+// cacheDefaultWriter 在响应头写出前补充处理函数没有设置的缓存请求头
+type cacheDefaultWriter struct {
+	gin.ResponseWriter
+	headers map[string]string
+	applied bool
+}
+
+func (w *cacheDefaultWriter) applyDefaults() {
+	if w.applied || w.ResponseWriter.Written() {
+		return
+	}
+	w.applied = true
+	header := w.ResponseWriter.Header()
+	for name, value := range w.headers {
+		if header.Get(name) == "" {
+			header.Set(name, value)
+		}
+	}
+}
+
+func (w *cacheDefaultWriter) WriteHeaderNow() {
+	w.applyDefaults()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *cacheDefaultWriter) Write(p []byte) (int, error) {
+	w.applyDefaults()
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *cacheDefaultWriter) WriteString(s string) (int, error) {
+	w.applyDefaults()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *cacheDefaultWriter) Flush() {
+	w.applyDefaults()
+	w.ResponseWriter.Flush()
+}
+
+// Unwrap 返回被包装的响应写入器，供 EarlyHints 和 http.ResponseController 使用
+func (w *cacheDefaultWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// EarlyHints 发送 103 Early Hints 响应，让浏览器在最终响应之前开始预加载资源
+//
+// links 为 Link 请求头的值，同时保留在最终响应中。应在处理耗时的业务逻辑之前、写入响应之前调用；
+// 客户端或代理不支持时会忽略 103 响应。
+//
+// 示例:
+//
+//	httpserver.EarlyHints(c, "</app.css>; rel=preload; as=style", "</app.js>; rel=preload; as=script")
+func EarlyHints(c *gin.Context, links ...string) error {
+	if c.Writer.Written() {
+		return errors.Newf(errors.CodeInternalServer, "响应已写出，无法发送 103 Early Hints")
+	}
+	header := c.Writer.Header()
+	for _, link := range links {
+		header.Add("Link", link)
+	}
+
+	// gin 的 WriteHeader 只记录状态码，1xx 响应需要直接写入底层的 http.ResponseWriter
+	var w http.ResponseWriter = c.Writer
+	for {
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		w = unwrapper.Unwrap()
+	}
+	w.WriteHeader(http.StatusEarlyHints)
+	return nil
+}
@@ -0,0 +1,241 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseCacheControl 将 Cache-Control 解析为指令集合，比较时不依赖顺序
+func parseCacheControl(value string) map[string]string {
+	directives := make(map[string]string)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, arg, _ := strings.Cut(part, "=")
+		directives[strings.ToLower(name)] = arg
+	}
+	return directives
+}
+
+func TestCacheDirectiveString(t *testing.T) {
+	tests := []struct {
+		name      string
+		directive CacheDirective
+		want      map[string]string
+	}{
+		{
+			"public with swr",
+			NewCacheDirective().Public().MaxAge(time.Minute).StaleWhileRevalidate(30 * time.Second),
+			map[string]string{"public": "", "max-age": "60", "stale-while-revalidate": "30"},
+		},
+		{
+			"private no-store",
+			NewCacheDirective().Private().NoStore(),
+			map[string]string{"private": "", "no-store": ""},
+		},
+		{
+			"max-age zero must-revalidate",
+			NewCacheDirective().NoCache().MaxAge(0).MustRevalidate(),
+			map[string]string{"no-cache": "", "max-age": "0", "must-revalidate": ""},
+		},
+		{
+			"shared cache",
+			NewCacheDirective().Public().MaxAge(10 * time.Second).SMaxAge(time.Hour).StaleIfError(time.Minute).Immutable(),
+			map[string]string{"public": "", "max-age": "10", "s-maxage": "3600", "stale-if-error": "60", "immutable": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.directive.Validate(); err != nil {
+				t.Fatalf("Expected valid directive, got %v", err)
+			}
+			if got := parseCacheControl(tt.directive.String()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v (%q)", tt.want, got, tt.directive.String())
+			}
+		})
+	}
+}
+
+func TestCacheDirectiveValidate(t *testing.T) {
+	invalid := map[string]CacheDirective{
+		"empty":              NewCacheDirective(),
+		"no-store max-age":   NewCacheDirective().NoStore().MaxAge(time.Minute),
+		"no-store public":    NewCacheDirective().Public().NoStore(),
+		"no-store swr":       NewCacheDirective().NoStore().StaleWhileRevalidate(time.Second),
+		"public and private": NewCacheDirective().Public().Private().MaxAge(time.Minute),
+		"negative max-age":   NewCacheDirective().Private().MaxAge(-time.Second),
+	}
+	for name, directive := range invalid {
+		if err := directive.Validate(); err == nil {
+			t.Errorf("%s: expected validation error for %q", name, directive.String())
+		}
+	}
+}
+
+func TestCacheControlRejectsInvalidDirective(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	if err := CacheControl(c, NewCacheDirective().NoStore().MaxAge(time.Minute)); err == nil {
+		t.Error("Expected error for no-store with max-age")
+	}
+	if got := c.Writer.Header().Get("Cache-Control"); got != "" {
+		t.Errorf("Expected header unchanged on error, got %q", got)
+	}
+}
+
+func TestDefaultCacheControl(t *testing.T) {
+	server := NewServer(nil)
+	server.Use(DefaultCacheControl(NewCacheDirective().Private().NoStore()))
+	server.GET("/default", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	server.GET("/handler", func(c *gin.Context) {
+		if err := CacheControl(c, NewCacheDirective().Public().MaxAge(time.Minute)); err != nil {
+			t.Errorf("CacheControl failed: %v", err)
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	server.GET("/empty", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	public := server.Group("/public", DefaultCacheControl(NewCacheDirective().Public().MaxAge(time.Minute)))
+	public.GET("/feed", func(c *gin.Context) {
+		c.String(http.StatusOK, "feed")
+	})
+
+	tests := []struct {
+		path string
+		want map[string]string
+	}{
+		{"/default", map[string]string{"private": "", "no-store": ""}},
+		{"/handler", map[string]string{"public": "", "max-age": "60"}},
+		{"/empty", map[string]string{"private": "", "no-store": ""}},
+		{"/public/feed", map[string]string{"public": "", "max-age": "60"}},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", tt.path, nil)
+		server.Engine().ServeHTTP(w, req)
+
+		if got := parseCacheControl(w.Header().Get("Cache-Control")); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected Cache-Control %v, got %q", tt.path, tt.want, w.Header().Get("Cache-Control"))
+		}
+	}
+}
+
+func TestDefaultCacheControlPanicsOnInvalidDirective(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic for invalid default directive")
+		}
+	}()
+	DefaultCacheControl(NewCacheDirective().NoStore().MaxAge(time.Minute))
+}
+
+func TestCacheControlMiddlewareSurrogate(t *testing.T) {
+	surrogate := NewCacheDirective().MaxAge(time.Hour).StaleWhileRevalidate(time.Minute)
+	server := NewServer(nil)
+	server.Use(CacheControlMiddleware(CacheControlConfig{
+		Default:         NewCacheDirective().Public().MaxAge(time.Minute),
+		Surrogate:       &surrogate,
+		SurrogateHeader: "CDN-Cache-Control",
+	}))
+	server.GET("/default", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	server.GET("/override", func(c *gin.Context) {
+		SurrogateControl(c, NewCacheDirective().NoStore())
+		c.String(http.StatusOK, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/default", nil)
+	server.Engine().ServeHTTP(w, req)
+	want := map[string]string{"max-age": "3600", "stale-while-revalidate": "60"}
+	if got := parseCacheControl(w.Header().Get("CDN-Cache-Control")); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected CDN-Cache-Control %v, got %q", want, w.Header().Get("CDN-Cache-Control"))
+	}
+	if w.Header().Get(DefaultSurrogateControlHeader) != "" {
+		t.Error("Expected configured header name instead of Surrogate-Control")
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/override", nil)
+	server.Engine().ServeHTTP(w, req)
+	if got := w.Header().Get("CDN-Cache-Control"); got != "no-store" {
+		t.Errorf("Expected handler-set CDN-Cache-Control to win, got %q", got)
+	}
+}
+
+func TestVary(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	c.Writer.Header().Set("Vary", "Accept-Encoding")
+	Vary(c, "Accept", "accept-encoding")
+	Vary(c, "Origin", "")
+
+	fields := strings.Split(c.Writer.Header().Get("Vary"), ", ")
+	sort.Strings(fields)
+	if want := []string{"Accept", "Accept-Encoding", "Origin"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("Expected Vary %v, got %v", want, fields)
+	}
+
+	c.Writer.Header().Set("Vary", "*")
+	Vary(c, "Accept")
+	if got := c.Writer.Header().Get("Vary"); got != "*" {
+		t.Errorf("Expected Vary * to be kept, got %q", got)
+	}
+}
+
+func TestEarlyHints(t *testing.T) {
+	server := NewServer(nil)
+	server.Use(DefaultCacheControl(NewCacheDirective().Private().NoStore()))
+	server.GET("/", func(c *gin.Context) {
+		if err := EarlyHints(c, "</app.css>; rel=preload; as=style"); err != nil {
+			t.Errorf("EarlyHints failed: %v", err)
+		}
+		c.String(http.StatusOK, "page")
+		if err := EarlyHints(c, "</late.js>; rel=preload"); err == nil {
+			t.Error("Expected error after response written")
+		}
+	})
+	ts := httptest.NewServer(server.Engine())
+	defer ts.Close()
+
+	var hints []textproto.MIMEHeader
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			if code == http.StatusEarlyHints {
+				hints = append(hints, header)
+			}
+			return nil
+		},
+	}
+	req, _ := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), "GET", ts.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(hints) != 1 || hints[0].Get("Link") != "</app.css>; rel=preload; as=style" {
+		t.Errorf("Expected one 103 response with Link header, got %v", hints)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Link") == "" {
+		t.Errorf("Expected 200 with Link header kept, got %d %v", resp.StatusCode, resp.Header)
+	}
+}
@@ -0,0 +1,82 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tsopia/go-kit/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RequestTimeoutHeader 客户端声明的请求超时请求头，值为 Go 时长（如 "2s"、"500ms"）或秒数
+	RequestTimeoutHeader = "X-Request-Timeout"
+
+	clientDeadlineKey = "client_request_deadline"
+
+	// maxClientTimeoutSeconds 以秒数表示时允许的最大值，超出即视为无效（远大于任何合理的 max）
+	maxClientTimeoutSeconds = 1 << 31
+)
+
+// ClientTimeoutMiddleware 根据 X-Request-Timeout 请求头为请求上下文设置截止时间
+//
+// 客户端声明的超时超过 max 时按 max 处理；值无法解析或不为正数时返回 400。
+// 截止时间只会缩短已有的超时，之后的 TimeoutMiddleware 或 WithTimeout 也不会将其延长，
+// 通过 ContextFromGin 传给数据库和下游HTTP调用即可实现协作式超时。
+//
+// 示例:
+//
+//	server.Use(httpserver.ClientTimeoutMiddleware(10 * time.Second))
+func ClientTimeoutMiddleware(max time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value := c.GetHeader(RequestTimeoutHeader)
+		if value == "" {
+			c.Next()
+			return
+		}
+
+		timeout, ok := parseClientTimeout(value)
+		if !ok {
+			abortWithError(c, http.StatusBadRequest, errors.CodeInvalidParam, "无效的 "+RequestTimeoutHeader+" 请求头")
+			return
+		}
+		if max > 0 && timeout > max {
+			timeout = max
+		}
+
+		deadline := time.Now().Add(timeout)
+		ctx, cancel := context.WithDeadline(c.Request.Context(), deadline)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Set(clientDeadlineKey, deadline)
+
+		c.Next()
+		abortIfTimedOut(c)
+	}
+}
+
+// parseClientTimeout 解析超时请求头，不带单位的数字按秒处理
+func parseClientTimeout(value string) (time.Duration, bool) {
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, convErr := strconv.ParseFloat(value, 64)
+		if convErr != nil || !(seconds > 0 && seconds < maxClientTimeoutSeconds) {
+			return 0, false
+		}
+		timeout = time.Duration(seconds * float64(time.Second))
+	}
+	return timeout, timeout > 0
+}
+
+// clientDeadline 返回 ClientTimeoutMiddleware 设置的截止时间
+func clientDeadline(c *gin.Context) (time.Time, bool) {
+	value, ok := c.Get(clientDeadlineKey)
+	if !ok {
+		return time.Time{}, false
+	}
+	deadline, ok := value.(time.Time)
+	return deadline, ok
+}
@@ -0,0 +1,99 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// deadlineHandler 返回处理函数上下文的剩余时间（毫秒）
+func deadlineHandler(c *gin.Context) {
+	deadline, ok := ContextFromGin(c).Deadline()
+	if !ok {
+		c.String(http.StatusOK, "none")
+		return
+	}
+	c.String(http.StatusOK, "%d", time.Until(deadline).Milliseconds())
+}
+
+func getWithTimeoutHeader(server *Server, path, value string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	if value != "" {
+		req.Header.Set(RequestTimeoutHeader, value)
+	}
+	server.Engine().ServeHTTP(w, req)
+	return w
+}
+
+func remainingMillis(t *testing.T, w *httptest.ResponseRecorder) time.Duration {
+	t.Helper()
+	var ms int64
+	if _, err := fmt.Sscan(w.Body.String(), &ms); err != nil {
+		t.Fatalf("Expected deadline in response, got %d %q", w.Code, w.Body.String())
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+func TestClientTimeoutMiddleware(t *testing.T) {
+	server := NewServer(nil)
+	server.Use(ClientTimeoutMiddleware(5 * time.Second))
+	server.GET("/deadline", deadlineHandler)
+
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+	}{
+		{"duration", "2s", 2 * time.Second},
+		{"seconds", "1.5", 1500 * time.Millisecond},
+		{"capped", "1h", 5 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := remainingMillis(t, getWithTimeoutHeader(server, "/deadline", tt.header))
+			if got > tt.want || got < tt.want-100*time.Millisecond {
+				t.Errorf("Expected deadline about %v away, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if w := getWithTimeoutHeader(server, "/deadline", ""); w.Body.String() != "none" {
+		t.Errorf("Expected no deadline without header, got %q", w.Body.String())
+	}
+}
+
+func TestClientTimeoutMiddlewareRejectsInvalid(t *testing.T) {
+	server := NewServer(nil)
+	server.Use(ClientTimeoutMiddleware(5 * time.Second))
+	server.GET("/deadline", deadlineHandler)
+
+	for _, value := range []string{"abc", "0", "-1s", "NaN", "1e300"} {
+		if w := getWithTimeoutHeader(server, "/deadline", value); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %q, got %d", value, w.Code)
+		}
+	}
+}
+
+func TestClientTimeoutNotExtendedByRouteTimeout(t *testing.T) {
+	server := NewServer(nil)
+	server.Use(TimeoutMiddleware(10 * time.Millisecond))
+	server.Use(ClientTimeoutMiddleware(time.Minute))
+	server.Handle(http.MethodGet, "/deadline", []RouteOption{WithTimeout(10 * time.Second)}, deadlineHandler)
+	server.Handle(http.MethodGet, "/slow", []RouteOption{WithTimeout(10 * time.Second)}, func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+
+	got := remainingMillis(t, getWithTimeoutHeader(server, "/deadline", "1s"))
+	if got > time.Second || got < 900*time.Millisecond {
+		t.Errorf("Expected client deadline to cap route timeout, got %v", got)
+	}
+
+	if w := getWithTimeoutHeader(server, "/slow", "20ms"); w.Code != http.StatusGatewayTimeout {
+		t.Errorf("Expected 504 after client deadline, got %d", w.Code)
+	}
+}
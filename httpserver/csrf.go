@@ -0,0 +1,242 @@
+package httpserver
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/tsopia/go-kit/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultCSRFCookieName 默认CSRF Cookie名称
+	DefaultCSRFCookieName = "csrf_token"
+	// DefaultCSRFHeaderName 默认CSRF请求头名称
+	DefaultCSRFHeaderName = "X-CSRF-Token"
+	// DefaultCSRFFormField 默认CSRF表单字段名称
+	DefaultCSRFFormField = "csrf_token"
+
+	csrfTokenKey  = "csrf_token"
+	csrfConfigKey = "csrf_config"
+	csrfTokenSize = 32
+)
+
+// CSRFConfig CSRF中间件配置（双重提交Cookie模式）
+type CSRFConfig struct {
+	CookieName   string        // Cookie名称，默认 csrf_token
+	HeaderName   string        // 请求头名称，默认 X-CSRF-Token
+	FormField    string        // 表单字段名称，默认 csrf_token
+	CookiePath   string        // Cookie路径，默认 /
+	CookieDomain string        // Cookie域名
+	MaxAge       int           // Cookie有效期（秒），0表示会话Cookie
+	Secure       bool          // 是否仅通过HTTPS发送
+	SameSite     http.SameSite // SameSite策略，默认 Lax
+	// RotateOnSafeMethods 安全方法（GET/HEAD/OPTIONS/TRACE）请求时是否轮换令牌
+	RotateOnSafeMethods bool
+	// Skipper 返回true时跳过校验，用于API Token认证等非Cookie会话的路由
+	Skipper func(c *gin.Context) bool
+	// UseSession 令牌保存在 SessionMiddleware 的会话中而不是单独的Cookie（同步令牌模式），
+	// 需要先注册 SessionMiddleware，前端通过 GetCSRFToken 渲染到页面获取令牌
+	UseSession bool
+	// Store 自定义令牌存储，设置后 UseSession 和Cookie相关配置不再生效
+	Store CSRFTokenStore
+}
+
+// CSRFTokenStore CSRF令牌存储
+//
+// 默认将令牌写入Cookie（双重提交Cookie模式），UseSession 时保存在会话中（同步令牌模式）。
+// 自定义实现可以把令牌保存到其他位置，例如按用户保存在Redis中。
+type CSRFTokenStore interface {
+	// Token 读取当前请求已签发的令牌，没有令牌时返回空字符串
+	Token(c *gin.Context) (string, error)
+	// SaveToken 保存新签发的令牌
+	SaveToken(c *gin.Context, token string) error
+}
+
+// cookieCSRFStore 令牌写入前端脚本可读的Cookie
+type cookieCSRFStore struct {
+	cfg CSRFConfig
+}
+
+func (s cookieCSRFStore) Token(c *gin.Context) (string, error) {
+	token, _ := c.Cookie(s.cfg.CookieName)
+	return token, nil
+}
+
+func (s cookieCSRFStore) SaveToken(c *gin.Context, token string) error {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     s.cfg.CookieName,
+		Value:    token,
+		Path:     s.cfg.CookiePath,
+		Domain:   s.cfg.CookieDomain,
+		MaxAge:   s.cfg.MaxAge,
+		Secure:   s.cfg.Secure,
+		HttpOnly: false, // 双重提交模式需要前端脚本读取
+		SameSite: s.cfg.SameSite,
+	})
+	return nil
+}
+
+// sessionCSRFStore 令牌保存在 SessionMiddleware 的会话中
+type sessionCSRFStore struct{}
+
+func (sessionCSRFStore) Token(c *gin.Context) (string, error) {
+	sess := Session(c)
+	if sess == nil {
+		return "", errSessionRequired
+	}
+	return sess.GetString(csrfSessionKey), nil
+}
+
+func (sessionCSRFStore) SaveToken(c *gin.Context, token string) error {
+	sess := Session(c)
+	if sess == nil {
+		return errSessionRequired
+	}
+	sess.Set(csrfSessionKey, token)
+	return sess.Save()
+}
+
+// withDefaults 填充默认值
+func (cfg CSRFConfig) withDefaults() CSRFConfig {
+	if cfg.CookieName == "" {
+		cfg.CookieName = DefaultCSRFCookieName
+	}
+	if cfg.HeaderName == "" {
+		cfg.HeaderName = DefaultCSRFHeaderName
+	}
+	if cfg.FormField == "" {
+		cfg.FormField = DefaultCSRFFormField
+	}
+	if cfg.CookiePath == "" {
+		cfg.CookiePath = "/"
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+	if cfg.Store == nil {
+		if cfg.UseSession {
+			cfg.Store = sessionCSRFStore{}
+		} else {
+			cfg.Store = cookieCSRFStore{cfg: cfg}
+		}
+	}
+	return cfg
+}
+
+// CSRFMiddleware CSRF防护中间件
+//
+// 采用双重提交Cookie模式：令牌写入Cookie（前端脚本可读），
+// 非安全方法（POST/PUT/PATCH/DELETE）必须通过请求头或表单字段提交相同的令牌，
+// 否则返回 403 及包含 trace_id 的JSON错误。
+//
+// 示例:
+//
+//	server.Use(httpserver.CSRFMiddleware(httpserver.CSRFConfig{
+//	    Secure: true,
+//	    Skipper: func(c *gin.Context) bool {
+//	        return strings.HasPrefix(c.GetHeader("Authorization"), "Bearer ")
+//	    },
+//	}))
+func CSRFMiddleware(cfg CSRFConfig) gin.HandlerFunc {
+	cfg = cfg.withDefaults()
+
+	return func(c *gin.Context) {
+		if cfg.Skipper != nil && cfg.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		c.Set(csrfConfigKey, cfg)
+		cookieToken, err := cfg.Store.Token(c)
+		if err != nil {
+			if errors.StdIs(err, errSessionRequired) {
+				abortWithError(c, http.StatusInternalServerError, errors.CodeInternalServer, "CSRF会话模式需要先注册SessionMiddleware")
+				return
+			}
+			abortWithError(c, http.StatusInternalServerError, errors.CodeInternalServer, "读取CSRF令牌失败")
+			return
+		}
+
+		if isSafeMethod(c.Request.Method) {
+			token := cookieToken
+			if token == "" || cfg.RotateOnSafeMethods {
+				var err error
+				if token, err = issueCSRFToken(c, cfg); err != nil {
+					abortWithError(c, http.StatusInternalServerError, errors.CodeInternalServer, "生成CSRF令牌失败")
+					return
+				}
+			}
+			c.Set(csrfTokenKey, token)
+			c.Next()
+			return
+		}
+
+		submitted := c.GetHeader(cfg.HeaderName)
+		if submitted == "" {
+			submitted = c.PostForm(cfg.FormField)
+		}
+
+		if cookieToken == "" || submitted == "" {
+			abortWithError(c, http.StatusForbidden, errors.CodeForbidden, "缺少CSRF令牌")
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(cookieToken), []byte(submitted)) != 1 {
+			abortWithError(c, http.StatusForbidden, errors.CodeForbidden, "CSRF令牌不匹配")
+			return
+		}
+
+		c.Set(csrfTokenKey, cookieToken)
+		c.Next()
+	}
+}
+
+// GetCSRFToken 获取当前请求的CSRF令牌，用于渲染到页面或返回给前端
+func GetCSRFToken(c *gin.Context) string {
+	return c.GetString(csrfTokenKey)
+}
+
+// RegenerateCSRFToken 重新生成CSRF令牌并保存到令牌存储（默认写入Cookie）
+// 应在登录等会话权限变化后调用，防止会话固定攻击
+func RegenerateCSRFToken(c *gin.Context) (string, error) {
+	cfg := CSRFConfig{}.withDefaults()
+	if value, exists := c.Get(csrfConfigKey); exists {
+		if stored, ok := value.(CSRFConfig); ok {
+			cfg = stored
+		}
+	}
+
+	token, err := issueCSRFToken(c, cfg)
+	if err != nil {
+		return "", err
+	}
+	c.Set(csrfTokenKey, token)
+	return token, nil
+}
+
+// issueCSRFToken 生成新令牌并保存到令牌存储
+func issueCSRFToken(c *gin.Context, cfg CSRFConfig) (string, error) {
+	token, err := generateCSRFToken()
+	if err != nil {
+		return "", err
+	}
+	if err := cfg.Store.SaveToken(c, token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// generateCSRFToken 生成加密安全的随机令牌
+func generateCSRFToken() (string, error) {
+	return randomToken(csrfTokenSize)
+}
+
+// isSafeMethod 判断是否为安全（不改变状态）的HTTP方法
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,236 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newCSRFTestServer(cfg CSRFConfig) *Server {
+	server := NewServer(nil)
+	server.Use(TraceIDMiddleware())
+	server.Use(CSRFMiddleware(cfg))
+	server.GET("/form", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"token": GetCSRFToken(c)})
+	})
+	server.POST("/submit", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	})
+	server.POST("/login", func(c *gin.Context) {
+		token, err := RegenerateCSRFToken(c)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"token": token})
+	})
+	return server
+}
+
+// fetchCSRFToken 通过GET请求获取令牌和Cookie
+func fetchCSRFToken(t *testing.T, server *Server) (string, *http.Cookie) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/form", nil)
+	server.Engine().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var cookie *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == DefaultCSRFCookieName {
+			cookie = c
+		}
+	}
+	if cookie == nil || cookie.Value == "" {
+		t.Fatal("Expected CSRF cookie to be issued")
+	}
+	if cookie.HttpOnly {
+		t.Error("Expected CSRF cookie to be readable by scripts")
+	}
+
+	var body map[string]string
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body["token"] != cookie.Value {
+		t.Errorf("Expected token in context to match cookie, got %q and %q", body["token"], cookie.Value)
+	}
+	return cookie.Value, cookie
+}
+
+func TestCSRFMiddlewareHappyPath(t *testing.T) {
+	server := newCSRFTestServer(CSRFConfig{})
+	token, cookie := fetchCSRFToken(t, server)
+
+	// 通过请求头提交
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/submit", nil)
+	req.AddCookie(cookie)
+	req.Header.Set(DefaultCSRFHeaderName, token)
+	server.Engine().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d with header token, got %d", http.StatusOK, w.Code)
+	}
+
+	// 通过表单字段提交
+	form := url.Values{DefaultCSRFFormField: {token}}
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/submit", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(cookie)
+	server.Engine().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d with form token, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestCSRFMiddlewareMissingToken(t *testing.T) {
+	server := newCSRFTestServer(CSRFConfig{})
+	_, cookie := fetchCSRFToken(t, server)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/submit", nil)
+	req.AddCookie(cookie)
+	server.Engine().ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("Expected status code %d, got %d", http.StatusForbidden, w.Code)
+	}
+
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body["trace_id"] == "" || body["trace_id"] == nil {
+		t.Error("Expected trace_id in error response")
+	}
+	if body["trace_id"] != w.Header().Get("X-Trace-ID") {
+		t.Errorf("Expected trace_id to match response header, got %v", body["trace_id"])
+	}
+}
+
+func TestCSRFMiddlewareMismatchedToken(t *testing.T) {
+	server := newCSRFTestServer(CSRFConfig{})
+	_, cookie := fetchCSRFToken(t, server)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/submit", nil)
+	req.AddCookie(cookie)
+	req.Header.Set(DefaultCSRFHeaderName, "forged-token")
+	server.Engine().ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", http.StatusForbidden, w.Code)
+	}
+}
+
+func TestCSRFMiddlewareSkipper(t *testing.T) {
+	server := newCSRFTestServer(CSRFConfig{
+		Skipper: func(c *gin.Context) bool {
+			return strings.HasPrefix(c.GetHeader("Authorization"), "Bearer ")
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/submit", nil)
+	req.Header.Set("Authorization", "Bearer api-token")
+	server.Engine().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d for skipped route, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestCSRFMiddlewareRegenerateToken(t *testing.T) {
+	server := newCSRFTestServer(CSRFConfig{CookieName: "xsrf"})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/form", nil)
+	server.Engine().ServeHTTP(w, req)
+	oldCookie := w.Result().Cookies()[0]
+	if oldCookie.Name != "xsrf" {
+		t.Fatalf("Expected custom cookie name 'xsrf', got %q", oldCookie.Name)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/login", nil)
+	req.AddCookie(oldCookie)
+	req.Header.Set(DefaultCSRFHeaderName, oldCookie.Value)
+	server.Engine().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "xsrf" || cookies[0].Value == oldCookie.Value {
+		t.Errorf("Expected a new 'xsrf' cookie after regeneration, got %v", cookies)
+	}
+}
+
+// userCSRFStore 按用户保存令牌的测试存储
+type userCSRFStore struct {
+	tokens map[string]string
+}
+
+func (s *userCSRFStore) Token(c *gin.Context) (string, error) {
+	return s.tokens[c.GetHeader("X-User")], nil
+}
+
+func (s *userCSRFStore) SaveToken(c *gin.Context, token string) error {
+	s.tokens[c.GetHeader("X-User")] = token
+	return nil
+}
+
+func TestCSRFMiddlewareCustomStore(t *testing.T) {
+	store := &userCSRFStore{tokens: map[string]string{}}
+	server := newCSRFTestServer(CSRFConfig{Store: store, HeaderName: "X-XSRF-Token", FormField: "_csrf"})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/form", nil)
+	req.Header.Set("X-User", "alice")
+	server.Engine().ServeHTTP(w, req)
+	if len(w.Result().Cookies()) != 0 {
+		t.Error("Custom store should not issue a cookie")
+	}
+	token := store.tokens["alice"]
+	if token == "" {
+		t.Fatal("Expected token to be saved in custom store")
+	}
+
+	tests := []struct {
+		name   string
+		user   string
+		header string
+		form   string
+		want   int
+	}{
+		{"valid header", "alice", token, "", http.StatusOK},
+		{"valid form field", "alice", "", token, http.StatusOK},
+		{"other user", "bob", token, "", http.StatusForbidden},
+		{"invalid token", "alice", "forged-token", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			if tt.form != "" {
+				form.Set("_csrf", tt.form)
+			}
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/submit", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("X-User", tt.user)
+			if tt.header != "" {
+				req.Header.Set("X-XSRF-Token", tt.header)
+			}
+			server.Engine().ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("Expected status code %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package httpserver
+
+import (
+	"github.com/tsopia/go-kit/database"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const databaseKey = "database"
+
+// InjectDB 将数据库管理器存入 gin context 和 request context
+//
+// 处理函数通过 DBFromGin 获取绑定请求上下文的GORM会话，不再直接使用全局实例；
+// 下游只拿到 request context 的代码可以使用 database.FromContext。
+//
+// 示例:
+//
+//	server.Use(httpserver.TraceIDMiddleware(), httpserver.InjectDB(db))
+//	server.GET("/users/:id", func(c *gin.Context) {
+//	    var user User
+//	    err := httpserver.DBFromGin(c).First(&user, c.Param("id")).Error
+//	    ...
+//	})
+func InjectDB(db *database.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(databaseKey, db)
+		c.Request = c.Request.WithContext(database.WithDatabase(c.Request.Context(), db))
+		c.Next()
+	}
+}
+
+// DBFromGin 获取绑定当前请求上下文的GORM会话，见 database.SessionFromContext
+// 未注册 InjectDB 中间件时 panic
+func DBFromGin(c *gin.Context) *gorm.DB {
+	value, _ := c.Get(databaseKey)
+	db, ok := value.(*database.Database)
+	if !ok {
+		db, ok = database.FromContext(c.Request.Context())
+	}
+	if !ok {
+		panic("httpserver: 未找到数据库实例，请先注册 InjectDB 中间件")
+	}
+	return database.SessionFromContext(c.Request.Context(), db)
+}
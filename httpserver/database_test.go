@@ -0,0 +1,63 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/tsopia/go-kit/constants"
+	"github.com/tsopia/go-kit/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestInjectDB(t *testing.T) {
+	db, err := database.New(&database.Config{
+		Driver:   "sqlite",
+		Database: filepath.Join(t.TempDir(), "inject.db"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	server := NewServer(nil)
+	server.Use(TraceIDMiddleware(), InjectDB(db))
+	server.GET("/db", func(c *gin.Context) {
+		session := DBFromGin(c)
+		if constants.TraceIDFromContext(session.Statement.Context) != GetTraceID(c) {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if fromCtx, ok := database.FromContext(c.Request.Context()); !ok || fromCtx != db {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		var one int
+		if err := session.Raw("SELECT 1").Scan(&one).Error; err != nil || one != 1 {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/db", nil)
+	server.Engine().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected 200, got %d", w.Code)
+	}
+}
+
+func TestDBFromGinWithoutInjectDB(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected DBFromGin to panic without InjectDB")
+		}
+	}()
+	DBFromGin(c)
+}
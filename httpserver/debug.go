@@ -0,0 +1,300 @@
+package httpserver
+
+import (
+	"crypto/subtle"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	rpprof "runtime/pprof"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tsopia/go-kit/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultDebugPrefix 调试端点的默认路径前缀
+	DefaultDebugPrefix = "/debug"
+	// DefaultDebugTokenHeader 调试端点静态令牌的默认请求头
+	DefaultDebugTokenHeader = "X-Debug-Token"
+	// DefaultDebugSnapshotInterval 两次 goroutine/heap 快照之间的默认最小间隔
+	DefaultDebugSnapshotInterval = 10 * time.Second
+
+	// DebugEndpointKey 调试端点请求在 gin.Context 中的标记，访问日志和指标中间件据此跳过这些请求
+	DebugEndpointKey = "debug_endpoint"
+)
+
+// DebugEndpointsConfig 调试端点配置
+//
+// Token、AllowCIDRs、Auth 至少设置一项，同时设置多项时必须全部通过。
+type DebugEndpointsConfig struct {
+	// Prefix 路径前缀，默认 /debug
+	Prefix string
+	// Token 静态令牌，请求头 TokenHeader 必须与之相同（常量时间比较）
+	Token string
+	// TokenHeader 携带令牌的请求头，默认 X-Debug-Token
+	TokenHeader string
+	// AllowCIDRs 允许访问的来源网段，例如 10.0.0.0/8、127.0.0.1/32
+	// 使用连接的对端地址（不信任 X-Forwarded-For），经过反向代理时应改用 Auth
+	AllowCIDRs []string
+	// Auth 自定义认证中间件，拒绝时应调用 c.Abort
+	Auth gin.HandlerFunc
+	// AllowInsecure 允许在没有任何认证的情况下挂载，仅用于本地开发
+	AllowInsecure bool
+	// SnapshotInterval 两次 snapshot 请求之间的最小间隔，默认 DefaultDebugSnapshotInterval
+	SnapshotInterval time.Duration
+	// ConfigDump 返回脱敏后的配置，设置后挂载 config 端点；返回值编码为JSON
+	ConfigDump func() (interface{}, error)
+	// LogRequests 访问日志中记录调试端点的请求，默认跳过
+	LogRequests bool
+}
+
+// withDefaults 填充默认值
+func (cfg DebugEndpointsConfig) withDefaults() DebugEndpointsConfig {
+	if cfg.Prefix == "" {
+		cfg.Prefix = DefaultDebugPrefix
+	}
+	cfg.Prefix = "/" + strings.Trim(cfg.Prefix, "/")
+	if cfg.TokenHeader == "" {
+		cfg.TokenHeader = DefaultDebugTokenHeader
+	}
+	if cfg.SnapshotInterval <= 0 {
+		cfg.SnapshotInterval = DefaultDebugSnapshotInterval
+	}
+	return cfg
+}
+
+// EnableDebugEndpoints 在 Prefix 下挂载 pprof、expvar、构建信息、快照和配置端点
+//
+// 挂载的路由（默认前缀 /debug）:
+//   - GET  /debug/pprof/...   net/http/pprof（index、cmdline、profile、symbol、trace 及各类 profile）
+//   - GET  /debug/vars        expvar
+//   - GET  /debug/buildinfo   版本、提交、Go版本等构建信息
+//   - GET  /debug/snapshot    goroutine 或 heap 快照（?type=heap），按 SnapshotInterval 限流
+//   - GET  /debug/config      ConfigDump 返回的配置（设置了 ConfigDump 时）
+//   - GET  /debug/routes      已注册的路由（方法、路径、处理函数），见 Server.Routes
+//   - POST /debug/drain       进入排空状态并返回就绪状态，见 Server.BeginDrain
+//
+// 没有配置任何认证且未设置 AllowInsecure 时拒绝挂载并返回错误，避免意外公开 pprof。
+// 请求在 gin.Context 中带有 DebugEndpointKey 标记，LoggingMiddleware 默认不记录这些请求。
+//
+// 示例:
+//
+//	err := server.EnableDebugEndpoints(httpserver.DebugEndpointsConfig{
+//	    Token:      os.Getenv("DEBUG_TOKEN"),
+//	    AllowCIDRs: []string{"10.0.0.0/8"},
+//	})
+func (s *Server) EnableDebugEndpoints(cfg DebugEndpointsConfig) error {
+	cfg = cfg.withDefaults()
+	auth, err := debugAuth(cfg)
+	if err != nil {
+		return err
+	}
+
+	group := s.engine.Group(cfg.Prefix, func(c *gin.Context) {
+		if !cfg.LogRequests {
+			c.Set(DebugEndpointKey, true)
+		}
+		c.Next()
+	})
+	group.Use(auth...)
+
+	group.GET("/pprof/*name", debugPprof)
+	group.POST("/pprof/symbol", gin.WrapF(pprof.Symbol))
+	group.GET("/vars", gin.WrapH(expvar.Handler()))
+	group.GET("/buildinfo", debugBuildInfo)
+	group.GET("/snapshot", newSnapshotHandler(cfg.SnapshotInterval))
+	group.GET("/routes", s.debugRoutes)
+	group.POST("/drain", s.debugDrain)
+	if cfg.ConfigDump != nil {
+		group.GET("/config", debugConfigDump(cfg.ConfigDump))
+	}
+	return nil
+}
+
+// IsDebugEndpoint 判断请求是否为调试端点，自定义的日志和指标中间件可据此跳过
+func IsDebugEndpoint(c *gin.Context) bool {
+	return c.GetBool(DebugEndpointKey)
+}
+
+// debugAuth 根据配置构建认证中间件，没有任何认证且未设置 AllowInsecure 时返回错误
+func debugAuth(cfg DebugEndpointsConfig) ([]gin.HandlerFunc, error) {
+	var handlers []gin.HandlerFunc
+	if len(cfg.AllowCIDRs) > 0 {
+		nets := make([]*net.IPNet, 0, len(cfg.AllowCIDRs))
+		for _, cidr := range cfg.AllowCIDRs {
+			_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+			if err != nil {
+				return nil, errors.Newf(errors.CodeInvalidParam, "调试端点的 AllowCIDRs 无效: %s", cidr)
+			}
+			nets = append(nets, ipNet)
+		}
+		handlers = append(handlers, debugCIDRAuth(nets))
+	}
+	if cfg.Token != "" {
+		handlers = append(handlers, debugTokenAuth(cfg.TokenHeader, cfg.Token))
+	}
+	if cfg.Auth != nil {
+		handlers = append(handlers, cfg.Auth)
+	}
+	if len(handlers) == 0 && !cfg.AllowInsecure {
+		return nil, errors.Newf(errors.CodeInvalidParam,
+			"调试端点没有配置认证（Token、AllowCIDRs 或 Auth），拒绝挂载；本地开发可设置 AllowInsecure")
+	}
+	return handlers, nil
+}
+
+func debugCIDRAuth(nets []*net.IPNet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := net.ParseIP(c.RemoteIP())
+		for _, ipNet := range nets {
+			if ip != nil && ipNet.Contains(ip) {
+				c.Next()
+				return
+			}
+		}
+		abortWithError(c, http.StatusForbidden, errors.CodeForbidden, "来源地址不允许访问调试端点")
+	}
+}
+
+func debugTokenAuth(header, token string) gin.HandlerFunc {
+	expected := []byte(token)
+	return func(c *gin.Context) {
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader(header)), expected) != 1 {
+			abortWithError(c, http.StatusUnauthorized, errors.CodeUnauthorized, "调试端点令牌无效")
+			return
+		}
+		c.Next()
+	}
+}
+
+// debugPprof 按名称分发到 net/http/pprof 的处理函数
+func debugPprof(c *gin.Context) {
+	switch name := strings.Trim(c.Param("name"), "/"); name {
+	case "":
+		pprof.Index(c.Writer, c.Request)
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		if rpprof.Lookup(name) == nil {
+			abortWithError(c, http.StatusNotFound, errors.CodeNotFound, "未知的profile: "+name)
+			return
+		}
+		pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// DebugBuildInfo 构建信息端点的响应
+type DebugBuildInfo struct {
+	GoVersion string            `json:"go_version"`
+	Path      string            `json:"path,omitempty"`
+	Version   string            `json:"version,omitempty"`
+	Commit    string            `json:"commit,omitempty"`
+	CommitAt  string            `json:"commit_time,omitempty"`
+	Modified  bool              `json:"modified,omitempty"`
+	Settings  map[string]string `json:"settings,omitempty"`
+}
+
+// readBuildInfo 读取构建信息（测试时替换）
+var readBuildInfo = debug.ReadBuildInfo
+
+func debugBuildInfo(c *gin.Context) {
+	info := DebugBuildInfo{GoVersion: runtime.Version()}
+	if bi, ok := readBuildInfo(); ok {
+		info.Path = bi.Main.Path
+		info.Version = bi.Main.Version
+		info.Settings = make(map[string]string, len(bi.Settings))
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				info.Commit = setting.Value
+			case "vcs.time":
+				info.CommitAt = setting.Value
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			default:
+				info.Settings[setting.Key] = setting.Value
+			}
+		}
+	}
+	c.JSON(http.StatusOK, info)
+}
+
+// newSnapshotHandler 返回 goroutine/heap 快照处理函数，两次快照之间至少间隔 interval
+//
+// ?type=goroutine（默认）或 heap；?debug=1/2 输出文本，默认输出 go tool pprof 可读的二进制格式；
+// heap 快照前可通过 ?gc=1 先执行一次GC。
+func newSnapshotHandler(interval time.Duration) gin.HandlerFunc {
+	var mu sync.Mutex
+	var last time.Time
+
+	return func(c *gin.Context) {
+		kind := c.DefaultQuery("type", "goroutine")
+		if kind != "goroutine" && kind != "heap" {
+			abortWithError(c, http.StatusBadRequest, errors.CodeInvalidParam, "type 只能是 goroutine 或 heap")
+			return
+		}
+		debugLevel, _ := strconv.Atoi(c.Query("debug"))
+
+		mu.Lock()
+		if wait := interval - time.Since(last); !last.IsZero() && wait > 0 {
+			mu.Unlock()
+			c.Header("Retry-After", strconv.Itoa(int((wait+time.Second-1)/time.Second)))
+			abortWithError(c, http.StatusTooManyRequests, errors.CodeTooManyRequests,
+				fmt.Sprintf("快照请求过于频繁，%v 后重试", wait.Round(time.Second)))
+			return
+		}
+		last = time.Now()
+		mu.Unlock()
+
+		if kind == "heap" && c.Query("gc") == "1" {
+			runtime.GC()
+		}
+		if debugLevel > 0 {
+			c.Header("Content-Type", "text/plain; charset=utf-8")
+		} else {
+			c.Header("Content-Type", "application/octet-stream")
+			c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.pb.gz"`, kind, time.Now().Format("20060102-150405")))
+		}
+		c.Status(http.StatusOK)
+		if err := rpprof.Lookup(kind).WriteTo(c.Writer, debugLevel); err != nil {
+			c.Error(err)
+		}
+	}
+}
+
+// debugRoutes 返回请求时已注册的所有路由
+func (s *Server) debugRoutes(c *gin.Context) {
+	c.JSON(http.StatusOK, s.Routes())
+}
+
+// debugDrain 进入排空状态，返回之后的就绪状态
+func (s *Server) debugDrain(c *gin.Context) {
+	s.BeginDrain()
+	c.JSON(http.StatusOK, s.Readiness())
+}
+
+func debugConfigDump(dump func() (interface{}, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg, err := dump()
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, errors.CodeInternalServer, "读取配置失败: "+err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, cfg)
+	}
+}
@@ -0,0 +1,246 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func debugRequest(server *Server, path string, header http.Header) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	server.Engine().ServeHTTP(w, req)
+	return w
+}
+
+func tokenHeader(token string) http.Header {
+	return http.Header{DefaultDebugTokenHeader: []string{token}}
+}
+
+func TestEnableDebugEndpointsRequiresAuth(t *testing.T) {
+	server := NewServer(nil)
+	if err := server.EnableDebugEndpoints(DebugEndpointsConfig{}); err == nil {
+		t.Fatal("Expected mounting without auth to fail")
+	}
+	if w := debugRequest(server, "/debug/pprof/", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected no routes mounted, got %d", w.Code)
+	}
+
+	if err := server.EnableDebugEndpoints(DebugEndpointsConfig{AllowCIDRs: []string{"10.0.0.0/33"}}); err == nil {
+		t.Error("Expected invalid CIDR to be rejected")
+	}
+
+	insecure := NewServer(nil)
+	if err := insecure.EnableDebugEndpoints(DebugEndpointsConfig{AllowInsecure: true}); err != nil {
+		t.Fatalf("Expected AllowInsecure to mount: %v", err)
+	}
+	if w := debugRequest(insecure, "/debug/vars", nil); w.Code != http.StatusOK {
+		t.Errorf("Expected insecure endpoints to be reachable, got %d", w.Code)
+	}
+}
+
+func TestDebugEndpointsTokenAuth(t *testing.T) {
+	server := NewServer(nil)
+	if err := server.EnableDebugEndpoints(DebugEndpointsConfig{Token: "s3cret"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, header := range []http.Header{nil, tokenHeader("wrong")} {
+		w := debugRequest(server, "/debug/pprof/heap", header)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for header %v, got %d", header, w.Code)
+		}
+	}
+
+	w := debugRequest(server, "/debug/pprof/heap", tokenHeader("s3cret"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected heap profile, got %d: %s", w.Code, w.Body.String())
+	}
+	// 二进制 profile 为 gzip 压缩的 protobuf
+	if !bytes.HasPrefix(w.Body.Bytes(), []byte{0x1f, 0x8b}) {
+		t.Errorf("Expected gzip profile, got %q", w.Body.String()[:min(20, w.Body.Len())])
+	}
+
+	w = debugRequest(server, "/debug/pprof/goroutine?debug=1", tokenHeader("s3cret"))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine profile") {
+		t.Errorf("Expected text goroutine profile, got %d", w.Code)
+	}
+	w = debugRequest(server, "/debug/pprof/", tokenHeader("s3cret"))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "heap") {
+		t.Errorf("Expected pprof index, got %d", w.Code)
+	}
+	if w := debugRequest(server, "/debug/pprof/nope", tokenHeader("s3cret")); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown profile, got %d", w.Code)
+	}
+}
+
+func TestDebugEndpointsCIDRAndCustomAuth(t *testing.T) {
+	// httptest 请求的对端地址为 192.0.2.1
+	server := NewServer(nil)
+	if err := server.EnableDebugEndpoints(DebugEndpointsConfig{AllowCIDRs: []string{"10.0.0.0/8"}}); err != nil {
+		t.Fatal(err)
+	}
+	if w := debugRequest(server, "/debug/vars", http.Header{"X-Forwarded-For": []string{"10.0.0.1"}}); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for address outside allowlist, got %d", w.Code)
+	}
+
+	server = NewServer(nil)
+	err := server.EnableDebugEndpoints(DebugEndpointsConfig{
+		Prefix:     "/admin/",
+		AllowCIDRs: []string{"192.0.2.0/24"},
+		Auth: func(c *gin.Context) {
+			if c.GetHeader("X-Admin") != "yes" {
+				c.AbortWithStatus(http.StatusForbidden)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := debugRequest(server, "/admin/vars", nil); w.Code != http.StatusForbidden {
+		t.Errorf("Expected custom auth to reject, got %d", w.Code)
+	}
+	w := debugRequest(server, "/admin/vars", http.Header{"X-Admin": []string{"yes"}})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "memstats") {
+		t.Errorf("Expected expvar output, got %d", w.Code)
+	}
+}
+
+func TestDebugBuildInfoAndConfig(t *testing.T) {
+	original := readBuildInfo
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			Main: debug.Module{Path: "example.com/orders", Version: "v1.2.3"},
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "abc123"},
+				{Key: "vcs.modified", Value: "true"},
+				{Key: "GOOS", Value: "linux"},
+			},
+		}, true
+	}
+	defer func() { readBuildInfo = original }()
+
+	server := NewServer(nil)
+	err := server.EnableDebugEndpoints(DebugEndpointsConfig{
+		Token: "t",
+		ConfigDump: func() (interface{}, error) {
+			return map[string]string{"db_password": "***"}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := debugRequest(server, "/debug/buildinfo", tokenHeader("t"))
+	var info DebugBuildInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &info); err != nil {
+		t.Fatalf("Invalid build info %q: %v", w.Body.String(), err)
+	}
+	if info.Version != "v1.2.3" || info.Commit != "abc123" || !info.Modified || info.GoVersion == "" || info.Settings["GOOS"] != "linux" {
+		t.Errorf("Unexpected build info: %+v", info)
+	}
+
+	w = debugRequest(server, "/debug/config", tokenHeader("t"))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"db_password":"***"`) {
+		t.Errorf("Unexpected config dump: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDebugSnapshotRateLimit(t *testing.T) {
+	server := NewServer(nil)
+	if err := server.EnableDebugEndpoints(DebugEndpointsConfig{Token: "t", SnapshotInterval: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+
+	if w := debugRequest(server, "/debug/snapshot?type=thread", tokenHeader("t")); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unknown snapshot type, got %d", w.Code)
+	}
+	w := debugRequest(server, "/debug/snapshot?type=heap&gc=1", tokenHeader("t"))
+	if w.Code != http.StatusOK || !strings.Contains(w.Header().Get("Content-Disposition"), "heap-") {
+		t.Fatalf("Expected heap snapshot, got %d", w.Code)
+	}
+	w = debugRequest(server, "/debug/snapshot", tokenHeader("t"))
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected rate limited snapshot, got %d", w.Code)
+	}
+}
+
+func TestDebugEndpointsSkipAccessLog(t *testing.T) {
+	logger := &recordingLogger{}
+	server := newLoggingTestServer(LoggingConfig{Logger: logger})
+	if err := server.EnableDebugEndpoints(DebugEndpointsConfig{Token: "t"}); err != nil {
+		t.Fatal(err)
+	}
+
+	debugRequest(server, "/debug/vars", tokenHeader("t"))
+	debugRequest(server, "/debug/vars", nil)
+	get(server, "/ok")
+	if len(logger.entries) != 1 || logger.entries[0].fields["path"] != "/ok" {
+		t.Errorf("Expected only non-debug request to be logged, got %+v", logger.entries)
+	}
+}
+
+func TestDebugRoutes(t *testing.T) {
+	server := NewServer(nil)
+	server.GET("/orders", func(c *gin.Context) {})
+	if err := server.EnableDebugEndpoints(DebugEndpointsConfig{Token: "t"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if w := debugRequest(server, "/debug/routes", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected routes endpoint to require auth, got %d", w.Code)
+	}
+	w := debugRequest(server, "/debug/routes", tokenHeader("t"))
+	var routes []RouteInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &routes); err != nil {
+		t.Fatalf("Failed to decode routes %q: %v", w.Body.String(), err)
+	}
+	found := false
+	for _, route := range routes {
+		if route.Method == http.MethodGet && route.Path == "/orders" {
+			found = true
+		}
+	}
+	if !found || len(routes) != len(server.Routes()) {
+		t.Errorf("Expected registered routes in the listing, got %+v", routes)
+	}
+}
+
+func TestDebugDrain(t *testing.T) {
+	server := NewServer(nil)
+	if err := server.EnableDebugEndpoints(DebugEndpointsConfig{Token: "t"}); err != nil {
+		t.Fatal(err)
+	}
+
+	drain := func(header http.Header) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/debug/drain", nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		server.Engine().ServeHTTP(w, req)
+		return w
+	}
+
+	if w := drain(nil); w.Code != http.StatusUnauthorized || server.Draining() {
+		t.Fatalf("Expected drain endpoint to require auth, got %d", w.Code)
+	}
+	w := drain(tokenHeader("t"))
+	var status ReadinessStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("Failed to decode drain response %q: %v", w.Body.String(), err)
+	}
+	if w.Code != http.StatusOK || !status.Draining || status.Ready || !server.Draining() {
+		t.Errorf("Expected server to be draining, got %d %+v", w.Code, status)
+	}
+}
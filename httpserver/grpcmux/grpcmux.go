@@ -0,0 +1,124 @@
+// Package grpcmux 让 gRPC 服务与 httpserver 的HTTP接口共用同一端口
+//
+// 独立为子包，只使用HTTP的服务不会引入gRPC依赖。
+package grpcmux
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tsopia/go-kit/httpserver"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// Handler 将 gRPC 请求交给 grpc.Server 处理，实现 httpserver.ProtocolHandler
+//
+// 请求按 HTTP/2 + Content-Type application/grpc 识别，其余请求仍由gin引擎处理。
+// 明文模式依赖 h2c（Config.EnableH2C），TLS 模式由 ALPN 协商 h2。
+type Handler struct {
+	server *grpc.Server
+
+	mu       sync.Mutex
+	active   int  // 处理中的 gRPC 请求数
+	draining bool // 已开始关闭，拒绝新的请求
+	idle     chan struct{}
+	closed   bool // idle 已关闭
+}
+
+var _ httpserver.ProtocolHandler = (*Handler)(nil)
+
+// New 创建 gRPC 协议处理器
+func New(server *grpc.Server) *Handler {
+	return &Handler{server: server, idle: make(chan struct{})}
+}
+
+// Attach 将 grpc.Server 挂载到HTTP服务器，与gin引擎共用端口
+//
+// 关闭服务器时先停止 gRPC（等待处理中的调用完成，最长到关闭超时），再排空HTTP连接。
+//
+// 示例:
+//
+//	config := httpserver.DefaultConfig()
+//	config.EnableH2C = true // 明文模式需要开启；RunTLS 不需要
+//
+//	server := httpserver.NewServer(config)
+//	grpcServer := grpc.NewServer()
+//	pb.RegisterOrderServiceServer(grpcServer, orderService)
+//	grpcmux.Attach(server, grpcServer)
+//
+//	server.RunWithGracefulShutdown()
+func Attach(s *httpserver.Server, server *grpc.Server) *Handler {
+	h := New(server)
+	s.Attach(h)
+	return h
+}
+
+// Match 判断是否为 gRPC 请求
+func (h *Handler) Match(r *http.Request) bool {
+	return r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
+
+// ServeHTTP 处理 gRPC 请求，关闭期间返回 Unavailable
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.begin() {
+		// Trailers-Only 响应，客户端可以重试到其他实例
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", strconv.Itoa(int(codes.Unavailable)))
+		w.Header().Set("Grpc-Message", "server is shutting down")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	defer h.end()
+
+	h.server.ServeHTTP(w, r)
+}
+
+// Shutdown 拒绝新的 gRPC 请求并等待处理中的请求完成
+// 全部完成后调用 GracefulStop；ctx 先结束时调用 Stop 强制中断并返回错误
+func (h *Handler) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.draining = true
+	h.closeIdleLocked()
+	h.mu.Unlock()
+
+	select {
+	case <-h.idle:
+		// 此时没有处理中的流，GracefulStop 会立即返回
+		h.server.GracefulStop()
+		return nil
+	case <-ctx.Done():
+		h.server.Stop()
+		return fmt.Errorf("gRPC 请求未在关闭超时内完成: %w", ctx.Err())
+	}
+}
+
+func (h *Handler) begin() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.draining {
+		return false
+	}
+	h.active++
+	return true
+}
+
+func (h *Handler) end() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.active--
+	h.closeIdleLocked()
+}
+
+// closeIdleLocked 关闭期间没有处理中的请求时通知 Shutdown，调用方需持有 mu
+func (h *Handler) closeIdleLocked() {
+	if h.draining && h.active == 0 && !h.closed {
+		close(h.idle)
+		h.closed = true
+	}
+}
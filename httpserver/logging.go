@@ -0,0 +1,177 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// accessLogMessage 访问日志消息
+const accessLogMessage = "HTTP请求"
+
+// Logger 访问日志接口，go-kit logger.Logger 满足该接口
+type Logger interface {
+	Info(msg string, fields ...interface{})
+	Warn(msg string, fields ...interface{})
+	Error(msg string, fields ...interface{})
+}
+
+// LoggingConfig 访问日志中间件配置
+type LoggingConfig struct {
+	// Logger 日志记录器，为空时输出到标准输出
+	Logger Logger
+	// SlowThreshold 耗时达到该值的请求以 Warn 级别记录并标记 slow=true，0 表示不检测慢请求
+	SlowThreshold time.Duration
+	// SampleRate 快速的2xx请求每 SampleRate 条记录 1 条，<=1 时全部记录
+	// 非2xx和慢请求始终记录
+	SampleRate int
+	// SkipPaths 不记录的路径，例如健康检查
+	SkipPaths []string
+}
+
+// LoggingMiddleware 结构化访问日志中间件
+//
+// 每个请求输出一条日志（EnableDebugEndpoints 挂载的调试端点除外），字段包括 method、path、route、status、latency、client_ip、
+// bytes_out、trace_id、request_id。级别规则:
+//   - 5xx: Error
+//   - 4xx/3xx 或耗时超过 SlowThreshold: Warn（慢请求附带 slow=true）
+//   - 其余: Info，按 SampleRate 采样，采样记录附带 sample_rate 字段便于换算
+//
+// 示例:
+//
+//	server.Use(httpserver.LoggingMiddleware(httpserver.LoggingConfig{
+//	    Logger:        log,
+//	    SlowThreshold: 500 * time.Millisecond,
+//	    SampleRate:    100, // 正常的2xx请求只记录 1%
+//	    SkipPaths:     []string{"/health"},
+//	}))
+func LoggingMiddleware(cfg LoggingConfig) gin.HandlerFunc {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = stdoutLogger{}
+	}
+	skip := make(map[string]bool, len(cfg.SkipPaths))
+	for _, path := range cfg.SkipPaths {
+		skip[path] = true
+	}
+	var counter atomic.Uint64
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+
+		c.Next()
+
+		if skip[path] || IsDebugEndpoint(c) {
+			return
+		}
+
+		latency := time.Since(start)
+		status := c.Writer.Status()
+		slow := cfg.SlowThreshold > 0 && latency >= cfg.SlowThreshold
+		success := status >= 200 && status < 300
+
+		var sampleRate int
+		if success && !slow && cfg.SampleRate > 1 {
+			// 第 1、N+1、2N+1... 个请求被记录
+			if (counter.Add(1)-1)%uint64(cfg.SampleRate) != 0 {
+				return
+			}
+			sampleRate = cfg.SampleRate
+		}
+
+		fields := []interface{}{
+			"method", c.Request.Method,
+			"path", path,
+			"route", c.FullPath(),
+			"status", status,
+			"latency", latency,
+			"client_ip", c.ClientIP(),
+			"bytes_out", c.Writer.Size(),
+			"trace_id", GetTraceID(c),
+			"request_id", GetRequestID(c),
+		}
+		if slow {
+			fields = append(fields, "slow", true)
+		}
+		if sampleRate > 0 {
+			fields = append(fields, "sample_rate", sampleRate)
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, "errors", c.Errors.String())
+		}
+
+		switch {
+		case status >= 500:
+			logger.Error(accessLogMessage, fields...)
+		case !success || slow:
+			logger.Warn(accessLogMessage, fields...)
+		default:
+			logger.Info(accessLogMessage, fields...)
+		}
+	}
+}
+
+// RequestLoggerKey BufferedLoggingMiddleware 在 gin.Context 中保存请求级日志缓冲的键
+const RequestLoggerKey = "request_logger"
+
+// RequestLogBuffer 请求级缓冲日志，logger.BufferedRequestLogger 满足该接口
+type RequestLogBuffer interface {
+	// Finish 请求结束时调用，状态码 >= 500 时输出缓冲的日志，否则丢弃
+	Finish(status int)
+}
+
+// BufferedLoggingMiddleware 为每个请求创建缓冲日志，请求结束后按响应状态码输出或丢弃
+//
+// newBuffer 为每个请求创建缓冲日志，返回值保存在 gin.Context 的 RequestLoggerKey 中；
+// handler panic 时按 500 处理后继续向外抛出，由外层的恢复中间件处理。
+//
+// 示例:
+//
+//	server.Use(httpserver.BufferedLoggingMiddleware(func(c *gin.Context) httpserver.RequestLogBuffer {
+//	    return logger.NewBufferedRequestLogger(logger.FromContext(c.Request.Context()),
+//	        logger.BufferConfig{Level: logger.DebugLevel})
+//	}))
+//
+//	func handler(c *gin.Context) {
+//	    log := c.MustGet(httpserver.RequestLoggerKey).(*logger.BufferedRequestLogger)
+//	    log.Debug("解析参数", "query", c.Request.URL.RawQuery)
+//	}
+func BufferedLoggingMiddleware(newBuffer func(c *gin.Context) RequestLogBuffer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		buffer := newBuffer(c)
+		c.Set(RequestLoggerKey, buffer)
+
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				buffer.Finish(http.StatusInternalServerError)
+				panic(recovered)
+			}
+		}()
+
+		c.Next()
+		buffer.Finish(c.Writer.Status())
+	}
+}
+
+// stdoutLogger 未配置 Logger 时使用，以 key=value 形式输出到标准输出
+type stdoutLogger struct{}
+
+func (stdoutLogger) Info(msg string, fields ...interface{})  { printAccessLog("INFO", msg, fields) }
+func (stdoutLogger) Warn(msg string, fields ...interface{})  { printAccessLog("WARN", msg, fields) }
+func (stdoutLogger) Error(msg string, fields ...interface{}) { printAccessLog("ERROR", msg, fields) }
+
+func printAccessLog(level, msg string, fields []interface{}) {
+	var b strings.Builder
+	b.WriteString(level)
+	b.WriteByte(' ')
+	b.WriteString(msg)
+	for i := 0; i+1 < len(fields); i += 2 {
+		fmt.Fprintf(&b, " %v=%v", fields[i], fields[i+1])
+	}
+	fmt.Println(b.String())
+}
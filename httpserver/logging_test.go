@@ -0,0 +1,186 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tsopia/go-kit/logger"
+)
+
+type accessEntry struct {
+	level  string
+	fields map[string]interface{}
+}
+
+// recordingLogger 记录访问日志
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []accessEntry
+}
+
+func (l *recordingLogger) record(level string, fields []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	m := make(map[string]interface{}, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		m[fields[i].(string)] = fields[i+1]
+	}
+	l.entries = append(l.entries, accessEntry{level: level, fields: m})
+}
+
+func (l *recordingLogger) Info(msg string, fields ...interface{})  { l.record("info", fields) }
+func (l *recordingLogger) Warn(msg string, fields ...interface{})  { l.record("warn", fields) }
+func (l *recordingLogger) Error(msg string, fields ...interface{}) { l.record("error", fields) }
+
+func newLoggingTestServer(cfg LoggingConfig) *Server {
+	server := NewServer(nil)
+	server.Use(TraceIDMiddleware())
+	server.Use(LoggingMiddleware(cfg))
+	server.GET("/ok", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	server.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+	server.GET("/fail", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+	server.GET("/slow", func(c *gin.Context) {
+		time.Sleep(30 * time.Millisecond)
+		c.String(http.StatusOK, "slow")
+	})
+	server.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return server
+}
+
+func get(server *Server, path string) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	server.Engine().ServeHTTP(w, req)
+}
+
+func TestLoggingMiddlewareSampling(t *testing.T) {
+	logger := &recordingLogger{}
+	server := newLoggingTestServer(LoggingConfig{Logger: logger, SampleRate: 10})
+
+	for i := 0; i < 25; i++ {
+		get(server, "/ok")
+	}
+	if len(logger.entries) != 3 {
+		t.Fatalf("Expected 3 sampled entries out of 25, got %d", len(logger.entries))
+	}
+	entry := logger.entries[0]
+	if entry.level != "info" || entry.fields["sample_rate"] != 10 || entry.fields["status"] != http.StatusOK {
+		t.Errorf("Unexpected sampled entry: %+v", entry)
+	}
+	if entry.fields["trace_id"] == "" || entry.fields["route"] != "/ok" {
+		t.Errorf("Expected trace_id and route fields, got %+v", entry.fields)
+	}
+
+	// 非2xx始终记录
+	logger.entries = nil
+	for i := 0; i < 5; i++ {
+		get(server, "/users/42")
+	}
+	get(server, "/fail")
+	if len(logger.entries) != 6 {
+		t.Fatalf("Expected all non-2xx requests to be logged, got %d", len(logger.entries))
+	}
+	if e := logger.entries[0]; e.level != "warn" || e.fields["route"] != "/users/:id" || e.fields["path"] != "/users/42" {
+		t.Errorf("Unexpected 4xx entry: %+v", e)
+	}
+	if e := logger.entries[5]; e.level != "error" {
+		t.Errorf("Expected 5xx to be logged at error, got %+v", e)
+	}
+	if _, ok := logger.entries[0].fields["sample_rate"]; ok {
+		t.Error("Unsampled entries should not carry sample_rate")
+	}
+}
+
+func TestLoggingMiddlewareSlowRequests(t *testing.T) {
+	logger := &recordingLogger{}
+	server := newLoggingTestServer(LoggingConfig{
+		Logger:        logger,
+		SlowThreshold: 20 * time.Millisecond,
+		SampleRate:    1000,
+	})
+
+	get(server, "/ok") // 第一个请求被采样
+	for i := 0; i < 3; i++ {
+		get(server, "/slow")
+	}
+
+	var slow int
+	for _, e := range logger.entries {
+		if e.fields["slow"] == true {
+			slow++
+			if e.level != "warn" {
+				t.Errorf("Expected slow request at warn, got %s", e.level)
+			}
+			if e.fields["latency"].(time.Duration) < 20*time.Millisecond {
+				t.Errorf("Unexpected latency %v", e.fields["latency"])
+			}
+		}
+	}
+	if slow != 3 {
+		t.Errorf("Expected every slow request to be logged, got %d", slow)
+	}
+}
+
+func TestLoggingMiddlewareSkipPaths(t *testing.T) {
+	logger := &recordingLogger{}
+	server := newLoggingTestServer(LoggingConfig{Logger: logger, SkipPaths: []string{"/health"}})
+
+	get(server, "/health")
+	get(server, "/ok")
+	if len(logger.entries) != 1 || logger.entries[0].fields["path"] != "/ok" {
+		t.Errorf("Expected only /ok to be logged, got %+v", logger.entries)
+	}
+}
+
+// logger.BufferedRequestLogger 满足 RequestLogBuffer
+var _ RequestLogBuffer = (*logger.BufferedRequestLogger)(nil)
+
+// fakeLogBuffer 记录 Finish 的状态码
+type fakeLogBuffer struct {
+	mu     sync.Mutex
+	status []int
+}
+
+func (f *fakeLogBuffer) Finish(status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.status = append(f.status, status)
+}
+
+func TestBufferedLoggingMiddleware(t *testing.T) {
+	buffers := &fakeLogBuffer{}
+	server := NewServer(nil)
+	server.Use(func(c *gin.Context) {
+		defer func() {
+			if recover() != nil {
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}
+		}()
+		c.Next()
+	})
+	server.Use(BufferedLoggingMiddleware(func(c *gin.Context) RequestLogBuffer { return buffers }))
+	server.GET("/ok", func(c *gin.Context) {
+		if c.MustGet(RequestLoggerKey) != buffers {
+			t.Error("Expected buffer to be stored in gin.Context")
+		}
+		c.Status(http.StatusNoContent)
+	})
+	server.GET("/fail", func(c *gin.Context) { c.Status(http.StatusBadGateway) })
+	server.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	for _, path := range []string{"/ok", "/fail", "/panic"} {
+		w := httptest.NewRecorder()
+		server.Engine().ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	want := []int{http.StatusNoContent, http.StatusBadGateway, http.StatusInternalServerError}
+	if fmt.Sprint(buffers.status) != fmt.Sprint(want) {
+		t.Errorf("Expected Finish statuses %v, got %v", want, buffers.status)
+	}
+}
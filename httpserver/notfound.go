@@ -0,0 +1,147 @@
+package httpserver
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/tsopia/go-kit/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSuggestionDistance 404建议的最大编辑距离，超出时不给出建议
+const maxSuggestionDistance = 3
+
+// SetNotFoundHandler 替换未匹配任何路由时的处理函数，StaticSPA 未处理的请求同样交给它
+//
+// 默认返回统一错误结构的JSON 404，包含 path 字段；Config.SuggestRoutes 为 true 时还包含 suggestion。
+// 传入nil恢复默认处理函数。
+func (s *Server) SetNotFoundHandler(handler gin.HandlerFunc) {
+	if handler == nil {
+		handler = s.defaultNotFound
+	}
+	s.notFound = handler
+}
+
+// SetMethodNotAllowedHandler 替换路径存在但方法不匹配时的处理函数
+//
+// 调用处理函数前已设置 Allow 响应头。默认返回统一错误结构的JSON 405，包含 path 和 allowed 字段。
+// 传入nil恢复默认处理函数。
+func (s *Server) SetMethodNotAllowedHandler(handler gin.HandlerFunc) {
+	if handler == nil {
+		handler = defaultMethodNotAllowed
+	}
+	s.methodNotAllowed = handler
+}
+
+// handleNoRoute 先尝试单页应用，未处理时调用404处理函数
+func (s *Server) handleNoRoute(c *gin.Context) {
+	if len(s.spaMounts) > 0 && s.serveSPA(c) {
+		return
+	}
+	s.notFound(c)
+}
+
+// handleNoMethod 设置 Allow 响应头后调用405处理函数
+func (s *Server) handleNoMethod(c *gin.Context) {
+	if allowed := s.allowedMethods(c.Request.URL.Path); len(allowed) > 0 {
+		c.Header("Allow", strings.Join(allowed, ", "))
+	}
+	s.methodNotAllowed(c)
+}
+
+// defaultNotFound 默认的JSON 404
+func (s *Server) defaultNotFound(c *gin.Context) {
+	body := errorBody(c, errors.CodeNotFound, "请求的路由不存在")
+	body["path"] = c.Request.URL.Path
+	if s.config.SuggestRoutes {
+		if suggestion := s.suggestRoute(c.Request.URL.Path); suggestion != "" {
+			body["suggestion"] = suggestion
+		}
+	}
+	c.AbortWithStatusJSON(http.StatusNotFound, body)
+}
+
+// defaultMethodNotAllowed 默认的JSON 405
+func defaultMethodNotAllowed(c *gin.Context) {
+	body := errorBody(c, errors.CodeInvalidParam, "不支持的请求方法 "+c.Request.Method)
+	body["path"] = c.Request.URL.Path
+	if allow := c.Writer.Header().Get("Allow"); allow != "" {
+		body["allowed"] = strings.Split(allow, ", ")
+	}
+	c.AbortWithStatusJSON(http.StatusMethodNotAllowed, body)
+}
+
+// allowedMethods 返回路由模板与路径匹配的方法，已排序
+func (s *Server) allowedMethods(path string) []string {
+	seen := make(map[string]bool)
+	var methods []string
+	for _, route := range s.engine.Routes() {
+		if !seen[route.Method] && matchRoute(route.Path, path) {
+			seen[route.Method] = true
+			methods = append(methods, route.Method)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// matchRoute 判断路径是否匹配gin路由模板，:name 匹配一段，*name 匹配剩余部分
+func matchRoute(template, path string) bool {
+	tmpl := strings.Split(strings.Trim(template, "/"), "/")
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range tmpl {
+		if strings.HasPrefix(part, "*") {
+			return true
+		}
+		if i >= len(segs) {
+			return false
+		}
+		if strings.HasPrefix(part, ":") {
+			if segs[i] == "" {
+				return false
+			}
+			continue
+		}
+		if part != segs[i] {
+			return false
+		}
+	}
+	return len(tmpl) == len(segs)
+}
+
+// suggestRoute 返回与路径编辑距离最小的路由模板，距离超过 maxSuggestionDistance 时返回空
+func (s *Server) suggestRoute(path string) string {
+	best, bestDistance := "", maxSuggestionDistance+1
+	for _, route := range s.engine.Routes() {
+		if d := editDistance(path, route.Path); d < bestDistance || (d == bestDistance && route.Path < best) {
+			best, bestDistance = route.Path, d
+		}
+	}
+	if bestDistance > maxSuggestionDistance {
+		return ""
+	}
+	return best
+}
+
+// editDistance 计算两个字符串的 Levenshtein 距离
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,143 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tsopia/go-kit/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func serveJSON(t *testing.T, server *Server, method, path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, nil)
+	server.Engine().ServeHTTP(w, req)
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Expected JSON response, got %q", w.Body.String())
+	}
+	return w, body
+}
+
+func newRoutedServer(config *Config) *Server {
+	server := NewServer(config)
+	server.Use(TraceIDMiddleware())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	server.GET("/api/users", ok)
+	server.POST("/api/users", ok)
+	server.GET("/api/users/:id", ok)
+	server.DELETE("/api/users/:id", ok)
+	server.GET("/files/*path", ok)
+	return server
+}
+
+func TestDefaultNotFound(t *testing.T) {
+	server := newRoutedServer(nil)
+
+	w, body := serveJSON(t, server, "GET", "/api/orders")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Expected 404, got %d", w.Code)
+	}
+	if body["code"] != float64(errors.CodeNotFound.Code) || body["path"] != "/api/orders" {
+		t.Errorf("Unexpected body: %v", body)
+	}
+	if body["trace_id"] == "" || body["trace_id"] != w.Header().Get("X-Trace-ID") {
+		t.Errorf("Expected trace_id in body, got %v", body["trace_id"])
+	}
+	if _, ok := body["suggestion"]; ok {
+		t.Error("Expected no suggestion when SuggestRoutes is disabled")
+	}
+}
+
+func TestNotFoundSuggestion(t *testing.T) {
+	config := DefaultConfig()
+	config.SuggestRoutes = true
+	server := newRoutedServer(config)
+
+	_, body := serveJSON(t, server, "GET", "/api/user")
+	if body["suggestion"] != "/api/users" {
+		t.Errorf("Expected suggestion /api/users, got %v", body["suggestion"])
+	}
+
+	_, body = serveJSON(t, server, "GET", "/completely/different")
+	if _, ok := body["suggestion"]; ok {
+		t.Errorf("Expected no suggestion for distant path, got %v", body["suggestion"])
+	}
+}
+
+func TestDefaultMethodNotAllowed(t *testing.T) {
+	server := newRoutedServer(nil)
+
+	tests := []struct {
+		method string
+		path   string
+		allow  string
+	}{
+		{"DELETE", "/api/users", "GET, POST"},
+		{"PUT", "/api/users/42", "DELETE, GET"},
+		{"POST", "/files/a/b.txt", "GET"},
+	}
+	for _, tt := range tests {
+		w, body := serveJSON(t, server, tt.method, tt.path)
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s: expected 405, got %d", tt.method, tt.path, w.Code)
+			continue
+		}
+		if got := w.Header().Get("Allow"); got != tt.allow {
+			t.Errorf("%s %s: expected Allow %q, got %q", tt.method, tt.path, tt.allow, got)
+		}
+		if body["path"] != tt.path || body["trace_id"] == "" {
+			t.Errorf("%s %s: unexpected body %v", tt.method, tt.path, body)
+		}
+		if allowed, _ := body["allowed"].([]interface{}); len(allowed) == 0 {
+			t.Errorf("%s %s: expected allowed methods in body, got %v", tt.method, tt.path, body)
+		}
+	}
+}
+
+func TestCustomNotFoundHandlers(t *testing.T) {
+	server := newRoutedServer(nil)
+	server.SetNotFoundHandler(func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"custom": "404"})
+	})
+	server.SetMethodNotAllowedHandler(func(c *gin.Context) {
+		c.JSON(http.StatusMethodNotAllowed, gin.H{"custom": c.Writer.Header().Get("Allow")})
+	})
+
+	if _, body := serveJSON(t, server, "GET", "/missing"); body["custom"] != "404" {
+		t.Errorf("Expected custom 404, got %v", body)
+	}
+	if _, body := serveJSON(t, server, "PATCH", "/api/users"); body["custom"] != "GET, POST" {
+		t.Errorf("Expected custom 405 with Allow header set, got %v", body)
+	}
+
+	server.SetNotFoundHandler(nil)
+	if _, body := serveJSON(t, server, "GET", "/missing"); body["path"] != "/missing" {
+		t.Errorf("Expected default 404 restored, got %v", body)
+	}
+}
+
+func TestMatchRoute(t *testing.T) {
+	tests := []struct {
+		template, path string
+		want           bool
+	}{
+		{"/api/users", "/api/users", true},
+		{"/api/users", "/api/users/", true},
+		{"/api/users/:id", "/api/users/1", true},
+		{"/api/users/:id", "/api/users", false},
+		{"/api/users/:id", "/api/users/1/posts", false},
+		{"/files/*path", "/files/a/b", true},
+		{"/", "/", true},
+	}
+	for _, tt := range tests {
+		if got := matchRoute(tt.template, tt.path); got != tt.want {
+			t.Errorf("matchRoute(%q, %q) = %v, want %v", tt.template, tt.path, got, tt.want)
+		}
+	}
+}
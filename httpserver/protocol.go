@@ -0,0 +1,85 @@
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// ProtocolHandler 与gin引擎共用同一端口的其他协议处理器，例如 gRPC（见 httpserver/grpcmux）
+//
+// 每个请求依次询问已挂载的处理器，第一个 Match 返回 true 的处理器接管请求，
+// 都不匹配时交给gin引擎。
+type ProtocolHandler interface {
+	http.Handler
+	// Match 判断请求是否由该处理器处理
+	Match(r *http.Request) bool
+	// Shutdown 在排空HTTP连接之前调用，应在 ctx 结束前停止处理中的请求
+	Shutdown(ctx context.Context) error
+}
+
+// Attach 挂载与gin引擎共用端口的协议处理器，需要在启动服务器之前调用
+//
+// 明文模式下 gRPC 等基于HTTP/2的协议需要同时开启 Config.EnableH2C；
+// RunTLS 通过 ALPN 协商 h2 或 http/1.1，无需额外配置。
+func (s *Server) Attach(h ProtocolHandler) {
+	s.protocols = append(s.protocols, h)
+}
+
+// protocolMux 按 ProtocolHandler.Match 分发请求
+type protocolMux struct {
+	protocols []ProtocolHandler
+	fallback  http.Handler
+}
+
+func (m protocolMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, p := range m.protocols {
+		if p.Match(r) {
+			p.ServeHTTP(w, r)
+			return
+		}
+	}
+	m.fallback.ServeHTTP(w, r)
+}
+
+// newHTTPServer 根据配置创建 http.Server
+func (s *Server) newHTTPServer() (*http.Server, error) {
+	server := &http.Server{
+		Addr:           fmt.Sprintf("%s:%d", s.config.Host, s.config.Port),
+		ReadTimeout:    s.config.ReadTimeout,
+		WriteTimeout:   s.config.WriteTimeout,
+		IdleTimeout:    s.config.IdleTimeout,
+		MaxHeaderBytes: s.config.MaxHeaderBytes,
+	}
+
+	var handler http.Handler = s.engine
+	if len(s.protocols) > 0 {
+		handler = protocolMux{protocols: s.protocols, fallback: s.engine}
+	}
+
+	if s.config.EnableH2C {
+		h2s := &http2.Server{IdleTimeout: s.config.IdleTimeout}
+		// 注册到 http.Server，Shutdown 时向HTTP/2连接发送 GOAWAY
+		if err := http2.ConfigureServer(server, h2s); err != nil {
+			return nil, fmt.Errorf("配置HTTP/2失败: %w", err)
+		}
+		handler = h2c.NewHandler(handler, h2s)
+	}
+
+	server.Handler = handler
+	return server, nil
+}
+
+// shutdownProtocols 依次关闭已挂载的协议处理器，返回第一个错误
+func (s *Server) shutdownProtocols(ctx context.Context) error {
+	var firstErr error
+	for _, p := range s.protocols {
+		if err := p.Shutdown(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
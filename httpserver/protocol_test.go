@@ -0,0 +1,147 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/http2"
+)
+
+// fakeProtocol 按请求头匹配的协议处理器，记录请求的协议版本和是否已关闭
+type fakeProtocol struct {
+	mu       sync.Mutex
+	proto    []int
+	shutdown bool
+	err      error
+}
+
+func (p *fakeProtocol) Match(r *http.Request) bool {
+	return r.Header.Get("X-Protocol") == "fake"
+}
+
+func (p *fakeProtocol) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	p.proto = append(p.proto, r.ProtoMajor)
+	p.mu.Unlock()
+	io.WriteString(w, "fake")
+}
+
+func (p *fakeProtocol) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.shutdown = true
+	return p.err
+}
+
+func startProtocolTestServer(t *testing.T, config *Config, p ProtocolHandler) (*Server, string) {
+	t.Helper()
+
+	config.Host = "127.0.0.1"
+	config.Port = 0
+	server := NewServer(config)
+	server.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+	server.Attach(p)
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+
+	return server, fmt.Sprintf("127.0.0.1:%d", server.Port())
+}
+
+func fetchProtocolTest(t *testing.T, client *http.Client, url string, header http.Header) (string, int) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body), resp.ProtoMajor
+}
+
+func TestAttachDispatchesMatchingRequests(t *testing.T) {
+	p := &fakeProtocol{}
+	server, addr := startProtocolTestServer(t, DefaultConfig(), p)
+
+	body, _ := fetchProtocolTest(t, http.DefaultClient, "http://"+addr+"/ping", nil)
+	if body != "pong" {
+		t.Errorf("Expected gin to handle unmatched request, got %q", body)
+	}
+	body, _ = fetchProtocolTest(t, http.DefaultClient, "http://"+addr+"/ping", http.Header{"X-Protocol": {"fake"}})
+	if body != "fake" {
+		t.Errorf("Expected protocol handler to handle matched request, got %q", body)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if !p.shutdown {
+		t.Error("Expected protocol handler to be shut down")
+	}
+}
+
+func TestEnableH2C(t *testing.T) {
+	config := DefaultConfig()
+	config.EnableH2C = true
+	p := &fakeProtocol{}
+	server, addr := startProtocolTestServer(t, config, p)
+	defer server.Shutdown(context.Background())
+
+	// 明文HTTP/2（prior knowledge）
+	h2cClient := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+
+	body, proto := fetchProtocolTest(t, h2cClient, "http://"+addr+"/ping", http.Header{"X-Protocol": {"fake"}})
+	if body != "fake" || proto != 2 {
+		t.Errorf("Expected HTTP/2 request to reach protocol handler, got %q over HTTP/%d", body, proto)
+	}
+	body, proto = fetchProtocolTest(t, h2cClient, "http://"+addr+"/ping", nil)
+	if body != "pong" || proto != 2 {
+		t.Errorf("Expected HTTP/2 request to reach gin, got %q over HTTP/%d", body, proto)
+	}
+
+	// HTTP/1.1 仍然可用
+	body, proto = fetchProtocolTest(t, http.DefaultClient, "http://"+addr+"/ping", nil)
+	if body != "pong" || proto != 1 {
+		t.Errorf("Expected HTTP/1.1 request to reach gin, got %q over HTTP/%d", body, proto)
+	}
+}
+
+func TestShutdownReturnsProtocolError(t *testing.T) {
+	p := &fakeProtocol{err: fmt.Errorf("grpc still busy")}
+	server, addr := startProtocolTestServer(t, DefaultConfig(), p)
+
+	if body, _ := fetchProtocolTest(t, http.DefaultClient, "http://"+addr+"/ping", nil); body != "pong" {
+		t.Fatalf("Expected pong, got %q", body)
+	}
+
+	err := server.Shutdown(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "grpc still busy") {
+		t.Errorf("Expected protocol shutdown error, got %v", err)
+	}
+	// HTTP 仍然被排空
+	if _, err := http.Get("http://" + addr + "/ping"); err == nil {
+		t.Error("Expected HTTP server to be closed")
+	}
+}
@@ -0,0 +1,268 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultReadinessPath EnableReadinessEndpoint 默认挂载的路径
+const DefaultReadinessPath = "/health/ready"
+
+// DefaultLivenessPath EnableLivenessEndpoint 默认挂载的路径
+const DefaultLivenessPath = "/health/live"
+
+const (
+	// reasonGatePending 就绪门注册后尚未调用 MarkReady 时的原因
+	reasonGatePending = "pending"
+	// reasonShuttingDown 服务器开始关闭后所有就绪门的原因
+	reasonShuttingDown = "shutting down"
+	// reasonWorkerStarting 阻塞就绪的工作协程尚未完成初始化
+	reasonWorkerStarting = "worker starting"
+	// reasonWorkerRestarting 阻塞就绪的工作协程 panic 后等待重启
+	reasonWorkerRestarting = "worker restarting"
+)
+
+// Gate 就绪门，所有就绪门都处于就绪状态时服务器才报告就绪
+//
+// 就绪门注册后默认未就绪，依赖的资源准备好后调用 MarkReady，依赖出现故障时调用 MarkUnready。
+// 服务器开始关闭后所有就绪门变为未就绪，之后的 MarkReady 不再生效。
+type Gate struct {
+	name      string
+	readiness *readiness
+
+	// 以下字段由 readiness.mu 保护
+	ready  bool
+	reason string
+}
+
+// GateStatus 就绪门的状态
+type GateStatus struct {
+	Name   string `json:"name"`
+	Ready  bool   `json:"ready"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ReadinessStatus 服务器的就绪状态
+type ReadinessStatus struct {
+	Ready        bool         `json:"ready"`
+	Draining     bool         `json:"draining,omitempty"`
+	ShuttingDown bool         `json:"shutting_down,omitempty"`
+	Pending      []GateStatus `json:"pending,omitempty"` // 未就绪的就绪门，按注册顺序排列
+}
+
+// readiness 服务器的就绪门
+type readiness struct {
+	mu           sync.Mutex
+	gates        []*Gate
+	shuttingDown bool
+	drainStarted time.Time // BeginDrain 的调用时间，零值表示未开始排空
+}
+
+// ReadinessGate 注册（或返回已注册的同名）就绪门
+//
+// 示例:
+//
+//	cacheGate := server.ReadinessGate("cache")
+//	go func() {
+//	    cache.Prime(ctx)
+//	    cacheGate.MarkReady()
+//	}()
+//	server.EnableReadinessEndpoint("") // GET /health/ready 在缓存预热完成前返回 503
+func (s *Server) ReadinessGate(name string) *Gate {
+	r := &s.readiness
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, gate := range r.gates {
+		if gate.name == name {
+			return gate
+		}
+	}
+	gate := &Gate{name: name, readiness: r, reason: reasonGatePending}
+	if r.shuttingDown {
+		gate.reason = reasonShuttingDown
+	}
+	r.gates = append(r.gates, gate)
+	return gate
+}
+
+// Name 返回就绪门的名称
+func (g *Gate) Name() string {
+	return g.name
+}
+
+// MarkReady 标记为就绪，服务器开始关闭后调用无效
+func (g *Gate) MarkReady() {
+	g.readiness.mu.Lock()
+	defer g.readiness.mu.Unlock()
+	if g.readiness.shuttingDown {
+		return
+	}
+	g.ready = true
+	g.reason = ""
+}
+
+// MarkUnready 标记为未就绪，reason 出现在就绪端点的响应中
+func (g *Gate) MarkUnready(reason string) {
+	g.readiness.mu.Lock()
+	defer g.readiness.mu.Unlock()
+	if g.readiness.shuttingDown {
+		return
+	}
+	g.ready = false
+	g.reason = reason
+}
+
+// Ready 判断是否就绪
+func (g *Gate) Ready() bool {
+	g.readiness.mu.Lock()
+	defer g.readiness.mu.Unlock()
+	return g.ready
+}
+
+// Readiness 返回服务器的就绪状态：没有开始排空或关闭，且所有就绪门都已就绪（没有注册就绪门时同样就绪）
+func (s *Server) Readiness() ReadinessStatus {
+	r := &s.readiness
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := ReadinessStatus{Draining: !r.drainStarted.IsZero(), ShuttingDown: r.shuttingDown}
+	for _, gate := range r.gates {
+		if !gate.ready {
+			status.Pending = append(status.Pending, GateStatus{Name: gate.name, Reason: gate.reason})
+		}
+	}
+	status.Ready = !status.Draining && !r.shuttingDown && len(status.Pending) == 0
+	return status
+}
+
+// BeginDrain 进入排空状态：就绪端点开始返回 503，负载均衡器停止发送新流量，
+// 服务器继续接受和处理请求，直到调用 Shutdown；重复调用无效，排空开始后不能撤销
+//
+// 滚动发布的正确顺序是"就绪失败 → 等待负载均衡器摘除 → 关闭"。Shutdown 本身也会先报告未就绪并等待
+// Config.ReadinessDrainDelay；提前调用 BeginDrain 时，Shutdown 只等待剩余的时间。
+//
+// 示例:
+//
+//	// preStop 钩子
+//	server.BeginDrain()
+//	time.Sleep(15 * time.Second) // 等待负载均衡器摘除实例，期间已接受的请求正常处理
+//	server.Shutdown(ctx)
+func (s *Server) BeginDrain() {
+	r := &s.readiness
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.drainStarted.IsZero() {
+		r.drainStarted = time.Now()
+	}
+}
+
+// Draining 判断服务器是否已进入排空状态
+func (s *Server) Draining() bool {
+	r := &s.readiness
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.drainStarted.IsZero()
+}
+
+// beginShutdown 把所有就绪门标记为未就绪，之后的 MarkReady 和 MarkUnready 不再生效，
+// 返回 BeginDrain 的调用时间（未排空时为关闭开始的时间）
+func (r *readiness) beginShutdown() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.drainStarted.IsZero() {
+		r.drainStarted = time.Now()
+	}
+	r.shuttingDown = true
+	for _, gate := range r.gates {
+		gate.ready = false
+		gate.reason = reasonShuttingDown
+	}
+	return r.drainStarted
+}
+
+// EnableReadinessEndpoint 在 path（为空时为 DefaultReadinessPath）挂载就绪检查端点
+//
+// 就绪时返回 200 和 {"ready": true}；存在未就绪的就绪门或服务器正在关闭时返回 503，
+// 响应中列出未就绪的就绪门及原因，例如:
+//
+//	{"ready": false, "pending": [{"name": "cache", "ready": false, "reason": "pending"}]}
+func (s *Server) EnableReadinessEndpoint(path string) {
+	if path == "" {
+		path = DefaultReadinessPath
+	}
+	s.engine.GET(path, s.readinessHandler)
+}
+
+// EnableLivenessEndpoint 在 path（为空时为 DefaultLivenessPath）挂载存活检查端点，
+// 只要进程能处理请求就返回 200 和 {"alive": true}，不受就绪门、排空和关闭影响
+//
+// 存活检查失败会导致容器被重启，因此不应依赖数据库等外部资源，外部依赖使用就绪门表达。
+func (s *Server) EnableLivenessEndpoint(path string) {
+	if path == "" {
+		path = DefaultLivenessPath
+	}
+	s.engine.GET(path, func(c *gin.Context) {
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusOK, gin.H{"alive": true})
+	})
+}
+
+func (s *Server) readinessHandler(c *gin.Context) {
+	status := s.Readiness()
+	code := http.StatusOK
+	if !status.Ready {
+		code = http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(code, status)
+}
+
+// waitReadinessPropagation 等待从开始报告未就绪（drainStarted）起满 Config.ReadinessDrainDelay，
+// 让负载均衡器发现服务器未就绪后再排空连接
+func (s *Server) waitReadinessPropagation(ctx context.Context, drainStarted time.Time) {
+	if remaining := s.config.ReadinessDrainDelay - time.Since(drainStarted); remaining > 0 {
+		sleepContext(ctx, remaining)
+	}
+}
+
+// workerGateKey 阻塞就绪的工作协程的 ctx 中保存就绪门的键
+type workerGateKey struct{}
+
+// WithReadinessGate 工作协程阻塞服务器就绪：注册名为 "worker:{name}" 的就绪门，
+// 工作协程完成初始化后调用 WorkerReady(ctx) 标记就绪
+//
+// 工作协程在调用 WorkerReady 之前正常返回（一次性的初始化任务）同样视为就绪；
+// 返回错误时就绪门保持未就绪并以错误为原因，panic 后等待重启期间未就绪，重启后需要再次调用 WorkerReady。
+func WithReadinessGate() WorkerOption {
+	return func(w *worker) {
+		w.blocksReadiness = true
+	}
+}
+
+// WorkerReady 标记当前工作协程已完成初始化，ctx 为 WorkerFunc 收到的 ctx；
+// 工作协程没有设置 WithReadinessGate 时无效
+//
+// 示例:
+//
+//	server.AddWorker("order-consumer", func(ctx context.Context) error {
+//	    if err := consumer.Subscribe(ctx); err != nil {
+//	        return err
+//	    }
+//	    httpserver.WorkerReady(ctx)
+//	    return consumer.Run(ctx)
+//	}, httpserver.WithReadinessGate())
+func WorkerReady(ctx context.Context) {
+	if gate, ok := ctx.Value(workerGateKey{}).(*Gate); ok {
+		gate.MarkReady()
+	}
+}
+
+// workerGateName 工作协程就绪门的名称
+func workerGateName(name string) string {
+	return "worker:" + name
+}
@@ -0,0 +1,271 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readinessOf 请求就绪端点，返回状态码和响应
+func readinessOf(t *testing.T, server *Server) (int, ReadinessStatus) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, DefaultReadinessPath, nil)
+	server.Engine().ServeHTTP(w, req)
+
+	var status ReadinessStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("Failed to decode readiness response %q: %v", w.Body.String(), err)
+	}
+	return w.Code, status
+}
+
+func waitReadiness(t *testing.T, server *Server, wantCode int) ReadinessStatus {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		code, status := readinessOf(t, server)
+		if code == wantCode {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected readiness %d, got %d: %+v", wantCode, code, status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestReadinessGates(t *testing.T) {
+	server := NewServer(nil)
+	server.EnableReadinessEndpoint("")
+
+	// 没有就绪门时就绪
+	if code, status := readinessOf(t, server); code != http.StatusOK || !status.Ready {
+		t.Fatalf("Expected ready without gates, got %d %+v", code, status)
+	}
+
+	db := server.ReadinessGate("db")
+	cache := server.ReadinessGate("cache")
+	if server.ReadinessGate("db") != db {
+		t.Error("Expected the same gate for the same name")
+	}
+
+	code, status := readinessOf(t, server)
+	if code != http.StatusServiceUnavailable || status.Ready || len(status.Pending) != 2 {
+		t.Fatalf("Expected 503 with 2 pending gates, got %d %+v", code, status)
+	}
+	if status.Pending[0].Name != "db" || status.Pending[0].Reason != "pending" || status.Pending[1].Name != "cache" {
+		t.Errorf("Unexpected pending gates %+v", status.Pending)
+	}
+
+	db.MarkReady()
+	cache.MarkReady()
+	if code, status := readinessOf(t, server); code != http.StatusOK || !status.Ready || len(status.Pending) != 0 {
+		t.Fatalf("Expected ready after all gates, got %d %+v", code, status)
+	}
+
+	cache.MarkUnready("redis unreachable")
+	code, status = readinessOf(t, server)
+	if code != http.StatusServiceUnavailable || len(status.Pending) != 1 || status.Pending[0].Reason != "redis unreachable" {
+		t.Errorf("Expected cache pending with reason, got %d %+v", code, status)
+	}
+}
+
+func TestReadinessWorkerGate(t *testing.T) {
+	server := newWorkerTestServer(t)
+	server.EnableReadinessEndpoint("")
+
+	initialized := make(chan struct{})
+	server.AddWorker("consumer", func(ctx context.Context) error {
+		<-initialized
+		WorkerReady(ctx)
+		<-ctx.Done()
+		return nil
+	}, WithReadinessGate())
+	server.AddWorker("warmup", func(ctx context.Context) error {
+		return nil // 一次性任务正常返回即就绪
+	}, WithReadinessGate())
+	server.AddWorker("plain", func(ctx context.Context) error {
+		WorkerReady(ctx) // 没有就绪门时无效
+		<-ctx.Done()
+		return nil
+	})
+
+	// 启动前所有阻塞就绪的工作协程都未就绪
+	_, status := readinessOf(t, server)
+	if len(status.Pending) != 2 || status.Pending[0].Name != "worker:consumer" || status.Pending[0].Reason != "worker starting" {
+		t.Fatalf("Expected worker gates pending before start, got %+v", status)
+	}
+
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Shutdown(context.Background())
+
+	status = waitReadinessPending(t, server, "worker:consumer")
+	if status.Ready {
+		t.Fatalf("Expected consumer to block readiness, got %+v", status)
+	}
+	close(initialized)
+	waitReadiness(t, server, http.StatusOK)
+}
+
+// waitReadinessPending 等待只剩 name 一个未就绪的就绪门
+func waitReadinessPending(t *testing.T, server *Server, name string) ReadinessStatus {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		status := server.Readiness()
+		if len(status.Pending) == 1 && status.Pending[0].Name == name {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected only %s pending, got %+v", name, status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestReadinessWorkerFailure(t *testing.T) {
+	server := newWorkerTestServer(t)
+	server.AddWorker("loader", func(ctx context.Context) error {
+		return errors.New("schema missing")
+	}, WithReadinessGate())
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Shutdown(context.Background())
+
+	waitWorkerState(t, server, "loader", WorkerFailed)
+	status := server.Readiness()
+	if status.Ready || len(status.Pending) != 1 || status.Pending[0].Reason != "schema missing" {
+		t.Errorf("Expected failed worker to keep the gate unready, got %+v", status)
+	}
+}
+
+func TestReadinessUnreadyBeforeDrain(t *testing.T) {
+	server := newWorkerTestServer(t)
+	server.config.ReadinessDrainDelay = 200 * time.Millisecond
+	server.EnableReadinessEndpoint("")
+	gate := server.ReadinessGate("db")
+	gate.MarkReady()
+
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	url := "http://" + server.Addr() + DefaultReadinessPath
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected ready before shutdown, got %d", resp.StatusCode)
+	}
+
+	done := make(chan error, 1)
+	start := time.Now()
+	go func() { done <- server.Shutdown(context.Background()) }()
+
+	// 关闭开始后、排空连接之前，服务器仍接受请求但报告未就绪
+	deadline := time.Now().Add(time.Second)
+	for server.Readiness().Ready {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected readiness to flip when shutdown begins")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	resp, err = http.Get(url)
+	if err != nil {
+		t.Fatalf("Expected server to accept connections during the readiness delay: %v", err)
+	}
+	var status ReadinessStatus
+	json.NewDecoder(resp.Body).Decode(&status)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || !status.ShuttingDown || status.Pending[0].Reason != "shutting down" {
+		t.Errorf("Expected 503 shutting down, got %d %+v", resp.StatusCode, status)
+	}
+
+	// 关闭后 MarkReady 不再生效
+	gate.MarkReady()
+	if gate.Ready() {
+		t.Error("Expected MarkReady to be ignored after shutdown began")
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Expected drain to wait for ReadinessDrainDelay, shutdown took %v", elapsed)
+	}
+	if _, err := http.Get(url); err == nil {
+		t.Error("Expected connections to be refused after shutdown")
+	}
+}
+
+func TestBeginDrain(t *testing.T) {
+	server := NewServer(nil)
+	server.EnableReadinessEndpoint("")
+	server.EnableLivenessEndpoint("")
+	server.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+	gate := server.ReadinessGate("db")
+	gate.MarkReady()
+
+	if code, _ := readinessOf(t, server); code != http.StatusOK || server.Draining() {
+		t.Fatalf("Expected ready before draining, got %d", code)
+	}
+
+	server.BeginDrain()
+	code, status := readinessOf(t, server)
+	if code != http.StatusServiceUnavailable || status.Ready || !status.Draining || status.ShuttingDown || len(status.Pending) != 0 {
+		t.Errorf("Expected 503 draining without pending gates, got %d %+v", code, status)
+	}
+	if !server.Draining() {
+		t.Error("Expected Draining to report true")
+	}
+
+	// 排空期间存活检查和业务请求不受影响
+	for _, path := range []string{DefaultLivenessPath, "/orders"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		server.Engine().ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected %s to return 200 while draining, got %d", path, w.Code)
+		}
+	}
+
+	// 排空不能被就绪门撤销
+	gate.MarkUnready("blip")
+	gate.MarkReady()
+	if server.Readiness().Ready {
+		t.Error("Expected readiness to stay failed while draining")
+	}
+}
+
+func TestBeginDrainShortensShutdownDelay(t *testing.T) {
+	server := newWorkerTestServer(t)
+	server.config.ReadinessDrainDelay = 300 * time.Millisecond
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	server.BeginDrain()
+	time.Sleep(200 * time.Millisecond)
+
+	start := time.Now()
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed >= 250*time.Millisecond {
+		t.Errorf("Expected Shutdown to wait only the remaining drain delay, took %v", elapsed)
+	}
+	if status := server.Readiness(); !status.Draining || !status.ShuttingDown {
+		t.Errorf("Expected draining and shutting down after Shutdown, got %+v", status)
+	}
+}
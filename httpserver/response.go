@@ -0,0 +1,247 @@
+package httpserver
+
+import (
+	stderrors "errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tsopia/go-kit/constants"
+	"github.com/tsopia/go-kit/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page 分页信息，作为 Paginated 响应的 meta
+type Page struct {
+	Number     int    `json:"number,omitempty"`      // 当前页码，从1开始；游标分页时为0
+	Size       int    `json:"size,omitempty"`        // 每页条数
+	TotalItems int64  `json:"total_items"`           // 总条数
+	TotalPages int    `json:"total_pages,omitempty"` // 总页数，为0且 Size 大于0时按 TotalItems 计算
+	NextCursor string `json:"next_cursor,omitempty"` // 下一页的游标，游标分页时使用
+}
+
+// ResponseError 响应中的错误信息
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Name    string `json:"name"`
+	Message string `json:"message"`
+	Details string `json:"details,omitempty"`
+}
+
+// Response 响应辅助函数收集的内容，由 EnvelopeConfig.Build 转换为响应体
+type Response struct {
+	Status    int            // HTTP状态码
+	Data      interface{}    // 响应数据，已按 NilDataAsEmptyObject 处理
+	Page      *Page          // 分页信息，只有 Paginated 设置
+	Error     *ResponseError // 错误信息，只有 Error 设置
+	TraceID   string
+	RequestID string
+}
+
+// Envelope 默认的统一响应结构
+//
+//	{"data": {...}, "meta": {...}, "error": {...}, "trace_id": "...", "request_id": "..."}
+//
+// meta 和 error 为空时省略，data、trace_id、request_id 始终存在。
+type Envelope struct {
+	Data      interface{}    `json:"data"`
+	Meta      *Page          `json:"meta,omitempty"`
+	Error     *ResponseError `json:"error,omitempty"`
+	TraceID   string         `json:"trace_id"`
+	RequestID string         `json:"request_id"`
+}
+
+// DefaultEnvelope 构建默认的 Envelope 响应体
+func DefaultEnvelope(c *gin.Context, r Response) interface{} {
+	return Envelope{Data: r.Data, Meta: r.Page, Error: r.Error, TraceID: r.TraceID, RequestID: r.RequestID}
+}
+
+// EnvelopeConfig 统一响应结构的全局配置
+type EnvelopeConfig struct {
+	// Build 构建响应体，nil 时使用 DefaultEnvelope；已有响应约定的团队可以在此返回自己的结构
+	Build func(c *gin.Context, r Response) interface{}
+	// NilDataAsEmptyObject 成功响应的 data 为 nil（包括 nil 指针和 nil map）时输出 {} 而不是 null
+	NilDataAsEmptyObject bool
+}
+
+// envelopeConfig 当前的全局配置
+var envelopeConfig atomic.Pointer[EnvelopeConfig]
+
+// RegisterEnvelope 设置统一响应结构，应在启动时调用一次；传入零值恢复默认
+//
+// 示例:
+//
+//	// 沿用已有的 {code, msg, result} 约定
+//	httpserver.RegisterEnvelope(httpserver.EnvelopeConfig{
+//	    Build: func(c *gin.Context, r httpserver.Response) interface{} {
+//	        body := gin.H{"code": 0, "msg": "ok", "result": r.Data}
+//	        if r.Error != nil {
+//	            body["code"], body["msg"] = r.Error.Code, r.Error.Message
+//	        }
+//	        return body
+//	    },
+//	})
+func RegisterEnvelope(cfg EnvelopeConfig) {
+	if cfg.Build == nil {
+		cfg.Build = DefaultEnvelope
+	}
+	envelopeConfig.Store(&cfg)
+}
+
+func currentEnvelope() *EnvelopeConfig {
+	if cfg := envelopeConfig.Load(); cfg != nil {
+		return cfg
+	}
+	return &EnvelopeConfig{Build: DefaultEnvelope}
+}
+
+// OK 返回 200 和 data
+func OK(c *gin.Context, data interface{}) {
+	respond(c, http.StatusOK, Response{Data: data})
+}
+
+// Created 返回 201 和新建的资源
+func Created(c *gin.Context, data interface{}) {
+	respond(c, http.StatusCreated, Response{Data: data})
+}
+
+// NoContent 返回 204，没有响应体
+func NoContent(c *gin.Context) {
+	c.Status(http.StatusNoContent)
+}
+
+// Paginated 返回 200，data 为 items，meta 为分页信息；items 为 nil 切片时输出 []
+//
+// 示例:
+//
+//	httpserver.Paginated(c, users, httpserver.Page{Number: 2, Size: 20, TotalItems: total})
+//	// {"data": [...], "meta": {"number": 2, "size": 20, "total_items": 45, "total_pages": 3}, ...}
+func Paginated(c *gin.Context, items interface{}, page Page) {
+	if v := reflect.ValueOf(items); v.Kind() == reflect.Slice && v.IsNil() {
+		items = reflect.MakeSlice(v.Type(), 0, 0).Interface()
+	}
+	if page.TotalPages == 0 && page.Size > 0 {
+		page.TotalPages = int((page.TotalItems + int64(page.Size) - 1) / int64(page.Size))
+	}
+	respond(c, http.StatusOK, Response{Data: items, Page: &page})
+}
+
+// Error 按错误码返回对应的HTTP状态码和错误信息，并中止请求
+//
+// 状态码由 RegisterErrorStatus 注册的映射决定，未注册的错误码按分类处理：
+// client 为 400，external 为 502，其余为 500。不是本库错误的 err 作为内部错误处理，
+// 响应中只包含默认消息，不暴露 err 的内容；原始错误通过 c.Error 记录，访问日志中可以看到。
+func Error(c *gin.Context, err error) {
+	if err == nil {
+		err = errors.New(errors.CodeInternalServer)
+	}
+	c.Error(err)
+	c.Abort()
+
+	// errors.GetCode 不会穿过 fmt.Errorf 的包装，使用 errors.As 查找
+	code := errors.CodeInternalServer
+	body := &ResponseError{Message: code.GetDefaultMessage()}
+	var kitErr *errors.Error
+	if stderrors.As(err, &kitErr) {
+		code = kitErr.Code
+		body.Message = kitErr.GetMessage()
+		body.Details = kitErr.Details
+	}
+	body.Code, body.Name = code.Code, code.Name
+	respond(c, ErrorStatus(code), Response{Error: body})
+}
+
+// respond 补充 trace/request ID 后按全局配置输出响应
+func respond(c *gin.Context, status int, r Response) {
+	cfg := currentEnvelope()
+	r.Status = status
+	if r.Error == nil && cfg.NilDataAsEmptyObject && isNilData(r.Data) {
+		r.Data = struct{}{}
+	}
+	r.TraceID, r.RequestID = responseIDs(c)
+	c.JSON(status, cfg.Build(c, r))
+}
+
+// responseIDs 从 gin.Context 获取 trace/request ID，没有时从请求的 context 获取
+func responseIDs(c *gin.Context) (traceID, requestID string) {
+	traceID, requestID = GetTraceID(c), GetRequestID(c)
+	if c.Request != nil {
+		if traceID == "" {
+			traceID = constants.TraceIDFromContext(c.Request.Context())
+		}
+		if requestID == "" {
+			requestID = constants.RequestIDFromContext(c.Request.Context())
+		}
+	}
+	return traceID, requestID
+}
+
+// isNilData 判断 data 是否为 nil、nil 指针或 nil map
+func isNilData(data interface{}) bool {
+	if data == nil {
+		return true
+	}
+	v := reflect.ValueOf(data)
+	return (v.Kind() == reflect.Ptr || v.Kind() == reflect.Map) && v.IsNil()
+}
+
+var (
+	errorStatusMu sync.RWMutex
+	// errorStatuses 数字错误码 -> HTTP状态码
+	errorStatuses = map[int]int{
+		errors.CodeInternalServer.Code:       http.StatusInternalServerError,
+		errors.CodeInvalidParam.Code:         http.StatusBadRequest,
+		errors.CodeNotFound.Code:             http.StatusNotFound,
+		errors.CodeUnauthorized.Code:         http.StatusUnauthorized,
+		errors.CodeForbidden.Code:            http.StatusForbidden,
+		errors.CodeConflict.Code:             http.StatusConflict,
+		errors.CodeTooManyRequests.Code:      http.StatusTooManyRequests,
+		errors.CodeUserNotFound.Code:         http.StatusNotFound,
+		errors.CodeUserExists.Code:           http.StatusConflict,
+		errors.CodeInvalidPassword.Code:      http.StatusUnauthorized,
+		errors.CodeTokenExpired.Code:         http.StatusUnauthorized,
+		errors.CodeTokenInvalid.Code:         http.StatusUnauthorized,
+		errors.CodeDatabaseError.Code:        http.StatusInternalServerError,
+		errors.CodeRecordNotFound.Code:       http.StatusNotFound,
+		errors.CodeDuplicateKey.Code:         http.StatusConflict,
+		errors.CodeForeignKeyViolation.Code:  http.StatusConflict,
+		errors.CodeExternalServiceError.Code: http.StatusBadGateway,
+		errors.CodeNetworkError.Code:         http.StatusBadGateway,
+		errors.CodeTimeoutError.Code:         http.StatusGatewayTimeout,
+	}
+)
+
+// RegisterErrorStatus 注册自定义错误码对应的HTTP状态码，已注册的错误码会被覆盖
+//
+// 示例:
+//
+//	var CodeQuotaExceeded = errors.NewErrorCode(2100, "QUOTA_EXCEEDED", "配额不足")
+//
+//	func init() {
+//	    httpserver.RegisterErrorStatus(CodeQuotaExceeded, http.StatusPaymentRequired)
+//	}
+func RegisterErrorStatus(code errors.ErrorCode, status int) {
+	errorStatusMu.Lock()
+	defer errorStatusMu.Unlock()
+	errorStatuses[code.Code] = status
+}
+
+// ErrorStatus 返回错误码对应的HTTP状态码，未注册时按分类：client 为 400，external 为 502，其余为 500
+func ErrorStatus(code errors.ErrorCode) int {
+	errorStatusMu.RLock()
+	status, ok := errorStatuses[code.Code]
+	errorStatusMu.RUnlock()
+	if ok {
+		return status
+	}
+	switch code.Category() {
+	case errors.CategoryClient:
+		return http.StatusBadRequest
+	case errors.CategoryExternal:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
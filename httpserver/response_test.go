@@ -0,0 +1,148 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tsopia/go-kit/constants"
+	"github.com/tsopia/go-kit/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondWith 在带有 trace/request ID 的请求中执行 handler
+func respondWith(handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	server := NewServer(nil)
+	server.GET("/", func(c *gin.Context) {
+		c.Set(constants.TraceIDKey, "t-1")
+		c.Set(constants.RequestIDKey, "r-1")
+		handler(c)
+	})
+	w := httptest.NewRecorder()
+	server.Engine().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	return w
+}
+
+func TestResponseHelpers(t *testing.T) {
+	type user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	var nilUsers []user
+
+	cases := []struct {
+		name    string
+		handler gin.HandlerFunc
+		status  int
+		body    string
+	}{
+		{"ok", func(c *gin.Context) { OK(c, user{ID: 1, Name: "a"}) }, http.StatusOK,
+			`{"data":{"id":1,"name":"a"},"trace_id":"t-1","request_id":"r-1"}`},
+		{"ok nil", func(c *gin.Context) { OK(c, nil) }, http.StatusOK,
+			`{"data":null,"trace_id":"t-1","request_id":"r-1"}`},
+		{"created", func(c *gin.Context) { Created(c, user{ID: 2, Name: "b"}) }, http.StatusCreated,
+			`{"data":{"id":2,"name":"b"},"trace_id":"t-1","request_id":"r-1"}`},
+		{"no content", func(c *gin.Context) { NoContent(c) }, http.StatusNoContent, ``},
+		{"paginated", func(c *gin.Context) {
+			Paginated(c, []user{{ID: 1, Name: "a"}}, Page{Number: 2, Size: 20, TotalItems: 45})
+		}, http.StatusOK,
+			`{"data":[{"id":1,"name":"a"}],"meta":{"number":2,"size":20,"total_items":45,"total_pages":3},"trace_id":"t-1","request_id":"r-1"}`},
+		{"paginated cursor", func(c *gin.Context) { Paginated(c, nilUsers, Page{NextCursor: "abc"}) }, http.StatusOK,
+			`{"data":[],"meta":{"total_items":0,"next_cursor":"abc"},"trace_id":"t-1","request_id":"r-1"}`},
+		{"error", func(c *gin.Context) {
+			Error(c, errors.NewWithDetails(errors.CodeUserNotFound, "用户不存在", "id=42"))
+		}, http.StatusNotFound,
+			`{"data":null,"error":{"code":2000,"name":"USER_NOT_FOUND","message":"用户不存在","details":"id=42"},"trace_id":"t-1","request_id":"r-1"}`},
+		{"wrapped error", func(c *gin.Context) {
+			Error(c, fmt.Errorf("service: %w", errors.Timeout()))
+		}, http.StatusGatewayTimeout,
+			`{"data":null,"error":{"code":4002,"name":"TIMEOUT_ERROR","message":"请求超时"},"trace_id":"t-1","request_id":"r-1"}`},
+		{"plain error", func(c *gin.Context) { Error(c, fmt.Errorf("dial tcp 10.0.0.1:5432: refused")) }, http.StatusInternalServerError,
+			`{"data":null,"error":{"code":1000,"name":"INTERNAL_SERVER_ERROR","message":"` + errors.CodeInternalServer.GetDefaultMessage() + `"},"trace_id":"t-1","request_id":"r-1"}`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := respondWith(tc.handler)
+			if w.Code != tc.status {
+				t.Errorf("Expected status %d, got %d", tc.status, w.Code)
+			}
+			if w.Body.String() != tc.body {
+				t.Errorf("Unexpected body:\n%s\nwant:\n%s", w.Body.String(), tc.body)
+			}
+			if tc.body != "" && w.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+				t.Errorf("Unexpected Content-Type %q", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestResponseIDsFromRequestContext(t *testing.T) {
+	server := NewServer(nil)
+	server.GET("/", func(c *gin.Context) { OK(c, "x") })
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(constants.WithTraceAndRequestID(req.Context(), "ctx-trace", "ctx-req"))
+	w := httptest.NewRecorder()
+	server.Engine().ServeHTTP(w, req)
+
+	if want := `{"data":"x","trace_id":"ctx-trace","request_id":"ctx-req"}`; w.Body.String() != want {
+		t.Errorf("Expected IDs from the request context, got %s", w.Body.String())
+	}
+}
+
+func TestRegisterEnvelope(t *testing.T) {
+	defer RegisterEnvelope(EnvelopeConfig{})
+
+	RegisterEnvelope(EnvelopeConfig{NilDataAsEmptyObject: true})
+	if w := respondWith(func(c *gin.Context) { OK(c, nil) }); w.Body.String() != `{"data":{},"trace_id":"t-1","request_id":"r-1"}` {
+		t.Errorf("Expected nil data as an empty object, got %s", w.Body.String())
+	}
+	if w := respondWith(func(c *gin.Context) { Error(c, errors.NotFound()) }); w.Body.String() != `{"data":null,"error":{"code":1002,"name":"NOT_FOUND","message":"`+errors.CodeNotFound.GetDefaultMessage()+`"},"trace_id":"t-1","request_id":"r-1"}` {
+		t.Errorf("Expected error data to stay null, got %s", w.Body.String())
+	}
+
+	RegisterEnvelope(EnvelopeConfig{Build: func(c *gin.Context, r Response) interface{} {
+		body := gin.H{"code": 0, "msg": "ok", "result": r.Data, "trace": r.TraceID}
+		if r.Error != nil {
+			body["code"], body["msg"] = r.Error.Code, r.Error.Message
+		}
+		if r.Page != nil {
+			body["total"] = r.Page.TotalItems
+		}
+		return body
+	}})
+	cases := map[string]struct {
+		handler gin.HandlerFunc
+		body    string
+	}{
+		"ok":        {func(c *gin.Context) { OK(c, []int{1}) }, `{"code":0,"msg":"ok","result":[1],"trace":"t-1"}`},
+		"paginated": {func(c *gin.Context) { Paginated(c, []int{1, 2}, Page{TotalItems: 2}) }, `{"code":0,"msg":"ok","result":[1,2],"total":2,"trace":"t-1"}`},
+		"error":     {func(c *gin.Context) { Error(c, errors.InvalidParam("名称不能为空")) }, `{"code":1001,"msg":"名称不能为空","result":null,"trace":"t-1"}`},
+	}
+	for name, tc := range cases {
+		if w := respondWith(tc.handler); w.Body.String() != tc.body {
+			t.Errorf("%s: unexpected custom envelope %s", name, w.Body.String())
+		}
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	custom := errors.NewErrorCode(2100, "QUOTA_EXCEEDED", "配额不足")
+	if got := ErrorStatus(custom); got != http.StatusBadRequest {
+		t.Errorf("Expected client category fallback 400, got %d", got)
+	}
+	RegisterErrorStatus(custom, http.StatusPaymentRequired)
+	defer func() {
+		errorStatusMu.Lock()
+		delete(errorStatuses, custom.Code)
+		errorStatusMu.Unlock()
+	}()
+	if got := ErrorStatus(custom); got != http.StatusPaymentRequired {
+		t.Errorf("Expected registered status 402, got %d", got)
+	}
+	if got := ErrorStatus(errors.NewErrorCode(4100, "UPSTREAM", "")); got != http.StatusBadGateway {
+		t.Errorf("Expected external category fallback 502, got %d", got)
+	}
+}
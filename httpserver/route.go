@@ -0,0 +1,247 @@
+package httpserver
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tsopia/go-kit/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	bodyLimitKey      = "body_limit"
+	baseBodyKey       = "body_limit_base"
+	requestTimeoutKey = "request_timeout"
+	baseContextKey    = "request_timeout_base"
+	requestStartKey   = "request_timeout_start"
+)
+
+// routeSettings 路由级（或路由组级）设置
+type routeSettings struct {
+	bodyLimit   int64
+	timeout     time.Duration
+	middlewares []gin.HandlerFunc
+}
+
+// RouteOption 路由（或路由组）选项，通过 Server.Handle、Route 或 Group 注册
+type RouteOption func(*routeSettings)
+
+// WithBodyLimit 设置路由的请求体大小上限（字节），覆盖 BodyLimitMiddleware 的全局设置
+//
+// 示例:
+//
+//	server.Handle(http.MethodPost, "/upload", []httpserver.RouteOption{httpserver.WithBodyLimit(200 << 20)}, uploadHandler)
+func WithBodyLimit(limit int64) RouteOption {
+	return func(s *routeSettings) {
+		s.bodyLimit = limit
+	}
+}
+
+// WithTimeout 设置路由的请求超时，覆盖 TimeoutMiddleware 的全局设置
+func WithTimeout(timeout time.Duration) RouteOption {
+	return func(s *routeSettings) {
+		s.timeout = timeout
+	}
+}
+
+// WithMiddleware 为路由（或路由组）添加额外的中间件，不影响其他路由
+func WithMiddleware(middleware ...gin.HandlerFunc) RouteOption {
+	return func(s *routeSettings) {
+		s.middlewares = append(s.middlewares, middleware...)
+	}
+}
+
+// Handle 注册带路由选项的路由
+//
+// 示例:
+//
+//	server.Handle(http.MethodPost, "/upload", []httpserver.RouteOption{
+//	    httpserver.WithBodyLimit(200 << 20),
+//	    httpserver.WithTimeout(5 * time.Minute),
+//	}, uploadHandler)
+func (s *Server) Handle(method, relativePath string, opts []RouteOption, handlers ...gin.HandlerFunc) {
+	Route(s.engine, method, relativePath, opts, handlers...)
+}
+
+// Route 在任意路由器（例如 gin.RouterGroup）上注册带路由选项的路由
+//
+// 示例:
+//
+//	admin := httpserver.Group(server.Engine(), "/admin", []httpserver.RouteOption{httpserver.WithMiddleware(adminAuth)})
+//	httpserver.Route(admin, http.MethodPost, "/import", []httpserver.RouteOption{httpserver.WithTimeout(time.Minute)}, importHandler)
+func Route(r gin.IRoutes, method, relativePath string, opts []RouteOption, handlers ...gin.HandlerFunc) gin.IRoutes {
+	return r.Handle(method, relativePath, withRouteOptions(opts, handlers)...)
+}
+
+// Group 创建带路由选项的路由组，选项对组内所有路由生效
+func Group(r gin.IRouter, relativePath string, opts []RouteOption, handlers ...gin.HandlerFunc) *gin.RouterGroup {
+	return r.Group(relativePath, withRouteOptions(opts, handlers)...)
+}
+
+// withRouteOptions 生成 [设置处理函数, 额外中间件..., 处理函数...] 的处理链
+func withRouteOptions(opts []RouteOption, handlers []gin.HandlerFunc) []gin.HandlerFunc {
+	if len(opts) == 0 {
+		return handlers
+	}
+
+	var settings routeSettings
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	chain := make([]gin.HandlerFunc, 0, len(handlers)+len(settings.middlewares)+1)
+	chain = append(chain, settingsHandler(settings))
+	chain = append(chain, settings.middlewares...)
+	return append(chain, handlers...)
+}
+
+// settingsHandler 在请求处理链中应用路由设置
+// 路由组的设置先执行，路由的设置后执行，因此优先级为 路由 > 路由组 > 全局
+func settingsHandler(settings routeSettings) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if settings.bodyLimit > 0 {
+			applyBodyLimit(c, settings.bodyLimit)
+		}
+		if settings.timeout > 0 {
+			cancel := applyTimeout(c, settings.timeout)
+			defer cancel()
+			c.Next()
+			abortIfTimedOut(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// BodyLimitMiddleware 请求体大小限制中间件
+//
+// 读取超过上限的请求体时返回 *http.MaxBytesError，处理函数可据此返回 413。
+// 路由或路由组通过 WithBodyLimit 设置的上限优先于此处的全局上限。
+func BodyLimitMiddleware(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit > 0 {
+			applyBodyLimit(c, limit)
+		}
+		c.Next()
+	}
+}
+
+// TimeoutMiddleware 请求超时中间件
+//
+// 为请求上下文设置截止时间；处理函数返回时如果已超时且尚未写入响应，返回 504。
+// 路由或路由组通过 WithTimeout 设置的超时优先于此处的全局超时（可以更长）。
+func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+		cancel := applyTimeout(c, timeout)
+		defer cancel()
+		c.Next()
+		abortIfTimedOut(c)
+	}
+}
+
+// BodyLimit 获取当前请求生效的请求体大小上限，未设置时返回0
+func BodyLimit(c *gin.Context) int64 {
+	return c.GetInt64(bodyLimitKey)
+}
+
+// RequestTimeout 获取当前请求生效的超时时间，未设置时返回0
+func RequestTimeout(c *gin.Context) time.Duration {
+	return c.GetDuration(requestTimeoutKey)
+}
+
+// applyBodyLimit 基于原始请求体重新设置大小上限，后设置的上限覆盖之前的
+func applyBodyLimit(c *gin.Context, limit int64) {
+	base, ok := c.Get(baseBodyKey)
+	if !ok {
+		base = c.Request.Body
+		c.Set(baseBodyKey, base)
+	}
+	if body, _ := base.(io.ReadCloser); body != nil {
+		c.Request.Body = http.MaxBytesReader(c.Writer, body, limit)
+	}
+	c.Set(bodyLimitKey, limit)
+}
+
+// applyTimeout 基于请求开始时间设置截止时间，后设置的超时覆盖之前的（可以延长）
+// 保留上下文中的值，并继续响应原始请求上下文的取消（例如客户端断开）；
+// 不会超过客户端通过 ClientTimeoutMiddleware 声明的截止时间
+func applyTimeout(c *gin.Context, timeout time.Duration) context.CancelFunc {
+	base, ok := c.Get(baseContextKey)
+	if !ok {
+		base = c.Request.Context()
+		c.Set(baseContextKey, base)
+		c.Set(requestStartKey, time.Now())
+	}
+	deadline := c.GetTime(requestStartKey).Add(timeout)
+	if limit, ok := clientDeadline(c); ok && limit.Before(deadline) {
+		deadline = limit
+	}
+
+	ctx, cancel := context.WithDeadline(context.WithoutCancel(c.Request.Context()), deadline)
+	stop := context.AfterFunc(base.(context.Context), cancel)
+	c.Request = c.Request.WithContext(ctx)
+	c.Set(requestTimeoutKey, timeout)
+
+	return func() {
+		stop()
+		cancel()
+	}
+}
+
+// abortIfTimedOut 请求已超时且尚未写入响应时返回 504
+func abortIfTimedOut(c *gin.Context) {
+	if c.Writer.Written() {
+		return
+	}
+	if c.Request.Context().Err() == context.DeadlineExceeded {
+		abortWithError(c, http.StatusGatewayTimeout, errors.CodeTimeoutError, "请求处理超时")
+	}
+}
+
+// RouteInfo 已注册路由的信息
+type RouteInfo struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Handler string `json:"handler"` // 最后一个处理函数的名称，去掉了包路径，例如 "orders.(*Handler).List"
+}
+
+// Routes 返回所有已注册的路由，按路径和方法排序，用于调试和确认路由注册结果
+//
+// 示例:
+//
+//	for _, route := range server.Routes() {
+//	    fmt.Printf("%-6s %s -> %s\n", route.Method, route.Path, route.Handler)
+//	}
+func (s *Server) Routes() []RouteInfo {
+	routes := s.engine.Routes()
+	infos := make([]RouteInfo, 0, len(routes))
+	for _, route := range routes {
+		infos = append(infos, RouteInfo{Method: route.Method, Path: route.Path, Handler: handlerName(route.Handler)})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Path != infos[j].Path {
+			return infos[i].Path < infos[j].Path
+		}
+		return infos[i].Method < infos[j].Method
+	})
+	return infos
+}
+
+// handlerName 简化 runtime 的函数名：去掉包路径和方法值的 "-fm" 后缀，
+// 例如 "github.com/acme/orders.(*Handler).List-fm" -> "orders.(*Handler).List"
+func handlerName(name string) string {
+	name = strings.TrimSuffix(name, "-fm")
+	if idx := strings.LastIndexByte(name, '/'); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return name
+}
@@ -0,0 +1,174 @@
+package httpserver
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// uploadHandler 读取请求体，超过上限时返回 413
+func uploadHandler(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.Status(http.StatusRequestEntityTooLarge)
+			return
+		}
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	c.String(http.StatusOK, "%d", len(body))
+}
+
+func postBody(server *Server, path string, size int) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", path, bytes.NewReader(make([]byte, size)))
+	server.Engine().ServeHTTP(w, req)
+	return w
+}
+
+func TestRouteBodyLimitOverride(t *testing.T) {
+	server := NewServer(nil)
+	server.Use(BodyLimitMiddleware(1 << 10))
+	server.Handle(http.MethodPost, "/upload", []RouteOption{WithBodyLimit(1 << 20)}, uploadHandler)
+	server.POST("/default", uploadHandler)
+
+	if w := postBody(server, "/upload", 100<<10); w.Code != http.StatusOK {
+		t.Errorf("Expected large upload to succeed on tuned route, got %d", w.Code)
+	}
+	if w := postBody(server, "/default", 100<<10); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected large upload to fail on default route, got %d", w.Code)
+	}
+	if w := postBody(server, "/default", 512); w.Code != http.StatusOK {
+		t.Errorf("Expected small upload to succeed on default route, got %d", w.Code)
+	}
+}
+
+func TestGroupOptionsPrecedence(t *testing.T) {
+	server := NewServer(nil)
+	server.Use(BodyLimitMiddleware(1 << 10))
+
+	api := Group(server.Engine(), "/api", []RouteOption{WithBodyLimit(64 << 10)})
+	api.POST("/group", uploadHandler)
+	Route(api, http.MethodPost, "/route", []RouteOption{WithBodyLimit(1 << 20)}, uploadHandler)
+
+	// 路由组 > 全局
+	if w := postBody(server, "/api/group", 32<<10); w.Code != http.StatusOK {
+		t.Errorf("Expected group limit to override global, got %d", w.Code)
+	}
+	if w := postBody(server, "/api/group", 100<<10); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected group limit to apply, got %d", w.Code)
+	}
+	// 路由 > 路由组
+	if w := postBody(server, "/api/route", 100<<10); w.Code != http.StatusOK {
+		t.Errorf("Expected route limit to override group, got %d", w.Code)
+	}
+}
+
+func TestRouteTimeoutOverride(t *testing.T) {
+	server := NewServer(nil)
+	server.Use(TraceIDMiddleware())
+	server.Use(TimeoutMiddleware(20 * time.Millisecond))
+
+	slow := func(c *gin.Context) {
+		select {
+		case <-time.After(60 * time.Millisecond):
+			c.String(http.StatusOK, "%s|%s", RequestTimeout(c), GetTraceID(c))
+		case <-c.Request.Context().Done():
+		}
+	}
+	server.Handle(http.MethodGet, "/slow", []RouteOption{WithTimeout(time.Second)}, slow)
+	server.GET("/default", slow)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/slow", nil)
+	server.Engine().ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Body.String(), "1s|") {
+		t.Errorf("Expected route timeout to extend global timeout, got %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/default", nil)
+	server.Engine().ServeHTTP(w, req)
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("Expected default route to time out, got %d", w.Code)
+	}
+}
+
+func TestWithMiddleware(t *testing.T) {
+	server := NewServer(nil)
+
+	var order []string
+	mark := func(name string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			order = append(order, name)
+			c.Next()
+		}
+	}
+
+	admin := Group(server.Engine(), "/admin", []RouteOption{WithMiddleware(mark("group"))})
+	Route(admin, http.MethodGet, "/users", []RouteOption{WithMiddleware(mark("route"))}, func(c *gin.Context) {
+		order = append(order, "handler")
+	})
+	server.GET("/public", func(c *gin.Context) {
+		order = append(order, "public")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin/users", nil)
+	server.Engine().ServeHTTP(w, req)
+	if got := strings.Join(order, ","); got != "group,route,handler" {
+		t.Errorf("Expected group,route,handler, got %s", got)
+	}
+
+	order = nil
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/public", nil)
+	server.Engine().ServeHTTP(w, req)
+	if got := strings.Join(order, ","); got != "public" {
+		t.Errorf("Expected route middleware not to affect other routes, got %s", got)
+	}
+}
+
+func TestWithRouteOptionsWithoutOptions(t *testing.T) {
+	handlers := []gin.HandlerFunc{func(c *gin.Context) {}, func(c *gin.Context) {}}
+	if got := withRouteOptions(nil, handlers); len(got) != 2 {
+		t.Errorf("Expected handlers to be unchanged, got %d", len(got))
+	}
+}
+
+// ordersHandler 用于检查方法值的处理函数名称
+type ordersHandler struct{}
+
+func (h *ordersHandler) List(c *gin.Context) {}
+
+func TestRoutes(t *testing.T) {
+	server := NewServer(nil)
+	h := &ordersHandler{}
+	server.GET("/orders", h.List)
+	server.Handle(http.MethodPost, "/orders/:id/upload", []RouteOption{WithTimeout(time.Second)}, uploadHandler)
+	server.Group("/admin").DELETE("/cache", func(c *gin.Context) {})
+
+	want := []RouteInfo{
+		{Method: http.MethodDelete, Path: "/admin/cache", Handler: "httpserver.TestRoutes.func1"},
+		{Method: http.MethodGet, Path: "/orders", Handler: "httpserver.(*ordersHandler).List"},
+		{Method: http.MethodPost, Path: "/orders/:id/upload", Handler: "httpserver.uploadHandler"},
+	}
+	got := server.Routes()
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d routes, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Route %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
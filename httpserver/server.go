@@ -3,13 +3,16 @@ package httpserver
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/tsopia/go-kit/constants"
+	"github.com/tsopia/go-kit/errors"
 
 	"github.com/gin-gonic/gin"
 )
@@ -23,6 +26,22 @@ type Config struct {
 	IdleTimeout     time.Duration
 	MaxHeaderBytes  int
 	ShutdownTimeout time.Duration
+	// ShutdownSignals 触发优雅关闭的信号，为空时使用 SIGINT 和 SIGTERM
+	ShutdownSignals []os.Signal
+	// WorkerStopOrder 关闭时停止后台工作协程与排空HTTP连接的先后顺序，默认同时进行
+	WorkerStopOrder WorkerStopOrder
+	// EnableH2C 在未启用TLS时支持明文HTTP/2（h2c），与 gRPC 共用端口时需要开启
+	EnableH2C bool
+	// SuggestRoutes 默认的404响应中包含与请求路径最接近的已注册路由（suggestion 字段）
+	SuggestRoutes bool
+	// ReadinessDrainDelay 开始关闭时就绪门变为未就绪后、排空连接前的等待时间，
+	// 让负载均衡器在停止接受连接前发现服务器未就绪（例如 Kubernetes 就绪探针的周期），计入 ShutdownTimeout
+	ReadinessDrainDelay time.Duration
+	// Logger 服务器内部事件（工作协程重启、失败和 panic）的日志记录器，为空时输出到标准输出
+	Logger Logger
+	// PanicReporter 上报工作协程中恢复的 panic，为空时连同堆栈以 Error 级别记录到 Logger
+	// 使用 logger.NewPanicReporter 可以按指纹对重复的 panic 去重
+	PanicReporter PanicReporter
 }
 
 // DefaultConfig 返回默认配置
@@ -35,14 +54,26 @@ func DefaultConfig() *Config {
 		IdleTimeout:     60 * time.Second,
 		MaxHeaderBytes:  1 << 20, // 1MB
 		ShutdownTimeout: 10 * time.Second,
+		ShutdownSignals: []os.Signal{syscall.SIGINT, syscall.SIGTERM},
 	}
 }
 
 // Server HTTP服务器 - 最小化封装
 type Server struct {
-	config *Config
-	engine *gin.Engine
-	server *http.Server
+	config           *Config
+	engine           *gin.Engine
+	server           *http.Server
+	spaMounts        []spaMount
+	workers          workerGroup
+	readiness        readiness
+	protocols        []ProtocolHandler
+	shutdownHooks    []ShutdownHook
+	notFound         gin.HandlerFunc
+	methodNotAllowed gin.HandlerFunc
+
+	// listenerMu 保护 listener，Run 阻塞期间其他协程可能读取监听地址
+	listenerMu sync.RWMutex
+	listener   net.Listener
 }
 
 // NewServer 创建新的HTTP服务器
@@ -53,11 +84,23 @@ func NewServer(config *Config) *Server {
 
 	// 创建纯净的gin引擎，不添加任何中间件
 	engine := gin.New()
+	engine.HandleMethodNotAllowed = true
 
-	return &Server{
-		config: config,
-		engine: engine,
+	logger := config.Logger
+	if logger == nil {
+		logger = stdoutLogger{}
 	}
+
+	s := &Server{
+		config:  config,
+		engine:  engine,
+		workers: workerGroup{failed: make(chan error, 1), logger: logger, reportPanic: config.PanicReporter},
+	}
+	s.notFound = s.defaultNotFound
+	s.methodNotAllowed = defaultMethodNotAllowed
+	engine.NoRoute(s.handleNoRoute)
+	engine.NoMethod(s.handleNoMethod)
+	return s
 }
 
 // Engine 返回Gin引擎，用户完全控制
@@ -71,6 +114,8 @@ func (s *Server) RegisterRoutes(routes func(r *gin.Engine)) {
 }
 
 // 路由注册便利方法（可选使用）
+//
+// 需要路由选项（WithBodyLimit、WithTimeout、WithMiddleware）时使用 Handle。
 
 // GET 注册GET路由的便利方法
 func (s *Server) GET(relativePath string, handlers ...gin.HandlerFunc) {
@@ -112,7 +157,7 @@ func (s *Server) Any(relativePath string, handlers ...gin.HandlerFunc) {
 	s.engine.Any(relativePath, handlers...)
 }
 
-// Group 创建路由组的便利方法
+// Group 创建路由组的便利方法，需要路由选项时使用 httpserver.Group
 func (s *Server) Group(relativePath string, handlers ...gin.HandlerFunc) *gin.RouterGroup {
 	return s.engine.Group(relativePath, handlers...)
 }
@@ -123,21 +168,23 @@ func (s *Server) Use(middleware ...gin.HandlerFunc) {
 }
 
 // Start 启动服务器（非阻塞）
+// 端口在返回前完成绑定，绑定失败（如端口被占用）时返回错误；Port 为 0 时由系统分配，通过 Port 获取
 func (s *Server) Start() error {
-	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
-
-	s.server = &http.Server{
-		Addr:           addr,
-		Handler:        s.engine,
-		ReadTimeout:    s.config.ReadTimeout,
-		WriteTimeout:   s.config.WriteTimeout,
-		IdleTimeout:    s.config.IdleTimeout,
-		MaxHeaderBytes: s.config.MaxHeaderBytes,
+	server, err := s.newHTTPServer()
+	if err != nil {
+		return err
 	}
+	listener, err := s.listen(server)
+	if err != nil {
+		return err
+	}
+	s.server = server
+
+	s.workers.start()
 
 	// 启动服务器（非阻塞）
 	go func() {
-		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
 			panic(fmt.Sprintf("HTTP server failed to start: %v", err))
 		}
 	}()
@@ -147,93 +194,212 @@ func (s *Server) Start() error {
 
 // Run 启动服务器（阻塞）
 func (s *Server) Run() error {
-	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
-
-	s.server = &http.Server{
-		Addr:           addr,
-		Handler:        s.engine,
-		ReadTimeout:    s.config.ReadTimeout,
-		WriteTimeout:   s.config.WriteTimeout,
-		IdleTimeout:    s.config.IdleTimeout,
-		MaxHeaderBytes: s.config.MaxHeaderBytes,
+	server, err := s.newHTTPServer()
+	if err != nil {
+		return err
 	}
+	listener, err := s.listen(server)
+	if err != nil {
+		return err
+	}
+	s.server = server
 
-	return s.server.ListenAndServe()
+	s.workers.start()
+	return s.server.Serve(listener)
 }
 
 // RunTLS 启动HTTPS服务器（阻塞）
 func (s *Server) RunTLS(certFile, keyFile string) error {
-	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
-
-	s.server = &http.Server{
-		Addr:           addr,
-		Handler:        s.engine,
-		ReadTimeout:    s.config.ReadTimeout,
-		WriteTimeout:   s.config.WriteTimeout,
-		IdleTimeout:    s.config.IdleTimeout,
-		MaxHeaderBytes: s.config.MaxHeaderBytes,
+	server, err := s.newHTTPServer()
+	if err != nil {
+		return err
+	}
+	listener, err := s.listen(server)
+	if err != nil {
+		return err
 	}
+	s.server = server
+
+	s.workers.start()
+	return s.server.ServeTLS(listener, certFile, keyFile)
+}
 
-	return s.server.ListenAndServeTLS(certFile, keyFile)
+// listen 绑定配置的地址并记录监听器，供 BoundAddr 和 Port 获取实际地址
+func (s *Server) listen(server *http.Server) (net.Listener, error) {
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("监听 %s 失败: %w", server.Addr, err)
+	}
+	s.listenerMu.Lock()
+	s.listener = listener
+	s.listenerMu.Unlock()
+	return listener, nil
 }
 
 // RunWithGracefulShutdown 启动服务器并自动处理优雅关闭（阻塞）
 func (s *Server) RunWithGracefulShutdown() error {
+	return s.RunWithGracefulShutdownContext(context.Background())
+}
+
+// RunWithGracefulShutdownContext 启动服务器，在收到关闭信号或 ctx 被取消时优雅关闭（阻塞）
+// 适用于由外部统一管理生命周期的场景，也便于在测试中通过取消 ctx 触发关闭
+func (s *Server) RunWithGracefulShutdownContext(ctx context.Context) error {
 	// 启动服务器（非阻塞）
 	if err := s.Start(); err != nil {
 		return err
 	}
 
 	// 监听关闭信号
-	return s.WaitForShutdown()
+	return s.WaitForShutdownContext(ctx)
 }
 
 // WaitForShutdown 等待关闭信号并执行优雅关闭
 func (s *Server) WaitForShutdown() error {
+	return s.WaitForShutdownContext(context.Background())
+}
+
+// WaitForShutdownContext 等待关闭信号或 ctx 取消，然后执行优雅关闭
+func (s *Server) WaitForShutdownContext(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	// 创建信号通道
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
-	// 阻塞等待信号
-	<-quit
-	fmt.Println("收到关闭信号，开始优雅关闭服务器...")
+	signal.Notify(quit, s.shutdownSignals()...)
+	defer signal.Stop(quit)
+
+	// 阻塞等待信号、context 取消或 fail-fast 工作协程失败
+	var workerErr error
+	select {
+	case <-quit:
+		fmt.Println("收到关闭信号，开始优雅关闭服务器...")
+	case <-ctx.Done():
+		fmt.Println("上下文已取消，开始优雅关闭服务器...")
+	case workerErr = <-s.workers.failed:
+		fmt.Printf("%v，开始优雅关闭服务器...\n", workerErr)
+	}
 
 	// 创建关闭context
-	ctx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
 	defer cancel()
 
 	// 优雅关闭
-	if err := s.Shutdown(ctx); err != nil {
+	if err := s.Shutdown(shutdownCtx); err != nil {
+		if workerErr != nil {
+			return fmt.Errorf("服务器关闭失败: %w (%v)", err, workerErr)
+		}
 		return fmt.Errorf("服务器关闭失败: %w", err)
 	}
 
 	fmt.Println("服务器已优雅关闭")
-	return nil
+	return workerErr
 }
 
-// Shutdown 优雅关闭服务器
-func (s *Server) Shutdown(ctx context.Context) error {
-	if s.server == nil {
-		return nil
+// shutdownSignals 返回触发优雅关闭的信号集合
+func (s *Server) shutdownSignals() []os.Signal {
+	if len(s.config.ShutdownSignals) > 0 {
+		return s.config.ShutdownSignals
+	}
+	return []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+}
+
+// ShutdownHook 服务器关闭时执行的清理函数，应在 ctx 结束前返回
+type ShutdownHook func(ctx context.Context) error
+
+// OnShutdown 注册关闭钩子，用于关闭数据库连接池、刷新日志等，需要在启动服务器之前调用
+//
+// 钩子在HTTP连接排空、后台工作协程停止之后按注册的相反顺序执行（与 defer 相同），
+// 此时不会再有请求使用这些资源。某个钩子返回错误时其余钩子仍会执行，Shutdown 返回第一个错误。
+//
+// 示例:
+//
+//	server.OnShutdown(db.Shutdown) // 等待进行中的查询完成后关闭连接池
+func (s *Server) OnShutdown(hook ShutdownHook) {
+	if hook != nil {
+		s.shutdownHooks = append(s.shutdownHooks, hook)
+	}
+}
+
+// runShutdownHooks 按注册的相反顺序执行关闭钩子，返回第一个错误
+func (s *Server) runShutdownHooks(ctx context.Context) error {
+	var firstErr error
+	for i := len(s.shutdownHooks) - 1; i >= 0; i-- {
+		if err := s.shutdownHooks[i](ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
+	return firstErr
+}
 
+// Shutdown 优雅关闭服务器，并按 Config.WorkerStopOrder 停止后台工作协程，最后执行 OnShutdown 注册的钩子
+//
+// 开始关闭时所有就绪门立即变为未就绪，等待 Config.ReadinessDrainDelay 后再排空连接；
+// 之前调用过 BeginDrain 时从 BeginDrain 开始计算等待时间。
+func (s *Server) Shutdown(ctx context.Context) error {
 	if ctx == nil {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
 		defer cancel()
 	}
 
-	return s.server.Shutdown(ctx)
+	drainStarted := s.readiness.beginShutdown()
+	s.waitReadinessPropagation(ctx, drainStarted)
+
+	drain := func() error {
+		// 先停止共用端口的其他协议，再排空HTTP连接
+		protocolErr := s.shutdownProtocols(ctx)
+		if s.server == nil {
+			return protocolErr
+		}
+		if err := s.server.Shutdown(ctx); err != nil {
+			return err
+		}
+		return protocolErr
+	}
+
+	err := s.workers.shutdown(ctx, s.config.WorkerStopOrder, drain)
+	if hookErr := s.runShutdownHooks(ctx); err == nil {
+		err = hookErr
+	}
+	return err
 }
 
-// Addr 返回服务器地址
+// Addr 返回服务器地址，启动后为实际绑定的地址
 func (s *Server) Addr() string {
+	if addr := s.BoundAddr(); addr != nil {
+		return addr.String()
+	}
 	if s.server != nil {
 		return s.server.Addr
 	}
 	return fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
 }
 
+// BoundAddr 返回启动后实际绑定的地址，尚未启动时返回 nil
+//
+// Config.Port 为 0 时由系统分配空闲端口，集成测试可以据此得到访问地址:
+//
+//	config.Port = 0
+//	server.Start()
+//	url := fmt.Sprintf("http://127.0.0.1:%d/ping", server.Port())
+func (s *Server) BoundAddr() net.Addr {
+	s.listenerMu.RLock()
+	defer s.listenerMu.RUnlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Port 返回启动后实际绑定的端口，尚未启动时返回 0
+func (s *Server) Port() int {
+	if addr, ok := s.BoundAddr().(*net.TCPAddr); ok {
+		return addr.Port
+	}
+	return 0
+}
+
 // IsRunning 检查服务器是否正在运行
 func (s *Server) IsRunning() bool {
 	return s.server != nil
@@ -321,6 +487,20 @@ func GetRequestID(c *gin.Context) string {
 	return ""
 }
 
+// abortWithError 以统一的错误结构中止请求
+func abortWithError(c *gin.Context, status int, code errors.ErrorCode, message string) {
+	c.AbortWithStatusJSON(status, errorBody(c, code, message))
+}
+
+// errorBody 统一的错误响应结构，需要附加字段时在此基础上添加
+func errorBody(c *gin.Context, code errors.ErrorCode, message string) gin.H {
+	return gin.H{
+		"code":     code.Code,
+		"message":  message,
+		"trace_id": GetTraceID(c),
+	}
+}
+
 // ContextFromGin 从 Gin Context 提取 request context
 // 这个 context 包含了 trace_id 和 request_id，可以用于创建 logger
 // 示例用法:
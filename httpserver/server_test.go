@@ -4,8 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -290,6 +296,100 @@ func TestShutdown(t *testing.T) {
 	}
 }
 
+func TestShutdownHooks(t *testing.T) {
+	server := NewServer(nil)
+
+	var order []string
+	workerStopped := false
+	server.AddWorker("consumer", func(ctx context.Context) error {
+		<-ctx.Done()
+		workerStopped = true
+		return nil
+	})
+	hookErr := fmt.Errorf("close failed")
+	server.OnShutdown(func(ctx context.Context) error {
+		order = append(order, "db")
+		if !workerStopped {
+			t.Error("Expected hooks to run after workers stopped")
+		}
+		return nil
+	})
+	server.OnShutdown(func(ctx context.Context) error {
+		order = append(order, "cache")
+		return hookErr
+	})
+	server.workers.start()
+
+	if err := server.Shutdown(context.Background()); err != hookErr {
+		t.Errorf("Expected hook error, got %v", err)
+	}
+	// 按注册的相反顺序执行，出错后其余钩子仍然执行
+	if strings.Join(order, ",") != "cache,db" {
+		t.Errorf("Expected hooks in reverse order, got %v", order)
+	}
+}
+
+func TestRunWithGracefulShutdownContext(t *testing.T) {
+	config := DefaultConfig()
+	config.Host = "127.0.0.1"
+	config.Port = 0
+	config.ShutdownTimeout = 2 * time.Second
+
+	server := NewServer(config)
+	server.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.RunWithGracefulShutdownContext(ctx)
+	}()
+
+	// 等待服务器绑定端口
+	for i := 0; i < 50 && server.Port() == 0; i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if server.Port() == 0 {
+		t.Fatal("Server did not bind a port")
+	}
+	url := fmt.Sprintf("http://127.0.0.1:%d/ping", server.Port())
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("Server did not become ready: %v", err)
+	}
+	resp.Body.Close()
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected no error on shutdown, got %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Server did not shut down after context cancellation")
+	}
+
+	if _, err := http.Get(url); err == nil {
+		t.Error("Expected request to fail after shutdown")
+	}
+}
+
+func TestShutdownSignalsDefault(t *testing.T) {
+	server := NewServer(&Config{})
+	signals := server.shutdownSignals()
+	if len(signals) != 2 || signals[0] != syscall.SIGINT || signals[1] != syscall.SIGTERM {
+		t.Errorf("Expected default signals [SIGINT SIGTERM], got %v", signals)
+	}
+
+	server = NewServer(&Config{ShutdownSignals: []os.Signal{syscall.SIGHUP}})
+	signals = server.shutdownSignals()
+	if len(signals) != 1 || signals[0] != syscall.SIGHUP {
+		t.Errorf("Expected [SIGHUP], got %v", signals)
+	}
+}
+
 func TestMiddlewareExecution(t *testing.T) {
 	server := NewServer(nil)
 	engine := server.Engine()
@@ -609,3 +709,57 @@ func TestContextFromGin(t *testing.T) {
 			response["ctx_request_id"], response["gin_request_id"])
 	}
 }
+
+func TestServerEphemeralPort(t *testing.T) {
+	config := DefaultConfig()
+	config.Host = "127.0.0.1"
+	config.Port = 0
+
+	server := NewServer(config)
+	server.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+	if server.BoundAddr() != nil || server.Port() != 0 {
+		t.Error("Expected no bound address before Start")
+	}
+
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Shutdown(context.Background())
+
+	port := server.Port()
+	if port == 0 {
+		t.Fatal("Expected non-zero ephemeral port after Start")
+	}
+	if want := fmt.Sprintf("127.0.0.1:%d", port); server.Addr() != want || server.BoundAddr().String() != want {
+		t.Errorf("Expected bound address %s, got Addr=%s BoundAddr=%v", want, server.Addr(), server.BoundAddr())
+	}
+
+	// Start 返回前已完成绑定，不需要等待
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/ping", port))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "pong" {
+		t.Errorf("Expected pong, got %q", body)
+	}
+}
+
+func TestServerStartPortInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	config := DefaultConfig()
+	config.Host = "127.0.0.1"
+	config.Port = listener.Addr().(*net.TCPAddr).Port
+
+	if err := NewServer(config).Start(); err == nil {
+		t.Error("Expected Start to return bind error for port in use")
+	}
+}
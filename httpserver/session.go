@@ -0,0 +1,361 @@
+package httpserver
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tsopia/go-kit/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultSessionCookieName 默认会话Cookie名称
+	DefaultSessionCookieName = "session_id"
+	// DefaultSessionTTL 默认会话有效期，每次请求都会顺延
+	DefaultSessionTTL = 24 * time.Hour
+
+	sessionKey      = "session"
+	sessionIDSize   = 32
+	flashesKey      = "_flashes"
+	csrfSessionKey  = "_csrf_token"
+	forwardedProto  = "X-Forwarded-Proto"
+	setCookieHeader = "Set-Cookie"
+)
+
+// SessionConfig 会话中间件配置
+type SessionConfig struct {
+	CookieName   string        // Cookie名称，默认 session_id
+	CookiePath   string        // Cookie路径，默认 /
+	CookieDomain string        // Cookie域名
+	TTL          time.Duration // 会话有效期，每次请求顺延，默认 24 小时
+	// Secure 是否仅通过HTTPS发送Cookie，nil 时按请求是否为HTTPS（含 X-Forwarded-Proto）判断
+	Secure   *bool
+	SameSite http.SameSite // SameSite策略，默认 Lax
+}
+
+// withDefaults 填充默认值
+func (cfg SessionConfig) withDefaults() SessionConfig {
+	if cfg.CookieName == "" {
+		cfg.CookieName = DefaultSessionCookieName
+	}
+	if cfg.CookiePath == "" {
+		cfg.CookiePath = "/"
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultSessionTTL
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+	return cfg
+}
+
+// SessionData 当前请求的会话，通过 Session 获取，修改后需要调用 Save 才会保存
+//
+// 同一请求内只能在一个协程中使用。
+type SessionData struct {
+	c      *gin.Context
+	store  SessionStore
+	codec  cookieSessionCodec // 签名Cookie模式，为nil时使用服务端存储
+	cfg    SessionConfig
+	id     string
+	oldID  string // RenewID 之前的会话ID，保存时从存储中删除
+	values map[string]interface{}
+}
+
+// SessionMiddleware 服务端会话中间件
+//
+// 从Cookie中读取会话ID并加载会话，已有会话的有效期在每次请求时顺延（SessionStore.Touch）。
+// store 为 NewCookieSessionStore 创建的存储时，会话数据经签名后直接保存在Cookie中（无状态模式）。
+// Cookie 默认 HttpOnly、SameSite=Lax，HTTPS 请求时带 Secure。
+// 无效、过期或被篡改的会话视为新会话；存储返回其他错误时返回 500。
+//
+// 示例:
+//
+//	store := httpserver.NewMemorySessionStore(0)
+//	defer store.Close()
+//	server.Use(httpserver.SessionMiddleware(store, httpserver.SessionConfig{TTL: 2 * time.Hour}))
+//
+//	server.POST("/login", func(c *gin.Context) {
+//	    sess := httpserver.Session(c)
+//	    sess.Set("user_id", user.ID)
+//	    if err := sess.RenewID(); err != nil { // 登录后更换会话ID，防止会话固定攻击
+//	        c.Status(http.StatusInternalServerError)
+//	        return
+//	    }
+//	    sess.AddFlash("欢迎回来")
+//	    sess.Save()
+//	})
+func SessionMiddleware(store SessionStore, cfg SessionConfig) gin.HandlerFunc {
+	cfg = cfg.withDefaults()
+	codec, _ := store.(cookieSessionCodec)
+
+	return func(c *gin.Context) {
+		sess := &SessionData{c: c, store: store, codec: codec, cfg: cfg, values: map[string]interface{}{}}
+		if err := sess.load(); err != nil {
+			abortWithError(c, http.StatusInternalServerError, errors.CodeInternalServer, "加载会话失败")
+			return
+		}
+		c.Set(sessionKey, sess)
+		c.Next()
+	}
+}
+
+// Session 返回当前请求的会话，未注册 SessionMiddleware 时返回nil
+func Session(c *gin.Context) *SessionData {
+	value, exists := c.Get(sessionKey)
+	if !exists {
+		return nil
+	}
+	sess, _ := value.(*SessionData)
+	return sess
+}
+
+// load 从Cookie加载会话，并顺延已有会话的有效期
+func (s *SessionData) load() error {
+	cookie, err := s.c.Cookie(s.cfg.CookieName)
+	if err != nil || cookie == "" {
+		return nil
+	}
+
+	if s.codec != nil {
+		payload, err := s.codec.decode(s.cfg.CookieName, cookie)
+		if err != nil {
+			return nil
+		}
+		s.id = payload.ID
+		if payload.Values != nil {
+			s.values = payload.Values
+		}
+		// 重新签发以顺延有效期，数据没有变化，不会超出大小上限
+		return s.writeSignedCookie()
+	}
+
+	ctx := s.c.Request.Context()
+	values, err := s.store.Get(ctx, cookie)
+	if isSessionNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s.id = cookie
+	s.values = values
+	if err := s.store.Touch(ctx, cookie, s.cfg.TTL); err != nil && !isSessionNotFound(err) {
+		return err
+	}
+	s.setCookie(cookie, int(s.cfg.TTL/time.Second))
+	return nil
+}
+
+// ID 返回会话ID，新会话在第一次 Save 之前为空
+func (s *SessionData) ID() string {
+	return s.id
+}
+
+// IsNew 判断是否为本次请求新建的会话（尚未保存）
+func (s *SessionData) IsNew() bool {
+	return s.id == ""
+}
+
+// Get 获取会话值
+func (s *SessionData) Get(key string) (interface{}, bool) {
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// GetString 获取字符串值，不存在或类型不符时返回空字符串
+func (s *SessionData) GetString(key string) string {
+	value, _ := s.values[key].(string)
+	return value
+}
+
+// GetInt 获取整数值，兼容签名Cookie模式下JSON解码得到的 float64
+func (s *SessionData) GetInt(key string) (int, bool) {
+	switch value := s.values[key].(type) {
+	case int:
+		return value, true
+	case int64:
+		return int(value), true
+	case float64:
+		return int(value), value == float64(int(value))
+	case json.Number:
+		n, err := value.Int64()
+		return int(n), err == nil
+	}
+	return 0, false
+}
+
+// GetBool 获取布尔值，不存在或类型不符时返回false
+func (s *SessionData) GetBool(key string) bool {
+	value, _ := s.values[key].(bool)
+	return value
+}
+
+// Set 设置会话值，签名Cookie模式下值必须可以JSON编码
+func (s *SessionData) Set(key string, value interface{}) {
+	s.values[key] = value
+}
+
+// Delete 删除会话值
+func (s *SessionData) Delete(key string) {
+	delete(s.values, key)
+}
+
+// Clear 删除所有会话值，会话ID保持不变
+func (s *SessionData) Clear() {
+	s.values = map[string]interface{}{}
+}
+
+// AddFlash 添加一次性消息，通常在重定向前调用，保存后在下一个请求中通过 Flashes 读取
+func (s *SessionData) AddFlash(message string) {
+	s.values[flashesKey] = append(s.Peek(), message)
+}
+
+// Peek 返回一次性消息但不移除
+func (s *SessionData) Peek() []string {
+	var flashes []string
+	switch stored := s.values[flashesKey].(type) {
+	case []string:
+		flashes = append(flashes, stored...)
+	case []interface{}: // 签名Cookie模式下JSON解码的结果
+		for _, item := range stored {
+			if message, ok := item.(string); ok {
+				flashes = append(flashes, message)
+			}
+		}
+	}
+	return flashes
+}
+
+// Flashes 返回并移除一次性消息，需要调用 Save 才会从存储中移除
+func (s *SessionData) Flashes() []string {
+	flashes := s.Peek()
+	delete(s.values, flashesKey)
+	return flashes
+}
+
+// RenewID 更换会话ID并立即保存，旧ID随即失效，用于登录等权限变化时防止会话固定攻击
+//
+// 会话中通过 CSRFConfig.UseSession 保存的CSRF令牌同时被移除，
+// 需要时调用 RegenerateCSRFToken 签发新令牌。
+func (s *SessionData) RenewID() error {
+	if s.codec == nil && s.id != "" && s.oldID == "" {
+		s.oldID = s.id
+	}
+	s.id = ""
+	delete(s.values, csrfSessionKey)
+	return s.Save()
+}
+
+// Save 保存会话并写入Cookie，需要在写入响应体之前调用
+func (s *SessionData) Save() error {
+	if s.id == "" {
+		id, err := randomToken(sessionIDSize)
+		if err != nil {
+			return err
+		}
+		s.id = id
+	}
+
+	if s.codec != nil {
+		return s.writeSignedCookie()
+	}
+
+	ctx := s.c.Request.Context()
+	if s.oldID != "" {
+		if err := s.store.Delete(ctx, s.oldID); err != nil {
+			return fmt.Errorf("删除旧会话失败: %w", err)
+		}
+		s.oldID = ""
+	}
+	if err := s.store.Set(ctx, s.id, s.values, s.cfg.TTL); err != nil {
+		return fmt.Errorf("保存会话失败: %w", err)
+	}
+	s.setCookie(s.id, int(s.cfg.TTL/time.Second))
+	return nil
+}
+
+// Destroy 删除会话并清除Cookie，用于退出登录
+func (s *SessionData) Destroy() error {
+	if s.codec == nil {
+		ctx := s.c.Request.Context()
+		for _, id := range []string{s.id, s.oldID} {
+			if id == "" {
+				continue
+			}
+			if err := s.store.Delete(ctx, id); err != nil {
+				return fmt.Errorf("删除会话失败: %w", err)
+			}
+		}
+	}
+	s.id, s.oldID = "", ""
+	s.values = map[string]interface{}{}
+	s.setCookie("", -1)
+	return nil
+}
+
+// writeSignedCookie 签名Cookie模式下编码会话数据并写入Cookie
+func (s *SessionData) writeSignedCookie() error {
+	value, err := s.codec.encode(s.cfg.CookieName, cookieSessionPayload{
+		ID:      s.id,
+		Values:  s.values,
+		Expires: time.Now().Add(s.cfg.TTL).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	s.setCookie(value, int(s.cfg.TTL/time.Second))
+	return nil
+}
+
+// setCookie 写入会话Cookie，同一请求中多次写入时只保留最后一次
+func (s *SessionData) setCookie(value string, maxAge int) {
+	header := s.c.Writer.Header()
+	prefix := s.cfg.CookieName + "="
+	kept := header.Values(setCookieHeader)[:0:0]
+	for _, cookie := range header.Values(setCookieHeader) {
+		if !strings.HasPrefix(cookie, prefix) {
+			kept = append(kept, cookie)
+		}
+	}
+	header.Del(setCookieHeader)
+	for _, cookie := range kept {
+		header.Add(setCookieHeader, cookie)
+	}
+
+	secure := isHTTPS(s.c.Request)
+	if s.cfg.Secure != nil {
+		secure = *s.cfg.Secure
+	}
+	http.SetCookie(s.c.Writer, &http.Cookie{
+		Name:     s.cfg.CookieName,
+		Value:    value,
+		Path:     s.cfg.CookiePath,
+		Domain:   s.cfg.CookieDomain,
+		MaxAge:   maxAge,
+		Secure:   secure,
+		HttpOnly: true,
+		SameSite: s.cfg.SameSite,
+	})
+}
+
+// isHTTPS 判断请求是否通过HTTPS到达（直接TLS或反向代理设置的 X-Forwarded-Proto）
+func isHTTPS(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get(forwardedProto), "https")
+}
+
+// randomToken 生成 size 字节的加密安全随机令牌
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成随机令牌失败: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
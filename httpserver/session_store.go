@@ -0,0 +1,271 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultSessionCleanupInterval 内存会话存储清理过期会话的默认间隔
+	DefaultSessionCleanupInterval = time.Minute
+	// DefaultSessionMaxCookieBytes 签名Cookie模式下Cookie值的默认最大字节数（浏览器通常限制为4KB）
+	DefaultSessionMaxCookieBytes = 4000
+)
+
+var (
+	// ErrSessionNotFound 会话不存在或已过期
+	ErrSessionNotFound = errors.New("httpserver: 会话不存在或已过期")
+	// ErrSessionTooLarge 签名Cookie模式下会话数据超出大小上限
+	ErrSessionTooLarge = errors.New("httpserver: 会话数据超出Cookie大小上限")
+	// ErrInvalidSessionCookie 会话Cookie格式错误、签名不匹配或已过期
+	ErrInvalidSessionCookie = errors.New("httpserver: 会话Cookie无效")
+
+	// errSessionRequired 需要会话的功能在未注册 SessionMiddleware 时返回
+	errSessionRequired = errors.New("httpserver: 未注册SessionMiddleware")
+)
+
+// SessionStore 服务端会话存储，实现需要支持并发调用
+type SessionStore interface {
+	// Get 返回会话数据，不存在或已过期时返回 ErrSessionNotFound
+	Get(ctx context.Context, id string) (map[string]interface{}, error)
+	// Set 保存会话数据，ttl 后过期
+	Set(ctx context.Context, id string, values map[string]interface{}, ttl time.Duration) error
+	// Delete 删除会话，会话不存在时不返回错误
+	Delete(ctx context.Context, id string) error
+	// Touch 将会话的过期时间延长为从现在起 ttl
+	Touch(ctx context.Context, id string, ttl time.Duration) error
+}
+
+// isSessionNotFound 判断存储返回的错误是否表示会话不存在
+func isSessionNotFound(err error) bool {
+	return errors.Is(err, ErrSessionNotFound)
+}
+
+// memorySession 内存存储中的一个会话
+type memorySession struct {
+	values  map[string]interface{}
+	expires time.Time
+}
+
+// MemorySessionStore 内存会话存储，后台协程定期清理过期会话，适用于单实例部署
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]memorySession
+	now      func() time.Time
+	stop     chan struct{}
+	once     sync.Once
+}
+
+// NewMemorySessionStore 创建内存会话存储，cleanupInterval<=0 时使用 DefaultSessionCleanupInterval
+// 不再使用时调用 Close 停止清理协程
+func NewMemorySessionStore(cleanupInterval time.Duration) *MemorySessionStore {
+	if cleanupInterval <= 0 {
+		cleanupInterval = DefaultSessionCleanupInterval
+	}
+	s := &MemorySessionStore{
+		sessions: make(map[string]memorySession),
+		now:      time.Now,
+		stop:     make(chan struct{}),
+	}
+	go s.janitor(cleanupInterval)
+	return s
+}
+
+// Get 返回会话数据的副本
+func (s *MemorySessionStore) Get(ctx context.Context, id string) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || !s.now().Before(session.expires) {
+		return nil, ErrSessionNotFound
+	}
+	return copyValues(session.values), nil
+}
+
+// Set 保存会话数据的副本
+func (s *MemorySessionStore) Set(ctx context.Context, id string, values map[string]interface{}, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[id] = memorySession{values: copyValues(values), expires: s.now().Add(ttl)}
+	return nil
+}
+
+// Delete 删除会话
+func (s *MemorySessionStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
+
+// Touch 延长会话的过期时间，会话不存在或已过期时返回 ErrSessionNotFound
+func (s *MemorySessionStore) Touch(ctx context.Context, id string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || !s.now().Before(session.expires) {
+		return ErrSessionNotFound
+	}
+	session.expires = s.now().Add(ttl)
+	s.sessions[id] = session
+	return nil
+}
+
+// Len 返回存储中的会话数（包括尚未清理的过期会话）
+func (s *MemorySessionStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
+
+// Close 停止清理协程
+func (s *MemorySessionStore) Close() error {
+	s.once.Do(func() { close(s.stop) })
+	return nil
+}
+
+func (s *MemorySessionStore) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.deleteExpired()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// deleteExpired 删除所有过期会话
+func (s *MemorySessionStore) deleteExpired() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for id, session := range s.sessions {
+		if !now.Before(session.expires) {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+// copyValues 浅复制会话数据，避免请求之间共享同一个 map
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		copied[key] = value
+	}
+	return copied
+}
+
+// cookieSessionCodec 会话数据保存在Cookie中的存储，由 SessionMiddleware 直接读写Cookie
+type cookieSessionCodec interface {
+	encode(name string, payload cookieSessionPayload) (string, error)
+	decode(name, value string) (cookieSessionPayload, error)
+}
+
+// cookieSessionPayload 签名Cookie中的内容
+type cookieSessionPayload struct {
+	ID      string                 `json:"id"`
+	Values  map[string]interface{} `json:"v,omitempty"`
+	Expires int64                  `json:"exp"` // Unix秒
+}
+
+// CookieSessionStore 无状态会话存储：会话数据经 HMAC-SHA256 签名后直接保存在Cookie中
+//
+// 数据只签名不加密，客户端可以读取内容，不要存放敏感信息；
+// 值经过JSON编码，读取时数字为 float64。篡改、过期或格式错误的Cookie视为新会话。
+// 服务端不保存任何状态，因此无法主动使某个会话失效（销毁只会清除当前客户端的Cookie）。
+type CookieSessionStore struct {
+	secret   []byte
+	maxBytes int
+	now      func() time.Time
+}
+
+// NewCookieSessionStore 创建签名Cookie会话存储，secret 至少32字节
+// maxBytes<=0 时使用 DefaultSessionMaxCookieBytes，编码后超出时 Save 返回 ErrSessionTooLarge
+func NewCookieSessionStore(secret []byte, maxBytes int) (*CookieSessionStore, error) {
+	if len(secret) < 32 {
+		return nil, fmt.Errorf("httpserver: 会话签名密钥至少32字节，当前 %d 字节", len(secret))
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultSessionMaxCookieBytes
+	}
+	return &CookieSessionStore{secret: secret, maxBytes: maxBytes, now: time.Now}, nil
+}
+
+// Get 数据保存在Cookie中，始终返回 ErrSessionNotFound
+func (s *CookieSessionStore) Get(ctx context.Context, id string) (map[string]interface{}, error) {
+	return nil, ErrSessionNotFound
+}
+
+// Set 数据由 SessionMiddleware 写入Cookie，不做任何操作
+func (s *CookieSessionStore) Set(ctx context.Context, id string, values map[string]interface{}, ttl time.Duration) error {
+	return nil
+}
+
+// Delete 不做任何操作
+func (s *CookieSessionStore) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+// Touch 不做任何操作
+func (s *CookieSessionStore) Touch(ctx context.Context, id string, ttl time.Duration) error {
+	return nil
+}
+
+// encode 编码为 base64(JSON).base64(HMAC)，签名包含Cookie名称，防止在不同Cookie之间替换
+func (s *CookieSessionStore) encode(name string, payload cookieSessionPayload) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("编码会话数据失败: %w", err)
+	}
+	body := base64.RawURLEncoding.EncodeToString(data)
+	value := body + "." + base64.RawURLEncoding.EncodeToString(s.sign(name, body))
+	if len(value) > s.maxBytes {
+		return "", fmt.Errorf("%w: %d 字节，上限 %d 字节", ErrSessionTooLarge, len(value), s.maxBytes)
+	}
+	return value, nil
+}
+
+// decode 校验签名和过期时间并解码
+func (s *CookieSessionStore) decode(name, value string) (cookieSessionPayload, error) {
+	var payload cookieSessionPayload
+	if len(value) > s.maxBytes {
+		return payload, ErrInvalidSessionCookie
+	}
+	body, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return payload, ErrInvalidSessionCookie
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, s.sign(name, body)) {
+		return payload, ErrInvalidSessionCookie
+	}
+	data, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil || json.Unmarshal(data, &payload) != nil {
+		return payload, ErrInvalidSessionCookie
+	}
+	if s.now().Unix() >= payload.Expires {
+		return payload, ErrInvalidSessionCookie
+	}
+	return payload, nil
+}
+
+func (s *CookieSessionStore) sign(name, body string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(name))
+	mac.Write([]byte{'|'})
+	mac.Write([]byte(body))
+	return mac.Sum(nil)
+}
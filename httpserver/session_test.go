@@ -0,0 +1,358 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var testSessionSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func newSessionTestServer(store SessionStore, cfg SessionConfig) *Server {
+	server := NewServer(nil)
+	server.Use(SessionMiddleware(store, cfg))
+	server.POST("/login", func(c *gin.Context) {
+		sess := Session(c)
+		sess.Set("user_id", 42)
+		if err := sess.RenewID(); err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		sess.AddFlash("欢迎回来")
+		if err := sess.Save(); err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"id": sess.ID()})
+	})
+	server.GET("/me", func(c *gin.Context) {
+		sess := Session(c)
+		userID, _ := sess.GetInt("user_id")
+		flashes := sess.Flashes()
+		sess.Save()
+		c.JSON(http.StatusOK, gin.H{"user_id": userID, "new": sess.IsNew(), "flashes": flashes})
+	})
+	server.POST("/big", func(c *gin.Context) {
+		sess := Session(c)
+		sess.Set("blob", strings.Repeat("x", 8000))
+		if err := sess.Save(); err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	server.POST("/logout", func(c *gin.Context) {
+		Session(c).Destroy()
+		c.Status(http.StatusOK)
+	})
+	return server
+}
+
+// sessionRequest 发送请求，cookie 非空时携带会话Cookie
+func sessionRequest(server *Server, method, path string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	server.Engine().ServeHTTP(w, req)
+	return w
+}
+
+func responseCookie(w *httptest.ResponseRecorder, name string) *http.Cookie {
+	var found *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == name {
+			found = c
+		}
+	}
+	return found
+}
+
+type meResponse struct {
+	UserID  int      `json:"user_id"`
+	New     bool     `json:"new"`
+	Flashes []string `json:"flashes"`
+}
+
+func decodeMe(t *testing.T, w *httptest.ResponseRecorder) meResponse {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var body meResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return body
+}
+
+func TestSessionMiddleware_MemoryStore(t *testing.T) {
+	store := NewMemorySessionStore(0)
+	defer store.Close()
+	server := newSessionTestServer(store, SessionConfig{})
+
+	w := sessionRequest(server, "POST", "/login", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	cookie := responseCookie(w, DefaultSessionCookieName)
+	if cookie == nil || cookie.Value == "" {
+		t.Fatal("Expected session cookie to be issued")
+	}
+	if store.Len() != 1 {
+		t.Errorf("Expected 1 stored session, got %d", store.Len())
+	}
+
+	body := decodeMe(t, sessionRequest(server, "GET", "/me", cookie))
+	if body.UserID != 42 || body.New {
+		t.Errorf("Expected existing session with user_id 42, got %+v", body)
+	}
+	if len(body.Flashes) != 1 || body.Flashes[0] != "欢迎回来" {
+		t.Errorf("Expected flash message, got %v", body.Flashes)
+	}
+
+	// 一次性消息读取后被移除
+	if body := decodeMe(t, sessionRequest(server, "GET", "/me", cookie)); len(body.Flashes) != 0 {
+		t.Errorf("Expected flashes to be consumed, got %v", body.Flashes)
+	}
+
+	sessionRequest(server, "POST", "/logout", cookie)
+	if store.Len() != 0 {
+		t.Errorf("Expected session to be destroyed, %d remaining", store.Len())
+	}
+	if body := decodeMe(t, sessionRequest(server, "GET", "/me", cookie)); body.UserID != 0 {
+		t.Errorf("Expected destroyed session to be gone, got %+v", body)
+	}
+}
+
+func TestSessionMiddleware_RenewIDInvalidatesOldID(t *testing.T) {
+	store := NewMemorySessionStore(0)
+	defer store.Close()
+	server := newSessionTestServer(store, SessionConfig{})
+
+	// 先建立一个匿名会话，再登录
+	first := responseCookie(sessionRequest(server, "GET", "/me", nil), DefaultSessionCookieName)
+	if first == nil {
+		t.Fatal("Expected anonymous session cookie")
+	}
+	w := sessionRequest(server, "POST", "/login", first)
+	renewed := responseCookie(w, DefaultSessionCookieName)
+	if renewed == nil || renewed.Value == first.Value {
+		t.Fatalf("Expected session ID to change after RenewID, got %v", renewed)
+	}
+	if got := len(w.Result().Cookies()); got != 1 {
+		t.Errorf("Expected a single Set-Cookie header, got %d", got)
+	}
+
+	if _, err := store.Get(context.Background(), first.Value); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected old session ID to be invalid, got %v", err)
+	}
+	if body := decodeMe(t, sessionRequest(server, "GET", "/me", renewed)); body.UserID != 42 {
+		t.Errorf("Expected renewed session to keep values, got %+v", body)
+	}
+}
+
+func TestMemorySessionStore_Expiry(t *testing.T) {
+	store := NewMemorySessionStore(time.Hour)
+	defer store.Close()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	store.Set(ctx, "a", map[string]interface{}{"k": "v"}, time.Minute)
+	store.Set(ctx, "b", map[string]interface{}{}, 2*time.Minute)
+
+	now = now.Add(90 * time.Second)
+	if _, err := store.Get(ctx, "a"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected expired session, got %v", err)
+	}
+	if err := store.Touch(ctx, "b", 2*time.Minute); err != nil {
+		t.Fatalf("Touch failed: %v", err)
+	}
+
+	// 顺延后原本的过期时间已经过了，会话仍然有效
+	now = now.Add(time.Minute)
+	if _, err := store.Get(ctx, "b"); err != nil {
+		t.Errorf("Expected touched session to be valid, got %v", err)
+	}
+
+	store.deleteExpired()
+	if store.Len() != 1 {
+		t.Errorf("Expected expired session to be cleaned up, %d remaining", store.Len())
+	}
+}
+
+func TestSessionMiddleware_CookieAttributes(t *testing.T) {
+	store := NewMemorySessionStore(0)
+	defer store.Close()
+
+	tests := []struct {
+		name       string
+		cfg        SessionConfig
+		tls        bool
+		forwarded  string
+		wantSecure bool
+	}{
+		{name: "plain http", wantSecure: false},
+		{name: "tls", tls: true, wantSecure: true},
+		{name: "forwarded https", forwarded: "https", wantSecure: true},
+		{name: "forced insecure", cfg: SessionConfig{Secure: new(bool)}, tls: true, wantSecure: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newSessionTestServer(store, tt.cfg)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/login", nil)
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.forwarded)
+			}
+			server.Engine().ServeHTTP(w, req)
+
+			cookie := responseCookie(w, DefaultSessionCookieName)
+			if cookie == nil {
+				t.Fatal("Expected session cookie")
+			}
+			if !cookie.HttpOnly {
+				t.Error("Expected session cookie to be HttpOnly")
+			}
+			if cookie.SameSite != http.SameSiteLaxMode {
+				t.Errorf("Expected SameSite=Lax, got %v", cookie.SameSite)
+			}
+			if cookie.Secure != tt.wantSecure {
+				t.Errorf("Secure = %v, want %v", cookie.Secure, tt.wantSecure)
+			}
+		})
+	}
+
+	t.Run("custom name domain and path", func(t *testing.T) {
+		server := newSessionTestServer(store, SessionConfig{
+			CookieName:   "sid",
+			CookieDomain: "example.com",
+			CookiePath:   "/app",
+			TTL:          time.Hour,
+			SameSite:     http.SameSiteStrictMode,
+		})
+		cookie := responseCookie(sessionRequest(server, "POST", "/login", nil), "sid")
+		if cookie == nil {
+			t.Fatal("Expected cookie named sid")
+		}
+		if cookie.Domain != "example.com" || cookie.Path != "/app" || cookie.MaxAge != 3600 {
+			t.Errorf("Unexpected cookie attributes: %+v", cookie)
+		}
+		if cookie.SameSite != http.SameSiteStrictMode {
+			t.Errorf("Expected SameSite=Strict, got %v", cookie.SameSite)
+		}
+	})
+}
+
+func TestSessionMiddleware_CookieStore(t *testing.T) {
+	if _, err := NewCookieSessionStore([]byte("short"), 0); err == nil {
+		t.Fatal("Expected error for short secret")
+	}
+	store, err := NewCookieSessionStore(testSessionSecret, 0)
+	if err != nil {
+		t.Fatalf("NewCookieSessionStore failed: %v", err)
+	}
+	server := newSessionTestServer(store, SessionConfig{})
+
+	cookie := responseCookie(sessionRequest(server, "POST", "/login", nil), DefaultSessionCookieName)
+	if cookie == nil || !strings.Contains(cookie.Value, ".") {
+		t.Fatalf("Expected signed session cookie, got %v", cookie)
+	}
+
+	w := sessionRequest(server, "GET", "/me", cookie)
+	body := decodeMe(t, w)
+	if body.UserID != 42 || len(body.Flashes) != 1 {
+		t.Errorf("Expected round-tripped values, got %+v", body)
+	}
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := *cookie
+		tampered.Value = "x" + cookie.Value[1:]
+		if body := decodeMe(t, sessionRequest(server, "GET", "/me", &tampered)); body.UserID != 0 {
+			t.Errorf("Expected tampered cookie to be rejected, got %+v", body)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		store.now = func() time.Time { return time.Now().Add(DefaultSessionTTL + time.Minute) }
+		defer func() { store.now = time.Now }()
+		if body := decodeMe(t, sessionRequest(server, "GET", "/me", cookie)); body.UserID != 0 {
+			t.Errorf("Expected expired cookie to be rejected, got %+v", body)
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		w := sessionRequest(server, "POST", "/big", nil)
+		if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), ErrSessionTooLarge.Error()) {
+			t.Errorf("Expected ErrSessionTooLarge, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestCSRFMiddleware_UseSession(t *testing.T) {
+	store := NewMemorySessionStore(0)
+	defer store.Close()
+
+	server := NewServer(nil)
+	server.Use(SessionMiddleware(store, SessionConfig{}))
+	server.Use(CSRFMiddleware(CSRFConfig{UseSession: true}))
+	server.GET("/form", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"token": GetCSRFToken(c)})
+	})
+	server.POST("/submit", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := sessionRequest(server, "GET", "/form", nil)
+	if responseCookie(w, DefaultCSRFCookieName) != nil {
+		t.Error("Expected no CSRF cookie in session mode")
+	}
+	cookie := responseCookie(w, DefaultSessionCookieName)
+	if cookie == nil {
+		t.Fatal("Expected session cookie")
+	}
+	var body map[string]string
+	json.Unmarshal(w.Body.Bytes(), &body)
+	token := body["token"]
+	if token == "" {
+		t.Fatal("Expected CSRF token")
+	}
+
+	submit := func(token string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/submit", nil)
+		req.AddCookie(cookie)
+		req.Header.Set(DefaultCSRFHeaderName, token)
+		server.Engine().ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := submit(token); code != http.StatusOK {
+		t.Errorf("Expected valid token to pass, got %d", code)
+	}
+	if code := submit("wrong"); code != http.StatusForbidden {
+		t.Errorf("Expected wrong token to be rejected, got %d", code)
+	}
+
+	t.Run("without session middleware", func(t *testing.T) {
+		server := NewServer(nil)
+		server.Use(CSRFMiddleware(CSRFConfig{UseSession: true}))
+		server.GET("/form", func(c *gin.Context) { c.Status(http.StatusOK) })
+		if w := sessionRequest(server, "GET", "/form", nil); w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package httpserver
+
+import (
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// spaIndexFile 单页应用的入口文件
+	spaIndexFile = "index.html"
+	// immutableCacheControl 带内容哈希的静态资源的缓存策略
+	immutableCacheControl = "public, max-age=31536000, immutable"
+	// indexCacheControl 入口文件每次都需要验证，保证发布后立即生效
+	indexCacheControl = "no-cache"
+)
+
+// hashedAssetPattern 匹配构建工具生成的带哈希文件名，例如
+// main.3f2a1b9c.js、main.3f2a1b9c.chunk.js（CRA）、index-BdF3k2aX.js（Vite）
+var hashedAssetPattern = regexp.MustCompile(`[.-]([A-Za-z0-9_]{8,})(\.chunk)?\.[A-Za-z0-9]+$`)
+
+// spaMount StaticSPA 注册的单页应用
+type spaMount struct {
+	prefix   string
+	root     string
+	excludes []string
+}
+
+// StaticSPA 在 urlPrefix 下提供 root 目录中的静态文件，并支持单页应用的前端路由
+//
+//   - 存在的文件直接返回，带内容哈希的文件设置长期缓存
+//   - 不存在且没有扩展名的路径（前端路由，例如 /app/users/42）返回 index.html
+//   - 不存在的带扩展名路径（例如缺失的 .js）和 excludePrefixes 下的路径照常返回 404
+//
+// excludePrefixes 为空时排除 /api/，显式注册的路由始终优先。
+//
+// 示例:
+//
+//	server.GET("/api/users", listUsers)
+//	server.StaticSPA("/", "./web/dist")
+func (s *Server) StaticSPA(urlPrefix, root string, excludePrefixes ...string) {
+	if len(excludePrefixes) == 0 {
+		excludePrefixes = []string{DefaultAPIVersionPathPrefix}
+	}
+
+	prefix := "/" + strings.Trim(urlPrefix, "/")
+	if prefix != "/" {
+		prefix += "/"
+	}
+
+	s.spaMounts = append(s.spaMounts, spaMount{prefix: prefix, root: root, excludes: excludePrefixes})
+	// 前缀较长的挂载点优先匹配
+	for i := len(s.spaMounts) - 1; i > 0 && len(s.spaMounts[i].prefix) > len(s.spaMounts[i-1].prefix); i-- {
+		s.spaMounts[i], s.spaMounts[i-1] = s.spaMounts[i-1], s.spaMounts[i]
+	}
+}
+
+// serveSPA 未匹配路由时查找单页应用，返回false时由 handleNoRoute 返回404
+func (s *Server) serveSPA(c *gin.Context) bool {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return false
+	}
+
+	urlPath := c.Request.URL.Path
+	for _, mount := range s.spaMounts {
+		if mount.match(urlPath) {
+			return mount.serve(c, urlPath)
+		}
+	}
+	return false
+}
+
+// match 判断路径是否属于该挂载点且不在排除前缀下
+func (m spaMount) match(urlPath string) bool {
+	if urlPath+"/" != m.prefix && !strings.HasPrefix(urlPath, m.prefix) {
+		return false
+	}
+	for _, exclude := range m.excludes {
+		if strings.HasPrefix(urlPath, exclude) || urlPath+"/" == exclude {
+			return false
+		}
+	}
+	return true
+}
+
+// serve 返回静态文件或 index.html，缺失的资源文件返回false
+func (m spaMount) serve(c *gin.Context, urlPath string) bool {
+	rel := strings.TrimPrefix(urlPath, strings.TrimSuffix(m.prefix, "/"))
+	// 清理路径，防止 ../ 访问 root 之外的文件
+	rel = path.Clean("/" + rel)
+
+	if rel != "/" {
+		name := filepath.Join(m.root, filepath.FromSlash(rel))
+		if serveFile(c, name, isHashedAsset(rel)) {
+			return true
+		}
+		// 带扩展名的路径视为资源请求，缺失时返回404，避免把 index.html 当作脚本返回
+		if path.Ext(rel) != "" {
+			return false
+		}
+	}
+
+	return serveFile(c, filepath.Join(m.root, spaIndexFile), false)
+}
+
+// serveFile 返回普通文件，文件不存在或是目录时返回false
+func serveFile(c *gin.Context, name string, hashed bool) bool {
+	f, err := os.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+
+	switch {
+	case hashed:
+		c.Header("Cache-Control", immutableCacheControl)
+	case filepath.Base(name) == spaIndexFile:
+		c.Header("Cache-Control", indexCacheControl)
+	}
+
+	// NoRoute 处理函数中状态码默认为404，返回文件前重置
+	c.Status(http.StatusOK)
+	http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), f)
+	return true
+}
+
+// isHashedAsset 判断文件名是否带内容哈希（哈希部分需包含数字，避免误判普通单词）
+func isHashedAsset(rel string) bool {
+	m := hashedAssetPattern.FindStringSubmatch(path.Base(rel))
+	return m != nil && strings.ContainsAny(m[1], "0123456789")
+}
@@ -0,0 +1,121 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newSPAServer 创建包含 index.html 和构建产物的单页应用目录
+func newSPAServer(t *testing.T, prefix string) *Server {
+	t.Helper()
+
+	root := t.TempDir()
+	files := map[string]string{
+		"index.html":                 "<html>app</html>",
+		"favicon.ico":                "icon",
+		"assets/index-BdF3k2a9.js":   "console.log('app')",
+		"assets/logo.svg":            "<svg/>",
+		"static/js/main.3f2a1b9c.js": "main",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server := NewServer(nil)
+	server.GET("/api/users", func(c *gin.Context) {
+		c.String(http.StatusOK, "users")
+	})
+	server.StaticSPA(prefix, root)
+	return server
+}
+
+func spaRequest(server *Server, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, nil)
+	server.Engine().ServeHTTP(w, req)
+	return w
+}
+
+func TestStaticSPA(t *testing.T) {
+	server := newSPAServer(t, "/")
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		wantStatus   int
+		wantBody     string
+		wantCacheHdr string
+	}{
+		{"root", "GET", "/", http.StatusOK, "<html>app</html>", indexCacheControl},
+		{"deep link", "GET", "/users/42/settings", http.StatusOK, "<html>app</html>", indexCacheControl},
+		{"head deep link", "HEAD", "/users/42", http.StatusOK, "", indexCacheControl},
+		{"plain file", "GET", "/favicon.ico", http.StatusOK, "icon", ""},
+		{"vite hashed asset", "GET", "/assets/index-BdF3k2a9.js", http.StatusOK, "console.log('app')", immutableCacheControl},
+		{"cra hashed asset", "GET", "/static/js/main.3f2a1b9c.js", http.StatusOK, "main", immutableCacheControl},
+		{"unhashed asset", "GET", "/assets/logo.svg", http.StatusOK, "<svg/>", ""},
+		{"missing asset", "GET", "/assets/missing.js", http.StatusNotFound, "", ""},
+		{"api route", "GET", "/api/users", http.StatusOK, "users", ""},
+		{"missing api route", "GET", "/api/orders", http.StatusNotFound, "", ""},
+		{"post deep link", "POST", "/users/42", http.StatusNotFound, "", ""},
+		{"path traversal", "GET", "/../../etc/passwd", http.StatusOK, "<html>app</html>", indexCacheControl},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := spaRequest(server, tt.method, tt.path)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("Expected body %q, got %q", tt.wantBody, w.Body.String())
+			}
+			if got := w.Header().Get("Cache-Control"); got != tt.wantCacheHdr {
+				t.Errorf("Expected Cache-Control %q, got %q", tt.wantCacheHdr, got)
+			}
+		})
+	}
+}
+
+func TestStaticSPAPrefix(t *testing.T) {
+	server := newSPAServer(t, "/app")
+
+	if w := spaRequest(server, "GET", "/app/orders/7"); w.Code != http.StatusOK || w.Body.String() != "<html>app</html>" {
+		t.Errorf("Expected deep link under prefix to return index.html, got %d %q", w.Code, w.Body.String())
+	}
+	if w := spaRequest(server, "GET", "/app"); w.Code != http.StatusOK {
+		t.Errorf("Expected prefix root to return index.html, got %d", w.Code)
+	}
+	if w := spaRequest(server, "GET", "/app/favicon.ico"); w.Body.String() != "icon" {
+		t.Errorf("Expected file under prefix, got %q", w.Body.String())
+	}
+	if w := spaRequest(server, "GET", "/other/page"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected paths outside prefix to 404, got %d", w.Code)
+	}
+}
+
+func TestIsHashedAsset(t *testing.T) {
+	tests := map[string]bool{
+		"main.3f2a1b9c.js":       true,
+		"main.3f2a1b9c.chunk.js": true,
+		"index-BdF3k2a9.css":     true,
+		"index-settings.js":      false,
+		"logo.svg":               false,
+	}
+	for name, want := range tests {
+		if got := isHashedAsset(name); got != want {
+			t.Errorf("isHashedAsset(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
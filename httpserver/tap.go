@@ -0,0 +1,308 @@
+package httpserver
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tsopia/go-kit/constants"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultTapMaxBodyBytes 抓包时每个请求体/响应体保留的默认最大字节数
+const DefaultTapMaxBodyBytes = 64 << 10
+
+// ErrInvalidTap 抓包参数无效
+var ErrInvalidTap = errors.New("httpserver: 抓包参数无效")
+
+// TapMatcher 判断请求是否需要抓包，例如只抓某个租户的请求
+type TapMatcher func(c *gin.Context) bool
+
+// HeaderMatcher 请求头 name 等于 value 时匹配
+func HeaderMatcher(name, value string) TapMatcher {
+	return func(c *gin.Context) bool {
+		return c.GetHeader(name) == value
+	}
+}
+
+// TapExchange 一次抓取的请求/响应
+type TapExchange struct {
+	Route             string            `json:"route"`
+	Method            string            `json:"method"`
+	Path              string            `json:"path"`
+	Query             string            `json:"query,omitempty"`
+	Status            int               `json:"status"`
+	TraceID           string            `json:"trace_id,omitempty"`
+	StartedAt         time.Time         `json:"started_at"`
+	Latency           time.Duration     `json:"latency"`
+	RequestHeaders    map[string]string `json:"request_headers"`
+	RequestBody       string            `json:"request_body"`
+	RequestTruncated  bool              `json:"request_truncated,omitempty"`
+	ResponseHeaders   map[string]string `json:"response_headers"`
+	ResponseBody      string            `json:"response_body"`
+	ResponseTruncated bool              `json:"response_truncated,omitempty"`
+}
+
+// tap 一个路由上启用的抓包
+type tap struct {
+	matcher   TapMatcher
+	remaining atomic.Int64
+	expires   time.Time
+}
+
+// TapRegistry 抓包注册表，运行时按路由启用抓包并收集结果
+//
+// 抓取的内容只保存在内存中，通过 Drain 取出，不会写入常规日志。
+type TapRegistry struct {
+	maxBodyBytes int
+	sensitive    map[string]bool
+	now          func() time.Time
+
+	// taps 路由模板 -> 抓包，写时复制，中间件只做一次原子读取和一次map查找
+	taps atomic.Pointer[map[string]*tap]
+
+	mu        sync.Mutex // 保护 taps 的写入和 exchanges
+	exchanges []TapExchange
+}
+
+// NewTapRegistry 创建抓包注册表
+//
+// maxBodyBytes 为每个请求体/响应体保留的最大字节数，<=0 时使用 DefaultTapMaxBodyBytes。
+// 敏感请求头使用 constants.DefaultSensitiveHeaders（与 httpclient 的 DebugConfig 相同），
+// 可通过 extraSensitiveHeaders 追加。
+func NewTapRegistry(maxBodyBytes int, extraSensitiveHeaders ...string) *TapRegistry {
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultTapMaxBodyBytes
+	}
+	sensitive := make(map[string]bool)
+	for _, header := range append(constants.DefaultSensitiveHeaders(), extraSensitiveHeaders...) {
+		sensitive[http.CanonicalHeaderKey(header)] = true
+	}
+
+	r := &TapRegistry{
+		maxBodyBytes: maxBodyBytes,
+		sensitive:    sensitive,
+		now:          time.Now,
+	}
+	empty := map[string]*tap{}
+	r.taps.Store(&empty)
+	return r
+}
+
+// EnableTap 在路由模板（如 "/users/:id"）上启用抓包
+//
+// 最多抓取 maxBodies 个请求，ttl 到期后自动失效，两者先到为准。
+// matcher 为 nil 时抓取该路由的所有请求。对同一路由再次调用会替换之前的设置。
+func (r *TapRegistry) EnableTap(route string, matcher TapMatcher, maxBodies int, ttl time.Duration) error {
+	if route == "" || maxBodies <= 0 || ttl <= 0 {
+		return ErrInvalidTap
+	}
+
+	t := &tap{matcher: matcher, expires: r.now().Add(ttl)}
+	t.remaining.Store(int64(maxBodies))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updateTaps(func(taps map[string]*tap) { taps[route] = t })
+	return nil
+}
+
+// DisableTap 关闭路由上的抓包
+func (r *TapRegistry) DisableTap(route string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updateTaps(func(taps map[string]*tap) { delete(taps, route) })
+}
+
+// ActiveTaps 返回当前启用抓包的路由
+func (r *TapRegistry) ActiveTaps() []string {
+	taps := *r.taps.Load()
+	routes := make([]string, 0, len(taps))
+	now := r.now()
+	for route, t := range taps {
+		if now.Before(t.expires) && t.remaining.Load() > 0 {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// Drain 取出并清空已抓取的内容，用于受保护的管理接口
+func (r *TapRegistry) Drain() []TapExchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exchanges := r.exchanges
+	r.exchanges = nil
+	return exchanges
+}
+
+// updateTaps 复制当前的抓包表并修改（调用方需持有 r.mu）
+func (r *TapRegistry) updateTaps(update func(map[string]*tap)) {
+	current := *r.taps.Load()
+	next := make(map[string]*tap, len(current)+1)
+	for route, t := range current {
+		next[route] = t
+	}
+	update(next)
+	r.taps.Store(&next)
+}
+
+// removeTap 移除已失效的抓包，路由已被重新启用时保留新的设置
+func (r *TapRegistry) removeTap(route string, t *tap) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if (*r.taps.Load())[route] != t {
+		return
+	}
+	r.updateTaps(func(taps map[string]*tap) { delete(taps, route) })
+}
+
+// acquire 判断请求是否需要抓包并占用一个名额
+func (r *TapRegistry) acquire(c *gin.Context, route string, t *tap) bool {
+	if !r.now().Before(t.expires) {
+		r.removeTap(route, t)
+		return false
+	}
+	if t.matcher != nil && !t.matcher(c) {
+		return false
+	}
+	remaining := t.remaining.Add(-1)
+	if remaining <= 0 {
+		r.removeTap(route, t)
+	}
+	return remaining >= 0
+}
+
+// record 保存抓取结果
+func (r *TapRegistry) record(exchange TapExchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = append(r.exchanges, exchange)
+}
+
+// maskHeaders 复制请求头并对敏感头脱敏
+func (r *TapRegistry) maskHeaders(headers http.Header) map[string]string {
+	masked := make(map[string]string, len(headers))
+	for key, values := range headers {
+		value := strings.Join(values, ", ")
+		if r.sensitive[http.CanonicalHeaderKey(key)] {
+			value = maskHeaderValue(value)
+		}
+		masked[key] = value
+	}
+	return masked
+}
+
+// maskHeaderValue 脱敏，保留首尾各4个字符（与 httpclient 调试输出一致）
+func maskHeaderValue(value string) string {
+	if len(value) <= 8 {
+		return "****"
+	}
+	return value[:4] + "****" + value[len(value)-4:]
+}
+
+// TapMiddleware 按 TapRegistry 的设置抓取请求和响应
+//
+// 没有启用抓包的路由只有一次map查找的开销。请求体在处理函数读取时同步抓取，
+// 不会提前读取或改变请求体内容；超过大小上限的部分被截断并标记。
+//
+// 示例:
+//
+//	taps := httpserver.NewTapRegistry(0)
+//	server.Use(httpserver.TapMiddleware(taps))
+//
+//	// 管理接口（需要鉴权）
+//	admin.POST("/taps", func(c *gin.Context) {
+//	    taps.EnableTap("/orders/:id", httpserver.HeaderMatcher("X-Tenant-ID", "acme"), 20, 10*time.Minute)
+//	})
+//	admin.GET("/taps", func(c *gin.Context) { c.JSON(http.StatusOK, taps.Drain()) })
+func TapMiddleware(registry *TapRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		t, ok := (*registry.taps.Load())[route]
+		if !ok || !registry.acquire(c, route, t) {
+			c.Next()
+			return
+		}
+
+		start := registry.now()
+		reqBody := &cappedBuffer{limit: registry.maxBodyBytes}
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			c.Request.Body = &teeReadCloser{Reader: io.TeeReader(c.Request.Body, reqBody), Closer: c.Request.Body}
+		}
+		respBody := &cappedBuffer{limit: registry.maxBodyBytes}
+		c.Writer = &tapWriter{ResponseWriter: c.Writer, body: respBody}
+
+		c.Next()
+
+		registry.record(TapExchange{
+			Route:             route,
+			Method:            c.Request.Method,
+			Path:              c.Request.URL.Path,
+			Query:             c.Request.URL.RawQuery,
+			Status:            c.Writer.Status(),
+			TraceID:           GetTraceID(c),
+			StartedAt:         start,
+			Latency:           registry.now().Sub(start),
+			RequestHeaders:    registry.maskHeaders(c.Request.Header),
+			RequestBody:       reqBody.String(),
+			RequestTruncated:  reqBody.truncated,
+			ResponseHeaders:   registry.maskHeaders(c.Writer.Header()),
+			ResponseBody:      respBody.String(),
+			ResponseTruncated: respBody.truncated,
+		})
+	}
+}
+
+// cappedBuffer 最多保留 limit 字节的缓冲区，超出部分丢弃并标记截断
+type cappedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+// teeReadCloser 读取请求体时同步写入抓包缓冲区
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// tapWriter 写入响应时同步写入抓包缓冲区
+type tapWriter struct {
+	gin.ResponseWriter
+	body *cappedBuffer
+}
+
+func (w *tapWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.body.Write(p[:n])
+	return n, err
+}
+
+func (w *tapWriter) WriteString(s string) (int, error) {
+	n, err := w.ResponseWriter.WriteString(s)
+	w.body.Write([]byte(s[:n]))
+	return n, err
+}
+
+// Unwrap 返回被包装的响应写入器
+func (w *tapWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,167 @@
+package httpserver
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTapTestServer(registry *TapRegistry) *Server {
+	server := NewServer(nil)
+	server.Use(TapMiddleware(registry))
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Header("Set-Cookie", "session=abcdefghijklmnop")
+		c.String(http.StatusOK, "echo:%s", body)
+	}
+	server.POST("/orders/:id", echo)
+	server.POST("/other", echo)
+	return server
+}
+
+func postTap(server *Server, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", path, strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	server.Engine().ServeHTTP(w, req)
+	return w
+}
+
+func TestTapActivation(t *testing.T) {
+	registry := NewTapRegistry(0)
+	server := newTapTestServer(registry)
+
+	postTap(server, "/orders/1", "before", nil)
+	if got := registry.Drain(); len(got) != 0 {
+		t.Fatalf("Expected no captures before EnableTap, got %d", len(got))
+	}
+
+	if err := registry.EnableTap("/orders/:id", HeaderMatcher("X-Tenant-ID", "acme"), 2, time.Minute); err != nil {
+		t.Fatalf("EnableTap failed: %v", err)
+	}
+
+	tenant := map[string]string{"X-Tenant-ID": "acme", "Authorization": "Bearer secret-token-123"}
+	w := postTap(server, "/orders/42?expand=items", `{"qty":1}`, tenant)
+	if w.Body.String() != `echo:{"qty":1}` {
+		t.Fatalf("Tap must not change the response, got %q", w.Body.String())
+	}
+	postTap(server, "/orders/43", "other tenant", map[string]string{"X-Tenant-ID": "globex"})
+	postTap(server, "/other", "other route", tenant)
+	postTap(server, "/orders/44", "second", tenant)
+	postTap(server, "/orders/45", "over limit", tenant)
+
+	got := registry.Drain()
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 captures bounded by maxBodies, got %d", len(got))
+	}
+	ex := got[0]
+	if ex.Route != "/orders/:id" || ex.Path != "/orders/42" || ex.Query != "expand=items" || ex.Status != http.StatusOK {
+		t.Errorf("Unexpected exchange metadata: %+v", ex)
+	}
+	if ex.RequestBody != `{"qty":1}` || ex.ResponseBody != `echo:{"qty":1}` {
+		t.Errorf("Unexpected bodies: %q %q", ex.RequestBody, ex.ResponseBody)
+	}
+	if ex.RequestHeaders["Authorization"] != "Bear****-123" {
+		t.Errorf("Expected Authorization to be masked, got %q", ex.RequestHeaders["Authorization"])
+	}
+	if ex.ResponseHeaders["Set-Cookie"] != "sess****mnop" {
+		t.Errorf("Expected Set-Cookie to be masked, got %q", ex.ResponseHeaders["Set-Cookie"])
+	}
+	if got[1].RequestBody != "second" {
+		t.Errorf("Expected second capture, got %q", got[1].RequestBody)
+	}
+	if len(registry.ActiveTaps()) != 0 {
+		t.Errorf("Expected exhausted tap to be removed, got %v", registry.ActiveTaps())
+	}
+	if len(registry.Drain()) != 0 {
+		t.Error("Expected Drain to clear captures")
+	}
+}
+
+func TestTapTTLExpiry(t *testing.T) {
+	registry := NewTapRegistry(0)
+	now := time.Unix(1000, 0)
+	registry.now = func() time.Time { return now }
+	server := newTapTestServer(registry)
+
+	if err := registry.EnableTap("/other", nil, 100, time.Minute); err != nil {
+		t.Fatalf("EnableTap failed: %v", err)
+	}
+	postTap(server, "/other", "a", nil)
+
+	now = now.Add(time.Minute)
+	postTap(server, "/other", "b", nil)
+
+	if got := registry.Drain(); len(got) != 1 || got[0].RequestBody != "a" {
+		t.Errorf("Expected only the capture before expiry, got %+v", got)
+	}
+	if len(registry.ActiveTaps()) != 0 {
+		t.Errorf("Expected expired tap to be removed, got %v", registry.ActiveTaps())
+	}
+}
+
+func TestTapBodyTruncation(t *testing.T) {
+	registry := NewTapRegistry(8)
+	server := newTapTestServer(registry)
+	registry.EnableTap("/other", nil, 1, time.Minute)
+
+	body := strings.Repeat("x", 100)
+	w := postTap(server, "/other", body, nil)
+	if w.Body.String() != "echo:"+body {
+		t.Fatalf("Handler must see the full body, got %d bytes", w.Body.Len())
+	}
+
+	got := registry.Drain()
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 capture, got %d", len(got))
+	}
+	if got[0].RequestBody != "xxxxxxxx" || !got[0].RequestTruncated {
+		t.Errorf("Expected truncated request body, got %q %v", got[0].RequestBody, got[0].RequestTruncated)
+	}
+	if got[0].ResponseBody != "echo:xxx" || !got[0].ResponseTruncated {
+		t.Errorf("Expected truncated response body, got %q %v", got[0].ResponseBody, got[0].ResponseTruncated)
+	}
+}
+
+func TestTapInactivePath(t *testing.T) {
+	registry := NewTapRegistry(0)
+	registry.EnableTap("/orders/:id", nil, 1, time.Minute)
+	middleware := TapMiddleware(registry)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/other", strings.NewReader("body"))
+	writer, body := c.Writer, c.Request.Body
+
+	allocs := testing.AllocsPerRun(100, func() {
+		middleware(c)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected no allocations without an active tap, got %v", allocs)
+	}
+	if c.Writer != writer || c.Request.Body != body {
+		t.Error("Expected request and writer to be untouched without an active tap")
+	}
+	if err := registry.EnableTap("", nil, 1, time.Minute); err != ErrInvalidTap {
+		t.Errorf("Expected ErrInvalidTap, got %v", err)
+	}
+}
+
+func BenchmarkTapMiddlewareInactive(b *testing.B) {
+	registry := NewTapRegistry(0)
+	middleware := TapMiddleware(registry)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		middleware(c)
+	}
+}
@@ -0,0 +1,215 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	kiterrors "github.com/tsopia/go-kit/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultUploadMaxSize 单个上传文件的默认最大字节数
+	DefaultUploadMaxSize = 10 << 20
+	// DefaultUploadMaxMemory 解析 multipart 表单时保存在内存中的默认最大字节数，超出部分写入临时文件
+	DefaultUploadMaxMemory = 8 << 20
+	// uploadFormOverhead 请求体上限在文件上限之外为边界、part 头和其他表单字段预留的字节数
+	uploadFormOverhead = 1 << 20
+	// sniffLen http.DetectContentType 最多读取的字节数
+	sniffLen = 512
+)
+
+var (
+	// ErrUploadMissing 请求中没有指定字段的文件
+	ErrUploadMissing = errors.New("httpserver: 缺少上传文件")
+	// ErrUploadTooLarge 上传文件或请求体超出大小上限
+	ErrUploadTooLarge = errors.New("httpserver: 上传文件超出大小上限")
+	// ErrUploadTypeNotAllowed 上传文件的内容类型不在允许列表中
+	ErrUploadTypeNotAllowed = errors.New("httpserver: 上传文件类型不允许")
+	// ErrUploadInvalid 请求不是合法的 multipart 表单
+	ErrUploadInvalid = errors.New("httpserver: 上传请求无效")
+)
+
+// UploadError UploadFile 的错误，Unwrap 返回 ErrUploadMissing 等哨兵错误
+type UploadError struct {
+	Field  string // 表单字段
+	Status int    // 已写出的HTTP状态码
+	Err    error  // 哨兵错误
+	Detail string // 补充说明，例如检测到的类型
+}
+
+func (e *UploadError) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("%v: %s", e.Err, e.Field)
+	}
+	return fmt.Sprintf("%v: %s (%s)", e.Err, e.Field, e.Detail)
+}
+
+func (e *UploadError) Unwrap() error {
+	return e.Err
+}
+
+// UploadOptions 上传限制
+type UploadOptions struct {
+	// MaxSize 文件最大字节数，默认 DefaultUploadMaxSize
+	MaxSize int64
+	// AllowedTypes 允许的MIME类型，支持 "image/*" 通配；为空时不限制
+	// 类型由文件内容嗅探得出（http.DetectContentType），不信任扩展名和客户端声明的 Content-Type
+	AllowedTypes []string
+	// MaxMemory 解析表单时保存在内存中的最大字节数，默认 DefaultUploadMaxMemory
+	MaxMemory int64
+}
+
+// withDefaults 填充默认值
+func (o UploadOptions) withDefaults() UploadOptions {
+	if o.MaxSize <= 0 {
+		o.MaxSize = DefaultUploadMaxSize
+	}
+	if o.MaxMemory <= 0 {
+		o.MaxMemory = DefaultUploadMaxMemory
+	}
+	return o
+}
+
+// UploadedFile 已通过检查的上传文件，读取位置在文件开头，使用完毕后调用 Close
+type UploadedFile struct {
+	multipart.File
+	// Filename 客户端提供的文件名（不含路径），保存到磁盘前仍应自行生成文件名
+	Filename string
+	// Size 文件字节数
+	Size int64
+	// ContentType 由内容嗅探得出的MIME类型（不含参数）
+	ContentType string
+	// DeclaredType 客户端在 part 头中声明的 Content-Type，仅供参考
+	DeclaredType string
+	// Header 原始的 part 头
+	Header *multipart.FileHeader
+}
+
+// UploadFile 读取 multipart 表单中 field 字段的文件，并检查大小和内容类型
+//
+// 检查失败时写出错误响应并中止后续处理函数，返回 *UploadError:
+//   - 缺少文件或表单格式错误: 400
+//   - 超出 MaxSize: 413（请求体按 MaxSize 加表单开销限制读取，不会把超大请求读完）
+//   - 类型不在 AllowedTypes 中: 415
+//
+// 示例:
+//
+//	file, err := httpserver.UploadFile(c, "avatar", httpserver.UploadOptions{
+//	    MaxSize:      2 << 20,
+//	    AllowedTypes: []string{"image/png", "image/jpeg"},
+//	})
+//	if err != nil {
+//	    return // 已写出错误响应
+//	}
+//	defer file.Close()
+//	err = storage.Put(ctx, newObjectKey(), file, file.Size, file.ContentType)
+func UploadFile(c *gin.Context, field string, opts UploadOptions) (*UploadedFile, error) {
+	opts = opts.withDefaults()
+
+	if c.Request.MultipartForm == nil {
+		if c.Request.ContentLength > opts.MaxSize+uploadFormOverhead {
+			return nil, uploadFailed(c, field, http.StatusRequestEntityTooLarge, ErrUploadTooLarge,
+				fmt.Sprintf("请求体 %d 字节", c.Request.ContentLength))
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, opts.MaxSize+uploadFormOverhead)
+		if err := c.Request.ParseMultipartForm(opts.MaxMemory); err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				return nil, uploadFailed(c, field, http.StatusRequestEntityTooLarge, ErrUploadTooLarge, "")
+			}
+			return nil, uploadFailed(c, field, http.StatusBadRequest, ErrUploadInvalid, err.Error())
+		}
+	}
+
+	file, header, err := c.Request.FormFile(field)
+	if err != nil {
+		if errors.Is(err, http.ErrMissingFile) {
+			return nil, uploadFailed(c, field, http.StatusBadRequest, ErrUploadMissing, "")
+		}
+		return nil, uploadFailed(c, field, http.StatusBadRequest, ErrUploadInvalid, err.Error())
+	}
+	if header.Size > opts.MaxSize {
+		file.Close()
+		return nil, uploadFailed(c, field, http.StatusRequestEntityTooLarge, ErrUploadTooLarge,
+			fmt.Sprintf("%d 字节，上限 %d 字节", header.Size, opts.MaxSize))
+	}
+
+	contentType, err := sniffContentType(file)
+	if err != nil {
+		file.Close()
+		return nil, uploadFailed(c, field, http.StatusBadRequest, ErrUploadInvalid, err.Error())
+	}
+	if !mimeAllowed(contentType, opts.AllowedTypes) {
+		file.Close()
+		return nil, uploadFailed(c, field, http.StatusUnsupportedMediaType, ErrUploadTypeNotAllowed, contentType)
+	}
+
+	return &UploadedFile{
+		File:         file,
+		Filename:     header.Filename,
+		Size:         header.Size,
+		ContentType:  contentType,
+		DeclaredType: header.Header.Get("Content-Type"),
+		Header:       header,
+	}, nil
+}
+
+// sniffContentType 根据文件开头的内容检测MIME类型，并把读取位置恢复到开头
+func sniffContentType(file multipart.File) (string, error) {
+	buf := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(buf[:n]))
+	if err != nil {
+		return "", err
+	}
+	return mediaType, nil
+}
+
+// mimeAllowed 判断类型是否在允许列表中，allowed 为空时全部允许
+func mimeAllowed(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == contentType || pattern == "*/*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok && strings.HasPrefix(contentType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// uploadFailed 写出错误响应并返回 *UploadError
+func uploadFailed(c *gin.Context, field string, status int, sentinel error, detail string) error {
+	uploadErr := &UploadError{Field: field, Status: status, Err: sentinel, Detail: detail}
+
+	var message string
+	switch sentinel {
+	case ErrUploadMissing:
+		message = "缺少上传文件: " + field
+	case ErrUploadTooLarge:
+		message = "上传文件过大"
+	case ErrUploadTypeNotAllowed:
+		message = "不支持的文件类型: " + detail
+	default:
+		message = "上传请求无效"
+	}
+	abortWithError(c, status, kiterrors.CodeInvalidParam, message)
+	return uploadErr
+}
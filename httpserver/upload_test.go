@@ -0,0 +1,151 @@
+package httpserver
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pngHeader PNG 文件签名，足以让 http.DetectContentType 识别为 image/png
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func multipartRequest(t *testing.T, field, filename, declaredType string, content []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	if field != "" {
+		header := make(map[string][]string)
+		header["Content-Disposition"] = []string{`form-data; name="` + field + `"; filename="` + filename + `"`}
+		header["Content-Type"] = []string{declaredType}
+		part, err := w.CreatePart(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(content)
+	}
+	w.WriteField("note", "hello")
+	w.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+// serveUpload 调用 UploadFile 并返回响应和错误
+func serveUpload(t *testing.T, req *http.Request, opts UploadOptions) (*httptest.ResponseRecorder, *UploadedFile, error) {
+	t.Helper()
+	var file *UploadedFile
+	var uploadErr error
+	server := NewServer(nil)
+	server.Engine().POST("/upload", func(c *gin.Context) {
+		file, uploadErr = UploadFile(c, "file", opts)
+		if uploadErr != nil {
+			return
+		}
+		defer file.Close()
+		data, _ := io.ReadAll(file)
+		c.String(http.StatusOK, "%s %d %d", file.ContentType, file.Size, len(data))
+	})
+	w := httptest.NewRecorder()
+	server.Engine().ServeHTTP(w, req)
+	return w, file, uploadErr
+}
+
+func TestUploadFileAccepted(t *testing.T) {
+	content := append(append([]byte{}, pngHeader...), bytes.Repeat([]byte{0}, 1000)...)
+	req := multipartRequest(t, "file", "../../avatar.png", "application/octet-stream", content)
+
+	w, file, err := serveUpload(t, req, UploadOptions{MaxSize: 2048, AllowedTypes: []string{"image/*"}})
+	if err != nil {
+		t.Fatalf("Expected upload to succeed: %v", err)
+	}
+	// 嗅探后读取位置回到开头，完整读出全部内容
+	if want := "image/png 1016 1016"; w.Code != http.StatusOK || w.Body.String() != want {
+		t.Errorf("Expected %q, got %d %q", want, w.Code, w.Body.String())
+	}
+	if file.Filename != "avatar.png" || file.DeclaredType != "application/octet-stream" {
+		t.Errorf("Unexpected metadata: filename=%q declared=%q", file.Filename, file.DeclaredType)
+	}
+}
+
+func TestUploadFileTooLarge(t *testing.T) {
+	content := append(append([]byte{}, pngHeader...), bytes.Repeat([]byte{0}, 4096)...)
+
+	w, _, err := serveUpload(t, multipartRequest(t, "file", "big.png", "image/png", content), UploadOptions{MaxSize: 1024})
+	var uploadErr *UploadError
+	if !errors.Is(err, ErrUploadTooLarge) || !errors.As(err, &uploadErr) || uploadErr.Field != "file" {
+		t.Fatalf("Expected ErrUploadTooLarge, got %v", err)
+	}
+	if w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), `"code":1001`) {
+		t.Errorf("Expected 413 with error body, got %d %s", w.Code, w.Body.String())
+	}
+
+	// 请求体超出 MaxSize 加表单开销时不解析表单，直接拒绝
+	huge := bytes.Repeat([]byte("a"), 2<<20)
+	w, _, err = serveUpload(t, multipartRequest(t, "file", "huge.txt", "text/plain", huge), UploadOptions{MaxSize: 1024})
+	if !errors.Is(err, ErrUploadTooLarge) || w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected oversized request to be rejected, got %d %v", w.Code, err)
+	}
+
+	// 没有 Content-Length 时由 MaxBytesReader 截断读取
+	req := multipartRequest(t, "file", "huge.txt", "text/plain", huge)
+	req.ContentLength = -1
+	w, _, err = serveUpload(t, req, UploadOptions{MaxSize: 1024})
+	if !errors.Is(err, ErrUploadTooLarge) || w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected chunked oversized request to be rejected, got %d %v", w.Code, err)
+	}
+}
+
+func TestUploadFileTypeNotAllowed(t *testing.T) {
+	// 扩展名和声明的类型都是PNG，内容却是HTML
+	content := []byte("<html><script>alert(1)</script></html>")
+	w, _, err := serveUpload(t, multipartRequest(t, "file", "fake.png", "image/png", content),
+		UploadOptions{AllowedTypes: []string{"image/png", "image/jpeg"}})
+	if !errors.Is(err, ErrUploadTypeNotAllowed) {
+		t.Fatalf("Expected ErrUploadTypeNotAllowed, got %v", err)
+	}
+	if w.Code != http.StatusUnsupportedMediaType || !strings.Contains(w.Body.String(), "text/html") {
+		t.Errorf("Expected 415 naming detected type, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUploadFileMissingAndInvalid(t *testing.T) {
+	w, _, err := serveUpload(t, multipartRequest(t, "", "", "", nil), UploadOptions{})
+	if !errors.Is(err, ErrUploadMissing) || w.Code != http.StatusBadRequest {
+		t.Errorf("Expected missing file error, got %d %v", w.Code, err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(`{"file":"x"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w, _, err = serveUpload(t, req, UploadOptions{})
+	if !errors.Is(err, ErrUploadInvalid) || w.Code != http.StatusBadRequest {
+		t.Errorf("Expected invalid request error, got %d %v", w.Code, err)
+	}
+}
+
+func TestMimeAllowed(t *testing.T) {
+	tests := []struct {
+		contentType string
+		allowed     []string
+		want        bool
+	}{
+		{"image/png", nil, true},
+		{"image/png", []string{"image/*"}, true},
+		{"image/png", []string{" IMAGE/PNG "}, true},
+		{"application/pdf", []string{"image/*", "text/plain"}, false},
+		{"imagex/png", []string{"image/*"}, false},
+		{"text/plain", []string{"*/*"}, true},
+	}
+	for _, tt := range tests {
+		if got := mimeAllowed(tt.contentType, tt.allowed); got != tt.want {
+			t.Errorf("mimeAllowed(%q, %v) = %v, want %v", tt.contentType, tt.allowed, got, tt.want)
+		}
+	}
+}
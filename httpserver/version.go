@@ -0,0 +1,134 @@
+package httpserver
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/tsopia/go-kit/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultAPIVersionPathPrefix 默认的版本化路径前缀
+	DefaultAPIVersionPathPrefix = "/api/"
+
+	apiVersionKey = "api_version"
+)
+
+// versionPattern 匹配 v1、v2、v10 等版本号
+var versionPattern = regexp.MustCompile(`^v[0-9]+$`)
+
+// APIVersionConfig API版本中间件配置
+type APIVersionConfig struct {
+	// Vendor Accept 媒体类型中的厂商名，例如 "myapp" 对应 application/vnd.myapp.v2+json
+	// 为空时接受任意厂商名
+	Vendor string
+	// Supported 支持的版本列表，例如 []string{"v1", "v2"}；为空时不限制
+	Supported []string
+	// Default 请求未指定版本时使用的版本，为空时 APIVersion 返回空字符串
+	Default string
+	// PathPrefix URL中版本号之前的路径前缀，默认 /api/（即 /api/v1/...）
+	PathPrefix string
+}
+
+// APIVersionMiddleware API版本中间件
+//
+// 依次从URL路径（/api/v1/...）和 Accept 请求头（application/vnd.myapp.v2+json）解析版本号，
+// 路径中的版本优先。版本不在 Supported 中时返回 406 及包含 trace_id 的JSON错误。
+// 处理函数通过 APIVersion(c) 读取解析结果。
+//
+// 示例:
+//
+//	server.Use(httpserver.APIVersionMiddleware(httpserver.APIVersionConfig{
+//	    Vendor:    "myapp",
+//	    Supported: []string{"v1", "v2"},
+//	    Default:   "v1",
+//	}))
+func APIVersionMiddleware(cfg APIVersionConfig) gin.HandlerFunc {
+	if cfg.PathPrefix == "" {
+		cfg.PathPrefix = DefaultAPIVersionPathPrefix
+	}
+	supported := make(map[string]bool, len(cfg.Supported))
+	for _, v := range cfg.Supported {
+		supported[strings.ToLower(v)] = true
+	}
+
+	return func(c *gin.Context) {
+		version := versionFromPath(c.Request.URL.Path, cfg.PathPrefix)
+		if version == "" {
+			version = versionFromAccept(c.GetHeader("Accept"), cfg.Vendor)
+		}
+		if version == "" {
+			version = cfg.Default
+		}
+
+		if version != "" && len(supported) > 0 && !supported[strings.ToLower(version)] {
+			abortWithError(c, http.StatusNotAcceptable, errors.CodeInvalidParam,
+				fmt.Sprintf("不支持的API版本: %s", version))
+			return
+		}
+
+		Vary(c, "Accept")
+		c.Set(apiVersionKey, version)
+		c.Next()
+	}
+}
+
+// APIVersion 获取 APIVersionMiddleware 解析出的版本号，例如 "v2"
+func APIVersion(c *gin.Context) string {
+	return c.GetString(apiVersionKey)
+}
+
+// versionFromPath 从路径前缀之后的第一段解析版本号
+func versionFromPath(path, prefix string) string {
+	if !strings.HasPrefix(path, prefix) {
+		return ""
+	}
+	segment := strings.TrimPrefix(path, prefix)
+	if i := strings.IndexByte(segment, '/'); i >= 0 {
+		segment = segment[:i]
+	}
+	segment = strings.ToLower(segment)
+	if versionPattern.MatchString(segment) {
+		return segment
+	}
+	return ""
+}
+
+// versionFromAccept 从 Accept 请求头中的 vnd 媒体类型解析版本号
+// 例如 application/vnd.myapp.v2+json -> v2
+func versionFromAccept(accept, vendor string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		subtype := mediaType[strings.IndexByte(mediaType, '/')+1:]
+		if !strings.HasPrefix(subtype, "vnd.") {
+			continue
+		}
+		subtype = strings.TrimPrefix(subtype, "vnd.")
+		if i := strings.IndexByte(subtype, '+'); i >= 0 {
+			subtype = subtype[:i]
+		}
+
+		// subtype 形如 myapp.v2
+		dot := strings.LastIndexByte(subtype, '.')
+		if dot < 0 {
+			continue
+		}
+		name, version := subtype[:dot], subtype[dot+1:]
+		if vendor != "" && !strings.EqualFold(name, vendor) {
+			continue
+		}
+		if versionPattern.MatchString(version) {
+			return version
+		}
+	}
+	return ""
+}
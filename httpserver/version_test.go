@@ -0,0 +1,84 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newVersionTestServer(cfg APIVersionConfig) *Server {
+	server := NewServer(nil)
+	server.Use(APIVersionMiddleware(cfg))
+	handler := func(c *gin.Context) {
+		c.String(http.StatusOK, APIVersion(c))
+	}
+	server.GET("/users", handler)
+	server.GET("/api/:version/users", handler)
+	return server
+}
+
+func TestAPIVersionMiddleware(t *testing.T) {
+	server := newVersionTestServer(APIVersionConfig{
+		Vendor:    "myapp",
+		Supported: []string{"v1", "v2"},
+		Default:   "v1",
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		accept     string
+		wantStatus int
+		wantBody   string
+	}{
+		{"Accept请求头", "/users", "application/vnd.myapp.v2+json", http.StatusOK, "v2"},
+		{"多个媒体类型", "/users", "text/html, application/vnd.myapp.v2+json;q=0.9", http.StatusOK, "v2"},
+		{"URL路径", "/api/v2/users", "", http.StatusOK, "v2"},
+		{"路径优先于请求头", "/api/v1/users", "application/vnd.myapp.v2+json", http.StatusOK, "v1"},
+		{"未指定版本使用默认值", "/users", "application/json", http.StatusOK, "v1"},
+		{"其他厂商名被忽略", "/users", "application/vnd.other.v2+json", http.StatusOK, "v1"},
+		{"不支持的请求头版本", "/users", "application/vnd.myapp.v3+json", http.StatusNotAcceptable, ""},
+		{"不支持的路径版本", "/api/v9/users", "", http.StatusNotAcceptable, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			server.Engine().ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("Expected version %q, got %q", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAPIVersionMiddlewareWithoutRestrictions(t *testing.T) {
+	server := newVersionTestServer(APIVersionConfig{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users", nil)
+	req.Header.Set("Accept", "application/vnd.anything.v7+json")
+	server.Engine().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Body.String() != "v7" {
+		t.Errorf("Expected v7 with status 200, got %q (%d)", w.Body.String(), w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/users", nil)
+	server.Engine().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Body.String() != "" {
+		t.Errorf("Expected empty version, got %q (%d)", w.Body.String(), w.Code)
+	}
+}
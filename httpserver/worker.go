@@ -0,0 +1,369 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WorkerStopOrder 关闭时停止后台工作协程与HTTP连接排空的先后顺序
+type WorkerStopOrder int
+
+const (
+	// WorkersStopWithDrain 取消工作协程的同时排空HTTP连接（默认）
+	WorkersStopWithDrain WorkerStopOrder = iota
+	// WorkersStopBeforeDrain 先停止工作协程，再排空HTTP连接
+	WorkersStopBeforeDrain
+	// WorkersStopAfterDrain 先排空HTTP连接，再停止工作协程（处理中的请求仍可依赖工作协程）
+	WorkersStopAfterDrain
+)
+
+// 工作协程状态
+const (
+	WorkerPending    = "pending"    // 已注册，服务器尚未启动
+	WorkerRunning    = "running"    // 运行中
+	WorkerRestarting = "restarting" // panic 后等待重启
+	WorkerFinished   = "finished"   // 关闭前正常返回
+	WorkerFailed     = "failed"     // 返回错误或 panic 且不再重启
+	WorkerStopped    = "stopped"    // 随服务器关闭而退出
+)
+
+// 重启退避上限，避免重启次数较多时位移溢出导致不再等待
+const (
+	maxRestartBackoffShift = 16
+	maxRestartDelay        = time.Minute
+)
+
+// ErrWorkerPanic 工作协程 panic，重启次数用尽后作为失败原因
+var ErrWorkerPanic = errors.New("工作协程 panic")
+
+// WorkerFunc 后台工作协程，ctx 在服务器开始关闭时取消，应在 ctx 取消后尽快返回
+type WorkerFunc func(ctx context.Context) error
+
+// PanicReporter 上报恢复的 panic，fields 为 key/value 形式的附加字段（例如 worker 名称）
+// logger.NewPanicReporter 返回的函数满足该类型
+type PanicReporter func(recovered interface{}, stack []byte, fields ...interface{})
+
+// WorkerOption 工作协程选项
+type WorkerOption func(*worker)
+
+// WithRestartOnPanic 工作协程 panic 后最多重启 maxRestarts 次，
+// 第 n 次重启前等待 backoff * 2^(n-1)，指数最多为 16，等待时间最长为 1 分钟
+func WithRestartOnPanic(maxRestarts int, backoff time.Duration) WorkerOption {
+	return func(w *worker) {
+		w.maxRestarts = maxRestarts
+		w.backoff = backoff
+	}
+}
+
+// WithFailFast 工作协程在服务器关闭前返回错误（或 panic 且不再重启）时触发服务器关闭
+// 未设置时只记录日志，服务器继续运行
+func WithFailFast() WorkerOption {
+	return func(w *worker) {
+		w.failFast = true
+	}
+}
+
+// WorkerStatus 工作协程状态，用于诊断
+type WorkerStatus struct {
+	Name      string    `json:"name"`
+	State     string    `json:"state"`
+	Restarts  int       `json:"restarts"`
+	LastError string    `json:"last_error,omitempty"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+}
+
+type worker struct {
+	name        string
+	run         WorkerFunc
+	maxRestarts int
+	backoff     time.Duration
+	failFast    bool
+
+	blocksReadiness bool  // WithReadinessGate
+	gate            *Gate // 阻塞就绪时的就绪门，由 AddWorker 注册
+
+	// 以下字段由 workerGroup.mu 保护
+	state     string
+	restarts  int
+	lastErr   error
+	startedAt time.Time
+}
+
+// workerGroup 服务器的后台工作协程
+type workerGroup struct {
+	mu      sync.Mutex
+	workers []*worker
+	started bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	failed  chan error // 触发 fail-fast 的错误，容量为1
+	logger  Logger     // Config.Logger
+
+	reportPanic PanicReporter // Config.PanicReporter
+}
+
+// AddWorker 注册后台工作协程，随服务器启动，并在关闭时取消其 ctx
+//
+// RunWithGracefulShutdown 关闭时最多等待 ShutdownTimeout 让工作协程返回，
+// 停止顺序由 Config.WorkerStopOrder 控制。工作协程中的 panic 会被恢复，
+// 交给 Config.PanicReporter 上报，未设置时连同堆栈以 Error 级别记录到 Config.Logger。
+//
+// 示例:
+//
+//	server.AddWorker("order-consumer", consumer.Run,
+//	    httpserver.WithRestartOnPanic(3, time.Second),
+//	    httpserver.WithFailFast(),
+//	)
+func (s *Server) AddWorker(name string, run WorkerFunc, opts ...WorkerOption) {
+	w := &worker{name: name, run: run, state: WorkerPending}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.blocksReadiness {
+		w.gate = s.ReadinessGate(workerGateName(name))
+		w.gate.MarkUnready(reasonWorkerStarting)
+	}
+
+	g := &s.workers
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.workers = append(g.workers, w)
+	// 服务器已启动时立即运行
+	if g.started && g.ctx.Err() == nil {
+		g.launch(w)
+	}
+}
+
+// WorkerStatus 返回所有工作协程的状态，按注册顺序排列
+func (s *Server) WorkerStatus() []WorkerStatus {
+	g := &s.workers
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	statuses := make([]WorkerStatus, 0, len(g.workers))
+	for _, w := range g.workers {
+		status := WorkerStatus{
+			Name:      w.name,
+			State:     w.state,
+			Restarts:  w.restarts,
+			StartedAt: w.startedAt,
+		}
+		if w.lastErr != nil {
+			status.LastError = w.lastErr.Error()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// start 启动所有工作协程，重复调用无效
+func (g *workerGroup) start() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.started {
+		return
+	}
+	g.started = true
+	g.ctx, g.cancel = context.WithCancel(context.Background())
+	for _, w := range g.workers {
+		g.launch(w)
+	}
+}
+
+// launch 在新的协程中运行工作协程，调用方需持有 g.mu
+func (g *workerGroup) launch(w *worker) {
+	w.state = WorkerRunning
+	w.startedAt = time.Now()
+	g.wg.Add(1)
+	go g.run(w)
+}
+
+// run 运行工作协程直到返回、失败或服务器关闭
+func (g *workerGroup) run(w *worker) {
+	defer g.wg.Done()
+
+	for {
+		panicked, err := g.runOnce(w.context(g.ctx), w)
+
+		g.mu.Lock()
+		switch {
+		case g.ctx.Err() != nil:
+			w.state = WorkerStopped
+			if err != nil && !errors.Is(err, context.Canceled) {
+				w.lastErr = err
+			}
+			g.mu.Unlock()
+			return
+		case panicked && w.restarts < w.maxRestarts:
+			w.restarts++
+			w.lastErr = err
+			w.state = WorkerRestarting
+			delay := w.restartDelay()
+			g.mu.Unlock()
+			w.markUnready(reasonWorkerRestarting)
+
+			g.logger.Warn("工作协程重启", "worker", w.name, "restart", w.restarts, "delay", delay)
+			if !sleepContext(g.ctx, delay) {
+				g.mu.Lock()
+				w.state = WorkerStopped
+				g.mu.Unlock()
+				return
+			}
+			g.mu.Lock()
+			w.state = WorkerRunning
+			w.startedAt = time.Now()
+			g.mu.Unlock()
+			continue
+		case err != nil:
+			w.state = WorkerFailed
+			w.lastErr = err
+			g.mu.Unlock()
+			w.markUnready(err.Error())
+
+			g.logger.Error("工作协程失败", "worker", w.name, "error", err)
+			if w.failFast {
+				g.fail(fmt.Errorf("工作协程 %s 失败: %w", w.name, err))
+			}
+			return
+		default:
+			w.state = WorkerFinished
+			g.mu.Unlock()
+			// 一次性的初始化任务正常返回即完成初始化
+			if w.gate != nil {
+				w.gate.MarkReady()
+			}
+			return
+		}
+	}
+}
+
+// context 返回传给工作协程的 ctx，阻塞就绪时带有就绪门供 WorkerReady 使用
+func (w *worker) context(ctx context.Context) context.Context {
+	if w.gate == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, workerGateKey{}, w.gate)
+}
+
+// markUnready 阻塞就绪的工作协程停止提供服务时标记就绪门
+func (w *worker) markUnready(reason string) {
+	if w.gate != nil {
+		w.gate.MarkUnready(reason)
+	}
+}
+
+// restartDelay 第 w.restarts 次重启前的等待时间，超过上限（或位移溢出）时取 maxRestartDelay
+func (w *worker) restartDelay() time.Duration {
+	delay := w.backoff << min(w.restarts-1, maxRestartBackoffShift)
+	if delay < w.backoff || delay > maxRestartDelay {
+		return maxRestartDelay
+	}
+	return delay
+}
+
+// runOnce 运行一次工作协程，恢复并上报 panic
+func (g *workerGroup) runOnce(ctx context.Context, w *worker) (panicked bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			g.panicked(w, r, debug.Stack())
+			panicked = true
+			err = fmt.Errorf("%w: %v", ErrWorkerPanic, r)
+		}
+	}()
+	return false, w.run(ctx)
+}
+
+// panicked 上报工作协程的 panic
+func (g *workerGroup) panicked(w *worker, recovered interface{}, stack []byte) {
+	if g.reportPanic != nil {
+		g.reportPanic(recovered, stack, "worker", w.name)
+		return
+	}
+	g.logger.Error("工作协程 panic", "worker", w.name, "panic", recovered, "stack", string(stack))
+}
+
+// fail 通知服务器因工作协程失败而关闭，只保留第一个错误
+func (g *workerGroup) fail(err error) {
+	select {
+	case g.failed <- err:
+	default:
+	}
+}
+
+// shutdown 按 order 停止工作协程并调用 drain 排空HTTP连接
+func (g *workerGroup) shutdown(ctx context.Context, order WorkerStopOrder, drain func() error) error {
+	switch order {
+	case WorkersStopBeforeDrain:
+		workerErr := g.stop(ctx)
+		return errors.Join(workerErr, drain())
+	case WorkersStopAfterDrain:
+		drainErr := drain()
+		return errors.Join(drainErr, g.stop(ctx))
+	default:
+		g.cancelAll()
+		drainErr := drain()
+		return errors.Join(drainErr, g.wait(ctx))
+	}
+}
+
+// stop 取消所有工作协程并等待其返回，ctx 到期时返回仍在运行的工作协程
+func (g *workerGroup) stop(ctx context.Context) error {
+	g.cancelAll()
+	return g.wait(ctx)
+}
+
+// cancelAll 取消工作协程的 ctx
+func (g *workerGroup) cancelAll() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.cancel != nil {
+		g.cancel()
+	}
+}
+
+// wait 等待工作协程返回
+func (g *workerGroup) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		g.mu.Lock()
+		var running []string
+		for _, w := range g.workers {
+			if w.state == WorkerRunning || w.state == WorkerRestarting {
+				running = append(running, w.name)
+			}
+		}
+		g.mu.Unlock()
+		return fmt.Errorf("等待工作协程退出超时 [%s]: %w", strings.Join(running, ", "), ctx.Err())
+	}
+}
+
+// sleepContext 等待 d 或 ctx 取消，ctx 取消时返回 false
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,306 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tsopia/go-kit/logger"
+	"github.com/tsopia/go-kit/logger/loggertest"
+)
+
+func newWorkerTestServer(t *testing.T) *Server {
+	t.Helper()
+	config := DefaultConfig()
+	config.Host = "127.0.0.1"
+	config.Port = 0
+	config.ShutdownTimeout = time.Second
+	return NewServer(config)
+}
+
+func waitWorkerState(t *testing.T, server *Server, name, state string) WorkerStatus {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, status := range server.WorkerStatus() {
+			if status.Name == name && status.State == state {
+				return status
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Worker %s did not reach state %s: %+v", name, state, server.WorkerStatus())
+	return WorkerStatus{}
+}
+
+func TestWorkerCleanStop(t *testing.T) {
+	server := newWorkerTestServer(t)
+
+	var stopped atomic.Bool
+	server.AddWorker("ticker", func(ctx context.Context) error {
+		<-ctx.Done()
+		stopped.Store(true)
+		return ctx.Err()
+	})
+
+	if got := server.WorkerStatus(); len(got) != 1 || got[0].State != WorkerPending {
+		t.Fatalf("Expected pending worker before start, got %+v", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.RunWithGracefulShutdownContext(ctx)
+	}()
+	waitWorkerState(t, server, "ticker", WorkerRunning)
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Expected clean shutdown, got %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Server did not shut down")
+	}
+
+	if !stopped.Load() {
+		t.Error("Expected worker to observe cancellation before shutdown returned")
+	}
+	status := server.WorkerStatus()[0]
+	if status.State != WorkerStopped || status.LastError != "" {
+		t.Errorf("Expected stopped worker without error, got %+v", status)
+	}
+}
+
+func TestWorkerStopOrder(t *testing.T) {
+	tests := []struct {
+		order WorkerStopOrder
+		want  string // 排空时工作协程的状态
+	}{
+		{WorkersStopBeforeDrain, WorkerStopped},
+		{WorkersStopAfterDrain, WorkerRunning},
+	}
+
+	for _, tt := range tests {
+		server := newWorkerTestServer(t)
+		server.config.WorkerStopOrder = tt.order
+		server.AddWorker("w", func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})
+		server.workers.start()
+
+		var during string
+		err := server.workers.shutdown(context.Background(), tt.order, func() error {
+			during = server.WorkerStatus()[0].State
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if during != tt.want {
+			t.Errorf("Order %d: expected worker %s during drain, got %s", tt.order, tt.want, during)
+		}
+	}
+}
+
+func TestWorkerShutdownTimeout(t *testing.T) {
+	server := newWorkerTestServer(t)
+	release := make(chan struct{})
+	defer close(release)
+	server.AddWorker("stuck", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	server.workers.start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := server.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "stuck") {
+		t.Errorf("Expected timeout naming stuck worker, got %v", err)
+	}
+}
+
+func TestWorkerPanicRestart(t *testing.T) {
+	server := newWorkerTestServer(t)
+
+	var runs atomic.Int32
+	server.AddWorker("flaky", func(ctx context.Context) error {
+		if runs.Add(1) <= 2 {
+			panic("boom")
+		}
+		<-ctx.Done()
+		return nil
+	}, WithRestartOnPanic(3, time.Millisecond))
+
+	server.AddWorker("fragile", func(ctx context.Context) error {
+		panic("always")
+	}, WithRestartOnPanic(1, time.Millisecond))
+
+	server.workers.start()
+	defer server.Shutdown(context.Background())
+
+	status := waitWorkerState(t, server, "fragile", WorkerFailed)
+	if status.Restarts != 1 || !strings.Contains(status.LastError, "always") {
+		t.Errorf("Expected fragile worker to fail after one restart, got %+v", status)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runs.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	status = waitWorkerState(t, server, "flaky", WorkerRunning)
+	if status.Restarts != 2 {
+		t.Errorf("Expected 2 restarts, got %+v", status)
+	}
+
+	// 非 fail-fast 的失败不会触发关闭
+	select {
+	case err := <-server.workers.failed:
+		t.Errorf("Expected no fail-fast signal, got %v", err)
+	default:
+	}
+}
+
+func TestWorkerRestartDelayBounded(t *testing.T) {
+	w := &worker{}
+	WithRestartOnPanic(100, time.Second)(w)
+
+	prev := time.Duration(0)
+	for w.restarts = 1; w.restarts <= 100; w.restarts++ {
+		delay := w.restartDelay()
+		if delay <= 0 || delay > maxRestartDelay {
+			t.Fatalf("restart %d: expected delay in (0, %v], got %v", w.restarts, maxRestartDelay, delay)
+		}
+		if delay < prev {
+			t.Fatalf("restart %d: expected non-decreasing delay, got %v after %v", w.restarts, delay, prev)
+		}
+		prev = delay
+	}
+	if prev != maxRestartDelay {
+		t.Errorf("Expected delay to settle at %v, got %v", maxRestartDelay, prev)
+	}
+
+	w.restarts = 3
+	if delay := w.restartDelay(); delay != 4*time.Second {
+		t.Errorf("Expected 4s before third restart, got %v", delay)
+	}
+
+	// backoff 本身位移后溢出时同样取上限
+	WithRestartOnPanic(100, time.Duration(1)<<62)(w)
+	w.restarts = 2
+	if delay := w.restartDelay(); delay != maxRestartDelay {
+		t.Errorf("Expected overflowed delay clamped to %v, got %v", maxRestartDelay, delay)
+	}
+}
+
+func TestWorkerPanicLogged(t *testing.T) {
+	logs := &recordingLogger{}
+	config := DefaultConfig()
+	config.ShutdownTimeout = time.Second
+	config.Logger = logs
+	server := NewServer(config)
+
+	server.AddWorker("fragile", func(ctx context.Context) error {
+		panic("boom")
+	})
+	server.workers.start()
+	defer server.Shutdown(context.Background())
+	waitWorkerState(t, server, "fragile", WorkerFailed)
+
+	logs.mu.Lock()
+	defer logs.mu.Unlock()
+	var panics int
+	for _, e := range logs.entries {
+		if e.level != "error" || e.fields["panic"] != "boom" {
+			continue
+		}
+		panics++
+		if e.fields["worker"] != "fragile" {
+			t.Errorf("Expected worker field, got %v", e.fields)
+		}
+		if stack, _ := e.fields["stack"].(string); !strings.Contains(stack, "TestWorkerPanicLogged") {
+			t.Errorf("Expected stack field with the panicking frame, got %q", stack)
+		}
+	}
+	if panics != 1 {
+		t.Errorf("Expected one panic entry, got %d: %+v", panics, logs.entries)
+	}
+}
+
+func TestWorkerPanicReporterDedup(t *testing.T) {
+	log, records := loggertest.NewTestLogger()
+	config := DefaultConfig()
+	config.ShutdownTimeout = time.Second
+	config.PanicReporter = logger.NewPanicReporter(log, time.Minute)
+	server := NewServer(config)
+
+	server.AddWorker("fragile", func(ctx context.Context) error {
+		panic("boom")
+	}, WithRestartOnPanic(3, 0))
+	server.workers.start()
+	defer server.Shutdown(context.Background())
+	waitWorkerState(t, server, "fragile", WorkerFailed)
+
+	// 第一次记录完整信息，之后相同的 panic 只记录计数
+	if full := records.Filter(logger.ErrorLevel, "panic recovered", "worker", "fragile"); len(full) != 1 {
+		t.Errorf("Expected one full panic report, got %d", len(full))
+	}
+	for count := 2; count <= 4; count++ {
+		records.AssertContains(t, logger.WarnLevel, "panic repeated", "worker", "fragile", "panic.count", count)
+	}
+}
+
+func TestWorkerFailFast(t *testing.T) {
+	server := newWorkerTestServer(t)
+
+	errBroken := errors.New("queue unavailable")
+	server.AddWorker("consumer", func(ctx context.Context) error {
+		return errBroken
+	}, WithFailFast())
+
+	var stopped atomic.Bool
+	server.AddWorker("other", func(ctx context.Context) error {
+		<-ctx.Done()
+		stopped.Store(true)
+		return nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.RunWithGracefulShutdownContext(context.Background())
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, errBroken) || !strings.Contains(err.Error(), "consumer") {
+			t.Errorf("Expected worker error to be returned, got %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Expected fail-fast worker to shut down the server")
+	}
+	if !stopped.Load() {
+		t.Error("Expected other workers to be stopped")
+	}
+	if status := server.WorkerStatus()[0]; status.State != WorkerFailed {
+		t.Errorf("Expected consumer to be failed, got %+v", status)
+	}
+}
+
+func TestAddWorkerAfterStart(t *testing.T) {
+	server := newWorkerTestServer(t)
+	server.workers.start()
+
+	server.AddWorker("late", func(ctx context.Context) error {
+		return nil
+	})
+	waitWorkerState(t, server, "late", WorkerFinished)
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
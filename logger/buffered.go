@@ -0,0 +1,254 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultBufferMaxEntries 请求级缓冲默认保留的最大日志条数
+const DefaultBufferMaxEntries = 256
+
+// BufferConfig 请求级缓冲日志配置
+type BufferConfig struct {
+	// Level 缓冲日志记录器的最低级别，通常设为 DebugLevel 以便在请求失败时输出调试日志
+	// 零值为 InfoLevel；低于 WarnLevel 的日志进入缓冲区，Warn 及以上直接输出
+	Level Level
+	// MaxEntries 缓冲区最多保留的条数，超出时丢弃最早的日志并计入溢出计数，0 使用 DefaultBufferMaxEntries
+	MaxEntries int
+	// DisableSummary 丢弃缓冲区时不输出摘要
+	DisableSummary bool
+}
+
+// bufferedEntry 缓冲区中的一条日志，保留原始时间、调用者和字段
+type bufferedEntry struct {
+	core   zapcore.Core // 写入时所用的 core，包含 With 添加的字段
+	ent    zapcore.Entry
+	fields []zapcore.Field
+}
+
+// logBuffer 单个请求的环形日志缓冲区，由该请求派生的所有记录器共享
+type logBuffer struct {
+	mu          sync.Mutex
+	root        zapcore.Core // 父记录器的 core，用于输出摘要和溢出提示
+	entries     []bufferedEntry
+	head        int // 最早一条日志的位置
+	size        int
+	overflow    int
+	passthrough bool // 已输出或已结束，后续日志直接写入父记录器
+}
+
+func newLogBuffer(root zapcore.Core, maxEntries int) *logBuffer {
+	if maxEntries <= 0 {
+		maxEntries = DefaultBufferMaxEntries
+	}
+	return &logBuffer{root: root, entries: make([]bufferedEntry, maxEntries)}
+}
+
+// add 追加一条日志，缓冲区已满时覆盖最早的一条；已切换为直接输出时返回false
+func (b *logBuffer) add(entry bufferedEntry) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.passthrough {
+		return false
+	}
+	capacity := len(b.entries)
+	if b.size == capacity {
+		b.entries[b.head] = entry
+		b.head = (b.head + 1) % capacity
+		b.overflow++
+		return true
+	}
+	b.entries[(b.head+b.size)%capacity] = entry
+	b.size++
+	return true
+}
+
+// take 取出全部缓冲日志并切换为直接输出
+func (b *logBuffer) take() ([]bufferedEntry, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entries := make([]bufferedEntry, 0, b.size)
+	for i := 0; i < b.size; i++ {
+		idx := (b.head + i) % len(b.entries)
+		entries = append(entries, b.entries[idx])
+		b.entries[idx] = bufferedEntry{}
+	}
+	overflow := b.overflow
+	b.head, b.size, b.overflow = 0, 0, 0
+	b.passthrough = true
+	return entries, overflow
+}
+
+func (b *logBuffer) isPassthrough() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.passthrough
+}
+
+// flush 按原始顺序输出缓冲日志
+// 缓冲日志绕过父记录器的级别过滤，调试日志在请求失败时同样输出
+func (b *logBuffer) flush() {
+	entries, overflow := b.take()
+	if overflow > 0 {
+		b.root.Write(zapcore.Entry{
+			Level:   zapcore.WarnLevel,
+			Time:    time.Now(),
+			Message: "请求日志缓冲区已满，最早的日志已丢弃",
+		}, []zapcore.Field{zap.Int("overflow", overflow)})
+	}
+	for _, e := range entries {
+		e.core.Write(e.ent, e.fields)
+	}
+}
+
+// discard 丢弃缓冲日志，返回丢弃的条数和其中因溢出丢弃的条数
+func (b *logBuffer) discard() (int, int) {
+	entries, overflow := b.take()
+	return len(entries) + overflow, overflow
+}
+
+// bufferingCore 将低于 WarnLevel 的日志写入请求缓冲区
+// Error 及以上级别的日志先输出缓冲区，再写入父记录器
+type bufferingCore struct {
+	zapcore.Core
+	buf   *logBuffer
+	level zapcore.Level // 进入缓冲区的最低级别
+}
+
+func (c *bufferingCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= c.level || c.Core.Enabled(lvl)
+}
+
+func (c *bufferingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &bufferingCore{Core: c.Core.With(fields), buf: c.buf, level: c.level}
+}
+
+func (c *bufferingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level >= zapcore.WarnLevel || c.buf.isPassthrough() {
+		if ent.Level >= zapcore.ErrorLevel {
+			c.buf.flush()
+		}
+		return c.Core.Check(ent, ce)
+	}
+	if ent.Level >= c.level {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *bufferingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	entry := bufferedEntry{core: c.Core, ent: ent, fields: append([]zapcore.Field(nil), fields...)}
+	if !c.buf.add(entry) {
+		// Check 之后缓冲区被其他 goroutine 输出，直接写入
+		if c.Core.Enabled(ent.Level) {
+			return c.Core.Write(ent, fields)
+		}
+	}
+	return nil
+}
+
+// BufferedRequestLogger 请求级缓冲日志记录器
+//
+// Debug/Info 日志保存在有界的环形缓冲区中，只在以下情况输出到父记录器：
+// 通过它（或 With 等派生的记录器）记录了 Error 及以上级别的日志、Finish 的状态码 >= 500、
+// 或显式调用 Flush；否则请求结束时丢弃，只输出一行摘要。
+// 输出的日志保留原始时间、调用者和字段。需要始终输出的审计日志使用 Unbuffered。
+type BufferedRequestLogger struct {
+	*Logger
+	buf            *logBuffer
+	disableSummary bool
+}
+
+// NewBufferedRequestLogger 基于父记录器创建请求级缓冲日志记录器，每个请求创建一个
+//
+// 示例:
+//
+//	reqLog := logger.NewBufferedRequestLogger(logger.FromContext(ctx), logger.BufferConfig{Level: logger.DebugLevel})
+//	defer func() { reqLog.Finish(status) }()
+//
+//	reqLog.Debug("解析参数", "params", params) // 请求成功时丢弃
+//	reqLog.Error("下单失败", "error", err)      // 先输出之前的调试日志
+func NewBufferedRequestLogger(parent *Logger, cfg BufferConfig) *BufferedRequestLogger {
+	if parent == nil {
+		parent = defaultLogger
+	}
+
+	captureLevel := convertLevel(cfg.Level)
+	buf := newLogBuffer(parent.zap.Core(), cfg.MaxEntries)
+
+	level := parent.level.Level()
+	if captureLevel < level {
+		level = captureLevel
+	}
+
+	l := &Logger{
+		zap: parent.zap.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return &bufferingCore{Core: core, buf: buf, level: captureLevel}
+		})),
+		level:        zap.NewAtomicLevelAt(level),
+		config:       parent.config,
+		hooks:        parent.hooks,
+		ctx:          parent.ctx,
+		ctxExtractor: parent.ctxExtractor,
+		flusher:      parent.flusher,
+	}
+	l.sugar = l.zap.Sugar()
+
+	return &BufferedRequestLogger{Logger: l, buf: buf, disableSummary: cfg.DisableSummary}
+}
+
+// Flush 立即输出缓冲的日志，之后的日志直接写入父记录器
+func (b *BufferedRequestLogger) Flush() {
+	b.buf.flush()
+}
+
+// Finish 在请求结束时调用：状态码 >= 500 时输出缓冲的日志，否则丢弃并输出一行摘要
+// 调用后缓冲区释放，之后的日志直接写入父记录器
+func (b *BufferedRequestLogger) Finish(status int) {
+	if status >= 500 {
+		b.buf.flush()
+		return
+	}
+
+	dropped, overflow := b.buf.discard()
+	if b.disableSummary || dropped == 0 {
+		return
+	}
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: "请求日志已丢弃"}
+	if ce := b.buf.root.Check(ent, nil); ce != nil {
+		ce.Write(zap.Int("dropped", dropped), zap.Int("overflow", overflow), zap.Int("status", status))
+	}
+}
+
+// Overflow 返回缓冲区溢出而丢弃的日志条数
+func (b *BufferedRequestLogger) Overflow() int {
+	b.buf.mu.Lock()
+	defer b.buf.mu.Unlock()
+	return b.buf.overflow
+}
+
+// Unbuffered 返回跳过请求级缓冲、直接写入父记录器的记录器，保留已添加的字段
+// 用于必须始终输出的审计日志；对未缓冲的记录器调用时返回等价的副本
+func (l *Logger) Unbuffered() *Logger {
+	newLogger := &Logger{
+		zap: l.zap.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			if buffering, ok := core.(*bufferingCore); ok {
+				return buffering.Core
+			}
+			return core
+		})),
+		level:        l.level,
+		config:       l.config,
+		hooks:        l.hooks,
+		ctx:          l.ctx,
+		ctxExtractor: l.ctxExtractor,
+		flusher:      l.flusher,
+	}
+	newLogger.sugar = newLogger.zap.Sugar()
+	return newLogger
+}
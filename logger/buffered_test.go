@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// newObservedLoggerAt 创建输出到内存、指定级别的日志记录器
+func newObservedLoggerAt(level Level) (*Logger, *observer.ObservedLogs) {
+	l, _ := newObservedLogger()
+	core, logs := observer.New(convertLevel(level))
+	l.level = zap.NewAtomicLevelAt(convertLevel(level))
+	l.zap = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(callerSkip))
+	l.sugar = l.zap.Sugar()
+	return l, logs
+}
+
+func TestBufferedRequestLogger_DropOnSuccess(t *testing.T) {
+	parent, logs := newObservedLoggerAt(InfoLevel)
+	reqLog := NewBufferedRequestLogger(parent, BufferConfig{Level: DebugLevel})
+
+	reqLog.Debug("parse params")
+	reqLog.Info("query user")
+	if logs.Len() != 0 {
+		t.Fatalf("Expected entries to be buffered, got %v", messages(logs))
+	}
+
+	reqLog.Finish(200)
+
+	all := logs.All()
+	if len(all) != 1 || all[0].Message != "请求日志已丢弃" {
+		t.Fatalf("Expected a single summary line, got %v", messages(logs))
+	}
+	fields := all[0].ContextMap()
+	if fields["dropped"] != int64(2) || fields["status"] != int64(200) || fields["overflow"] != int64(0) {
+		t.Errorf("Unexpected summary fields: %v", fields)
+	}
+}
+
+func TestBufferedRequestLogger_FlushOnError(t *testing.T) {
+	parent, logs := newObservedLoggerAt(InfoLevel)
+	reqLog := NewBufferedRequestLogger(parent.With("service", "orders"), BufferConfig{Level: DebugLevel})
+
+	reqLog.With("step", 1).Debug("parse params", "id", 42)
+	time.Sleep(5 * time.Millisecond)
+	reqLog.Infof("query user %d", 42)
+	time.Sleep(5 * time.Millisecond)
+	errorTime := time.Now()
+	reqLog.Error("create order failed")
+
+	all := logs.All()
+	want := []string{"parse params", "query user 42", "create order failed"}
+	if got := messages(logs); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+
+	// 调试日志绕过父记录器的 Info 级别
+	if all[0].Level != zapcore.DebugLevel {
+		t.Errorf("Expected debug entry to keep its level, got %s", all[0].Level)
+	}
+	// 保留原始时间
+	if !all[0].Time.Before(all[1].Time) || !all[1].Time.Before(errorTime) {
+		t.Errorf("Expected original timestamps, got %v, %v (error at %v)", all[0].Time, all[1].Time, errorTime)
+	}
+	// 保留 With 字段和调用时字段
+	first := all[0].ContextMap()
+	if first["service"] != "orders" || first["step"] != int64(1) || first["id"] != int64(42) {
+		t.Errorf("Expected fields to be preserved, got %v", first)
+	}
+	if all[0].Caller.File == "" {
+		t.Error("Expected caller to be preserved")
+	}
+
+	// 输出后直接写入
+	reqLog.Info("after error")
+	if logs.Len() != 4 {
+		t.Errorf("Expected entries after flush to pass through, got %v", messages(logs))
+	}
+	reqLog.Finish(200)
+	if logs.Len() != 4 {
+		t.Errorf("Expected no summary after flush, got %v", messages(logs))
+	}
+}
+
+func TestBufferedRequestLogger_FlushOnServerError(t *testing.T) {
+	parent, logs := newObservedLoggerAt(InfoLevel)
+	reqLog := NewBufferedRequestLogger(parent, BufferConfig{Level: DebugLevel})
+
+	reqLog.Debug("step one")
+	reqLog.Finish(503)
+
+	if got := messages(logs); len(got) != 1 || got[0] != "step one" {
+		t.Errorf("Expected buffered entry on 5xx, got %v", got)
+	}
+}
+
+func TestBufferedRequestLogger_ExplicitFlushAndWarn(t *testing.T) {
+	parent, logs := newObservedLoggerAt(DebugLevel)
+	reqLog := NewBufferedRequestLogger(parent, BufferConfig{})
+
+	reqLog.Debug("below capture level")
+	reqLog.Info("buffered")
+	reqLog.Warn("warn passes through")
+	if got := messages(logs); len(got) != 1 || got[0] != "warn passes through" {
+		t.Fatalf("Expected only the warning, got %v", got)
+	}
+
+	reqLog.Flush()
+	if got := messages(logs); len(got) != 2 || got[1] != "buffered" {
+		t.Errorf("Expected Flush to emit the buffered entry, got %v", got)
+	}
+}
+
+func TestBufferedRequestLogger_Overflow(t *testing.T) {
+	parent, logs := newObservedLoggerAt(InfoLevel)
+	reqLog := NewBufferedRequestLogger(parent, BufferConfig{Level: DebugLevel, MaxEntries: 3})
+
+	for i := 0; i < 10; i++ {
+		reqLog.Debug(fmt.Sprintf("entry %d", i))
+	}
+	if reqLog.Overflow() != 7 {
+		t.Errorf("Expected overflow 7, got %d", reqLog.Overflow())
+	}
+
+	reqLog.Error("boom")
+
+	want := []string{"请求日志缓冲区已满，最早的日志已丢弃", "entry 7", "entry 8", "entry 9", "boom"}
+	if got := messages(logs); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	if logs.All()[0].ContextMap()["overflow"] != int64(7) {
+		t.Errorf("Expected overflow field, got %v", logs.All()[0].ContextMap())
+	}
+}
+
+func TestBufferedRequestLogger_OverflowSummary(t *testing.T) {
+	parent, logs := newObservedLoggerAt(InfoLevel)
+	reqLog := NewBufferedRequestLogger(parent, BufferConfig{Level: DebugLevel, MaxEntries: 2})
+
+	for i := 0; i < 5; i++ {
+		reqLog.Info("noise")
+	}
+	reqLog.Finish(204)
+
+	fields := logs.All()[0].ContextMap()
+	if fields["dropped"] != int64(5) || fields["overflow"] != int64(3) {
+		t.Errorf("Unexpected summary fields: %v", fields)
+	}
+}
+
+func TestBufferedRequestLogger_Unbuffered(t *testing.T) {
+	parent, logs := newObservedLoggerAt(InfoLevel)
+	reqLog := NewBufferedRequestLogger(parent, BufferConfig{Level: DebugLevel, DisableSummary: true})
+
+	audit := reqLog.With("user", "alice").Unbuffered()
+	audit.Info("permission granted")
+	reqLog.Info("buffered")
+
+	all := logs.All()
+	if len(all) != 1 || all[0].Message != "permission granted" || all[0].ContextMap()["user"] != "alice" {
+		t.Fatalf("Expected audit event to bypass the buffer, got %v", messages(logs))
+	}
+
+	audit.Debug("filtered by parent level")
+	reqLog.Finish(200)
+	if logs.Len() != 1 {
+		t.Errorf("Expected no further output, got %v", messages(logs))
+	}
+}
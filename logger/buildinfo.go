@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"runtime/debug"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// BuildInfo 构建信息，作为 service.version、service.commit、service.build_time 字段输出
+type BuildInfo struct {
+	Version   string // 版本号，例如 v1.2.3
+	Commit    string // 提交哈希
+	BuildTime string // 构建时间
+}
+
+// ReadBuildInfo 从 runtime/debug.ReadBuildInfo 读取主模块版本和VCS信息
+// 无法读取时返回空的 BuildInfo；通过 -ldflags 注入版本的项目应直接构造 BuildInfo
+func ReadBuildInfo() *BuildInfo {
+	bi := &BuildInfo{}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return bi
+	}
+	bi.Version = info.Main.Version
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			bi.Commit = setting.Value
+		case "vcs.time":
+			bi.BuildTime = setting.Value
+		}
+	}
+	return bi
+}
+
+// fields 返回非空的构建信息字段
+func (bi *BuildInfo) fields() []zap.Field {
+	if bi == nil {
+		return nil
+	}
+	fields := make([]zap.Field, 0, 3)
+	if bi.Version != "" {
+		fields = append(fields, zap.String("service.version", bi.Version))
+	}
+	if bi.Commit != "" {
+		fields = append(fields, zap.String("service.commit", bi.Commit))
+	}
+	if bi.BuildTime != "" {
+		fields = append(fields, zap.String("service.build_time", bi.BuildTime))
+	}
+	return fields
+}
+
+// buildInfo 返回选项中生效的构建信息：优先使用 BuildInfo，
+// 否则在 IncludeBuildInfo 为 true 时从构建信息中读取
+func (o Options) buildInfo() *BuildInfo {
+	if o.BuildInfo != nil {
+		return o.BuildInfo
+	}
+	if o.IncludeBuildInfo {
+		return ReadBuildInfo()
+	}
+	return nil
+}
+
+// globalBuildInfo SetGlobalBuildInfo 派生的全局日志记录器及其来源
+var globalBuildInfo = struct {
+	mu      sync.Mutex
+	base    *Logger // 添加构建信息之前的全局日志记录器
+	derived *Logger // 添加构建信息之后的全局日志记录器
+}{}
+
+// SetGlobalBuildInfo 为全局日志记录器的每条日志添加构建信息字段
+//
+// 重复调用时替换之前设置的构建信息而不是叠加；传入nil移除。
+// 通过 Init 或 SetDefaultLogger 替换全局实例后需要重新调用。
+//
+// 示例:
+//
+//	logger.SetGlobalBuildInfo(&logger.BuildInfo{Version: version, Commit: commit})
+func SetGlobalBuildInfo(bi *BuildInfo) {
+	globalBuildInfo.mu.Lock()
+	defer globalBuildInfo.mu.Unlock()
+
+	base := defaultLogger
+	if base == globalBuildInfo.derived && globalBuildInfo.base != nil {
+		base = globalBuildInfo.base
+	}
+
+	derived := base
+	if fields := bi.fields(); len(fields) > 0 {
+		derived = base.withZapFields(fields)
+	}
+	globalBuildInfo.base = base
+	globalBuildInfo.derived = derived
+	defaultLogger = derived
+}
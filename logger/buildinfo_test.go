@@ -0,0 +1,61 @@
+package logger
+
+import "testing"
+
+func TestBuildInfoFields(t *testing.T) {
+	l, read := newLimitedLogger(t, Options{
+		BuildInfo: &BuildInfo{Version: "v1.2.3", Commit: "abc123", BuildTime: "2026-01-02T03:04:05Z"},
+	})
+	l.Named("db").With("k", "v").Info("with build info")
+
+	entry := read()[0]
+	want := map[string]string{
+		"service.version":    "v1.2.3",
+		"service.commit":     "abc123",
+		"service.build_time": "2026-01-02T03:04:05Z",
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("Expected %s=%s, got %v", key, value, entry[key])
+		}
+	}
+}
+
+func TestIncludeBuildInfoReadsRuntime(t *testing.T) {
+	bi := ReadBuildInfo()
+	if bi.Version == "" {
+		t.Skip("build info unavailable")
+	}
+
+	l, read := newLimitedLogger(t, Options{IncludeBuildInfo: true})
+	l.Info("runtime build info")
+
+	if got := read()[0]["service.version"]; got != bi.Version {
+		t.Errorf("Expected service.version %q, got %v", bi.Version, got)
+	}
+}
+
+func TestSetGlobalBuildInfo(t *testing.T) {
+	original := defaultLogger
+	defer func() { defaultLogger = original }()
+
+	l, read := newLimitedLogger(t, Options{})
+	SetDefaultLogger(l)
+
+	SetGlobalBuildInfo(&BuildInfo{Version: "v1"})
+	SetGlobalBuildInfo(&BuildInfo{Version: "v2", Commit: "def"})
+	Info("global")
+	SetGlobalBuildInfo(nil)
+	Info("cleared")
+
+	entries := read()
+	if entries[0]["service.version"] != "v2" || entries[0]["service.commit"] != "def" {
+		t.Errorf("Expected latest build info, got %v", entries[0])
+	}
+	if _, ok := entries[1]["service.version"]; ok {
+		t.Errorf("Expected build info removed, got %v", entries[1])
+	}
+	if defaultLogger != l {
+		t.Error("Expected nil build info to restore the original logger")
+	}
+}
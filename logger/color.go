@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"io"
+	"os"
+)
+
+// ColorMode 控制台格式的颜色模式
+type ColorMode int
+
+const (
+	// ColorAuto 输出目标是终端且未设置 NO_COLOR、TERM 不为 dumb 时使用颜色（默认）
+	ColorAuto ColorMode = iota
+	// ColorAlways 始终使用颜色，例如通过支持颜色的分页器查看时
+	ColorAlways
+	// ColorNever 从不使用颜色
+	ColorNever
+)
+
+// String 返回颜色模式字符串
+func (m ColorMode) String() string {
+	switch m {
+	case ColorAlways:
+		return "always"
+	case ColorNever:
+		return "never"
+	default:
+		return "auto"
+	}
+}
+
+// ParseColorMode 解析颜色模式，无法识别时返回 ColorAuto
+func ParseColorMode(mode string) ColorMode {
+	switch mode {
+	case "always":
+		return ColorAlways
+	case "never":
+		return ColorNever
+	default:
+		return ColorAuto
+	}
+}
+
+// isTerminal 判断写入目标是否为终端，测试中可替换
+var isTerminal = func(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// useColor 判断写入 w 的日志是否使用颜色，只有 FormatConsole 可能使用颜色
+func (o Options) useColor(w io.Writer) bool {
+	if o.Format != FormatConsole {
+		return false
+	}
+	switch o.Color {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	// https://no-color.org: 设置了非空的 NO_COLOR 时不输出颜色
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(w)
+}
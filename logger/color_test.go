@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const ansiEscape = "\x1b["
+
+// fakeTerminal 把指定的写入目标视为终端
+func fakeTerminal(t *testing.T, terminal io.Writer) {
+	t.Helper()
+	original := isTerminal
+	isTerminal = func(w io.Writer) bool { return w == terminal }
+	t.Cleanup(func() { isTerminal = original })
+}
+
+func clearColorEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "xterm-256color")
+}
+
+func TestColorOnlyReachesTerminalSink(t *testing.T) {
+	clearColorEnv(t)
+	var stdout bytes.Buffer
+	fakeTerminal(t, &stdout)
+
+	logFile := filepath.Join(t.TempDir(), "app.log")
+	log := NewWithOptions(Options{
+		Level:            InfoLevel,
+		Format:           FormatConsole,
+		Output:           &stdout,
+		EnableFileOutput: true,
+		Rotate:           &RotateConfig{Filename: logFile, MaxSize: 1},
+	})
+	log.Warn("disk almost full", "usage", 0.93)
+	log.Sync()
+
+	if !bytes.Contains(stdout.Bytes(), []byte(ansiEscape)) {
+		t.Errorf("terminal sink should be colored, got %q", stdout.String())
+	}
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	if !bytes.Contains(data, []byte("disk almost full")) {
+		t.Fatalf("file sink should receive the entry, got %q", data)
+	}
+	if bytes.Contains(data, []byte(ansiEscape)) {
+		t.Errorf("file sink must not contain ANSI escapes, got %q", data)
+	}
+}
+
+func TestColorModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   Format
+		color    ColorMode
+		terminal bool
+		env      map[string]string
+		want     bool
+	}{
+		{name: "auto terminal", format: FormatConsole, terminal: true, want: true},
+		{name: "auto redirected", format: FormatConsole, terminal: false, want: false},
+		{name: "auto NO_COLOR", format: FormatConsole, terminal: true, env: map[string]string{"NO_COLOR": "1"}, want: false},
+		{name: "auto TERM=dumb", format: FormatConsole, terminal: true, env: map[string]string{"TERM": "dumb"}, want: false},
+		{name: "always redirected", format: FormatConsole, color: ColorAlways, env: map[string]string{"NO_COLOR": "1"}, want: true},
+		{name: "never terminal", format: FormatConsole, color: ColorNever, terminal: true, want: false},
+		{name: "text always", format: FormatText, color: ColorAlways, terminal: true, want: false},
+		{name: "json always", format: FormatJSON, color: ColorAlways, terminal: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearColorEnv(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			var out bytes.Buffer
+			if tt.terminal {
+				fakeTerminal(t, &out)
+			}
+
+			log := NewWithOptions(Options{Level: InfoLevel, Format: tt.format, Color: tt.color, Output: &out})
+			log.Info("hello")
+
+			if got := bytes.Contains(out.Bytes(), []byte(ansiEscape)); got != tt.want {
+				t.Errorf("colored = %v, want %v: %q", got, tt.want, out.String())
+			}
+		})
+	}
+}
+
+func TestParseColorMode(t *testing.T) {
+	for input, want := range map[string]ColorMode{"always": ColorAlways, "never": ColorNever, "auto": ColorAuto, "": ColorAuto} {
+		if got := ParseColorMode(input); got != want {
+			t.Errorf("ParseColorMode(%q) = %v, want %v", input, got, want)
+		}
+		if input != "" && want.String() != input {
+			t.Errorf("%v.String() = %q, want %q", want, want.String(), input)
+		}
+	}
+}
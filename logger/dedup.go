@@ -0,0 +1,199 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// dedupKey 重复日志按级别和消息识别
+type dedupKey struct {
+	level   zapcore.Level
+	message string
+}
+
+// dedupEntry 窗口内某条消息的状态
+type dedupEntry struct {
+	start      time.Time     // 窗口开始时间（最近一次实际输出的时间）
+	suppressed int           // 窗口内被抑制的次数
+	core       zapcore.Core  // 最近一次写入所用的 core，汇总日志带上相同的字段
+	ent        zapcore.Entry // 最近一次被抑制的日志
+}
+
+// dedupState 同一 Every 调用（或 Options.DedupWindow）派生的所有 core 共享的状态
+type dedupState struct {
+	mu        sync.Mutex
+	window    time.Duration
+	entries   map[dedupKey]*dedupEntry
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+// dedupSummary 待输出的 "repeated N times" 汇总
+type dedupSummary struct {
+	core       zapcore.Core
+	ent        zapcore.Entry
+	suppressed int
+}
+
+// dedupCore 抑制窗口内级别和消息都相同的重复日志
+//
+// 每个窗口只输出第一条，其余计数；窗口结束后该消息再次出现、
+// 窗口结束后有其他日志写入，或调用 Sync（包括 FlushInterval 的定期同步和 Close）时，
+// 输出一条 "<消息> (repeated N times)" 汇总，repeated 字段为被抑制的次数。
+// DPanic 及以上级别的日志不会被抑制。
+type dedupCore struct {
+	zapcore.Core
+	state *dedupState
+}
+
+// newDedupCore window 不大于0时返回原始 core
+func newDedupCore(core zapcore.Core, window time.Duration) zapcore.Core {
+	if window <= 0 {
+		return core
+	}
+	return &dedupCore{
+		Core: core,
+		state: &dedupState{
+			window:  window,
+			entries: make(map[dedupKey]*dedupEntry),
+			now:     time.Now,
+		},
+	}
+}
+
+func (c *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	return &dedupCore{Core: c.Core.With(fields), state: c.state}
+}
+
+func (c *dedupCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *dedupCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ent.Level >= zapcore.DPanicLevel {
+		return c.Core.Write(ent, fields)
+	}
+
+	write, summaries := c.state.observe(c.Core, ent)
+	for _, s := range summaries {
+		s.write()
+	}
+	if !write {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+func (c *dedupCore) Sync() error {
+	for _, s := range c.state.drain() {
+		s.write()
+	}
+	return c.Core.Sync()
+}
+
+// observe 记录一条日志，返回是否输出以及需要先输出的汇总
+func (s *dedupState) observe(core zapcore.Core, ent zapcore.Entry) (bool, []dedupSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	key := dedupKey{level: ent.Level, message: ent.Message}
+
+	var summaries []dedupSummary
+	if !now.Before(s.nextSweep) {
+		summaries = s.sweep(now, key)
+		s.nextSweep = now.Add(s.window)
+	}
+
+	entry, ok := s.entries[key]
+	if ok && now.Sub(entry.start) < s.window {
+		entry.suppressed++
+		entry.core = core
+		entry.ent = ent
+		return false, summaries
+	}
+
+	if ok && entry.suppressed > 0 {
+		summaries = append(summaries, dedupSummary{core: entry.core, ent: entry.ent, suppressed: entry.suppressed})
+	}
+	s.entries[key] = &dedupEntry{start: now, core: core, ent: ent}
+	return true, summaries
+}
+
+// sweep 清理窗口已结束的消息并收集其汇总，skip 对应的消息由调用方处理
+func (s *dedupState) sweep(now time.Time, skip dedupKey) []dedupSummary {
+	var summaries []dedupSummary
+	for key, entry := range s.entries {
+		if key == skip || now.Sub(entry.start) < s.window {
+			continue
+		}
+		if entry.suppressed > 0 {
+			summaries = append(summaries, dedupSummary{core: entry.core, ent: entry.ent, suppressed: entry.suppressed})
+		}
+		delete(s.entries, key)
+	}
+	return summaries
+}
+
+// drain 收集所有待输出的汇总并重置计数，窗口保持不变
+func (s *dedupState) drain() []dedupSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var summaries []dedupSummary
+	for _, entry := range s.entries {
+		if entry.suppressed == 0 {
+			continue
+		}
+		summaries = append(summaries, dedupSummary{core: entry.core, ent: entry.ent, suppressed: entry.suppressed})
+		entry.suppressed = 0
+	}
+	return summaries
+}
+
+// write 输出汇总日志，沿用最近一次被抑制日志的级别、名称和调用者
+func (s dedupSummary) write() {
+	ent := s.ent
+	ent.Message = fmt.Sprintf("%s (repeated %d times)", ent.Message, s.suppressed)
+	ent.Stack = ""
+	s.core.Write(ent, []zapcore.Field{zap.Int("repeated", s.suppressed)})
+}
+
+// Every 返回在窗口 d 内抑制重复日志的记录器
+//
+// 级别和消息都相同的日志在每个窗口内只输出第一条，其余计数后输出
+// "<消息> (repeated N times)" 汇总，适合在循环或故障期间防止日志风暴。
+// 通过包装 zap core 实现，与采样、截断和所有输出目标组合使用。
+// 每次调用创建独立的计数状态，应保存返回的记录器重复使用。
+//
+// 示例:
+//
+//	var pollLog = logger.New().Every(time.Minute)
+//
+//	for {
+//	    if err := poll(); err != nil {
+//	        pollLog.Error("轮询失败", "error", err)
+//	    }
+//	}
+func (l *Logger) Every(d time.Duration) *Logger {
+	newLogger := &Logger{
+		zap: l.zap.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return newDedupCore(core, d)
+		})),
+		level:        l.level,
+		config:       l.config,
+		hooks:        l.hooks,
+		ctx:          l.ctx,
+		ctxExtractor: l.ctxExtractor,
+		flusher:      l.flusher,
+	}
+	newLogger.sugar = newLogger.zap.Sugar()
+	return newLogger
+}
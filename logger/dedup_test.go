@@ -0,0 +1,175 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// fakeClock 可手动推进的时钟
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newDedupLogger 创建使用假时钟、窗口为 window 的去重日志记录器
+func newDedupLogger(t *testing.T, window time.Duration) (*Logger, *observer.ObservedLogs, *fakeClock) {
+	t.Helper()
+
+	base, logs := newObservedLogger()
+	l := base.Every(window)
+	core, ok := l.zap.Core().(*dedupCore)
+	if !ok {
+		t.Fatalf("Expected dedupCore, got %T", l.zap.Core())
+	}
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	core.state.now = clock.Now
+	return l, logs, clock
+}
+
+func messages(logs *observer.ObservedLogs) []string {
+	var out []string
+	for _, entry := range logs.All() {
+		out = append(out, entry.Message)
+	}
+	return out
+}
+
+func TestEverySuppressesRepeats(t *testing.T) {
+	l, logs, clock := newDedupLogger(t, time.Minute)
+
+	for i := 0; i < 5; i++ {
+		l.Error("db down", "attempt", i)
+	}
+	if got := messages(logs); len(got) != 1 || got[0] != "db down" {
+		t.Fatalf("Expected only the first message within the window, got %v", got)
+	}
+
+	clock.Advance(time.Minute)
+	l.Error("db down", "attempt", 5)
+
+	all := logs.All()
+	if len(all) != 3 {
+		t.Fatalf("Expected first, summary and new message, got %v", messages(logs))
+	}
+	summary := all[1]
+	if summary.Message != "db down (repeated 4 times)" || summary.Level != zapcore.ErrorLevel {
+		t.Errorf("Unexpected summary: %s %q", summary.Level, summary.Message)
+	}
+	if summary.ContextMap()["repeated"] != int64(4) {
+		t.Errorf("Expected repeated=4, got %v", summary.ContextMap())
+	}
+	if all[2].Message != "db down" || all[2].ContextMap()["attempt"] != int64(5) {
+		t.Errorf("Expected new window to log the message, got %+v", all[2])
+	}
+}
+
+func TestEveryKeysOnLevelAndMessage(t *testing.T) {
+	l, logs, _ := newDedupLogger(t, time.Minute)
+
+	l.Warn("retrying")
+	l.Error("retrying")
+	l.Warn("other")
+	l.Warn("retrying")
+	l.With("k", "v").Error("retrying")
+
+	want := []string{"retrying", "retrying", "other"}
+	if got := messages(logs); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestEverySummaryOnSync(t *testing.T) {
+	l, logs, _ := newDedupLogger(t, time.Hour)
+
+	l.Info("tick")
+	l.Info("tick")
+	l.Info("tick")
+	l.Sync()
+
+	got := messages(logs)
+	if len(got) != 2 || got[1] != "tick (repeated 2 times)" {
+		t.Fatalf("Expected summary after Sync, got %v", got)
+	}
+
+	// 汇总后窗口保持不变，仍然抑制
+	l.Info("tick")
+	l.Sync()
+	got = messages(logs)
+	if len(got) != 3 || got[2] != "tick (repeated 1 times)" {
+		t.Errorf("Expected window to persist after Sync, got %v", got)
+	}
+
+	// 没有被抑制的日志时不输出汇总
+	l.Sync()
+	if len(logs.All()) != 3 {
+		t.Errorf("Expected no extra summary, got %v", messages(logs))
+	}
+}
+
+func TestEverySummaryOnOtherWrite(t *testing.T) {
+	l, logs, clock := newDedupLogger(t, time.Minute)
+
+	l.Error("storm")
+	l.Error("storm")
+	clock.Advance(2 * time.Minute)
+	l.Info("recovered")
+
+	want := []string{"storm", "storm (repeated 1 times)", "recovered"}
+	if got := messages(logs); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestEverySummaryKeepsFields(t *testing.T) {
+	l, logs, _ := newDedupLogger(t, time.Minute)
+
+	worker := l.With("worker", "sync")
+	worker.Warn("lagging")
+	worker.Warn("lagging")
+	l.Sync()
+
+	all := logs.All()
+	if len(all) != 2 || all[1].ContextMap()["worker"] != "sync" {
+		t.Errorf("Expected summary to carry With fields, got %+v", all)
+	}
+}
+
+func TestDedupWindowOption(t *testing.T) {
+	l, read := newLimitedLogger(t, Options{DedupWindow: time.Hour})
+
+	for i := 0; i < 10; i++ {
+		l.Info("flood")
+	}
+
+	entries := read()
+	if len(entries) != 2 {
+		t.Fatalf("Expected message and summary, got %v", entries)
+	}
+	if entries[1]["msg"] != "flood (repeated 9 times)" || entries[1]["repeated"] != float64(9) {
+		t.Errorf("Unexpected summary: %v", entries[1])
+	}
+}
+
+func TestNewDedupCoreDisabled(t *testing.T) {
+	core, _ := observer.New(zapcore.InfoLevel)
+	if newDedupCore(core, 0) != core {
+		t.Error("Expected zero window to return the original core")
+	}
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	stderrors "errors"
+	"fmt"
+	"reflect"
+
+	kiterrors "github.com/tsopia/go-kit/errors"
+)
+
+// stackTraceMethod pkg/errors 等库约定的获取堆栈的方法名
+const stackTraceMethod = "StackTrace"
+
+// errorStack 沿 Unwrap 链查找错误携带的堆栈，返回最深一层（最接近错误源头）的堆栈
+//
+// 支持两类错误:
+//   - 实现 StackTrace() 方法的错误（例如 pkg/errors），堆栈按 %+v 格式化
+//   - 本库的 *errors.Error，使用其 Stack 字段
+//
+// 没有堆栈时返回空字符串。
+func errorStack(err error) string {
+	var stack string
+	for err != nil {
+		if s := stackOf(err); s != "" {
+			stack = s
+		}
+		err = stderrors.Unwrap(err)
+	}
+	return stack
+}
+
+// stackOf 获取单个错误（不展开 Unwrap 链）携带的堆栈
+func stackOf(err error) string {
+	if e, ok := err.(*kiterrors.Error); ok {
+		if e == nil {
+			return ""
+		}
+		return e.Stack
+	}
+
+	// pkg/errors 的 StackTrace() 返回其自定义类型，无法用接口断言，通过反射调用
+	method := reflect.ValueOf(err).MethodByName(stackTraceMethod)
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return ""
+	}
+	trace := method.Call(nil)[0]
+	if isEmptyTrace(trace) {
+		return ""
+	}
+	return fmt.Sprintf("%+v", trace.Interface())
+}
+
+// isEmptyTrace 判断反射得到的堆栈是否为空
+func isEmptyTrace(trace reflect.Value) bool {
+	switch trace.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String:
+		return trace.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return trace.IsNil()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	kiterrors "github.com/tsopia/go-kit/errors"
+)
+
+// stackFrames 模拟 pkg/errors 的 StackTrace 类型，%+v 输出每一帧
+type stackFrames []string
+
+func (s stackFrames) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('+') {
+		fmt.Fprint(f, strings.Join(s, "\n"))
+		return
+	}
+	fmt.Fprint(f, len(s))
+}
+
+type tracedError struct {
+	msg    string
+	frames stackFrames
+}
+
+func (e *tracedError) Error() string           { return e.msg }
+func (e *tracedError) StackTrace() stackFrames { return e.frames }
+
+func TestErrorStack(t *testing.T) {
+	traced := &tracedError{msg: "boom", frames: stackFrames{"main.handler", "main.main"}}
+	kitErr := kiterrors.New(kiterrors.CodeInternalServer, "internal").WithStack()
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"plain", stderrors.New("plain"), ""},
+		{"stack tracer", traced, "main.handler\nmain.main"},
+		{"wrapped stack tracer", fmt.Errorf("query: %w", traced), "main.handler\nmain.main"},
+		{"kit error", kitErr, kitErr.Stack},
+		{"kit error without stack", kiterrors.New(kiterrors.CodeInternalServer), ""},
+		{"deepest wins", kiterrors.Wrap(traced, kiterrors.CodeInternalServer).WithStack(), "main.handler\nmain.main"},
+		{"empty trace", &tracedError{msg: "empty"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorStack(tt.err); got != tt.want {
+				t.Errorf("Expected stack %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWithErrorStackField(t *testing.T) {
+	l, logPath := newTempFileLogger(t, 0)
+
+	traced := &tracedError{msg: "boom", frames: stackFrames{"main.handler"}}
+	l.WithError(fmt.Errorf("wrapped: %w", traced)).Error("with stack")
+	l.WithError(stderrors.New("plain")).Error("without stack")
+	l.Close()
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 log lines, got %d: %s", len(lines), content)
+	}
+
+	var withStack, withoutStack map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &withStack); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &withoutStack); err != nil {
+		t.Fatal(err)
+	}
+
+	if withStack["stack"] != "main.handler" {
+		t.Errorf("Expected stack field 'main.handler', got %v", withStack["stack"])
+	}
+	if withStack["error"] != "wrapped: boom" {
+		t.Errorf("Expected error field 'wrapped: boom', got %v", withStack["error"])
+	}
+	if _, ok := withoutStack["stack"]; ok {
+		t.Errorf("Expected no stack field for plain error, got %v", withoutStack["stack"])
+	}
+}
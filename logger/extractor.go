@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"context"
+	"sort"
+)
+
+// contextField 一个额外提取的上下文字段
+type contextField struct {
+	name string      // 输出的字段名
+	key  interface{} // 上下文中的键
+}
+
+// ContextFieldsExtractor 在基础提取器的结果之上，按配置从上下文中提取额外字段
+//
+// 通常通过 Options.ContextFields 配置，不需要为一两个额外字段实现完整的 ContextExtractor。
+// 额外字段与基础提取器的字段同名时，上下文中存在该键则覆盖基础结果。
+type ContextFieldsExtractor struct {
+	base   ContextExtractor
+	fields []contextField
+}
+
+// NewContextFieldsExtractor 创建额外字段提取器
+// fields 的键为输出字段名，值为上下文键（例如 ContextKey("tenant_id") 或自定义类型的键）；
+// base 为nil时只提取 fields 中的字段
+func NewContextFieldsExtractor(base ContextExtractor, fields map[string]interface{}) *ContextFieldsExtractor {
+	extractor := &ContextFieldsExtractor{base: base, fields: make([]contextField, 0, len(fields))}
+	for name, key := range fields {
+		if name == "" || key == nil {
+			continue
+		}
+		extractor.fields = append(extractor.fields, contextField{name: name, key: key})
+	}
+	// 固定查找顺序，便于测试和排查
+	sort.Slice(extractor.fields, func(i, j int) bool { return extractor.fields[i].name < extractor.fields[j].name })
+	return extractor
+}
+
+// Extract 提取基础字段和额外字段，均不存在时返回nil
+func (e *ContextFieldsExtractor) Extract(ctx context.Context) map[string]interface{} {
+	var fields map[string]interface{}
+	if e.base != nil {
+		fields = e.base.Extract(ctx)
+	}
+
+	copied := false
+	for _, field := range e.fields {
+		value := ctx.Value(field.key)
+		if value == nil {
+			continue
+		}
+		// 基础提取器返回的 map 可能被其复用，写入前复制一份
+		if !copied {
+			merged := make(map[string]interface{}, len(fields)+len(e.fields))
+			for key, v := range fields {
+				merged[key] = v
+			}
+			fields = merged
+			copied = true
+		}
+		fields[field.name] = value
+	}
+	return fields
+}
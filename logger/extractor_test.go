@@ -0,0 +1,221 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/tsopia/go-kit/constants"
+)
+
+func populatedContext() context.Context {
+	ctx := constants.WithTraceAndRequestID(context.Background(), "trace-123", "req-456")
+	ctx = context.WithValue(ctx, ContextKey("span_id"), "span-789")
+	ctx = context.WithValue(ctx, ContextKey("user_id"), 42)
+	return ctx
+}
+
+func TestDefaultContextExtractorEmpty(t *testing.T) {
+	extractor := &DefaultContextExtractor{}
+	if fields := extractor.Extract(context.Background()); fields != nil {
+		t.Errorf("Expected nil fields for empty context, got %v", fields)
+	}
+}
+
+func TestDefaultContextExtractorConstants(t *testing.T) {
+	fields := (&DefaultContextExtractor{}).Extract(populatedContext())
+
+	expected := map[string]interface{}{
+		"trace_id":   "trace-123",
+		"request_id": "req-456",
+		"span_id":    "span-789",
+		"user_id":    42,
+	}
+	for key, want := range expected {
+		if fields[key] != want {
+			t.Errorf("Expected %s = %v, got %v", key, want, fields[key])
+		}
+	}
+}
+
+func TestDefaultContextExtractorLegacyKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   interface{}
+		field string
+	}{
+		{"ContextKey trace_id", ContextKey("trace_id"), "trace_id"},
+		{"traceId", "traceId", "trace_id"},
+		{"x-trace-id", "x-trace-id", "trace_id"},
+		{"ContextKey request_id", ContextKey("request_id"), "request_id"},
+		{"requestId", "requestId", "request_id"},
+		{"x-request-id", "x-request-id", "request_id"},
+		{"ContextKey span_id", ContextKey("span_id"), "span_id"},
+		{"spanId", "spanId", "span_id"},
+		{"ContextKey user_id", ContextKey("user_id"), "user_id"},
+		{"userId", "userId", "user_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), tt.key, "value")
+			fields := (&DefaultContextExtractor{}).Extract(ctx)
+			if len(fields) != 1 || fields[tt.field] != "value" {
+				t.Errorf("Expected only %s = value, got %v", tt.field, fields)
+			}
+		})
+	}
+}
+
+func TestDefaultContextExtractorPrecedence(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "x-trace-id", "legacy")
+	ctx = context.WithValue(ctx, ContextKey("trace_id"), "typed")
+	ctx = context.WithValue(ctx, "spanId", "camel")
+	ctx = context.WithValue(ctx, ContextKey("span_id"), "typed")
+
+	fields := (&DefaultContextExtractor{}).Extract(ctx)
+	if fields["trace_id"] != "typed" {
+		t.Errorf("Expected ContextKey trace_id to win, got %v", fields["trace_id"])
+	}
+	if fields["span_id"] != "camel" {
+		t.Errorf("Expected spanId to win, got %v", fields["span_id"])
+	}
+
+	// constants 包的键优先于所有兼容键；空字符串视为不存在
+	ctx = constants.WithTraceID(ctx, "constants")
+	if fields := (&DefaultContextExtractor{}).Extract(ctx); fields["trace_id"] != "constants" {
+		t.Errorf("Expected constants trace_id to win, got %v", fields["trace_id"])
+	}
+	ctx = constants.WithTraceID(ctx, "")
+	if fields := (&DefaultContextExtractor{}).Extract(ctx); fields["trace_id"] != "typed" {
+		t.Errorf("Expected fallback for empty constants trace_id, got %v", fields["trace_id"])
+	}
+}
+
+func TestDisableContextExtraction(t *testing.T) {
+	l := NewWithOptions(Options{Level: InfoLevel, DisableContextExtraction: true})
+	if _, ok := l.ctxExtractor.(NopExtractor); !ok {
+		t.Fatalf("Expected NopExtractor, got %T", l.ctxExtractor)
+	}
+
+	derived := l.WithContext(populatedContext())
+	if derived.zap != l.zap {
+		t.Error("Expected no context fields to be added")
+	}
+}
+
+type tenantKey struct{}
+
+func TestContextFieldsExtractor(t *testing.T) {
+	extractor := NewContextFieldsExtractor(&DefaultContextExtractor{}, map[string]interface{}{
+		"tenant_id":  tenantKey{},
+		"session_id": ContextKey("session_id"),
+		"user_id":    "uid", // 与默认字段同名时覆盖
+	})
+
+	if fields := extractor.Extract(context.Background()); fields != nil {
+		t.Errorf("Expected nil fields for empty context, got %v", fields)
+	}
+
+	ctx := context.WithValue(populatedContext(), tenantKey{}, "acme")
+	ctx = context.WithValue(ctx, ContextKey("session_id"), "sess-1")
+	fields := extractor.Extract(ctx)
+	expected := map[string]interface{}{
+		"trace_id":   "trace-123",
+		"request_id": "req-456",
+		"span_id":    "span-789",
+		"user_id":    42,
+		"tenant_id":  "acme",
+		"session_id": "sess-1",
+	}
+	if len(fields) != len(expected) {
+		t.Errorf("Expected %d fields, got %v", len(expected), fields)
+	}
+	for key, want := range expected {
+		if fields[key] != want {
+			t.Errorf("Expected %s = %v, got %v", key, want, fields[key])
+		}
+	}
+
+	ctx = context.WithValue(ctx, "uid", "override")
+	if fields := extractor.Extract(ctx); fields["user_id"] != "override" {
+		t.Errorf("Expected configured key to override user_id, got %v", fields["user_id"])
+	}
+}
+
+func TestOptionsContextFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(Options{
+		Level:         InfoLevel,
+		Format:        FormatJSON,
+		Output:        &buf,
+		ContextFields: map[string]interface{}{"tenant_id": ContextKey("tenant_id")},
+	})
+
+	ctx := context.WithValue(populatedContext(), ContextKey("tenant_id"), "acme")
+	l.WithContext(ctx).Info("hello")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to decode log entry %q: %v", buf.String(), err)
+	}
+	if entry["tenant_id"] != "acme" || entry["trace_id"] != "trace-123" {
+		t.Errorf("Expected tenant_id and default fields, got %v", entry)
+	}
+
+	disabled := NewWithOptions(Options{
+		Level:                    InfoLevel,
+		DisableContextExtraction: true,
+		ContextFields:            map[string]interface{}{"tenant_id": ContextKey("tenant_id")},
+	})
+	if _, ok := disabled.ctxExtractor.(NopExtractor); !ok {
+		t.Errorf("Expected DisableContextExtraction to take precedence, got %T", disabled.ctxExtractor)
+	}
+}
+
+func BenchmarkDefaultContextExtractor_Empty(b *testing.B) {
+	extractor := &DefaultContextExtractor{}
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		extractor.Extract(ctx)
+	}
+}
+
+func BenchmarkDefaultContextExtractor_Populated(b *testing.B) {
+	extractor := &DefaultContextExtractor{}
+	ctx := populatedContext()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		extractor.Extract(ctx)
+	}
+}
+
+func BenchmarkWithContext_Empty(b *testing.B) {
+	l := NewNop()
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.WithContext(ctx)
+	}
+}
+
+func BenchmarkWithContext_Populated(b *testing.B) {
+	l := NewNop()
+	ctx := populatedContext()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.WithContext(ctx)
+	}
+}
+
+func BenchmarkWithContext_Disabled(b *testing.B) {
+	l := NewNop()
+	l.ctxExtractor = NopExtractor{}
+	ctx := populatedContext()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.WithContext(ctx)
+	}
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// DanglingFieldKey 键值对参数为奇数个时，最后一个没有配对的值使用的键
+const DanglingFieldKey = "_dangling"
+
+// normalizeFields 检查 Info/Debug 等方法传入的键值对参数
+//
+// zap 的 SugaredLogger 会丢弃没有配对的值和非字符串的键，只留下一条容易被忽略的错误日志。
+// 这里把非字符串的键转换为字符串，把没有配对的最后一个值记录在 DanglingFieldKey 下，并输出警告。
+// zap.Field 类型的参数单独占一个位置，与 zap 的处理方式一致。参数正确时原样返回，不产生额外分配。
+func (l *Logger) normalizeFields(msg string, fields []interface{}) []interface{} {
+	if fieldsValid(fields) {
+		return fields
+	}
+
+	out := make([]interface{}, 0, len(fields)+1)
+	var coerced []string
+	var dangling interface{}
+	hasDangling := false
+	for i := 0; i < len(fields); i++ {
+		if f, ok := fields[i].(zap.Field); ok {
+			out = append(out, f)
+			continue
+		}
+		if i == len(fields)-1 {
+			dangling, hasDangling = fields[i], true
+			break
+		}
+		key, ok := fields[i].(string)
+		if !ok {
+			key = fmt.Sprint(fields[i])
+			coerced = append(coerced, key)
+		}
+		out = append(out, key, fields[i+1])
+		i++
+	}
+	if hasDangling {
+		out = append(out, DanglingFieldKey, dangling)
+	}
+
+	// 警告指向调用日志方法的用户代码：normalizeFields 比日志方法多一层
+	warn := l.zap.WithOptions(zap.AddCallerSkip(1))
+	if hasDangling {
+		warn.Warn("日志字段的键值对参数为奇数个，没有配对的值记录在 "+DanglingFieldKey+" 中",
+			zap.String("message", msg), zap.Any("value", dangling))
+	}
+	if len(coerced) > 0 {
+		warn.Warn("日志字段的键不是字符串，已转换为字符串",
+			zap.String("message", msg), zap.Strings("keys", coerced))
+	}
+	return out
+}
+
+// fieldsValid 判断键值对参数是否都已正确配对且键为字符串
+func fieldsValid(fields []interface{}) bool {
+	for i := 0; i < len(fields); i++ {
+		if _, ok := fields[i].(zap.Field); ok {
+			continue
+		}
+		if i == len(fields)-1 {
+			return false
+		}
+		if _, ok := fields[i].(string); !ok {
+			return false
+		}
+		i++
+	}
+	return true
+}
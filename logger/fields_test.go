@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestOddFieldsKeepDanglingValue(t *testing.T) {
+	l, logs := newObservedLogger()
+
+	l.Info("odd", "user", "alice", "orphan")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("Expected warning and log entry, got %d entries", len(entries))
+	}
+	warning, entry := entries[0], entries[1]
+	if warning.Level != zap.WarnLevel || warning.ContextMap()["message"] != "odd" {
+		t.Errorf("Expected warning about odd fields, got %+v", warning)
+	}
+	if file := filepath.Base(warning.Caller.File); file != "fields_test.go" {
+		t.Errorf("Expected warning caller in fields_test.go, got %s", warning.Caller.String())
+	}
+
+	fields := entry.ContextMap()
+	if fields["user"] != "alice" || fields[DanglingFieldKey] != "orphan" {
+		t.Errorf("Expected dangling value preserved, got %v", fields)
+	}
+	if file := filepath.Base(entry.Caller.File); file != "fields_test.go" {
+		t.Errorf("Expected caller in fields_test.go, got %s", entry.Caller.String())
+	}
+}
+
+func TestFieldsNormalization(t *testing.T) {
+	l, logs := newObservedLogger()
+
+	// zap.Field 单独占一个位置，不算奇数
+	l.Info("typed", zap.Int("n", 1), "k", "v")
+	// 非字符串的键转换为字符串
+	l.Info("coerced", 42, "answer")
+	// 只有一个值
+	l.Error("single", "key")
+
+	var got []map[string]interface{}
+	warnings := 0
+	for _, entry := range logs.All() {
+		if entry.Level == zap.WarnLevel {
+			warnings++
+			continue
+		}
+		got = append(got, entry.ContextMap())
+	}
+	if warnings != 2 {
+		t.Errorf("Expected 2 warnings, got %d", warnings)
+	}
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(got))
+	}
+	if got[0]["n"] != int64(1) || got[0]["k"] != "v" || got[0][DanglingFieldKey] != nil {
+		t.Errorf("Unexpected typed fields: %v", got[0])
+	}
+	if got[1]["42"] != "answer" {
+		t.Errorf("Expected coerced key, got %v", got[1])
+	}
+	if got[2][DanglingFieldKey] != "key" {
+		t.Errorf("Expected single dangling value, got %v", got[2])
+	}
+}
+
+func TestFieldsValidNoAllocation(t *testing.T) {
+	l, _ := newObservedLogger()
+	fields := []interface{}{"a", 1, zap.String("b", "c"), "d", 2}
+	allocs := testing.AllocsPerRun(100, func() {
+		l.normalizeFields("msg", fields)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected no allocations for valid fields, got %v", allocs)
+	}
+}
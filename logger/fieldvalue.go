@@ -0,0 +1,158 @@
+package logger
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DurationEncoding time.Duration 字段值的输出方式
+type DurationEncoding int
+
+const (
+	// DurationString 输出为 time.Duration.String() 的格式，例如 "1.5s"（默认）
+	DurationString DurationEncoding = iota
+	// DurationSeconds 输出为浮点秒数，例如 1.5
+	DurationSeconds
+)
+
+// BytesEncoding []byte 字段值的输出方式
+type BytesEncoding int
+
+const (
+	// BytesBase64 输出为 base64 字符串（默认）
+	BytesBase64 BytesEncoding = iota
+	// BytesHex 输出为十六进制字符串，超过 MaxHexBytes 的部分截断
+	BytesHex
+)
+
+// MaxHexBytes BytesHex 输出的最大字节数（编码前），超出部分以 "...(N bytes)" 表示
+const MaxHexBytes = 64
+
+// valueEncoding 字段值规范化的配置，raw 为true时不做任何转换
+type valueEncoding struct {
+	raw      bool
+	duration DurationEncoding
+	bytes    BytesEncoding
+}
+
+// valueEncoding 返回字段值规范化的配置
+func (o *Options) valueEncoding() valueEncoding {
+	return valueEncoding{raw: o.RawFieldEncoding, duration: o.DurationEncoding, bytes: o.BytesEncoding}
+}
+
+// stringerType fmt.Stringer 的反射类型，用于查找指针接收者实现的 String 方法
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// normalize 把字段值转换为在各种输出格式下都一致的形式，不需要转换时原样返回
+//
+//   - time.Duration 和 []time.Duration 按 DurationEncoding 输出
+//   - []error 输出为各错误 Error() 的字符串数组（zap 默认输出为 [{"error": "..."}]）
+//   - []byte 按 BytesEncoding 输出
+//   - 实现 fmt.Stringer 的值（包括只有指针接收者实现 String 的结构体值）输出 String() 的结果
+//
+// error、time.Time 和实现 zapcore.ObjectMarshaler/ArrayMarshaler 的值保持不变：
+// error 仍作为错误字段（ErrorReporter 据此提取错误和堆栈），time.Time 由编码器按 TimeFormat 输出。
+func (e valueEncoding) normalize(value interface{}) interface{} {
+	if e.raw {
+		return value
+	}
+	switch v := value.(type) {
+	case nil, string, bool, int, int64, float64, error, time.Time,
+		zapcore.ObjectMarshaler, zapcore.ArrayMarshaler:
+		return value
+	case time.Duration:
+		if e.duration == DurationString {
+			return v.String()
+		}
+		return value
+	case []time.Duration:
+		if e.duration != DurationString {
+			return value
+		}
+		out := make([]string, len(v))
+		for i, d := range v {
+			out[i] = d.String()
+		}
+		return out
+	case []error:
+		out := make([]string, len(v))
+		for i, err := range v {
+			if err == nil {
+				out[i] = "<nil>"
+				continue
+			}
+			out[i] = err.Error()
+		}
+		return out
+	case []byte:
+		if e.bytes == BytesHex {
+			return truncatedHex(v)
+		}
+		return value
+	case fmt.Stringer:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return value
+		}
+		return v.String()
+	}
+
+	// 只有指针接收者实现 String 的值按值传入时不满足 fmt.Stringer，zap 会输出结构体的字段
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Struct && reflect.PtrTo(rv.Type()).Implements(stringerType) {
+		ptr := reflect.New(rv.Type())
+		ptr.Elem().Set(rv)
+		return ptr.Interface().(fmt.Stringer).String()
+	}
+	return value
+}
+
+// normalizeKeysAndValues 规范化键值对参数中的值，zap.Field 原样保留；没有需要转换的值时返回原切片
+func (e valueEncoding) normalizeKeysAndValues(fields []interface{}) []interface{} {
+	if e.raw {
+		return fields
+	}
+	var out []interface{}
+	for i := 0; i < len(fields); i++ {
+		if _, ok := fields[i].(zap.Field); ok || i+1 >= len(fields) {
+			continue
+		}
+		i++
+		normalized := e.normalize(fields[i])
+		if out == nil {
+			if sameValue(normalized, fields[i]) {
+				continue
+			}
+			out = make([]interface{}, len(fields))
+			copy(out, fields)
+		}
+		out[i] = normalized
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// sameValue 判断 normalize 是否原样返回了值：转换后的值类型一定不同
+func sameValue(normalized, original interface{}) bool {
+	return reflect.TypeOf(normalized) == reflect.TypeOf(original)
+}
+
+// field 创建规范化后的 zap 字段
+func (e valueEncoding) field(key string, value interface{}) zap.Field {
+	return zap.Any(key, e.normalize(value))
+}
+
+// truncatedHex 把字节切片编码为十六进制，超过 MaxHexBytes 的部分截断
+func truncatedHex(b []byte) string {
+	if len(b) <= MaxHexBytes {
+		return hex.EncodeToString(b)
+	}
+	return hex.EncodeToString(b[:MaxHexBytes]) + "...(" + strconv.Itoa(len(b)) + " bytes)"
+}
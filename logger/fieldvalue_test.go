@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	kiterrors "github.com/tsopia/go-kit/errors"
+)
+
+// orderStatus 值接收者实现 String
+type orderStatus int
+
+func (s orderStatus) String() string { return fmt.Sprintf("status-%d", int(s)) }
+
+// money 只有指针接收者实现 String，按值传入时 zap 会输出结构体字段
+type money struct {
+	Cents int
+}
+
+func (m *money) String() string { return fmt.Sprintf("%d.%02d", m.Cents/100, m.Cents%100) }
+
+// presetOptions 与 NewDevelopment、NewProduction 相同的格式配置，输出到 buf
+func presetOptions(buf *bytes.Buffer) map[string]Options {
+	return map[string]Options{
+		"development": {Level: DebugLevel, Format: FormatConsole, TimeFormat: "2006-01-02 15:04:05", Color: ColorNever, Output: buf},
+		"production":  {Level: InfoLevel, Format: FormatJSON, TimeFormat: time.RFC3339, Output: buf},
+	}
+}
+
+// renderedFields 解析最后一行日志的字段，控制台格式的字段是行尾的JSON对象
+func renderedFields(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	line := lines[len(lines)-1]
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(line[strings.Index(line, "{"):]), &fields); err != nil {
+		t.Fatalf("Failed to decode %q: %v", line, err)
+	}
+	return fields
+}
+
+func TestFieldValueEncoding(t *testing.T) {
+	at := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+	cases := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+	}{
+		{"duration", 1500 * time.Millisecond, "1.5s"},
+		{"durations", []time.Duration{time.Second, 250 * time.Millisecond}, []interface{}{"1s", "250ms"}},
+		{"error", errors.New("boom"), "boom"},
+		{"kit_error", kiterrors.New(kiterrors.CodeDatabaseError, "写入失败"), "[DATABASE_ERROR] 写入失败"},
+		{"errors", []error{errors.New("a"), nil, kiterrors.New(kiterrors.CodeNotFound, "b")}, []interface{}{"a", "<nil>", "[NOT_FOUND] b"}},
+		{"stringer", orderStatus(2), "status-2"},
+		{"pointer_stringer", money{Cents: 1250}, "12.50"},
+		{"bytes", []byte("hi"), "aGk="},
+	}
+
+	for name, opts := range presetOptions(nil) {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts.Output = &buf
+			l := NewWithOptions(opts)
+			for _, tc := range cases {
+				l.Info("value", tc.name, tc.value)
+				got := renderedFields(t, &buf)[tc.name]
+				if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+					t.Errorf("%s: expected %v, got %#v", tc.name, tc.want, got)
+				}
+			}
+
+			// time.Time 使用配置的 TimeFormat
+			l.Info("value", "at", at)
+			want := at.Format(opts.TimeFormat)
+			if got := renderedFields(t, &buf)["at"]; got != want {
+				t.Errorf("time: expected %q, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestFieldValueEncodingAppliesToAllAPIs(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(Options{Format: FormatJSON, Output: &buf, Fields: map[string]interface{}{"default": time.Minute}})
+
+	scope := PushFields("mdc", 2*time.Second)
+	defer scope.Pop()
+	l.With("with", time.Millisecond).WithFields(map[string]interface{}{"map": 3 * time.Hour}).Info("all")
+
+	fields := renderedFields(t, &buf)
+	for key, want := range map[string]string{"default": "1m0s", "mdc": "2s", "with": "1ms", "map": "3h0m0s"} {
+		if fields[key] != want {
+			t.Errorf("%s: expected %q, got %v", key, want, fields[key])
+		}
+	}
+}
+
+func TestFieldValueEncodingOptions(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(Options{Format: FormatJSON, Output: &buf, DurationEncoding: DurationSeconds, BytesEncoding: BytesHex})
+
+	l.Info("options", "d", 1500*time.Millisecond, "short", []byte{0xde, 0xad}, "long", bytes.Repeat([]byte{0xab}, MaxHexBytes+6))
+	fields := renderedFields(t, &buf)
+	if fields["d"] != 1.5 {
+		t.Errorf("Expected float seconds, got %v", fields["d"])
+	}
+	if fields["short"] != "dead" {
+		t.Errorf("Expected hex bytes, got %v", fields["short"])
+	}
+	if want := strings.Repeat("ab", MaxHexBytes) + "...(70 bytes)"; fields["long"] != want {
+		t.Errorf("Expected truncated hex, got %v", fields["long"])
+	}
+}
+
+func TestRawFieldEncoding(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(Options{Format: FormatJSON, Output: &buf, RawFieldEncoding: true, BytesEncoding: BytesHex})
+
+	l.Info("raw", "d", 1500*time.Millisecond, "errs", []error{errors.New("a")}, "b", []byte("hi"), "m", money{Cents: 1})
+	fields := renderedFields(t, &buf)
+	if fields["d"] != 1.5 || fields["b"] != "aGk=" {
+		t.Errorf("Expected zap default encoding, got %v", fields)
+	}
+	if got := fmt.Sprint(fields["errs"]); got != "[map[error:a]]" {
+		t.Errorf("Expected zap error objects, got %s", got)
+	}
+	if got := fmt.Sprint(fields["m"]); got != "map[Cents:1]" {
+		t.Errorf("Expected struct fields, got %s", got)
+	}
+}
+
+func TestNormalizeKeysAndValuesNoCopy(t *testing.T) {
+	fields := []interface{}{"a", "x", "b", 1}
+	out := valueEncoding{}.normalizeKeysAndValues(fields)
+	if &out[0] != &fields[0] {
+		t.Error("Expected fields without conversions to be returned as is")
+	}
+
+	fields = []interface{}{"a", "x", "d", time.Second}
+	out = valueEncoding{}.normalizeKeysAndValues(fields)
+	if out[3] != "1s" || fields[3] != time.Second {
+		t.Errorf("Expected a converted copy, got %v (original %v)", out, fields)
+	}
+}
@@ -0,0 +1,166 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// flushRegistry 需要在进程退出前刷新的日志记录器
+var flushRegistry = struct {
+	mu      sync.Mutex
+	loggers []*Logger
+}{}
+
+// RegisterForFlush 注册日志记录器，FlushAll 和 FlushOnSignal 会同步其缓冲区
+func RegisterForFlush(l *Logger) {
+	if l == nil {
+		return
+	}
+
+	flushRegistry.mu.Lock()
+	defer flushRegistry.mu.Unlock()
+
+	for _, registered := range flushRegistry.loggers {
+		if registered == l {
+			return
+		}
+	}
+	flushRegistry.loggers = append(flushRegistry.loggers, l)
+}
+
+// UnregisterForFlush 取消注册日志记录器
+func UnregisterForFlush(l *Logger) {
+	flushRegistry.mu.Lock()
+	defer flushRegistry.mu.Unlock()
+
+	for i, registered := range flushRegistry.loggers {
+		if registered == l {
+			flushRegistry.loggers = append(flushRegistry.loggers[:i], flushRegistry.loggers[i+1:]...)
+			return
+		}
+	}
+}
+
+// FlushAll 同步全局日志记录器和所有已注册的日志记录器
+func FlushAll() error {
+	flushRegistry.mu.Lock()
+	loggers := append([]*Logger{defaultLogger}, flushRegistry.loggers...)
+	flushRegistry.mu.Unlock()
+
+	var errs []error
+	for _, l := range loggers {
+		if l == nil {
+			continue
+		}
+		if err := l.Sync(); err != nil && !isIgnorableSyncError(err) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FlushOnSignal 收到指定信号时刷新所有日志，然后重新发送该信号，让进程按原有方式退出
+// 未指定信号时使用 SIGINT 和 SIGTERM，返回的函数用于取消监听
+//
+// 如果同时使用 httpserver.RunWithGracefulShutdown，服务器也会收到同一信号并开始优雅关闭，
+// 此时更推荐在 RunWithGracefulShutdown 返回后调用 FlushAll 或 Logger.Close，
+// 以确保关闭过程中产生的日志也被刷新
+func FlushOnSignal(signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+
+	done := make(chan struct{})
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+
+	go func() {
+		select {
+		case sig := <-ch:
+			FlushAll()
+			stop()
+			// 重新发送信号：没有其他监听者时恢复默认行为（终止进程）
+			if p, err := os.FindProcess(os.Getpid()); err == nil {
+				p.Signal(sig)
+			}
+		case <-done:
+		}
+	}()
+
+	return stop
+}
+
+// periodicFlusher 定期同步日志缓冲区
+type periodicFlusher struct {
+	ticker *time.Ticker
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// startPeriodicFlush 启动后台定期刷新
+func startPeriodicFlush(l *Logger, interval time.Duration) *periodicFlusher {
+	f := &periodicFlusher{
+		ticker: time.NewTicker(interval),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(f.done)
+		for {
+			select {
+			case <-f.ticker.C:
+				l.zap.Sync()
+			case <-f.stop:
+				return
+			}
+		}
+	}()
+
+	return f
+}
+
+// close 停止定期刷新并等待后台goroutine退出，可重复调用
+func (f *periodicFlusher) close() {
+	f.once.Do(func() {
+		f.ticker.Stop()
+		close(f.stop)
+	})
+	<-f.done
+}
+
+// Close 停止定期刷新（如果启用了 FlushInterval）并同步缓冲区，可重复调用
+func (l *Logger) Close() error {
+	if l.flusher != nil {
+		l.flusher.close()
+	}
+	UnregisterForFlush(l)
+
+	err := l.Sync()
+	if l.syslog != nil {
+		l.syslog.Close()
+	}
+	if err != nil && !isIgnorableSyncError(err) {
+		return err
+	}
+	return nil
+}
+
+// isIgnorableSyncError 判断是否为可忽略的同步错误
+// 对终端或管道形式的 stdout/stderr 调用 fsync 会返回 EINVAL 或 ENOTTY
+func isIgnorableSyncError(err error) bool {
+	return errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY)
+}
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTempFileLogger(t *testing.T, interval time.Duration) (*Logger, string) {
+	t.Helper()
+
+	logPath := filepath.Join(t.TempDir(), "flush.log")
+	l := NewWithOptions(Options{
+		Level:            InfoLevel,
+		Format:           FormatJSON,
+		EnableFileOutput: true,
+		FlushInterval:    interval,
+		Rotate: &RotateConfig{
+			Filename:   logPath,
+			MaxSize:    1,
+			MaxBackups: 1,
+		},
+	})
+	return l, logPath
+}
+
+func TestPeriodicFlush(t *testing.T) {
+	l, logPath := newTempFileLogger(t, 10*time.Millisecond)
+	defer l.Close()
+
+	l.Info("periodic flush message")
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		content, err := os.ReadFile(logPath)
+		if err == nil && strings.Contains(string(content), "periodic flush message") {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Expected log line to reach disk")
+}
+
+func TestCloseStopsFlusher(t *testing.T) {
+	l, _ := newTempFileLogger(t, 10*time.Millisecond)
+	if l.flusher == nil {
+		t.Fatal("Expected flusher to be started")
+	}
+
+	if err := l.Close(); err != nil {
+		t.Errorf("Expected no error on Close, got %v", err)
+	}
+
+	select {
+	case <-l.flusher.done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected flush goroutine to stop after Close")
+	}
+
+	// 重复调用不应panic或阻塞
+	if err := l.Close(); err != nil {
+		t.Errorf("Expected no error on second Close, got %v", err)
+	}
+}
+
+func TestCloseWithoutFlushInterval(t *testing.T) {
+	l, _ := newTempFileLogger(t, 0)
+	if l.flusher != nil {
+		t.Fatal("Expected no flusher without FlushInterval")
+	}
+	if err := l.Close(); err != nil {
+		t.Errorf("Expected no error on Close, got %v", err)
+	}
+}
+
+func TestRegisterForFlush(t *testing.T) {
+	l, logPath := newTempFileLogger(t, 0)
+	RegisterForFlush(l)
+	RegisterForFlush(l)
+	defer UnregisterForFlush(l)
+
+	flushRegistry.mu.Lock()
+	count := 0
+	for _, registered := range flushRegistry.loggers {
+		if registered == l {
+			count++
+		}
+	}
+	flushRegistry.mu.Unlock()
+	if count != 1 {
+		t.Errorf("Expected logger to be registered once, got %d", count)
+	}
+
+	l.Info("flush all message")
+	if err := FlushAll(); err != nil {
+		t.Errorf("Expected no error on FlushAll, got %v", err)
+	}
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "flush all message") {
+		t.Error("Expected log line to be flushed")
+	}
+}
+
+func TestFlushOnSignalStop(t *testing.T) {
+	stop := FlushOnSignal()
+	stop()
+	// 重复调用不应panic
+	stop()
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestGoroutineID(t *testing.T) {
+	main := goroutineID()
+	if main == 0 {
+		t.Fatal("Expected non-zero goroutine id")
+	}
+
+	var other uint64
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		other = goroutineID()
+	}()
+	wg.Wait()
+
+	if other == 0 || other == main {
+		t.Errorf("Expected distinct goroutine ids, got %d and %d", main, other)
+	}
+}
+
+func TestIncludeGoroutineID(t *testing.T) {
+	l, read := newLimitedLogger(t, Options{IncludeGoroutineID: true})
+	l.With("k", "v").Info("with gid")
+	l.Debug("filtered")
+
+	entries := read()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	id, ok := entries[0][goroutineIDKey].(float64)
+	if !ok || uint64(id) != goroutineID() {
+		t.Errorf("Expected goroutine_id %d, got %v", goroutineID(), entries[0][goroutineIDKey])
+	}
+	if entries[0]["k"] != "v" {
+		t.Errorf("Expected other fields kept, got %v", entries[0])
+	}
+}
+
+func TestGoroutineIDDisabledByDefault(t *testing.T) {
+	l, read := newLimitedLogger(t, Options{})
+	l.Info("no gid")
+
+	if _, ok := read()[0][goroutineIDKey]; ok {
+		t.Error("Expected no goroutine_id without IncludeGoroutineID")
+	}
+}
+
+func BenchmarkGoroutineID(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		goroutineID()
+	}
+}
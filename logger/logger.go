@@ -114,7 +114,7 @@ type Format string
 const (
 	// FormatJSON JSON格式输出
 	FormatJSON Format = "json"
-	// FormatConsole 控制台格式输出（带颜色）
+	// FormatConsole 控制台格式输出，颜色由 Options.Color 决定，默认只在终端中使用颜色
 	FormatConsole Format = "console"
 	// FormatText 文本格式输出（不带颜色）
 	FormatText Format = "text"
@@ -191,12 +191,66 @@ type Options struct {
 	Format           Format                 // 输出格式 (FormatJSON, FormatConsole, FormatText)
 	TimeFormat       string                 // 时间格式
 	Caller           bool                   // 是否显示调用者信息
-	Stacktrace       bool                   // 是否显示堆栈跟踪
+	Stacktrace       bool                   // 是否自动附加堆栈跟踪（级别由 StacktraceLevel 决定）
 	EnableFileOutput bool                   // 是否启用文件输出
 	Sampling         *SamplingConfig        // 采样配置
 	Rotate           *RotateConfig          // 日志轮转配置
 	Fields           map[string]interface{} // 默认字段
 	Hooks            []Hook                 // 钩子函数
+	FlushInterval    time.Duration          // 定期同步缓冲区的间隔，0表示不启用，需调用 Close 停止
+	// DisableContextExtraction 禁用 WithContext 的上下文字段提取（使用 NopExtractor），同时忽略 ContextFields
+	DisableContextExtraction bool
+	// ContextFields WithContext 在默认字段之外额外提取的上下文字段，键为输出字段名，值为上下文键，
+	// 例如 {"tenant_id": ContextKey("tenant_id")}
+	ContextFields   map[string]interface{}
+	MaxFieldBytes   int // 字段值（字符串、字节切片、错误、JSON编码的对象）的最大字节数，0表示不限制
+	MaxFieldDepth   int // 嵌套对象的最大层级，超出部分替换为 "..."，0表示不限制
+	MaxMessageBytes int // 日志消息的最大字节数，0表示不限制
+	// DedupWindow 抑制窗口内级别和消息都相同的重复日志并输出汇总（见 Every），0表示不启用
+	DedupWindow time.Duration
+	// IncludeGoroutineID 为每条日志添加 goroutine_id 字段，仅在日志实际输出时获取（每条约数微秒）
+	IncludeGoroutineID bool
+	// IncludeBuildInfo 在 BuildInfo 为nil时从 runtime/debug.ReadBuildInfo 读取构建信息
+	IncludeBuildInfo bool
+	// BuildInfo 构建信息，作为 service.version、service.commit 等静态字段添加到每条日志
+	BuildInfo *BuildInfo
+	// Output 替代标准输出的写入目标（例如测试中的 loggertest.Recorder），文件输出不受影响
+	Output io.Writer
+	// Color FormatConsole 的颜色模式，默认 ColorAuto；文件输出始终不带颜色
+	Color ColorMode
+	// SortFields 输出前按键排序字段，使相同事件的日志逐字节一致，便于对比和按行去重；
+	// 对所有格式生效，只影响 stdout 和文件输出，默认关闭以避免排序开销
+	SortFields bool
+	// FieldPriority SortFields 开启时排在最前面的字段（按列表顺序），其余按字母顺序；nil 使用 DefaultFieldPriority
+	FieldPriority []string
+	// StacktraceLevel Stacktrace 为true时附加堆栈的最低级别，零值（InfoLevel）表示默认的 ErrorLevel；
+	// 需要 Info 及以上都附加堆栈时设置为 DebugLevel
+	StacktraceLevel Level
+	// ErrorReporter 把达到 ErrorReportLevel 的日志（包括字段和错误堆栈）转发到错误追踪服务，
+	// 例如 sentryhook.New 创建的 Sentry 上报器；与 Hooks 不同，可以拿到完整的字段
+	ErrorReporter ErrorReporter
+	// ErrorReportLevel 上报的最低级别，零值（InfoLevel）表示默认的 ErrorLevel，不受 Level 影响
+	ErrorReportLevel Level
+	// DurationEncoding time.Duration 字段值的输出方式，默认 DurationString（"1.5s"）
+	DurationEncoding DurationEncoding
+	// BytesEncoding []byte 字段值的输出方式，默认 BytesBase64
+	BytesEncoding BytesEncoding
+	// RawFieldEncoding 不规范化字段值，保持 zap 的默认编码（时长为浮点秒数、[]error 为对象数组等），
+	// 此时 DurationEncoding 和 BytesEncoding 不生效
+	RawFieldEncoding bool
+	// Syslog 额外输出到 syslog，按日志级别设置严重程度（不支持 Windows）；连接失败时输出到 stderr 并跳过
+	Syslog *SyslogConfig
+	// Journald 标准输出的每行前加上 "<N>" 优先级前缀，由 systemd 启动时 journald 据此设置日志优先级；
+	// 同时关闭颜色
+	Journald bool
+}
+
+// stacktraceLevel 返回自动附加堆栈的最低级别
+func (o Options) stacktraceLevel() zapcore.Level {
+	if o.StacktraceLevel == InfoLevel {
+		return zapcore.ErrorLevel
+	}
+	return convertLevel(o.StacktraceLevel)
 }
 
 // SamplingConfig 采样配置
@@ -215,64 +269,77 @@ type ContextExtractor interface {
 }
 
 // DefaultContextExtractor 默认上下文提取器
+//
+// 提取 trace_id、request_id、span_id、user_id，各字段的键优先级:
+//   - trace_id: constants.TraceIDKey > ContextKey("trace_id") > "traceId" > "x-trace-id"
+//   - request_id: constants.RequestIDKey > ContextKey("request_id") > "requestId" > "x-request-id"
+//   - span_id: "spanId" > ContextKey("span_id")
+//   - user_id: "userId" > ContextKey("user_id")
+//
+// 上下文中没有任何字段时返回nil，不分配内存。
 type DefaultContextExtractor struct{}
 
 // Extract 从context中提取信息
 func (d *DefaultContextExtractor) Extract(ctx context.Context) map[string]interface{} {
-	fields := make(map[string]interface{})
-
-	// 提取 trace_id（优先使用 constants 包定义的键，保持向后兼容性）
-	if traceID := constants.TraceIDFromContext(ctx); traceID != "" {
-		fields["trace_id"] = traceID
-	} else if traceID := ctx.Value(ContextKey("trace_id")); traceID != nil {
-		fields["trace_id"] = traceID
-	} else if traceID := ctx.Value("traceId"); traceID != nil {
-		fields["trace_id"] = traceID
-	} else if traceID := ctx.Value("x-trace-id"); traceID != nil {
-		fields["trace_id"] = traceID
+	var fields map[string]interface{}
+	set := func(key string, value interface{}) {
+		if fields == nil {
+			fields = make(map[string]interface{}, 4)
+		}
+		fields[key] = value
 	}
 
-	// 提取 request_id（优先使用 constants 包定义的键，保持向后兼容性）
-	if requestID := constants.RequestIDFromContext(ctx); requestID != "" {
-		fields["request_id"] = requestID
-	} else if requestID := ctx.Value(ContextKey("request_id")); requestID != nil {
-		fields["request_id"] = requestID
-	} else if requestID := ctx.Value("requestId"); requestID != nil {
-		fields["request_id"] = requestID
-	} else if requestID := ctx.Value("x-request-id"); requestID != nil {
-		fields["request_id"] = requestID
+	// constants 包定义的键覆盖绝大多数请求，命中时跳过兼容键的查找
+	// 直接保存 ctx.Value 返回的接口值，避免字符串重新装箱
+	if traceID := ctx.Value(constants.TraceIDKey); isNonEmptyString(traceID) {
+		set("trace_id", traceID)
+	} else if traceID := firstValue(ctx, ContextKey("trace_id"), "traceId", "x-trace-id"); traceID != nil {
+		set("trace_id", traceID)
 	}
 
-	// 提取span信息
-	if spanID := ctx.Value(ContextKey("span_id")); spanID != nil {
-		fields["span_id"] = spanID
-	}
-	if spanID := ctx.Value("spanId"); spanID != nil {
-		fields["span_id"] = spanID
+	if requestID := ctx.Value(constants.RequestIDKey); isNonEmptyString(requestID) {
+		set("request_id", requestID)
+	} else if requestID := firstValue(ctx, ContextKey("request_id"), "requestId", "x-request-id"); requestID != nil {
+		set("request_id", requestID)
 	}
 
-	// 提取request_id
-	if requestID := ctx.Value(ContextKey("request_id")); requestID != nil {
-		fields["request_id"] = requestID
-	}
-	if requestID := ctx.Value("requestId"); requestID != nil {
-		fields["request_id"] = requestID
+	if spanID := firstValue(ctx, "spanId", ContextKey("span_id")); spanID != nil {
+		set("span_id", spanID)
 	}
-	if requestID := ctx.Value("x-request-id"); requestID != nil {
-		fields["request_id"] = requestID
+	if userID := firstValue(ctx, "userId", ContextKey("user_id")); userID != nil {
+		set("user_id", userID)
 	}
 
-	// 提取user_id
-	if userID := ctx.Value(ContextKey("user_id")); userID != nil {
-		fields["user_id"] = userID
-	}
-	if userID := ctx.Value("userId"); userID != nil {
-		fields["user_id"] = userID
+	return fields
+}
+
+// firstValue 按顺序查找第一个非nil的上下文值
+func firstValue(ctx context.Context, keys ...interface{}) interface{} {
+	for _, key := range keys {
+		if value := ctx.Value(key); value != nil {
+			return value
+		}
 	}
+	return nil
+}
 
-	return fields
+// isNonEmptyString 判断值是否为非空字符串，与 constants.TraceIDFromContext 的判断一致
+func isNonEmptyString(value interface{}) bool {
+	str, ok := value.(string)
+	return ok && str != ""
 }
 
+// NopExtractor 不提取任何字段的上下文提取器，用于不需要上下文字段的服务
+type NopExtractor struct{}
+
+// Extract 始终返回nil
+func (NopExtractor) Extract(ctx context.Context) map[string]interface{} {
+	return nil
+}
+
+// callerSkip 日志方法（或包级函数）与 logw/logf 两层封装需要跳过的调用栈深度
+const callerSkip = 2
+
 // Logger 日志管理器
 type Logger struct {
 	zap          *zap.Logger
@@ -283,6 +350,8 @@ type Logger struct {
 	hooks        []Hook
 	ctx          context.Context  // 当前上下文
 	ctxExtractor ContextExtractor // 上下文信息提取器
+	flusher      *periodicFlusher // 定期刷新器
+	syslog       io.Closer        // syslog 连接，未启用时为nil
 }
 
 // New 创建新的日志管理器
@@ -305,28 +374,39 @@ func NewWithOptions(opts Options) *Logger {
 		ctx:          context.Background(),
 		ctxExtractor: &DefaultContextExtractor{},
 	}
+	if opts.DisableContextExtraction {
+		logger.ctxExtractor = NopExtractor{}
+	} else if len(opts.ContextFields) > 0 {
+		logger.ctxExtractor = NewContextFieldsExtractor(logger.ctxExtractor, opts.ContextFields)
+	}
 
 	// 构建编码器配置
 	encoderConfig := logger.buildEncoderConfig()
 
-	// 构建编码器
-	var encoder zapcore.Encoder
-	switch opts.Format {
-	case FormatJSON:
-		encoder = zapcore.NewJSONEncoder(encoderConfig)
-	case FormatConsole:
-		encoder = zapcore.NewConsoleEncoder(encoderConfig)
-	case FormatText:
-		encoder = zapcore.NewConsoleEncoder(encoderConfig)
-	default:
-		encoder = zapcore.NewConsoleEncoder(encoderConfig)
-	}
+	// 构建核心，每个输出目标单独编码
+	core := logger.buildCore(encoderConfig)
+
+	// 按键排序字段（未开启时不包装），位于最内层，之后添加的字段同样参与排序
+	core = newSortingCore(core, opts.SortFields, opts.FieldPriority)
+
+	// 限制字段和消息大小（未设置限制时不包装）
+	core = newTruncatingCore(core, truncateLimits{
+		maxFieldBytes:   opts.MaxFieldBytes,
+		maxFieldDepth:   opts.MaxFieldDepth,
+		maxMessageBytes: opts.MaxMessageBytes,
+	})
 
-	// 构建输出
-	writer := logger.buildWriter()
+	// 转发错误日志（未设置时不包装），位于 MDC 之内，上报的事件同样包含协程字段
+	core = newReporterCore(core, opts.ErrorReporter, opts.errorReportLevel())
 
-	// 构建核心
-	core := zapcore.NewCore(encoder, writer, logger.level)
+	// 添加 PushFields 压入的协程字段，位于截断之外，这些字段同样受大小限制
+	core = newMDCCore(core, opts.valueEncoding())
+
+	// 添加协程ID，位于去重和采样之内，被丢弃的日志不会获取协程ID
+	core = newGoroutineCore(core, opts.IncludeGoroutineID)
+
+	// 抑制重复日志
+	core = newDedupCore(core, opts.DedupWindow)
 
 	// 应用采样
 	if opts.Sampling != nil {
@@ -336,28 +416,42 @@ func NewWithOptions(opts Options) *Logger {
 	// 构建zap logger
 	zapLogger := zap.New(core)
 
+	// 跳过封装层，调用者信息和堆栈都从用户代码的调用处开始（未开启 Caller 时同样需要）
+	zapLogger = zapLogger.WithOptions(zap.AddCallerSkip(callerSkip))
+
 	// 添加调用者信息
 	if opts.Caller {
-		zapLogger = zapLogger.WithOptions(zap.AddCaller(), zap.AddCallerSkip(1))
+		zapLogger = zapLogger.WithOptions(zap.AddCaller())
 	}
 
 	// 添加堆栈跟踪
 	if opts.Stacktrace {
-		zapLogger = zapLogger.WithOptions(zap.AddStacktrace(zapcore.ErrorLevel))
+		zapLogger = zapLogger.WithOptions(zap.AddStacktrace(opts.stacktraceLevel()))
 	}
 
 	// 添加默认字段
 	if opts.Fields != nil {
 		fields := make([]zap.Field, 0, len(opts.Fields))
+		encoding := opts.valueEncoding()
 		for key, value := range opts.Fields {
-			fields = append(fields, zap.Any(key, value))
+			fields = append(fields, encoding.field(key, value))
 		}
 		zapLogger = zapLogger.With(fields...)
 	}
 
+	// 添加构建信息
+	if fields := opts.buildInfo().fields(); len(fields) > 0 {
+		zapLogger = zapLogger.With(fields...)
+	}
+
 	logger.zap = zapLogger
 	logger.sugar = zapLogger.Sugar()
 
+	// 启动定期刷新
+	if opts.FlushInterval > 0 {
+		logger.flusher = startPeriodicFlush(logger, opts.FlushInterval)
+	}
+
 	return logger
 }
 
@@ -385,7 +479,7 @@ func (l *Logger) buildEncoderConfig() zapcore.EncoderConfig {
 	// 根据格式调整编码器
 	switch l.config.Format {
 	case FormatConsole:
-		config.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		config.EncodeLevel = zapcore.CapitalLevelEncoder
 		config.EncodeCaller = zapcore.ShortCallerEncoder
 	case FormatJSON:
 		config.EncodeLevel = zapcore.LowercaseLevelEncoder
@@ -395,35 +489,70 @@ func (l *Logger) buildEncoderConfig() zapcore.EncoderConfig {
 	return config
 }
 
-// buildWriter 构建输出写入器
-func (l *Logger) buildWriter() zapcore.WriteSyncer {
-	// 始终输出到stdout
-	writers := []zapcore.WriteSyncer{zapcore.AddSync(os.Stdout)}
+// buildEncoder 构建编码器，color 为true时级别带颜色
+func (l *Logger) buildEncoder(config zapcore.EncoderConfig, color bool) zapcore.Encoder {
+	if l.config.Format == FormatJSON {
+		return zapcore.NewJSONEncoder(config)
+	}
+	if color {
+		config.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+	return zapcore.NewConsoleEncoder(config)
+}
+
+// buildCore 构建输出核心
+// 标准输出、文件和 syslog 各用一个核心，颜色按写入目标分别判断，文件中不会出现颜色控制字符
+func (l *Logger) buildCore(config zapcore.EncoderConfig) zapcore.Core {
+	// 输出到stdout，设置了 Output 时输出到 Output
+	var out io.Writer = os.Stdout
+	if l.config.Output != nil {
+		out = l.config.Output
+	}
+	var core zapcore.Core
+	if l.config.Journald {
+		core = newJournaldCore(l.buildEncoder(config, false), out, l.level)
+	} else {
+		core = zapcore.NewCore(l.buildEncoder(config, l.config.useColor(out)), zapcore.AddSync(out), l.level)
+	}
 
-	// 如果启用文件输出，添加文件写入器
-	if l.config.EnableFileOutput {
-		if l.config.Rotate != nil {
-			writers = append(writers, zapcore.AddSync(l.buildRotateWriter()))
+	cores := []zapcore.Core{core}
+	if file := l.buildFileWriter(); file != nil {
+		cores = append(cores, zapcore.NewCore(l.buildEncoder(config, false), file, l.level))
+	}
+	if l.config.Syslog != nil {
+		sender, err := dialSyslog(l.config.Syslog)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "连接 syslog 失败: %v\n", err)
 		} else {
-			// 如果没有轮转配置，使用默认文件
-			logPath := GetDefaultLogPath()
-			// 确保日志目录存在
-			if err := EnsureLogDirForPath(logPath); err == nil {
-				file, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-				if err == nil {
-					writers = append(writers, zapcore.AddSync(file))
-				}
-			}
+			l.syslog = sender
+			cores = append(cores, newSyslogCore(l.buildEncoder(config, false), sender, l.level))
 		}
 	}
+	if len(cores) == 1 {
+		return core
+	}
+	return zapcore.NewTee(cores...)
+}
 
-	// 如果只有一个写入器，直接返回
-	if len(writers) == 1 {
-		return writers[0]
+func (l *Logger) buildFileWriter() zapcore.WriteSyncer {
+	if !l.config.EnableFileOutput {
+		return nil
+	}
+	if l.config.Rotate != nil {
+		return zapcore.AddSync(l.buildRotateWriter())
 	}
 
-	// 多个写入器，使用MultiWriteSyncer
-	return zapcore.NewMultiWriteSyncer(writers...)
+	// 如果没有轮转配置，使用默认文件
+	logPath := GetDefaultLogPath()
+	// 确保日志目录存在
+	if err := EnsureLogDirForPath(logPath); err != nil {
+		return nil
+	}
+	file, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return nil
+	}
+	return zapcore.AddSync(file)
 }
 
 // buildRotateWriter 构建轮转写入器
@@ -486,92 +615,115 @@ func (l *Logger) GetLevel() Level {
 
 // Debug 输出调试日志
 func (l *Logger) Debug(msg string, fields ...interface{}) {
-	l.executeHooks(zapcore.DebugLevel, msg)
-	l.sugar.Debugw(msg, fields...)
+	l.logw(zapcore.DebugLevel, msg, fields)
 }
 
 // Info 输出信息日志
 func (l *Logger) Info(msg string, fields ...interface{}) {
-	l.executeHooks(zapcore.InfoLevel, msg)
-	l.sugar.Infow(msg, fields...)
+	l.logw(zapcore.InfoLevel, msg, fields)
 }
 
 // Warn 输出警告日志
 func (l *Logger) Warn(msg string, fields ...interface{}) {
-	l.executeHooks(zapcore.WarnLevel, msg)
-	l.sugar.Warnw(msg, fields...)
+	l.logw(zapcore.WarnLevel, msg, fields)
 }
 
 // Error 输出错误日志
 func (l *Logger) Error(msg string, fields ...interface{}) {
-	l.executeHooks(zapcore.ErrorLevel, msg)
-	l.sugar.Errorw(msg, fields...)
+	l.logw(zapcore.ErrorLevel, msg, fields)
 }
 
 // Fatal 输出致命错误日志并退出
 func (l *Logger) Fatal(msg string, fields ...interface{}) {
-	l.executeHooks(zapcore.FatalLevel, msg)
-	l.sugar.Fatalw(msg, fields...)
+	l.logw(zapcore.FatalLevel, msg, fields)
 }
 
 // Panic 输出panic日志并panic
 func (l *Logger) Panic(msg string, fields ...interface{}) {
-	l.executeHooks(zapcore.PanicLevel, msg)
-	l.sugar.Panicw(msg, fields...)
+	l.logw(zapcore.PanicLevel, msg, fields)
 }
 
 // === 格式化日志方法 ===
 
 // Debugf 输出格式化调试日志
 func (l *Logger) Debugf(format string, args ...interface{}) {
-	if l.IsEnabled(DebugLevel) {
-		msg := fmt.Sprintf(format, args...)
-		l.executeHooks(zapcore.DebugLevel, msg)
-		l.sugar.Debug(msg)
-	}
+	l.logf(zapcore.DebugLevel, format, args)
 }
 
 // Infof 输出格式化信息日志
 func (l *Logger) Infof(format string, args ...interface{}) {
-	if l.IsEnabled(InfoLevel) {
-		msg := fmt.Sprintf(format, args...)
-		l.executeHooks(zapcore.InfoLevel, msg)
-		l.sugar.Info(msg)
-	}
+	l.logf(zapcore.InfoLevel, format, args)
 }
 
 // Warnf 输出格式化警告日志
 func (l *Logger) Warnf(format string, args ...interface{}) {
-	if l.IsEnabled(WarnLevel) {
-		msg := fmt.Sprintf(format, args...)
-		l.executeHooks(zapcore.WarnLevel, msg)
-		l.sugar.Warn(msg)
-	}
+	l.logf(zapcore.WarnLevel, format, args)
 }
 
 // Errorf 输出格式化错误日志
 func (l *Logger) Errorf(format string, args ...interface{}) {
-	if l.IsEnabled(ErrorLevel) {
-		msg := fmt.Sprintf(format, args...)
-		l.executeHooks(zapcore.ErrorLevel, msg)
-		l.sugar.Error(msg)
-	}
+	l.logf(zapcore.ErrorLevel, format, args)
 }
 
 // Fatalf 输出格式化致命错误日志并退出
 func (l *Logger) Fatalf(format string, args ...interface{}) {
-	if l.IsEnabled(FatalLevel) {
-		msg := fmt.Sprintf(format, args...)
-		l.executeHooks(zapcore.FatalLevel, msg)
-		l.sugar.Fatal(msg)
-	}
+	l.logf(zapcore.FatalLevel, format, args)
 }
 
 // Panicf 输出格式化panic日志并panic
 func (l *Logger) Panicf(format string, args ...interface{}) {
+	l.logf(zapcore.PanicLevel, format, args)
+}
+
+// logw 输出结构化日志
+//
+// 所有日志方法和包级函数都恰好经过一层调用后进入 logw，
+// 因此调用栈深度一致，callerSkip 能准确指向用户代码
+func (l *Logger) logw(level zapcore.Level, msg string, fields []interface{}) {
+	l.executeHooks(level, msg)
+	if level >= zapcore.PanicLevel || l.level.Enabled(level) {
+		fields = l.config.valueEncoding().normalizeKeysAndValues(l.normalizeFields(msg, fields))
+	}
+
+	switch level {
+	case zapcore.DebugLevel:
+		l.sugar.Debugw(msg, fields...)
+	case zapcore.InfoLevel:
+		l.sugar.Infow(msg, fields...)
+	case zapcore.WarnLevel:
+		l.sugar.Warnw(msg, fields...)
+	case zapcore.ErrorLevel:
+		l.sugar.Errorw(msg, fields...)
+	case zapcore.FatalLevel:
+		l.sugar.Fatalw(msg, fields...)
+	case zapcore.PanicLevel:
+		l.sugar.Panicw(msg, fields...)
+	}
+}
+
+// logf 输出格式化日志，级别未启用时跳过格式化
+func (l *Logger) logf(level zapcore.Level, format string, args []interface{}) {
+	if level != zapcore.PanicLevel && !l.level.Enabled(level) {
+		return
+	}
+
 	msg := fmt.Sprintf(format, args...)
-	l.executeHooks(zapcore.PanicLevel, msg)
-	l.sugar.Panic(msg)
+	l.executeHooks(level, msg)
+
+	switch level {
+	case zapcore.DebugLevel:
+		l.sugar.Debug(msg)
+	case zapcore.InfoLevel:
+		l.sugar.Info(msg)
+	case zapcore.WarnLevel:
+		l.sugar.Warn(msg)
+	case zapcore.ErrorLevel:
+		l.sugar.Error(msg)
+	case zapcore.FatalLevel:
+		l.sugar.Fatal(msg)
+	case zapcore.PanicLevel:
+		l.sugar.Panic(msg)
+	}
 }
 
 // executeHooks 执行钩子函数
@@ -597,12 +749,13 @@ func (l *Logger) executeHooks(level zapcore.Level, msg string) {
 // With 创建带字段的日志记录器
 func (l *Logger) With(fields ...interface{}) *Logger {
 	newLogger := &Logger{
-		zap:          l.zap.Sugar().With(fields...).Desugar(),
+		zap:          l.zap.Sugar().With(l.config.valueEncoding().normalizeKeysAndValues(fields)...).Desugar(),
 		level:        l.level,
 		config:       l.config,
 		hooks:        l.hooks,
 		ctx:          l.ctx,
 		ctxExtractor: l.ctxExtractor,
+		flusher:      l.flusher,
 	}
 	newLogger.sugar = newLogger.zap.Sugar()
 	return newLogger
@@ -610,18 +763,24 @@ func (l *Logger) With(fields ...interface{}) *Logger {
 
 // WithFields 创建带字段的日志记录器
 func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	encoding := l.config.valueEncoding()
 	zapFields := make([]zap.Field, 0, len(fields))
 	for key, value := range fields {
-		zapFields = append(zapFields, zap.Any(key, value))
+		zapFields = append(zapFields, encoding.field(key, value))
 	}
+	return l.withZapFields(zapFields)
+}
 
+// withZapFields 创建带 zap 字段的日志记录器
+func (l *Logger) withZapFields(fields []zap.Field) *Logger {
 	newLogger := &Logger{
-		zap:          l.zap.With(zapFields...),
+		zap:          l.zap.With(fields...),
 		level:        l.level,
 		config:       l.config,
 		hooks:        l.hooks,
 		ctx:          l.ctx,
 		ctxExtractor: l.ctxExtractor,
+		flusher:      l.flusher,
 	}
 	newLogger.sugar = newLogger.zap.Sugar()
 	return newLogger
@@ -639,31 +798,61 @@ func (l *Logger) WithContext(ctx context.Context) *Logger {
 	// 创建新的logger
 	newLogger := &Logger{
 		zap:          l.zap,
+		sugar:        l.sugar,
 		level:        l.level,
 		config:       l.config,
 		hooks:        l.hooks,
 		ctx:          ctx,
 		ctxExtractor: l.ctxExtractor,
+		flusher:      l.flusher,
 	}
 
-	// 如果有上下文字段，添加到logger中
+	// 如果有上下文字段，添加到logger中；否则复用原有的sugar，避免额外分配
 	if len(ctxFields) > 0 {
+		encoding := l.config.valueEncoding()
 		zapFields := make([]zap.Field, 0, len(ctxFields))
 		for key, value := range ctxFields {
-			zapFields = append(zapFields, zap.Any(key, value))
+			zapFields = append(zapFields, encoding.field(key, value))
 		}
 		newLogger.zap = l.zap.With(zapFields...)
+		newLogger.sugar = newLogger.zap.Sugar()
+	} else if newLogger.sugar == nil {
+		newLogger.sugar = newLogger.zap.Sugar()
 	}
 
-	newLogger.sugar = newLogger.zap.Sugar()
 	return newLogger
 }
 
 // WithError 创建带错误字段的日志记录器
+//
+// 错误（或其 Unwrap 链中的错误）携带堆栈时，额外输出 stack 字段，
+// 支持实现 StackTrace() 方法的错误（例如 pkg/errors）和本库的 *errors.Error。
 func (l *Logger) WithError(err error) *Logger {
+	if stack := errorStack(err); stack != "" {
+		return l.With("error", err, "stack", stack)
+	}
 	return l.With("error", err)
 }
 
+// WithStack 创建所有级别的日志都附加调用处堆栈的日志记录器，不受 Options.Stacktrace 影响
+//
+// 示例:
+//
+//	logger.WithStack().Warn("重试次数过多", "attempts", n)
+func (l *Logger) WithStack() *Logger {
+	newLogger := &Logger{
+		zap:          l.zap.WithOptions(zap.AddStacktrace(zapcore.DebugLevel)),
+		level:        l.level,
+		config:       l.config,
+		hooks:        l.hooks,
+		ctx:          l.ctx,
+		ctxExtractor: l.ctxExtractor,
+		flusher:      l.flusher,
+	}
+	newLogger.sugar = newLogger.zap.Sugar()
+	return newLogger
+}
+
 // Named 创建命名的日志记录器
 func (l *Logger) Named(name string) *Logger {
 	newLogger := &Logger{
@@ -673,6 +862,7 @@ func (l *Logger) Named(name string) *Logger {
 		hooks:        l.hooks,
 		ctx:          l.ctx,
 		ctxExtractor: l.ctxExtractor,
+		flusher:      l.flusher,
 	}
 	newLogger.sugar = newLogger.zap.Sugar()
 	return newLogger
@@ -684,13 +874,14 @@ func (l *Logger) Sync() error {
 }
 
 // GetZap 获取底层zap日志记录器
+// 返回的记录器已去除封装层的 callerSkip，直接调用时调用者信息指向用户代码
 func (l *Logger) GetZap() *zap.Logger {
-	return l.zap
+	return l.zap.WithOptions(zap.AddCallerSkip(-callerSkip))
 }
 
 // GetSugar 获取底层sugar日志记录器
 func (l *Logger) GetSugar() *zap.SugaredLogger {
-	return l.sugar
+	return l.GetZap().Sugar()
 }
 
 // AddHook 添加钩子函数
@@ -725,6 +916,7 @@ func (l *Logger) Clone() *Logger {
 		hooks:        append([]Hook(nil), l.hooks...),
 		ctx:          l.ctx,
 		ctxExtractor: l.ctxExtractor,
+		flusher:      l.flusher,
 	}
 }
 
@@ -812,53 +1004,53 @@ func GetLevel() Level {
 }
 
 func Debug(msg string, fields ...interface{}) {
-	defaultLogger.Debug(msg, fields...)
+	defaultLogger.logw(zapcore.DebugLevel, msg, fields)
 }
 
 func Info(msg string, fields ...interface{}) {
-	defaultLogger.Info(msg, fields...)
+	defaultLogger.logw(zapcore.InfoLevel, msg, fields)
 }
 
 func Warn(msg string, fields ...interface{}) {
-	defaultLogger.Warn(msg, fields...)
+	defaultLogger.logw(zapcore.WarnLevel, msg, fields)
 }
 
 func Error(msg string, fields ...interface{}) {
-	defaultLogger.Error(msg, fields...)
+	defaultLogger.logw(zapcore.ErrorLevel, msg, fields)
 }
 
 func Fatal(msg string, fields ...interface{}) {
-	defaultLogger.Fatal(msg, fields...)
+	defaultLogger.logw(zapcore.FatalLevel, msg, fields)
 }
 
 func Panic(msg string, fields ...interface{}) {
-	defaultLogger.Panic(msg, fields...)
+	defaultLogger.logw(zapcore.PanicLevel, msg, fields)
 }
 
 // === 格式化全局函数 ===
 
 func Debugf(format string, args ...interface{}) {
-	defaultLogger.Debugf(format, args...)
+	defaultLogger.logf(zapcore.DebugLevel, format, args)
 }
 
 func Infof(format string, args ...interface{}) {
-	defaultLogger.Infof(format, args...)
+	defaultLogger.logf(zapcore.InfoLevel, format, args)
 }
 
 func Warnf(format string, args ...interface{}) {
-	defaultLogger.Warnf(format, args...)
+	defaultLogger.logf(zapcore.WarnLevel, format, args)
 }
 
 func Errorf(format string, args ...interface{}) {
-	defaultLogger.Errorf(format, args...)
+	defaultLogger.logf(zapcore.ErrorLevel, format, args)
 }
 
 func Fatalf(format string, args ...interface{}) {
-	defaultLogger.Fatalf(format, args...)
+	defaultLogger.logf(zapcore.FatalLevel, format, args)
 }
 
 func Panicf(format string, args ...interface{}) {
-	defaultLogger.Panicf(format, args...)
+	defaultLogger.logf(zapcore.PanicLevel, format, args)
 }
 
 func With(fields ...interface{}) *Logger {
@@ -877,6 +1069,10 @@ func Named(name string) *Logger {
 	return defaultLogger.Named(name)
 }
 
+func WithStack() *Logger {
+	return defaultLogger.WithStack()
+}
+
 func Sync() error {
 	return defaultLogger.Sync()
 }
@@ -3,7 +3,13 @@ package logger
 import (
 	"context"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestNew(t *testing.T) {
@@ -553,3 +559,100 @@ func TestDifferentFormats(t *testing.T) {
 		logger.Sync()
 	}
 }
+
+// newObservedLogger 创建输出到内存的日志记录器，用于断言日志条目
+func newObservedLogger() (*Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := NewWithOptions(Options{Level: DebugLevel, Caller: true})
+	l.zap = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(callerSkip))
+	l.sugar = l.zap.Sugar()
+	return l, logs
+}
+
+func TestCallerPointsToUserCode(t *testing.T) {
+	l, logs := newObservedLogger()
+
+	oldLogger := GetDefaultLogger()
+	SetDefaultLogger(l)
+	defer SetDefaultLogger(oldLogger)
+
+	l.Info("method")
+	l.Infof("formatted %s", "method")
+	l.With("k", "v").Warn("derived method")
+	l.WithContext(context.Background()).Errorf("derived formatted %d", 1)
+	Info("global")
+	Infof("global %s", "formatted")
+	Debug("global debug")
+	l.GetSugar().Info("sugar")
+	l.GetZap().Info("zap")
+
+	entries := logs.All()
+	if len(entries) != 9 {
+		t.Fatalf("Expected 9 entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if !entry.Caller.Defined {
+			t.Errorf("Expected caller for %q", entry.Message)
+			continue
+		}
+		if file := filepath.Base(entry.Caller.File); file != "logger_test.go" {
+			t.Errorf("Expected caller in logger_test.go for %q, got %s", entry.Message, entry.Caller.String())
+		}
+	}
+}
+
+func TestStacktraceLevel(t *testing.T) {
+	l, read := newLimitedLogger(t, Options{Stacktrace: true, StacktraceLevel: WarnLevel})
+	l.Info("no stack")
+	l.Warn("warn stack")
+	l.Named("sub").Errorf("error %s", "stack")
+
+	entries := read()
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	if _, ok := entries[0]["stacktrace"]; ok {
+		t.Errorf("Expected no stacktrace below StacktraceLevel, got %v", entries[0])
+	}
+	for _, entry := range entries[1:] {
+		stack, _ := entry["stacktrace"].(string)
+		if !strings.HasPrefix(stack, "github.com/tsopia/go-kit/logger.TestStacktraceLevel\n") {
+			t.Errorf("Expected stacktrace to start at the log call site for %q, got:\n%s", entry["msg"], stack)
+		}
+	}
+}
+
+func TestStacktraceDefaultLevel(t *testing.T) {
+	l, read := newLimitedLogger(t, Options{Stacktrace: true})
+	l.Warn("warn")
+	l.Error("error")
+
+	entries := read()
+	if _, ok := entries[0]["stacktrace"]; ok {
+		t.Error("Expected no stacktrace for Warn with default StacktraceLevel")
+	}
+	if _, ok := entries[1]["stacktrace"]; !ok {
+		t.Error("Expected stacktrace for Error with default StacktraceLevel")
+	}
+}
+
+func TestWithStack(t *testing.T) {
+	l, read := newLimitedLogger(t, Options{})
+	l.Info("plain")
+	l.WithStack().Info("with stack")
+	l.Error("error without automatic stack")
+
+	entries := read()
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	stack, _ := entries[1]["stacktrace"].(string)
+	if !strings.HasPrefix(stack, "github.com/tsopia/go-kit/logger.TestWithStack\n") {
+		t.Errorf("Expected WithStack to attach call-site stacktrace, got:\n%s", stack)
+	}
+	for _, i := range []int{0, 2} {
+		if _, ok := entries[i]["stacktrace"]; ok {
+			t.Errorf("Expected no stacktrace for %q when Stacktrace is disabled", entries[i]["msg"])
+		}
+	}
+}
@@ -0,0 +1,235 @@
+// Package loggertest 记录日志输出，便于在测试中断言某条日志是否输出
+//
+// 日志经过完整的编码流程（JSON格式），字段值与线上输出一致。
+package loggertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tsopia/go-kit/logger"
+)
+
+// channelBuffer C 的缓冲大小，缓冲区满时新条目不再发送到 C，但仍会记录
+const channelBuffer = 256
+
+// JSON 输出中的固定键，其余键均为字段
+var entryKeys = map[string]bool{
+	"timestamp":  true,
+	"level":      true,
+	"logger":     true,
+	"caller":     true,
+	"msg":        true,
+	"stacktrace": true,
+}
+
+// Entry 一条已记录的日志
+type Entry struct {
+	Level   logger.Level
+	Message string
+	Logger  string                 // Named 设置的名称
+	Caller  string                 // 调用位置，形如 pkg/file.go:42
+	Time    time.Time              // 日志时间
+	Fields  map[string]interface{} // 字段，值为 JSON 解码后的结果（数字为 float64）
+	Raw     string                 // 原始 JSON 行
+}
+
+// Recorder 记录日志记录器输出的所有条目（线程安全）
+type Recorder struct {
+	// C 依次接收记录的条目，用于等待异步代码输出的日志
+	C <-chan Entry
+
+	mu      sync.Mutex
+	entries []Entry
+	ch      chan Entry
+}
+
+// NewTestLogger 创建输出到 Recorder 的 Debug 级别 JSON 日志记录器
+//
+// 示例:
+//
+//	log, rec := loggertest.NewTestLogger()
+//	svc := NewUserService(log)
+//	svc.Create(ctx, user)
+//	rec.AssertContains(t, logger.InfoLevel, "user created", "user_id", 123)
+func NewTestLogger() (*logger.Logger, *Recorder) {
+	return NewTestLoggerWithOptions(logger.Options{Level: logger.DebugLevel, Caller: true})
+}
+
+// NewTestLoggerWithOptions 使用指定选项创建输出到 Recorder 的日志记录器
+// Format 和 Output 会被覆盖，文件输出被关闭
+func NewTestLoggerWithOptions(opts logger.Options) (*logger.Logger, *Recorder) {
+	rec := NewRecorder()
+	opts.Format = logger.FormatJSON
+	opts.Output = rec
+	opts.EnableFileOutput = false
+	return logger.NewWithOptions(opts), rec
+}
+
+// NewRecorder 创建空的 Recorder，可作为 logger.Options.Output 使用
+func NewRecorder() *Recorder {
+	ch := make(chan Entry, channelBuffer)
+	return &Recorder{C: ch, ch: ch}
+}
+
+// Write 解析 JSON 日志行并记录，实现 io.Writer
+func (r *Recorder) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(bytes.TrimSpace(p), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		entry, err := parseEntry(line)
+		if err != nil {
+			return 0, err
+		}
+		r.record(entry)
+	}
+	return len(p), nil
+}
+
+func (r *Recorder) record(entry Entry) {
+	r.mu.Lock()
+	r.entries = append(r.entries, entry)
+	r.mu.Unlock()
+
+	select {
+	case r.ch <- entry:
+	default:
+	}
+}
+
+// parseEntry 将 JSON 日志行解析为 Entry
+func parseEntry(line []byte) (Entry, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return Entry{}, fmt.Errorf("loggertest: 无法解析日志行 %q: %w", line, err)
+	}
+
+	entry := Entry{Fields: make(map[string]interface{}), Raw: string(line)}
+	for key, value := range raw {
+		if !entryKeys[key] {
+			entry.Fields[key] = value
+		}
+	}
+	entry.Level = logger.ParseLevel(stringValue(raw["level"]))
+	entry.Message = stringValue(raw["msg"])
+	entry.Logger = stringValue(raw["logger"])
+	entry.Caller = stringValue(raw["caller"])
+	entry.Time, _ = time.Parse("2006-01-02T15:04:05.000Z0700", stringValue(raw["timestamp"]))
+	return entry, nil
+}
+
+func stringValue(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+// Entries 返回已记录条目的副本
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Entry(nil), r.entries...)
+}
+
+// Len 返回已记录的条目数
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+// Reset 清空已记录的条目
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = nil
+}
+
+// Filter 返回级别和消息都匹配且包含所有键值对的条目
+//
+// keyvals 为交替的键和值，值按 JSON 编码后比较，因此 123、int64(123) 与输出中的 123 相等；
+// time.Duration 与输出中的 "1.5s" 和浮点秒数 1.5 都相等（见 logger.Options.DurationEncoding）；
+// 最后一个键没有对应的值时只检查该字段是否存在。
+func (r *Recorder) Filter(level logger.Level, msg string, keyvals ...interface{}) []Entry {
+	var matched []Entry
+	for _, entry := range r.Entries() {
+		if entry.Level == level && entry.Message == msg && entry.Has(keyvals...) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+// Contains 判断是否记录了匹配的条目，参数同 Filter
+func (r *Recorder) Contains(level logger.Level, msg string, keyvals ...interface{}) bool {
+	return len(r.Filter(level, msg, keyvals...)) > 0
+}
+
+// AssertContains 没有匹配的条目时使测试失败，并列出已记录的日志
+func (r *Recorder) AssertContains(t testing.TB, level logger.Level, msg string, keyvals ...interface{}) {
+	t.Helper()
+	if !r.Contains(level, msg, keyvals...) {
+		t.Errorf("loggertest: 未找到日志 [%s] %q %v，已记录:\n%s", level, msg, keyvals, r.dump())
+	}
+}
+
+// AssertNotContains 存在匹配的条目时使测试失败
+func (r *Recorder) AssertNotContains(t testing.TB, level logger.Level, msg string, keyvals ...interface{}) {
+	t.Helper()
+	if matched := r.Filter(level, msg, keyvals...); len(matched) > 0 {
+		t.Errorf("loggertest: 不应输出日志 [%s] %q %v，实际: %s", level, msg, keyvals, matched[0].Raw)
+	}
+}
+
+// dump 返回所有已记录的原始日志行
+func (r *Recorder) dump() string {
+	entries := r.Entries()
+	if len(entries) == 0 {
+		return "  (无)"
+	}
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		lines[i] = "  " + entry.Raw
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Has 判断条目是否包含所有键值对，参数同 Recorder.Filter
+func (e Entry) Has(keyvals ...interface{}) bool {
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		actual, ok := e.Fields[key]
+		if !ok {
+			return false
+		}
+		if i+1 < len(keyvals) && !jsonEqual(keyvals[i+1], actual) {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonEqual 将期望值经过 JSON 编解码后与实际值比较，结构体与解码得到的 map 可以相等
+func jsonEqual(expected, actual interface{}) bool {
+	if d, ok := expected.(time.Duration); ok {
+		return actual == d.String() || actual == d.Seconds()
+	}
+	if err, ok := expected.(error); ok {
+		expected = err.Error()
+	}
+	data, err := json.Marshal(expected)
+	if err != nil {
+		return false
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(normalized, actual)
+}
@@ -0,0 +1,106 @@
+package loggertest
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tsopia/go-kit/logger"
+)
+
+func TestRecorderCapturesEntries(t *testing.T) {
+	log, rec := NewTestLogger()
+
+	log.Named("users").With("tenant", "acme").Info("user created", "user_id", 123, "tags", []string{"a", "b"})
+	log.Debug("debug entry")
+	log.WithError(errors.New("boom")).Error("save failed")
+
+	if rec.Len() != 3 {
+		t.Fatalf("Expected 3 entries, got %d", rec.Len())
+	}
+
+	entry := rec.Entries()[0]
+	if entry.Level != logger.InfoLevel || entry.Message != "user created" || entry.Logger != "users" {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+	if entry.Time.IsZero() || entry.Caller == "" {
+		t.Errorf("Expected time and caller, got %+v", entry)
+	}
+
+	rec.AssertContains(t, logger.InfoLevel, "user created", "user_id", 123, "tenant", "acme")
+	rec.AssertContains(t, logger.InfoLevel, "user created", "tags", []string{"a", "b"})
+	rec.AssertContains(t, logger.InfoLevel, "user created", "user_id")
+	rec.AssertContains(t, logger.DebugLevel, "debug entry")
+	rec.AssertContains(t, logger.ErrorLevel, "save failed", "error", errors.New("boom"))
+	rec.AssertNotContains(t, logger.WarnLevel, "user created")
+}
+
+func TestRecorderNoMatch(t *testing.T) {
+	log, rec := NewTestLogger()
+	log.Info("user created", "user_id", 123)
+
+	tests := []struct {
+		name    string
+		level   logger.Level
+		msg     string
+		keyvals []interface{}
+	}{
+		{"wrong level", logger.WarnLevel, "user created", nil},
+		{"wrong message", logger.InfoLevel, "user deleted", nil},
+		{"wrong value", logger.InfoLevel, "user created", []interface{}{"user_id", 124}},
+		{"missing key", logger.InfoLevel, "user created", []interface{}{"email"}},
+	}
+	for _, tt := range tests {
+		if rec.Contains(tt.level, tt.msg, tt.keyvals...) {
+			t.Errorf("%s: expected no match", tt.name)
+		}
+	}
+
+	rec.Reset()
+	if rec.Len() != 0 {
+		t.Errorf("Expected Reset to clear entries, got %d", rec.Len())
+	}
+}
+
+func TestRecorderChannel(t *testing.T) {
+	log, rec := NewTestLogger()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		log.Warn("async done", "job", "sync")
+	}()
+
+	select {
+	case entry := <-rec.C:
+		if entry.Message != "async done" || !entry.Has("job", "sync") {
+			t.Errorf("Unexpected entry: %+v", entry)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected entry on channel")
+	}
+	wg.Wait()
+}
+
+func TestNewTestLoggerWithOptions(t *testing.T) {
+	log, rec := NewTestLoggerWithOptions(logger.Options{Level: logger.WarnLevel})
+	log.Info("filtered")
+	log.Warn("kept")
+
+	if rec.Len() != 1 || !rec.Contains(logger.WarnLevel, "kept") {
+		t.Errorf("Expected only warn entry, got %v", rec.Entries())
+	}
+}
+
+func TestRecorderDurationValues(t *testing.T) {
+	log, rec := NewTestLogger()
+	log.Info("string", "elapsed", 1500*time.Millisecond)
+	rec.AssertContains(t, logger.InfoLevel, "string", "elapsed", 1500*time.Millisecond)
+
+	log, rec = NewTestLoggerWithOptions(logger.Options{DurationEncoding: logger.DurationSeconds})
+	log.Info("seconds", "elapsed", 1500*time.Millisecond)
+	rec.AssertContains(t, logger.InfoLevel, "seconds", "elapsed", 1500*time.Millisecond)
+	rec.AssertNotContains(t, logger.InfoLevel, "seconds", "elapsed", time.Second)
+}
@@ -0,0 +1,279 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// mdcSweepInterval 清理已退出协程遗留字段的最小间隔
+const mdcSweepInterval = time.Minute
+
+// mdcFrame 一次 PushFields 压入的字段
+type mdcFrame struct {
+	scope  *MDCScope
+	fields []interface{}
+}
+
+// mdcRegistry 按协程ID保存的字段栈
+//
+// active 为登记的协程数，为0时日志写入不需要获取协程ID，未使用MDC时没有额外开销。
+var mdcRegistry = struct {
+	mu        sync.Mutex
+	stacks    map[uint64][]mdcFrame
+	active    atomic.Int64
+	nextSweep time.Time
+}{stacks: make(map[uint64][]mdcFrame)}
+
+// liveGoroutineIDs 返回当前存活的协程ID（测试时替换）
+var liveGoroutineIDs = allGoroutineIDs
+
+// MDCScope PushFields 返回的字段作用域
+type MDCScope struct {
+	gid    uint64
+	popped atomic.Bool
+}
+
+// PushFields 为当前协程压入日志字段（MDC），直到返回的作用域 Pop 为止
+//
+// 作用域内当前协程通过任意日志记录器（包括全局函数、Get 返回的子记录器）输出的日志都会带上这些字段，
+// 适合无法传递 context 的批处理循环和命令行工具。嵌套压入时内层的同名字段覆盖外层，Pop 后恢复；
+// 日志调用时显式传入的同名字段优先。
+//
+// 字段只对当前协程生效，不会传递给新启动的协程，需要时通过 Carry 手动传递。
+// 协程退出时没有 Pop 的字段会在之后的 PushFields 中被定期清理，不会造成内存泄漏。
+//
+// 示例:
+//
+//	for _, item := range items {
+//	    scope := logger.PushFields("item_id", item.ID)
+//	    process(item) // 内部的 logger.Info 自动带上 item_id
+//	    scope.Pop()
+//	}
+func PushFields(keysAndValues ...interface{}) *MDCScope {
+	return pushFields(goroutineID(), keysAndValues)
+}
+
+func pushFields(gid uint64, keysAndValues []interface{}) *MDCScope {
+	if len(keysAndValues)%2 != 0 {
+		keysAndValues = keysAndValues[:len(keysAndValues)-1]
+	}
+	fields := make([]interface{}, len(keysAndValues))
+	copy(fields, keysAndValues)
+	scope := &MDCScope{gid: gid}
+
+	mdcRegistry.mu.Lock()
+	defer mdcRegistry.mu.Unlock()
+
+	if now := time.Now(); !now.Before(mdcRegistry.nextSweep) {
+		mdcRegistry.nextSweep = now.Add(mdcSweepInterval)
+		sweepMDCLocked()
+	}
+	stack, exists := mdcRegistry.stacks[gid]
+	if !exists {
+		mdcRegistry.active.Add(1)
+	}
+	mdcRegistry.stacks[gid] = append(stack, mdcFrame{scope: scope, fields: fields})
+	return scope
+}
+
+// Pop 移除该作用域压入的字段，重复调用无效果
+// 作用域没有按压入的相反顺序 Pop 时，其后压入的字段一并移除
+func (s *MDCScope) Pop() {
+	if s == nil || s.popped.Swap(true) {
+		return
+	}
+
+	mdcRegistry.mu.Lock()
+	defer mdcRegistry.mu.Unlock()
+
+	stack := mdcRegistry.stacks[s.gid]
+	for i, frame := range stack {
+		if frame.scope != s {
+			continue
+		}
+		for _, removed := range stack[i+1:] {
+			removed.scope.popped.Store(true)
+		}
+		if i == 0 {
+			delete(mdcRegistry.stacks, s.gid)
+			mdcRegistry.active.Add(-1)
+		} else {
+			mdcRegistry.stacks[s.gid] = stack[:i:i]
+		}
+		return
+	}
+}
+
+// Carry 返回作用域所在协程当前全部字段的快照，用于传递给新启动的协程
+//
+//	carried := scope.Carry()
+//	go func() {
+//	    defer carried.Push().Pop()
+//	    logger.Info("子任务") // 带上父协程的字段
+//	}()
+func (s *MDCScope) Carry() MDCSnapshot {
+	if s == nil {
+		return MDCSnapshot{}
+	}
+	mdcRegistry.mu.Lock()
+	defer mdcRegistry.mu.Unlock()
+	return MDCSnapshot{fields: mergeMDCFrames(mdcRegistry.stacks[s.gid], nil)}
+}
+
+// MDCSnapshot Carry 返回的字段快照
+type MDCSnapshot struct {
+	fields []interface{}
+}
+
+// Push 在当前协程压入快照中的字段
+func (s MDCSnapshot) Push() *MDCScope {
+	return PushFields(s.fields...)
+}
+
+// Fields 返回快照中的键值对
+func (s MDCSnapshot) Fields() []interface{} {
+	out := make([]interface{}, len(s.fields))
+	copy(out, s.fields)
+	return out
+}
+
+// mdcFields 返回当前协程的字段，exclude 中的键跳过
+func mdcFields(exclude map[string]struct{}) []interface{} {
+	if mdcRegistry.active.Load() == 0 {
+		return nil
+	}
+	gid := goroutineID()
+
+	mdcRegistry.mu.Lock()
+	defer mdcRegistry.mu.Unlock()
+	return mergeMDCFrames(mdcRegistry.stacks[gid], exclude)
+}
+
+// mergeMDCFrames 合并字段栈，内层覆盖外层的同名字段，保持首次出现的顺序
+func mergeMDCFrames(stack []mdcFrame, exclude map[string]struct{}) []interface{} {
+	if len(stack) == 0 {
+		return nil
+	}
+	var keys []string
+	values := make(map[string]interface{})
+	for _, frame := range stack {
+		for i := 0; i+1 < len(frame.fields); i += 2 {
+			key := mdcKey(frame.fields[i])
+			if _, skip := exclude[key]; skip {
+				continue
+			}
+			if _, seen := values[key]; !seen {
+				keys = append(keys, key)
+			}
+			values[key] = frame.fields[i+1]
+		}
+	}
+	out := make([]interface{}, 0, len(keys)*2)
+	for _, key := range keys {
+		out = append(out, key, values[key])
+	}
+	return out
+}
+
+func mdcKey(key interface{}) string {
+	if s, ok := key.(string); ok {
+		return s
+	}
+	return fmt.Sprint(key)
+}
+
+// sweepMDCLocked 移除已退出协程遗留的字段，调用方持有锁
+func sweepMDCLocked() {
+	if len(mdcRegistry.stacks) == 0 {
+		return
+	}
+	live := liveGoroutineIDs()
+	for gid, stack := range mdcRegistry.stacks {
+		if _, ok := live[gid]; ok {
+			continue
+		}
+		for _, frame := range stack {
+			frame.scope.popped.Store(true)
+		}
+		delete(mdcRegistry.stacks, gid)
+		mdcRegistry.active.Add(-1)
+	}
+}
+
+// allGoroutineIDs 解析 runtime.Stack 输出的全部协程ID，会短暂暂停所有协程，因此只定期调用
+func allGoroutineIDs() map[uint64]struct{} {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+
+	ids := make(map[uint64]struct{})
+	prefix := []byte("goroutine ")
+	for _, line := range bytes.Split(buf, []byte("\n")) {
+		if !bytes.HasPrefix(line, prefix) {
+			continue
+		}
+		line = line[len(prefix):]
+		if end := bytes.IndexByte(line, ' '); end > 0 {
+			if id, err := strconv.ParseUint(string(line[:end]), 10, 64); err == nil {
+				ids[id] = struct{}{}
+			}
+		}
+	}
+	return ids
+}
+
+// mdcCore 为每条实际输出的日志添加当前协程 PushFields 压入的字段
+type mdcCore struct {
+	zapcore.Core
+	encoding valueEncoding // 压入的字段在输出时才转换为 zap 字段，按记录器的配置规范化
+}
+
+func newMDCCore(core zapcore.Core, encoding valueEncoding) zapcore.Core {
+	return &mdcCore{Core: core, encoding: encoding}
+}
+
+func (c *mdcCore) With(fields []zapcore.Field) zapcore.Core {
+	return &mdcCore{Core: c.Core.With(fields), encoding: c.encoding}
+}
+
+func (c *mdcCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *mdcCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if mdcRegistry.active.Load() == 0 {
+		return c.Core.Write(ent, fields)
+	}
+
+	exclude := make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		exclude[f.Key] = struct{}{}
+	}
+	extra := mdcFields(exclude)
+	if len(extra) == 0 {
+		return c.Core.Write(ent, fields)
+	}
+
+	merged := make([]zapcore.Field, 0, len(fields)+len(extra)/2)
+	merged = append(merged, fields...)
+	for i := 0; i+1 < len(extra); i += 2 {
+		merged = append(merged, c.encoding.field(extra[i].(string), extra[i+1]))
+	}
+	return c.Core.Write(ent, merged)
+}
@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+)
+
+// useDefaultLogger 临时替换全局日志记录器
+func useDefaultLogger(t *testing.T, l *Logger) {
+	t.Helper()
+	previous := defaultLogger
+	SetDefaultLogger(l)
+	t.Cleanup(func() { SetDefaultLogger(previous) })
+}
+
+func TestPushFieldsNesting(t *testing.T) {
+	l, read := newLimitedLogger(t, Options{})
+	useDefaultLogger(t, l)
+
+	outer := PushFields("job", "import", "item_id", 1)
+	Info("outer")
+	inner := PushFields("item_id", 2, "step", "parse")
+	Info("inner")
+	Get("worker").Info("named")
+	Info("explicit", "item_id", 3)
+	inner.Pop()
+	Info("restored")
+	outer.Pop()
+	Info("cleared")
+
+	entries := read()
+	if len(entries) != 6 {
+		t.Fatalf("Expected 6 entries, got %d", len(entries))
+	}
+	expect := []struct {
+		itemID interface{}
+		step   interface{}
+	}{
+		{float64(1), nil},
+		{float64(2), "parse"},
+		{float64(2), "parse"},
+		{float64(3), "parse"},
+		{float64(1), nil},
+		{nil, nil},
+	}
+	for i, want := range expect {
+		entry := entries[i]
+		if entry["item_id"] != want.itemID || entry["step"] != want.step {
+			t.Errorf("%s: expected item_id=%v step=%v, got %v", entry["msg"], want.itemID, want.step, entry)
+		}
+		if want.itemID != nil && entry["job"] != "import" {
+			t.Errorf("%s: expected outer field job, got %v", entry["msg"], entry)
+		}
+	}
+	if _, ok := mdcRegistry.stacks[goroutineID()]; ok {
+		t.Error("Expected registry entry removed after all scopes popped")
+	}
+}
+
+func TestPushFieldsOutOfOrderPop(t *testing.T) {
+	outer := PushFields("a", 1)
+	inner := PushFields("b", 2)
+	outer.Pop()
+	inner.Pop() // 已随 outer 移除，不应影响其他字段
+
+	if fields := mdcFields(nil); fields != nil {
+		t.Errorf("Expected no fields after popping outer scope, got %v", fields)
+	}
+}
+
+func TestPushFieldsGoroutineIsolation(t *testing.T) {
+	l, read := newLimitedLogger(t, Options{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			scope := PushFields("worker", id)
+			defer scope.Pop()
+			for j := 0; j < 20; j++ {
+				l.Info("work", "expected", id)
+			}
+		}(i)
+	}
+	l.Info("main")
+	wg.Wait()
+
+	for _, entry := range read() {
+		if entry["msg"] == "main" {
+			if _, ok := entry["worker"]; ok {
+				t.Errorf("Expected main goroutine log without worker field, got %v", entry)
+			}
+			continue
+		}
+		if entry["worker"] != entry["expected"] {
+			t.Errorf("Expected worker field %v, got %v", entry["expected"], entry["worker"])
+		}
+	}
+}
+
+func TestMDCScopeCarry(t *testing.T) {
+	l, read := newLimitedLogger(t, Options{})
+
+	scope := PushFields("request_id", "r-1")
+	defer scope.Pop()
+	carried := scope.Carry()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Info("without carry")
+		defer carried.Push().Pop()
+		l.Info("with carry")
+	}()
+	<-done
+
+	entries := read()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if _, ok := entries[0]["request_id"]; ok {
+		t.Errorf("Fields should not propagate to new goroutines, got %v", entries[0])
+	}
+	if entries[1]["request_id"] != "r-1" {
+		t.Errorf("Expected carried request_id, got %v", entries[1])
+	}
+}
+
+func TestMDCSweepExitedGoroutines(t *testing.T) {
+	var gid uint64
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		gid = goroutineID()
+		PushFields("leaked", true) // 退出前没有 Pop
+	}()
+	<-done
+
+	mdcRegistry.mu.Lock()
+	_, leaked := mdcRegistry.stacks[gid]
+	mdcRegistry.mu.Unlock()
+	if !leaked {
+		t.Fatal("Expected fields registered for exited goroutine before sweep")
+	}
+
+	// 协程可能尚未完全退出，替换存活列表使结果确定
+	original := liveGoroutineIDs
+	liveGoroutineIDs = func() map[uint64]struct{} {
+		ids := original()
+		delete(ids, gid)
+		return ids
+	}
+	defer func() { liveGoroutineIDs = original }()
+
+	mine := PushFields("alive", true)
+	defer mine.Pop()
+
+	mdcRegistry.mu.Lock()
+	sweepMDCLocked()
+	_, leaked = mdcRegistry.stacks[gid]
+	_, alive := mdcRegistry.stacks[goroutineID()]
+	mdcRegistry.mu.Unlock()
+
+	if leaked {
+		t.Error("Expected sweep to remove fields of exited goroutine")
+	}
+	if !alive {
+		t.Error("Expected sweep to keep fields of live goroutine")
+	}
+}
+
+func TestAllGoroutineIDs(t *testing.T) {
+	ids := allGoroutineIDs()
+	if _, ok := ids[goroutineID()]; !ok {
+		t.Errorf("Expected current goroutine %d in %d live ids", goroutineID(), len(ids))
+	}
+}
@@ -0,0 +1,193 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	kiterrors "github.com/tsopia/go-kit/errors"
+)
+
+const (
+	// panicMessage LogPanic 输出的日志消息
+	panicMessage = "panic recovered"
+	// repeatedPanicMessage NewPanicReporter 对窗口内重复的 panic 输出的日志消息
+	repeatedPanicMessage = "panic repeated"
+	// maxPanicFrames panic.frames 字段保留的最大帧数
+	maxPanicFrames = 32
+	// fingerprintFrames 计算指纹使用的栈顶帧数
+	fingerprintFrames = 5
+)
+
+// LogPanic 以 Error 级别记录恢复的 panic，堆栈解析为结构化帧
+//
+// 输出字段:
+//   - panic.value: panic 的值
+//   - panic.type: panic 值的类型
+//   - panic.fingerprint: PanicFingerprint 计算的指纹，用于错误追踪系统聚合重复的 panic
+//   - panic.frames: 从 panic 发生处开始的堆栈帧，最多 32 帧
+//
+// l 为 nil 时使用全局日志器，stack 通常来自 debug.Stack()。
+//
+// 示例:
+//
+//	defer func() {
+//	    if r := recover(); r != nil {
+//	        logger.LogPanic(log, r, debug.Stack(), "worker", name)
+//	    }
+//	}()
+func LogPanic(l *Logger, recovered interface{}, stack []byte, fields ...interface{}) {
+	if l == nil {
+		l = defaultLogger
+	}
+
+	frames := panicFrames(stack)
+	if len(frames) > maxPanicFrames {
+		frames = frames[:maxPanicFrames]
+	}
+
+	panicFields := make([]interface{}, 0, len(fields)+8)
+	panicFields = append(panicFields,
+		"panic.value", fmt.Sprint(recovered),
+		"panic.type", fmt.Sprintf("%T", recovered),
+		"panic.fingerprint", PanicFingerprint(recovered, stack),
+		"panic.frames", frames,
+	)
+	panicFields = append(panicFields, fields...)
+	l.Error(panicMessage, panicFields...)
+}
+
+// PanicFingerprint 计算 panic 的稳定指纹
+//
+// 指纹由 panic 值的类型和栈顶 5 个非 runtime 帧的函数名计算，不包含行号和 panic 值，
+// 因此同一位置、同一类型的 panic 在不同请求和小幅改动后的版本中得到相同指纹，
+// 可用于聚合或对重复的 panic 限流（参见 PanicDeduper）。
+func PanicFingerprint(recovered interface{}, stack []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%T", recovered)
+
+	n := 0
+	for _, frame := range panicFrames(stack) {
+		if isRuntimeFrame(frame.Function) {
+			continue
+		}
+		h.Write([]byte{'\n'})
+		h.Write([]byte(frame.Function))
+		if n++; n == fingerprintFrames {
+			break
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// panicFrames 解析堆栈并去掉 panic 之前的恢复逻辑帧（debug.Stack、defer 函数、runtime.gopanic）
+func panicFrames(stack []byte) []kiterrors.Frame {
+	frames := kiterrors.ParseStack(string(stack))
+	for i := len(frames) - 1; i >= 0; i-- {
+		if frames[i].Function == "panic" || frames[i].Function == "runtime.gopanic" {
+			return frames[i+1:]
+		}
+	}
+	return frames
+}
+
+// isRuntimeFrame 判断是否为 Go 运行时内部的帧
+func isRuntimeFrame(function string) bool {
+	return strings.HasPrefix(function, "runtime.") || strings.HasPrefix(function, "runtime/")
+}
+
+// PanicDeduper 在时间窗口内对相同指纹的 panic 去重
+//
+// 窗口内第一次出现的 panic 应记录完整信息，之后只累加计数，
+// 适用于 recovery 中间件和后台任务中反复出现的同一个 panic。
+//
+// 示例:
+//
+//	deduper := logger.NewPanicDeduper(time.Minute)
+//
+//	fingerprint := logger.PanicFingerprint(r, stack)
+//	if count, first := deduper.Observe(fingerprint); first {
+//	    logger.LogPanic(log, r, stack)
+//	} else {
+//	    log.Warn("重复的panic", "panic.fingerprint", fingerprint, "count", count)
+//	}
+type PanicDeduper struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*panicWindow
+}
+
+type panicWindow struct {
+	start time.Time
+	count int
+}
+
+// NewPanicDeduper 创建去重器，window 为去重窗口
+func NewPanicDeduper(window time.Duration) *PanicDeduper {
+	return &PanicDeduper{
+		window:  window,
+		now:     time.Now,
+		entries: make(map[string]*panicWindow),
+	}
+}
+
+// Observe 记录一次 panic，返回当前窗口内的出现次数，以及是否为窗口内第一次出现
+func (d *PanicDeduper) Observe(fingerprint string) (count int, first bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	entry, ok := d.entries[fingerprint]
+	if !ok || now.Sub(entry.start) >= d.window {
+		d.evictExpired(now)
+		d.entries[fingerprint] = &panicWindow{start: now, count: 1}
+		return 1, true
+	}
+	entry.count++
+	return entry.count, false
+}
+
+// evictExpired 清理过期的窗口，避免指纹无限增长（调用方需持有锁）
+func (d *PanicDeduper) evictExpired(now time.Time) {
+	for fingerprint, entry := range d.entries {
+		if now.Sub(entry.start) >= d.window {
+			delete(d.entries, fingerprint)
+		}
+	}
+}
+
+// NewPanicReporter 返回按指纹去重的 panic 上报函数
+//
+// window 内第一次出现的 panic 通过 LogPanic 记录完整信息，之后相同指纹的 panic
+// 只以 Warn 级别记录 panic.fingerprint 和 panic.count（窗口内的出现次数）。
+// 返回的函数可以直接作为 httpserver.Config.PanicReporter 使用。
+//
+// 示例:
+//
+//	config := httpserver.DefaultConfig()
+//	config.PanicReporter = logger.NewPanicReporter(log, time.Minute)
+func NewPanicReporter(l *Logger, window time.Duration) func(recovered interface{}, stack []byte, fields ...interface{}) {
+	deduper := NewPanicDeduper(window)
+	return func(recovered interface{}, stack []byte, fields ...interface{}) {
+		fingerprint := PanicFingerprint(recovered, stack)
+		count, first := deduper.Observe(fingerprint)
+		if first {
+			LogPanic(l, recovered, stack, fields...)
+			return
+		}
+
+		log := l
+		if log == nil {
+			log = defaultLogger
+		}
+		repeatFields := make([]interface{}, 0, len(fields)+4)
+		repeatFields = append(repeatFields, "panic.fingerprint", fingerprint, "panic.count", count)
+		repeatFields = append(repeatFields, fields...)
+		log.Warn(repeatedPanicMessage, repeatFields...)
+	}
+}
@@ -0,0 +1,186 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+)
+
+type panicResult struct {
+	value interface{}
+	stack []byte
+}
+
+// capturePanic 执行 fn 并捕获 panic 与堆栈，模拟 recovery 中间件
+func capturePanic(fn func()) (result panicResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = panicResult{value: r, stack: debug.Stack()}
+		}
+	}()
+	fn()
+	return
+}
+
+func panicWithString(msg string) { panic(msg) }
+
+func panicWithError() { panic(errors.New("boom")) }
+
+func panicIndex(i int) int {
+	var s []int
+	return s[i]
+}
+
+func TestPanicFingerprint(t *testing.T) {
+	// 相同的调用路径（模拟同一个处理函数处理不同请求）
+	panicString := func(msg string) panicResult { return capturePanic(func() { panicWithString(msg) }) }
+	panicErr := func() panicResult { return capturePanic(func() { panicWithError() }) }
+	panicIdx := func(i int) panicResult { return capturePanic(func() { panicIndex(i) }) }
+
+	a := panicString("user 1 not found")
+	b := panicString("user 2 not found")
+	c := panicErr()
+	d := panicIdx(3)
+	e := panicIdx(5)
+
+	fa := PanicFingerprint(a.value, a.stack)
+	if fb := PanicFingerprint(b.value, b.stack); fa != fb {
+		t.Errorf("Expected identical panics to share fingerprint, got %s and %s", fa, fb)
+	}
+	if fc := PanicFingerprint(c.value, c.stack); fa == fc {
+		t.Errorf("Expected different panics to have different fingerprints, both %s", fa)
+	}
+	fd, fe := PanicFingerprint(d.value, d.stack), PanicFingerprint(e.value, e.stack)
+	if fd != fe {
+		t.Errorf("Expected runtime errors at the same site to share fingerprint, got %s and %s", fd, fe)
+	}
+	if fd == fa || len(fa) != 16 {
+		t.Errorf("Unexpected fingerprints %s %s", fa, fd)
+	}
+
+	// 同一函数中类型不同的 panic 指纹不同
+	if PanicFingerprint("x", a.stack) == PanicFingerprint(42, a.stack) {
+		t.Error("Expected panic type to be part of the fingerprint")
+	}
+}
+
+func TestPanicFrames(t *testing.T) {
+	r := capturePanic(func() { panicWithString("boom") })
+	frames := panicFrames(r.stack)
+	if len(frames) == 0 {
+		t.Fatal("Expected frames")
+	}
+	if !strings.HasSuffix(frames[0].Function, ".panicWithString") {
+		t.Errorf("Expected first frame to be the panic site, got %+v", frames[0])
+	}
+	if !strings.HasSuffix(frames[0].File, "panic_test.go") || frames[0].Line == 0 {
+		t.Errorf("Expected file and line for panic site, got %+v", frames[0])
+	}
+}
+
+func TestLogPanic(t *testing.T) {
+	l, logPath := newTempFileLogger(t, 0)
+
+	r := capturePanic(func() { panicWithError() })
+	LogPanic(l, r.value, r.stack, "path", "/users")
+	l.Close()
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(content))), &entry); err != nil {
+		t.Fatalf("Failed to parse log: %v: %s", err, content)
+	}
+
+	if entry["level"] != "error" || entry["msg"] != panicMessage {
+		t.Errorf("Unexpected level/message: %v", entry)
+	}
+	if entry["panic.value"] != "boom" || entry["panic.type"] != "*errors.errorString" {
+		t.Errorf("Unexpected panic value/type: %v %v", entry["panic.value"], entry["panic.type"])
+	}
+	if entry["panic.fingerprint"] != PanicFingerprint(r.value, r.stack) {
+		t.Errorf("Unexpected fingerprint %v", entry["panic.fingerprint"])
+	}
+	if entry["path"] != "/users" {
+		t.Errorf("Expected extra fields to be kept, got %v", entry["path"])
+	}
+	frames, ok := entry["panic.frames"].([]interface{})
+	if !ok || len(frames) == 0 || len(frames) > maxPanicFrames {
+		t.Fatalf("Expected structured frames, got %v", entry["panic.frames"])
+	}
+	first := frames[0].(map[string]interface{})
+	if !strings.HasSuffix(first["function"].(string), ".panicWithError") {
+		t.Errorf("Expected first frame to be panic site, got %v", first)
+	}
+}
+
+func TestPanicDeduper(t *testing.T) {
+	now := time.Unix(0, 0)
+	d := NewPanicDeduper(time.Minute)
+	d.now = func() time.Time { return now }
+
+	if count, first := d.Observe("a"); !first || count != 1 {
+		t.Errorf("Expected first observation, got %d %v", count, first)
+	}
+	if count, first := d.Observe("a"); first || count != 2 {
+		t.Errorf("Expected repeated observation, got %d %v", count, first)
+	}
+	if _, first := d.Observe("b"); !first {
+		t.Error("Expected different fingerprint to be first")
+	}
+
+	now = now.Add(time.Minute)
+	if count, first := d.Observe("a"); !first || count != 1 {
+		t.Errorf("Expected new window after expiry, got %d %v", count, first)
+	}
+	if len(d.entries) != 1 {
+		t.Errorf("Expected expired entries to be evicted, got %d", len(d.entries))
+	}
+}
+
+func TestNewPanicReporter(t *testing.T) {
+	l, logPath := newTempFileLogger(t, 0)
+	report := NewPanicReporter(l, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		r := capturePanic(func() { panicWithError() })
+		report(r.value, r.stack, "worker", "consumer")
+	}
+	l.Close()
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 entries, got %d: %s", len(lines), content)
+	}
+	for i, line := range lines {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to parse log: %v: %s", err, line)
+		}
+		if entry["worker"] != "consumer" {
+			t.Errorf("Expected extra fields to be kept, got %v", entry)
+		}
+		if i == 0 {
+			if entry["level"] != "error" || entry["msg"] != panicMessage {
+				t.Errorf("Expected full report first, got %v", entry)
+			}
+			continue
+		}
+		if entry["level"] != "warn" || entry["msg"] != repeatedPanicMessage || entry["panic.count"] != float64(i+1) {
+			t.Errorf("Expected repeated panic %d to be counted, got %v", i+1, entry)
+		}
+		if _, ok := entry["panic.frames"]; ok {
+			t.Errorf("Expected repeated panic without frames, got %v", entry)
+		}
+	}
+}
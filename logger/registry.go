@@ -0,0 +1,39 @@
+package logger
+
+import "sync"
+
+// namedRegistry Get 创建的子系统日志记录器缓存
+var namedRegistry = struct {
+	mu      sync.Mutex
+	parent  *Logger // 缓存对应的全局日志记录器，全局实例被替换后缓存失效
+	loggers map[string]*Logger
+}{}
+
+// Get 返回全局日志记录器的命名子记录器，相同名称返回同一实例（线程安全）
+//
+// 子记录器在首次调用时创建，与全局日志记录器共享级别和输出，
+// 日志中的 logger 字段为名称，各子系统无需层层传递日志记录器。
+// Init、InitWithLogger 或 SetDefaultLogger 替换全局实例后，之后的调用基于新实例重新创建；
+// 已经获取的子记录器仍然写入原来的实例，因此应在初始化全局日志之后再获取。
+//
+// 示例:
+//
+//	logger.Get("db").Info("连接池已就绪", "max_open", 100)
+//	logger.Get("http").Warn("请求超时", "path", path)
+func Get(name string) *Logger {
+	namedRegistry.mu.Lock()
+	defer namedRegistry.mu.Unlock()
+
+	parent := defaultLogger
+	if namedRegistry.parent != parent || namedRegistry.loggers == nil {
+		namedRegistry.parent = parent
+		namedRegistry.loggers = make(map[string]*Logger)
+	}
+
+	if l, ok := namedRegistry.loggers[name]; ok {
+		return l
+	}
+	l := parent.Named(name)
+	namedRegistry.loggers[name] = l
+	return l
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestGetReturnsCachedNamedLogger(t *testing.T) {
+	l, logs := newObservedLogger()
+	oldLogger := GetDefaultLogger()
+	SetDefaultLogger(l)
+	defer SetDefaultLogger(oldLogger)
+
+	db := Get("db")
+	if Get("db") != db {
+		t.Error("相同名称应返回同一实例")
+	}
+	if Get("http") == db {
+		t.Error("不同名称应返回不同实例")
+	}
+
+	db.Info("查询完成")
+	Get("http").Info("请求完成")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("期望 2 条日志, 实际 %d", len(entries))
+	}
+	if entries[0].LoggerName != "db" || entries[1].LoggerName != "http" {
+		t.Errorf("期望名称 db 和 http, 实际 %q 和 %q", entries[0].LoggerName, entries[1].LoggerName)
+	}
+
+	// 与全局日志记录器共享级别
+	SetLevel(WarnLevel)
+	defer SetLevel(DebugLevel)
+	if db.IsEnabled(InfoLevel) {
+		t.Error("子记录器应跟随全局级别")
+	}
+}
+
+func TestGetAfterDefaultReplaced(t *testing.T) {
+	first, _ := newObservedLogger()
+	oldLogger := GetDefaultLogger()
+	SetDefaultLogger(first)
+	defer SetDefaultLogger(oldLogger)
+
+	before := Get("db")
+
+	second, logs := newObservedLogger()
+	SetDefaultLogger(second)
+
+	after := Get("db")
+	if after == before {
+		t.Fatal("替换全局日志记录器后应重新创建子记录器")
+	}
+	after.Info("写入新实例")
+	if logs.Len() != 1 {
+		t.Errorf("期望写入新的全局实例, 实际 %d 条", logs.Len())
+	}
+}
+
+func TestGetConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	results := make([]*Logger, 16)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = Get("concurrent")
+		}(i)
+	}
+	wg.Wait()
+
+	for _, l := range results {
+		if l != results[0] {
+			t.Fatal("并发调用应返回同一实例")
+		}
+	}
+}
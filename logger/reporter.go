@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// reportFlushTimeout Fatal/Panic 日志和 Sync 等待错误上报完成的最长时间
+const reportFlushTimeout = 2 * time.Second
+
+// ErrorEvent 交给 ErrorReporter 的一条日志
+type ErrorEvent struct {
+	Level      Level
+	Time       time.Time
+	Message    string
+	LoggerName string
+	Caller     string                 // 文件:行号，未开启 Caller 时为空
+	Fields     map[string]interface{} // 全部字段，包括 With、WithContext 提取的和 PushFields 压入的
+	Err        error                  // 第一个错误字段（例如 "error", err 或 WithError）
+	Stack      string                 // Err 携带的堆栈，没有时为日志自动附加的堆栈
+}
+
+// ErrorReporter 把错误日志转发到 Sentry 等错误追踪服务
+//
+// Report 在记录日志的协程中同步调用，实现应尽快返回（例如放入队列后异步发送）；
+// Flush 在 Fatal/Panic 日志和 Sync 时调用，等待已接收的事件发送完成，返回是否在超时前完成。
+// Sentry 的实现见 logger/sentryhook 子包。
+type ErrorReporter interface {
+	Report(event ErrorEvent)
+	Flush(timeout time.Duration) bool
+}
+
+// errorReportLevel 返回上报的最低级别，零值（InfoLevel）表示默认的 ErrorLevel
+func (o Options) errorReportLevel() zapcore.Level {
+	if o.ErrorReportLevel == InfoLevel {
+		return zapcore.ErrorLevel
+	}
+	return convertLevel(o.ErrorReportLevel)
+}
+
+// reporterCore 把达到上报级别的日志交给 ErrorReporter，与输出级别相互独立
+// 不使用 zapcore.NewTee：Tee 的 Write 不检查级别，会让输出核心写出低于其级别的日志
+type reporterCore struct {
+	zapcore.Core
+	reporter ErrorReporter
+	level    zapcore.Level
+	fields   []zapcore.Field // With 添加的字段
+}
+
+// newReporterCore 未设置 reporter 时返回原始核心
+func newReporterCore(core zapcore.Core, reporter ErrorReporter, level zapcore.Level) zapcore.Core {
+	if reporter == nil {
+		return core
+	}
+	return &reporterCore{Core: core, reporter: reporter, level: level}
+}
+
+func (c *reporterCore) Enabled(level zapcore.Level) bool {
+	return level >= c.level || c.Core.Enabled(level)
+}
+
+func (c *reporterCore) With(fields []zapcore.Field) zapcore.Core {
+	merged := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	merged = append(merged, c.fields...)
+	merged = append(merged, fields...)
+	return &reporterCore{Core: c.Core.With(fields), reporter: c.reporter, level: c.level, fields: merged}
+}
+
+func (c *reporterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *reporterCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	var err error
+	if c.Core.Enabled(ent.Level) {
+		err = c.Core.Write(ent, fields)
+	}
+	if ent.Level >= c.level {
+		c.reporter.Report(c.event(ent, fields))
+		// 进程即将退出或 panic，等待发送完成
+		if ent.Level > zapcore.ErrorLevel {
+			c.reporter.Flush(reportFlushTimeout)
+		}
+	}
+	return err
+}
+
+func (c *reporterCore) Sync() error {
+	c.reporter.Flush(reportFlushTimeout)
+	return c.Core.Sync()
+}
+
+// event 把日志条目和字段转换为 ErrorEvent，字段通过 MapObjectEncoder 编码为普通的值
+func (c *reporterCore) event(ent zapcore.Entry, fields []zapcore.Field) ErrorEvent {
+	event := ErrorEvent{
+		Level:      fromZapLevel(ent.Level),
+		Time:       ent.Time,
+		Message:    ent.Message,
+		LoggerName: ent.LoggerName,
+	}
+	if ent.Caller.Defined {
+		event.Caller = ent.Caller.TrimmedPath()
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	for _, group := range [][]zapcore.Field{c.fields, fields} {
+		for _, f := range group {
+			if f.Type == zapcore.ErrorType && event.Err == nil {
+				event.Err, _ = f.Interface.(error)
+			}
+			f.AddTo(enc)
+		}
+	}
+	event.Fields = enc.Fields
+
+	if event.Err != nil {
+		event.Stack = errorStack(event.Err)
+	}
+	if event.Stack == "" {
+		event.Stack = ent.Stack
+	}
+	return event
+}
+
+// fromZapLevel 把zap级别转换为本包的级别，DPanic 和 Panic 视为 FatalLevel
+func fromZapLevel(level zapcore.Level) Level {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return DebugLevel
+	case level == zapcore.InfoLevel:
+		return InfoLevel
+	case level == zapcore.WarnLevel:
+		return WarnLevel
+	case level == zapcore.ErrorLevel:
+		return ErrorLevel
+	default:
+		return FatalLevel
+	}
+}
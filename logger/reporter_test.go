@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	kiterrors "github.com/tsopia/go-kit/errors"
+	"go.uber.org/zap"
+)
+
+// recordingReporter 记录收到的错误事件
+type recordingReporter struct {
+	mu      sync.Mutex
+	events  []ErrorEvent
+	flushes int
+}
+
+func (r *recordingReporter) Report(event ErrorEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recordingReporter) Flush(timeout time.Duration) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushes++
+	return true
+}
+
+func TestErrorReporterReceivesFieldsAndStack(t *testing.T) {
+	reporter := &recordingReporter{}
+	l := NewWithOptions(Options{
+		Level:         InfoLevel,
+		Output:        io.Discard,
+		Caller:        true,
+		ErrorReporter: reporter,
+	})
+
+	ctx := context.WithValue(context.Background(), ContextKey("trace_id"), "t-1")
+	err := kiterrors.New(kiterrors.CodeDatabaseError, "写入失败").WithStack()
+
+	scope := PushFields("tenant", "acme")
+	l.WithContext(ctx).With("order_id", 42).Warn("仅警告")
+	l.WithContext(ctx).With("order_id", 42).Error("创建订单失败", "error", err, "attempt", 2)
+	scope.Pop()
+
+	if len(reporter.events) != 1 {
+		t.Fatalf("Expected only the error to be reported, got %d events", len(reporter.events))
+	}
+	event := reporter.events[0]
+	if event.Level != ErrorLevel || event.Message != "创建订单失败" || event.Time.IsZero() {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+	if !strings.Contains(event.Caller, "reporter_test.go:") {
+		t.Errorf("Expected caller in test file, got %q", event.Caller)
+	}
+	for key, want := range map[string]interface{}{"trace_id": "t-1", "order_id": int64(42), "attempt": int64(2), "tenant": "acme"} {
+		if got := event.Fields[key]; got != want {
+			t.Errorf("Field %s = %#v, want %#v", key, got, want)
+		}
+	}
+	if !stderrors.Is(event.Err, err) {
+		t.Errorf("Expected the logged error, got %v", event.Err)
+	}
+	if event.Stack == "" || event.Stack != err.Stack {
+		t.Errorf("Expected the error's stack, got %q", event.Stack)
+	}
+}
+
+func TestErrorReporterLevels(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := &recordingReporter{}
+	// 输出级别高于上报级别时，输出核心不写出、上报照常
+	l := NewWithOptions(Options{
+		Level:            FatalLevel,
+		Format:           FormatJSON,
+		Output:           &buf,
+		ErrorReporter:    reporter,
+		ErrorReportLevel: WarnLevel,
+	})
+
+	l.Info("忽略")
+	l.Warn("磁盘空间不足")
+	l.Error("失败")
+	if len(reporter.events) != 2 || reporter.events[0].Level != WarnLevel || reporter.events[1].Level != ErrorLevel {
+		t.Errorf("Expected warn and error to be reported, got %+v", reporter.events)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected nothing written below FatalLevel, got %q", buf.String())
+	}
+
+	// 没有错误字段时使用日志自动附加的堆栈
+	stacked := NewWithOptions(Options{Output: io.Discard, Stacktrace: true, ErrorReporter: reporter})
+	stacked.Error("没有错误对象")
+	if last := reporter.events[len(reporter.events)-1]; last.Err != nil || !strings.Contains(last.Stack, "TestErrorReporterLevels") {
+		t.Errorf("Expected entry stack, got %+v", last)
+	}
+
+	l.Sync()
+	if reporter.flushes == 0 {
+		t.Error("Expected Sync to flush the reporter")
+	}
+}
+
+func TestErrorReporterDisabledByDefault(t *testing.T) {
+	l := NewWithOptions(Options{Output: io.Discard})
+	if _, ok := l.zap.Core().(*reporterCore); ok {
+		t.Error("Expected no reporter core without ErrorReporter")
+	}
+	core := newReporterCore(zap.NewNop().Core(), &recordingReporter{}, zap.ErrorLevel)
+	if !core.Enabled(zap.ErrorLevel) || core.Enabled(zap.WarnLevel) {
+		t.Error("Expected reporter core to enable only report levels when inner core is disabled")
+	}
+}
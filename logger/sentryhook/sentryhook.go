@@ -0,0 +1,460 @@
+// Package sentryhook 把错误日志上报到 Sentry，实现 logger.ErrorReporter
+//
+// 直接调用 Sentry 的 envelope 接口，不依赖 sentry-go；只有导入本包的服务才会启用上报。
+//
+// 示例:
+//
+//	reporter, err := sentryhook.New(sentryhook.Options{
+//	    DSN:         os.Getenv("SENTRY_DSN"),
+//	    Environment: "prod",
+//	    Release:     version,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer reporter.Close()
+//
+//	log := logger.NewWithOptions(logger.Options{
+//	    Level:         logger.InfoLevel,
+//	    ErrorReporter: reporter, // Error 及以上的日志上报到 Sentry
+//	})
+package sentryhook
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tsopia/go-kit/logger"
+)
+
+const (
+	// DefaultTimeout 单次上报请求的默认超时
+	DefaultTimeout = 5 * time.Second
+	// DefaultQueueSize 等待发送的事件的默认上限，队列已满时丢弃新事件
+	DefaultQueueSize = 100
+
+	// clientName 上报时在 X-Sentry-Auth 中使用的客户端名称
+	clientName = "go-kit-sentryhook/1.0"
+)
+
+// DefaultTags 默认提升为 Sentry 标签（可搜索、可聚合）的字段
+var DefaultTags = []string{"trace_id", "request_id", "user_id"}
+
+// ErrInvalidDSN DSN 格式错误
+var ErrInvalidDSN = errors.New("sentryhook: DSN 无效")
+
+// Options Sentry 上报配置
+type Options struct {
+	// DSN 形如 https://<public_key>@o0.ingest.sentry.io/<project_id>
+	DSN         string
+	Environment string
+	Release     string
+	// ServerName 默认为主机名
+	ServerName string
+	// Tags 提升为标签的字段，nil 使用 DefaultTags
+	Tags []string
+	// Transport 发送请求使用的传输层，默认 http.DefaultTransport
+	Transport http.RoundTripper
+	// Timeout 单次上报请求的超时，默认 DefaultTimeout
+	Timeout time.Duration
+	// QueueSize 等待发送的事件上限，默认 DefaultQueueSize
+	QueueSize int
+	// OnError 上报失败时调用，默认写到标准错误（不能使用上报所在的 logger，避免循环）
+	OnError func(err error)
+}
+
+// dsn 解析后的 DSN
+type dsn struct {
+	raw       string
+	publicKey string
+	secretKey string
+	endpoint  string // envelope 接口地址
+}
+
+// parseDSN 解析 DSN，项目ID之前的路径作为 Sentry 的前缀（自建部署）
+func parseDSN(raw string) (*dsn, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDSN, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidDSN, raw)
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	projectID := path[slash+1:]
+	if _, err := strconv.ParseUint(projectID, 10, 64); err != nil {
+		return nil, fmt.Errorf("%w: 缺少项目ID: %q", ErrInvalidDSN, raw)
+	}
+	secret, _ := u.User.Password()
+	return &dsn{
+		raw:       raw,
+		publicKey: u.User.Username(),
+		secretKey: secret,
+		endpoint:  fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, path[:slash], projectID),
+	}, nil
+}
+
+// authHeader X-Sentry-Auth 请求头
+func (d *dsn) authHeader() string {
+	header := fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s", clientName, d.publicKey)
+	if d.secretKey != "" {
+		header += ", sentry_secret=" + d.secretKey
+	}
+	return header
+}
+
+// Reporter 把 logger.ErrorEvent 转换为 Sentry 事件，由后台协程逐个发送
+type Reporter struct {
+	dsn        *dsn
+	opts       Options
+	tags       map[string]bool
+	httpClient *http.Client
+
+	queue   chan []byte
+	pending atomic.Int64 // 已接收但尚未发送完成的事件
+	dropped atomic.Int64
+
+	mu     sync.RWMutex // 保护 closed，避免 Close 之后向已关闭的队列发送
+	closed bool
+	done   chan struct{}
+}
+
+// New 解析 DSN 并启动发送协程，DSN 无效时返回包装 ErrInvalidDSN 的错误
+func New(opts Options) (*Reporter, error) {
+	d, err := parseDSN(opts.DSN)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	if opts.Tags == nil {
+		opts.Tags = DefaultTags
+	}
+	if opts.ServerName == "" {
+		opts.ServerName, _ = os.Hostname()
+	}
+	if opts.OnError == nil {
+		opts.OnError = func(err error) {
+			fmt.Fprintf(os.Stderr, "Sentry上报失败: %v\n", err)
+		}
+	}
+	transport := opts.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	tags := make(map[string]bool, len(opts.Tags))
+	for _, key := range opts.Tags {
+		tags[key] = true
+	}
+	r := &Reporter{
+		dsn:        d,
+		opts:       opts,
+		tags:       tags,
+		httpClient: &http.Client{Transport: transport, Timeout: opts.Timeout},
+		queue:      make(chan []byte, opts.QueueSize),
+		done:       make(chan struct{}),
+	}
+	go r.run()
+	return r, nil
+}
+
+// Report 编码事件并放入发送队列，队列已满时丢弃
+func (r *Reporter) Report(event logger.ErrorEvent) {
+	envelope, err := r.envelope(event)
+	if err != nil {
+		r.opts.OnError(err)
+		return
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		r.dropped.Add(1)
+		return
+	}
+	r.pending.Add(1)
+	select {
+	case r.queue <- envelope:
+	default:
+		r.pending.Add(-1)
+		r.dropped.Add(1)
+	}
+}
+
+// Flush 等待队列中的事件发送完成，返回是否在超时前完成
+func (r *Reporter) Flush(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for r.pending.Load() > 0 {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return true
+}
+
+// Dropped 返回因队列已满或已关闭而丢弃的事件数
+func (r *Reporter) Dropped() int64 {
+	return r.dropped.Load()
+}
+
+// Close 发送队列中剩余的事件后停止发送协程，之后的事件被丢弃
+func (r *Reporter) Close() {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+	<-r.done
+}
+
+func (r *Reporter) run() {
+	defer close(r.done)
+	for envelope := range r.queue {
+		if err := r.send(envelope); err != nil {
+			r.opts.OnError(err)
+		}
+		r.pending.Add(-1)
+	}
+}
+
+func (r *Reporter) send(envelope []byte) error {
+	req, err := http.NewRequest(http.MethodPost, r.dsn.endpoint, bytes.NewReader(envelope))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", r.dsn.authHeader())
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Sentry 返回 %s", resp.Status)
+	}
+	return nil
+}
+
+// envelope 编码为 envelope 格式：头、条目头、事件各占一行
+func (r *Reporter) envelope(event logger.ErrorEvent) ([]byte, error) {
+	payload := r.event(event)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		// 无法编码为JSON的字段（例如通过 zap.Reflect 添加的函数）转为字符串后重试
+		for key, value := range payload.Extra {
+			payload.Extra[key] = fmt.Sprintf("%+v", value)
+		}
+		if body, err = json.Marshal(payload); err != nil {
+			return nil, fmt.Errorf("编码Sentry事件失败: %w", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	header, _ := json.Marshal(map[string]string{
+		"event_id": payload.EventID,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339Nano),
+		"dsn":      r.dsn.raw,
+	})
+	buf.Write(header)
+	buf.WriteByte('\n')
+	fmt.Fprintf(&buf, `{"type":"event","length":%d}`, len(body))
+	buf.WriteByte('\n')
+	buf.Write(body)
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// sentryEvent Sentry 事件中用到的字段
+type sentryEvent struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   string                 `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Platform    string                 `json:"platform"`
+	Logger      string                 `json:"logger,omitempty"`
+	Message     string                 `json:"message,omitempty"`
+	Culprit     string                 `json:"culprit,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	ServerName  string                 `json:"server_name,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+	Exception   *exceptionList         `json:"exception,omitempty"`
+	Stacktrace  *stacktrace            `json:"stacktrace,omitempty"`
+}
+
+type exceptionList struct {
+	Values []exception `json:"values"`
+}
+
+type exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Stacktrace *stacktrace `json:"stacktrace,omitempty"`
+}
+
+type stacktrace struct {
+	Frames []frame `json:"frames"`
+}
+
+type frame struct {
+	Function string `json:"function,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	AbsPath  string `json:"abs_path,omitempty"`
+	Lineno   int    `json:"lineno,omitempty"`
+}
+
+// event 把日志事件转换为 Sentry 事件：字段放入 extra，Tags 中的字段同时作为标签，错误和堆栈作为 exception
+func (r *Reporter) event(event logger.ErrorEvent) sentryEvent {
+	payload := sentryEvent{
+		EventID:     newEventID(),
+		Timestamp:   event.Time.UTC().Format(time.RFC3339Nano),
+		Level:       sentryLevel(event.Level),
+		Platform:    "go",
+		Logger:      event.LoggerName,
+		Message:     event.Message,
+		Culprit:     event.Caller,
+		Environment: r.opts.Environment,
+		Release:     r.opts.Release,
+		ServerName:  r.opts.ServerName,
+	}
+
+	if len(event.Fields) > 0 {
+		payload.Extra = make(map[string]interface{}, len(event.Fields))
+		for key, value := range event.Fields {
+			payload.Extra[key] = value
+			if r.tags[key] {
+				if payload.Tags == nil {
+					payload.Tags = make(map[string]string)
+				}
+				payload.Tags[key] = fmt.Sprint(value)
+			}
+		}
+	}
+
+	trace := parseStack(event.Stack)
+	if event.Err != nil {
+		payload.Exception = &exceptionList{Values: []exception{{
+			Type:       fmt.Sprintf("%T", event.Err),
+			Value:      event.Err.Error(),
+			Stacktrace: trace,
+		}}}
+	} else {
+		payload.Stacktrace = trace
+	}
+	if trace == nil && event.Stack != "" {
+		// 无法解析的堆栈原样保留
+		if payload.Extra == nil {
+			payload.Extra = make(map[string]interface{}, 1)
+		}
+		payload.Extra["stack"] = event.Stack
+	}
+	return payload
+}
+
+// parseStack 解析 "函数\n\t文件:行号" 形式的堆栈（runtime.Stack、zap 的 stacktrace 和 *errors.Error 的 Stack），
+// Sentry 要求帧按调用顺序排列（最外层在前），与Go的输出顺序相反；无法解析时返回nil
+func parseStack(stack string) *stacktrace {
+	if stack == "" {
+		return nil
+	}
+	var frames []frame
+	var function string
+	scanner := bufio.NewScanner(strings.NewReader(stack))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "\t") {
+			function = strings.TrimSpace(line)
+			continue
+		}
+		location := strings.TrimSpace(line)
+		if i := strings.LastIndex(location, " +0x"); i >= 0 {
+			location = location[:i]
+		}
+		colon := strings.LastIndex(location, ":")
+		if colon < 0 {
+			continue
+		}
+		lineno, err := strconv.Atoi(location[colon+1:])
+		if err != nil {
+			continue
+		}
+		file := location[:colon]
+		frames = append(frames, frame{
+			Function: trimArgs(function),
+			Filename: shortFile(file),
+			AbsPath:  file,
+			Lineno:   lineno,
+		})
+		function = ""
+	}
+	if len(frames) == 0 {
+		return nil
+	}
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return &stacktrace{Frames: frames}
+}
+
+// trimArgs 去掉 runtime.Stack 输出中函数名后的参数，例如 main.run(0x1, 0x2)
+func trimArgs(function string) string {
+	if i := strings.LastIndex(function, "("); i > 0 && strings.HasSuffix(function, ")") {
+		return function[:i]
+	}
+	return function
+}
+
+// shortFile 保留最后两级路径，与日志的 caller 格式一致
+func shortFile(path string) string {
+	idx := strings.LastIndexByte(path, '/')
+	if idx < 0 {
+		return path
+	}
+	if prev := strings.LastIndexByte(path[:idx], '/'); prev >= 0 {
+		return path[prev+1:]
+	}
+	return path
+}
+
+func sentryLevel(level logger.Level) string {
+	switch level {
+	case logger.DebugLevel:
+		return "debug"
+	case logger.InfoLevel:
+		return "info"
+	case logger.WarnLevel:
+		return "warning"
+	case logger.ErrorLevel:
+		return "error"
+	default:
+		return "fatal"
+	}
+}
+
+// newEventID 32位十六进制的随机事件ID
+func newEventID() string {
+	var id [16]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
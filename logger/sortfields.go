@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"sort"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultFieldPriority Options.SortFields 开启且未设置 FieldPriority 时排在最前面的字段
+var DefaultFieldPriority = []string{"trace_id", "request_id", "span_id"}
+
+// sortingCore 输出前按键排序字段，使相同事件的日志逐字节一致
+//
+// With 添加的字段不交给内层编码器（编码器会立即把字段写入缓冲区，之后无法调整顺序），
+// 而是保存在这里，Write 时与本次日志的字段合并后一起排序。只包装输出到 stdout/文件的核心，
+// 其他核心看到的字段不受影响；未开启时不包装，没有额外开销。
+type sortingCore struct {
+	zapcore.Core
+	priority map[string]int
+	context  []zapcore.Field
+}
+
+// newSortingCore enabled 为 false 时返回原始 core
+func newSortingCore(core zapcore.Core, enabled bool, priority []string) zapcore.Core {
+	if !enabled {
+		return core
+	}
+	if priority == nil {
+		priority = DefaultFieldPriority
+	}
+	ranks := make(map[string]int, len(priority))
+	for i, key := range priority {
+		if _, exists := ranks[key]; !exists {
+			ranks[key] = i
+		}
+	}
+	return &sortingCore{Core: core, priority: ranks}
+}
+
+func (c *sortingCore) With(fields []zapcore.Field) zapcore.Core {
+	context := make([]zapcore.Field, 0, len(c.context)+len(fields))
+	context = append(context, c.context...)
+	context = append(context, fields...)
+	return &sortingCore{Core: c.Core, priority: c.priority, context: context}
+}
+
+func (c *sortingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *sortingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	all := make([]zapcore.Field, 0, len(c.context)+len(fields))
+	all = append(all, c.context...)
+	all = append(all, fields...)
+	c.sort(all)
+	return c.Core.Write(ent, all)
+}
+
+// sort 优先字段按 priority 的顺序排在最前，其余按键的字典序排列
+//
+// 使用稳定排序，重复的键保持 With 在前、本次日志在后的相对顺序。
+// zap.Namespace 之后的字段属于该命名空间，只排序第一个命名空间之前的字段。
+func (c *sortingCore) sort(fields []zapcore.Field) {
+	for i, f := range fields {
+		if f.Type == zapcore.NamespaceType {
+			fields = fields[:i]
+			break
+		}
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		ri, pi := c.rank(fields[i].Key)
+		rj, pj := c.rank(fields[j].Key)
+		if pi != pj {
+			return pi
+		}
+		if pi {
+			return ri < rj
+		}
+		return fields[i].Key < fields[j].Key
+	})
+}
+
+// rank 返回字段在优先列表中的位置
+func (c *sortingCore) rank(key string) (int, bool) {
+	r, ok := c.priority[key]
+	return r, ok
+}
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// fieldSection 返回 console/text 格式日志行中的字段部分（最后一个制表符之后）
+func fieldSection(line string) string {
+	return line[strings.LastIndex(line, "\t")+1:]
+}
+
+func sortedTestLogger(out io.Writer, format Format, priority []string) *Logger {
+	return NewWithOptions(Options{
+		Level:         DebugLevel,
+		Format:        format,
+		Output:        out,
+		Color:         ColorNever,
+		SortFields:    true,
+		FieldPriority: priority,
+	})
+}
+
+func TestSortFieldsDeterministic(t *testing.T) {
+	fields := map[string]interface{}{}
+	for _, key := range []string{"zeta", "alpha", "mu", "beta", "omega", "gamma", "kappa", "delta"} {
+		fields[key] = key
+	}
+
+	for _, format := range []Format{FormatConsole, FormatText} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			l := sortedTestLogger(&buf, format, nil)
+
+			for i := 0; i < 2; i++ {
+				l.WithFields(fields).With("request_id", "r1").Info("event", "user", "alice", "trace_id", "t1")
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("Expected 2 lines, got %d: %q", len(lines), buf.String())
+			}
+			first, second := fieldSection(lines[0]), fieldSection(lines[1])
+			if first != second {
+				t.Errorf("Expected identical field sections:\n%s\n%s", first, second)
+			}
+			want := `{"trace_id": "t1", "request_id": "r1", "alpha": "alpha", "beta": "beta", "delta": "delta", ` +
+				`"gamma": "gamma", "kappa": "kappa", "mu": "mu", "omega": "omega", "user": "alice", "zeta": "zeta"}`
+			if first != want {
+				t.Errorf("Unexpected field order:\n got: %s\nwant: %s", first, want)
+			}
+		})
+	}
+}
+
+func TestSortFieldsPriorityAndDuplicates(t *testing.T) {
+	var buf bytes.Buffer
+	l := sortedTestLogger(&buf, FormatJSON, []string{"tenant", "trace_id"})
+
+	// 重复的键保持 With 在前、本次日志在后的顺序
+	l.With("b", 1, "dup", "context").Info("event", "trace_id", "t1", "dup", "call", "tenant", "acme", "a", 2)
+
+	line := strings.TrimSpace(buf.String())
+	fieldsStart := strings.Index(line, `"tenant"`)
+	if fieldsStart < 0 {
+		t.Fatalf("Missing fields in %s", line)
+	}
+	want := `"tenant":"acme","trace_id":"t1","a":2,"b":1,"dup":"context","dup":"call"}`
+	if got := line[fieldsStart:]; got != want {
+		t.Errorf("Unexpected field order:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestSortFieldsNamespace(t *testing.T) {
+	var buf bytes.Buffer
+	l := sortedTestLogger(&buf, FormatJSON, []string{})
+
+	l.Info("event", "b", 1, "a", 3, zap.Namespace("http"), zap.String("z", "1"), zap.String("a", "2"))
+
+	line := strings.TrimSpace(buf.String())
+	// 命名空间之前的字段排序，命名空间内的字段保持原顺序
+	if want := `"a":3,"b":1,"http":{"z":"1","a":"2"}}`; !strings.HasSuffix(line, want) {
+		t.Errorf("Unexpected namespace output: %s", line)
+	}
+}
+
+func TestSortFieldsDisabledByDefault(t *testing.T) {
+	l := NewWithOptions(Options{Output: io.Discard})
+	if _, ok := l.zap.Core().(*sortingCore); ok {
+		t.Error("Expected no sorting core when SortFields is disabled")
+	}
+}
+
+func benchmarkFields(b *testing.B, sorted bool) {
+	l := NewWithOptions(Options{
+		Level:      InfoLevel,
+		Format:     FormatConsole,
+		Output:     io.Discard,
+		Color:      ColorNever,
+		SortFields: sorted,
+	}).With("service", "orders", "request_id", "r1")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("event", "user", "alice", "trace_id", "t1", "status", 200, "path", "/orders")
+	}
+}
+
+func BenchmarkSortFields(b *testing.B) {
+	b.Run("unsorted", func(b *testing.B) { benchmarkFields(b, false) })
+	b.Run("sorted", func(b *testing.B) { benchmarkFields(b, true) })
+}
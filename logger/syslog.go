@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// SyslogConfig syslog 输出配置
+type SyslogConfig struct {
+	// Network 连接方式（"udp"、"tcp"、"unix" 等），为空时连接本机的 syslog 守护进程
+	Network string
+	// Address 服务器地址，例如 "syslog.internal:514"；Network 为空时忽略
+	Address string
+	// Facility 设施名称：kern、user、mail、daemon、auth、syslog、lpr、news、uucp、cron、authpriv、ftp、local0 ~ local7，默认 user
+	Facility string
+	// Tag 消息标签，默认为进程名
+	Tag string
+}
+
+// syslogFacilities 设施名称对应的编码（已左移3位，与 log/syslog 的 LOG_* 常量相同）
+var syslogFacilities = map[string]int{
+	"kern": 0 << 3, "user": 1 << 3, "mail": 2 << 3, "daemon": 3 << 3,
+	"auth": 4 << 3, "syslog": 5 << 3, "lpr": 6 << 3, "news": 7 << 3,
+	"uucp": 8 << 3, "cron": 9 << 3, "authpriv": 10 << 3, "ftp": 11 << 3,
+	"local0": 16 << 3, "local1": 17 << 3, "local2": 18 << 3, "local3": 19 << 3,
+	"local4": 20 << 3, "local5": 21 << 3, "local6": 22 << 3, "local7": 23 << 3,
+}
+
+// facility 返回设施编码
+func (c *SyslogConfig) facility() (int, error) {
+	if c.Facility == "" {
+		return syslogFacilities["user"], nil
+	}
+	code, ok := syslogFacilities[c.Facility]
+	if !ok {
+		return 0, fmt.Errorf("未知的 syslog 设施: %s", c.Facility)
+	}
+	return code, nil
+}
+
+// syslog 严重程度（RFC 5424）
+const (
+	severityCrit    = 2
+	severityErr     = 3
+	severityWarning = 4
+	severityInfo    = 6
+	severityDebug   = 7
+)
+
+// syslogSeverity 日志级别对应的 syslog 严重程度，DPanic、Panic、Fatal 均为 crit
+func syslogSeverity(level zapcore.Level) int {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return severityDebug
+	case level == zapcore.InfoLevel:
+		return severityInfo
+	case level == zapcore.WarnLevel:
+		return severityWarning
+	case level == zapcore.ErrorLevel:
+		return severityErr
+	default:
+		return severityCrit
+	}
+}
+
+// syslogSender 按严重程度发送消息，*syslog.Writer 实现了该接口
+type syslogSender interface {
+	Debug(m string) error
+	Info(m string) error
+	Warning(m string) error
+	Err(m string) error
+	Crit(m string) error
+	Close() error
+}
+
+// send 按日志级别对应的严重程度发送
+func send(sender syslogSender, level zapcore.Level, line string) error {
+	switch syslogSeverity(level) {
+	case severityDebug:
+		return sender.Debug(line)
+	case severityInfo:
+		return sender.Info(line)
+	case severityWarning:
+		return sender.Warning(line)
+	case severityErr:
+		return sender.Err(line)
+	default:
+		return sender.Crit(line)
+	}
+}
+
+// leveledCore 编码日志后连同级别交给 write，用于需要按级别设置优先级的输出（syslog、journald）
+type leveledCore struct {
+	zapcore.LevelEnabler
+	enc   zapcore.Encoder
+	write func(level zapcore.Level, line []byte) error
+}
+
+func (c *leveledCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.enc = c.enc.Clone()
+	for _, field := range fields {
+		field.AddTo(clone.enc)
+	}
+	return &clone
+}
+
+func (c *leveledCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+func (c *leveledCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	err = c.write(entry.Level, buf.Bytes())
+	buf.Free()
+	return err
+}
+
+func (c *leveledCore) Sync() error {
+	return nil
+}
+
+// newSyslogCore 创建输出到 syslog 的核心
+func newSyslogCore(enc zapcore.Encoder, sender syslogSender, level zapcore.LevelEnabler) zapcore.Core {
+	return &leveledCore{LevelEnabler: level, enc: enc, write: func(level zapcore.Level, line []byte) error {
+		return send(sender, level, string(line))
+	}}
+}
+
+// newJournaldCore 创建每行带 "<N>" 优先级前缀的核心，systemd-journald 据此设置标准输出日志的优先级
+func newJournaldCore(enc zapcore.Encoder, out io.Writer, level zapcore.LevelEnabler) zapcore.Core {
+	return &leveledCore{LevelEnabler: level, enc: enc, write: func(level zapcore.Level, line []byte) error {
+		prefixed := make([]byte, 0, len(line)+3)
+		prefixed = append(prefixed, '<')
+		prefixed = strconv.AppendInt(prefixed, int64(syslogSeverity(level)), 10)
+		prefixed = append(prefixed, '>')
+		_, err := out.Write(append(prefixed, line...))
+		return err
+	}}
+}
@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package logger
+
+import (
+	"errors"
+	"runtime"
+)
+
+// dialSyslog 当前平台不支持 syslog
+func dialSyslog(cfg *SyslogConfig) (syslogSender, error) {
+	return nil, errors.New("syslog 不支持 " + runtime.GOOS)
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestJournaldPrefix(t *testing.T) {
+	var out strings.Builder
+	l := NewWithOptions(Options{Level: DebugLevel, Format: FormatConsole, Color: ColorAlways, Output: &out, Journald: true})
+	l.Debug("d")
+	l.Info("i", "k", "v")
+	l.Warn("w")
+	l.With("scope", "x").Error("e")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	wants := []string{"<7>", "<6>", "<4>", "<3>"}
+	if len(lines) != len(wants) {
+		t.Fatalf("期望 %d 行，实际 %q", len(wants), out.String())
+	}
+	for i, want := range wants {
+		if !strings.HasPrefix(lines[i], want) {
+			t.Errorf("第 %d 行期望以 %s 开头，实际 %q", i+1, want, lines[i])
+		}
+		if strings.Contains(lines[i], "\x1b[") {
+			t.Errorf("journald 输出不应包含颜色: %q", lines[i])
+		}
+	}
+	if !strings.Contains(lines[3], `"scope": "x"`) {
+		t.Errorf("期望 With 的字段出现在输出中: %q", lines[3])
+	}
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	cases := map[zapcore.Level]int{
+		zapcore.DebugLevel:  7,
+		zapcore.InfoLevel:   6,
+		zapcore.WarnLevel:   4,
+		zapcore.ErrorLevel:  3,
+		zapcore.DPanicLevel: 2,
+		zapcore.FatalLevel:  2,
+	}
+	for level, want := range cases {
+		if got := syslogSeverity(level); got != want {
+			t.Errorf("%s: 期望 %d，实际 %d", level, want, got)
+		}
+	}
+
+	if code, err := (&SyslogConfig{}).facility(); err != nil || code != 8 {
+		t.Errorf("期望默认设施为 user(8)，实际 %d %v", code, err)
+	}
+	if _, err := (&SyslogConfig{Facility: "local9"}).facility(); err == nil {
+		t.Error("期望未知设施返回错误")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows && !plan9
+
+package logger
+
+import "log/syslog"
+
+// dialSyslog 连接 syslog 服务器
+func dialSyslog(cfg *SyslogConfig) (syslogSender, error) {
+	facility, err := cfg.facility()
+	if err != nil {
+		return nil, err
+	}
+	return syslog.Dial(cfg.Network, cfg.Address, syslog.Priority(facility)|syslog.LOG_INFO, cfg.Tag)
+}
@@ -0,0 +1,63 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("无法监听UDP: %v", err)
+	}
+	defer conn.Close()
+
+	l := NewWithOptions(Options{
+		Level:  DebugLevel,
+		Format: FormatJSON,
+		Output: &strings.Builder{},
+		Syslog: &SyslogConfig{Network: "udp", Address: conn.LocalAddr().String(), Facility: "local3", Tag: "orders"},
+	})
+	defer l.Close()
+
+	l.Info("订单已创建", "order_id", 42)
+	l.Warn("库存不足")
+	l.Error("支付失败")
+	l.Debug("调试")
+
+	// local3 = 19，优先级 = 19*8 + 严重程度
+	wants := []struct{ prefix, msg string }{
+		{"<158>", `"msg":"订单已创建"`},
+		{"<156>", `"msg":"库存不足"`},
+		{"<155>", `"msg":"支付失败"`},
+		{"<159>", `"msg":"调试"`},
+	}
+	buf := make([]byte, 4096)
+	for _, want := range wants {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("读取 syslog 消息失败: %v", err)
+		}
+		packet := string(buf[:n])
+		if !strings.HasPrefix(packet, want.prefix) || !strings.Contains(packet, " orders[") || !strings.Contains(packet, want.msg) {
+			t.Errorf("期望 %s 开头且包含标签和 %s，实际 %q", want.prefix, want.msg, packet)
+		}
+	}
+}
+
+func TestSyslogDialFailure(t *testing.T) {
+	var out strings.Builder
+	l := NewWithOptions(Options{Format: FormatJSON, Output: &out, Syslog: &SyslogConfig{Network: "udp", Address: "127.0.0.1:1", Facility: "nope"}})
+	defer l.Close()
+
+	// 设施无效时跳过 syslog，其他输出不受影响
+	l.Info("仍然输出")
+	if l.syslog != nil || !strings.Contains(out.String(), "仍然输出") {
+		t.Errorf("期望跳过 syslog 并继续输出到 stdout，实际 %q", out.String())
+	}
+}
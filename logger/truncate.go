@@ -0,0 +1,262 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// depthPlaceholder 超过 MaxFieldDepth 的嵌套值的占位符
+const depthPlaceholder = "..."
+
+// truncationCount 截断事件计数（字段值、嵌套层级或消息被截断的次数）
+var truncationCount atomic.Uint64
+
+// TruncationCount 返回进程内所有日志记录器的截断事件总数，用于监控
+func TruncationCount() uint64 {
+	return truncationCount.Load()
+}
+
+// truncateLimits 字段和消息的大小限制，0表示不限制
+type truncateLimits struct {
+	maxFieldBytes   int
+	maxFieldDepth   int
+	maxMessageBytes int
+}
+
+func (l truncateLimits) enabled() bool {
+	return l.maxFieldBytes > 0 || l.maxFieldDepth > 0 || l.maxMessageBytes > 0
+}
+
+// truncatingCore 在字段交给编码器之前限制其大小
+// With 和 Write 都会经过转换，因此 With、WithFields 和每次调用传入的字段都受限制
+type truncatingCore struct {
+	zapcore.Core
+	limits truncateLimits
+}
+
+// newTruncatingCore 未设置任何限制时返回原始 core，不产生额外开销
+func newTruncatingCore(core zapcore.Core, limits truncateLimits) zapcore.Core {
+	if !limits.enabled() {
+		return core
+	}
+	return &truncatingCore{Core: core, limits: limits}
+}
+
+func (c *truncatingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &truncatingCore{Core: c.Core.With(c.limits.fields(fields)), limits: c.limits}
+}
+
+func (c *truncatingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *truncatingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if c.limits.maxMessageBytes > 0 {
+		if msg, truncated := truncateString(ent.Message, c.limits.maxMessageBytes); truncated {
+			ent.Message = msg
+		}
+	}
+	return c.Core.Write(ent, c.limits.fields(fields))
+}
+
+// fields 返回限制后的字段，没有字段需要修改时返回原切片
+func (l truncateLimits) fields(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i, f := range fields {
+		limited, changed := l.field(f)
+		if !changed {
+			if out != nil {
+				out = append(out, f)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]zapcore.Field, i, len(fields))
+			copy(out, fields[:i])
+		}
+		out = append(out, limited)
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// field 限制单个字段
+func (l truncateLimits) field(f zapcore.Field) (zapcore.Field, bool) {
+	switch f.Type {
+	case zapcore.StringType:
+		if s, ok := truncateString(f.String, l.maxFieldBytes); ok {
+			return zap.String(f.Key, s), true
+		}
+	case zapcore.ByteStringType, zapcore.BinaryType:
+		if b, ok := f.Interface.([]byte); ok && l.maxFieldBytes > 0 && len(b) > l.maxFieldBytes {
+			truncationCount.Add(1)
+			suffix := fmt.Sprintf("...(truncated %d bytes)", len(b)-l.maxFieldBytes)
+			limited := append(append(make([]byte, 0, l.maxFieldBytes+len(suffix)), b[:l.maxFieldBytes]...), suffix...)
+			if f.Type == zapcore.BinaryType {
+				return zap.Binary(f.Key, limited), true
+			}
+			return zap.ByteString(f.Key, limited), true
+		}
+	case zapcore.StringerType, zapcore.ErrorType:
+		if l.maxFieldBytes <= 0 {
+			return f, false
+		}
+		var s string
+		if err, ok := f.Interface.(error); ok {
+			s = err.Error()
+		} else if stringer, ok := f.Interface.(fmt.Stringer); ok {
+			s = stringer.String()
+		}
+		if limited, ok := truncateString(s, l.maxFieldBytes); ok {
+			return zap.String(f.Key, limited), true
+		}
+	case zapcore.ReflectType, zapcore.ArrayMarshalerType, zapcore.ObjectMarshalerType:
+		return l.encodedField(f)
+	}
+	return f, false
+}
+
+// encodedField 限制结构体、map、切片等编码为JSON的字段
+// 超过层级的嵌套值替换为占位符；编码结果仍超过 MaxFieldBytes 时，以截断的JSON文本代替
+func (l truncateLimits) encodedField(f zapcore.Field) (zapcore.Field, bool) {
+	if l.maxFieldBytes <= 0 && l.maxFieldDepth <= 0 {
+		return f, false
+	}
+	encoded, ok := encodeFieldValue(f)
+	if !ok {
+		return f, false
+	}
+
+	tooLarge := l.maxFieldBytes > 0 && len(encoded) > l.maxFieldBytes
+	tooDeep := l.maxFieldDepth > 0 && jsonDepth(encoded) > l.maxFieldDepth
+	if !tooLarge && !tooDeep {
+		return f, false
+	}
+
+	if tooDeep {
+		var tree interface{}
+		decoder := json.NewDecoder(bytes.NewReader(encoded))
+		decoder.UseNumber()
+		if err := decoder.Decode(&tree); err != nil {
+			return f, false
+		}
+		limited, err := json.Marshal(l.limitDepth(tree, 1))
+		if err != nil {
+			return f, false
+		}
+		encoded = limited
+	}
+
+	if s, ok := truncateStringQuiet(string(encoded), l.maxFieldBytes); ok {
+		truncationCount.Add(1)
+		return zap.String(f.Key, s), true
+	}
+	truncationCount.Add(1)
+	return zap.Reflect(f.Key, json.RawMessage(encoded)), true
+}
+
+// encodeFieldValue 将字段值编码为JSON
+func encodeFieldValue(f zapcore.Field) ([]byte, bool) {
+	if f.Type == zapcore.ReflectType {
+		encoded, err := json.Marshal(f.Interface)
+		return encoded, err == nil
+	}
+
+	// ArrayMarshaler 和 ObjectMarshaler 借助JSON编码器编码为 {"":value}
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{})
+	f.Key = ""
+	buf, err := enc.EncodeEntry(zapcore.Entry{}, []zapcore.Field{f})
+	if err != nil {
+		return nil, false
+	}
+	defer buf.Free()
+
+	line := bytes.TrimSpace(buf.Bytes())
+	line = bytes.TrimPrefix(line, []byte(`{"":`))
+	line = bytes.TrimSuffix(line, []byte(`}`))
+	return append([]byte(nil), line...), true
+}
+
+// limitDepth 将超过层级的嵌套对象和数组替换为占位符，depth 从1开始
+func (l truncateLimits) limitDepth(v interface{}, depth int) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if depth > l.maxFieldDepth {
+			return depthPlaceholder
+		}
+		for key, item := range value {
+			value[key] = l.limitDepth(item, depth+1)
+		}
+		return value
+	case []interface{}:
+		if depth > l.maxFieldDepth {
+			return depthPlaceholder
+		}
+		for i, item := range value {
+			value[i] = l.limitDepth(item, depth+1)
+		}
+		return value
+	default:
+		return v
+	}
+}
+
+// jsonDepth 计算JSON文本的最大嵌套层级（对象和数组）
+func jsonDepth(data []byte) int {
+	depth, maxDepth := 0, 0
+	inString, escaped := false, false
+	for _, b := range data {
+		switch {
+		case escaped:
+			escaped = false
+		case inString:
+			if b == '\\' {
+				escaped = true
+			} else if b == '"' {
+				inString = false
+			}
+		case b == '"':
+			inString = true
+		case b == '{' || b == '[':
+			depth++
+			if depth > maxDepth {
+				maxDepth = depth
+			}
+		case b == '}' || b == ']':
+			depth--
+		}
+	}
+	return maxDepth
+}
+
+// truncateString 超过 max 字节时截断并追加 "...(truncated N bytes)"，记录截断事件
+func truncateString(s string, max int) (string, bool) {
+	limited, ok := truncateStringQuiet(s, max)
+	if ok {
+		truncationCount.Add(1)
+	}
+	return limited, ok
+}
+
+// truncateStringQuiet 与 truncateString 相同但不计数，截断位置不会切开UTF-8字符
+func truncateStringQuiet(s string, max int) (string, bool) {
+	if max <= 0 || len(s) <= max {
+		return s, false
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s...(truncated %d bytes)", s[:cut], len(s)-cut), true
+}
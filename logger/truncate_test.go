@@ -0,0 +1,190 @@
+package logger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// newLimitedLogger 创建写入临时文件的JSON日志记录器，返回读取日志行的函数
+func newLimitedLogger(t *testing.T, opts Options) (*Logger, func() []map[string]interface{}) {
+	t.Helper()
+
+	logPath := filepath.Join(t.TempDir(), "truncate.log")
+	opts.Level = InfoLevel
+	opts.Format = FormatJSON
+	opts.EnableFileOutput = true
+	opts.Rotate = &RotateConfig{Filename: logPath, MaxSize: 10}
+	l := NewWithOptions(opts)
+
+	return l, func() []map[string]interface{} {
+		l.Sync()
+		content, err := os.ReadFile(logPath)
+		if err != nil {
+			t.Fatalf("Failed to read log file: %v", err)
+		}
+		var entries []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+			var entry map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("Invalid JSON line %q: %v", line, err)
+			}
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+}
+
+func TestMaxFieldBytes(t *testing.T) {
+	before := TruncationCount()
+	l, read := newLimitedLogger(t, Options{MaxFieldBytes: 16})
+
+	long := strings.Repeat("a", 100)
+	l.With("with_field", long).Info("per-call", "body", long, "short", "ok")
+	l.WithFields(map[string]interface{}{"fields": long}).Info("fields")
+	l.GetZap().Info("bytes", zap.ByteString("raw", []byte(long)))
+
+	entries := read()
+	want := strings.Repeat("a", 16) + "...(truncated 84 bytes)"
+	if entries[0]["body"] != want {
+		t.Errorf("Expected per-call field truncated to %q, got %v", want, entries[0]["body"])
+	}
+	if entries[0]["with_field"] != want {
+		t.Errorf("Expected With field truncated, got %v", entries[0]["with_field"])
+	}
+	if entries[0]["short"] != "ok" {
+		t.Errorf("Expected short field untouched, got %v", entries[0]["short"])
+	}
+	if entries[1]["fields"] != want {
+		t.Errorf("Expected WithFields field truncated, got %v", entries[1]["fields"])
+	}
+	if entries[2]["raw"] != want {
+		t.Errorf("Expected byte string truncated, got %v", entries[2]["raw"])
+	}
+	if got := TruncationCount() - before; got < 4 {
+		t.Errorf("Expected truncation counter to increase by at least 4, got %d", got)
+	}
+}
+
+func TestMaxFieldBytesReflect(t *testing.T) {
+	l, read := newLimitedLogger(t, Options{MaxFieldBytes: 64})
+
+	type payload struct {
+		Name string `json:"name"`
+		Blob string `json:"blob"`
+	}
+	l.Info("struct", "payload", payload{Name: "n", Blob: strings.Repeat("x", 1000)})
+	l.Info("huge", "items", make([]int, 1000))
+	l.Info("small", "small", payload{Name: "n"})
+
+	entries := read()
+	for i, key := range []string{"payload", "items"} {
+		text, ok := entries[i][key].(string)
+		if !ok || !strings.HasPrefix(text, "{\"name\"") && !strings.HasPrefix(text, "[0,") {
+			t.Fatalf("Expected oversized %s encoded as truncated JSON text, got %v", key, entries[i][key])
+		}
+		if !strings.Contains(text, "...(truncated") || len(text) > 64+len("...(truncated 10000 bytes)") {
+			t.Errorf("Expected %s bounded by MaxFieldBytes, got %d bytes", key, len(text))
+		}
+	}
+	if entries[2]["small"].(map[string]interface{})["name"] != "n" {
+		t.Errorf("Expected small struct untouched, got %v", entries[2]["small"])
+	}
+}
+
+func TestMaxFieldDepth(t *testing.T) {
+	l, read := newLimitedLogger(t, Options{MaxFieldDepth: 2})
+
+	nested := map[string]interface{}{
+		"level1": map[string]interface{}{
+			"level2": map[string]interface{}{
+				"level3": "deep",
+			},
+			"list": []interface{}{[]interface{}{1}},
+		},
+		"flat": 1,
+	}
+	l.Info("nested", "data", nested, "shallow", map[string]int{"a": 1})
+
+	entry := read()[0]
+	level1 := entry["data"].(map[string]interface{})["level1"].(map[string]interface{})
+	if level1["level2"] != depthPlaceholder {
+		t.Errorf("Expected level2 replaced by placeholder, got %v", level1["level2"])
+	}
+	if list := level1["list"]; list != depthPlaceholder {
+		t.Errorf("Expected nested list replaced by placeholder, got %v", list)
+	}
+	if entry["data"].(map[string]interface{})["flat"] != float64(1) {
+		t.Errorf("Expected scalar values preserved, got %v", entry["data"])
+	}
+	if entry["shallow"].(map[string]interface{})["a"] != float64(1) {
+		t.Errorf("Expected shallow map untouched, got %v", entry["shallow"])
+	}
+}
+
+func TestMaxMessageBytes(t *testing.T) {
+	l, read := newLimitedLogger(t, Options{MaxMessageBytes: 10, MaxFieldBytes: 100})
+
+	l.Info(strings.Repeat("m", 30), "field", strings.Repeat("f", 30))
+
+	entry := read()[0]
+	if entry["msg"] != "mmmmmmmmmm...(truncated 20 bytes)" {
+		t.Errorf("Expected message truncated, got %v", entry["msg"])
+	}
+	if entry["field"] != strings.Repeat("f", 30) {
+		t.Errorf("Expected field limited by MaxFieldBytes only, got %v", entry["field"])
+	}
+}
+
+func TestTruncateStringUTF8(t *testing.T) {
+	s, ok := truncateStringQuiet("你好世界", 4)
+	if !ok || s != "你...(truncated 9 bytes)" {
+		t.Errorf("Expected truncation at rune boundary, got %q", s)
+	}
+}
+
+func TestTruncatingCoreDisabled(t *testing.T) {
+	core := zapcore.NewNopCore()
+	if newTruncatingCore(core, truncateLimits{}) != core {
+		t.Error("Expected core to be returned unwrapped when no limits are set")
+	}
+}
+
+func benchmarkSmallFields(b *testing.B, opts Options) {
+	opts.Level = InfoLevel
+	opts.Format = FormatJSON
+	l := NewWithOptions(opts)
+	l.zap = zap.New(newTruncatingCore(zapcore.NewCore(
+		zapcore.NewJSONEncoder(l.buildEncoderConfig()),
+		zapcore.AddSync(discardWriter{}),
+		l.level,
+	), truncateLimits{
+		maxFieldBytes:   opts.MaxFieldBytes,
+		maxFieldDepth:   opts.MaxFieldDepth,
+		maxMessageBytes: opts.MaxMessageBytes,
+	}))
+	l.sugar = l.zap.Sugar()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("request handled", "method", "GET", "path", "/api/users", "status", 200)
+	}
+}
+
+type discardWriter struct{}
+
+func (discardWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+func BenchmarkSmallFields_NoLimits(b *testing.B) {
+	benchmarkSmallFields(b, Options{})
+}
+
+func BenchmarkSmallFields_WithLimits(b *testing.B) {
+	benchmarkSmallFields(b, Options{MaxFieldBytes: 1024, MaxFieldDepth: 5, MaxMessageBytes: 1024})
+}